@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/keep-network/keep-core/config"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/urfave/cli"
+)
+
+// ConfigCommand contains the definition of the config command-line
+// subcommand.
+var ConfigCommand cli.Command
+
+// ConfigOverrideFlags contains definitions of global command-line flags
+// overriding config fields, for example --ethereum.url for Ethereum.URL.
+var ConfigOverrideFlags []cli.Flag
+
+const checkConnectivityFlag = "check-connectivity"
+
+// connectivityCheckTimeout is the maximum time a single connectivity check
+// can take.
+const connectivityCheckTimeout = 10 * time.Second
+
+const configValidateDescription = `The validate command reads the config file
+   and validates all of its sections: contract addresses and their checksums,
+   URL schemes, bootstrap peer and announced multiaddresses, the dissemination
+   time range, ports, metric ticks and the data directory permissions. With
+   the "check-connectivity" flag, the command also connects to the Ethereum
+   node, the bootstrap peers and the signing service. The effective config,
+   with defaults applied and passwords masked, is printed to the standard
+   output. The command fails if any problem has been found.`
+
+func init() {
+	for _, fieldPath := range config.OverridableFields() {
+		ConfigOverrideFlags = append(ConfigOverrideFlags, cli.StringFlag{
+			Name: config.FlagName(fieldPath),
+			Usage: fmt.Sprintf(
+				"overrides %v from the config file and %v",
+				fieldPath,
+				config.EnvVariable(fieldPath),
+			),
+		})
+	}
+
+	ConfigCommand = cli.Command{
+		Name:  "config",
+		Usage: "Provides access to the client configuration.",
+		Subcommands: []cli.Command{
+			{
+				Name:        "validate",
+				Usage:       "Validates the config file and prints the effective config.",
+				Description: configValidateDescription,
+				Action:      validateConfig,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  checkConnectivityFlag,
+						Usage: "check connectivity of configured services",
+					},
+				},
+			},
+		},
+	}
+}
+
+// readConfig reads the config file pointed by the global config flag and
+// overrides its values with environment variables and the global config
+// override flags set.
+func readConfig(c *cli.Context) (*config.Config, error) {
+	return readConfigWithFlags(c, map[string]string{})
+}
+
+// readConfigWithFlags works the same way as readConfig but lets the command
+// pass values of its own flags overriding config fields. Command flags take
+// precedence over the global config override flags.
+func readConfigWithFlags(
+	c *cli.Context,
+	commandFlagValues map[string]string,
+) (*config.Config, error) {
+	return config.ReadConfigWithOverrides(
+		c.GlobalString("config"),
+		overrideFlagValues(c, commandFlagValues),
+	)
+}
+
+// overrideFlagValues returns values of all flags overriding config fields,
+// keyed by field paths. Values of the provided command flags take precedence
+// over values of the global config override flags.
+func overrideFlagValues(
+	c *cli.Context,
+	commandFlagValues map[string]string,
+) map[string]string {
+	flagValues := make(map[string]string)
+	for _, fieldPath := range config.OverridableFields() {
+		if value, ok := commandFlagValues[fieldPath]; ok {
+			flagValues[fieldPath] = value
+		} else if c.GlobalIsSet(config.FlagName(fieldPath)) {
+			flagValues[fieldPath] = c.GlobalString(config.FlagName(fieldPath))
+		}
+	}
+
+	return flagValues
+}
+
+// validateConfig validates the config file and prints the effective config.
+func validateConfig(c *cli.Context) error {
+	cfg, err := readConfig(c)
+	if err != nil {
+		return fmt.Errorf("error reading config file: [%v]", err)
+	}
+
+	problems := cfg.Validate()
+
+	if c.Bool(checkConnectivityFlag) {
+		problems = append(problems, checkConnectivity(cfg)...)
+	}
+
+	if err := toml.NewEncoder(os.Stdout).Encode(
+		cfg.WithDefaults().Masked(),
+	); err != nil {
+		return fmt.Errorf("could not print config: [%v]", err)
+	}
+
+	if len(problems) > 0 {
+		fmt.Fprintln(os.Stderr)
+		for _, problem := range problems {
+			fmt.Fprintf(os.Stderr, "invalid config: %v\n", problem)
+		}
+
+		return fmt.Errorf("found [%v] config problems", len(problems))
+	}
+
+	fmt.Fprintln(os.Stderr, "\nconfig is valid")
+
+	return nil
+}
+
+// checkConnectivity connects to the Ethereum node, bootstrap peers and the
+// signing service and returns all connectivity problems found.
+func checkConnectivity(cfg *config.Config) []error {
+	var problems []error
+
+	for _, ethereumURL := range []string{cfg.Ethereum.URL, cfg.Ethereum.URLRPC} {
+		if ethereumURL == "" {
+			continue
+		}
+
+		if err := checkEthereumConnectivity(ethereumURL); err != nil {
+			problems = append(problems, fmt.Errorf(
+				"ethereum: could not connect to [%v]: [%v]",
+				ethereumURL,
+				err,
+			))
+		}
+	}
+
+	for _, peer := range cfg.LibP2P.Peers {
+		if err := checkPeerConnectivity(peer); err != nil {
+			problems = append(problems, fmt.Errorf(
+				"libp2p: could not connect to peer [%v]: [%v]",
+				peer,
+				err,
+			))
+		}
+	}
+
+	if cfg.Signer.URL != "" {
+		if err := checkSignerConnectivity(cfg.Signer.URL); err != nil {
+			problems = append(problems, fmt.Errorf(
+				"signer: could not connect to [%v]: [%v]",
+				cfg.Signer.URL,
+				err,
+			))
+		}
+	}
+
+	return problems
+}
+
+func checkEthereumConnectivity(url string) error {
+	ctx, cancel := context.WithTimeout(
+		context.Background(),
+		connectivityCheckTimeout,
+	)
+	defer cancel()
+
+	client, err := ethclient.DialContext(ctx, url)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	_, err = client.NetworkID(ctx)
+	return err
+}
+
+func checkPeerConnectivity(peer string) error {
+	multiaddress, err := ma.NewMultiaddr(peer)
+	if err != nil {
+		return err
+	}
+
+	port, err := multiaddress.ValueForProtocol(ma.P_TCP)
+	if err != nil {
+		return err
+	}
+
+	var host string
+	for _, protocol := range []int{ma.P_IP4, ma.P_IP6, ma.P_DNS4, ma.P_DNS6} {
+		if host, err = multiaddress.ValueForProtocol(protocol); err == nil {
+			break
+		}
+	}
+	if host == "" {
+		return fmt.Errorf("no host in the address")
+	}
+
+	connection, err := net.DialTimeout(
+		"tcp",
+		net.JoinHostPort(host, port),
+		connectivityCheckTimeout,
+	)
+	if err != nil {
+		return err
+	}
+
+	return connection.Close()
+}
+
+func checkSignerConnectivity(url string) error {
+	ctx, cancel := context.WithTimeout(
+		context.Background(),
+		connectivityCheckTimeout,
+	)
+	defer cancel()
+
+	client, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	var accounts []string
+	return client.CallContext(ctx, &accounts, "eth_accounts")
+}
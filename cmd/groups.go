@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/keep-network/keep-core/pkg/admin"
+	"github.com/urfave/cli"
+)
+
+// GroupsCommand contains the definition of the groups command-line
+// subcommand and its own subcommands.
+var GroupsCommand cli.Command
+
+// approximateBlockTime is the average block time used only to present the
+// time left until the group expiration in a human-readable form.
+const approximateBlockTime = 15 * time.Second
+
+const groupsDescription = `The groups command inspects groups the running
+	client is a member of. It queries the admin API of the client so the admin
+	API has to be enabled in the config file.
+
+	The "list" subcommand prints all groups along with the number of members
+	hosted by the client, the block the group has been registered at, the block
+	the group is expected to expire at and the number of blocks left until then,
+	as well as the block of the last relay entry signing the client took part
+	in. The time left until the expiration is approximated assuming 15 seconds
+	per block. Blocks not known yet are reported as "-".`
+
+// groupStatus is the group entry of the groups admin API source.
+type groupStatus struct {
+	GroupPublicKey        string `json:"group_public_key"`
+	MemberIndexes         []int  `json:"member_indexes"`
+	RegistrationBlock     uint64 `json:"registration_block"`
+	ExpirationBlock       uint64 `json:"expiration_block"`
+	BlocksUntilExpiration int64  `json:"blocks_until_expiration"`
+	LastSigningBlock      uint64 `json:"last_signing_block"`
+}
+
+func init() {
+	GroupsCommand = cli.Command{
+		Name:        "groups",
+		Usage:       "Inspects groups the running client is a member of.",
+		Description: groupsDescription,
+		Subcommands: []cli.Command{
+			{
+				Name:   "list",
+				Usage:  "Lists groups along with their expected expiration.",
+				Action: listGroups,
+			},
+		},
+	}
+}
+
+// listGroups prints groups the running client is a member of.
+func listGroups(c *cli.Context) error {
+	config, err := readConfig(c)
+	if err != nil {
+		return fmt.Errorf("error reading config file: [%v]", err)
+	}
+
+	var groups []groupStatus
+	if err := admin.Query(
+		config.Admin.Port,
+		adminTokenFile(config),
+		"groups",
+		&groups,
+	); err != nil {
+		return fmt.Errorf("could not list groups: [%v]", err)
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "GROUP\tMEMBERS\tREGISTERED\tEXPIRES\tETA\tLAST SIGNING")
+
+	for _, group := range groups {
+		eta := "-"
+		if group.RegistrationBlock != 0 {
+			if group.BlocksUntilExpiration > 0 {
+				eta = fmt.Sprintf(
+					"%v blocks (~%v)",
+					group.BlocksUntilExpiration,
+					time.Duration(group.BlocksUntilExpiration)*approximateBlockTime,
+				)
+			} else {
+				eta = "expired"
+			}
+		}
+
+		fmt.Fprintf(
+			writer,
+			"%v\t%v\t%v\t%v\t%v\t%v\n",
+			shortHex(group.GroupPublicKey),
+			len(group.MemberIndexes),
+			blockOrDash(group.RegistrationBlock),
+			blockOrDash(group.ExpirationBlock),
+			eta,
+			blockOrDash(group.LastSigningBlock),
+		)
+	}
+
+	return writer.Flush()
+}
+
+// blockOrDash formats the block number, printing a dash for unknown blocks.
+func blockOrDash(block uint64) string {
+	if block == 0 {
+		return "-"
+	}
+
+	return fmt.Sprint(block)
+}
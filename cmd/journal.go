@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/keep-network/keep-core/pkg/journal"
+	"github.com/urfave/cli"
+)
+
+// JournalCommand contains the definition of the journal command-line
+// subcommand.
+var JournalCommand cli.Command
+
+const (
+	operatorFlag = "operator"
+	seedFlag     = "seed"
+	groupFlag    = "group"
+	dataDirFlag  = "data-dir"
+	jsonFlag     = "json"
+)
+
+const journalDescription = `The journal command prints entries of the event
+	journal kept by the client in its data directory. The journal records every
+	group selection, ticket submission, DKG state transition, accusation, DKG
+	result vote, relay entry request, signature share received or rejected,
+	relay entry submission and transaction not submitted in the shadow mode,
+	along with block numbers and the operator the entry belongs to. Entries can
+	be filtered by the operator address, the group selection seed or the group
+	public key, all in hexadecimal format. The data directory is read from the
+	config file unless provided explicitly. By default, entries are printed as
+	a table with long values shortened; the "json" flag prints complete
+	entries, one per line.`
+
+func init() {
+	JournalCommand = cli.Command{
+		Name:        "journal",
+		Usage:       "Prints the event journal of protocol executions.",
+		Description: journalDescription,
+		Action:      printJournal,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  operatorFlag,
+				Usage: "print only entries for the given operator address",
+			},
+			&cli.StringFlag{
+				Name:  seedFlag,
+				Usage: "print only entries for the given group selection seed",
+			},
+			&cli.StringFlag{
+				Name:  groupFlag,
+				Usage: "print only entries for the given group public key",
+			},
+			&cli.StringFlag{
+				Name:  dataDirFlag,
+				Usage: "data directory of the client; overrides the config file",
+			},
+			&cli.BoolFlag{
+				Name:  jsonFlag,
+				Usage: "print complete entries in JSON format, one per line",
+			},
+		},
+	}
+}
+
+// printJournal prints journal entries matching the provided filter.
+func printJournal(c *cli.Context) error {
+	dataDir := c.String(dataDirFlag)
+	if dataDir == "" {
+		cfg, err := readConfig(c)
+		if err != nil {
+			return fmt.Errorf("error reading config file: [%v]", err)
+		}
+
+		dataDir = cfg.Storage.DataDir
+	}
+
+	entries, err := journal.Read(dataDir, &journal.Filter{
+		Operator:       c.String(operatorFlag),
+		Seed:           c.String(seedFlag),
+		GroupPublicKey: c.String(groupFlag),
+	})
+	if err != nil {
+		return fmt.Errorf("could not read journal: [%v]", err)
+	}
+
+	if c.Bool(jsonFlag) {
+		encoder := json.NewEncoder(os.Stdout)
+		for _, entry := range entries {
+			if err := encoder.Encode(entry); err != nil {
+				return fmt.Errorf("could not marshal entry: [%v]", err)
+			}
+		}
+
+		return nil
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "BLOCK\tTIME\tTYPE\tOPERATOR\tMEMBER\tSEED\tGROUP\tDETAILS")
+
+	for _, entry := range entries {
+		details, err := json.Marshal(entry.Details)
+		if err != nil {
+			return fmt.Errorf("could not marshal entry details: [%v]", err)
+		}
+
+		fmt.Fprintf(
+			writer,
+			"%v\t%v\t%v\t%v\t%v\t%v\t%v\t%s\n",
+			entry.BlockNumber,
+			entry.Time.Format(time.RFC3339),
+			entry.Type,
+			shortHex(entry.Operator),
+			entry.MemberIndex,
+			shortHex(entry.Seed),
+			shortHex(entry.GroupPublicKey),
+			details,
+		)
+	}
+
+	return writer.Flush()
+}
+
+// shortHex shortens long hexadecimal values so that the table stays readable.
+func shortHex(value string) string {
+	if len(value) <= 16 {
+		return value
+	}
+
+	return value[:8] + "..." + value[len(value)-8:]
+}
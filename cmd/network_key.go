@@ -0,0 +1,538 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/keep-network/keep-core/config"
+	"github.com/keep-network/keep-core/pkg/chain"
+	"github.com/keep-network/keep-core/pkg/chain/ethereum/signer"
+	"github.com/keep-network/keep-core/pkg/net"
+	"github.com/keep-network/keep-core/pkg/net/key"
+	"github.com/keep-network/keep-core/pkg/net/libp2p"
+	"github.com/keep-network/keep-core/pkg/operator"
+	"github.com/pborman/uuid"
+	"github.com/urfave/cli"
+)
+
+// NetworkKeyCommand contains the definition of the network-key command-line
+// subcommand and its own subcommands.
+var NetworkKeyCommand cli.Command
+
+const networkKeyRotateDescription = `The rotate command replaces the network key
+	of the operator with a newly generated key and signs the delegation of the
+	new key with the operator key. The new delegation has a sequence number
+	higher than the previous one. Peers reject the previous delegation and
+	disconnect the peer presenting it as soon as they see the new one, and the
+	previous delegation is rejected by all peers once it expires. The client
+	has to be restarted to use the new network key.
+
+	The network key is shared by all operators hosted by the client. Additional
+	operators delegate the new network key when the client is restarted. The
+	network key can not be rotated if it is derived from the operator key.`
+
+// defaultNetworkKeyFile is the name of the network key file created in the
+// storage data directory if the operator key is held by a signing service and
+// no network key file is configured.
+const defaultNetworkKeyFile = "network_key"
+
+// delegationFileSuffix is appended to the network key file path to get the
+// path of the file storing the operator's delegation of the network key.
+const delegationFileSuffix = ".delegation"
+
+// delegationValidity is the period for which the operator delegates the
+// network key. Peers reject expired delegations.
+const delegationValidity = 30 * 24 * time.Hour
+
+// delegationRenewalPeriod is the period before the delegation expiration in
+// which the delegation is renewed.
+const delegationRenewalPeriod = delegationValidity / 2
+
+// delegationRenewalCheckInterval is the interval in which the running client
+// checks if the delegation has to be renewed.
+const delegationRenewalCheckInterval = time.Hour
+
+func init() {
+	NetworkKeyCommand = cli.Command{
+		Name:  "network-key",
+		Usage: "Manages the network key delegated by the operator.",
+		Subcommands: []cli.Command{
+			{
+				Name:        "rotate",
+				Usage:       "Replaces the network key and its delegation.",
+				Description: networkKeyRotateDescription,
+				Action:      rotateNetworkKey,
+			},
+		},
+	}
+}
+
+// networkKeyDelegation is the operator's delegation of the network key along
+// with everything needed to renew it.
+type networkKeyDelegation struct {
+	file             string
+	networkPublicKey *key.NetworkPublic
+	operatorSigning  chain.Signing
+	operatorAddress  string
+
+	delegation *key.Delegation
+}
+
+// operatorNetworkKey returns the network key of the client along with the
+// operator's delegation of that key. The network key is derived from the
+// operator key, and no delegation is returned, if the operator key is held by
+// the client process and no network key file is configured. Otherwise, the
+// network key is read from the network key file, or created if the file does
+// not exist, and delegated by the operator.
+func operatorNetworkKey(
+	config *config.Config,
+	operatorSigner signer.Signer,
+) (*key.NetworkPrivate, *networkKeyDelegation, error) {
+	keyFile, ok := operatorNetworkKeyFile(config, operatorSigner)
+	if !ok {
+		localSigner := operatorSigner.(*signer.Local)
+		networkPrivateKey, _ := key.OperatorKeyToNetworkKey(
+			operator.ChainKeyToOperatorKey(localSigner.Key()),
+		)
+		return networkPrivateKey, nil, nil
+	}
+
+	return delegatedNetworkKey(
+		keyFile,
+		config.Ethereum.Account.KeyFilePassword,
+		operatorSigner,
+		operatorSigner.Address().Hex(),
+	)
+}
+
+// operatorNetworkKeyFile returns the network key file of the primary operator.
+// False is returned if the network key is derived from the operator key.
+func operatorNetworkKeyFile(
+	config *config.Config,
+	operatorSigner signer.Signer,
+) (string, bool) {
+	if config.NetworkKey.KeyFile != "" {
+		return config.NetworkKey.KeyFile, true
+	}
+
+	if _, ok := operatorSigner.(*signer.Local); ok {
+		return "", false
+	}
+
+	return filepath.Join(config.Storage.DataDir, defaultNetworkKeyFile), true
+}
+
+// hostedOperatorDelegation returns the additional operator's delegation of
+// the network key of the client. All operators hosted by the client share the
+// network key. The delegation is stored in the operator's data directory and
+// signed again if it does not delegate the current network key, for example
+// after the network key has been rotated.
+func hostedOperatorDelegation(
+	dataDir string,
+	networkPublicKey *key.NetworkPublic,
+	operatorSigning chain.Signing,
+	operatorAddress string,
+) (*networkKeyDelegation, error) {
+	networkKeyDelegation := &networkKeyDelegation{
+		file: filepath.Join(
+			dataDir,
+			defaultNetworkKeyFile+delegationFileSuffix,
+		),
+		networkPublicKey: networkPublicKey,
+		operatorSigning:  operatorSigning,
+		operatorAddress:  operatorAddress,
+	}
+
+	delegation, err := readOrSignDelegation(
+		networkKeyDelegation.file,
+		networkPublicKey,
+		operatorSigning,
+		operatorAddress,
+	)
+	if err != nil {
+		return nil, err
+	}
+	networkKeyDelegation.delegation = delegation
+
+	return networkKeyDelegation, nil
+}
+
+// delegatedNetworkKey reads the network key from the provided key file, or
+// creates it if the file does not exist, along with the operator's delegation
+// of that key.
+func delegatedNetworkKey(
+	keyFile string,
+	password string,
+	operatorSigning chain.Signing,
+	operatorAddress string,
+) (*key.NetworkPrivate, *networkKeyDelegation, error) {
+	networkPrivateKey, err := readOrCreateNetworkKey(keyFile, password)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	networkKeyDelegation := &networkKeyDelegation{
+		file:             keyFile + delegationFileSuffix,
+		networkPublicKey: key.Libp2pKeyToNetworkKey(networkPrivateKey.GetPublic()),
+		operatorSigning:  operatorSigning,
+		operatorAddress:  operatorAddress,
+	}
+
+	networkKeyDelegation.delegation, err = readOrSignDelegation(
+		networkKeyDelegation.file,
+		networkKeyDelegation.networkPublicKey,
+		operatorSigning,
+		operatorAddress,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	logger.Infof(
+		"using network key [%v] delegated by operator [%v]",
+		keyFile,
+		operatorAddress,
+	)
+
+	return networkPrivateKey, networkKeyDelegation, nil
+}
+
+// rotateNetworkKey replaces the network key of the operator with a new key
+// and signs the delegation of the new key.
+func rotateNetworkKey(c *cli.Context) error {
+	config, err := readConfig(c)
+	if err != nil {
+		return fmt.Errorf("error reading config file: [%v]", err)
+	}
+
+	operatorSigner, err := connectOperatorSigner(context.Background(), config)
+	if err != nil {
+		return err
+	}
+
+	keyFile, ok := operatorNetworkKeyFile(config, operatorSigner)
+	if !ok {
+		return fmt.Errorf(
+			"network key is derived from the operator key; " +
+				"configure the network key file to use a separate network key",
+		)
+	}
+
+	delegationFile := keyFile + delegationFileSuffix
+
+	previous, err := readDelegation(delegationFile, operatorSigner)
+	if err != nil {
+		return err
+	}
+
+	var sequence uint64
+	if previous != nil {
+		sequence = previous.Sequence()
+	}
+
+	networkPrivateKey, err := createNetworkKey(
+		keyFile,
+		config.Ethereum.Account.KeyFilePassword,
+	)
+	if err != nil {
+		return err
+	}
+
+	delegation, err := signDelegation(
+		delegationFile,
+		key.Libp2pKeyToNetworkKey(networkPrivateKey.GetPublic()),
+		operatorSigner,
+		sequence+1,
+	)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf(
+		"Rotated network key [%v] of operator [%v].\n"+
+			"The new delegation has sequence [%v] and expires at [%v].\n"+
+			"Restart the client to use the new network key.\n",
+		keyFile,
+		operatorSigner.Address().Hex(),
+		delegation.Sequence(),
+		delegation.ExpiresAt(),
+	)
+
+	return nil
+}
+
+// readOrCreateNetworkKey decrypts the network key from the provided key file.
+// If the key file does not exist, a new network key is generated and stored
+// in the file encrypted with the provided password.
+func readOrCreateNetworkKey(
+	keyFile string,
+	password string,
+) (*key.NetworkPrivate, error) {
+	keyJSON, err := ioutil.ReadFile(keyFile)
+	if os.IsNotExist(err) {
+		return createNetworkKey(keyFile, password)
+	}
+	if err != nil {
+		return nil, fmt.Errorf(
+			"could not read network key file [%v]: [%v]",
+			keyFile,
+			err,
+		)
+	}
+
+	networkKey, err := keystore.DecryptKey(keyJSON, password)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"could not decrypt network key file [%v]: [%v]",
+			keyFile,
+			err,
+		)
+	}
+
+	networkPrivateKey, _ := key.OperatorKeyToNetworkKey(
+		operator.ChainKeyToOperatorKey(networkKey),
+	)
+	return networkPrivateKey, nil
+}
+
+func createNetworkKey(
+	keyFile string,
+	password string,
+) (*key.NetworkPrivate, error) {
+	privateKey, _, err := operator.GenerateKeyPair()
+	if err != nil {
+		return nil, fmt.Errorf("could not generate network key: [%v]", err)
+	}
+
+	networkKey := &keystore.Key{
+		Id:         uuid.NewRandom(),
+		Address:    crypto.PubkeyToAddress(privateKey.PublicKey),
+		PrivateKey: privateKey,
+	}
+
+	keyJSON, err := keystore.EncryptKey(
+		networkKey,
+		password,
+		keystore.StandardScryptN,
+		keystore.StandardScryptP,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not encrypt network key: [%v]", err)
+	}
+
+	if err := ioutil.WriteFile(keyFile, keyJSON, 0600); err != nil {
+		return nil, fmt.Errorf(
+			"could not write network key file [%v]: [%v]",
+			keyFile,
+			err,
+		)
+	}
+
+	logger.Infof("created new network key file [%v]", keyFile)
+
+	networkPrivateKey, _ := key.OperatorKeyToNetworkKey(
+		operator.ChainKeyToOperatorKey(networkKey),
+	)
+	return networkPrivateKey, nil
+}
+
+// readOrSignDelegation reads the operator's delegation of the network key from
+// the provided delegation file. If the file does not exist, the stored
+// delegation has not been issued by the operator for the network key or it is
+// about to expire, a new delegation is signed with the operator key and stored
+// in the file. The new delegation has a sequence number higher than the stored
+// one so that peers reject the stored delegation once they see the new one.
+func readOrSignDelegation(
+	delegationFile string,
+	networkPublicKey *key.NetworkPublic,
+	operatorSigning chain.Signing,
+	operatorAddress string,
+) (*key.Delegation, error) {
+	stored, err := readDelegation(delegationFile, operatorSigning)
+	if err != nil {
+		return nil, err
+	}
+
+	var sequence uint64
+	if stored != nil {
+		if stored.Verify(networkPublicKey) == nil &&
+			time.Until(stored.ExpiresAt()) > delegationRenewalPeriod {
+			return stored, nil
+		}
+
+		logger.Infof(
+			"delegation file [%v] does not delegate the network key "+
+				"on behalf of operator [%v] or it is about to expire; "+
+				"signing a new delegation",
+			delegationFile,
+			operatorAddress,
+		)
+
+		sequence = stored.Sequence()
+	}
+
+	return signDelegation(
+		delegationFile,
+		networkPublicKey,
+		operatorSigning,
+		sequence+1,
+	)
+}
+
+// readDelegation reads the delegation issued by the operator from the provided
+// delegation file. Nil is returned if the file does not exist or it does not
+// hold a delegation issued by the operator.
+func readDelegation(
+	delegationFile string,
+	operatorSigning chain.Signing,
+) (*key.Delegation, error) {
+	delegationBytes, err := ioutil.ReadFile(delegationFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf(
+			"could not read delegation file [%v]: [%v]",
+			delegationFile,
+			err,
+		)
+	}
+
+	delegation, err := key.UnmarshalDelegation(delegationBytes)
+	if err != nil || !bytes.Equal(
+		operator.Marshal(delegation.OperatorPublicKey()),
+		operatorSigning.PublicKey(),
+	) {
+		logger.Warningf(
+			"delegation file [%v] does not hold a delegation of the operator",
+			delegationFile,
+		)
+		return nil, nil
+	}
+
+	return delegation, nil
+}
+
+// signDelegation signs the delegation of the network key with the given
+// sequence number using the operator key and stores it in the provided
+// delegation file. The delegation is valid for the delegation validity period.
+func signDelegation(
+	delegationFile string,
+	networkPublicKey *key.NetworkPublic,
+	operatorSigning chain.Signing,
+	sequence uint64,
+) (*key.Delegation, error) {
+	operatorPublicKey, err := operator.Unmarshal(operatorSigning.PublicKey())
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal operator key: [%v]", err)
+	}
+
+	delegation, err := key.SignDelegation(
+		networkPublicKey,
+		operatorPublicKey,
+		sequence,
+		time.Now().Add(delegationValidity),
+		operatorSigning.Sign,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	delegationBytes, err := delegation.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal delegation: [%v]", err)
+	}
+
+	if err := ioutil.WriteFile(delegationFile, delegationBytes, 0600); err != nil {
+		return nil, fmt.Errorf(
+			"could not write delegation file [%v]: [%v]",
+			delegationFile,
+			err,
+		)
+	}
+
+	logger.Infof(
+		"signed delegation [%v] with sequence [%v] valid until [%v]",
+		delegationFile,
+		delegation.Sequence(),
+		delegation.ExpiresAt(),
+	)
+
+	return delegation, nil
+}
+
+// keepRenewed renews the delegation of the network key before it expires and
+// presents the renewed delegation to peers through the provided network
+// provider. Renewal stops when the context is done or when the delegation
+// file holds a newer delegation of another network key, that is, when the
+// network key has been rotated. The client has to be restarted then to use
+// the new network key.
+func (nkd *networkKeyDelegation) keepRenewed(
+	ctx context.Context,
+	provider net.Provider,
+) {
+	delegated, ok := provider.(libp2p.Delegated)
+	if !ok {
+		logger.Warningf(
+			"network provider does not support delegation renewal; "+
+				"delegation of operator [%v] will not be renewed",
+			nkd.operatorAddress,
+		)
+		return
+	}
+
+	ticker := time.NewTicker(delegationRenewalCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if time.Until(nkd.delegation.ExpiresAt()) > delegationRenewalPeriod {
+				continue
+			}
+
+			stored, err := readDelegation(nkd.file, nkd.operatorSigning)
+			if err != nil {
+				logger.Errorf("could not renew delegation: [%v]", err)
+				continue
+			}
+			if stored != nil &&
+				stored.Sequence() > nkd.delegation.Sequence() &&
+				stored.Verify(nkd.networkPublicKey) != nil {
+				logger.Warningf(
+					"network key of operator [%v] has been rotated; "+
+						"restart the client to use the new network key",
+					nkd.operatorAddress,
+				)
+				return
+			}
+
+			delegation, err := readOrSignDelegation(
+				nkd.file,
+				nkd.networkPublicKey,
+				nkd.operatorSigning,
+				nkd.operatorAddress,
+			)
+			if err != nil {
+				logger.Errorf("could not renew delegation: [%v]", err)
+				continue
+			}
+
+			if err := delegated.UpdateDelegation(delegation); err != nil {
+				logger.Errorf("could not present renewed delegation: [%v]", err)
+				continue
+			}
+
+			nkd.delegation = delegation
+		case <-ctx.Done():
+			return
+		}
+	}
+}
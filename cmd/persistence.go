@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/keep-network/keep-common/pkg/persistence"
+)
+
+// diskHandles opens disk persistence handles of the client and keeps track of
+// them so that all writes in progress can be completed before the client
+// exits.
+type diskHandles struct {
+	mutex   sync.Mutex
+	handles []*flushableHandle
+}
+
+// open creates a disk persistence handle for the given directory.
+func (dh *diskHandles) open(path string) (persistence.Handle, error) {
+	handle, err := persistence.NewDiskHandle(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dh.mutex.Lock()
+	defer dh.mutex.Unlock()
+
+	flushable := &flushableHandle{Handle: handle}
+	dh.handles = append(dh.handles, flushable)
+
+	return flushable, nil
+}
+
+// flush blocks until all writes in progress of all opened handles complete.
+// All writes attempted after the flush fail.
+func (dh *diskHandles) flush() {
+	dh.mutex.Lock()
+	defer dh.mutex.Unlock()
+
+	for _, handle := range dh.handles {
+		handle.flush()
+	}
+}
+
+// flushableHandle is a persistence handle which tracks writes in progress.
+// Once flushed, the handle no longer accepts writes so that no write is
+// interrupted by the client exit.
+type flushableHandle struct {
+	persistence.Handle
+
+	// Writes hold the read lock for their duration so that the flush,
+	// acquiring the write lock, waits for all of them to complete.
+	mutex   sync.RWMutex
+	flushed bool
+}
+
+func (fh *flushableHandle) Save(data []byte, directory, name string) error {
+	return fh.write(func() error {
+		return fh.Handle.Save(data, directory, name)
+	})
+}
+
+func (fh *flushableHandle) Snapshot(data []byte, directory, name string) error {
+	return fh.write(func() error {
+		return fh.Handle.Snapshot(data, directory, name)
+	})
+}
+
+func (fh *flushableHandle) Archive(directory string) error {
+	return fh.write(func() error {
+		return fh.Handle.Archive(directory)
+	})
+}
+
+func (fh *flushableHandle) write(write func() error) error {
+	fh.mutex.RLock()
+	defer fh.mutex.RUnlock()
+
+	if fh.flushed {
+		return fmt.Errorf("persistence handle has been flushed")
+	}
+
+	return write()
+}
+
+func (fh *flushableHandle) flush() {
+	fh.mutex.Lock()
+	defer fh.mutex.Unlock()
+
+	fh.flushed = true
+}
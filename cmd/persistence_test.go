@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestFlushWaitsForWritesInProgress(t *testing.T) {
+	dataDir, err := ioutil.TempDir("", "keep-persistence")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dataDir)
+
+	handles := &diskHandles{}
+	handle, err := handles.open(dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	flushable := handle.(*flushableHandle)
+
+	writeStarted := make(chan struct{})
+	writeCompleted := make(chan struct{})
+	go flushable.write(func() error {
+		close(writeStarted)
+		time.Sleep(100 * time.Millisecond)
+		close(writeCompleted)
+		return nil
+	})
+
+	<-writeStarted
+	handles.flush()
+
+	select {
+	case <-writeCompleted:
+	default:
+		t.Errorf("flush returned before the write in progress completed")
+	}
+
+	if err := handle.Save([]byte{1}, "dir", "file"); err == nil {
+		t.Errorf("expected write after the flush to fail")
+	}
+}
+
+func TestWriteBeforeFlush(t *testing.T) {
+	dataDir, err := ioutil.TempDir("", "keep-persistence")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dataDir)
+
+	handles := &diskHandles{}
+	handle, err := handles.open(dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := handle.Save([]byte{1}, "dir", "file"); err != nil {
+		t.Fatal(err)
+	}
+
+	descriptors, errors := handle.ReadAll()
+	go func() {
+		for err := range errors {
+			t.Error(err)
+		}
+	}()
+
+	count := 0
+	for range descriptors {
+		count++
+	}
+	if count != 1 {
+		t.Errorf(
+			"unexpected number of persisted files\nexpected: [1]\nactual:   [%v]",
+			count,
+		)
+	}
+}
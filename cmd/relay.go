@@ -1,13 +1,16 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"time"
 
 	"github.com/keep-network/keep-core/config"
 	"github.com/keep-network/keep-core/pkg/beacon/relay/event"
+	"github.com/keep-network/keep-core/pkg/chain"
 	"github.com/keep-network/keep-core/pkg/chain/ethereum"
+	"github.com/keep-network/keep-core/pkg/chain/standin"
 	"github.com/urfave/cli"
 )
 
@@ -46,14 +49,14 @@ func init() {
 // request id. By default, it also waits until the associated relay entry is
 // generated and prints out the entry.
 func relayRequest(c *cli.Context) error {
-	cfg, err := config.ReadConfig(c.GlobalString("config"))
+	cfg, err := readConfig(c)
 	if err != nil {
 		return fmt.Errorf("error reading config file: [%v]", err)
 	}
 
-	utility, err := ethereum.ConnectUtility(cfg.Ethereum)
+	utility, err := connectUtility(cfg)
 	if err != nil {
-		return fmt.Errorf("error connecting to Ethereum node: [%v]", err)
+		return err
 	}
 
 	wait := make(chan struct{})
@@ -86,14 +89,14 @@ func relayRequest(c *cli.Context) error {
 
 // genesis kicks off protocol to create the first group.
 func genesis(c *cli.Context) error {
-	cfg, err := config.ReadConfig(c.GlobalString("config"))
+	cfg, err := readConfig(c)
 	if err != nil {
 		return fmt.Errorf("error reading config file: [%v]", err)
 	}
 
-	utility, err := ethereum.ConnectUtility(cfg.Ethereum)
+	utility, err := connectUtility(cfg)
 	if err != nil {
-		return fmt.Errorf("error connecting to Ethereum node: [%v]", err)
+		return err
 	}
 
 	err = utility.Genesis()
@@ -102,3 +105,30 @@ func genesis(c *cli.Context) error {
 	}
 	return nil
 }
+
+// connectUtility connects to the chain stand-in if it is configured or to
+// Ethereum otherwise. The stand-in accepts requests on behalf of the
+// configured operator.
+func connectUtility(cfg *config.Config) (chain.Utility, error) {
+	if cfg.StandIn.URL == "" {
+		utility, err := ethereum.ConnectUtility(cfg.Ethereum)
+		if err != nil {
+			return nil, fmt.Errorf("error connecting to Ethereum node: [%v]", err)
+		}
+		return utility, nil
+	}
+
+	ctx := context.Background()
+
+	operatorSigner, err := connectOperatorSigner(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	utility, err := standin.Connect(ctx, cfg.StandIn.URL, operatorSigner)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to the chain stand-in: [%v]", err)
+	}
+
+	return utility, nil
+}
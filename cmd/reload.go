@@ -0,0 +1,283 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/keep-network/keep-common/pkg/logging"
+	"github.com/keep-network/keep-core/config"
+	"github.com/keep-network/keep-core/pkg/chain/ethereum"
+	"github.com/keep-network/keep-core/pkg/metrics"
+	"github.com/keep-network/keep-core/pkg/net"
+	"github.com/keep-network/keep-core/pkg/net/libp2p"
+	"github.com/urfave/cli"
+)
+
+// configReloader re-reads the config of the running client and applies
+// changes of the reloadable config fields to the client components. Changes of
+// other fields are reported and applied only after restart. Reloads are
+// serialized.
+type configReloader struct {
+	read func(current *config.Config) (*config.Config, error)
+
+	mutex    sync.Mutex
+	current  *config.Config
+	handlers []reloadHandler
+}
+
+// reloadHandler applies changes of the given config fields to a single
+// component of the running client.
+type reloadHandler struct {
+	fields []string
+	apply  func(reloaded *config.Config) error
+}
+
+// newConfigReloader creates a reloader of the provided current config. The
+// config is re-read from the config file pointed by the global config flag,
+// with the same environment variables, global config override flags and the
+// provided command flag values applied.
+func newConfigReloader(
+	c *cli.Context,
+	commandFlagValues map[string]string,
+	current *config.Config,
+) *configReloader {
+	return &configReloader{
+		read: func(current *config.Config) (*config.Config, error) {
+			return config.ReloadConfig(
+				c.GlobalString("config"),
+				overrideFlagValues(c, commandFlagValues),
+				current,
+			)
+		},
+		current: current,
+	}
+}
+
+// onChange registers the function applying changes of any of the given config
+// fields. The function is called with the reloaded config only if at least
+// one of the fields has changed.
+func (cr *configReloader) onChange(
+	fields []string,
+	apply func(reloaded *config.Config) error,
+) {
+	cr.mutex.Lock()
+	defer cr.mutex.Unlock()
+
+	cr.handlers = append(cr.handlers, reloadHandler{fields, apply})
+}
+
+// config returns the most recently loaded config.
+func (cr *configReloader) config() *config.Config {
+	cr.mutex.Lock()
+	defer cr.mutex.Unlock()
+
+	return cr.current
+}
+
+// reload re-reads and validates the config, and applies changes of the
+// reloadable fields. Returns paths of all the changed fields which have been
+// applied. If the config could not be read or is invalid, no change is
+// applied. If changes of some fields could not be applied, changes of the
+// other fields are still applied. The current config is updated only with the
+// applied changes so that the failed changes are applied again on the next
+// reload and changes of fields which are not reloadable keep being reported
+// until the client is restarted.
+func (cr *configReloader) reload() ([]string, error) {
+	cr.mutex.Lock()
+	defer cr.mutex.Unlock()
+
+	reloaded, err := cr.read(cr.current)
+	if err != nil {
+		return nil, fmt.Errorf("could not read config: [%v]", err)
+	}
+
+	if problems := reloaded.Validate(); len(problems) > 0 {
+		return nil, fmt.Errorf("invalid config: %v", problems)
+	}
+
+	changedFields := cr.current.ChangedFields(reloaded)
+	changed := make(map[string]bool)
+	for _, field := range changedFields {
+		changed[field] = true
+	}
+
+	// Several handlers can apply changes of the same field, for example
+	// LibP2P fields are applied to the network provider of each operator.
+	// A field is applied only if all of its handlers succeed.
+	handled := make(map[string]bool)
+	failed := make(map[string]bool)
+	var applyErrors []error
+	for _, handler := range cr.handlers {
+		var handlerChanged []string
+		for _, field := range handler.fields {
+			if changed[field] {
+				handlerChanged = append(handlerChanged, field)
+				handled[field] = true
+			}
+		}
+
+		if len(handlerChanged) == 0 {
+			continue
+		}
+
+		if err := handler.apply(reloaded); err != nil {
+			for _, field := range handlerChanged {
+				failed[field] = true
+			}
+			applyErrors = append(applyErrors, fmt.Errorf(
+				"could not apply changes of %v: [%v]",
+				handlerChanged,
+				err,
+			))
+			continue
+		}
+
+		logger.Infof("applied changes of %v", handlerChanged)
+	}
+
+	var applied []string
+	for _, field := range changedFields {
+		if handled[field] && !failed[field] {
+			applied = append(applied, field)
+		}
+	}
+
+	for _, field := range changedFields {
+		if !handled[field] {
+			logger.Warningf(
+				"change of [%v] will be applied only after restart",
+				field,
+			)
+		}
+	}
+
+	cr.current = cr.current.WithFields(reloaded, applied)
+
+	if len(applyErrors) > 0 {
+		return applied, fmt.Errorf("%v", applyErrors)
+	}
+
+	return applied, nil
+}
+
+// handleReloadSignals reloads the config on each SIGHUP received until the
+// context is done.
+func handleReloadSignals(ctx context.Context, reloader *configReloader) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case received := <-signals:
+			logger.Infof("received [%v] signal; reloading config", received)
+
+			if _, err := reloader.reload(); err != nil {
+				logger.Errorf("could not reload config: [%v]", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// reloadLogging applies changes of log levels.
+func reloadLogging(reloader *configReloader) {
+	reloader.onChange(
+		[]string{"Logging.Level"},
+		configureLogging,
+	)
+}
+
+// reloadEthereum applies changes of the Ethereum request rate limits, the
+// mining check interval and the max gas price to all chain handles.
+func reloadEthereum(
+	reloader *configReloader,
+	reconfigurer *ethereum.Reconfigurer,
+) {
+	reloader.onChange(
+		[]string{
+			"Ethereum.RequestsPerSecondLimit",
+			"Ethereum.ConcurrencyLimit",
+			"Ethereum.MiningCheckInterval",
+			"Ethereum.MaxGasPrice",
+		},
+		func(reloaded *config.Config) error {
+			return reconfigurer.Reconfigure(reloaded.Ethereum)
+		},
+	)
+}
+
+// reloadNetwork applies changes of the bootstrap peers, announced addresses
+// and the dissemination time to the network provider of the primary operator,
+// if the provider supports that.
+func reloadNetwork(reloader *configReloader, netProvider net.Provider) {
+	reconfigureNetwork(
+		reloader,
+		netProvider,
+		func(reloaded *config.Config) libp2p.Config {
+			return reloaded.LibP2P
+		},
+	)
+}
+
+func reconfigureNetwork(
+	reloader *configReloader,
+	netProvider net.Provider,
+	libp2pConfig func(reloaded *config.Config) libp2p.Config,
+) {
+	reconfigurable, ok := netProvider.(libp2p.Reconfigurable)
+	if !ok {
+		return
+	}
+
+	reloader.onChange(
+		[]string{
+			"LibP2P.Peers",
+			"LibP2P.AnnouncedAddresses",
+			"LibP2P.DisseminationTime",
+		},
+		func(reloaded *config.Config) error {
+			return reconfigurable.Reconfigure(libp2pConfig(reloaded))
+		},
+	)
+}
+
+// reloadMetricsTicks applies changes of the metrics observation ticks.
+func reloadMetricsTicks(
+	reloader *configReloader,
+	networkMetricsTick *metrics.Tick,
+	ethereumMetricsTick *metrics.Tick,
+) {
+	reloader.onChange(
+		[]string{
+			"Metrics.NetworkMetricsTick",
+			"Metrics.EthereumMetricsTick",
+		},
+		func(reloaded *config.Config) error {
+			networkMetricsTick.Set(
+				time.Duration(reloaded.Metrics.NetworkMetricsTick) * time.Second,
+			)
+			ethereumMetricsTick.Set(
+				time.Duration(reloaded.Metrics.EthereumMetricsTick) * time.Second,
+			)
+			return nil
+		},
+	)
+}
+
+// configureLogging sets log levels from the config. If log levels are not
+// set in the config, levels from the LOG_LEVEL environment variable are used.
+func configureLogging(config *config.Config) error {
+	level := config.Logging.Level
+	if level == "" {
+		level = os.Getenv("LOG_LEVEL")
+	}
+
+	return logging.Configure(level)
+}
@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/keep-network/keep-common/pkg/chain/ethereum"
+	"github.com/keep-network/keep-common/pkg/chain/ethlike"
+	"github.com/keep-network/keep-core/config"
+	"github.com/keep-network/keep-core/pkg/net/libp2p"
+)
+
+func TestReloadKeepsChangesAppliedBeforeFailure(t *testing.T) {
+	current, cleanup := newTestReloadConfig(t)
+	defer cleanup()
+
+	reloaded := *current
+	reloaded.Ethereum.RequestsPerSecondLimit = 150
+	reloaded.Metrics.NetworkMetricsTick = 30
+
+	reloader := &configReloader{
+		read: func(*config.Config) (*config.Config, error) {
+			copied := reloaded
+			return &copied, nil
+		},
+		current: current,
+	}
+
+	failing := true
+	reloader.onChange(
+		[]string{"Ethereum.RequestsPerSecondLimit"},
+		func(*config.Config) error { return nil },
+	)
+	reloader.onChange(
+		[]string{"Metrics.NetworkMetricsTick"},
+		func(*config.Config) error {
+			if failing {
+				return fmt.Errorf("metrics unavailable")
+			}
+			return nil
+		},
+	)
+
+	applied, err := reloader.reload()
+	if err == nil {
+		t.Fatal("expected reload error")
+	}
+
+	expectedApplied := []string{"Ethereum.RequestsPerSecondLimit"}
+	if !reflect.DeepEqual(expectedApplied, applied) {
+		t.Errorf(
+			"unexpected applied fields\nexpected: [%v]\nactual:   [%v]",
+			expectedApplied,
+			applied,
+		)
+	}
+
+	if reloader.config().Ethereum.RequestsPerSecondLimit != 150 {
+		t.Errorf("applied change has not been recorded in the current config")
+	}
+	if reloader.config().Metrics.NetworkMetricsTick != 0 {
+		t.Errorf("failed change has been recorded in the current config")
+	}
+
+	failing = false
+
+	applied, err = reloader.reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedApplied = []string{"Metrics.NetworkMetricsTick"}
+	if !reflect.DeepEqual(expectedApplied, applied) {
+		t.Errorf(
+			"unexpected applied fields\nexpected: [%v]\nactual:   [%v]",
+			expectedApplied,
+			applied,
+		)
+	}
+}
+
+func TestReloadAppliesSharedFieldsToAllHandlers(t *testing.T) {
+	current, cleanup := newTestReloadConfig(t)
+	defer cleanup()
+
+	reloaded := *current
+	reloaded.LibP2P.DisseminationTime = 60
+
+	reloader := &configReloader{
+		read: func(*config.Config) (*config.Config, error) {
+			copied := reloaded
+			return &copied, nil
+		},
+		current: current,
+	}
+
+	calls := 0
+	for i := 0; i < 2; i++ {
+		reloader.onChange(
+			[]string{"LibP2P.DisseminationTime"},
+			func(*config.Config) error {
+				calls++
+				return nil
+			},
+		)
+	}
+
+	if _, err := reloader.reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	if calls != 2 {
+		t.Errorf(
+			"unexpected number of handler calls\nexpected: [%v]\nactual:   [%v]",
+			2,
+			calls,
+		)
+	}
+}
+
+func TestReloadKeepsReportingNonReloadableChanges(t *testing.T) {
+	current, cleanup := newTestReloadConfig(t)
+	defer cleanup()
+
+	reloaded := *current
+	reloaded.Ethereum.RequestsPerSecondLimit = 150
+	reloaded.LibP2P.Port = 3920
+
+	reloader := &configReloader{
+		read: func(*config.Config) (*config.Config, error) {
+			copied := reloaded
+			return &copied, nil
+		},
+		current: current,
+	}
+
+	reloader.onChange(
+		[]string{"Ethereum.RequestsPerSecondLimit"},
+		func(*config.Config) error { return nil },
+	)
+
+	for i := 0; i < 2; i++ {
+		if _, err := reloader.reload(); err != nil {
+			t.Fatal(err)
+		}
+
+		if reloader.config().Ethereum.RequestsPerSecondLimit != 150 {
+			t.Errorf("applied change has not been recorded in the current config")
+		}
+
+		// The change of the port is not reloadable so it is still reported
+		// as changed on every reload until the client is restarted.
+		expectedPending := []string{"LibP2P.Port"}
+		pending := reloader.config().ChangedFields(&reloaded)
+		if !reflect.DeepEqual(expectedPending, pending) {
+			t.Errorf(
+				"unexpected fields pending restart\nexpected: [%v]\nactual:   [%v]",
+				expectedPending,
+				pending,
+			)
+		}
+	}
+}
+
+func newTestReloadConfig(t *testing.T) (*config.Config, func()) {
+	dataDir, err := ioutil.TempDir("", "keep-reload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(dataDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	keyFile := filepath.Join(dataDir, "operator-key")
+	if err := ioutil.WriteFile(keyFile, []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Ethereum: ethereum.Config{
+			Config: ethlike.Config{
+				URL: "ws://localhost:8546",
+				ContractAddresses: map[string]string{
+					"KeepRandomBeaconOperator": "0xcf64c2a367341170cb4e09cf8c0ed137d8473ceb",
+					"KeepRandomBeaconService":  "0xcf64c2a367341170cb4e09cf8c0ed137d8473ceb",
+					"TokenStaking":             "0xcf64c2a367341170cb4e09cf8c0ed137d8473ceb",
+				},
+				Account: ethlike.Account{KeyFile: keyFile},
+			},
+		},
+		LibP2P:  libp2p.Config{Port: 3919},
+		Storage: config.Storage{DataDir: dataDir},
+	}
+
+	if problems := cfg.Validate(); len(problems) > 0 {
+		t.Fatalf("invalid test config: [%v]", problems)
+	}
+
+	return cfg, func() { os.RemoveAll(dataDir) }
+}
@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ethereum/go-ethereum/common"
+	relaychain "github.com/keep-network/keep-core/pkg/beacon/relay/chain"
+	"github.com/keep-network/keep-core/pkg/beacon/relay/groupselection"
+	"github.com/urfave/cli"
+)
+
+// SimulateCommand contains the definition of the simulate command-line
+// subcommand and its own subcommands.
+var SimulateCommand cli.Command
+
+const (
+	stakesFlag                  = "stakes"
+	minimumStakeFlag            = "minimum-stake"
+	groupSizeFlag               = "group-size"
+	ticketSubmissionTimeoutFlag = "ticket-submission-timeout"
+)
+
+const simulateDescription = `The simulate command predicts outcomes of the
+	protocol offline, without connecting to the chain or the network.
+
+	The "group-selection" subcommand predicts which operators and which of
+	their virtual stakers would be selected to the group for the given relay
+	entry used as the group selection seed, in hexadecimal format. Stakes of
+	operators are read from a CSV file with an operator address and a stake in
+	each line; the stakes and the minimum stake have to be integers expressed
+	in the same unit. Only the listed operators are assumed to take part in the
+	group selection, all of them submitting tickets according to the protocol
+	rounds. For each operator, the number of tickets submitted in each round is
+	reported along with the number of selected virtual stakers and, regardless
+	of the seed, the expected number of selected virtual stakers and the chance
+	of at least one of them being selected.`
+
+func init() {
+	SimulateCommand = cli.Command{
+		Name:        "simulate",
+		Usage:       "Simulates the protocol offline.",
+		Description: simulateDescription,
+		Subcommands: []cli.Command{
+			{
+				Name:   "group-selection",
+				Usage:  "Predicts the group selected for the given seed.",
+				Action: simulateGroupSelection,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  seedFlag,
+						Usage: "relay entry used as the group selection seed",
+					},
+					&cli.StringFlag{
+						Name:  stakesFlag,
+						Usage: "CSV file with operator addresses and their stakes",
+					},
+					&cli.StringFlag{
+						Name:  minimumStakeFlag,
+						Usage: "minimum stake, in the same unit as stakes",
+					},
+					&cli.IntFlag{
+						Name:  groupSizeFlag,
+						Value: 64,
+						Usage: "number of members of the group",
+					},
+					&cli.Uint64Flag{
+						Name:  ticketSubmissionTimeoutFlag,
+						Value: 78,
+						Usage: "ticket submission timeout in blocks",
+					},
+				},
+			},
+		},
+	}
+}
+
+// simulateGroupSelection simulates the group selection for the provided
+// seed and stakes and prints the selected group and per-operator summary.
+func simulateGroupSelection(c *cli.Context) error {
+	seed, ok := new(big.Int).SetString(
+		strings.TrimPrefix(c.String(seedFlag), "0x"),
+		16,
+	)
+	if !ok {
+		return fmt.Errorf(
+			"invalid seed [%v]; expected hexadecimal value",
+			c.String(seedFlag),
+		)
+	}
+
+	minimumStake, ok := new(big.Int).SetString(c.String(minimumStakeFlag), 10)
+	if !ok || minimumStake.Sign() <= 0 {
+		return fmt.Errorf(
+			"invalid minimum stake [%v]; expected positive integer",
+			c.String(minimumStakeFlag),
+		)
+	}
+
+	if c.String(stakesFlag) == "" {
+		return fmt.Errorf("stakes file is required")
+	}
+
+	stakesFile, err := os.Open(c.String(stakesFlag))
+	if err != nil {
+		return fmt.Errorf("could not open stakes file: [%v]", err)
+	}
+	defer stakesFile.Close()
+
+	stakers, err := readStakes(stakesFile)
+	if err != nil {
+		return fmt.Errorf("could not read stakes file: [%v]", err)
+	}
+
+	result, err := groupselection.Simulate(
+		seed,
+		stakers,
+		&relaychain.Config{
+			GroupSize:               c.Int(groupSizeFlag),
+			TicketSubmissionTimeout: c.Uint64(ticketSubmissionTimeoutFlag),
+		},
+		minimumStake,
+	)
+	if err != nil {
+		return fmt.Errorf("could not simulate group selection: [%v]", err)
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(writer, "MEMBER\tOPERATOR\tVIRTUAL STAKER\tTICKET")
+	for i, member := range result.Members {
+		fmt.Fprintf(
+			writer,
+			"%v\t%v\t%v\t%016x\n",
+			i+1,
+			common.BytesToAddress(member.Address).Hex(),
+			member.VirtualStakerIndex,
+			member.TicketValue,
+		)
+	}
+
+	fmt.Fprintln(writer)
+	fmt.Fprintln(
+		writer,
+		"OPERATOR\tVIRTUAL STAKERS\tTICKETS PER ROUND\tSELECTED\t"+
+			"EXPECTED\tCHANCE",
+	)
+	for _, staker := range result.Stakers {
+		ticketsPerRound := make([]string, len(staker.SubmittedTickets))
+		for i, tickets := range staker.SubmittedTickets {
+			ticketsPerRound[i] = fmt.Sprint(tickets)
+		}
+
+		fmt.Fprintf(
+			writer,
+			"%v\t%v\t%v\t%v\t%.2f\t%.2f%%\n",
+			common.BytesToAddress(staker.Address).Hex(),
+			staker.VirtualStakers,
+			strings.Join(ticketsPerRound, "/"),
+			staker.SelectedMembers,
+			staker.ExpectedMembers,
+			staker.SelectionChance*100,
+		)
+	}
+
+	return writer.Flush()
+}
+
+// readStakes reads operator addresses and their stakes from CSV records.
+// Empty lines and lines starting with # are skipped, as well as the header
+// line if present.
+func readStakes(reader io.Reader) ([]*groupselection.SimulatedStaker, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.Comment = '#'
+	csvReader.FieldsPerRecord = 2
+	csvReader.TrimLeadingSpace = true
+
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	stakers := make([]*groupselection.SimulatedStaker, 0, len(records))
+	for i, record := range records {
+		address := strings.TrimSpace(record[0])
+		stake, ok := new(big.Int).SetString(strings.TrimSpace(record[1]), 10)
+
+		if i == 0 && !common.IsHexAddress(address) && !ok {
+			continue
+		}
+
+		if !common.IsHexAddress(address) {
+			return nil, fmt.Errorf(
+				"invalid operator address [%v] in record [%v]",
+				address,
+				i+1,
+			)
+		}
+		if !ok || stake.Sign() < 0 {
+			return nil, fmt.Errorf(
+				"invalid stake [%v] in record [%v]",
+				record[1],
+				i+1,
+			)
+		}
+
+		stakers = append(stakers, &groupselection.SimulatedStaker{
+			Address: common.HexToAddress(address).Bytes(),
+			Stake:   stake,
+		})
+	}
+
+	return stakers, nil
+}
@@ -3,24 +3,38 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"syscall"
 	"time"
 
+	"github.com/keep-network/keep-core/pkg/admin"
 	"github.com/keep-network/keep-core/pkg/diagnostics"
 	"github.com/keep-network/keep-core/pkg/metrics"
 	"github.com/keep-network/keep-core/pkg/net"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ipfs/go-log"
 	"github.com/keep-network/keep-common/pkg/chain/ethereum/ethutil"
+	"github.com/keep-network/keep-common/pkg/chain/ethlike"
 	"github.com/keep-network/keep-common/pkg/persistence"
 	"github.com/keep-network/keep-core/config"
 	"github.com/keep-network/keep-core/pkg/beacon"
+	"github.com/keep-network/keep-core/pkg/beacon/relay"
+	"github.com/keep-network/keep-core/pkg/beacon/relay/groupselection"
 	"github.com/keep-network/keep-core/pkg/chain"
 	"github.com/keep-network/keep-core/pkg/chain/ethereum"
+	"github.com/keep-network/keep-core/pkg/chain/ethereum/signer"
+	"github.com/keep-network/keep-core/pkg/chain/shadow"
+	"github.com/keep-network/keep-core/pkg/chain/standin"
 	"github.com/keep-network/keep-core/pkg/firewall"
+	"github.com/keep-network/keep-core/pkg/journal"
 	"github.com/keep-network/keep-core/pkg/net/key"
 	"github.com/keep-network/keep-core/pkg/net/libp2p"
 	"github.com/keep-network/keep-core/pkg/net/retransmission"
-	"github.com/keep-network/keep-core/pkg/operator"
 	"github.com/urfave/cli"
 )
 
@@ -36,10 +50,20 @@ const (
 	portShort         = "p"
 	waitForStakeFlag  = "wait-for-stake"
 	waitForStakeShort = "w"
+	shadowFlag        = "shadow"
 )
 
+// shutdownCheckInterval is the interval in which the graceful shutdown
+// process checks whether all protocol executions completed.
+const shutdownCheckInterval = 5 * time.Second
+
 const startDescription = `Starts the Keep client in the foreground. Currently this only consists of the
-   threshold relay client for the Keep random beacon.`
+   threshold relay client for the Keep random beacon.
+
+   With the "shadow" flag, the client follows the chain and takes part in
+   all protocols but never submits any transaction. Transactions the client
+   would submit are logged and recorded in the event journal instead. The
+   operator does not need to have the minimum stake in this mode.`
 
 func init() {
 	StartCommand =
@@ -55,6 +79,10 @@ func init() {
 				&cli.IntFlag{
 					Name: waitForStakeFlag + "," + waitForStakeShort,
 				},
+				&cli.BoolFlag{
+					Name:  shadowFlag,
+					Usage: "run without submitting any transactions",
+				},
 			},
 		}
 }
@@ -62,67 +90,189 @@ func init() {
 // Start starts a node; if it's not a bootstrap node it will get the Node.URLs
 // from the config file
 func Start(c *cli.Context) error {
-	ctx := context.Background()
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	defer cancelCtx()
+
+	// Termination signals are handled from the very start so that the client
+	// stopped while still starting up flushes its persistent state as well.
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	clientShutdown := newClientShutdown(cancelCtx)
+	go handleSignals(signals, clientShutdown)
 
-	config, err := config.ReadConfig(c.GlobalString("config"))
+	flagValues := map[string]string{}
+	if c.Int(portFlag) > 0 {
+		flagValues["LibP2P.Port"] = strconv.Itoa(c.Int(portFlag))
+	}
+
+	config, err := readConfigWithFlags(c, flagValues)
 	if err != nil {
 		return fmt.Errorf("error reading config file: %v", err)
 	}
 
-	if c.Int(portFlag) > 0 {
-		config.LibP2P.Port = c.Int(portFlag)
+	if config.Logging.Level != "" {
+		if err := configureLogging(config); err != nil {
+			return fmt.Errorf("could not configure logging: [%v]", err)
+		}
 	}
 
-	ethereumKey, err := ethutil.DecryptKeyFile(
-		config.Ethereum.Account.KeyFile,
+	reloader := newConfigReloader(c, flagValues, config)
+	reloadLogging(reloader)
+
+	// Metrics are updated regardless of whether the metrics server is
+	// enabled.
+	metricsRegistry := metrics.NewRegistry()
+	ethereumMetrics, err := metrics.NewEthereum(metricsRegistry)
+	if err != nil {
+		return err
+	}
+	networkMetrics, err := metrics.NewNetwork(metricsRegistry)
+	if err != nil {
+		return err
+	}
+	protocolMetrics, err := metrics.NewProtocol(metricsRegistry)
+	if err != nil {
+		return err
+	}
+
+	operatorSigner, err := connectOperatorSigner(ctx, config)
+	if err != nil {
+		return err
+	}
+	operatorAddress := operatorSigner.Address().Hex()
+
+	// Persistent state is flushed whenever the client stops, including when
+	// it is stopped before all of its subsystems have been initialized.
+	diskHandles := &diskHandles{}
+	var eventJournal *journal.Journal
+	defer func() {
+		logger.Infof("flushing persistent state")
+		diskHandles.flush()
+		if eventJournal != nil {
+			if err := eventJournal.Close(); err != nil {
+				logger.Errorf("could not close event journal: [%v]", err)
+			}
+		}
+
+		logger.Infof("client stopped")
+	}()
+
+	handle, err := diskHandles.open(config.Storage.DataDir)
+	if err != nil {
+		return fmt.Errorf("failed while creating a storage disk handler: [%v]", err)
+	}
+	persistence := persistence.NewEncryptedPersistence(
+		handle,
 		config.Ethereum.Account.KeyFilePassword,
 	)
-	if err != nil {
-		return fmt.Errorf(
-			"failed to read key file [%s]: [%v]",
-			config.Ethereum.Account.KeyFile,
-			err,
-		)
+
+	// Transactions are neither persisted nor replayed in the shadow mode.
+	isShadow := c.Bool(shadowFlag)
+	outboxPersistence := persistence
+	if isShadow {
+		outboxPersistence = nil
 	}
 
-	chainProvider, err := ethereum.Connect(ctx, config.Ethereum)
+	chainProviders, err := connectChain(
+		ctx,
+		config,
+		reloader,
+		ethereumMetrics,
+		outboxPersistence,
+		operatorSigner,
+	)
 	if err != nil {
-		return fmt.Errorf("error connecting to Ethereum node: [%v]", err)
+		return err
 	}
 
-	blockCounter, err := chainProvider.BlockCounter()
+	blockCounter, err := chainProviders[0].BlockCounter()
 	if err != nil {
 		return err
 	}
 
+	eventJournal, err = journal.Open(
+		config.Storage.DataDir,
+		config.Journal,
+		blockCounter,
+	)
+	if err != nil {
+		return fmt.Errorf("error opening event journal: [%v]", err)
+	}
+
+	if isShadow {
+		logger.Warningf(
+			"running in the shadow mode; no transactions will be submitted",
+		)
+		for i := range chainProviders {
+			address := operatorAddress
+			if i > 0 {
+				address = chainOperatorAddress(chainProviders[i])
+			}
+
+			chainProviders[i] = shadow.Wrap(
+				chainProviders[i],
+				eventJournal.ForOperator(address),
+			)
+		}
+	}
+
+	chainProvider := chainProviders[0]
+
 	stakeMonitor, err := chainProvider.StakeMonitor()
 	if err != nil {
 		return fmt.Errorf("error obtaining stake monitor handle [%v]", err)
 	}
 	if c.Int(waitForStakeFlag) != 0 {
-		err = waitForStake(stakeMonitor, ethereumKey.Address.Hex(), c.Int(waitForStakeFlag))
+		err = waitForStake(
+			ctx,
+			stakeMonitor,
+			operatorAddress,
+			c.Int(waitForStakeFlag),
+		)
 		if err != nil {
 			return err
 		}
 	}
 	hasMinimumStake, err := stakeMonitor.HasMinimumStake(
-		ethereumKey.Address.Hex(),
+		operatorAddress,
 	)
 	if err != nil {
 		return fmt.Errorf("could not check the stake [%v]", err)
 	}
 	if !hasMinimumStake {
-		return fmt.Errorf(
+		if !isShadow {
+			return fmt.Errorf(
+				"no minimum KEEP stake or operator is not authorized to use it; " +
+					"please make sure the operator address in the configuration " +
+					"is correct and it has KEEP tokens delegated and the operator " +
+					"contract has been authorized to operate on the stake",
+			)
+		}
+
+		logger.Warningf(
 			"no minimum KEEP stake or operator is not authorized to use it; " +
-				"please make sure the operator address in the configuration " +
-				"is correct and it has KEEP tokens delegated and the operator " +
-				"contract has been authorized to operate on the stake",
+				"continuing in the shadow mode",
 		)
 	}
 
-	networkPrivateKey, _ := key.OperatorKeyToNetworkKey(
-		operator.ChainKeyToOperatorKey(ethereumKey),
+	networkPrivateKey, delegation, err := operatorNetworkKey(
+		config,
+		operatorSigner,
 	)
+	if err != nil {
+		return err
+	}
+
+	connectOptions := []libp2p.ConnectOption{libp2p.WithMetrics(networkMetrics)}
+	if delegation != nil {
+		connectOptions = append(
+			connectOptions,
+			libp2p.WithDelegation(delegation.delegation),
+		)
+	}
+
 	netProvider, err := libp2p.Connect(
 		ctx,
 		config.LibP2P,
@@ -130,47 +280,460 @@ func Start(c *cli.Context) error {
 		libp2p.ProtocolBeacon,
 		firewall.MinimumStakePolicy(stakeMonitor),
 		retransmission.NewTicker(blockCounter.WatchBlocks(ctx)),
+		connectOptions...,
 	)
 	if err != nil {
 		return err
 	}
 
-	nodeHeader(netProvider.ConnectionManager().AddrStrings(), config.LibP2P.Port)
+	reloadNetwork(reloader, netProvider)
 
-	handle, err := persistence.NewDiskHandle(config.Storage.DataDir)
-	if err != nil {
-		return fmt.Errorf("failed while creating a storage disk handler: [%v]", err)
+	if delegation != nil {
+		go delegation.keepRenewed(ctx, netProvider)
 	}
-	persistence := persistence.NewEncryptedPersistence(
-		handle,
-		config.Ethereum.Account.KeyFilePassword,
-	)
 
-	err = beacon.Initialize(
+	nodeHeader(netProvider.ConnectionManager().AddrStrings(), config.LibP2P.Port)
+
+	beaconHandle, err := beacon.Initialize(
 		ctx,
-		ethereumKey.Address.Hex(),
+		operatorAddress,
 		chainProvider,
 		netProvider,
 		persistence,
+		config.EventConfirmation,
+		ticketOptimizerConfig(config),
+		eventJournal,
+		protocolMetrics,
 	)
 	if err != nil {
 		return fmt.Errorf("error initializing beacon: [%v]", err)
 	}
 
-	initializeMetrics(ctx, config, netProvider, stakeMonitor, ethereumKey.Address.Hex())
+	err = addAdditionalOperators(
+		ctx,
+		config,
+		beaconHandle,
+		chainProviders[1:],
+		stakeMonitor,
+		netProvider,
+		key.Libp2pKeyToNetworkKey(networkPrivateKey.GetPublic()),
+		diskHandles,
+	)
+	if err != nil {
+		return fmt.Errorf("error initializing additional operators: [%v]", err)
+	}
+
+	clientShutdown.setBeacon(beaconHandle)
+
+	go handleReloadSignals(ctx, reloader)
+
+	initializeMetrics(
+		ctx,
+		config,
+		metricsRegistry,
+		reloader,
+		beaconHandle,
+		netProvider,
+		stakeMonitor,
+		operatorAddress,
+	)
 	initializeDiagnostics(ctx, config, netProvider)
+	err = initializeAdmin(
+		ctx,
+		config,
+		reloader,
+		beaconHandle,
+		netProvider,
+		stakeMonitor,
+		clientShutdown.shutdown,
+	)
+	if err != nil {
+		return fmt.Errorf("error initializing admin API: [%v]", err)
+	}
+
+	<-ctx.Done()
+
+	return nil
+}
+
+// drainableBeacon is the beacon drained when the client shuts down gracefully.
+type drainableBeacon interface {
+	Drain()
+	Executions() []*relay.Execution
+}
+
+// clientShutdown coordinates the shutdown of the client. It is created before
+// any subsystem of the client is initialized so that the shutdown can be
+// triggered while the client is still starting up. The beacon is drained only
+// once it has been initialized; until then, the client is stopped right away.
+type clientShutdown struct {
+	stop func()
+
+	once sync.Once
+
+	beaconMutex  sync.Mutex
+	beaconHandle drainableBeacon
+}
+
+func newClientShutdown(stop func()) *clientShutdown {
+	return &clientShutdown{stop: stop}
+}
+
+// setBeacon registers the initialized beacon to be drained on shutdown.
+func (cs *clientShutdown) setBeacon(beaconHandle drainableBeacon) {
+	cs.beaconMutex.Lock()
+	defer cs.beaconMutex.Unlock()
+
+	cs.beaconHandle = beaconHandle
+}
+
+func (cs *clientShutdown) beacon() drainableBeacon {
+	cs.beaconMutex.Lock()
+	defer cs.beaconMutex.Unlock()
+
+	return cs.beaconHandle
+}
+
+// shutdown triggers the graceful shutdown of the client. Only the first call
+// has an effect.
+func (cs *clientShutdown) shutdown() {
+	cs.once.Do(func() {
+		go gracefulShutdown(cs.beacon(), cs.stop)
+	})
+}
+
+// executionsCount returns the number of protocol executions in progress.
+func (cs *clientShutdown) executionsCount() int {
+	beaconHandle := cs.beacon()
+	if beaconHandle == nil {
+		return 0
+	}
+
+	return len(beaconHandle.Executions())
+}
+
+// handleSignals triggers the graceful shutdown on the first SIGINT or SIGTERM
+// received. The graceful shutdown refuses to stop the client in the middle of
+// DKG or relay entry signing. If another signal is received before the graceful
+// shutdown completes, the client is stopped immediately, aborting all protocol
+// executions in progress.
+func handleSignals(signals <-chan os.Signal, clientShutdown *clientShutdown) {
+	received := <-signals
+	logger.Infof("received [%v] signal", received)
+
+	if beaconHandle := clientShutdown.beacon(); beaconHandle != nil {
+		for _, execution := range beaconHandle.Executions() {
+			if execution.Protocol == relay.ProtocolDKG ||
+				execution.Protocol == relay.ProtocolGroupSelection {
+				logger.Warningf(
+					"group selection or DKG is in progress; the client " +
+						"will stop once it completes; send the signal " +
+						"again to force the exit",
+				)
+				break
+			}
+		}
+	}
+
+	clientShutdown.shutdown()
+
+	received = <-signals
+	logger.Warningf(
+		"received [%v] signal again; forcing the exit and aborting "+
+			"[%v] protocol executions in progress",
+		received,
+		clientShutdown.executionsCount(),
+	)
+
+	clientShutdown.stop()
+}
+
+// gracefulShutdown drains the beacon so that no new work is accepted, waits
+// until all protocol executions in progress complete, and then stops the
+// client. If the beacon has not been initialized yet, the client is stopped
+// right away.
+func gracefulShutdown(beaconHandle drainableBeacon, stop func()) {
+	logger.Infof("shutting down gracefully")
+
+	if beaconHandle == nil {
+		logger.Infof("client is still starting up; stopping right away")
+		stop()
+		return
+	}
+
+	beaconHandle.Drain()
 
-	select {
-	case <-ctx.Done():
+	for {
+		executions := beaconHandle.Executions()
+		if len(executions) == 0 {
+			break
+		}
+
+		logger.Infof(
+			"waiting for [%v] protocol executions to complete before shutdown",
+			len(executions),
+		)
+		time.Sleep(shutdownCheckInterval)
+	}
+
+	stop()
+}
+
+// addAdditionalOperators starts hosting the additional operators in the
+// running beacon. Group memberships and the delegation of the network key of
+// each additional operator are kept in a separate subdirectory of the data
+// directory named after the operator address. All operators share the network
+// host of the primary operator; each additional operator delegates the network
+// key of the host and its delegation is attached to all messages sent on
+// behalf of the operator. Operators without the minimum stake are skipped.
+func addAdditionalOperators(
+	ctx context.Context,
+	config *config.Config,
+	beaconHandle *beacon.Handle,
+	chainProviders []chain.Handle,
+	stakeMonitor chain.StakeMonitor,
+	netProvider net.Provider,
+	networkPublicKey *key.NetworkPublic,
+	diskHandles *diskHandles,
+) error {
+	if len(chainProviders) == 0 {
+		return nil
+	}
+
+	operatorHost, ok := netProvider.(libp2p.OperatorHost)
+	if !ok {
+		return fmt.Errorf("network provider can not host additional operators")
+	}
+
+	for i, chainProvider := range chainProviders {
+		address := chainOperatorAddress(chainProvider)
+
+		hasMinimumStake, err := stakeMonitor.HasMinimumStake(address)
 		if err != nil {
-			return err
+			return fmt.Errorf(
+				"could not check the stake of operator [%v]: [%v]",
+				address,
+				err,
+			)
+		}
+		if !hasMinimumStake {
+			logger.Warningf(
+				"no minimum KEEP stake for operator [%v] or operator is "+
+					"not authorized to use it; skipping the operator",
+				address,
+			)
+			continue
+		}
+
+		dataDir := filepath.Join(config.Storage.DataDir, address)
+		if err := os.MkdirAll(dataDir, 0700); err != nil {
+			return fmt.Errorf(
+				"could not create data directory [%v]: [%v]",
+				dataDir,
+				err,
+			)
+		}
+
+		handle, err := diskHandles.open(dataDir)
+		if err != nil {
+			return fmt.Errorf(
+				"failed while creating a storage disk handler for "+
+					"operator [%v]: [%v]",
+				address,
+				err,
+			)
+		}
+
+		delegation, err := hostedOperatorDelegation(
+			dataDir,
+			networkPublicKey,
+			chainProvider.Signing(),
+			address,
+		)
+		if err != nil {
+			return fmt.Errorf(
+				"could not delegate network key on behalf of "+
+					"operator [%v]: [%v]",
+				address,
+				err,
+			)
+		}
+
+		operatorProvider, err := operatorHost.HostOperator(
+			delegation.delegation,
+		)
+		if err != nil {
+			return fmt.Errorf(
+				"could not host operator [%v] in the network: [%v]",
+				address,
+				err,
+			)
 		}
 
-		return fmt.Errorf("uh-oh, we went boom boom for no reason")
+		go delegation.keepRenewed(ctx, operatorProvider)
+
+		err = beaconHandle.AddOperator(
+			ctx,
+			address,
+			chainProvider,
+			operatorProvider,
+			persistence.NewEncryptedPersistence(
+				handle,
+				config.AdditionalOperators[i].KeyFilePassword,
+			),
+		)
+		if err != nil {
+			return fmt.Errorf(
+				"could not add operator [%v]: [%v]",
+				address,
+				err,
+			)
+		}
+
+		logger.Infof("hosting additional operator [%v]", address)
+	}
+
+	return nil
+}
+
+// chainOperatorAddress returns the address of the operator the given chain
+// handle signs on behalf of.
+func chainOperatorAddress(chainProvider chain.Handle) string {
+	signing := chainProvider.Signing()
+	return common.BytesToAddress(
+		signing.PublicKeyBytesToAddress(signing.PublicKey()),
+	).Hex()
+}
+
+// connectChain connects all operators of the client to the chain. The first
+// of the returned handles belongs to the primary operator. If the chain
+// stand-in is configured, the primary operator connects to the stand-in
+// instead of Ethereum.
+func connectChain(
+	ctx context.Context,
+	config *config.Config,
+	reloader *configReloader,
+	ethereumMetrics *metrics.Ethereum,
+	outboxPersistence persistence.Handle,
+	operatorSigner signer.Signer,
+) ([]chain.Handle, error) {
+	if config.StandIn.URL != "" {
+		if len(config.AdditionalOperators) > 0 {
+			return nil, fmt.Errorf(
+				"additional operators are not supported with the chain stand-in",
+			)
+		}
+
+		logger.Warningf(
+			"connecting to the chain stand-in at [%v]; the stand-in is meant "+
+				"for tests and development only",
+			config.StandIn.URL,
+		)
+
+		chainProvider, err := standin.Connect(
+			ctx,
+			config.StandIn.URL,
+			operatorSigner,
+		)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"error connecting to the chain stand-in: [%v]",
+				err,
+			)
+		}
+
+		return []chain.Handle{chainProvider}, nil
+	}
+
+	additionalAccounts := make([]ethlike.Account, len(config.AdditionalOperators))
+	for i, operator := range config.AdditionalOperators {
+		additionalAccounts[i] = operator.Account
+	}
+
+	chainProviders, chainReconfigurer, err := ethereum.ConnectOperators(
+		ctx,
+		config.Ethereum,
+		config.EthereumFailover,
+		config.GasPolicies,
+		ethereumMetrics,
+		outboxPersistence,
+		operatorSigner,
+		additionalAccounts,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to Ethereum node: [%v]", err)
 	}
+
+	reloadEthereum(reloader, chainReconfigurer)
+
+	return chainProviders, nil
 }
 
-func waitForStake(stakeMonitor chain.StakeMonitor, address string, timeout int) error {
+// connectOperatorSigner creates the signer of the primary operator. If
+// a signing service is configured, the operator key is held by that service
+// and only signing requests are sent to it. Otherwise, the operator key is
+// decrypted from the configured key file.
+func connectOperatorSigner(
+	ctx context.Context,
+	config *config.Config,
+) (signer.Signer, error) {
+	if config.Signer.URL != "" {
+		remoteSigner, err := signer.ConnectRemote(
+			ctx,
+			config.Signer.URL,
+			common.HexToAddress(config.Signer.Address),
+		)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"could not connect to the signing service: [%v]",
+				err,
+			)
+		}
+
+		logger.Infof(
+			"using signing service [%v] for operator [%v]",
+			config.Signer.URL,
+			remoteSigner.Address().Hex(),
+		)
+
+		return remoteSigner, nil
+	}
+
+	ethereumKey, err := ethutil.DecryptKeyFile(
+		config.Ethereum.Account.KeyFile,
+		config.Ethereum.Account.KeyFilePassword,
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to read key file [%s]: [%v]",
+			config.Ethereum.Account.KeyFile,
+			err,
+		)
+	}
+
+	return signer.NewLocal(ethereumKey), nil
+}
+
+func ticketOptimizerConfig(
+	config *config.Config,
+) groupselection.OptimizerConfig {
+	optimizerConfig := groupselection.OptimizerConfig{}
+	if reward := config.TicketOptimizer.ExpectedMemberReward; reward != nil {
+		optimizerConfig.ExpectedMemberReward = reward.Int
+	}
+
+	return optimizerConfig
+}
+
+// waitForStake waits until the operator has the minimum stake, checking it
+// every minute for the given number of minutes. Waiting is aborted when the
+// provided context is done.
+func waitForStake(
+	ctx context.Context,
+	stakeMonitor chain.StakeMonitor,
+	address string,
+	timeout int,
+) error {
 	waitMins := 0
 	for waitMins < timeout {
 		hasMinimumStake, err := stakeMonitor.HasMinimumStake(address)
@@ -181,7 +744,15 @@ func waitForStake(stakeMonitor chain.StakeMonitor, address string, timeout int)
 			return nil
 		}
 		logger.Warningf("%s below min stake for %d min \n", address, waitMins)
-		time.Sleep(time.Minute)
+
+		select {
+		case <-time.After(time.Minute):
+		case <-ctx.Done():
+			return fmt.Errorf(
+				"stopped waiting for %s to have required minimum stake",
+				address,
+			)
+		}
 		waitMins++
 	}
 	return fmt.Errorf("timed out waiting for %s to have required minimum stake", address)
@@ -190,11 +761,15 @@ func waitForStake(stakeMonitor chain.StakeMonitor, address string, timeout int)
 func initializeMetrics(
 	ctx context.Context,
 	config *config.Config,
+	registry *metrics.Registry,
+	reloader *configReloader,
+	beaconHandle *beacon.Handle,
 	netProvider net.Provider,
 	stakeMonitor chain.StakeMonitor,
 	ethereumAddress string,
 ) {
-	registry, isConfigured := metrics.Initialize(
+	isConfigured := metrics.Initialize(
+		registry,
 		config.Metrics.Port,
 	)
 	if !isConfigured {
@@ -207,19 +782,32 @@ func initializeMetrics(
 		config.Metrics.Port,
 	)
 
+	networkMetricsTick := metrics.NewTick(
+		time.Duration(config.Metrics.NetworkMetricsTick)*time.Second,
+		metrics.DefaultNetworkMetricsTick,
+	)
+	ethereumMetricsTick := metrics.NewTick(
+		time.Duration(config.Metrics.EthereumMetricsTick)*time.Second,
+		metrics.DefaultEthereumMetricsTick,
+	)
+
+	reloadMetricsTicks(reloader, networkMetricsTick, ethereumMetricsTick)
+
 	metrics.ObserveConnectedPeersCount(
 		ctx,
 		registry,
 		netProvider,
-		time.Duration(config.Metrics.NetworkMetricsTick)*time.Second,
+		networkMetricsTick,
 	)
 
 	metrics.ObserveConnectedBootstrapCount(
 		ctx,
 		registry,
 		netProvider,
-		config.LibP2P.Peers,
-		time.Duration(config.Metrics.NetworkMetricsTick)*time.Second,
+		func() []string {
+			return reloader.config().LibP2P.Peers
+		},
+		networkMetricsTick,
 	)
 
 	metrics.ObserveEthConnectivity(
@@ -227,7 +815,16 @@ func initializeMetrics(
 		registry,
 		stakeMonitor,
 		ethereumAddress,
-		time.Duration(config.Metrics.EthereumMetricsTick)*time.Second,
+		ethereumMetricsTick,
+	)
+
+	metrics.ObserveActiveGroupsCount(
+		ctx,
+		registry,
+		func() int {
+			return len(beaconHandle.Groups())
+		},
+		networkMetricsTick,
 	)
 }
 
@@ -251,4 +848,51 @@ func initializeDiagnostics(
 
 	diagnostics.RegisterConnectedPeersSource(registry, netProvider)
 	diagnostics.RegisterClientInfoSource(registry, netProvider)
+	diagnostics.RegisterPeerReputationSource(registry, netProvider)
+}
+
+func initializeAdmin(
+	ctx context.Context,
+	config *config.Config,
+	reloader *configReloader,
+	beaconHandle *beacon.Handle,
+	netProvider net.Provider,
+	stakeMonitor chain.StakeMonitor,
+	shutdown func(),
+) error {
+	tokenFile := adminTokenFile(config)
+
+	server, isConfigured, err := admin.Initialize(
+		ctx,
+		config.Admin.Port,
+		tokenFile,
+	)
+	if err != nil {
+		return err
+	}
+	if !isConfigured {
+		logger.Infof("admin API is not configured")
+		return nil
+	}
+
+	logger.Infof(
+		"enabled admin API on port [%v]; token written to [%v]",
+		config.Admin.Port,
+		tokenFile,
+	)
+
+	admin.RegisterGroupsSource(server, beaconHandle)
+	admin.RegisterExecutionsSource(server, beaconHandle)
+	admin.RegisterPeersSource(server, netProvider, stakeMonitor)
+	admin.RegisterDrainAction(server, beaconHandle)
+	admin.RegisterShutdownAction(server, shutdown)
+	admin.RegisterReloadAction(server, reloader.reload)
+
+	return nil
+}
+
+// adminTokenFile returns the path of the file the admin API token is written
+// to by the running client.
+func adminTokenFile(config *config.Config) string {
+	return filepath.Join(config.Storage.DataDir, "admin.token")
 }
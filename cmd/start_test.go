@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/keep-network/keep-core/pkg/beacon/relay"
+)
+
+func TestSignalDuringStartupStopsClient(t *testing.T) {
+	stopped := make(chan struct{})
+	clientShutdown := newClientShutdown(func() { close(stopped) })
+
+	signals := make(chan os.Signal, 1)
+	go handleSignals(signals, clientShutdown)
+
+	// The signal is received before the beacon has been initialized.
+	signals <- syscall.SIGTERM
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("client has not been stopped")
+	}
+}
+
+func TestSignalAfterStartupDrainsBeacon(t *testing.T) {
+	stopped := make(chan struct{})
+	clientShutdown := newClientShutdown(func() { close(stopped) })
+
+	beaconHandle := &testBeacon{}
+	clientShutdown.setBeacon(beaconHandle)
+
+	signals := make(chan os.Signal, 1)
+	go handleSignals(signals, clientShutdown)
+
+	signals <- syscall.SIGTERM
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("client has not been stopped")
+	}
+
+	if atomic.LoadInt32(&beaconHandle.drained) != 1 {
+		t.Errorf("beacon has not been drained")
+	}
+}
+
+type testBeacon struct {
+	drained int32
+}
+
+func (tb *testBeacon) Drain() {
+	atomic.StoreInt32(&tb.drained, 1)
+}
+
+func (tb *testBeacon) Executions() []*relay.Execution {
+	return nil
+}
@@ -7,7 +7,12 @@ import (
 	"syscall"
 
 	"github.com/BurntSushi/toml"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/keep-network/keep-common/pkg/chain/ethereum"
+	"github.com/keep-network/keep-common/pkg/chain/ethlike"
+	"github.com/keep-network/keep-core/pkg/chain/confirmation"
+	ethereumchain "github.com/keep-network/keep-core/pkg/chain/ethereum"
+	"github.com/keep-network/keep-core/pkg/journal"
 	"github.com/keep-network/keep-core/pkg/net/libp2p"
 	"golang.org/x/crypto/ssh/terminal"
 )
@@ -24,6 +29,46 @@ type Config struct {
 	Storage     Storage
 	Metrics     Metrics
 	Diagnostics Diagnostics
+	Admin       Admin
+	Signer      Signer
+	NetworkKey  NetworkKey
+	Logging     Logging
+
+	// EthereumFailover lists Ethereum nodes the client fails over to when
+	// the node configured in the Ethereum section becomes unhealthy.
+	EthereumFailover ethereumchain.FailoverConfig
+
+	// EventConfirmation configures how many blocks relay entry requests and
+	// group selection starts have to be confirmed with before the client
+	// acts upon them.
+	EventConfirmation confirmation.Config
+
+	// GasPolicies configures gas prices of tickets, DKG results, relay
+	// entries and relay entry timeout reports submitted by the client.
+	GasPolicies ethereumchain.GasPolicies
+
+	// TicketOptimizer configures skipping group selection tickets which are
+	// not worth their submission cost.
+	TicketOptimizer TicketOptimizer
+
+	// Journal configures rotation of the event journal file kept in the
+	// data directory.
+	Journal journal.Config
+
+	// AdditionalOperators lists operators hosted by the client in addition
+	// to the operator configured in the Ethereum section. All operators share
+	// the same Ethereum connection and the same network host.
+	AdditionalOperators []Operator
+
+	// StandIn configures the chain stand-in the client connects to instead
+	// of Ethereum.
+	StandIn StandIn
+}
+
+// Operator stores configuration of an additional operator hosted by the
+// client.
+type Operator struct {
+	ethlike.Account
 }
 
 // Storage stores meta-info about keeping data on disk
@@ -43,6 +88,61 @@ type Diagnostics struct {
 	Port int
 }
 
+// Admin stores configuration of the local admin API.
+type Admin struct {
+	Port int
+}
+
+// Signer stores configuration of an external signing service holding the
+// operator key. When the URL is set, the operator key file is not used and
+// all signing requests of the operator are sent to the service.
+type Signer struct {
+	// URL of the signing service; either an HTTP URL or a path to a local
+	// IPC socket.
+	URL string
+	// Address of the operator whose key is held by the signing service.
+	Address string
+}
+
+// NetworkKey stores configuration of the network key the client uses to
+// authenticate in the network. If the key file is set, the network key is
+// separate from the operator key and it is delegated by the operator. The
+// delegation signed with the operator key is stored next to the key file.
+// If the key file is not set, the network key is derived from the operator
+// key, unless the operator key is held by a signing service.
+type NetworkKey struct {
+	// KeyFile is the path to the encrypted network key file. The file is
+	// created if it does not exist.
+	KeyFile string
+}
+
+// StandIn stores configuration of the chain stand-in simulating the operator
+// contract, shared by clients running on a single machine. When the URL is
+// set, the client connects to the stand-in instead of Ethereum and the
+// Ethereum section is used only for the operator account. It is meant to be
+// used in tests and development environments only.
+type StandIn struct {
+	// URL of the stand-in JSON-RPC endpoint; an HTTP URL.
+	URL string
+}
+
+// TicketOptimizer stores configuration of the ticket submission optimizer.
+type TicketOptimizer struct {
+	// ExpectedMemberReward is the reward the operator expects for a single
+	// membership in a group. Tickets whose probability of making it to the
+	// group multiplied by the reward does not cover their submission cost
+	// are not submitted. The optimizer is disabled if the reward is not set.
+	ExpectedMemberReward *ethereum.Wei
+}
+
+// Logging stores logging configuration.
+type Logging struct {
+	// Level is a space-delimited set of log level directives in the same
+	// format as the one of the LOG_LEVEL environment variable. If set, it
+	// takes precedence over the environment variable.
+	Level string
+}
+
 var (
 	// KeepOpts contains global application settings
 	KeepOpts Config
@@ -50,23 +150,67 @@ var (
 
 // ReadConfig reads in the configuration file at `filePath` and returns the
 // valid config stored there, or an error if something fails while reading the
-// file or the config is invalid in a known way.
+// file or the config is invalid in a known way. Values from the file are
+// overridden by the corresponding KEEP_* environment variables; see
+// OverridableFields.
 func ReadConfig(filePath string) (*Config, error) {
+	return ReadConfigWithOverrides(filePath, nil)
+}
+
+// ReadConfigWithOverrides reads in the configuration file at `filePath` the
+// same way as ReadConfig does and then overrides its values with the values
+// of command-line flags, keyed by field paths as returned by
+// OverridableFields. The precedence is: config file, environment variables,
+// command-line flags.
+func ReadConfigWithOverrides(
+	filePath string,
+	flagValues map[string]string,
+) (*Config, error) {
+	return readConfig(filePath, flagValues, "")
+}
+
+// ReloadConfig reads in the configuration file at `filePath` the same way as
+// ReadConfigWithOverrides does but instead of reading the password from the
+// environment, or prompting for it, it uses the password of the provided
+// current config. It is meant to re-read the config of a running client.
+func ReloadConfig(
+	filePath string,
+	flagValues map[string]string,
+	current *Config,
+) (*Config, error) {
+	return readConfig(
+		filePath,
+		flagValues,
+		current.Ethereum.Account.KeyFilePassword,
+	)
+}
+
+func readConfig(
+	filePath string,
+	flagValues map[string]string,
+	password string,
+) (*Config, error) {
 	config := &Config{}
 	if _, err := toml.DecodeFile(filePath, config); err != nil {
 		return nil, fmt.Errorf("unable to decode .toml file [%s] error [%s]", filePath, err)
 	}
 
+	if err := config.applyEnvOverrides(); err != nil {
+		return nil, err
+	}
+	if err := config.applyFlagOverrides(flagValues); err != nil {
+		return nil, err
+	}
+
 	envPassword := os.Getenv(passwordEnvVariable)
-	if envPassword == "prompt" {
-		var (
-			password string
-			err      error
-		)
-		if password, err = readPassword("Enter Account Password: "); err != nil {
+	if password != "" {
+		config.Ethereum.Account.KeyFilePassword = password
+	} else if envPassword == "prompt" {
+		promptedPassword, err := readPassword("Enter Account Password: ")
+		if err != nil {
 			return nil, err
 		}
-		config.Ethereum.Account.KeyFilePassword = password
+		config.Ethereum.Account.KeyFilePassword = promptedPassword
 	} else {
 		config.Ethereum.Account.KeyFilePassword = envPassword
 	}
@@ -80,6 +224,30 @@ func ReadConfig(filePath string) (*Config, error) {
 		)
 	}
 
+	for i := range config.AdditionalOperators {
+		operator := &config.AdditionalOperators[i]
+
+		if operator.KeyFile == "" {
+			return nil, fmt.Errorf(
+				"missing key file for additional operator [%v]",
+				i,
+			)
+		}
+
+		// Additional operators without an explicitly configured password
+		// use the same password as the primary operator.
+		if operator.KeyFilePassword == "" {
+			operator.KeyFilePassword = config.Ethereum.Account.KeyFilePassword
+		}
+	}
+
+	if config.Signer.URL != "" && !common.IsHexAddress(config.Signer.Address) {
+		return nil, fmt.Errorf(
+			"invalid operator address [%v] for the signing service",
+			config.Signer.Address,
+		)
+	}
+
 	if config.LibP2P.Port == 0 {
 		return nil, fmt.Errorf("missing value for port; see node section in config file or use --port flag")
 	}
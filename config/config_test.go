@@ -5,6 +5,8 @@ import (
 	"os"
 	"reflect"
 	"testing"
+
+	"github.com/keep-network/keep-common/pkg/chain/ethlike"
 )
 
 func TestReadConfig(t *testing.T) {
@@ -53,6 +55,36 @@ func TestReadConfig(t *testing.T) {
 			readValueFunc: func(c *Config) interface{} { return c.Ethereum.BalanceAlertThreshold.Int },
 			expectedValue: big.NewInt(2500000000000000000),
 		},
+		"AdditionalOperators": {
+			readValueFunc: func(c *Config) interface{} { return c.AdditionalOperators },
+			expectedValue: []Operator{
+				{
+					Account: ethlike.Account{
+						KeyFile:         "/tmp/UTC--2018-03-11T01-37-33.202765887Z--e75ca4e9d2ad0ef9e2a5e8ba2ed9dfa4f3fbf6b6",
+						KeyFilePassword: "not-my-password",
+					},
+				},
+				{
+					Account: ethlike.Account{
+						KeyFile:         "/tmp/UTC--2018-03-11T01-37-33.202765887Z--ab3bc44f27ba0c4a5a55f2d0d4c7cd1b5e25e0d0",
+						KeyFilePassword: "not-our-password",
+					},
+				},
+			},
+		},
+		"Signer": {
+			readValueFunc: func(c *Config) interface{} { return c.Signer },
+			expectedValue: Signer{
+				URL:     "/var/run/signer/signer.ipc",
+				Address: "0x6299496199d99941193Fdd2d717ef585F431eA05",
+			},
+		},
+		"NetworkKey": {
+			readValueFunc: func(c *Config) interface{} { return c.NetworkKey },
+			expectedValue: NetworkKey{
+				KeyFile: "/my/secure/location/network_key",
+			},
+		},
 	}
 
 	for testName, test := range configReadTests {
@@ -0,0 +1,266 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// envVariablePrefix prefixes names of all environment variables overriding
+// config fields.
+const envVariablePrefix = "KEEP"
+
+// overridableSections lists the config sections whose fields can be
+// overridden with environment variables and command-line flags.
+var overridableSections = []string{
+	"Ethereum",
+	"LibP2P",
+	"Storage",
+	"Metrics",
+	"Diagnostics",
+	"Admin",
+	"Signer",
+	"NetworkKey",
+	"Logging",
+	"EthereumFailover",
+	"EventConfirmation",
+	"GasPolicies",
+	"TicketOptimizer",
+	"Journal",
+	"StandIn",
+}
+
+// textUnmarshalerType is used to find fields which can parse their own values,
+// like ethereum.Wei.
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// OverridableFields returns paths of all config fields which can be
+// overridden with environment variables and command-line flags, for example
+// Ethereum.URL or LibP2P.Peers. Fields of embedded structs are addressed as
+// if they were declared in the embedding struct, the same way as in the
+// config file.
+//
+// Passwords are not overridable this way; the password of the operator key
+// can be set only with the KEEP_ETHEREUM_PASSWORD environment variable.
+func OverridableFields() []string {
+	configType := reflect.TypeOf(Config{})
+
+	var paths []string
+	for _, section := range overridableSections {
+		field, _ := configType.FieldByName(section)
+		paths = append(paths, overridableFields(field.Type, section)...)
+	}
+
+	return paths
+}
+
+func overridableFields(structType reflect.Type, path string) []string {
+	var paths []string
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+
+		if field.PkgPath != "" || field.Name == "KeyFilePassword" {
+			continue
+		}
+
+		fieldPath := path + "." + field.Name
+		if field.Anonymous {
+			fieldPath = path
+		}
+
+		switch {
+		case isOverridable(field.Type):
+			paths = append(paths, fieldPath)
+		case field.Type.Kind() == reflect.Struct:
+			paths = append(paths, overridableFields(field.Type, fieldPath)...)
+		}
+	}
+
+	return paths
+}
+
+func isOverridable(fieldType reflect.Type) bool {
+	if reflect.PtrTo(fieldType).Implements(textUnmarshalerType) ||
+		fieldType.Implements(textUnmarshalerType) {
+		return true
+	}
+
+	switch fieldType.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int64:
+		return true
+	case reflect.Slice:
+		return fieldType.Elem().Kind() == reflect.String
+	case reflect.Map:
+		return fieldType.Key().Kind() == reflect.String &&
+			fieldType.Elem().Kind() == reflect.String
+	}
+
+	return false
+}
+
+// EnvVariable returns the name of the environment variable overriding the
+// config field with the given path, for example KEEP_ETHEREUM_URL for
+// Ethereum.URL.
+func EnvVariable(fieldPath string) string {
+	return envVariablePrefix + "_" +
+		strings.ToUpper(strings.Replace(fieldPath, ".", "_", -1))
+}
+
+// FlagName returns the name of the command-line flag overriding the config
+// field with the given path, for example ethereum.url for Ethereum.URL.
+func FlagName(fieldPath string) string {
+	return strings.ToLower(fieldPath)
+}
+
+// applyEnvOverrides sets config fields to values of the environment variables
+// corresponding to them.
+func (c *Config) applyEnvOverrides() error {
+	for _, fieldPath := range OverridableFields() {
+		value, ok := os.LookupEnv(EnvVariable(fieldPath))
+		if !ok {
+			continue
+		}
+
+		if err := c.setField(fieldPath, value); err != nil {
+			return fmt.Errorf(
+				"invalid value of environment variable [%v]: [%v]",
+				EnvVariable(fieldPath),
+				err,
+			)
+		}
+	}
+
+	return nil
+}
+
+// applyFlagOverrides sets config fields to the provided values of command-line
+// flags, keyed by field paths.
+func (c *Config) applyFlagOverrides(flagValues map[string]string) error {
+	for _, fieldPath := range OverridableFields() {
+		value, ok := flagValues[fieldPath]
+		if !ok {
+			continue
+		}
+
+		if err := c.setField(fieldPath, value); err != nil {
+			return fmt.Errorf(
+				"invalid value of flag [%v]: [%v]",
+				FlagName(fieldPath),
+				err,
+			)
+		}
+	}
+
+	return nil
+}
+
+// ChangedFields returns paths of all overridable fields whose values differ
+// between this and the other config.
+func (c *Config) ChangedFields(other *Config) []string {
+	var changed []string
+	for _, fieldPath := range OverridableFields() {
+		if !reflect.DeepEqual(
+			c.field(fieldPath).Interface(),
+			other.field(fieldPath).Interface(),
+		) {
+			changed = append(changed, fieldPath)
+		}
+	}
+
+	return changed
+}
+
+// WithFields returns a copy of this config with values of the fields with the
+// given paths taken from the other config.
+func (c *Config) WithFields(other *Config, fieldPaths []string) *Config {
+	config := *c
+	for _, fieldPath := range fieldPaths {
+		config.field(fieldPath).Set(other.field(fieldPath))
+	}
+
+	return &config
+}
+
+func (c *Config) field(fieldPath string) reflect.Value {
+	field := reflect.ValueOf(c).Elem()
+	for _, name := range strings.Split(fieldPath, ".") {
+		field = field.FieldByName(name)
+	}
+
+	return field
+}
+
+// setField parses the value and sets it to the config field with the given
+// path. Lists are parsed from comma-separated values. Maps are parsed from
+// comma-separated key=value pairs and are merged into the map read from the
+// config file.
+func (c *Config) setField(fieldPath string, value string) error {
+	field := c.field(fieldPath)
+
+	if field.Kind() == reflect.Ptr && field.Type().Implements(textUnmarshalerType) {
+		parsed := reflect.New(field.Type().Elem())
+		if err := parsed.Interface().(encoding.TextUnmarshaler).UnmarshalText(
+			[]byte(value),
+		); err != nil {
+			return err
+		}
+		field.Set(parsed)
+		return nil
+	}
+	if field.CanAddr() && field.Addr().Type().Implements(textUnmarshalerType) {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText(
+			[]byte(value),
+		)
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(parsed)
+	case reflect.Int, reflect.Int64:
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(parsed)
+	case reflect.Slice:
+		field.Set(reflect.ValueOf(splitList(value)))
+	case reflect.Map:
+		if field.IsNil() {
+			field.Set(reflect.MakeMap(field.Type()))
+		}
+		for _, entry := range splitList(value) {
+			pair := strings.SplitN(entry, "=", 2)
+			if len(pair) != 2 {
+				return fmt.Errorf("entry [%v] is not a key=value pair", entry)
+			}
+			field.SetMapIndex(
+				reflect.ValueOf(strings.TrimSpace(pair[0])),
+				reflect.ValueOf(strings.TrimSpace(pair[1])),
+			)
+		}
+	default:
+		return fmt.Errorf("unsupported field type [%v]", field.Type())
+	}
+
+	return nil
+}
+
+func splitList(value string) []string {
+	list := []string{}
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			list = append(list, entry)
+		}
+	}
+
+	return list
+}
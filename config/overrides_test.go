@@ -0,0 +1,183 @@
+package config
+
+import (
+	"math/big"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestReadConfigWithOverrides(t *testing.T) {
+	environment := map[string]string{
+		"KEEP_ETHEREUM_PASSWORD":          "not-my-password",
+		"KEEP_ETHEREUM_URL":               "ws://env:8546",
+		"KEEP_ETHEREUM_URLRPC":            "http://env:8545",
+		"KEEP_ETHEREUM_CONTRACTADDRESSES": "TokenStaking=0x1, KeepRandomBeaconService=0x2",
+		"KEEP_ETHEREUM_MAXGASPRICE":       "20 Gwei",
+		"KEEP_LIBP2P_PEERS":               "/ip4/127.0.0.1/tcp/3919, /ip4/127.0.0.1/tcp/3920",
+		"KEEP_METRICS_PORT":               "8080",
+	}
+	for name, value := range environment {
+		if err := os.Setenv(name, value); err != nil {
+			t.Fatal(err)
+		}
+		defer os.Unsetenv(name)
+	}
+
+	cfg, err := ReadConfigWithOverrides(
+		"../test/config.toml",
+		map[string]string{
+			"Ethereum.URLRPC":  "http://flag:8545",
+			"Diagnostics.Port": "8081",
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var overrideTests = map[string]struct {
+		readValueFunc func(*Config) interface{}
+		expectedValue interface{}
+	}{
+		"Ethereum.URL": {
+			readValueFunc: func(c *Config) interface{} { return c.Ethereum.URL },
+			expectedValue: "ws://env:8546",
+		},
+		"Ethereum.URLRPC": {
+			readValueFunc: func(c *Config) interface{} { return c.Ethereum.URLRPC },
+			expectedValue: "http://flag:8545",
+		},
+		"Ethereum.ContractAddresses": {
+			readValueFunc: func(c *Config) interface{} { return c.Ethereum.ContractAddresses },
+			expectedValue: map[string]string{
+				"KeepRandomBeaconOperator": "0xcf64c2a367341170cb4e09cf8c0ed137d8473ceb",
+				"TokenStaking":             "0x1",
+				"KeepRandomBeaconService":  "0x2",
+			},
+		},
+		"Ethereum.MaxGasPrice": {
+			readValueFunc: func(c *Config) interface{} { return c.Ethereum.MaxGasPrice.Int },
+			expectedValue: big.NewInt(20000000000),
+		},
+		"LibP2P.Peers": {
+			readValueFunc: func(c *Config) interface{} { return c.LibP2P.Peers },
+			expectedValue: []string{
+				"/ip4/127.0.0.1/tcp/3919",
+				"/ip4/127.0.0.1/tcp/3920",
+			},
+		},
+		"LibP2P.Port": {
+			readValueFunc: func(c *Config) interface{} { return c.LibP2P.Port },
+			expectedValue: 27001,
+		},
+		"Metrics.Port": {
+			readValueFunc: func(c *Config) interface{} { return c.Metrics.Port },
+			expectedValue: 8080,
+		},
+		"Diagnostics.Port": {
+			readValueFunc: func(c *Config) interface{} { return c.Diagnostics.Port },
+			expectedValue: 8081,
+		},
+	}
+
+	for testName, test := range overrideTests {
+		t.Run(testName, func(t *testing.T) {
+			actualValue := test.readValueFunc(cfg)
+			if !reflect.DeepEqual(test.expectedValue, actualValue) {
+				t.Errorf(
+					"unexpected value\nexpected: [%+v]\nactual:   [%+v]",
+					test.expectedValue,
+					actualValue,
+				)
+			}
+		})
+	}
+}
+
+func TestReadConfigWithInvalidOverride(t *testing.T) {
+	if err := os.Setenv("KEEP_ETHEREUM_PASSWORD", "not-my-password"); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := ReadConfigWithOverrides(
+		"../test/config.toml",
+		map[string]string{"LibP2P.Port": "port"},
+	)
+
+	expectedError := "invalid value of flag [libp2p.port]: " +
+		"[strconv.ParseInt: parsing \"port\": invalid syntax]"
+	if err == nil || err.Error() != expectedError {
+		t.Fatalf(
+			"unexpected error\nexpected: [%v]\nactual:   [%v]",
+			expectedError,
+			err,
+		)
+	}
+}
+
+func TestOverridableFieldsNames(t *testing.T) {
+	fields := make(map[string]bool)
+	for _, fieldPath := range OverridableFields() {
+		fields[fieldPath] = true
+	}
+
+	for _, expectedField := range []string{
+		"Ethereum.URL",
+		"Ethereum.ContractAddresses",
+		"Ethereum.Account.KeyFile",
+		"LibP2P.Peers",
+		"Storage.DataDir",
+		"Metrics.Port",
+		"Diagnostics.Port",
+	} {
+		if !fields[expectedField] {
+			t.Errorf("field [%v] is not overridable", expectedField)
+		}
+	}
+
+	if fields["Ethereum.Account.KeyFilePassword"] {
+		t.Errorf("password must not be overridable")
+	}
+
+	if envVariable := EnvVariable("LibP2P.Peers"); envVariable != "KEEP_LIBP2P_PEERS" {
+		t.Errorf("unexpected environment variable [%v]", envVariable)
+	}
+	if flagName := FlagName("LibP2P.Peers"); flagName != "libp2p.peers" {
+		t.Errorf("unexpected flag name [%v]", flagName)
+	}
+}
+
+func TestChangedFields(t *testing.T) {
+	if err := os.Setenv("KEEP_ETHEREUM_PASSWORD", "not-my-password"); err != nil {
+		t.Fatal(err)
+	}
+
+	current, err := ReadConfig("../test/config.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := ReadConfigWithOverrides(
+		"../test/config.toml",
+		map[string]string{
+			"LibP2P.Peers":         "/ip4/127.0.0.1/tcp/3919",
+			"Ethereum.MaxGasPrice": "140 Gwei",
+			"Logging.Level":        "debug",
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedFields := []string{"LibP2P.Peers", "Logging.Level"}
+	if changedFields := current.ChangedFields(reloaded); !reflect.DeepEqual(
+		expectedFields,
+		changedFields,
+	) {
+		t.Errorf(
+			"unexpected changed fields\nexpected: [%v]\nactual:   [%v]",
+			expectedFields,
+			changedFields,
+		)
+	}
+}
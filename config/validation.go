@@ -0,0 +1,624 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/keep-network/keep-common/pkg/chain/ethereum"
+	ethereumchain "github.com/keep-network/keep-core/pkg/chain/ethereum"
+	"github.com/keep-network/keep-core/pkg/metrics"
+	"github.com/keep-network/keep-core/pkg/net/libp2p"
+	peerstore "github.com/libp2p/go-libp2p-peerstore"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// RequiredContracts lists names of contracts whose addresses have to be
+// configured in the ethereum section.
+var RequiredContracts = []string{
+	"KeepRandomBeaconOperator",
+	"KeepRandomBeaconService",
+	"TokenStaking",
+}
+
+// maskedPassword replaces passwords, as well as credentials and tokens of
+// Ethereum node URLs, in the config returned by Masked.
+const maskedPassword = "********"
+
+// Validate checks all sections of the config and returns all problems found.
+// Contrary to ReadConfig, which rejects only configs the client could not
+// start with, Validate reports every value the client would fail on at
+// runtime. Validate does not connect to any of the configured services.
+func (c *Config) Validate() []error {
+	var errors []error
+	report := func(format string, args ...interface{}) {
+		errors = append(errors, fmt.Errorf(format, args...))
+	}
+
+	// The Ethereum section is used only for the operator account when the
+	// client connects to the chain stand-in.
+	if c.StandIn.URL != "" {
+		if err := validateURL(c.StandIn.URL, "http", "https"); err != nil {
+			report("stand-in: invalid URL [%v]: [%v]", c.StandIn.URL, err)
+		}
+		c.validateAccountKeyFile(report)
+	} else {
+		c.validateEthereum(report)
+	}
+	c.validateLibP2P(report)
+	c.validateStorage(report)
+	c.validatePorts(report)
+
+	if c.Metrics.NetworkMetricsTick < 0 {
+		report(
+			"metrics: network metrics tick [%v] must not be negative",
+			c.Metrics.NetworkMetricsTick,
+		)
+	}
+	if c.Metrics.EthereumMetricsTick < 0 {
+		report(
+			"metrics: ethereum metrics tick [%v] must not be negative",
+			c.Metrics.EthereumMetricsTick,
+		)
+	}
+
+	if c.Signer.URL != "" {
+		if err := validateSignerURL(c.Signer.URL); err != nil {
+			report("signer: invalid URL [%v]: [%v]", c.Signer.URL, err)
+		}
+		if !common.IsHexAddress(c.Signer.Address) {
+			report("signer: invalid operator address [%v]", c.Signer.Address)
+		}
+	}
+
+	if c.NetworkKey.KeyFile != "" {
+		if err := checkDirectory(filepath.Dir(c.NetworkKey.KeyFile)); err != nil {
+			report(
+				"network key: invalid key file location [%v]: [%v]",
+				c.NetworkKey.KeyFile,
+				err,
+			)
+		}
+	}
+
+	for i, account := range c.AdditionalOperators {
+		if err := checkFile(account.KeyFile); err != nil {
+			report(
+				"additional operator [%v]: invalid key file [%v]: [%v]",
+				i,
+				account.KeyFile,
+				err,
+			)
+		}
+	}
+
+	return errors
+}
+
+func (c *Config) validateEthereum(report func(string, ...interface{})) {
+	if err := validateURL(c.Ethereum.URL, "ws", "wss"); err != nil {
+		report("ethereum: invalid URL [%v]: [%v]", c.Ethereum.URL, err)
+	}
+
+	// The client connects only through the websocket URL; the RPC URL is
+	// optional and used only by the contract utility commands.
+	if c.Ethereum.URLRPC != "" {
+		if err := validateURL(c.Ethereum.URLRPC, "http", "https"); err != nil {
+			report("ethereum: invalid URLRPC [%v]: [%v]", c.Ethereum.URLRPC, err)
+		}
+	}
+
+	for _, contractName := range RequiredContracts {
+		if _, ok := c.Ethereum.ContractAddresses[contractName]; !ok {
+			report("ethereum: missing address of contract [%v]", contractName)
+		}
+	}
+	for contractName, address := range c.Ethereum.ContractAddresses {
+		if err := validateAddress(address); err != nil {
+			report(
+				"ethereum: invalid address [%v] of contract [%v]: [%v]",
+				address,
+				contractName,
+				err,
+			)
+		}
+	}
+
+	c.validateAccountKeyFile(report)
+
+	if c.Ethereum.MiningCheckInterval < 0 {
+		report(
+			"ethereum: mining check interval [%v] must not be negative",
+			c.Ethereum.MiningCheckInterval,
+		)
+	}
+	if c.Ethereum.RequestsPerSecondLimit < 0 {
+		report(
+			"ethereum: requests per second limit [%v] must not be negative",
+			c.Ethereum.RequestsPerSecondLimit,
+		)
+	}
+	if c.Ethereum.ConcurrencyLimit < 0 {
+		report(
+			"ethereum: concurrency limit [%v] must not be negative",
+			c.Ethereum.ConcurrencyLimit,
+		)
+	}
+	if c.Ethereum.MaxGasPrice != nil && c.Ethereum.MaxGasPrice.Sign() <= 0 {
+		report(
+			"ethereum: max gas price [%v] must be positive",
+			c.Ethereum.MaxGasPrice,
+		)
+	}
+
+	for _, endpoint := range c.EthereumFailover.Endpoints {
+		if err := validateURL(endpoint, "ws", "wss"); err != nil {
+			report("ethereum failover: invalid endpoint [%v]: [%v]", endpoint, err)
+		}
+	}
+
+	failoverLimits := []struct {
+		name  string
+		value int
+	}{
+		{"probe interval", c.EthereumFailover.ProbeInterval},
+		{"max block lag", c.EthereumFailover.MaxBlockLag},
+		{"max error rate", c.EthereumFailover.MaxErrorRate},
+		{"max latency", c.EthereumFailover.MaxLatency},
+		{"live events delay", c.EthereumFailover.LiveEventsDelay},
+	}
+	for _, limit := range failoverLimits {
+		if limit.value < 0 {
+			report(
+				"ethereum failover: %v [%v] must not be negative",
+				limit.name,
+				limit.value,
+			)
+		}
+	}
+	if c.EthereumFailover.MaxErrorRate > 100 {
+		report(
+			"ethereum failover: max error rate [%v] must not exceed 100",
+			c.EthereumFailover.MaxErrorRate,
+		)
+	}
+
+	if c.EventConfirmation.Depth < 0 {
+		report(
+			"event confirmation: depth [%v] must not be negative",
+			c.EventConfirmation.Depth,
+		)
+	}
+
+	// Events of blocks considered processed are not fetched again after the
+	// connection is restored, so events which could still be reorganized out
+	// of the chain and included again must not be considered processed.
+	liveEventsDelay := c.EthereumFailover.LiveEventsDelay
+	if liveEventsDelay == 0 {
+		liveEventsDelay = ethereumchain.DefaultLiveEventsDelay
+	}
+	if liveEventsDelay < c.EventConfirmation.Depth {
+		report(
+			"ethereum failover: live events delay [%v] must not be lower "+
+				"than event confirmation depth [%v]",
+			liveEventsDelay,
+			c.EventConfirmation.Depth,
+		)
+	}
+
+	if c.Journal.MaxFileSize < 0 {
+		report(
+			"journal: max file size [%v] must not be negative",
+			c.Journal.MaxFileSize,
+		)
+	}
+	if c.Journal.MaxFiles < 0 {
+		report(
+			"journal: max files [%v] must not be negative",
+			c.Journal.MaxFiles,
+		)
+	}
+
+	gasPolicies := []struct {
+		name   string
+		policy ethereumchain.GasPolicy
+	}{
+		{"submit ticket", c.GasPolicies.SubmitTicket},
+		{"submit DKG result", c.GasPolicies.SubmitDKGResult},
+		{"submit relay entry", c.GasPolicies.SubmitRelayEntry},
+		{"report relay entry timeout", c.GasPolicies.ReportRelayEntryTimeout},
+	}
+	for _, gasPolicy := range gasPolicies {
+		validateGasPolicy(gasPolicy.name, gasPolicy.policy, report)
+	}
+
+	if reward := c.TicketOptimizer.ExpectedMemberReward; reward != nil &&
+		reward.Sign() <= 0 {
+		report(
+			"ticket optimizer: expected member reward [%v] must be positive",
+			reward,
+		)
+	}
+}
+
+func (c *Config) validateAccountKeyFile(report func(string, ...interface{})) {
+	// The key file is not used if the operator key is held by a signing
+	// service.
+	if c.Signer.URL == "" {
+		if err := checkFile(c.Ethereum.Account.KeyFile); err != nil {
+			report(
+				"ethereum: invalid account key file [%v]: [%v]",
+				c.Ethereum.Account.KeyFile,
+				err,
+			)
+		}
+	}
+}
+
+func validateGasPolicy(
+	name string,
+	policy ethereumchain.GasPolicy,
+	report func(string, ...interface{}),
+) {
+	knownStrategy := policy.Strategy == ""
+	for _, strategy := range ethereumchain.GasPriceStrategies {
+		if policy.Strategy == strategy {
+			knownStrategy = true
+		}
+	}
+	if !knownStrategy {
+		report(
+			"gas policies: unknown strategy [%v] of %v policy; "+
+				"supported strategies are [%v]",
+			policy.Strategy,
+			name,
+			strings.Join(ethereumchain.GasPriceStrategies, ", "),
+		)
+	}
+
+	switch policy.Strategy {
+	case ethereumchain.FixedGasPriceStrategy:
+		if policy.Price == nil {
+			report("gas policies: %v policy requires price", name)
+		}
+	case ethereumchain.DeadlineGasPriceStrategy:
+		if policy.Price == nil || policy.MaxPrice == nil {
+			report("gas policies: %v policy requires price and max price", name)
+		}
+	}
+
+	prices := []struct {
+		name  string
+		value *ethereum.Wei
+	}{
+		{"price", policy.Price},
+		{"priority fee", policy.PriorityFee},
+		{"max price", policy.MaxPrice},
+	}
+	for _, price := range prices {
+		if price.value != nil && price.value.Sign() <= 0 {
+			report(
+				"gas policies: %v [%v] of %v policy must be positive",
+				price.name,
+				price.value,
+				name,
+			)
+		}
+	}
+
+	if policy.Price != nil && policy.MaxPrice != nil &&
+		policy.Price.Cmp(policy.MaxPrice.Int) > 0 {
+		report(
+			"gas policies: price [%v] of %v policy must not exceed "+
+				"max price [%v]",
+			policy.Price,
+			name,
+			policy.MaxPrice,
+		)
+	}
+}
+
+func (c *Config) validateLibP2P(report func(string, ...interface{})) {
+	for _, peer := range c.LibP2P.Peers {
+		multiaddress, err := ma.NewMultiaddr(peer)
+		if err != nil {
+			report("libp2p: invalid peer address [%v]: [%v]", peer, err)
+			continue
+		}
+
+		if _, err := peerstore.InfoFromP2pAddr(multiaddress); err != nil {
+			report("libp2p: invalid peer address [%v]: [%v]", peer, err)
+		}
+	}
+
+	for _, address := range c.LibP2P.AnnouncedAddresses {
+		if _, err := ma.NewMultiaddr(address); err != nil {
+			report("libp2p: invalid announced address [%v]: [%v]", address, err)
+		}
+	}
+
+	if c.LibP2P.DisseminationTime < 0 ||
+		c.LibP2P.DisseminationTime > libp2p.MaximumDisseminationTime {
+		report(
+			"libp2p: dissemination time [%v] must be in range [0, %v]",
+			c.LibP2P.DisseminationTime,
+			libp2p.MaximumDisseminationTime,
+		)
+	}
+}
+
+func (c *Config) validateStorage(report func(string, ...interface{})) {
+	dataDir := c.Storage.DataDir
+
+	if err := checkDirectory(dataDir); err != nil {
+		report("storage: invalid data directory [%v]: [%v]", dataDir, err)
+		return
+	}
+
+	info, err := os.Stat(dataDir)
+	if err != nil {
+		report("storage: invalid data directory [%v]: [%v]", dataDir, err)
+		return
+	}
+	if info.Mode().Perm()&0002 != 0 {
+		report(
+			"storage: data directory [%v] with permissions [%v] is "+
+				"writable by all users",
+			dataDir,
+			info.Mode().Perm(),
+		)
+	}
+
+	// The only reliable way to check whether the client can write to the
+	// data directory is to write to it.
+	probe, err := ioutil.TempFile(dataDir, ".validate")
+	if err != nil {
+		report("storage: data directory [%v] is not writable: [%v]", dataDir, err)
+		return
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+}
+
+func (c *Config) validatePorts(report func(string, ...interface{})) {
+	type portEntry struct {
+		section  string
+		port     int
+		optional bool
+	}
+
+	ports := []portEntry{
+		{"libp2p", c.LibP2P.Port, false},
+		{"metrics", c.Metrics.Port, true},
+		{"diagnostics", c.Diagnostics.Port, true},
+		{"admin", c.Admin.Port, true},
+	}
+
+	used := make(map[int]string)
+	for _, entry := range ports {
+		if entry.optional && entry.port == 0 {
+			continue
+		}
+
+		if entry.port <= 0 || entry.port > 65535 {
+			report("%v: port [%v] must be in range [1, 65535]", entry.section, entry.port)
+			continue
+		}
+
+		if section, ok := used[entry.port]; ok {
+			report(
+				"%v: port [%v] is already used by %v",
+				entry.section,
+				entry.port,
+				section,
+			)
+			continue
+		}
+		used[entry.port] = entry.section
+	}
+}
+
+// WithDefaults returns a copy of the config with default values applied to
+// all unset values the client has defaults for.
+func (c *Config) WithDefaults() *Config {
+	config := *c
+
+	if config.Ethereum.MiningCheckInterval == 0 {
+		config.Ethereum.MiningCheckInterval = int(
+			ethereumchain.DefaultMiningCheckInterval.Seconds(),
+		)
+	}
+	if config.Ethereum.MaxGasPrice == nil {
+		config.Ethereum.MaxGasPrice = ethereum.WrapWei(
+			ethereumchain.DefaultMaxGasPrice,
+		)
+	}
+	if config.EthereumFailover.ProbeInterval == 0 {
+		config.EthereumFailover.ProbeInterval = int(
+			ethereumchain.DefaultProbeInterval.Seconds(),
+		)
+	}
+	if config.EthereumFailover.MaxBlockLag == 0 {
+		config.EthereumFailover.MaxBlockLag = ethereumchain.DefaultMaxBlockLag
+	}
+	if config.EthereumFailover.MaxErrorRate == 0 {
+		config.EthereumFailover.MaxErrorRate = ethereumchain.DefaultMaxErrorRate
+	}
+	if config.EthereumFailover.MaxLatency == 0 {
+		config.EthereumFailover.MaxLatency = int(
+			ethereumchain.DefaultMaxLatency / time.Millisecond,
+		)
+	}
+	if config.EthereumFailover.LiveEventsDelay == 0 {
+		config.EthereumFailover.LiveEventsDelay =
+			ethereumchain.DefaultLiveEventsDelay
+	}
+	if config.Metrics.NetworkMetricsTick == 0 {
+		config.Metrics.NetworkMetricsTick = int(
+			metrics.DefaultNetworkMetricsTick.Seconds(),
+		)
+	}
+	if config.Metrics.EthereumMetricsTick == 0 {
+		config.Metrics.EthereumMetricsTick = int(
+			metrics.DefaultEthereumMetricsTick.Seconds(),
+		)
+	}
+
+	return &config
+}
+
+// Masked returns a copy of the config with all passwords and credentials of
+// Ethereum node, signing service and chain stand-in URLs masked so that the
+// config can be safely printed.
+func (c *Config) Masked() *Config {
+	config := *c
+
+	if config.Ethereum.Account.KeyFilePassword != "" {
+		config.Ethereum.Account.KeyFilePassword = maskedPassword
+	}
+
+	config.Ethereum.URL = maskURL(c.Ethereum.URL)
+	config.Ethereum.URLRPC = maskURL(c.Ethereum.URLRPC)
+
+	config.EthereumFailover.Endpoints = make(
+		[]string,
+		len(c.EthereumFailover.Endpoints),
+	)
+	for i, endpoint := range c.EthereumFailover.Endpoints {
+		config.EthereumFailover.Endpoints[i] = maskURL(endpoint)
+	}
+
+	config.Signer.URL = maskURL(c.Signer.URL)
+	config.StandIn.URL = maskURL(c.StandIn.URL)
+
+	config.AdditionalOperators = make([]Operator, len(c.AdditionalOperators))
+	for i, operator := range c.AdditionalOperators {
+		if operator.KeyFilePassword != "" {
+			operator.KeyFilePassword = maskedPassword
+		}
+		config.AdditionalOperators[i] = operator
+	}
+
+	return &config
+}
+
+// maskURL masks credentials of the provided service URL. Node providers and
+// hosted services pass access tokens in the user info, the path or the query
+// of the URL so all of them are masked; only the scheme, host and port are
+// left intact. URLs which can not be parsed and IPC paths are masked entirely.
+func maskURL(rawURL string) string {
+	if rawURL == "" {
+		return rawURL
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return maskedPassword
+	}
+
+	masked := parsed.Scheme + "://"
+	if parsed.User != nil {
+		masked += maskedPassword + "@"
+	}
+	masked += parsed.Host
+	if parsed.Path != "" && parsed.Path != "/" {
+		masked += "/" + maskedPassword
+	}
+	if parsed.RawQuery != "" {
+		masked += "?" + maskedPassword
+	}
+
+	return masked
+}
+
+func validateURL(rawURL string, schemes ...string) error {
+	if rawURL == "" {
+		return fmt.Errorf("URL is not set")
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	for _, scheme := range schemes {
+		if parsed.Scheme == scheme {
+			if parsed.Host == "" {
+				return fmt.Errorf("missing host")
+			}
+			return nil
+		}
+	}
+
+	return fmt.Errorf(
+		"unsupported scheme [%v]; expected one of [%v]",
+		parsed.Scheme,
+		strings.Join(schemes, ", "),
+	)
+}
+
+// validateSignerURL accepts either an HTTP URL or a path to a local IPC
+// socket of the signing service.
+func validateSignerURL(rawURL string) error {
+	if filepath.IsAbs(rawURL) {
+		return checkDirectory(filepath.Dir(rawURL))
+	}
+
+	return validateURL(rawURL, "http", "https", "ws", "wss")
+}
+
+// validateAddress checks whether the address is a valid hex address. Mixed
+// case addresses have to match their EIP-55 checksum.
+func validateAddress(address string) error {
+	if !common.IsHexAddress(address) {
+		return fmt.Errorf("not a valid hex address")
+	}
+
+	hex := strings.TrimPrefix(strings.TrimPrefix(address, "0x"), "0X")
+	if hex == strings.ToLower(hex) || hex == strings.ToUpper(hex) {
+		return nil
+	}
+
+	if checksummed := common.HexToAddress(address).Hex(); checksummed[2:] != hex {
+		return fmt.Errorf("invalid checksum; expected [%v]", checksummed)
+	}
+
+	return nil
+}
+
+func checkFile(path string) error {
+	if path == "" {
+		return fmt.Errorf("path is not set")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("is a directory")
+	}
+
+	return nil
+}
+
+func checkDirectory(path string) error {
+	if path == "" {
+		return fmt.Errorf("path is not set")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("not a directory")
+	}
+
+	return nil
+}
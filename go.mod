@@ -27,6 +27,8 @@ require (
 	github.com/libp2p/go-libp2p-secio v0.2.2
 	github.com/multiformats/go-multiaddr v0.2.2
 	github.com/pborman/uuid v1.2.0
+	github.com/prometheus/client_golang v1.12.2
+	github.com/prometheus/client_model v0.2.0
 	github.com/urfave/cli v1.22.1
 	golang.org/x/crypto v0.0.0-20200622213623-75b288015ac9
 )
@@ -57,16 +57,25 @@ func main() {
 			Usage:       "full path to the configuration file",
 		},
 	}
+	app.Flags = append(app.Flags, cmd.ConfigOverrideFlags...)
 	app.Commands = []cli.Command{
 		cmd.StartCommand,
 		cmd.RelayCommand,
 		cmd.PingCommand,
 		cmd.EthereumCommand,
+		cmd.JournalCommand,
+		cmd.ConfigCommand,
+		cmd.SimulateCommand,
+		cmd.GroupsCommand,
+		cmd.NetworkKeyCommand,
 	}
 
 	cli.AppHelpTemplate = fmt.Sprintf(`%s
 ENVIRONMENT VARIABLES:
    KEEP_ETHEREUM_PASSWORD    keep client password
+   KEEP_<SECTION>_<FIELD>    overrides the config field, e.g. KEEP_ETHEREUM_URL
+                             overrides Ethereum.URL; lists are comma-separated,
+                             maps are comma-separated key=value pairs
    LOG_LEVEL                 space-delimited set of log level directives; set to
                              "help" for help
 
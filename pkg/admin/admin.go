@@ -0,0 +1,285 @@
+// Package admin provides a local, authenticated HTTP API allowing the operator
+// to inspect and control a running client.
+//
+// The API exposes two kinds of endpoints. Sources are read-only and are
+// available under `GET /admin/<name>`, returning the source data in JSON
+// format. Actions change the state of the client and are available under
+// `POST /admin/<name>`. Every request has to carry the `Authorization: Bearer
+// <token>` header where the token is the one generated when the server was
+// initialized and written to the token file.
+package admin
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ipfs/go-log"
+)
+
+var logger = log.Logger("keep-admin")
+
+const (
+	pathPrefix = "/admin/"
+
+	// tokenLength is the length of the generated admin API token in bytes.
+	tokenLength = 32
+)
+
+// Source is a function returning the current value of an admin API source.
+// The returned value is serialized to JSON before it is sent to the caller.
+type Source func() (interface{}, error)
+
+// Action is a function performing an admin API action. The returned value is
+// serialized to JSON before it is sent to the caller.
+type Action func() (interface{}, error)
+
+// Server is the admin API server. It allows to register sources and actions
+// and exposes them through the HTTP endpoint listening on the loopback
+// interface.
+type Server struct {
+	token string
+
+	mutex   sync.RWMutex
+	sources map[string]Source
+	actions map[string]Action
+}
+
+// Initialize sets up the admin API server and enables it on the given port.
+// The server is listening only on the loopback interface. A fresh token is
+// generated and written to the provided token file readable only by the owner.
+// The server is closed when the provided context is done. Returns false if the
+// admin API is not configured and an error if the server could not be set up,
+// including when the port could not be bound.
+func Initialize(
+	ctx context.Context,
+	port int,
+	tokenFile string,
+) (*Server, bool, error) {
+	if port == 0 {
+		return nil, false, nil
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:"+strconv.Itoa(port))
+	if err != nil {
+		return nil, false, fmt.Errorf(
+			"could not listen on port [%v]: [%v]",
+			port,
+			err,
+		)
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		listener.Close()
+		return nil, false, fmt.Errorf("could not generate token: [%v]", err)
+	}
+
+	if err := ioutil.WriteFile(tokenFile, []byte(token), 0600); err != nil {
+		listener.Close()
+		return nil, false, fmt.Errorf(
+			"could not write token file [%v]: [%v]",
+			tokenFile,
+			err,
+		)
+	}
+
+	server := newServer(token)
+	server.serve(ctx, listener)
+
+	return server, true, nil
+}
+
+func newServer(token string) *Server {
+	return &Server{
+		token:   token,
+		sources: make(map[string]Source),
+		actions: make(map[string]Action),
+	}
+}
+
+func generateToken() (string, error) {
+	bytes := make([]byte, tokenLength)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(bytes), nil
+}
+
+// serve serves the admin API on the given listener until the provided context
+// is done.
+func (s *Server) serve(ctx context.Context, listener net.Listener) {
+	server := &http.Server{Handler: s.handler()}
+
+	go func() {
+		if err := server.Serve(listener); err != http.ErrServerClosed {
+			logger.Errorf("admin server error: [%v]", err)
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		if err := server.Close(); err != nil {
+			logger.Errorf("could not close admin server: [%v]", err)
+		}
+	}()
+}
+
+// RegisterSource registers a read-only source under the given name. The
+// source is exposed under `GET /admin/<name>`. Registering a source with
+// a name already in use overrides the previous source.
+func (s *Server) RegisterSource(name string, source Source) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.sources[name] = source
+}
+
+// RegisterAction registers an action under the given name. The action is
+// exposed under `POST /admin/<name>`. Registering an action with a name
+// already in use overrides the previous action.
+func (s *Server) RegisterAction(name string, action Action) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.actions[name] = action
+}
+
+func (s *Server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(pathPrefix, s.handle)
+	mux.HandleFunc(strings.TrimSuffix(pathPrefix, "/"), s.handle)
+	return mux
+}
+
+func (s *Server) handle(response http.ResponseWriter, request *http.Request) {
+	if !s.isAuthorized(request) {
+		writeError(response, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	name := strings.Trim(strings.TrimPrefix(request.URL.Path, pathPrefix), "/")
+	if name == "" || name == strings.Trim(pathPrefix, "/") {
+		if request.Method != http.MethodGet {
+			writeError(response, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		writeResult(response, s.index())
+		return
+	}
+
+	switch request.Method {
+	case http.MethodGet:
+		s.mutex.RLock()
+		source, ok := s.sources[name]
+		s.mutex.RUnlock()
+
+		if !ok {
+			writeError(response, http.StatusNotFound, "unknown source")
+			return
+		}
+
+		result, err := source()
+		if err != nil {
+			logger.Errorf("admin source [%v] failed: [%v]", name, err)
+			writeError(response, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		writeResult(response, result)
+	case http.MethodPost:
+		s.mutex.RLock()
+		action, ok := s.actions[name]
+		s.mutex.RUnlock()
+
+		if !ok {
+			writeError(response, http.StatusNotFound, "unknown action")
+			return
+		}
+
+		logger.Infof("executing admin action [%v]", name)
+
+		result, err := action()
+		if err != nil {
+			logger.Errorf("admin action [%v] failed: [%v]", name, err)
+			writeError(response, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		writeResult(response, result)
+	default:
+		writeError(response, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (s *Server) isAuthorized(request *http.Request) bool {
+	const prefix = "Bearer "
+
+	header := request.Header.Get("Authorization")
+	if !strings.HasPrefix(header, prefix) {
+		return false
+	}
+
+	token := strings.TrimPrefix(header, prefix)
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+}
+
+// index lists names of all registered sources and actions.
+func (s *Server) index() map[string][]string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	sources := make([]string, 0, len(s.sources))
+	for name := range s.sources {
+		sources = append(sources, name)
+	}
+	sort.Strings(sources)
+
+	actions := make([]string, 0, len(s.actions))
+	for name := range s.actions {
+		actions = append(actions, name)
+	}
+	sort.Strings(actions)
+
+	return map[string][]string{
+		"sources": sources,
+		"actions": actions,
+	}
+}
+
+func writeResult(response http.ResponseWriter, result interface{}) {
+	bytes, err := json.Marshal(result)
+	if err != nil {
+		logger.Errorf("admin response JSON serialization error: [%v]", err)
+		writeError(response, http.StatusInternalServerError, "serialization error")
+		return
+	}
+
+	response.Header().Set("Content-Type", "application/json")
+	if _, err := response.Write(bytes); err != nil {
+		logger.Errorf("could not write response: [%v]", err)
+	}
+}
+
+func writeError(response http.ResponseWriter, status int, message string) {
+	bytes, _ := json.Marshal(map[string]string{"error": message})
+
+	response.Header().Set("Content-Type", "application/json")
+	response.WriteHeader(status)
+	if _, err := response.Write(bytes); err != nil {
+		logger.Errorf("could not write response: [%v]", err)
+	}
+}
@@ -0,0 +1,287 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const testToken = "0123456789abcdef"
+
+func TestUnauthorizedRequest(t *testing.T) {
+	server := newServer(testToken)
+	server.RegisterSource("test", func() (interface{}, error) {
+		return "value", nil
+	})
+
+	var tests = map[string]struct {
+		authorization string
+	}{
+		"no authorization header": {
+			authorization: "",
+		},
+		"invalid token": {
+			authorization: "Bearer fedcba9876543210",
+		},
+		"invalid scheme": {
+			authorization: "Basic " + testToken,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			status, _ := execute(t, server, http.MethodGet, "test", test.authorization)
+
+			if status != http.StatusUnauthorized {
+				t.Errorf(
+					"unexpected status\nexpected: [%v]\nactual:   [%v]",
+					http.StatusUnauthorized,
+					status,
+				)
+			}
+		})
+	}
+}
+
+func TestSource(t *testing.T) {
+	server := newServer(testToken)
+	server.RegisterSource("test", func() (interface{}, error) {
+		return map[string]interface{}{"key": "value"}, nil
+	})
+
+	status, body := execute(
+		t,
+		server,
+		http.MethodGet,
+		"test",
+		"Bearer "+testToken,
+	)
+
+	if status != http.StatusOK {
+		t.Fatalf(
+			"unexpected status\nexpected: [%v]\nactual:   [%v]",
+			http.StatusOK,
+			status,
+		)
+	}
+
+	expected := map[string]interface{}{"key": "value"}
+	if !reflect.DeepEqual(expected, body) {
+		t.Errorf(
+			"unexpected body\nexpected: [%v]\nactual:   [%v]",
+			expected,
+			body,
+		)
+	}
+}
+
+func TestSourceError(t *testing.T) {
+	server := newServer(testToken)
+	server.RegisterSource("test", func() (interface{}, error) {
+		return nil, fmt.Errorf("source failed")
+	})
+
+	status, body := execute(
+		t,
+		server,
+		http.MethodGet,
+		"test",
+		"Bearer "+testToken,
+	)
+
+	if status != http.StatusInternalServerError {
+		t.Fatalf(
+			"unexpected status\nexpected: [%v]\nactual:   [%v]",
+			http.StatusInternalServerError,
+			status,
+		)
+	}
+
+	expected := map[string]interface{}{"error": "source failed"}
+	if !reflect.DeepEqual(expected, body) {
+		t.Errorf(
+			"unexpected body\nexpected: [%v]\nactual:   [%v]",
+			expected,
+			body,
+		)
+	}
+}
+
+func TestAction(t *testing.T) {
+	server := newServer(testToken)
+
+	executions := 0
+	server.RegisterAction("test", func() (interface{}, error) {
+		executions++
+		return nil, nil
+	})
+
+	status, _ := execute(t, server, http.MethodGet, "test", "Bearer "+testToken)
+	if status != http.StatusNotFound {
+		t.Errorf(
+			"unexpected status for GET\nexpected: [%v]\nactual:   [%v]",
+			http.StatusNotFound,
+			status,
+		)
+	}
+
+	status, _ = execute(t, server, http.MethodPost, "test", "Bearer "+testToken)
+	if status != http.StatusOK {
+		t.Errorf(
+			"unexpected status for POST\nexpected: [%v]\nactual:   [%v]",
+			http.StatusOK,
+			status,
+		)
+	}
+
+	if executions != 1 {
+		t.Errorf(
+			"unexpected number of action executions\nexpected: [%v]\nactual:   [%v]",
+			1,
+			executions,
+		)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	server := newServer(testToken)
+	server.RegisterSource("source2", func() (interface{}, error) { return nil, nil })
+	server.RegisterSource("source1", func() (interface{}, error) { return nil, nil })
+	server.RegisterAction("action", func() (interface{}, error) { return nil, nil })
+
+	status, body := execute(t, server, http.MethodGet, "", "Bearer "+testToken)
+	if status != http.StatusOK {
+		t.Fatalf(
+			"unexpected status\nexpected: [%v]\nactual:   [%v]",
+			http.StatusOK,
+			status,
+		)
+	}
+
+	expected := map[string]interface{}{
+		"sources": []interface{}{"source1", "source2"},
+		"actions": []interface{}{"action"},
+	}
+	if !reflect.DeepEqual(expected, body) {
+		t.Errorf(
+			"unexpected body\nexpected: [%v]\nactual:   [%v]",
+			expected,
+			body,
+		)
+	}
+}
+
+func TestInitializeWithPortInUse(t *testing.T) {
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	defer cancelCtx()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	dataDir, err := ioutil.TempDir("", "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dataDir)
+
+	tokenFile := filepath.Join(dataDir, "admin.token")
+
+	_, _, err = Initialize(ctx, port, tokenFile)
+	if err == nil {
+		t.Fatal("expected an error for the port in use")
+	}
+
+	if _, err := os.Stat(tokenFile); !os.IsNotExist(err) {
+		t.Errorf("token file should not be written when the port is in use")
+	}
+}
+
+func TestInitializeClosesServerWhenContextDone(t *testing.T) {
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	defer cancelCtx()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	dataDir, err := ioutil.TempDir("", "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dataDir)
+
+	tokenFile := filepath.Join(dataDir, "admin.token")
+
+	_, isConfigured, err := Initialize(ctx, port, tokenFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isConfigured {
+		t.Fatal("admin API should be configured")
+	}
+
+	cancelCtx()
+
+	// The port is released once the server is closed.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		listener, err := net.Listen("tcp", "127.0.0.1:"+strconv.Itoa(port))
+		if err == nil {
+			listener.Close()
+			return
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("port has not been released: [%v]", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func execute(
+	t *testing.T,
+	server *Server,
+	method string,
+	name string,
+	authorization string,
+) (int, interface{}) {
+	request := httptest.NewRequest(method, pathPrefix+name, nil)
+	if authorization != "" {
+		request.Header.Set("Authorization", authorization)
+	}
+
+	recorder := httptest.NewRecorder()
+	server.handler().ServeHTTP(recorder, request)
+
+	response := recorder.Result()
+	defer response.Body.Close()
+
+	bytes, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var body interface{}
+	if err := json.Unmarshal(bytes, &body); err != nil {
+		t.Fatal(err)
+	}
+
+	return response.StatusCode, body
+}
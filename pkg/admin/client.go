@@ -0,0 +1,83 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clientTimeout is the maximum time the admin API client waits for the
+// response of the server.
+const clientTimeout = 30 * time.Second
+
+// Query fetches the source with the given name from the admin API of the
+// client running locally on the given port and decodes the JSON result into
+// the provided value. The token is read from the token file written by the
+// server when it was initialized.
+func Query(port int, tokenFile string, name string, result interface{}) error {
+	if port == 0 {
+		return fmt.Errorf("admin API is not configured")
+	}
+
+	token, err := ioutil.ReadFile(tokenFile)
+	if err != nil {
+		return fmt.Errorf("could not read token file [%v]: [%v]", tokenFile, err)
+	}
+
+	return query(
+		"http://127.0.0.1:"+strconv.Itoa(port),
+		strings.TrimSpace(string(token)),
+		name,
+		result,
+	)
+}
+
+func query(address string, token string, name string, result interface{}) error {
+	request, err := http.NewRequest(
+		http.MethodGet,
+		address+pathPrefix+name,
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("could not create request: [%v]", err)
+	}
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	client := &http.Client{Timeout: clientTimeout}
+	response, err := client.Do(request)
+	if err != nil {
+		return fmt.Errorf("could not query admin API: [%v]", err)
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return fmt.Errorf("could not read response: [%v]", err)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		var errorResponse struct {
+			Error string `json:"error"`
+		}
+		if err := json.Unmarshal(body, &errorResponse); err != nil ||
+			errorResponse.Error == "" {
+			errorResponse.Error = response.Status
+		}
+
+		return fmt.Errorf(
+			"admin API source [%v] failed: [%v]",
+			name,
+			errorResponse.Error,
+		)
+	}
+
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("could not decode response: [%v]", err)
+	}
+
+	return nil
+}
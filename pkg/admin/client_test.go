@@ -0,0 +1,72 @@
+package admin
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestQuery(t *testing.T) {
+	server := newServer(testToken)
+	server.RegisterSource("test", func() (interface{}, error) {
+		return []map[string]interface{}{{"key": "value"}}, nil
+	})
+	server.RegisterSource("failing", func() (interface{}, error) {
+		return nil, fmt.Errorf("source failure")
+	})
+
+	httpServer := httptest.NewServer(server.handler())
+	defer httpServer.Close()
+
+	var result []map[string]string
+	if err := query(httpServer.URL, testToken, "test", &result); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []map[string]string{{"key": "value"}}
+	if !reflect.DeepEqual(expected, result) {
+		t.Errorf(
+			"unexpected result\nexpected: [%v]\nactual:   [%v]",
+			expected,
+			result,
+		)
+	}
+
+	var tests = map[string]struct {
+		token         string
+		name          string
+		expectedError string
+	}{
+		"invalid token": {
+			token:         "fedcba9876543210",
+			name:          "test",
+			expectedError: "unauthorized",
+		},
+		"unknown source": {
+			token:         testToken,
+			name:          "unknown",
+			expectedError: "unknown source",
+		},
+		"failing source": {
+			token:         testToken,
+			name:          "failing",
+			expectedError: "source failure",
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			var result interface{}
+			err := query(httpServer.URL, test.token, test.name, &result)
+			if err == nil || !strings.Contains(err.Error(), test.expectedError) {
+				t.Errorf(
+					"unexpected error\nexpected: [%v]\nactual:   [%v]",
+					test.expectedError,
+					err,
+				)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package admin
+
+import (
+	"sort"
+
+	"github.com/keep-network/keep-core/pkg/beacon"
+	"github.com/keep-network/keep-core/pkg/chain"
+	"github.com/keep-network/keep-core/pkg/net"
+	"github.com/keep-network/keep-core/pkg/operator"
+)
+
+// RegisterGroupsSource registers the admin source providing information about
+// groups the client is a member of, including their registration and
+// expected expiration blocks. Blocks are reported as zero until the group
+// registration block is resolved from the chain.
+func RegisterGroupsSource(server *Server, beaconHandle *beacon.Handle) {
+	server.RegisterSource("groups", func() (interface{}, error) {
+		groups := beaconHandle.Groups()
+		statuses := beaconHandle.GroupStatuses()
+
+		groupPublicKeys := make([]string, 0, len(groups))
+		for groupPublicKey := range groups {
+			groupPublicKeys = append(groupPublicKeys, groupPublicKey)
+		}
+		sort.Strings(groupPublicKeys)
+
+		groupsList := make([]map[string]interface{}, 0, len(groups))
+		for _, groupPublicKey := range groupPublicKeys {
+			memberships := groups[groupPublicKey]
+
+			channelName := ""
+			memberIndexes := make([]int, len(memberships))
+			for i, membership := range memberships {
+				channelName = membership.ChannelName
+				memberIndexes[i] = int(membership.Signer.MemberID())
+			}
+			sort.Ints(memberIndexes)
+
+			status := statuses[groupPublicKey]
+
+			groupsList = append(groupsList, map[string]interface{}{
+				"group_public_key":        groupPublicKey,
+				"channel_name":            channelName,
+				"member_indexes":          memberIndexes,
+				"registration_block":      status.RegistrationBlock,
+				"expiration_block":        status.ExpirationBlock,
+				"stale_block":             status.StaleBlock,
+				"blocks_until_expiration": status.BlocksUntilExpiration,
+				"last_signing_block":      status.LastSigningBlock,
+			})
+		}
+
+		return groupsList, nil
+	})
+}
+
+// RegisterExecutionsSource registers the admin source providing information
+// about group selections, DKG and relay entry signing executions the client
+// currently takes part in.
+func RegisterExecutionsSource(server *Server, beaconHandle *beacon.Handle) {
+	server.RegisterSource("executions", func() (interface{}, error) {
+		executions := beaconHandle.Executions()
+
+		executionsList := make([]map[string]interface{}, len(executions))
+		for i, execution := range executions {
+			executionsList[i] = map[string]interface{}{
+				"protocol":     execution.Protocol,
+				"id":           execution.ID,
+				"member_index": execution.MemberIndex,
+				"start_block":  execution.StartBlock,
+			}
+		}
+
+		return map[string]interface{}{
+			"draining":                 beaconHandle.IsDraining(),
+			"pending_group_selections": beaconHandle.PendingGroupSelections(),
+			"pending_relay_requests":   beaconHandle.PendingRelayRequests(),
+			"executions":               executionsList,
+		}, nil
+	})
+}
+
+// RegisterPeersSource registers the admin source providing information about
+// connected peers along with their stake status.
+func RegisterPeersSource(
+	server *Server,
+	netProvider net.Provider,
+	stakeMonitor chain.StakeMonitor,
+) {
+	server.RegisterSource("peers", func() (interface{}, error) {
+		connectionManager := netProvider.ConnectionManager()
+		connectedPeers := connectionManager.ConnectedPeers()
+
+		peersList := make([]map[string]interface{}, 0, len(connectedPeers))
+		for _, peer := range connectedPeers {
+			peerPublicKey, err := connectionManager.GetPeerOperatorPublicKey(peer)
+			if err != nil {
+				logger.Errorf("error on getting peer operator public key: [%v]", err)
+				continue
+			}
+
+			address := operator.PubkeyToAddress(*peerPublicKey).String()
+
+			peerInfo := map[string]interface{}{
+				"network_id":       peer,
+				"ethereum_address": address,
+			}
+
+			hasMinimumStake, err := stakeMonitor.HasMinimumStake(address)
+			if err != nil {
+				peerInfo["stake_error"] = err.Error()
+			} else {
+				peerInfo["has_minimum_stake"] = hasMinimumStake
+			}
+
+			peersList = append(peersList, peerInfo)
+		}
+
+		return peersList, nil
+	})
+}
+
+// RegisterDrainAction registers the admin action stopping the beacon from
+// accepting any new work. Executions already in progress are not affected.
+func RegisterDrainAction(server *Server, beaconHandle *beacon.Handle) {
+	server.RegisterAction("drain", func() (interface{}, error) {
+		beaconHandle.Drain()
+
+		return map[string]interface{}{
+			"draining":   true,
+			"executions": len(beaconHandle.Executions()),
+		}, nil
+	})
+}
+
+// RegisterShutdownAction registers the admin action triggering a graceful
+// shutdown of the client. The provided shutdown function is expected to
+// return immediately and perform the shutdown in the background.
+func RegisterShutdownAction(server *Server, shutdown func()) {
+	server.RegisterAction("shutdown", func() (interface{}, error) {
+		shutdown()
+
+		return map[string]interface{}{
+			"shutting_down": true,
+		}, nil
+	})
+}
+
+// RegisterReloadAction registers the admin action reloading the config of
+// the client. The provided reload function is expected to return paths of
+// the config fields whose changes have been applied.
+func RegisterReloadAction(server *Server, reload func() ([]string, error)) {
+	server.RegisterAction("reload", func() (interface{}, error) {
+		applied, err := reload()
+		if err != nil {
+			return nil, err
+		}
+
+		if applied == nil {
+			applied = []string{}
+		}
+
+		return map[string]interface{}{
+			"applied": applied,
+		}, nil
+	})
+}
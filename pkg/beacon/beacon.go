@@ -3,6 +3,7 @@ package beacon
 import (
 	"context"
 	"encoding/hex"
+	"sort"
 	"sync"
 	"time"
 
@@ -15,22 +16,215 @@ import (
 	"github.com/keep-network/keep-core/pkg/beacon/relay/groupselection"
 	"github.com/keep-network/keep-core/pkg/beacon/relay/registry"
 	"github.com/keep-network/keep-core/pkg/chain"
+	"github.com/keep-network/keep-core/pkg/chain/confirmation"
+	"github.com/keep-network/keep-core/pkg/journal"
+	"github.com/keep-network/keep-core/pkg/metrics"
 	"github.com/keep-network/keep-core/pkg/net"
 )
 
 var logger = log.Logger("keep-beacon")
 
+// Handle is a handle to the random beacon running in the client. It allows
+// to inspect the current state of the beacon and to control it. The beacon
+// can host several operators, each with its own network provider; the handle
+// aggregates the state of all of them.
+type Handle struct {
+	confirmationConfig confirmation.Config
+	optimizerConfig    groupselection.OptimizerConfig
+	journal            *journal.Journal
+	metrics            *metrics.Protocol
+
+	operatorsMutex sync.RWMutex
+	operators      []*operator
+
+	drainingMutex sync.RWMutex
+	draining      bool
+}
+
+// operator is a single staking identity hosted by the beacon.
+type operator struct {
+	node                   *relay.Node
+	groupRegistry          *registry.Groups
+	pendingGroupSelections *event.GroupSelectionTrack
+	pendingRelayRequests   *event.RelayRequestTrack
+}
+
+func (h *Handle) operatorsList() []*operator {
+	h.operatorsMutex.RLock()
+	defer h.operatorsMutex.RUnlock()
+
+	return append([]*operator{}, h.operators...)
+}
+
+// Groups returns all groups the client is a member of, keyed by the
+// hexadecimal representation of the uncompressed group public key.
+// Memberships of all hosted operators are included.
+func (h *Handle) Groups() map[string][]*registry.Membership {
+	groups := make(map[string][]*registry.Membership)
+	for _, operator := range h.operatorsList() {
+		for groupPublicKey, memberships := range operator.groupRegistry.GetGroups() {
+			groups[groupPublicKey] = append(groups[groupPublicKey], memberships...)
+		}
+	}
+
+	return groups
+}
+
+// GroupStatuses returns lifecycle statuses of all groups the client is
+// a member of, keyed the same way as groups returned from Groups. If the
+// group has members hosted by multiple operators, the latest signing of any
+// of them is reported.
+func (h *Handle) GroupStatuses() map[string]registry.GroupStatus {
+	statuses := make(map[string]registry.GroupStatus)
+	for _, operator := range h.operatorsList() {
+		for groupPublicKey, status := range operator.groupRegistry.GroupStatuses() {
+			existing, ok := statuses[groupPublicKey]
+			if !ok || existing.RegistrationBlock == 0 {
+				existing.RegistrationBlock = status.RegistrationBlock
+				existing.ExpirationBlock = status.ExpirationBlock
+				existing.StaleBlock = status.StaleBlock
+				existing.BlocksUntilExpiration = status.BlocksUntilExpiration
+			}
+			if status.LastSigningBlock > existing.LastSigningBlock {
+				existing.LastSigningBlock = status.LastSigningBlock
+			}
+			statuses[groupPublicKey] = existing
+		}
+	}
+
+	return statuses
+}
+
+// PendingGroupSelections returns seeds of all group selections the client
+// currently takes part in.
+func (h *Handle) PendingGroupSelections() []string {
+	var entries []string
+	for _, operator := range h.operatorsList() {
+		entries = append(entries, operator.pendingGroupSelections.Entries()...)
+	}
+
+	return uniqueSorted(entries)
+}
+
+// PendingRelayRequests returns previous entries of all relay requests the
+// client currently processes.
+func (h *Handle) PendingRelayRequests() []string {
+	var entries []string
+	for _, operator := range h.operatorsList() {
+		entries = append(entries, operator.pendingRelayRequests.Entries()...)
+	}
+
+	return uniqueSorted(entries)
+}
+
+func uniqueSorted(entries []string) []string {
+	unique := make([]string, 0, len(entries))
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		if !seen[entry] {
+			seen[entry] = true
+			unique = append(unique, entry)
+		}
+	}
+	sort.Strings(unique)
+
+	return unique
+}
+
+// Executions returns all DKG and relay entry signing executions the client
+// currently takes part in.
+func (h *Handle) Executions() []*relay.Execution {
+	var executions []*relay.Execution
+	for _, operator := range h.operatorsList() {
+		executions = append(executions, operator.node.Executions()...)
+	}
+
+	return executions
+}
+
+// Drain stops the beacon from joining new group selections and relay entry
+// signing. Executions already in progress are not affected and they continue
+// until they complete.
+func (h *Handle) Drain() {
+	h.drainingMutex.Lock()
+	defer h.drainingMutex.Unlock()
+
+	if !h.draining {
+		logger.Infof("draining the beacon; no new work will be accepted")
+	}
+
+	h.draining = true
+}
+
+// IsDraining returns true if the beacon has been drained and does not accept
+// any new work.
+func (h *Handle) IsDraining() bool {
+	h.drainingMutex.RLock()
+	defer h.drainingMutex.RUnlock()
+
+	return h.draining
+}
+
 // Initialize kicks off the random beacon by initializing internal state,
 // ensuring preconditions like staking are met, and then kicking off the
-// internal random beacon implementation. Returns an error if this failed,
-// otherwise enters a blocked loop.
+// internal random beacon implementation. Returns a handle to the running
+// beacon or an error if the initialization failed. All group selections, DKG
+// and relay entry signing executions started by the beacon are aborted as soon
+// as the provided context is done. Relay entry requests and group selection
+// starts are acted upon according to the provided confirmation config; work
+// started for events removed by a chain reorganization is aborted. Tickets
+// are submitted in group selections according to the provided optimizer
+// config. Protocol executions of all operators are recorded in the given
+// journal and counted in the given metrics.
 func Initialize(
 	ctx context.Context,
 	stakingID string,
 	chainHandle chain.Handle,
 	netProvider net.Provider,
 	persistence persistence.Handle,
+	confirmationConfig confirmation.Config,
+	optimizerConfig groupselection.OptimizerConfig,
+	eventJournal *journal.Journal,
+	protocolMetrics *metrics.Protocol,
+) (*Handle, error) {
+	handle := &Handle{
+		confirmationConfig: confirmationConfig,
+		optimizerConfig:    optimizerConfig,
+		journal:            eventJournal,
+		metrics:            protocolMetrics,
+	}
+
+	err := handle.AddOperator(
+		ctx,
+		stakingID,
+		chainHandle,
+		netProvider,
+		persistence,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return handle, nil
+}
+
+// AddOperator starts hosting another operator in the running beacon. The
+// operator has its own staker, chain handle used to sign and submit
+// transactions, network provider authenticated with the operator's network
+// identity, and persistence handle for its group memberships. Protocol
+// executions of the operator are recorded in the journal and counted in
+// metrics on behalf of the operator. Each operator monitors relay entries
+// and reports relay entry timeouts on its own.
+func (h *Handle) AddOperator(
+	ctx context.Context,
+	stakingID string,
+	chainHandle chain.Handle,
+	netProvider net.Provider,
+	persistence persistence.Handle,
 ) error {
+	operatorJournal := h.journal.ForOperator(stakingID)
+	operatorMetrics := h.metrics.ForOperator(stakingID)
+
 	relayChain := chainHandle.ThresholdRelay()
 	chainConfig := relayChain.GetConfig()
 
@@ -49,10 +243,28 @@ func Initialize(
 		return err
 	}
 
+	blockHashes, err := chainHandle.BlockHashes()
+	if err != nil {
+		return err
+	}
+
+	confirmer := confirmation.NewConfirmer(
+		h.confirmationConfig,
+		blockCounter,
+		blockHashes,
+		operatorJournal,
+	)
+
 	signing := chainHandle.Signing()
 
-	groupRegistry := registry.NewGroupRegistry(relayChain, persistence)
+	groupRegistry := registry.NewGroupRegistry(
+		relayChain,
+		chainConfig,
+		persistence,
+		operatorMetrics,
+	)
 	groupRegistry.LoadExistingGroups()
+	groupRegistry.MonitorGroups(ctx, blockCounter)
 
 	node := relay.NewNode(
 		staker,
@@ -60,6 +272,9 @@ func Initialize(
 		blockCounter,
 		chainConfig,
 		groupRegistry,
+		persistence,
+		operatorJournal,
+		operatorMetrics,
 	)
 
 	pendingGroupSelections := &event.GroupSelectionTrack{
@@ -72,112 +287,206 @@ func Initialize(
 		Mutex: &sync.Mutex{},
 	}
 
-	node.ResumeSigningIfEligible(relayChain, signing)
+	h.operatorsMutex.Lock()
+	h.operators = append(h.operators, &operator{
+		node:                   &node,
+		groupRegistry:          groupRegistry,
+		pendingGroupSelections: pendingGroupSelections,
+		pendingRelayRequests:   pendingRelayRequests,
+	})
+	h.operatorsMutex.Unlock()
+
+	node.ResumeSigningIfEligible(ctx, relayChain, signing)
+	node.ResumeDKGIfEligible(ctx, relayChain, signing)
 
 	_ = relayChain.OnRelayEntryRequested(func(request *event.Request) {
-		onConfirmed := func() {
-			if node.IsInGroup(request.GroupPublicKey) {
-				go func() {
-					previousEntry := hex.EncodeToString(request.PreviousEntry[:])
+		if request.Removed {
+			confirmer.Retract(
+				"relay entry requested",
+				request.BlockNumber,
+				request.BlockHash,
+			)
+			return
+		}
 
-					if ok := pendingRelayRequests.Add(previousEntry); !ok {
+		confirmer.Deliver(
+			ctx,
+			"relay entry requested",
+			request.BlockNumber,
+			request.BlockHash,
+			func(ctx context.Context) {
+				onConfirmed := func() {
+					isInGroup := node.IsInGroup(request.GroupPublicKey)
+
+					operatorJournal.Record(&journal.Entry{
+						Type:           journal.RelayEntryRequested,
+						BlockNumber:    request.BlockNumber,
+						GroupPublicKey: hex.EncodeToString(request.GroupPublicKey),
+						Details: map[string]interface{}{
+							"previous_entry": hex.EncodeToString(request.PreviousEntry),
+							"in_group":       isInGroup,
+						},
+					})
+
+					if isInGroup && h.IsDraining() {
 						logger.Warningf(
-							"relay entry requested event with previous entry "+
-								"[0x%x] has been registered already",
-							request.PreviousEntry,
+							"beacon is draining; not joining the signing of relay "+
+								"entry requested at block [%v] from group [0x%x]",
+							request.BlockNumber,
+							request.GroupPublicKey,
 						)
-						return
+						isInGroup = false
 					}
 
-					defer pendingRelayRequests.Remove(previousEntry)
-
-					logger.Infof(
-						"new relay entry requested at block [%v] from group "+
-							"[0x%x] using previous entry [0x%x]",
-						request.BlockNumber,
-						request.GroupPublicKey,
-						request.PreviousEntry,
-					)
+					if isInGroup {
+						go func() {
+							previousEntry := hex.EncodeToString(request.PreviousEntry[:])
+
+							if ok := pendingRelayRequests.Add(previousEntry); !ok {
+								logger.Warningf(
+									"relay entry requested event with previous entry "+
+										"[0x%x] has been registered already",
+									request.PreviousEntry,
+								)
+								return
+							}
+
+							defer pendingRelayRequests.Remove(previousEntry)
+
+							logger.Infof(
+								"new relay entry requested at block [%v] from group "+
+									"[0x%x] using previous entry [0x%x]",
+								request.BlockNumber,
+								request.GroupPublicKey,
+								request.PreviousEntry,
+							)
+
+							node.GenerateRelayEntry(
+								ctx,
+								request.PreviousEntry,
+								relayChain,
+								signing,
+								request.GroupPublicKey,
+								request.BlockNumber,
+							)
+						}()
+					} else {
+						go node.ForwardSignatureShares(request.GroupPublicKey)
+					}
 
-					node.GenerateRelayEntry(
-						request.PreviousEntry,
+					go node.MonitorRelayEntry(
+						ctx,
 						relayChain,
-						signing,
-						request.GroupPublicKey,
 						request.BlockNumber,
+						chainConfig,
 					)
-				}()
-			} else {
-				go node.ForwardSignatureShares(request.GroupPublicKey)
-			}
+				}
 
-			go node.MonitorRelayEntry(
-				relayChain,
-				request.BlockNumber,
-				chainConfig,
-			)
-		}
+				currentRelayRequestConfirmationRetries := 30
+				currentRelayRequestConfirmationDelay := time.Second
 
-		currentRelayRequestConfirmationRetries := 30
-		currentRelayRequestConfirmationDelay := time.Second
-
-		confirmCurrentRelayRequest(
-			request.BlockNumber,
-			relayChain,
-			onConfirmed,
-			currentRelayRequestConfirmationRetries,
-			currentRelayRequestConfirmationDelay,
+				confirmCurrentRelayRequest(
+					request.BlockNumber,
+					relayChain,
+					onConfirmed,
+					currentRelayRequestConfirmationRetries,
+					currentRelayRequestConfirmationDelay,
+				)
+			},
 		)
 	})
 
 	_ = relayChain.OnGroupSelectionStarted(func(event *event.GroupSelectionStart) {
-		onGroupSelected := func(group *groupselection.Result) {
-			for index, staker := range group.SelectedStakers {
-				logger.Infof(
-					"new candidate group member [0x%v] with index [%v]",
-					hex.EncodeToString(staker),
-					index,
-				)
-			}
-			node.JoinGroupIfEligible(
-				relayChain,
-				signing,
-				group,
-				event.NewEntry,
+		if event.Removed {
+			confirmer.Retract(
+				"group selection started",
+				event.BlockNumber,
+				event.BlockHash,
 			)
+			return
 		}
 
-		newEntry := event.NewEntry.Text(16)
-		go func() {
-			if ok := pendingGroupSelections.Add(newEntry); !ok {
-				logger.Errorf(
-					"group selection event with seed [0x%x] has been registered already",
-					event.NewEntry,
-				)
-				return
-			}
+		confirmer.Deliver(
+			ctx,
+			"group selection started",
+			event.BlockNumber,
+			event.BlockHash,
+			func(ctx context.Context) {
+				onGroupSelected := func(group *groupselection.Result) {
+					for index, staker := range group.SelectedStakers {
+						logger.Infof(
+							"new candidate group member [0x%v] with index [%v]",
+							hex.EncodeToString(staker),
+							index,
+						)
+					}
+					node.JoinGroupIfEligible(
+						ctx,
+						relayChain,
+						signing,
+						group,
+						event.NewEntry,
+					)
+				}
+
+				if h.IsDraining() {
+					logger.Warningf(
+						"beacon is draining; not joining the group selection "+
+							"started with seed [0x%x] at block [%v]",
+						event.NewEntry,
+						event.BlockNumber,
+					)
+					return
+				}
 
-			defer pendingGroupSelections.Remove(newEntry)
+				newEntry := event.NewEntry.Text(16)
+				go func() {
+					if ok := pendingGroupSelections.Add(newEntry); !ok {
+						logger.Errorf(
+							"group selection event with seed [0x%x] has been registered already",
+							event.NewEntry,
+						)
+						return
+					}
 
-			logger.Infof(
-				"group selection started with seed [0x%x] at block [%v]",
-				event.NewEntry,
-				event.BlockNumber,
-			)
+					defer pendingGroupSelections.Remove(newEntry)
 
-			err := groupselection.CandidateToNewGroup(
-				relayChain,
-				blockCounter,
-				chainConfig,
-				staker,
-				event.NewEntry,
-				event.BlockNumber,
-				onGroupSelected,
-			)
-			if err != nil {
-				logger.Errorf("Tickets submission failed: [%v]", err)
-			}
-		}()
+					done := node.TrackGroupSelection(
+						event.NewEntry,
+						event.BlockNumber,
+					)
+					defer done()
+
+					logger.Infof(
+						"group selection started with seed [0x%x] at block [%v]",
+						event.NewEntry,
+						event.BlockNumber,
+					)
+					operatorJournal.Record(&journal.Entry{
+						Type:        journal.GroupSelectionStarted,
+						BlockNumber: event.BlockNumber,
+						Seed:        journal.SeedString(event.NewEntry),
+					})
+
+					err := groupselection.CandidateToNewGroup(
+						ctx,
+						relayChain,
+						blockCounter,
+						chainConfig,
+						h.optimizerConfig,
+						operatorJournal,
+						operatorMetrics,
+						staker,
+						event.NewEntry,
+						event.BlockNumber,
+						onGroupSelected,
+					)
+					if err != nil {
+						logger.Errorf("Tickets submission failed: [%v]", err)
+					}
+				}()
+			},
+		)
 	})
 
 	_ = relayChain.OnGroupRegistered(func(registration *event.GroupRegistration) {
@@ -64,6 +64,9 @@ type GroupSelectionInterface interface {
 	SubmitTicket(ticket *Ticket) *async.EventGroupTicketSubmissionPromise
 	// GetSubmittedTickets gets the submitted group candidate tickets so far.
 	GetSubmittedTickets() ([]uint64, error)
+	// TicketSubmissionCost returns the expected cost in wei of submitting
+	// a single ticket at the current gas price.
+	TicketSubmissionCost() (*big.Int, error)
 	// GetSelectedParticipants returns `GroupSize` slice of addresses of
 	// candidates which have been selected to the currently assembling group.
 	GetSelectedParticipants() ([]StakerAddress, error)
@@ -83,6 +86,10 @@ type GroupRegistrationInterface interface {
 	// in the past. Stale group is never selected by the chain to any new
 	// operation.
 	IsStaleGroup(groupPublicKey []byte) (bool, error)
+	// GetGroupRegistrationBlock returns the number of the block at which the
+	// group with the given public key has been registered on-chain. An error
+	// is returned if the group registration could not be found.
+	GetGroupRegistrationBlock(groupPublicKey []byte) (uint64, error)
 	// GetGroupMembers returns `GroupSize` slice of addresses of
 	// participants which have been selected to the group with given public key.
 	GetGroupMembers(groupPublicKey []byte) ([]StakerAddress, error)
@@ -162,6 +169,10 @@ type Config struct {
 	// entry to be published by the selected group. Blocks are
 	// counted from the moment relay request occur.
 	RelayEntryTimeout uint64
+	// GroupActiveTime is the duration (in blocks) after which a group expires
+	// and is no longer selected to new operations. Blocks are counted from
+	// the moment the group has been registered.
+	GroupActiveTime uint64
 }
 
 // DishonestThreshold is the maximum number of misbehaving participants for
@@ -2,6 +2,7 @@ package dkg
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"math/big"
 
@@ -12,14 +13,37 @@ import (
 	"github.com/keep-network/keep-core/pkg/beacon/relay/event"
 	"github.com/keep-network/keep-core/pkg/beacon/relay/gjkr"
 	"github.com/keep-network/keep-core/pkg/beacon/relay/group"
+	"github.com/keep-network/keep-core/pkg/beacon/relay/state"
 	"github.com/keep-network/keep-core/pkg/chain"
+	"github.com/keep-network/keep-core/pkg/journal"
+	"github.com/keep-network/keep-core/pkg/metrics"
 	"github.com/keep-network/keep-core/pkg/net"
 )
 
 var logger = log.Logger("keep-dkg")
 
-// ExecuteDKG runs the full distributed key generation lifecycle.
+// Checkpoint captures the progress of the distributed key generation. It
+// allows to resume the execution after a restart. Checkpoint contains private
+// values and should never be exposed.
+type Checkpoint struct {
+	// GJKR is the checkpoint of the key generation protocol.
+	GJKR *gjkr.Checkpoint
+	// Publication is the checkpoint of the result publication protocol.
+	Publication *state.Checkpoint
+}
+
+// ExecuteDKG runs the full distributed key generation lifecycle. Progress of
+// the execution is recorded in the given journal and counted in the given
+// metrics. The execution is aborted as soon as the provided context is done.
+//
+// If the shares exchange is provided, peer shares are delivered to other
+// group members over unicast channels in addition to the broadcast channel.
+//
+// If the checkpoint is provided, the execution is resumed from that
+// checkpoint instead of being started from scratch. The checkpoint handler,
+// if provided, is called each time the execution progresses.
 func ExecuteDKG(
+	ctx context.Context,
 	seed *big.Int,
 	index uint8, // starts with 0
 	groupSize int,
@@ -30,24 +54,59 @@ func ExecuteDKG(
 	relayChain relayChain.Interface,
 	signing chain.Signing,
 	channel net.BroadcastChannel,
+	sharesExchange *gjkr.SharesExchange,
+	eventJournal *journal.Journal,
+	protocolMetrics *metrics.Protocol,
+	checkpoint *Checkpoint,
+	onCheckpoint func(*Checkpoint),
 ) (*ThresholdSigner, error) {
 	// The staker index should begin with 1
 	playerIndex := group.MemberIndex(index + 1)
 
+	protocolMetrics.DKGStarted.Inc()
+
 	gjkr.RegisterUnmarshallers(channel)
 	dkgResult.RegisterUnmarshallers(channel)
 
+	progress := &Checkpoint{}
+	if checkpoint != nil {
+		progress.GJKR = checkpoint.GJKR
+		progress.Publication = checkpoint.Publication
+	}
+
+	var onGJKRCheckpoint func(*gjkr.Checkpoint)
+	var onPublicationCheckpoint func(*state.Checkpoint)
+	if onCheckpoint != nil {
+		onGJKRCheckpoint = func(gjkrCheckpoint *gjkr.Checkpoint) {
+			progress.GJKR = gjkrCheckpoint
+			onCheckpoint(progress)
+		}
+		onPublicationCheckpoint = func(publicationCheckpoint *state.Checkpoint) {
+			progress.Publication = publicationCheckpoint
+			onCheckpoint(progress)
+		}
+	}
+
 	gjkrResult, gjkrEndBlockHeight, err := gjkr.Execute(
+		ctx,
 		playerIndex,
 		groupSize,
 		blockCounter,
 		channel,
+		sharesExchange,
 		dishonestThreshold,
 		seed,
 		membershipValidator,
 		startBlockHeight,
+		eventJournal,
+		protocolMetrics,
+		progress.GJKR,
+		onGJKRCheckpoint,
 	)
 	if err != nil {
+		protocolMetrics.DKGFailed.Inc(
+			failureReason(ctx, metrics.DKGFailureKeyGeneration),
+		)
 		return nil, fmt.Errorf(
 			"[member:%v] GJKR execution failed [%v]",
 			playerIndex,
@@ -66,6 +125,7 @@ func ExecuteDKG(
 	defer dkgResultSubscription.Unsubscribe()
 
 	err = dkgResult.Publish(
+		ctx,
 		playerIndex,
 		gjkrResult.Group,
 		membershipValidator,
@@ -74,7 +134,10 @@ func ExecuteDKG(
 		relayChain,
 		signing,
 		blockCounter,
+		eventJournal,
 		startPublicationBlockHeight,
+		progress.Publication,
+		onPublicationCheckpoint,
 	)
 	if err != nil {
 		// Result publication failed. It means that either the result this
@@ -90,6 +153,7 @@ func ExecuteDKG(
 		)
 
 		if err := decideMemberFate(
+			ctx,
 			playerIndex,
 			gjkrResult,
 			dkgResultChannel,
@@ -97,10 +161,15 @@ func ExecuteDKG(
 			relayChain,
 			blockCounter,
 		); err != nil {
+			protocolMetrics.DKGFailed.Inc(
+				failureReason(ctx, metrics.DKGFailureResultPublication),
+			)
 			return nil, err
 		}
 	}
 
+	protocolMetrics.DKGSucceeded.Inc()
+
 	return &ThresholdSigner{
 		memberIndex:          playerIndex,
 		groupPublicKey:       gjkrResult.GroupPublicKey,
@@ -109,11 +178,22 @@ func ExecuteDKG(
 	}, nil
 }
 
+// failureReason returns the given reason of the DKG execution failure unless
+// the execution failed because it has been aborted.
+func failureReason(ctx context.Context, reason string) string {
+	if ctx.Err() != nil {
+		return metrics.DKGFailureAborted
+	}
+
+	return reason
+}
+
 // decideMemberFate decides what the member will do in case it failed
 // publishing its DKG result. Member can stay in the group if it
 // supports the same group public key as the one registered on-chain and
 // the member is not considered as misbehaving by the group.
 func decideMemberFate(
+	ctx context.Context,
 	playerIndex group.MemberIndex,
 	gjkrResult *gjkr.Result,
 	dkgResultChannel chan *event.DKGResultSubmission,
@@ -122,6 +202,7 @@ func decideMemberFate(
 	blockCounter chain.BlockCounter,
 ) error {
 	dkgResultEvent, err := waitForDkgResultEvent(
+		ctx,
 		dkgResultChannel,
 		startPublicationBlockHeight,
 		relayChain,
@@ -161,6 +242,7 @@ func decideMemberFate(
 }
 
 func waitForDkgResultEvent(
+	ctx context.Context,
 	dkgResultChannel chan *event.DKGResultSubmission,
 	startPublicationBlockHeight uint64,
 	relayChain relayChain.Interface,
@@ -182,5 +264,10 @@ func waitForDkgResultEvent(
 		return dkgResultEvent, nil
 	case <-timeoutBlockChannel:
 		return nil, fmt.Errorf("DKG result publication timed out")
+	case <-ctx.Done():
+		return nil, fmt.Errorf(
+			"waiting for DKG result publication cancelled: [%v]",
+			ctx.Err(),
+		)
 	}
 }
@@ -1,6 +1,7 @@
 package dkg
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 	"reflect"
@@ -43,6 +44,7 @@ func TestDecideMemberFate_HappyPath(t *testing.T) {
 	}
 
 	err := decideMemberFate(
+		context.Background(),
 		playerIndex,
 		gjkrResult,
 		dkgResultChannel,
@@ -70,6 +72,7 @@ func TestDecideMemberFate_NotSameGroupPublicKey(t *testing.T) {
 	}
 
 	err := decideMemberFate(
+		context.Background(),
 		playerIndex,
 		gjkrResult,
 		dkgResultChannel,
@@ -101,6 +104,7 @@ func TestDecideMemberFate_MemberIsMisbehaved(t *testing.T) {
 	}
 
 	err := decideMemberFate(
+		context.Background(),
 		playerIndex,
 		gjkrResult,
 		dkgResultChannel,
@@ -127,6 +131,7 @@ func TestDecideMemberFate_Timeout(t *testing.T) {
 	setup()
 
 	err := decideMemberFate(
+		context.Background(),
 		playerIndex,
 		gjkrResult,
 		dkgResultChannel,
@@ -144,3 +149,32 @@ func TestDecideMemberFate_Timeout(t *testing.T) {
 		)
 	}
 }
+
+func TestDecideMemberFate_Cancelled(t *testing.T) {
+	setup()
+
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	cancelCtx()
+
+	err := decideMemberFate(
+		ctx,
+		playerIndex,
+		gjkrResult,
+		dkgResultChannel,
+		startPublicationBlockHeight,
+		localChain.ThresholdRelay(),
+		blockCounter,
+	)
+
+	expectedError := fmt.Errorf(
+		"waiting for DKG result publication cancelled: [%v]",
+		context.Canceled,
+	)
+	if !reflect.DeepEqual(expectedError, err) {
+		t.Errorf(
+			"unexpected error\nexpected: %v\nactual:   %v\n",
+			expectedError,
+			err,
+		)
+	}
+}
@@ -1,6 +1,7 @@
 package result
 
 import (
+	"context"
 	"fmt"
 
 	relayChain "github.com/keep-network/keep-core/pkg/beacon/relay/chain"
@@ -8,6 +9,7 @@ import (
 	"github.com/keep-network/keep-core/pkg/beacon/relay/group"
 	"github.com/keep-network/keep-core/pkg/beacon/relay/state"
 	"github.com/keep-network/keep-core/pkg/chain"
+	"github.com/keep-network/keep-core/pkg/journal"
 	"github.com/keep-network/keep-core/pkg/net"
 )
 
@@ -16,17 +18,34 @@ import (
 // required protocol message unmarshallers.
 // The channel needs to be fully initialized before Publish is called.
 func RegisterUnmarshallers(channel net.BroadcastChannel) {
-	channel.SetUnmarshaler(func() net.TaggedUnmarshaler {
-		return &DKGResultHashSignatureMessage{}
-	})
+	for _, unmarshaller := range unmarshallers() {
+		channel.SetUnmarshaler(unmarshaller)
+	}
+}
+
+func unmarshallers() []func() net.TaggedUnmarshaler {
+	return []func() net.TaggedUnmarshaler{
+		func() net.TaggedUnmarshaler {
+			return &DKGResultHashSignatureMessage{}
+		},
+	}
 }
 
 // Publish executes Phase 13 and 14 of DKG as a state machine. First, the
 // chosen result is hashed, signed, and sent over a broadcast channel. Then, all
 // other signatures and results are received and accounted for. Those that match
 // our own result and added to the list of votes. Finally, we submit the result
-// along with everyone's votes.
+// along with everyone's votes. Votes and state transitions are recorded in
+// the given journal. The publication is aborted as soon as the provided
+// context is done.
+//
+// If the checkpoint is provided, the publication is resumed from that
+// checkpoint instead of being started from scratch. A publication which
+// already finished is never resumed, so that the result is not submitted
+// again. The checkpoint handler, if provided, is called each time
+// a publication state completes.
 func Publish(
+	ctx context.Context,
 	memberIndex group.MemberIndex,
 	dkgGroup *group.Group,
 	membershipValidator group.MembershipValidator,
@@ -35,22 +54,55 @@ func Publish(
 	relayChain relayChain.Interface,
 	signing chain.Signing,
 	blockCounter chain.BlockCounter,
+	eventJournal *journal.Journal,
 	startBlockHeight uint64,
+	checkpoint *state.Checkpoint,
+	onCheckpoint func(*state.Checkpoint),
 ) error {
+	if checkpoint != nil && checkpoint.Finished {
+		logger.Infof(
+			"[member:%v] DKG result publication already finished",
+			memberIndex,
+		)
+		return nil
+	}
+
+	replayChannel := state.NewReplayChannel(channel)
+
 	initialState := &resultSigningState{
-		channel:                 channel,
+		channel:                 replayChannel,
 		relayChain:              relayChain,
 		signing:                 signing,
 		blockCounter:            blockCounter,
+		journal:                 eventJournal,
 		member:                  NewSigningMember(memberIndex, dkgGroup, membershipValidator),
 		result:                  convertGjkrResult(result),
 		signatureMessages:       make([]*DKGResultHashSignatureMessage, 0),
 		signingStartBlockHeight: startBlockHeight,
 	}
 
-	stateMachine := state.NewMachine(channel, blockCounter, initialState)
+	stateMachine := state.NewMachine(
+		replayChannel,
+		blockCounter,
+		eventJournal,
+		initialState,
+	)
 
-	lastState, _, err := stateMachine.Execute(startBlockHeight)
+	if onCheckpoint != nil {
+		stateMachine.OnCheckpoint(onCheckpoint)
+	}
+
+	var lastState state.State
+	var err error
+	if checkpoint != nil {
+		lastState, _, err = stateMachine.Resume(
+			ctx,
+			checkpoint,
+			unmarshallers()...,
+		)
+	} else {
+		lastState, _, err = stateMachine.Execute(ctx, startBlockHeight)
+	}
 	if err != nil {
 		return err
 	}
@@ -3,11 +3,13 @@ package result
 import (
 	"bytes"
 	"context"
+	"encoding/hex"
 
 	relayChain "github.com/keep-network/keep-core/pkg/beacon/relay/chain"
 	"github.com/keep-network/keep-core/pkg/beacon/relay/group"
 	"github.com/keep-network/keep-core/pkg/beacon/relay/state"
 	"github.com/keep-network/keep-core/pkg/chain"
+	"github.com/keep-network/keep-core/pkg/journal"
 	"github.com/keep-network/keep-core/pkg/net"
 )
 
@@ -35,6 +37,7 @@ type resultSigningState struct {
 	relayChain   relayChain.Interface
 	signing      chain.Signing
 	blockCounter chain.BlockCounter
+	journal      *journal.Journal
 
 	member *SigningMember
 
@@ -61,6 +64,9 @@ func (rss *resultSigningState) Initiate(ctx context.Context) error {
 	if err := rss.channel.Send(ctx, message); err != nil {
 		return err
 	}
+
+	rss.journalVote(rss.result.GroupPublicKey, message)
+
 	return nil
 }
 
@@ -79,18 +85,35 @@ func (rss *resultSigningState) Receive(msg net.Message) error {
 	// In this final step, we compare the pinned network key with one used to
 	// produce a signature over the DKG result hash. If the keys don't match,
 	// it means that an incorrect key was used to sign DKG result hash and
-	// the message should be rejected.
+	// the message should be rejected and its sender reported to the network
+	// layer as misbehaving.
 	isValidKeyUsed := func(phaseMessage *DKGResultHashSignatureMessage) bool {
-		return bytes.Compare(phaseMessage.publicKey, msg.SenderPublicKey()) == 0
+		if bytes.Compare(phaseMessage.publicKey, msg.SenderPublicKey()) == 0 {
+			return true
+		}
+
+		logger.Warningf(
+			"[member: %v] sender [%d] used invalid key to sign "+
+				"the result hash",
+			rss.member.index,
+			phaseMessage.senderIndex,
+		)
+		net.ReportMisbehavior(rss.channel, msg)
+
+		return false
 	}
 
 	switch signedMessage := msg.Payload().(type) {
 	case *DKGResultHashSignatureMessage:
 		if !group.IsMessageFromSelf(rss.member.index, signedMessage) &&
-			group.IsSenderValid(rss.member, signedMessage, msg.SenderPublicKey()) &&
+			group.IsSenderValidOrReport(rss.member, signedMessage, rss.channel, msg) &&
 			group.IsSenderAccepted(rss.member, signedMessage) &&
 			isValidKeyUsed(signedMessage) {
 			rss.signatureMessages = append(rss.signatureMessages, signedMessage)
+
+			// The group public key other member voted for is not known,
+			// just the hash of the result.
+			rss.journalVote(nil, signedMessage)
 		}
 	}
 
@@ -235,3 +258,31 @@ func (rss *resultSubmissionState) Next() signingState {
 func (rss *resultSubmissionState) MemberIndex() group.MemberIndex {
 	return rss.member.index
 }
+
+// resultSigningPhase is the journal phase of DKG result votes.
+const resultSigningPhase = "result_signing"
+
+// journalVote records in the journal the vote for the DKG result carried by
+// the given message. The vote is recorded once per voting member, no matter
+// how many members hosted by the client received it. The group public key is
+// optional and should be provided only if the voted result is known. The
+// broadcast channel used for DKG is named after the group selection seed.
+func (rss *resultSigningState) journalVote(
+	groupPublicKey []byte,
+	message *DKGResultHashSignatureMessage,
+) {
+	if !rss.journal.Enabled() {
+		return
+	}
+
+	rss.journal.Record(&journal.Entry{
+		Type:           journal.DKGResultVote,
+		Seed:           rss.channel.Name(),
+		GroupPublicKey: hex.EncodeToString(groupPublicKey),
+		MemberIndex:    uint8(message.senderIndex),
+		Phase:          resultSigningPhase,
+		Details: map[string]interface{}{
+			"result_hash": hex.EncodeToString(message.resultHash[:]),
+		},
+	})
+}
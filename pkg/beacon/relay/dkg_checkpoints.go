@@ -0,0 +1,143 @@
+package relay
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/keep-network/keep-common/pkg/persistence"
+
+	relaychain "github.com/keep-network/keep-core/pkg/beacon/relay/chain"
+	"github.com/keep-network/keep-core/pkg/beacon/relay/dkg"
+)
+
+const (
+	dkgCheckpointDirectoryPrefix = "dkg_"
+	dkgCheckpointFileName        = "checkpoint"
+)
+
+// dkgCheckpoint is the persisted progress of a single member's DKG execution.
+// It carries everything needed to resume the execution after a restart.
+type dkgCheckpoint struct {
+	Seed             *big.Int
+	Index            uint8
+	SelectedStakers  []relaychain.StakerAddress
+	StartBlockHeight uint64
+	Progress         *dkg.Checkpoint
+}
+
+type dkgCheckpointStorage interface {
+	save(checkpoint *dkgCheckpoint) error
+	readAll() (<-chan *dkgCheckpoint, <-chan error)
+	archive(seed *big.Int, index uint8) error
+}
+
+type persistentDKGCheckpointStorage struct {
+	handle persistence.Handle
+}
+
+func newDKGCheckpointStorage(
+	persistence persistence.Handle,
+) dkgCheckpointStorage {
+	return &persistentDKGCheckpointStorage{
+		handle: persistence,
+	}
+}
+
+func dkgCheckpointDirectory(seed *big.Int, index uint8) string {
+	return fmt.Sprintf("%v%v_%v", dkgCheckpointDirectoryPrefix, seed.Text(16), index)
+}
+
+func (pcs *persistentDKGCheckpointStorage) save(
+	checkpoint *dkgCheckpoint,
+) error {
+	checkpointBytes, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("marshalling of the DKG checkpoint failed: [%v]", err)
+	}
+
+	return pcs.handle.Save(
+		checkpointBytes,
+		dkgCheckpointDirectory(checkpoint.Seed, checkpoint.Index),
+		dkgCheckpointFileName,
+	)
+}
+
+func (pcs *persistentDKGCheckpointStorage) archive(
+	seed *big.Int,
+	index uint8,
+) error {
+	return pcs.handle.Archive(dkgCheckpointDirectory(seed, index))
+}
+
+func (pcs *persistentDKGCheckpointStorage) readAll() (
+	<-chan *dkgCheckpoint,
+	<-chan error,
+) {
+	outputCheckpoints := make(chan *dkgCheckpoint)
+	outputErrors := make(chan error)
+
+	inputData, inputErrors := pcs.handle.ReadAll()
+
+	// The same as for group memberships, data and errors channels are read
+	// by two goroutines as we don't know in what order producers write to
+	// them. The third goroutine closes output channels once both are done.
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		wg.Wait()
+		close(outputCheckpoints)
+		close(outputErrors)
+	}()
+
+	go func() {
+		for err := range inputErrors {
+			outputErrors <- err
+		}
+		wg.Done()
+	}()
+
+	go func() {
+		for descriptor := range inputData {
+			// The same persistence handle is used to store other data, such
+			// as group memberships. Skip all of them.
+			if !strings.HasPrefix(
+				descriptor.Directory(),
+				dkgCheckpointDirectoryPrefix,
+			) {
+				continue
+			}
+
+			content, err := descriptor.Content()
+			if err != nil {
+				outputErrors <- fmt.Errorf(
+					"could not read DKG checkpoint from file [%v] in directory [%v]: [%v]",
+					descriptor.Name(),
+					descriptor.Directory(),
+					err,
+				)
+				continue
+			}
+
+			checkpoint := &dkgCheckpoint{}
+			if err := json.Unmarshal(content, checkpoint); err != nil {
+				outputErrors <- fmt.Errorf(
+					"could not unmarshal DKG checkpoint from file [%v] in directory [%v]: [%v]",
+					descriptor.Name(),
+					descriptor.Directory(),
+					err,
+				)
+				continue
+			}
+
+			outputCheckpoints <- checkpoint
+		}
+
+		wg.Done()
+	}()
+
+	return outputCheckpoints, outputErrors
+}
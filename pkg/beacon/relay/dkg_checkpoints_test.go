@@ -0,0 +1,229 @@
+package relay
+
+import (
+	"math/big"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/keep-network/keep-common/pkg/persistence"
+	relaychain "github.com/keep-network/keep-core/pkg/beacon/relay/chain"
+	"github.com/keep-network/keep-core/pkg/beacon/relay/dkg"
+	"github.com/keep-network/keep-core/pkg/beacon/relay/gjkr"
+	"github.com/keep-network/keep-core/pkg/beacon/relay/group"
+	"github.com/keep-network/keep-core/pkg/beacon/relay/state"
+)
+
+func TestDKGCheckpointStorage(t *testing.T) {
+	handle := newTestPersistenceHandle()
+	storage := newDKGCheckpointStorage(handle)
+
+	// data of other components stored with the same handle
+	if err := handle.Save([]byte{0x01}, "03ab", "membership_1"); err != nil {
+		t.Fatal(err)
+	}
+
+	checkpoint1 := &dkgCheckpoint{
+		Seed:  big.NewInt(1234),
+		Index: 0,
+		SelectedStakers: []relaychain.StakerAddress{
+			[]byte{0x01, 0x02},
+			[]byte{0x03, 0x04},
+		},
+		StartBlockHeight: 10,
+		Progress: &dkg.Checkpoint{
+			GJKR: &gjkr.Checkpoint{
+				Machine: &state.Checkpoint{
+					CompletedStates: 2,
+					EndBlockHeight:  15,
+					Messages: []*state.RecordedMessage{
+						{
+							StateIndex:      1,
+							Type:            "gjkr/ephemeral_public_key",
+							SenderPublicKey: []byte{0x05},
+							Payload:         []byte{0x06},
+						},
+					},
+				},
+				EphemeralPrivateKeys: map[group.MemberIndex][]byte{
+					2: []byte{0x07},
+				},
+				CoefficientsA: []*big.Int{big.NewInt(8)},
+				CoefficientsB: []*big.Int{big.NewInt(9)},
+			},
+		},
+	}
+	checkpoint2 := &dkgCheckpoint{
+		Seed:             big.NewInt(1234),
+		Index:            1,
+		StartBlockHeight: 10,
+		Progress:         &dkg.Checkpoint{},
+	}
+
+	if err := storage.save(checkpoint1); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.save(checkpoint2); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := readAllDKGCheckpoints(t, storage)
+	if len(loaded) != 2 {
+		t.Fatalf(
+			"unexpected number of checkpoints\nexpected: [%v]\nactual:   [%v]",
+			2,
+			len(loaded),
+		)
+	}
+	if !reflect.DeepEqual(checkpoint1, loaded[0]) {
+		t.Errorf(
+			"unexpected checkpoint\nexpected: [%+v]\nactual:   [%+v]",
+			checkpoint1,
+			loaded[0],
+		)
+	}
+	if !reflect.DeepEqual(checkpoint2, loaded[1]) {
+		t.Errorf(
+			"unexpected checkpoint\nexpected: [%+v]\nactual:   [%+v]",
+			checkpoint2,
+			loaded[1],
+		)
+	}
+
+	if err := storage.archive(checkpoint1.Seed, checkpoint1.Index); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded = readAllDKGCheckpoints(t, storage)
+	if len(loaded) != 1 {
+		t.Fatalf(
+			"unexpected number of checkpoints after archiving\nexpected: [%v]\nactual:   [%v]",
+			1,
+			len(loaded),
+		)
+	}
+	if loaded[0].Index != checkpoint2.Index {
+		t.Errorf(
+			"unexpected checkpoint after archiving\nexpected: [%v]\nactual:   [%v]",
+			checkpoint2.Index,
+			loaded[0].Index,
+		)
+	}
+}
+
+func readAllDKGCheckpoints(
+	t *testing.T,
+	storage dkgCheckpointStorage,
+) []*dkgCheckpoint {
+	checkpointsChannel, errorsChannel := storage.readAll()
+
+	var checkpoints []*dkgCheckpoint
+	var errors []error
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		for checkpoint := range checkpointsChannel {
+			checkpoints = append(checkpoints, checkpoint)
+		}
+		wg.Done()
+	}()
+	go func() {
+		for err := range errorsChannel {
+			errors = append(errors, err)
+		}
+		wg.Done()
+	}()
+	wg.Wait()
+
+	for _, err := range errors {
+		t.Errorf("unexpected error: [%v]", err)
+	}
+
+	// the test persistence handle returns data in the order it was saved
+	return checkpoints
+}
+
+type testPersistenceHandle struct {
+	mutex sync.Mutex
+	dirs  []string
+	data  map[string]map[string][]byte
+}
+
+func newTestPersistenceHandle() *testPersistenceHandle {
+	return &testPersistenceHandle{
+		data: make(map[string]map[string][]byte),
+	}
+}
+
+func (tph *testPersistenceHandle) Save(data []byte, dir, name string) error {
+	tph.mutex.Lock()
+	defer tph.mutex.Unlock()
+
+	if _, ok := tph.data[dir]; !ok {
+		tph.dirs = append(tph.dirs, dir)
+		tph.data[dir] = make(map[string][]byte)
+	}
+	tph.data[dir][name] = data
+
+	return nil
+}
+
+func (tph *testPersistenceHandle) Snapshot(data []byte, dir, name string) error {
+	return tph.Save(data, dir, name)
+}
+
+func (tph *testPersistenceHandle) ReadAll() (
+	<-chan persistence.DataDescriptor,
+	<-chan error,
+) {
+	tph.mutex.Lock()
+	defer tph.mutex.Unlock()
+
+	dataChannel := make(chan persistence.DataDescriptor, len(tph.dirs))
+	errorChannel := make(chan error)
+
+	for _, dir := range tph.dirs {
+		for name, content := range tph.data[dir] {
+			dataChannel <- &testDataDescriptor{name, dir, content}
+		}
+	}
+
+	close(dataChannel)
+	close(errorChannel)
+
+	return dataChannel, errorChannel
+}
+
+func (tph *testPersistenceHandle) Archive(dir string) error {
+	tph.mutex.Lock()
+	defer tph.mutex.Unlock()
+
+	delete(tph.data, dir)
+	for i, existing := range tph.dirs {
+		if existing == dir {
+			tph.dirs = append(tph.dirs[:i], tph.dirs[i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+type testDataDescriptor struct {
+	name      string
+	directory string
+	content   []byte
+}
+
+func (tdd *testDataDescriptor) Name() string {
+	return tdd.name
+}
+
+func (tdd *testDataDescriptor) Directory() string {
+	return tdd.directory
+}
+
+func (tdd *testDataDescriptor) Content() ([]byte, error) {
+	return tdd.content, nil
+}
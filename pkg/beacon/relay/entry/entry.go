@@ -2,6 +2,7 @@ package entry
 
 import (
 	"context"
+	"encoding/hex"
 	"fmt"
 
 	"github.com/keep-network/keep-core/pkg/beacon/relay/event"
@@ -13,6 +14,8 @@ import (
 	"github.com/keep-network/keep-core/pkg/beacon/relay/group"
 	"github.com/keep-network/keep-core/pkg/bls"
 	"github.com/keep-network/keep-core/pkg/chain"
+	"github.com/keep-network/keep-core/pkg/journal"
+	"github.com/keep-network/keep-core/pkg/metrics"
 	"github.com/keep-network/keep-core/pkg/net"
 )
 
@@ -30,8 +33,11 @@ func RegisterUnmarshallers(channel net.BroadcastChannel) {
 
 // SignAndSubmit triggers the threshold signature process for the
 // previous relay entry and publishes the signature to the chain as
-// a new relay entry.
+// a new relay entry. Received signature shares and the submitted entry are
+// recorded in the given journal and counted in the given metrics. The process
+// is aborted as soon as the provided context is done.
 func SignAndSubmit(
+	parentCtx context.Context,
 	blockCounter chain.BlockCounter,
 	channel net.BroadcastChannel,
 	relayChain relayChain.Interface,
@@ -39,8 +45,10 @@ func SignAndSubmit(
 	honestThreshold int,
 	signer *dkg.ThresholdSigner,
 	startBlockHeight uint64,
+	eventJournal *journal.Journal,
+	protocolMetrics *metrics.Protocol,
 ) error {
-	ctx, cancelCtx := context.WithCancel(context.Background())
+	ctx, cancelCtx := context.WithCancel(parentCtx)
 	defer cancelCtx()
 
 	relayEntrySubmittedChannel := make(chan uint64)
@@ -91,6 +99,8 @@ func SignAndSubmit(
 				continue
 			}
 
+			protocolMetrics.SignatureSharesReceived.Inc()
+
 			share, err := extractAndValidateShare(
 				message,
 				signer.GroupPublicKeyShares(),
@@ -104,6 +114,14 @@ func SignAndSubmit(
 					message.senderID,
 					err,
 				)
+				journalShare(
+					eventJournal,
+					journal.SignatureShareRejected,
+					signer,
+					message.senderID,
+					err,
+				)
+				protocolMetrics.SignatureSharesRejected.Inc()
 				continue
 			}
 
@@ -112,6 +130,14 @@ func SignAndSubmit(
 				signer.MemberID(),
 				message.senderID,
 			)
+			journalShare(
+				eventJournal,
+				journal.SignatureShareReceived,
+				signer,
+				message.senderID,
+				nil,
+			)
+			protocolMetrics.SignatureSharesAccepted.Inc()
 
 			receivedValidShares[message.senderID] = share
 		case blockNumber := <-relayEntrySubmittedChannel:
@@ -128,6 +154,12 @@ func SignAndSubmit(
 				blockNumber,
 				len(receivedValidShares),
 			)
+		case <-ctx.Done():
+			return fmt.Errorf(
+				"signing cancelled; received [%v] valid signature shares: [%v]",
+				len(receivedValidShares),
+				ctx.Err(),
+			)
 		}
 	}
 
@@ -139,6 +171,7 @@ func SignAndSubmit(
 	submitter := &relayEntrySubmitter{
 		chain:        relayChain,
 		blockCounter: blockCounter,
+		journal:      eventJournal,
 		index:        signer.MemberID(),
 	}
 
@@ -148,6 +181,7 @@ func SignAndSubmit(
 	// still a possibility those signals appear in the future so the submitter
 	// must be aware of them and break the execution if they occur.
 	return submitter.submitRelayEntry(
+		ctx,
 		signature.Marshal(),
 		signer.GroupPublicKeyBytes(),
 		startBlockHeight,
@@ -176,6 +210,35 @@ func broadcastShare(
 	}
 }
 
+// journalShare records in the journal the signature share received from
+// the given member, along with the reason of the rejection, if the share has
+// been rejected.
+func journalShare(
+	eventJournal *journal.Journal,
+	entryType string,
+	signer *dkg.ThresholdSigner,
+	senderID group.MemberIndex,
+	rejectionReason error,
+) {
+	if !eventJournal.Enabled() {
+		return
+	}
+
+	details := map[string]interface{}{
+		"sender": senderID,
+	}
+	if rejectionReason != nil {
+		details["reason"] = rejectionReason.Error()
+	}
+
+	eventJournal.Record(&journal.Entry{
+		Type:           entryType,
+		GroupPublicKey: hex.EncodeToString(signer.GroupPublicKeyBytes()),
+		MemberIndex:    uint8(signer.MemberID()),
+		Details:        details,
+	})
+}
+
 func extractAndValidateShare(
 	message *SignatureShareMessage,
 	groupPublicKeyShares map[group.MemberIndex]*bn256.G2,
@@ -1,17 +1,21 @@
 package entry
 
 import (
+	"context"
+	"encoding/hex"
 	"fmt"
 
 	relayChain "github.com/keep-network/keep-core/pkg/beacon/relay/chain"
 	"github.com/keep-network/keep-core/pkg/beacon/relay/event"
 	"github.com/keep-network/keep-core/pkg/beacon/relay/group"
 	"github.com/keep-network/keep-core/pkg/chain"
+	"github.com/keep-network/keep-core/pkg/journal"
 )
 
 type relayEntrySubmitter struct {
 	chain        relayChain.Interface
 	blockCounter chain.BlockCounter
+	journal      *journal.Journal
 
 	index group.MemberIndex
 }
@@ -21,8 +25,10 @@ type relayEntrySubmitter struct {
 // Group member with index 1 tries to submit as the first one, group member 2
 // tries to submit after a few blocks if member 1 did not submit and so on.
 // Relay entry submit process starts at block height defined by startBlockheight
-// parameter.
+// parameter. The submission is abandoned as soon as the provided context is
+// done.
 func (res *relayEntrySubmitter) submitRelayEntry(
+	ctx context.Context,
 	newEntry []byte,
 	groupPublicKey []byte,
 	startBlockHeight uint64,
@@ -68,6 +74,15 @@ func (res *relayEntrySubmitter) submitRelayEntry(
 							res.index,
 							entry.BlockNumber,
 						)
+						res.journal.Record(&journal.Entry{
+							Type:           journal.RelayEntrySubmitted,
+							BlockNumber:    entry.BlockNumber,
+							GroupPublicKey: hex.EncodeToString(groupPublicKey),
+							MemberIndex:    uint8(res.index),
+							Details: map[string]interface{}{
+								"entry": hex.EncodeToString(newEntry),
+							},
+						})
 					}
 					errorChannel <- err
 				})
@@ -113,6 +128,11 @@ func (res *relayEntrySubmitter) submitRelayEntry(
 				"relay entry timed out at block [%v]",
 				blockNumber,
 			)
+		case <-ctx.Done():
+			return fmt.Errorf(
+				"relay entry submission cancelled: [%v]",
+				ctx.Err(),
+			)
 		}
 	}
 }
@@ -22,16 +22,28 @@ type EntryGenerated struct {
 }
 
 // Request represents a request for an entry in the threshold relay.
+//
+// BlockHash is the hash of the block the request has been emitted in, if the
+// chain provides it. Removed is set if the request has been removed from the
+// chain by a chain reorganization after it had been emitted.
 type Request struct {
 	PreviousEntry  []byte
 	GroupPublicKey []byte
 	BlockNumber    uint64
+	BlockHash      []byte
+	Removed        bool
 }
 
 // GroupSelectionStart represents a group selection start event.
+//
+// BlockHash is the hash of the block the event has been emitted in, if the
+// chain provides it. Removed is set if the event has been removed from the
+// chain by a chain reorganization after it had been emitted.
 type GroupSelectionStart struct {
 	NewEntry    *big.Int
 	BlockNumber uint64
+	BlockHash   []byte
+	Removed     bool
 }
 
 // GroupTicketSubmission represents a group selection ticket submission event.
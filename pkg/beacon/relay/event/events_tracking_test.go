@@ -1,6 +1,7 @@
 package event
 
 import (
+	"reflect"
 	"sync"
 	"testing"
 )
@@ -142,3 +143,50 @@ func TestRelayRequestTrack_WhenEmpty(t *testing.T) {
 		t.Error("RelayEntryRequested event wasn't emitted before; should be added successfully")
 	}
 }
+
+func TestGroupSelectionTrack_Entries(t *testing.T) {
+	gst := &GroupSelectionTrack{
+		Data:  make(map[string]bool),
+		Mutex: &sync.Mutex{},
+	}
+
+	gst.Add("0x67891")
+	gst.Add("0x12345")
+	gst.Add("0xabcde")
+	gst.Remove("0xabcde")
+
+	expected := []string{"0x12345", "0x67891"}
+	actual := gst.Entries()
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf(
+			"unexpected entries\nexpected: [%v]\nactual:   [%v]",
+			expected,
+			actual,
+		)
+	}
+}
+
+func TestRelayRequestTrack_Entries(t *testing.T) {
+	rrt := &RelayRequestTrack{
+		Data:  make(map[string]bool),
+		Mutex: &sync.Mutex{},
+	}
+
+	if entries := rrt.Entries(); len(entries) != 0 {
+		t.Errorf("expected no entries; has: [%v]", entries)
+	}
+
+	rrt.Add("0x12345")
+
+	expected := []string{"0x12345"}
+	actual := rrt.Entries()
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf(
+			"unexpected entries\nexpected: [%v]\nactual:   [%v]",
+			expected,
+			actual,
+		)
+	}
+}
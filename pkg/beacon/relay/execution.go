@@ -0,0 +1,95 @@
+package relay
+
+import (
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/keep-network/keep-core/pkg/beacon/relay/group"
+)
+
+// Protocols executed by the node.
+const (
+	ProtocolGroupSelection = "group-selection"
+	ProtocolDKG            = "dkg"
+	ProtocolSigning        = "signing"
+)
+
+// Execution represents a single protocol execution the node currently takes
+// part in. For group selection and DKG, the identifier is the hexadecimal
+// representation of the group selection seed; group selection executions
+// have no member index. For relay entry signing, the identifier is the
+// broadcast channel name of the signing group, that is, the hexadecimal
+// representation of the compressed group public key.
+type Execution struct {
+	Protocol    string
+	ID          string
+	MemberIndex group.MemberIndex
+	StartBlock  uint64
+}
+
+type executions struct {
+	mutex sync.Mutex
+	data  map[*Execution]bool
+}
+
+// start registers the given execution as in progress and returns a function
+// which should be called once the execution completes, no matter if it
+// succeeded or failed.
+func (e *executions) start(execution *Execution) func() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.data == nil {
+		e.data = make(map[*Execution]bool)
+	}
+
+	e.data[execution] = true
+
+	return func() {
+		e.mutex.Lock()
+		defer e.mutex.Unlock()
+
+		delete(e.data, execution)
+	}
+}
+
+func (e *executions) list() []*Execution {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	list := make([]*Execution, 0, len(e.data))
+	for execution := range e.data {
+		list = append(list, execution)
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].StartBlock != list[j].StartBlock {
+			return list[i].StartBlock < list[j].StartBlock
+		}
+		if list[i].ID != list[j].ID {
+			return list[i].ID < list[j].ID
+		}
+		return list[i].MemberIndex < list[j].MemberIndex
+	})
+
+	return list
+}
+
+// Executions returns all protocol executions the node currently takes part
+// in, ordered by their start block.
+func (n *Node) Executions() []*Execution {
+	return n.executions.list()
+}
+
+// TrackGroupSelection registers the group selection started with the given
+// seed at the given block as a protocol execution of the node. It returns
+// a function which should be called once the node completes its part in the
+// group selection.
+func (n *Node) TrackGroupSelection(seed *big.Int, startBlock uint64) func() {
+	return n.executions.start(&Execution{
+		Protocol:   ProtocolGroupSelection,
+		ID:         seed.Text(16),
+		StartBlock: startBlock,
+	})
+}
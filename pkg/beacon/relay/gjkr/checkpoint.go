@@ -0,0 +1,66 @@
+package gjkr
+
+import (
+	"math/big"
+
+	"github.com/keep-network/keep-core/pkg/beacon/relay/group"
+	"github.com/keep-network/keep-core/pkg/beacon/relay/state"
+	"github.com/keep-network/keep-core/pkg/net/ephemeral"
+)
+
+// Checkpoint captures the progress of the GJKR protocol execution along with
+// all the random values generated by the member so far. It allows to resume
+// the protocol after a restart. Checkpoint contains private values and should
+// never be exposed.
+type Checkpoint struct {
+	// Machine is the checkpoint of the protocol state machine.
+	Machine *state.Checkpoint
+
+	// EphemeralPrivateKeys are the ephemeral private keys generated in phase 1
+	// for every other group member.
+	EphemeralPrivateKeys map[group.MemberIndex][]byte
+	// CoefficientsA are the coefficients of the sharing polynomial generated
+	// in phase 3.
+	CoefficientsA []*big.Int
+	// CoefficientsB are the coefficients of the hiding polynomial generated
+	// in phase 3.
+	CoefficientsB []*big.Int
+}
+
+// checkpoint creates a checkpoint of the protocol execution from the given
+// state machine checkpoint and secrets of the member.
+func (mc *memberCore) checkpoint(machineCheckpoint *state.Checkpoint) *Checkpoint {
+	checkpoint := &Checkpoint{
+		Machine:              machineCheckpoint,
+		EphemeralPrivateKeys: make(map[group.MemberIndex][]byte),
+	}
+
+	if mc.secrets == nil {
+		return checkpoint
+	}
+
+	for memberIndex, keyPair := range mc.secrets.ephemeralKeyPairs {
+		checkpoint.EphemeralPrivateKeys[memberIndex] = keyPair.PrivateKey.Marshal()
+	}
+
+	checkpoint.CoefficientsA = mc.secrets.coefficientsA
+	checkpoint.CoefficientsB = mc.secrets.coefficientsB
+
+	return checkpoint
+}
+
+// restore restores secrets of the member from the given checkpoint so that
+// the member uses the same random values as before the checkpoint was taken.
+func (mc *memberCore) restore(checkpoint *Checkpoint) {
+	mc.secrets = &memberSecrets{
+		ephemeralKeyPairs: make(map[group.MemberIndex]*ephemeral.KeyPair),
+		coefficientsA:     checkpoint.CoefficientsA,
+		coefficientsB:     checkpoint.CoefficientsB,
+	}
+
+	for memberIndex, privateKey := range checkpoint.EphemeralPrivateKeys {
+		mc.secrets.ephemeralKeyPairs[memberIndex] = ephemeral.UnmarshalKeyPair(
+			privateKey,
+		)
+	}
+}
@@ -0,0 +1,99 @@
+package gjkr
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/keep-network/keep-core/pkg/beacon/relay/state"
+)
+
+func TestCheckpointRestoresSecrets(t *testing.T) {
+	groupSize := 3
+	dishonestThreshold := 1
+	seed := big.NewInt(18313131145)
+
+	member, err := NewMember(1, groupSize, dishonestThreshold, nil, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ephemeralMember := member.InitializeEphemeralKeysGeneration()
+	ephemeralMessage, err := ephemeralMember.GenerateEphemeralKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	committingMember := ephemeralMember.
+		InitializeSymmetricKeyGeneration().
+		InitializeCommitting()
+	coefficientsA, coefficientsB, err := committingMember.generatePolynomials()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkpointBytes, err := json.Marshal(
+		member.checkpoint(&state.Checkpoint{CompletedStates: 3}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkpoint := &Checkpoint{}
+	if err := json.Unmarshal(checkpointBytes, checkpoint); err != nil {
+		t.Fatal(err)
+	}
+
+	if checkpoint.Machine.CompletedStates != 3 {
+		t.Errorf(
+			"unexpected number of completed states\nexpected: [%v]\nactual:   [%v]",
+			3,
+			checkpoint.Machine.CompletedStates,
+		)
+	}
+
+	restoredMember, err := NewMember(1, groupSize, dishonestThreshold, nil, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restoredMember.restore(checkpoint)
+
+	restoredEphemeralMember := restoredMember.InitializeEphemeralKeysGeneration()
+	restoredEphemeralMessage, err := restoredEphemeralMember.GenerateEphemeralKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(ephemeralMessage, restoredEphemeralMessage) {
+		t.Errorf(
+			"unexpected ephemeral public key message\nexpected: [%v]\nactual:   [%v]",
+			ephemeralMessage,
+			restoredEphemeralMessage,
+		)
+	}
+
+	restoredCoefficientsA, restoredCoefficientsB, err := restoredEphemeralMember.
+		InitializeSymmetricKeyGeneration().
+		InitializeCommitting().
+		generatePolynomials()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(coefficientsA, restoredCoefficientsA) {
+		t.Errorf(
+			"unexpected sharing polynomial coefficients\nexpected: [%v]\nactual:   [%v]",
+			coefficientsA,
+			restoredCoefficientsA,
+		)
+	}
+
+	if !reflect.DeepEqual(coefficientsB, restoredCoefficientsB) {
+		t.Errorf(
+			"unexpected hiding polynomial coefficients\nexpected: [%v]\nactual:   [%v]",
+			coefficientsB,
+			restoredCoefficientsB,
+		)
+	}
+}
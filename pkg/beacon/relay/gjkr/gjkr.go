@@ -1,6 +1,7 @@
 package gjkr
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 
@@ -9,6 +10,8 @@ import (
 	"github.com/keep-network/keep-core/pkg/beacon/relay/group"
 	"github.com/keep-network/keep-core/pkg/beacon/relay/state"
 	"github.com/keep-network/keep-core/pkg/chain"
+	"github.com/keep-network/keep-core/pkg/journal"
+	"github.com/keep-network/keep-core/pkg/metrics"
 	"github.com/keep-network/keep-core/pkg/net"
 )
 
@@ -19,33 +22,35 @@ var logger = log.Logger("keep-gjkr")
 // message unmarshallers.
 // The channel needs to be fully initialized before Execute is called.
 func RegisterUnmarshallers(channel net.BroadcastChannel) {
-	channel.SetUnmarshaler(func() net.TaggedUnmarshaler {
-		return &EphemeralPublicKeyMessage{}
-	})
-
-	channel.SetUnmarshaler(func() net.TaggedUnmarshaler {
-		return &MemberCommitmentsMessage{}
-	})
-
-	channel.SetUnmarshaler(func() net.TaggedUnmarshaler {
-		return &PeerSharesMessage{}
-	})
-
-	channel.SetUnmarshaler(func() net.TaggedUnmarshaler {
-		return &SecretSharesAccusationsMessage{}
-	})
-
-	channel.SetUnmarshaler(func() net.TaggedUnmarshaler {
-		return &MemberPublicKeySharePointsMessage{}
-	})
-
-	channel.SetUnmarshaler(func() net.TaggedUnmarshaler {
-		return &PointsAccusationsMessage{}
-	})
+	for _, unmarshaller := range unmarshallers() {
+		channel.SetUnmarshaler(unmarshaller)
+	}
+}
 
-	channel.SetUnmarshaler(func() net.TaggedUnmarshaler {
-		return &MisbehavedEphemeralKeysMessage{}
-	})
+func unmarshallers() []func() net.TaggedUnmarshaler {
+	return []func() net.TaggedUnmarshaler{
+		func() net.TaggedUnmarshaler {
+			return &EphemeralPublicKeyMessage{}
+		},
+		func() net.TaggedUnmarshaler {
+			return &MemberCommitmentsMessage{}
+		},
+		func() net.TaggedUnmarshaler {
+			return &PeerSharesMessage{}
+		},
+		func() net.TaggedUnmarshaler {
+			return &SecretSharesAccusationsMessage{}
+		},
+		func() net.TaggedUnmarshaler {
+			return &MemberPublicKeySharePointsMessage{}
+		},
+		func() net.TaggedUnmarshaler {
+			return &PointsAccusationsMessage{}
+		},
+		func() net.TaggedUnmarshaler {
+			return &MisbehavedEphemeralKeysMessage{}
+		},
+	}
 }
 
 // Execute runs the GJKR distributed key generation  protocol, given a
@@ -54,16 +59,32 @@ func RegisterUnmarshallers(channel net.BroadcastChannel) {
 // when DKG protocol should start.
 // If the generation is successful, it returns a threshold group member which
 // can participate in the signing group; if the generation fails, it returns an
-// error.
+// error. The generation is aborted as soon as the provided context is done.
+//
+// State transitions and accusations are recorded in the given journal and
+// misbehaving members are counted in the given metrics.
+//
+// If the shares exchange is provided, peer shares are delivered to their
+// receivers over unicast channels in addition to the broadcast channel.
+//
+// If the checkpoint is provided, the protocol is resumed from that checkpoint
+// instead of being started from scratch. The checkpoint handler, if provided,
+// is called each time a protocol state completes.
 func Execute(
+	ctx context.Context,
 	memberIndex group.MemberIndex,
 	groupSize int,
 	blockCounter chain.BlockCounter,
 	channel net.BroadcastChannel,
+	sharesExchange *SharesExchange,
 	dishonestThreshold int,
 	seed *big.Int,
 	membershipValidator group.MembershipValidator,
 	startBlockHeight uint64,
+	eventJournal *journal.Journal,
+	protocolMetrics *metrics.Protocol,
+	checkpoint *Checkpoint,
+	onCheckpoint func(*Checkpoint),
 ) (*Result, uint64, error) {
 	logger.Debugf("[member:%v] initializing member", memberIndex)
 
@@ -78,14 +99,62 @@ func Execute(
 		return nil, 0, fmt.Errorf("cannot create a new member: [%v]", err)
 	}
 
+	if checkpoint != nil {
+		member.restore(checkpoint)
+	}
+
+	member.misbehaviorReporter = newMisbehaviorReporter(channel)
+
+	replayChannel := state.NewReplayChannel(channel)
+
+	unicastShares := newUnicastShares(
+		sharesExchange,
+		replayChannel,
+		memberIndex,
+		membershipValidator,
+	)
+	if sharesExchange != nil {
+		sharesExchange.onShares(ctx, unicastShares.receive)
+	}
+
 	initialState := &ephemeralKeyPairGenerationState{
-		channel: channel,
-		member:  member.InitializeEphemeralKeysGeneration(),
+		channel:       replayChannel,
+		member:        member.InitializeEphemeralKeysGeneration(),
+		unicastShares: unicastShares,
+		journal:       eventJournal,
 	}
 
-	stateMachine := state.NewMachine(channel, blockCounter, initialState)
+	stateMachine := state.NewMachine(
+		replayChannel,
+		blockCounter,
+		eventJournal,
+		initialState,
+	)
 
-	lastState, endBlockHeight, err := stateMachine.Execute(startBlockHeight)
+	stateMachine.OnStateCompleted(
+		newMisbehaviourObserver(member.group, protocolMetrics).observe,
+	)
+
+	if onCheckpoint != nil {
+		stateMachine.OnCheckpoint(func(machineCheckpoint *state.Checkpoint) {
+			onCheckpoint(member.checkpoint(machineCheckpoint))
+		})
+	}
+
+	var lastState state.State
+	var endBlockHeight uint64
+	if checkpoint != nil && checkpoint.Machine != nil {
+		lastState, endBlockHeight, err = stateMachine.Resume(
+			ctx,
+			checkpoint.Machine,
+			unmarshallers()...,
+		)
+	} else {
+		lastState, endBlockHeight, err = stateMachine.Execute(
+			ctx,
+			startBlockHeight,
+		)
+	}
 	if err != nil {
 		return nil, 0, err
 	}
@@ -12,6 +12,7 @@ import (
 	"github.com/keep-network/keep-core/pkg/beacon/relay/gjkr"
 	"github.com/keep-network/keep-core/pkg/beacon/relay/group"
 	"github.com/keep-network/keep-core/pkg/internal/dkgtest"
+	"github.com/keep-network/keep-core/pkg/internal/faultnet"
 	"github.com/keep-network/keep-core/pkg/net"
 	"github.com/keep-network/keep-core/pkg/net/ephemeral"
 )
@@ -71,6 +72,48 @@ func TestExecute_IA_member1_phase1(t *testing.T) {
 	dkgtest.AssertResultSupportingMembers(t, result, []group.MemberIndex{2, 3, 4, 5}...)
 }
 
+// Network partition test case - a member is partitioned from the rest of the
+// group for the entire protocol execution. The member does not receive any
+// messages from other members and other members do not receive any messages
+// from that member. The member is considered inactive by all other members.
+func TestExecute_IA_member5_partitioned(t *testing.T) {
+	t.Parallel()
+
+	groupSize := 5
+	honestThreshold := 3
+	seed := dkgtest.RandomSeed(t)
+
+	interceptor := func(msg net.TaggedMarshaler) net.TaggedMarshaler {
+		return msg
+	}
+
+	faults := faultnet.Config{
+		Seed: seed.Int64(),
+		Partitions: []faultnet.Partition{
+			{Members: []group.MemberIndex{5}},
+		},
+	}
+
+	result, err := dkgtest.RunTestWithFaults(
+		groupSize,
+		honestThreshold,
+		seed,
+		interceptor,
+		faults,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dkgtest.AssertDkgResultPublished(t, result)
+	dkgtest.AssertSuccessfulSignersCount(t, result, groupSize-1)
+	dkgtest.AssertSuccessfulSigners(t, result, []group.MemberIndex{1, 2, 3, 4}...)
+	dkgtest.AssertMemberFailuresCount(t, result, 1)
+	dkgtest.AssertSamePublicKey(t, result)
+	dkgtest.AssertMisbehavingMembers(t, result, group.MemberIndex(5))
+	dkgtest.AssertValidGroupPublicKey(t, result)
+}
+
 func TestExecute_IA_members12_phase3(t *testing.T) {
 	t.Parallel()
 
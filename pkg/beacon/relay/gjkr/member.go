@@ -25,6 +25,24 @@ type memberCore struct {
 
 	// Cryptographic protocol parameters, the same for all members in the group.
 	protocolParameters *protocolParameters
+
+	// Random values generated by the member during the protocol execution.
+	// They are kept to let the member resume the protocol after a restart
+	// with exactly the same values. If nil, values are not kept.
+	secrets *memberSecrets
+
+	// Reporter of members which sent invalid messages to the network layer.
+	// If nil, members are not reported.
+	misbehaviorReporter *misbehaviorReporter
+}
+
+// memberSecrets holds random values generated by the member during the
+// protocol execution. They are private and should never be exposed.
+type memberSecrets struct {
+	// Ephemeral key pairs generated in phase 1 for every other group member.
+	ephemeralKeyPairs map[group.MemberIndex]*ephemeral.KeyPair
+	// Coefficients of sharing and hiding polynomials generated in phase 3.
+	coefficientsA, coefficientsB []*big.Int
 }
 
 // LocalMember represents one member in a threshold group, prior to the
@@ -238,6 +256,10 @@ func NewMember(
 			membershipValidator,
 			newDkgEvidenceLog(),
 			newProtocolParameters(seed),
+			&memberSecrets{
+				ephemeralKeyPairs: make(map[group.MemberIndex]*ephemeral.KeyPair),
+			},
+			nil,
 		},
 	}, nil
 }
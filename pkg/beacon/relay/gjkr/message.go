@@ -47,6 +47,10 @@ type PeerSharesMessage struct {
 	senderID group.MemberIndex // i
 
 	shares map[group.MemberIndex]*peerShares // j -> (s_ij, t_ij)
+
+	// unicast is true if the message has been received over a unicast
+	// channel and contains only shares computed for the receiver.
+	unicast bool
 }
 
 type peerShares struct {
@@ -0,0 +1,68 @@
+package gjkr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/keep-network/keep-core/pkg/beacon/relay/group"
+	"github.com/keep-network/keep-core/pkg/beacon/relay/state"
+	"github.com/keep-network/keep-core/pkg/metrics"
+)
+
+// misbehaviourObserver updates metrics of misbehaving members each time
+// a protocol state completes. Members disqualified or marked as inactive
+// since the previous state completed are attributed to the completed state.
+// States replayed when the protocol is resumed from a checkpoint are observed
+// as well, so misbehaviour detected before the checkpoint is attributed to
+// the phase it has been detected in, not to the first state executed live.
+type misbehaviourObserver struct {
+	group   *group.Group
+	metrics *metrics.Protocol
+
+	disqualifiedCount int
+	inactiveCount     int
+}
+
+func newMisbehaviourObserver(
+	dkgGroup *group.Group,
+	protocolMetrics *metrics.Protocol,
+) *misbehaviourObserver {
+	return &misbehaviourObserver{
+		group:             dkgGroup,
+		metrics:           protocolMetrics,
+		disqualifiedCount: len(dkgGroup.DisqualifiedMemberIDs()),
+		inactiveCount:     len(dkgGroup.InactiveMemberIDs()),
+	}
+}
+
+func (mo *misbehaviourObserver) observe(completedState state.State) {
+	phase := phaseName(completedState)
+
+	disqualifiedCount := len(mo.group.DisqualifiedMemberIDs())
+	if disqualifiedCount > mo.disqualifiedCount {
+		mo.metrics.DKGMisbehavingMembers.Add(
+			float64(disqualifiedCount-mo.disqualifiedCount),
+			phase,
+			metrics.MisbehaviourDisqualified,
+		)
+		mo.disqualifiedCount = disqualifiedCount
+	}
+
+	inactiveCount := len(mo.group.InactiveMemberIDs())
+	if inactiveCount > mo.inactiveCount {
+		mo.metrics.DKGMisbehavingMembers.Add(
+			float64(inactiveCount-mo.inactiveCount),
+			phase,
+			metrics.MisbehaviourInactive,
+		)
+		mo.inactiveCount = inactiveCount
+	}
+}
+
+// phaseName returns the name of the protocol phase covered by the given
+// state, e.g. `commitmentsVerification` for `commitmentsVerificationState`.
+func phaseName(protocolState state.State) string {
+	name := fmt.Sprintf("%T", protocolState)
+	name = name[strings.LastIndex(name, ".")+1:]
+	return strings.TrimSuffix(name, "State")
+}
@@ -0,0 +1,66 @@
+package gjkr
+
+import (
+	"sync"
+
+	"github.com/keep-network/keep-core/pkg/beacon/relay/group"
+	"github.com/keep-network/keep-core/pkg/net"
+)
+
+// misbehaviorReporter reports members whose messages have been rejected by
+// the protocol as invalid to the network layer, so that the reputation of
+// peers they use is lowered. Transport identifiers of members are learned
+// from messages accepted from them by protocol states.
+type misbehaviorReporter struct {
+	channel net.BroadcastChannel
+
+	transportIDsMutex sync.Mutex
+	transportIDs      map[group.MemberIndex]net.TransportIdentifier
+}
+
+func newMisbehaviorReporter(channel net.BroadcastChannel) *misbehaviorReporter {
+	return &misbehaviorReporter{
+		channel:      channel,
+		transportIDs: make(map[group.MemberIndex]net.TransportIdentifier),
+	}
+}
+
+// recordSender records the transport identifier of the member which sent
+// the given message. Messages replayed when the protocol is resumed from
+// a checkpoint carry no transport identifier and are ignored.
+func (mr *misbehaviorReporter) recordSender(
+	senderID group.MemberIndex,
+	message net.Message,
+) {
+	if mr == nil || message.TransportSenderID() == nil {
+		return
+	}
+
+	mr.transportIDsMutex.Lock()
+	defer mr.transportIDsMutex.Unlock()
+
+	mr.transportIDs[senderID] = message.TransportSenderID()
+}
+
+// report reports the member as misbehaving if the transport identifier of
+// the member is known and the channel supports reporting misbehavior.
+func (mr *misbehaviorReporter) report(memberID group.MemberIndex) {
+	if mr == nil {
+		return
+	}
+
+	reporter, ok := mr.channel.(net.MisbehaviorReporter)
+	if !ok {
+		return
+	}
+
+	mr.transportIDsMutex.Lock()
+	transportID, ok := mr.transportIDs[memberID]
+	mr.transportIDsMutex.Unlock()
+
+	if !ok {
+		return
+	}
+
+	reporter.ReportMisbehavior(transportID)
+}
@@ -0,0 +1,107 @@
+package gjkr
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/keep-network/keep-core/pkg/beacon/relay/group"
+	"github.com/keep-network/keep-core/pkg/net"
+)
+
+func TestReportInvalidMemberCommitmentsMessage(t *testing.T) {
+	dishonestThreshold := 1
+	groupSize := 3
+
+	members, err := initializeCommittingMembersGroup(dishonestThreshold, groupSize)
+	if err != nil {
+		t.Fatalf("group initialization failed [%s]", err)
+	}
+
+	member1 := members[0]
+	member2 := members[1]
+	member3 := members[2]
+
+	channel := &reportingBroadcastChannel{}
+	member3.misbehaviorReporter = newMisbehaviorReporter(channel)
+
+	var sharesMessages []*PeerSharesMessage
+	var commitmentsMessages []*MemberCommitmentsMessage
+	for _, member := range []*CommittingMember{member1, member2} {
+		sharesMessage, commitmentsMessage, err :=
+			member.CalculateMembersSharesAndCommitments()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		member3.misbehaviorReporter.recordSender(
+			member.ID,
+			&mockUnicastMessage{transportID: peerID(member.ID)},
+		)
+
+		sharesMessages = append(sharesMessages, sharesMessage)
+		commitmentsMessages = append(commitmentsMessages, commitmentsMessage)
+	}
+
+	// Member 2 sends fewer commitments than expected.
+	commitmentsMessages[1].commitments = commitmentsMessages[1].commitments[1:]
+
+	verifyingMember := member3.InitializeCommitmentsVerification()
+
+	_, err = verifyingMember.VerifyReceivedSharesAndCommitmentsMessages(
+		sharesMessages,
+		commitmentsMessages,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedReported := []string{peerID(member2.ID)}
+	if !reflect.DeepEqual(expectedReported, channel.reported) {
+		t.Errorf(
+			"unexpected reported peers\nexpected: [%v]\nactual:   [%v]",
+			expectedReported,
+			channel.reported,
+		)
+	}
+}
+
+func TestReportMemberWithUnknownTransportID(t *testing.T) {
+	channel := &reportingBroadcastChannel{}
+	reporter := newMisbehaviorReporter(channel)
+
+	// Replayed messages carry no transport identifier.
+	reporter.recordSender(1, &replayedTestMessage{})
+	reporter.report(1)
+
+	if len(channel.reported) != 0 {
+		t.Errorf(
+			"unexpected reported peers\nexpected: [%v]\nactual:   [%v]",
+			[]string{},
+			channel.reported,
+		)
+	}
+}
+
+func peerID(memberID group.MemberIndex) string {
+	return "peer-" + string('0'+rune(memberID))
+}
+
+type reportingBroadcastChannel struct {
+	net.BroadcastChannel
+
+	reported []string
+}
+
+func (rbc *reportingBroadcastChannel) ReportMisbehavior(
+	sender net.TransportIdentifier,
+) {
+	rbc.reported = append(rbc.reported, sender.String())
+}
+
+type replayedTestMessage struct {
+	mockUnicastMessage
+}
+
+func (rtm *replayedTestMessage) TransportSenderID() net.TransportIdentifier {
+	return nil
+}
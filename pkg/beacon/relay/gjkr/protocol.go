@@ -38,7 +38,7 @@ func (em *EphemeralKeyPairGeneratingMember) GenerateEphemeralKeyPair() (
 			continue
 		}
 
-		ephemeralKeyPair, err := ephemeral.GenerateKeyPair()
+		ephemeralKeyPair, err := em.generateEphemeralKeyPair(member)
 		if err != nil {
 			return nil, err
 		}
@@ -56,6 +56,30 @@ func (em *EphemeralKeyPairGeneratingMember) GenerateEphemeralKeyPair() (
 	}, nil
 }
 
+// generateEphemeralKeyPair generates an ephemeral key pair for the given
+// group member. If the member resumes the protocol after a restart, the key
+// pair generated before the restart is used.
+func (em *EphemeralKeyPairGeneratingMember) generateEphemeralKeyPair(
+	otherMember group.MemberIndex,
+) (*ephemeral.KeyPair, error) {
+	if em.secrets != nil {
+		if keyPair, ok := em.secrets.ephemeralKeyPairs[otherMember]; ok {
+			return keyPair, nil
+		}
+	}
+
+	keyPair, err := ephemeral.GenerateKeyPair()
+	if err != nil {
+		return nil, err
+	}
+
+	if em.secrets != nil {
+		em.secrets.ephemeralKeyPairs[otherMember] = keyPair
+	}
+
+	return keyPair, nil
+}
+
 // GenerateSymmetricKeys attempts to generate symmetric keys for all remote group
 // members via ECDH. It generates this symmetric key for each remote group member
 // by doing an ECDH between the ephemeral private key generated for a remote
@@ -77,6 +101,7 @@ func (sm *SymmetricKeyGeneratingMember) GenerateSymmetricKeys(
 				otherMember,
 			)
 			sm.group.MarkMemberAsDisqualified(otherMember)
+			sm.misbehaviorReporter.report(otherMember)
 			continue
 		}
 
@@ -160,20 +185,9 @@ func (cm *CommittingMember) CalculateMembersSharesAndCommitments() (
 	*MemberCommitmentsMessage,
 	error,
 ) {
-	polynomialDegree := cm.group.DishonestThreshold()
-	coefficientsA, err := generatePolynomial(polynomialDegree)
+	coefficientsA, coefficientsB, err := cm.generatePolynomials()
 	if err != nil {
-		return nil, nil, fmt.Errorf(
-			"could not generate shares polynomial [%v]",
-			err,
-		)
-	}
-	coefficientsB, err := generatePolynomial(polynomialDegree)
-	if err != nil {
-		return nil, nil, fmt.Errorf(
-			"could not generate hiding polynomial [%v]",
-			err,
-		)
+		return nil, nil, err
 	}
 
 	cm.secretCoefficients = coefficientsA
@@ -230,6 +244,38 @@ func (cm *CommittingMember) CalculateMembersSharesAndCommitments() (
 	return sharesMessage, commitmentsMessage, nil
 }
 
+// generatePolynomials generates coefficients of the sharing polynomial `a`
+// and the hiding polynomial `b`. If the member resumes the protocol after
+// a restart, coefficients generated before the restart are used.
+func (cm *CommittingMember) generatePolynomials() ([]*big.Int, []*big.Int, error) {
+	if cm.secrets != nil && cm.secrets.coefficientsA != nil {
+		return cm.secrets.coefficientsA, cm.secrets.coefficientsB, nil
+	}
+
+	polynomialDegree := cm.group.DishonestThreshold()
+	coefficientsA, err := generatePolynomial(polynomialDegree)
+	if err != nil {
+		return nil, nil, fmt.Errorf(
+			"could not generate shares polynomial [%v]",
+			err,
+		)
+	}
+	coefficientsB, err := generatePolynomial(polynomialDegree)
+	if err != nil {
+		return nil, nil, fmt.Errorf(
+			"could not generate hiding polynomial [%v]",
+			err,
+		)
+	}
+
+	if cm.secrets != nil {
+		cm.secrets.coefficientsA = coefficientsA
+		cm.secrets.coefficientsB = coefficientsB
+	}
+
+	return coefficientsA, coefficientsB, nil
+}
+
 // calculateCommitment generates a Pedersen commitment to a secret value
 // `secret` with a blinding factor `t`.
 func (cm *CommittingMember) calculateCommitment(
@@ -315,6 +361,12 @@ func (cvm *CommitmentsVerifyingMember) VerifyReceivedSharesAndCommitmentsMessage
 	commitmentsMessages []*MemberCommitmentsMessage,
 ) (*SecretSharesAccusationsMessage, error) {
 	for _, sharesMessage := range sharesMessages {
+		if sharesMessage.unicast {
+			// Shares received over a unicast channel are not known to other
+			// group members so they can not be used as an evidence.
+			continue
+		}
+
 		err := cvm.evidenceLog.PutPeerSharesMessage(sharesMessage)
 		if err != nil {
 			logger.Errorf(
@@ -334,6 +386,7 @@ func (cvm *CommitmentsVerifyingMember) VerifyReceivedSharesAndCommitmentsMessage
 				commitmentsMessage.senderID,
 			)
 			cvm.group.MarkMemberAsDisqualified(commitmentsMessage.senderID)
+			cvm.misbehaviorReporter.report(commitmentsMessage.senderID)
 			continue
 		}
 
@@ -354,6 +407,7 @@ func (cvm *CommitmentsVerifyingMember) VerifyReceivedSharesAndCommitmentsMessage
 						sharesMessage.senderID,
 					)
 					cvm.group.MarkMemberAsDisqualified(sharesMessage.senderID)
+					cvm.misbehaviorReporter.report(sharesMessage.senderID)
 					break
 				}
 
@@ -459,9 +513,25 @@ func (cvm *CommitmentsVerifyingMember) isValidMemberCommitmentsMessage(
 
 // isValidPeerSharesMessage validates a given PeerSharesMessage.
 // Message is considered valid if it contains shares for all other group members.
+// Message received over a unicast channel is considered valid if it contains
+// shares for the current member.
 func (cvm *CommitmentsVerifyingMember) isValidPeerSharesMessage(
 	message *PeerSharesMessage,
 ) bool {
+	if message.unicast {
+		if _, ok := message.shares[cvm.ID]; !ok {
+			logger.Warningf(
+				"[member:%v] unicast peer shares message from member [%v] "+
+					"does not contain shares for the member",
+				cvm.ID,
+				message.senderID,
+			)
+			return false
+		}
+
+		return true
+	}
+
 	for _, memberID := range cvm.group.OperatingMemberIDs() {
 		if memberID == message.senderID {
 			// Message contains shares only for other group members.
@@ -853,6 +923,7 @@ func (sm *SharingMember) VerifyPublicKeySharePoints(
 				message.senderID,
 			)
 			sm.group.MarkMemberAsDisqualified(message.senderID)
+			sm.misbehaviorReporter.report(message.senderID)
 			continue
 		}
 
@@ -1253,6 +1324,7 @@ func (rm *ReconstructingMember) ReconstructMisbehavedIndividualKeys(
 				message.senderID,
 			)
 			rm.group.MarkMemberAsDisqualified(message.senderID)
+			rm.misbehaviorReporter.report(message.senderID)
 		}
 	}
 
@@ -361,7 +361,7 @@ func TestResolvePublicKeySharePointsAccusationsMessages(t *testing.T) {
 			shares := make(map[group.MemberIndex]*peerShares)
 			shares[test.accuserID] = &peerShares{encryptedShareS, encryptedShareT}
 			justifyingMember.evidenceLog.PutPeerSharesMessage(
-				&PeerSharesMessage{test.accusedID, shares},
+				&PeerSharesMessage{senderID: test.accusedID, shares: shares},
 			)
 
 			if test.modifyEvidenceLog != nil {
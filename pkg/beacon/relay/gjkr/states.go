@@ -2,10 +2,13 @@ package gjkr
 
 import (
 	"context"
+	"sort"
 
 	"github.com/keep-network/keep-core/pkg/beacon/relay/group"
 	"github.com/keep-network/keep-core/pkg/beacon/relay/state"
+	"github.com/keep-network/keep-core/pkg/journal"
 	"github.com/keep-network/keep-core/pkg/net"
+	"github.com/keep-network/keep-core/pkg/net/ephemeral"
 )
 
 type keyGenerationState = state.State
@@ -42,8 +45,10 @@ const (
 //
 // State covers phase 1 of the protocol.
 type ephemeralKeyPairGenerationState struct {
-	channel net.BroadcastChannel
-	member  *EphemeralKeyPairGeneratingMember
+	channel       net.BroadcastChannel
+	member        *EphemeralKeyPairGeneratingMember
+	unicastShares *unicastShares
+	journal       *journal.Journal
 
 	phaseMessages []*EphemeralPublicKeyMessage
 }
@@ -72,9 +77,17 @@ func (ekpgs *ephemeralKeyPairGenerationState) Receive(msg net.Message) error {
 	switch phaseMessage := msg.Payload().(type) {
 	case *EphemeralPublicKeyMessage:
 		if !group.IsMessageFromSelf(ekpgs.member.ID, phaseMessage) &&
-			group.IsSenderValid(ekpgs.member, phaseMessage, msg.SenderPublicKey()) &&
+			group.IsSenderValidOrReport(ekpgs.member, phaseMessage, ekpgs.channel, msg) &&
 			group.IsSenderAccepted(ekpgs.member, phaseMessage) {
+			ekpgs.member.misbehaviorReporter.recordSender(
+				phaseMessage.senderID,
+				msg,
+			)
 			ekpgs.phaseMessages = append(ekpgs.phaseMessages, phaseMessage)
+			ekpgs.unicastShares.recordTransportID(
+				phaseMessage.senderID,
+				msg.TransportSenderID(),
+			)
 		}
 	}
 
@@ -85,6 +98,8 @@ func (ekpgs *ephemeralKeyPairGenerationState) Next() keyGenerationState {
 	return &symmetricKeyGenerationState{
 		channel:               ekpgs.channel,
 		member:                ekpgs.member.InitializeSymmetricKeyGeneration(),
+		unicastShares:         ekpgs.unicastShares,
+		journal:               ekpgs.journal,
 		previousPhaseMessages: ekpgs.phaseMessages,
 	}
 }
@@ -99,8 +114,10 @@ func (ekpgs *ephemeralKeyPairGenerationState) MemberIndex() group.MemberIndex {
 //
 // State covers phase 2 of the protocol.
 type symmetricKeyGenerationState struct {
-	channel net.BroadcastChannel
-	member  *SymmetricKeyGeneratingMember
+	channel       net.BroadcastChannel
+	member        *SymmetricKeyGeneratingMember
+	unicastShares *unicastShares
+	journal       *journal.Journal
 
 	previousPhaseMessages []*EphemeralPublicKeyMessage
 }
@@ -124,8 +141,10 @@ func (skgs *symmetricKeyGenerationState) Receive(msg net.Message) error {
 
 func (skgs *symmetricKeyGenerationState) Next() keyGenerationState {
 	return &commitmentState{
-		channel: skgs.channel,
-		member:  skgs.member.InitializeCommitting(),
+		channel:       skgs.channel,
+		member:        skgs.member.InitializeCommitting(),
+		unicastShares: skgs.unicastShares,
+		journal:       skgs.journal,
 	}
 }
 
@@ -138,10 +157,16 @@ func (skgs *symmetricKeyGenerationState) MemberIndex() group.MemberIndex {
 // - `PeerSharesMessage`
 // - `MemberCommitmentsMessage`
 //
+// Shares are broadcast and also delivered to their receivers over unicast
+// channels. Shares received over unicast channels are used only if the
+// broadcast message of the sender has not been received.
+//
 // State covers phase 3 of the protocol.
 type commitmentState struct {
-	channel net.BroadcastChannel
-	member  *CommittingMember
+	channel       net.BroadcastChannel
+	member        *CommittingMember
+	unicastShares *unicastShares
+	journal       *journal.Journal
 
 	phaseSharesMessages      []*PeerSharesMessage
 	phaseCommitmentsMessages []*MemberCommitmentsMessage
@@ -169,6 +194,8 @@ func (cs *commitmentState) Initiate(ctx context.Context) error {
 		return err
 	}
 
+	cs.unicastShares.deliver(sharesMsg)
+
 	return nil
 }
 
@@ -176,15 +203,23 @@ func (cs *commitmentState) Receive(msg net.Message) error {
 	switch phaseMessage := msg.Payload().(type) {
 	case *PeerSharesMessage:
 		if !group.IsMessageFromSelf(cs.member.ID, phaseMessage) &&
-			group.IsSenderValid(cs.member, phaseMessage, msg.SenderPublicKey()) &&
+			group.IsSenderValidOrReport(cs.member, phaseMessage, cs.channel, msg) &&
 			group.IsSenderAccepted(cs.member, phaseMessage) {
+			cs.member.misbehaviorReporter.recordSender(
+				phaseMessage.senderID,
+				msg,
+			)
 			cs.phaseSharesMessages = append(cs.phaseSharesMessages, phaseMessage)
 		}
 
 	case *MemberCommitmentsMessage:
 		if !group.IsMessageFromSelf(cs.member.ID, phaseMessage) &&
-			group.IsSenderValid(cs.member, phaseMessage, msg.SenderPublicKey()) &&
+			group.IsSenderValidOrReport(cs.member, phaseMessage, cs.channel, msg) &&
 			group.IsSenderAccepted(cs.member, phaseMessage) {
+			cs.member.misbehaviorReporter.recordSender(
+				phaseMessage.senderID,
+				msg,
+			)
 			cs.phaseCommitmentsMessages = append(
 				cs.phaseCommitmentsMessages,
 				phaseMessage,
@@ -196,11 +231,19 @@ func (cs *commitmentState) Receive(msg net.Message) error {
 }
 
 func (cs *commitmentState) Next() keyGenerationState {
+	sharesMessages := cs.phaseSharesMessages
+	for _, message := range cs.unicastShares.fallbackMessages(sharesMessages) {
+		if group.IsSenderAccepted(cs.member, message) {
+			sharesMessages = append(sharesMessages, message)
+		}
+	}
+
 	return &commitmentsVerificationState{
 		channel: cs.channel,
 		member:  cs.member.InitializeCommitmentsVerification(),
+		journal: cs.journal,
 
-		previousPhaseSharesMessages:      cs.phaseSharesMessages,
+		previousPhaseSharesMessages:      sharesMessages,
 		previousPhaseCommitmentsMessages: cs.phaseCommitmentsMessages,
 	}
 }
@@ -217,6 +260,7 @@ func (cs *commitmentState) MemberIndex() group.MemberIndex {
 type commitmentsVerificationState struct {
 	channel net.BroadcastChannel
 	member  *CommitmentsVerifyingMember
+	journal *journal.Journal
 
 	previousPhaseSharesMessages      []*PeerSharesMessage
 	previousPhaseCommitmentsMessages []*MemberCommitmentsMessage
@@ -249,6 +293,14 @@ func (cvs *commitmentsVerificationState) Initiate(ctx context.Context) error {
 		return err
 	}
 
+	journalAccusation(
+		cvs.journal,
+		cvs.channel,
+		accusationsMsg.senderID,
+		accusationsMsg.accusedMembersKeys,
+		secretSharesAccusation,
+	)
+
 	return nil
 }
 
@@ -256,8 +308,12 @@ func (cvs *commitmentsVerificationState) Receive(msg net.Message) error {
 	switch phaseMessage := msg.Payload().(type) {
 	case *SecretSharesAccusationsMessage:
 		if !group.IsMessageFromSelf(cvs.member.ID, phaseMessage) &&
-			group.IsSenderValid(cvs.member, phaseMessage, msg.SenderPublicKey()) &&
+			group.IsSenderValidOrReport(cvs.member, phaseMessage, cvs.channel, msg) &&
 			group.IsSenderAccepted(cvs.member, phaseMessage) {
+			cvs.member.misbehaviorReporter.recordSender(
+				phaseMessage.senderID,
+				msg,
+			)
 			cvs.phaseAccusationsMessages = append(
 				cvs.phaseAccusationsMessages,
 				phaseMessage,
@@ -272,6 +328,7 @@ func (cvs *commitmentsVerificationState) Next() keyGenerationState {
 	return &sharesJustificationState{
 		channel: cvs.channel,
 		member:  cvs.member.InitializeSharesJustification(),
+		journal: cvs.journal,
 
 		previousPhaseAccusationsMessages: cvs.phaseAccusationsMessages,
 	}
@@ -289,6 +346,7 @@ func (cvs *commitmentsVerificationState) MemberIndex() group.MemberIndex {
 type sharesJustificationState struct {
 	channel net.BroadcastChannel
 	member  *SharesJustifyingMember
+	journal *journal.Journal
 
 	previousPhaseAccusationsMessages []*SecretSharesAccusationsMessage
 }
@@ -302,6 +360,16 @@ func (sjs *sharesJustificationState) ActiveBlocks() uint64 {
 }
 
 func (sjs *sharesJustificationState) Initiate(ctx context.Context) error {
+	for _, message := range sjs.previousPhaseAccusationsMessages {
+		journalAccusation(
+			sjs.journal,
+			sjs.channel,
+			message.senderID,
+			message.accusedMembersKeys,
+			secretSharesAccusation,
+		)
+	}
+
 	sjs.member.MarkInactiveMembers(sjs.previousPhaseAccusationsMessages)
 
 	err := sjs.member.ResolveSecretSharesAccusationsMessages(
@@ -322,6 +390,7 @@ func (sjs *sharesJustificationState) Next() keyGenerationState {
 	return &qualificationState{
 		channel: sjs.channel,
 		member:  sjs.member.InitializeQualified(),
+		journal: sjs.journal,
 	}
 }
 
@@ -337,6 +406,7 @@ func (sjs *sharesJustificationState) MemberIndex() group.MemberIndex {
 type qualificationState struct {
 	channel net.BroadcastChannel
 	member  *QualifiedMember
+	journal *journal.Journal
 }
 
 func (qs *qualificationState) DelayBlocks() uint64 {
@@ -360,6 +430,7 @@ func (qs *qualificationState) Next() keyGenerationState {
 	return &pointsShareState{
 		channel: qs.channel,
 		member:  qs.member.InitializeSharing(),
+		journal: qs.journal,
 	}
 }
 
@@ -375,6 +446,7 @@ func (qs *qualificationState) MemberIndex() group.MemberIndex {
 type pointsShareState struct {
 	channel net.BroadcastChannel
 	member  *SharingMember // TODO: SharingMember should be renamed to PointsSharingMember
+	journal *journal.Journal
 
 	phaseMessages []*MemberPublicKeySharePointsMessage
 }
@@ -400,8 +472,12 @@ func (pss *pointsShareState) Receive(msg net.Message) error {
 	switch phaseMessage := msg.Payload().(type) {
 	case *MemberPublicKeySharePointsMessage:
 		if !group.IsMessageFromSelf(pss.member.ID, phaseMessage) &&
-			group.IsSenderValid(pss.member, phaseMessage, msg.SenderPublicKey()) &&
+			group.IsSenderValidOrReport(pss.member, phaseMessage, pss.channel, msg) &&
 			group.IsSenderAccepted(pss.member, phaseMessage) {
+			pss.member.misbehaviorReporter.recordSender(
+				phaseMessage.senderID,
+				msg,
+			)
 			pss.phaseMessages = append(pss.phaseMessages, phaseMessage)
 		}
 	}
@@ -413,6 +489,7 @@ func (pss *pointsShareState) Next() keyGenerationState {
 	return &pointsValidationState{
 		channel: pss.channel,
 		member:  pss.member,
+		journal: pss.journal,
 
 		previousPhaseMessages: pss.phaseMessages,
 	}
@@ -430,6 +507,7 @@ func (pss *pointsShareState) MemberIndex() group.MemberIndex {
 type pointsValidationState struct {
 	channel net.BroadcastChannel
 	member  *SharingMember // TODO: split validation logic into PointsValidatingMember
+	journal *journal.Journal
 
 	previousPhaseMessages []*MemberPublicKeySharePointsMessage
 
@@ -457,6 +535,14 @@ func (pvs *pointsValidationState) Initiate(ctx context.Context) error {
 		return err
 	}
 
+	journalAccusation(
+		pvs.journal,
+		pvs.channel,
+		accusationMsg.senderID,
+		accusationMsg.accusedMembersKeys,
+		publicKeySharePointsAccusation,
+	)
+
 	return nil
 }
 
@@ -464,8 +550,12 @@ func (pvs *pointsValidationState) Receive(msg net.Message) error {
 	switch phaseMessage := msg.Payload().(type) {
 	case *PointsAccusationsMessage:
 		if !group.IsMessageFromSelf(pvs.member.ID, phaseMessage) &&
-			group.IsSenderValid(pvs.member, phaseMessage, msg.SenderPublicKey()) &&
+			group.IsSenderValidOrReport(pvs.member, phaseMessage, pvs.channel, msg) &&
 			group.IsSenderAccepted(pvs.member, phaseMessage) {
+			pvs.member.misbehaviorReporter.recordSender(
+				phaseMessage.senderID,
+				msg,
+			)
 			pvs.phaseMessages = append(pvs.phaseMessages, phaseMessage)
 		}
 	}
@@ -477,6 +567,7 @@ func (pvs *pointsValidationState) Next() keyGenerationState {
 	return &pointsJustificationState{
 		channel: pvs.channel,
 		member:  pvs.member.InitializePointsJustification(),
+		journal: pvs.journal,
 
 		previousPhaseMessages: pvs.phaseMessages,
 	}
@@ -494,6 +585,7 @@ func (pvs *pointsValidationState) MemberIndex() group.MemberIndex {
 type pointsJustificationState struct {
 	channel net.BroadcastChannel
 	member  *PointsJustifyingMember
+	journal *journal.Journal
 
 	previousPhaseMessages []*PointsAccusationsMessage
 }
@@ -507,6 +599,16 @@ func (pjs *pointsJustificationState) ActiveBlocks() uint64 {
 }
 
 func (pjs *pointsJustificationState) Initiate(ctx context.Context) error {
+	for _, message := range pjs.previousPhaseMessages {
+		journalAccusation(
+			pjs.journal,
+			pjs.channel,
+			message.senderID,
+			message.accusedMembersKeys,
+			publicKeySharePointsAccusation,
+		)
+	}
+
 	pjs.member.MarkInactiveMembers(pjs.previousPhaseMessages)
 
 	err := pjs.member.ResolvePublicKeySharePointsAccusationsMessages(
@@ -571,8 +673,12 @@ func (rs *keyRevealState) Receive(msg net.Message) error {
 	switch phaseMessage := msg.Payload().(type) {
 	case *MisbehavedEphemeralKeysMessage:
 		if !group.IsMessageFromSelf(rs.member.ID, phaseMessage) &&
-			group.IsSenderValid(rs.member, phaseMessage, msg.SenderPublicKey()) &&
+			group.IsSenderValidOrReport(rs.member, phaseMessage, rs.channel, msg) &&
 			group.IsSenderAccepted(rs.member, phaseMessage) {
+			rs.member.misbehaviorReporter.recordSender(
+				phaseMessage.senderID,
+				msg,
+			)
 			rs.phaseMessages = append(rs.phaseMessages, phaseMessage)
 		}
 	}
@@ -714,3 +820,42 @@ func (fs *finalizationState) MemberIndex() group.MemberIndex {
 func (fs *finalizationState) result() *Result {
 	return fs.member.Result()
 }
+
+// Kinds of accusations recorded in the journal.
+const (
+	secretSharesAccusation         = "secret_shares"
+	publicKeySharePointsAccusation = "public_key_share_points"
+)
+
+// journalAccusation records in the journal the accusation made by the given
+// accuser, if the accuser accused anyone. The accusation is recorded once per
+// accuser, no matter if it has been made by a member hosted by the client or
+// received by any number of them. The broadcast channel used for DKG is named
+// after the group selection seed.
+func journalAccusation(
+	eventJournal *journal.Journal,
+	channel net.BroadcastChannel,
+	accuserID group.MemberIndex,
+	accusedMembersKeys map[group.MemberIndex]*ephemeral.PrivateKey,
+	kind string,
+) {
+	if !eventJournal.Enabled() || len(accusedMembersKeys) == 0 {
+		return
+	}
+
+	accusedMembers := make([]int, 0, len(accusedMembersKeys))
+	for accusedID := range accusedMembersKeys {
+		accusedMembers = append(accusedMembers, int(accusedID))
+	}
+	sort.Ints(accusedMembers)
+
+	eventJournal.Record(&journal.Entry{
+		Type:        journal.Accusation,
+		Seed:        channel.Name(),
+		MemberIndex: uint8(accuserID),
+		Phase:       kind,
+		Details: map[string]interface{}{
+			"accused": accusedMembers,
+		},
+	})
+}
@@ -0,0 +1,334 @@
+package gjkr
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/keep-network/keep-core/pkg/beacon/relay/group"
+	"github.com/keep-network/keep-core/pkg/beacon/relay/state"
+	"github.com/keep-network/keep-core/pkg/net"
+)
+
+// UnicastProvider opens unicast channels with other peers and notifies about
+// unicast channels opened by other peers. It is implemented by net.Provider.
+type UnicastProvider interface {
+	UnicastChannelWith(peerID net.TransportIdentifier) (net.UnicastChannel, error)
+	OnUnicastChannelOpened(handler func(channel net.UnicastChannel))
+}
+
+// SharesExchange delivers peer shares to other group members over unicast
+// channels and dispatches peer shares received over unicast channels to all
+// protocol executions in progress. Network providers may support only one
+// handler of opened unicast channels so a single exchange should be created
+// for the provider and shared by all protocol executions.
+type SharesExchange struct {
+	provider UnicastProvider
+
+	channelsMutex sync.Mutex
+	channels      map[net.UnicastChannel]bool
+
+	handlersMutex sync.Mutex
+	handlers      []*sharesHandler
+}
+
+type sharesHandler struct {
+	ctx    context.Context
+	handle func(message net.Message)
+}
+
+// NewSharesExchange creates a new shares exchange on top of the provided
+// unicast provider.
+func NewSharesExchange(provider UnicastProvider) *SharesExchange {
+	exchange := &SharesExchange{
+		provider: provider,
+		channels: make(map[net.UnicastChannel]bool),
+	}
+
+	provider.OnUnicastChannelOpened(exchange.attach)
+
+	return exchange
+}
+
+// attach registers peer shares unmarshaler and message handler in the given
+// channel unless they have been already registered.
+func (se *SharesExchange) attach(channel net.UnicastChannel) {
+	se.channelsMutex.Lock()
+	defer se.channelsMutex.Unlock()
+
+	if se.channels[channel] {
+		return
+	}
+	se.channels[channel] = true
+
+	channel.SetUnmarshaler(func() net.TaggedUnmarshaler {
+		return &PeerSharesMessage{}
+	})
+	channel.Recv(context.Background(), se.dispatch)
+}
+
+// open opens a unicast channel with the given peer and makes it ready for
+// receiving peer shares.
+func (se *SharesExchange) open(
+	peerID net.TransportIdentifier,
+) (net.UnicastChannel, error) {
+	channel, err := se.provider.UnicastChannelWith(peerID)
+	if err != nil {
+		return nil, err
+	}
+
+	se.attach(channel)
+
+	return channel, nil
+}
+
+// send delivers the message to the given peer over a unicast channel.
+func (se *SharesExchange) send(
+	peerID net.TransportIdentifier,
+	message *PeerSharesMessage,
+) error {
+	channel, err := se.open(peerID)
+	if err != nil {
+		return err
+	}
+
+	return channel.Send(message)
+}
+
+// onShares registers a handler called for each peer shares message received
+// over unicast channels until the provided context is done.
+func (se *SharesExchange) onShares(
+	ctx context.Context,
+	handle func(message net.Message),
+) {
+	se.handlersMutex.Lock()
+	defer se.handlersMutex.Unlock()
+
+	se.handlers = append(se.handlers, &sharesHandler{ctx, handle})
+}
+
+func (se *SharesExchange) dispatch(message net.Message) {
+	se.handlersMutex.Lock()
+	active := se.handlers[:0]
+	for _, handler := range se.handlers {
+		if handler.ctx.Err() == nil {
+			active = append(active, handler)
+		}
+	}
+	se.handlers = active
+
+	snapshot := make([]*sharesHandler, len(active))
+	copy(snapshot, active)
+	se.handlersMutex.Unlock()
+
+	for _, handler := range snapshot {
+		handler.handle(message)
+	}
+}
+
+// unicastShares delivers shares computed by the member to other group members
+// over unicast channels, each of them receiving only shares computed for them,
+// and collects shares sent to the member in the same way. Transport
+// identifiers of group members are learned from messages received in phase 1
+// of the protocol. Members operated by the same client share the transport
+// identifier and shares sent to them are dispatched locally by the network
+// provider.
+//
+// Shares are still broadcast to the entire group as all members need to have
+// access to shares exchanged between other members to resolve accusations and
+// to reconstruct keys of disqualified members. Shares received over unicast
+// channels serve as a fallback in case the member has not received the
+// broadcast message of the sender and they are never recorded in the evidence
+// log.
+//
+// No shares are exchanged while the protocol is replaying states completed
+// before the checkpoint it is resumed from was taken.
+type unicastShares struct {
+	exchange            *SharesExchange
+	replayChannel       *state.ReplayChannel
+	memberID            group.MemberIndex
+	membershipValidator group.MembershipValidator
+
+	mutex        sync.Mutex
+	transportIDs map[group.MemberIndex]net.TransportIdentifier
+	received     map[group.MemberIndex]*PeerSharesMessage
+}
+
+func newUnicastShares(
+	exchange *SharesExchange,
+	replayChannel *state.ReplayChannel,
+	memberID group.MemberIndex,
+	membershipValidator group.MembershipValidator,
+) *unicastShares {
+	return &unicastShares{
+		exchange:            exchange,
+		replayChannel:       replayChannel,
+		memberID:            memberID,
+		membershipValidator: membershipValidator,
+		transportIDs:        make(map[group.MemberIndex]net.TransportIdentifier),
+		received:            make(map[group.MemberIndex]*PeerSharesMessage),
+	}
+}
+
+// recordTransportID records the transport identifier of the network peer
+// operating the given group member. The unicast channel with the peer is
+// opened in the background so that both sides are ready to exchange shares
+// once they are computed. Messages replayed when the protocol is resumed
+// carry no transport identifier and are ignored.
+func (us *unicastShares) recordTransportID(
+	memberID group.MemberIndex,
+	transportID net.TransportIdentifier,
+) {
+	if transportID == nil {
+		return
+	}
+
+	us.mutex.Lock()
+	us.transportIDs[memberID] = transportID
+	us.mutex.Unlock()
+
+	if us.exchange == nil {
+		return
+	}
+
+	go func() {
+		if _, err := us.exchange.open(transportID); err != nil {
+			logger.Warningf(
+				"[member:%v] could not open unicast channel with member "+
+					"[%v]: [%v]",
+				us.memberID,
+				memberID,
+				err,
+			)
+		}
+	}()
+}
+
+// deliver sends shares from the message to their receivers over unicast
+// channels. Shares are sent asynchronously; receivers which could not be
+// reached rely on the broadcast message. Shares are not sent again when the
+// protocol is replaying completed states.
+func (us *unicastShares) deliver(message *PeerSharesMessage) {
+	if us.exchange == nil || us.replaying() {
+		return
+	}
+
+	us.mutex.Lock()
+	defer us.mutex.Unlock()
+
+	for receiverID, shares := range message.shares {
+		transportID, ok := us.transportIDs[receiverID]
+		if !ok {
+			logger.Debugf(
+				"[member:%v] transport identifier of member [%v] unknown; "+
+					"shares delivered only in broadcast message",
+				us.memberID,
+				receiverID,
+			)
+			continue
+		}
+
+		pair := newPeerSharesMessage(message.senderID)
+		pair.shares[receiverID] = shares
+
+		go func(receiverID group.MemberIndex) {
+			if err := us.exchange.send(transportID, pair); err != nil {
+				logger.Warningf(
+					"[member:%v] could not deliver shares to member [%v] "+
+						"over unicast channel; shares delivered only in "+
+						"broadcast message: [%v]",
+					us.memberID,
+					receiverID,
+					err,
+				)
+			}
+		}(receiverID)
+	}
+}
+
+func (us *unicastShares) replaying() bool {
+	return us.replayChannel != nil && us.replayChannel.Replaying()
+}
+
+// receive collects shares sent to the member over a unicast channel. Only
+// the first message with shares for the member received from the transport
+// identifier recorded for the sender is kept.
+func (us *unicastShares) receive(msg net.Message) {
+	message, ok := msg.Payload().(*PeerSharesMessage)
+	if !ok {
+		return
+	}
+
+	if group.IsMessageFromSelf(us.memberID, message) ||
+		len(message.shares) != 1 ||
+		message.shares[us.memberID] == nil {
+		return
+	}
+
+	if !us.membershipValidator.IsValidMembership(
+		message.senderID,
+		msg.SenderPublicKey(),
+	) {
+		logger.Warningf(
+			"[member:%v] rejecting unicast shares from [%v] with invalid "+
+				"sender member index [%v]",
+			us.memberID,
+			msg.TransportSenderID(),
+			message.senderID,
+		)
+		return
+	}
+
+	us.mutex.Lock()
+	defer us.mutex.Unlock()
+
+	transportID, ok := us.transportIDs[message.senderID]
+	if !ok || transportID.String() != msg.TransportSenderID().String() {
+		return
+	}
+
+	// The message may be dispatched to other protocol executions as well so
+	// it is copied before being marked.
+	if _, ok := us.received[message.senderID]; !ok {
+		us.received[message.senderID] = &PeerSharesMessage{
+			senderID: message.senderID,
+			shares:   message.shares,
+			unicast:  true,
+		}
+	}
+}
+
+// fallbackMessages returns shares received over unicast channels from
+// senders for whom no broadcast message is in the provided list.
+func (us *unicastShares) fallbackMessages(
+	broadcastMessages []*PeerSharesMessage,
+) []*PeerSharesMessage {
+	us.mutex.Lock()
+	defer us.mutex.Unlock()
+
+	broadcastSenders := make(map[group.MemberIndex]bool)
+	for _, message := range broadcastMessages {
+		broadcastSenders[message.senderID] = true
+	}
+
+	fallback := make([]*PeerSharesMessage, 0)
+	for senderID, message := range us.received {
+		if broadcastSenders[senderID] {
+			continue
+		}
+
+		logger.Infof(
+			"[member:%v] using shares received over unicast channel from "+
+				"member [%v]; broadcast message not received",
+			us.memberID,
+			senderID,
+		)
+		fallback = append(fallback, message)
+	}
+
+	sort.Slice(fallback, func(i, j int) bool {
+		return fallback[i].senderID < fallback[j].senderID
+	})
+
+	return fallback
+}
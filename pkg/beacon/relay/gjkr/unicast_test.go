@@ -0,0 +1,385 @@
+package gjkr
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/keep-network/keep-core/pkg/beacon/relay/group"
+	"github.com/keep-network/keep-core/pkg/beacon/relay/state"
+	chainLocal "github.com/keep-network/keep-core/pkg/chain/local"
+	"github.com/keep-network/keep-core/pkg/net"
+	netLocal "github.com/keep-network/keep-core/pkg/net/local"
+)
+
+func TestUnicastSharesDelivery(t *testing.T) {
+	senderProvider := netLocal.Connect()
+	receiverProvider := netLocal.Connect()
+
+	senderShares := newUnicastShares(
+		NewSharesExchange(senderProvider),
+		nil,
+		1,
+		&mockMembershipValidator{},
+	)
+	receiverShares := newUnicastShares(
+		NewSharesExchange(receiverProvider),
+		nil,
+		2,
+		&mockMembershipValidator{},
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	receiverShares.exchange.onShares(ctx, receiverShares.receive)
+
+	senderShares.recordTransportID(2, receiverProvider.ID())
+	receiverShares.recordTransportID(1, senderProvider.ID())
+
+	// Channels are opened in the background.
+	time.Sleep(100 * time.Millisecond)
+
+	sharesMessage := newPeerSharesMessage(1)
+	sharesMessage.shares[2] = &peerShares{[]byte{0x02}, []byte{0x12}}
+	sharesMessage.shares[3] = &peerShares{[]byte{0x03}, []byte{0x13}}
+
+	senderShares.deliver(sharesMessage)
+
+	time.Sleep(200 * time.Millisecond)
+
+	fallbackMessages := receiverShares.fallbackMessages(nil)
+	if len(fallbackMessages) != 1 {
+		t.Fatalf(
+			"unexpected number of messages\nexpected: [1]\nactual:   [%v]",
+			len(fallbackMessages),
+		)
+	}
+
+	expectedMessage := &PeerSharesMessage{
+		senderID: 1,
+		shares: map[group.MemberIndex]*peerShares{
+			2: {[]byte{0x02}, []byte{0x12}},
+		},
+		unicast: true,
+	}
+	if !reflect.DeepEqual(expectedMessage, fallbackMessages[0]) {
+		t.Errorf(
+			"unexpected message\nexpected: [%+v]\nactual:   [%+v]",
+			expectedMessage,
+			fallbackMessages[0],
+		)
+	}
+
+	if len(receiverShares.fallbackMessages(
+		[]*PeerSharesMessage{sharesMessage},
+	)) != 0 {
+		t.Errorf("expected broadcast message to take precedence")
+	}
+}
+
+func TestUnicastSharesDeliveryToMemberOfTheSamePeer(t *testing.T) {
+	// Both members are operated by the same client, e.g. as virtual stakers
+	// of the same operator, so they share the network peer and the exchange.
+	provider := netLocal.Connect()
+	exchange := NewSharesExchange(provider)
+
+	senderShares := newUnicastShares(
+		exchange,
+		nil,
+		1,
+		&mockMembershipValidator{},
+	)
+	receiverShares := newUnicastShares(
+		exchange,
+		nil,
+		2,
+		&mockMembershipValidator{},
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	receiverShares.exchange.onShares(ctx, receiverShares.receive)
+
+	senderShares.recordTransportID(2, provider.ID())
+	receiverShares.recordTransportID(1, provider.ID())
+
+	// Channels are opened in the background.
+	time.Sleep(100 * time.Millisecond)
+
+	sharesMessage := newPeerSharesMessage(1)
+	sharesMessage.shares[2] = &peerShares{[]byte{0x02}, []byte{0x12}}
+
+	senderShares.deliver(sharesMessage)
+
+	time.Sleep(200 * time.Millisecond)
+
+	fallbackMessages := receiverShares.fallbackMessages(nil)
+	if len(fallbackMessages) != 1 {
+		t.Fatalf(
+			"unexpected number of messages\nexpected: [1]\nactual:   [%v]",
+			len(fallbackMessages),
+		)
+	}
+
+	expectedMessage := &PeerSharesMessage{
+		senderID: 1,
+		shares: map[group.MemberIndex]*peerShares{
+			2: {[]byte{0x02}, []byte{0x12}},
+		},
+		unicast: true,
+	}
+	if !reflect.DeepEqual(expectedMessage, fallbackMessages[0]) {
+		t.Errorf(
+			"unexpected message\nexpected: [%+v]\nactual:   [%+v]",
+			expectedMessage,
+			fallbackMessages[0],
+		)
+	}
+}
+
+func TestUnicastSharesReceive(t *testing.T) {
+	senderProvider := netLocal.Connect()
+	otherProvider := netLocal.Connect()
+
+	newPair := func(senderID, receiverID group.MemberIndex) *PeerSharesMessage {
+		message := newPeerSharesMessage(senderID)
+		message.shares[receiverID] = &peerShares{[]byte{0x01}, []byte{0x02}}
+		return message
+	}
+
+	var tests = map[string]struct {
+		message          *PeerSharesMessage
+		transportID      string
+		validMembership  bool
+		expectedReceived bool
+	}{
+		"valid shares": {
+			message:          newPair(1, 2),
+			transportID:      senderProvider.ID().String(),
+			validMembership:  true,
+			expectedReceived: true,
+		},
+		"shares for another member": {
+			message:          newPair(1, 3),
+			transportID:      senderProvider.ID().String(),
+			validMembership:  true,
+			expectedReceived: false,
+		},
+		"shares from unexpected transport": {
+			message:          newPair(1, 2),
+			transportID:      otherProvider.ID().String(),
+			validMembership:  true,
+			expectedReceived: false,
+		},
+		"invalid sender membership": {
+			message:          newPair(1, 2),
+			transportID:      senderProvider.ID().String(),
+			validMembership:  false,
+			expectedReceived: false,
+		},
+		"shares for more members": {
+			message: func() *PeerSharesMessage {
+				message := newPair(1, 2)
+				message.shares[3] = &peerShares{[]byte{0x03}, []byte{0x04}}
+				return message
+			}(),
+			transportID:      senderProvider.ID().String(),
+			validMembership:  true,
+			expectedReceived: false,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			unicastShares := newUnicastShares(
+				nil,
+				nil,
+				2,
+				&mockMembershipValidator{invalid: !test.validMembership},
+			)
+			unicastShares.recordTransportID(1, senderProvider.ID())
+
+			unicastShares.receive(&mockUnicastMessage{
+				payload:     test.message,
+				transportID: test.transportID,
+			})
+
+			received := len(unicastShares.fallbackMessages(nil)) == 1
+			if received != test.expectedReceived {
+				t.Errorf(
+					"unexpected result\nexpected: [%v]\nactual:   [%v]",
+					test.expectedReceived,
+					received,
+				)
+			}
+		})
+	}
+}
+
+func TestUnicastSharesNotExchangedDuringReplay(t *testing.T) {
+	groupSize := 3
+	dishonestThreshold := 1
+	seed := big.NewInt(18313131145)
+
+	members := initializeEphemeralKeyPairMembersGroup(dishonestThreshold, groupSize)
+
+	var recordedMessages []*state.RecordedMessage
+	for _, member := range members[1:] {
+		message, err := member.GenerateEphemeralKeyPair()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		payload, err := message.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		recordedMessages = append(recordedMessages, &state.RecordedMessage{
+			StateIndex:      0,
+			Type:            message.Type(),
+			SenderPublicKey: []byte{},
+			Payload:         payload,
+		})
+	}
+
+	chain := chainLocal.Connect(groupSize, dishonestThreshold+1, big.NewInt(200))
+	blockCounter, err := chain.BlockCounter()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	currentBlock, err := blockCounter.CurrentBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	channel, err := netLocal.Connect().BroadcastChannelFor(
+		"unicast-shares-replay-test",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	RegisterUnmarshallers(channel)
+
+	provider := &recordingUnicastProvider{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// The execution is resumed after the states of phases 1 to 3 completed
+	// and is aborted by the context.
+	_, _, _ = Execute(
+		ctx,
+		1,
+		groupSize,
+		blockCounter,
+		channel,
+		NewSharesExchange(provider),
+		dishonestThreshold,
+		seed,
+		&mockMembershipValidator{},
+		0,
+		nil,
+		nil,
+		&Checkpoint{
+			Machine: &state.Checkpoint{
+				CompletedStates: 3,
+				EndBlockHeight:  currentBlock,
+				Messages:        recordedMessages,
+			},
+		},
+		nil,
+	)
+
+	// Channels would be opened in the background.
+	time.Sleep(100 * time.Millisecond)
+
+	if openedChannels := provider.openedChannels(); openedChannels != 0 {
+		t.Errorf(
+			"unexpected number of opened unicast channels\n"+
+				"expected: [0]\nactual:   [%v]",
+			openedChannels,
+		)
+	}
+}
+
+type recordingUnicastProvider struct {
+	mutex  sync.Mutex
+	opened int
+}
+
+func (rup *recordingUnicastProvider) UnicastChannelWith(
+	peerID net.TransportIdentifier,
+) (net.UnicastChannel, error) {
+	rup.mutex.Lock()
+	defer rup.mutex.Unlock()
+
+	rup.opened++
+
+	return netLocal.Connect().UnicastChannelWith(peerID)
+}
+
+func (rup *recordingUnicastProvider) OnUnicastChannelOpened(
+	handler func(channel net.UnicastChannel),
+) {
+}
+
+func (rup *recordingUnicastProvider) openedChannels() int {
+	rup.mutex.Lock()
+	defer rup.mutex.Unlock()
+
+	return rup.opened
+}
+
+type mockMembershipValidator struct {
+	invalid bool
+}
+
+func (mmv *mockMembershipValidator) IsInGroup(
+	publicKey *ecdsa.PublicKey,
+) bool {
+	return !mmv.invalid
+}
+
+func (mmv *mockMembershipValidator) IsValidMembership(
+	memberID group.MemberIndex,
+	publicKey []byte,
+) bool {
+	return !mmv.invalid
+}
+
+type mockUnicastMessage struct {
+	payload     interface{}
+	transportID string
+}
+
+type mockTransportIdentifier string
+
+func (mti mockTransportIdentifier) String() string {
+	return string(mti)
+}
+
+func (mum *mockUnicastMessage) TransportSenderID() net.TransportIdentifier {
+	return mockTransportIdentifier(mum.transportID)
+}
+
+func (mum *mockUnicastMessage) SenderPublicKey() []byte {
+	return []byte{}
+}
+
+func (mum *mockUnicastMessage) Payload() interface{} {
+	return mum.payload
+}
+
+func (mum *mockUnicastMessage) Type() string {
+	return "gjkr/peer_shares"
+}
+
+func (mum *mockUnicastMessage) Seqno() uint64 {
+	return 0
+}
@@ -22,6 +22,11 @@ type MembershipValidator interface {
 // the group. It is also used to confirm the position in the group of
 // a party that was selected. This is used to validate messages sent by that
 // party to all other group members.
+//
+// Public keys passed to the validator are operator public keys. Messages sent
+// by peers using a network key delegated by the operator carry the operator
+// public key resolved by the network layer through the delegation, so the
+// membership is always validated against the staker's on-chain identity.
 type StakersMembershipValidator struct {
 	members map[string][]int // staker address -> staker positions in group
 	signing chain.Signing
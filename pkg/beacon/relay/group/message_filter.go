@@ -2,6 +2,7 @@ package group
 
 import (
 	"github.com/ipfs/go-log"
+	"github.com/keep-network/keep-core/pkg/net"
 )
 
 var logger = log.Logger("keep-message-filter")
@@ -108,6 +109,30 @@ func IsSenderValid(
 	return filter.IsSenderValid(message.SenderID(), senderPublicKey)
 }
 
+// IsSenderValidOrReport checks if sender of the provided ProtocolMessage is
+// in the group and uses appropriate group member index, just like
+// IsSenderValid. If the sender is not valid, the peer which sent the message
+// is reported to the network layer as misbehaving.
+func IsSenderValidOrReport(
+	filter MessageFiltering,
+	message ProtocolMessage,
+	channel net.BroadcastChannel,
+	msg net.Message,
+) bool {
+	if IsSenderValid(filter, message, msg.SenderPublicKey()) {
+		return true
+	}
+
+	logger.Warningf(
+		"rejecting message from [%v] with invalid sender member index [%v]",
+		msg.TransportSenderID(),
+		message.SenderID(),
+	)
+	net.ReportMisbehavior(channel, msg)
+
+	return false
+}
+
 // IsSenderAccepted determines if sender of the given ProtocoLMessage is
 // accepted by group (not marked as inactive or disqualified).
 func IsSenderAccepted(filter MessageFiltering, message ProtocolMessage) bool {
@@ -3,6 +3,8 @@ package group
 import (
 	"reflect"
 	"testing"
+
+	"github.com/keep-network/keep-core/pkg/net"
 )
 
 func TestFilterInactiveMembers(t *testing.T) {
@@ -63,3 +65,108 @@ func TestFilterInactiveMembers(t *testing.T) {
 		})
 	}
 }
+
+func TestIsSenderValidOrReport(t *testing.T) {
+	var tests = map[string]struct {
+		senderID         MemberIndex
+		expectedValid    bool
+		expectedReported []string
+	}{
+		"valid sender": {
+			senderID:         1,
+			expectedValid:    true,
+			expectedReported: nil,
+		},
+		"invalid sender": {
+			senderID:         2,
+			expectedValid:    false,
+			expectedReported: []string{"peer-1"},
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			filter := &mockMessageFiltering{validSenderID: 1}
+			channel := &mockReportingChannel{}
+			message := &mockNetMessage{transportSenderID: "peer-1"}
+
+			valid := IsSenderValidOrReport(
+				filter,
+				&mockProtocolMessage{senderID: test.senderID},
+				channel,
+				message,
+			)
+
+			if valid != test.expectedValid {
+				t.Errorf(
+					"unexpected validation result\nexpected: %v\nactual:   %v\n",
+					test.expectedValid,
+					valid,
+				)
+			}
+
+			if !reflect.DeepEqual(test.expectedReported, channel.reported) {
+				t.Errorf(
+					"unexpected reported peers\nexpected: %v\nactual:   %v\n",
+					test.expectedReported,
+					channel.reported,
+				)
+			}
+		})
+	}
+}
+
+type mockMessageFiltering struct {
+	validSenderID MemberIndex
+}
+
+func (mmf *mockMessageFiltering) IsSenderAccepted(senderID MemberIndex) bool {
+	return true
+}
+
+func (mmf *mockMessageFiltering) IsSenderValid(
+	senderID MemberIndex,
+	senderPublicKey []byte,
+) bool {
+	return senderID == mmf.validSenderID
+}
+
+type mockProtocolMessage struct {
+	senderID MemberIndex
+}
+
+func (mpm *mockProtocolMessage) SenderID() MemberIndex {
+	return mpm.senderID
+}
+
+type mockTransportIdentifier string
+
+func (mti mockTransportIdentifier) String() string {
+	return string(mti)
+}
+
+type mockNetMessage struct {
+	net.Message
+
+	transportSenderID mockTransportIdentifier
+}
+
+func (mnm *mockNetMessage) TransportSenderID() net.TransportIdentifier {
+	return mnm.transportSenderID
+}
+
+func (mnm *mockNetMessage) SenderPublicKey() []byte {
+	return []byte{}
+}
+
+type mockReportingChannel struct {
+	net.BroadcastChannel
+
+	reported []string
+}
+
+func (mrc *mockReportingChannel) ReportMisbehavior(
+	sender net.TransportIdentifier,
+) {
+	mrc.reported = append(mrc.reported, sender.String())
+}
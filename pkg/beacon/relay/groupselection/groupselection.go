@@ -4,6 +4,9 @@
 package groupselection
 
 import (
+	"bytes"
+	"context"
+	"encoding/hex"
 	"fmt"
 	"math/big"
 	"sort"
@@ -12,6 +15,8 @@ import (
 
 	relaychain "github.com/keep-network/keep-core/pkg/beacon/relay/chain"
 	"github.com/keep-network/keep-core/pkg/chain"
+	"github.com/keep-network/keep-core/pkg/journal"
+	"github.com/keep-network/keep-core/pkg/metrics"
 )
 
 var logger = log.Logger("keep-groupselection")
@@ -59,10 +64,21 @@ type Result struct {
 // After the last round, there is a 12 blocks mining lag allowing all
 // outstanding ticket submissions to have a higher chance of being
 // mined before the deadline.
+//
+// If the optimizer is enabled, candidate tickets whose expected reward does
+// not cover the cost of their submission are not submitted at all.
+//
+// Submitted tickets are recorded in the given journal and group selection
+// metrics are updated in the given metrics of the staker's operator. The group
+// selection is abandoned as soon as the provided context is done.
 func CandidateToNewGroup(
+	ctx context.Context,
 	relayChain relaychain.Interface,
 	blockCounter chain.BlockCounter,
 	chainConfig *relaychain.Config,
+	optimizerConfig OptimizerConfig,
+	eventJournal *journal.Journal,
+	protocolMetrics *metrics.Protocol,
 	staker chain.Staker,
 	newEntry *big.Int,
 	startBlockHeight uint64,
@@ -89,12 +105,18 @@ func CandidateToNewGroup(
 	}
 
 	logger.Infof("starting ticket submission with [%v] tickets", len(tickets))
+	protocolMetrics.TicketsGenerated.Add(float64(len(tickets)))
 
 	err = submitTickets(
+		ctx,
 		tickets,
 		relayChain,
 		blockCounter,
 		chainConfig,
+		optimizerConfig,
+		eventJournal,
+		protocolMetrics,
+		newEntry,
 		startBlockHeight,
 	)
 	if err != nil {
@@ -110,7 +132,12 @@ func CandidateToNewGroup(
 		return err
 	}
 
-	ticketSubmissionEndBlockHeight := <-ticketSubmissionTimeoutChannel
+	var ticketSubmissionEndBlockHeight uint64
+	select {
+	case ticketSubmissionEndBlockHeight = <-ticketSubmissionTimeoutChannel:
+	case <-ctx.Done():
+		return fmt.Errorf("group selection cancelled: [%v]", ctx.Err())
+	}
 
 	logger.Infof(
 		"ticket submission ended at block [%v]",
@@ -126,6 +153,15 @@ func CandidateToNewGroup(
 		)
 	}
 
+	outcome := metrics.GroupSelectionNotSelected
+	for _, selectedStaker := range selectedStakers {
+		if bytes.Equal(selectedStaker, staker.Address()) {
+			outcome = metrics.GroupSelectionSelected
+			break
+		}
+	}
+	protocolMetrics.GroupSelections.Inc(outcome)
+
 	go onGroupSelected(&Result{
 		SelectedStakers:        selectedStakers,
 		GroupSelectionEndBlock: ticketSubmissionEndBlockHeight,
@@ -135,10 +171,15 @@ func CandidateToNewGroup(
 }
 
 func submitTickets(
+	ctx context.Context,
 	tickets []*ticket,
 	relayChain relaychain.GroupSelectionInterface,
 	blockCounter chain.BlockCounter,
 	chainConfig *relaychain.Config,
+	optimizerConfig OptimizerConfig,
+	eventJournal *journal.Journal,
+	protocolMetrics *metrics.Protocol,
+	seed *big.Int,
 	startBlockHeight uint64,
 ) error {
 	rounds, err := calculateRoundsCount(chainConfig.TicketSubmissionTimeout)
@@ -146,6 +187,12 @@ func submitTickets(
 		return err
 	}
 
+	optimizer := newTicketOptimizer(
+		optimizerConfig,
+		chainConfig.GroupSize,
+	)
+	defer optimizer.report(protocolMetrics)
+
 	for roundIndex := uint64(0); roundIndex <= rounds; roundIndex++ {
 		roundStartDelay := roundIndex * roundDuration
 		roundStartBlock := startBlockHeight + roundStartDelay
@@ -159,11 +206,21 @@ func submitTickets(
 			roundLeadingZeros,
 		)
 
-		err := blockCounter.WaitForBlockHeight(roundStartBlock)
+		roundStartWaiter, err := blockCounter.BlockHeightWaiter(roundStartBlock)
 		if err != nil {
 			return err
 		}
 
+		select {
+		case <-roundStartWaiter:
+		case <-ctx.Done():
+			return fmt.Errorf(
+				"ticket submission cancelled before round [%v]: [%v]",
+				roundIndex,
+				ctx.Err(),
+			)
+		}
+
 		candidateTickets, err := roundCandidateTickets(
 			relayChain,
 			tickets,
@@ -175,6 +232,13 @@ func submitTickets(
 			return err
 		}
 
+		candidateTickets = optimizer.filter(
+			relayChain,
+			candidateTickets,
+			roundIndex,
+			roundLeadingZeros,
+		)
+
 		logger.Infof(
 			"ticket submission round [%v] submitting "+
 				"[%v] tickets",
@@ -182,7 +246,20 @@ func submitTickets(
 			len(candidateTickets),
 		)
 
-		submitTicketsOnChain(candidateTickets, relayChain)
+		for _, candidateTicket := range candidateTickets {
+			eventJournal.Record(&journal.Entry{
+				Type:        journal.TicketSubmitted,
+				BlockNumber: roundStartBlock,
+				Seed:        journal.SeedString(seed),
+				Details: map[string]interface{}{
+					"round":                roundIndex,
+					"value":                hex.EncodeToString(candidateTicket.value[:]),
+					"virtual_staker_index": candidateTicket.proof.virtualStakerIndex,
+				},
+			})
+		}
+
+		submitTicketsOnChain(candidateTickets, relayChain, protocolMetrics)
 	}
 
 	return nil
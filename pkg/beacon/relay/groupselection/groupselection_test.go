@@ -1,6 +1,7 @@
 package groupselection
 
 import (
+	"context"
 	"encoding/binary"
 	"math/big"
 	"reflect"
@@ -11,6 +12,7 @@ import (
 	"github.com/keep-network/keep-core/pkg/beacon/relay/event"
 	"github.com/keep-network/keep-core/pkg/chain/local"
 	"github.com/keep-network/keep-core/pkg/gen/async"
+	"github.com/keep-network/keep-core/pkg/metrics"
 	"github.com/keep-network/keep-core/pkg/subscription"
 )
 
@@ -73,11 +75,16 @@ func TestSubmitTickets(t *testing.T) {
 			}
 
 			err = submitTickets(
+				context.Background(),
 				test.tickets,
 				chain,
 				blockCounter,
 				chainConfig,
-				0, // start block height
+				OptimizerConfig{},
+				nil,
+				testProtocolMetrics(t),
+				big.NewInt(100), // seed
+				0,               // start block height
 			)
 			if err != nil {
 				t.Fatal(err)
@@ -316,8 +323,9 @@ func TestRoundCandidateTickets(t *testing.T) {
 }
 
 type stubGroupInterface struct {
-	groupSize        int
-	submittedTickets []*chain.Ticket
+	groupSize            int
+	submittedTickets     []*chain.Ticket
+	ticketSubmissionCost *big.Int
 }
 
 func (stg *stubGroupInterface) SubmitTicket(ticket *chain.Ticket) *async.EventGroupTicketSubmissionPromise {
@@ -356,6 +364,10 @@ func (stg *stubGroupInterface) GetSubmittedTickets() ([]uint64, error) {
 	return tickets, nil
 }
 
+func (stg *stubGroupInterface) TicketSubmissionCost() (*big.Int, error) {
+	return stg.ticketSubmissionCost, nil
+}
+
 func (stg *stubGroupInterface) GetSelectedParticipants() ([]chain.StakerAddress, error) {
 	selected := make([]chain.StakerAddress, stg.groupSize)
 	for i := 0; i < stg.groupSize; i++ {
@@ -370,3 +382,12 @@ func (stg *stubGroupInterface) OnGroupSelectionStarted(
 ) subscription.EventSubscription {
 	panic("not implemented")
 }
+
+func testProtocolMetrics(t *testing.T) *metrics.Protocol {
+	protocolMetrics, err := metrics.NewProtocol(metrics.NewRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return protocolMetrics.ForOperator("test")
+}
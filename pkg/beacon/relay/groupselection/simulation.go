@@ -0,0 +1,255 @@
+package groupselection
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+
+	relaychain "github.com/keep-network/keep-core/pkg/beacon/relay/chain"
+	"github.com/keep-network/keep-core/pkg/beacon/relay/event"
+	"github.com/keep-network/keep-core/pkg/gen/async"
+	"github.com/keep-network/keep-core/pkg/subscription"
+)
+
+// SimulatedStaker is a staker taking part in a simulated group selection.
+type SimulatedStaker struct {
+	Address []byte
+	Stake   *big.Int
+}
+
+// SimulatedMember is a virtual staker selected to the group in a simulated
+// group selection.
+type SimulatedMember struct {
+	Address            []byte
+	VirtualStakerIndex *big.Int
+	TicketValue        uint64
+}
+
+// SimulatedStakerResult summarizes a simulated group selection from the
+// perspective of a single staker.
+type SimulatedStakerResult struct {
+	Address []byte
+	// VirtualStakers is the number of virtual stakers of the staker, that
+	// is, the number of tickets generated by the staker.
+	VirtualStakers int
+	// SubmittedTickets holds the number of tickets submitted by the staker
+	// in each ticket submission round.
+	SubmittedTickets []int
+	// SelectedMembers is the number of virtual stakers of the staker selected
+	// to the group for the simulated seed.
+	SelectedMembers int
+	// ExpectedMembers is the number of virtual stakers of the staker
+	// selected to the group on average, regardless of the seed.
+	ExpectedMembers float64
+	// SelectionChance is the probability of at least one virtual staker of
+	// the staker being selected to the group, regardless of the seed.
+	SelectionChance float64
+}
+
+// SimulationResult is the result of a simulated group selection.
+type SimulationResult struct {
+	// Members of the group ordered by their ticket value.
+	Members []*SimulatedMember
+	// Stakers in the order they have been provided to the simulation.
+	Stakers []*SimulatedStakerResult
+}
+
+// Simulate predicts the outcome of the group selection for the given seed,
+// assuming all provided stakers generate their tickets and submit them
+// according to the protocol rounds, and no other stakers take part in the
+// selection. Tickets are not submitted anywhere; the chain state is simulated
+// in memory.
+func Simulate(
+	seed *big.Int,
+	stakers []*SimulatedStaker,
+	chainConfig *relaychain.Config,
+	minimumStake *big.Int,
+) (*SimulationResult, error) {
+	if minimumStake.Sign() <= 0 {
+		return nil, fmt.Errorf("minimum stake must be positive")
+	}
+
+	rounds, err := calculateRoundsCount(chainConfig.TicketSubmissionTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	stakerTickets := make([][]*ticket, len(stakers))
+	stakerResults := make([]*SimulatedStakerResult, len(stakers))
+	totalVirtualStakers := 0
+
+	for i, staker := range stakers {
+		tickets, err := generateTickets(
+			seed.Bytes(),
+			staker.Address,
+			staker.Stake,
+			minimumStake,
+		)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"could not generate tickets of staker [0x%x]: [%v]",
+				staker.Address,
+				err,
+			)
+		}
+
+		stakerTickets[i] = tickets
+		stakerResults[i] = &SimulatedStakerResult{
+			Address:          staker.Address,
+			VirtualStakers:   len(tickets),
+			SubmittedTickets: make([]int, rounds+1),
+		}
+		totalVirtualStakers += len(tickets)
+	}
+
+	chain := &simulatedChain{groupSize: chainConfig.GroupSize}
+
+	for roundIndex := uint64(0); roundIndex <= rounds; roundIndex++ {
+		roundLeadingZeros := rounds - roundIndex
+
+		// All stakers determine their candidate tickets against the tickets
+		// submitted in preceding rounds as tickets submitted in the same
+		// round are not mined yet.
+		roundTickets := make([]*simulatedTicket, 0)
+		for i, tickets := range stakerTickets {
+			candidateTickets, err := roundCandidateTickets(
+				chain,
+				tickets,
+				roundIndex,
+				roundLeadingZeros,
+				chainConfig.GroupSize,
+			)
+			if err != nil {
+				return nil, err
+			}
+
+			stakerResults[i].SubmittedTickets[roundIndex] = len(candidateTickets)
+			for _, candidateTicket := range candidateTickets {
+				roundTickets = append(
+					roundTickets,
+					&simulatedTicket{candidateTicket, i},
+				)
+			}
+		}
+
+		chain.submit(roundTickets)
+	}
+
+	members := make([]*SimulatedMember, len(chain.tickets))
+	for i, selectedTicket := range chain.tickets {
+		members[i] = &SimulatedMember{
+			Address:            stakers[selectedTicket.stakerIndex].Address,
+			VirtualStakerIndex: selectedTicket.proof.virtualStakerIndex,
+			TicketValue:        selectedTicket.intValue().Uint64(),
+		}
+		stakerResults[selectedTicket.stakerIndex].SelectedMembers++
+	}
+
+	for _, stakerResult := range stakerResults {
+		stakerResult.ExpectedMembers, stakerResult.SelectionChance =
+			selectionOdds(
+				stakerResult.VirtualStakers,
+				totalVirtualStakers,
+				chainConfig.GroupSize,
+			)
+	}
+
+	return &SimulationResult{
+		Members: members,
+		Stakers: stakerResults,
+	}, nil
+}
+
+// selectionOdds returns the expected number of virtual stakers of a staker
+// selected to the group and the probability of at least one of them being
+// selected. Ticket values are uniformly distributed so the lowest tickets
+// selected to the group are a uniformly random subset of all tickets.
+func selectionOdds(
+	virtualStakers int,
+	totalVirtualStakers int,
+	groupSize int,
+) (float64, float64) {
+	if virtualStakers == 0 {
+		return 0, 0
+	}
+
+	if totalVirtualStakers <= groupSize {
+		return float64(virtualStakers), 1
+	}
+
+	expectedMembers := float64(groupSize*virtualStakers) /
+		float64(totalVirtualStakers)
+
+	// The probability none of the tickets of the staker is selected, as in
+	// the hypergeometric distribution.
+	notSelected := 1.0
+	for i := 0; i < groupSize; i++ {
+		otherTickets := totalVirtualStakers - virtualStakers - i
+		if otherTickets <= 0 {
+			notSelected = 0
+			break
+		}
+
+		notSelected *= float64(otherTickets) / float64(totalVirtualStakers-i)
+	}
+
+	return expectedMembers, 1 - notSelected
+}
+
+type simulatedTicket struct {
+	*ticket
+	stakerIndex int
+}
+
+// simulatedChain keeps tickets submitted in a simulated group selection the
+// same way the chain does, that is, only the group size of the lowest tickets.
+type simulatedChain struct {
+	groupSize int
+	tickets   []*simulatedTicket
+}
+
+func (sc *simulatedChain) submit(tickets []*simulatedTicket) {
+	sc.tickets = append(sc.tickets, tickets...)
+
+	sort.SliceStable(sc.tickets, func(i, j int) bool {
+		return sc.tickets[i].intValue().Cmp(sc.tickets[j].intValue()) < 0
+	})
+
+	if len(sc.tickets) > sc.groupSize {
+		sc.tickets = sc.tickets[:sc.groupSize]
+	}
+}
+
+func (sc *simulatedChain) OnGroupSelectionStarted(
+	func(groupSelectionStarted *event.GroupSelectionStart),
+) subscription.EventSubscription {
+	return subscription.NewEventSubscription(func() {})
+}
+
+func (sc *simulatedChain) SubmitTicket(
+	ticket *relaychain.Ticket,
+) *async.EventGroupTicketSubmissionPromise {
+	promise := &async.EventGroupTicketSubmissionPromise{}
+	_ = promise.Fail(fmt.Errorf("tickets are not submitted in simulation"))
+	return promise
+}
+
+func (sc *simulatedChain) GetSubmittedTickets() ([]uint64, error) {
+	tickets := make([]uint64, len(sc.tickets))
+	for i, submittedTicket := range sc.tickets {
+		tickets[i] = submittedTicket.intValue().Uint64()
+	}
+
+	return tickets, nil
+}
+
+func (sc *simulatedChain) TicketSubmissionCost() (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+
+func (sc *simulatedChain) GetSelectedParticipants() (
+	[]relaychain.StakerAddress,
+	error,
+) {
+	return nil, fmt.Errorf("participants are not selected in simulation")
+}
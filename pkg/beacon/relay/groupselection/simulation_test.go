@@ -0,0 +1,182 @@
+package groupselection
+
+import (
+	"math"
+	"math/big"
+	"sort"
+	"testing"
+
+	"github.com/keep-network/keep-core/pkg/beacon/relay/chain"
+)
+
+func TestSimulateSelectsLowestTickets(t *testing.T) {
+	seed := big.NewInt(31337)
+	minimumStake := big.NewInt(10)
+
+	stakers := []*SimulatedStaker{
+		{Address: []byte{0x01}, Stake: big.NewInt(100)},
+		{Address: []byte{0x02}, Stake: big.NewInt(55)},
+		{Address: []byte{0x03}, Stake: big.NewInt(200)},
+		{Address: []byte{0x04}, Stake: big.NewInt(5)},
+	}
+
+	chainConfig := &chain.Config{
+		GroupSize:               8,
+		TicketSubmissionTimeout: 24,
+	}
+
+	result, err := Simulate(seed, stakers, chainConfig, minimumStake)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	allTickets := make([]uint64, 0)
+	for _, staker := range stakers {
+		tickets, err := generateTickets(
+			seed.Bytes(),
+			staker.Address,
+			staker.Stake,
+			minimumStake,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, ticket := range tickets {
+			allTickets = append(allTickets, ticket.intValue().Uint64())
+		}
+	}
+	sort.Slice(allTickets, func(i, j int) bool {
+		return allTickets[i] < allTickets[j]
+	})
+
+	if len(result.Members) != chainConfig.GroupSize {
+		t.Fatalf(
+			"unexpected number of members\nexpected: [%v]\nactual:   [%v]",
+			chainConfig.GroupSize,
+			len(result.Members),
+		)
+	}
+
+	for i, member := range result.Members {
+		if member.TicketValue != allTickets[i] {
+			t.Errorf(
+				"unexpected ticket of member [%v]\nexpected: [%v]\nactual:   [%v]",
+				i,
+				allTickets[i],
+				member.TicketValue,
+			)
+		}
+	}
+
+	expectedVirtualStakers := []int{10, 5, 20, 0}
+	selectedMembers := 0
+	for i, staker := range result.Stakers {
+		if staker.VirtualStakers != expectedVirtualStakers[i] {
+			t.Errorf(
+				"unexpected virtual stakers of staker [%v]\n"+
+					"expected: [%v]\nactual:   [%v]",
+				i,
+				expectedVirtualStakers[i],
+				staker.VirtualStakers,
+			)
+		}
+
+		submittedTickets := 0
+		for _, roundTickets := range staker.SubmittedTickets {
+			submittedTickets += roundTickets
+		}
+		if submittedTickets < staker.SelectedMembers {
+			t.Errorf(
+				"staker [%v] submitted [%v] tickets but has [%v] members",
+				i,
+				submittedTickets,
+				staker.SelectedMembers,
+			)
+		}
+
+		selectedMembers += staker.SelectedMembers
+	}
+
+	if selectedMembers != chainConfig.GroupSize {
+		t.Errorf(
+			"unexpected number of selected members\n"+
+				"expected: [%v]\nactual:   [%v]",
+			chainConfig.GroupSize,
+			selectedMembers,
+		)
+	}
+}
+
+func TestSelectionOdds(t *testing.T) {
+	var tests = map[string]struct {
+		virtualStakers          int
+		totalVirtualStakers     int
+		groupSize               int
+		expectedExpectedMembers float64
+		expectedSelectionChance float64
+	}{
+		"no virtual stakers": {
+			virtualStakers:          0,
+			totalVirtualStakers:     10,
+			groupSize:               2,
+			expectedExpectedMembers: 0,
+			expectedSelectionChance: 0,
+		},
+		"less virtual stakers than group size": {
+			virtualStakers:          2,
+			totalVirtualStakers:     3,
+			groupSize:               5,
+			expectedExpectedMembers: 2,
+			expectedSelectionChance: 1,
+		},
+		"too many virtual stakers to miss the group": {
+			virtualStakers:          8,
+			totalVirtualStakers:     10,
+			groupSize:               3,
+			expectedExpectedMembers: 2.4,
+			expectedSelectionChance: 1,
+		},
+		"single virtual staker": {
+			virtualStakers:          1,
+			totalVirtualStakers:     10,
+			groupSize:               2,
+			expectedExpectedMembers: 0.2,
+			expectedSelectionChance: 0.2,
+		},
+		"many virtual stakers": {
+			virtualStakers:          2,
+			totalVirtualStakers:     10,
+			groupSize:               2,
+			expectedExpectedMembers: 0.4,
+			// 1 - (8 / 10) * (7 / 9)
+			expectedSelectionChance: 1 - 56.0/90.0,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			expectedMembers, selectionChance := selectionOdds(
+				test.virtualStakers,
+				test.totalVirtualStakers,
+				test.groupSize,
+			)
+
+			if math.Abs(expectedMembers-test.expectedExpectedMembers) > 1e-9 {
+				t.Errorf(
+					"unexpected expected members\nexpected: [%v]\nactual:   [%v]",
+					test.expectedExpectedMembers,
+					expectedMembers,
+				)
+			}
+
+			if math.Abs(selectionChance-test.expectedSelectionChance) > 1e-9 {
+				t.Errorf(
+					"unexpected selection chance\nexpected: [%v]\nactual:   [%v]",
+					test.expectedSelectionChance,
+					selectionChance,
+				)
+			}
+		})
+	}
+}
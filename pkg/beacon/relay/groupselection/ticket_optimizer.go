@@ -0,0 +1,219 @@
+package groupselection
+
+import (
+	"math"
+	"math/big"
+
+	relaychain "github.com/keep-network/keep-core/pkg/beacon/relay/chain"
+	"github.com/keep-network/keep-core/pkg/metrics"
+)
+
+// OptimizerConfig configures the ticket submission optimizer. The optimizer
+// skips tickets which are unlikely to make it to the group given the tickets
+// submitted so far, so that their expected reward does not cover the cost of
+// their submission.
+type OptimizerConfig struct {
+	// ExpectedMemberReward is the reward in wei the operator expects for
+	// a single membership in a group. The optimizer is disabled if the
+	// reward is not set.
+	ExpectedMemberReward *big.Int
+}
+
+func (oc OptimizerConfig) enabled() bool {
+	return oc.ExpectedMemberReward != nil && oc.ExpectedMemberReward.Sign() > 0
+}
+
+// ticketOptimizer decides which candidate tickets of a single group
+// selection are worth submitting and keeps the expected value summary of
+// the selection.
+type ticketOptimizer struct {
+	config    OptimizerConfig
+	groupSize int
+
+	// submittedValues are values of tickets of the staker passed for
+	// submission by the optimizer.
+	submittedValues map[uint64]bool
+
+	submittedTickets int
+	skippedTickets   int
+	expectedReward   float64
+	expectedCost     *big.Int
+}
+
+func newTicketOptimizer(
+	config OptimizerConfig,
+	groupSize int,
+) *ticketOptimizer {
+	return &ticketOptimizer{
+		config:          config,
+		groupSize:       groupSize,
+		submittedValues: make(map[uint64]bool),
+		expectedCost:    big.NewInt(0),
+	}
+}
+
+// filter returns candidate tickets of the round whose expected reward
+// covers the cost of their submission. Candidate tickets have to be sorted
+// in ascending order by their value. As the probability of making it to the
+// group decreases with the ticket value, all tickets following the first
+// skipped one are skipped as well. If the cost of ticket submission could
+// not be determined, all candidate tickets are returned.
+func (to *ticketOptimizer) filter(
+	relayChain relaychain.GroupSelectionInterface,
+	candidateTickets []*ticket,
+	roundIndex uint64,
+	roundLeadingZeros uint64,
+) []*ticket {
+	if !to.config.enabled() || len(candidateTickets) == 0 {
+		return candidateTickets
+	}
+
+	ticketCost, err := relayChain.TicketSubmissionCost()
+	if err != nil {
+		logger.Warningf(
+			"could not determine ticket submission cost; "+
+				"submitting all candidate tickets: [%v]",
+			err,
+		)
+		return candidateTickets
+	}
+
+	submittedTickets, err := relayChain.GetSubmittedTickets()
+	if err != nil {
+		logger.Warningf(
+			"could not get submitted tickets; "+
+				"submitting all candidate tickets: [%v]",
+			err,
+		)
+		return candidateTickets
+	}
+
+	coveredValue := roundCoveredValue(roundIndex, roundLeadingZeros)
+	reward, _ := new(big.Float).SetInt(to.config.ExpectedMemberReward).Float64()
+	cost, _ := new(big.Float).SetInt(ticketCost).Float64()
+
+	for i, candidateTicket := range candidateTickets {
+		probability := to.selectionProbability(
+			candidateTicket.intValue().Uint64(),
+			submittedTickets,
+			i,
+			coveredValue,
+		)
+
+		if probability*reward < cost {
+			logger.Infof(
+				"skipping [%v] tickets of round [%v]; expected reward [%.0f] "+
+					"wei of the lowest one does not cover its cost [%v] wei",
+				len(candidateTickets)-i,
+				roundIndex,
+				probability*reward,
+				ticketCost,
+			)
+
+			to.skippedTickets += len(candidateTickets) - i
+			return candidateTickets[:i]
+		}
+
+		to.submittedValues[candidateTicket.intValue().Uint64()] = true
+		to.submittedTickets++
+		to.expectedReward += probability * reward
+		to.expectedCost.Add(to.expectedCost, ticketCost)
+	}
+
+	return candidateTickets
+}
+
+// selectionProbability estimates the probability of the ticket with the
+// given value making it to the group. All tickets with values lower than the
+// covered value should have been already submitted according to the
+// protocol, so the density of tickets of other stakers is estimated from
+// submitted tickets lower than the covered value. The number of tickets of
+// other stakers that are yet to be submitted below the given value is then
+// modelled with the Poisson distribution. Only tickets of the staker passed
+// for submission by the optimizer are excluded from the estimation; tickets
+// skipped by the optimizer have never been submitted. Tickets of the staker
+// lower than the given value submitted in the same round take group slots as
+// well.
+func (to *ticketOptimizer) selectionProbability(
+	value uint64,
+	submittedTickets []uint64,
+	lowerRoundTickets int,
+	coveredValue uint64,
+) float64 {
+	knownLowerTickets := lowerRoundTickets
+	coveredTickets := 0
+	for _, submittedTicket := range submittedTickets {
+		if submittedTicket < value {
+			knownLowerTickets++
+		}
+		if submittedTicket < coveredValue && !to.submittedValues[submittedTicket] {
+			coveredTickets++
+		}
+	}
+
+	freeSlots := to.groupSize - knownLowerTickets
+	if freeSlots <= 0 {
+		return 0
+	}
+
+	if coveredValue == 0 || value <= coveredValue {
+		return 1
+	}
+
+	if coveredTickets == 0 {
+		return 1
+	}
+
+	density := float64(coveredTickets) / float64(coveredValue)
+	mean := density * float64(value-coveredValue)
+
+	// Cumulative distribution function of the Poisson distribution: the
+	// probability that less than the number of free slots tickets of other
+	// stakers are submitted below the given value.
+	term := math.Exp(-mean)
+	probability := term
+	for k := 1; k < freeSlots; k++ {
+		term *= mean / float64(k)
+		probability += term
+	}
+
+	return math.Min(probability, 1)
+}
+
+// report logs the expected value summary of the group selection and updates
+// the optimizer metrics in the given metrics.
+func (to *ticketOptimizer) report(protocolMetrics *metrics.Protocol) {
+	if !to.config.enabled() {
+		return
+	}
+
+	expectedCost, _ := new(big.Float).SetInt(to.expectedCost).Float64()
+
+	logger.Infof(
+		"ticket submission optimizer submitted [%v] and skipped [%v] "+
+			"tickets; expected reward [%.0f] wei, expected cost [%v] wei, "+
+			"expected value [%.0f] wei",
+		to.submittedTickets,
+		to.skippedTickets,
+		to.expectedReward,
+		to.expectedCost,
+		to.expectedReward-expectedCost,
+	)
+
+	protocolMetrics.TicketsSkipped.Add(float64(to.skippedTickets))
+	protocolMetrics.TicketsExpectedRewardGwei.Add(to.expectedReward / 1e9)
+	protocolMetrics.TicketsExpectedCostGwei.Add(expectedCost / 1e9)
+}
+
+// roundCoveredValue returns the value below which all tickets should have
+// been submitted in rounds preceding the given one. Round 0 is the first
+// round so no tickets are covered.
+func roundCoveredValue(roundIndex uint64, roundLeadingZeros uint64) uint64 {
+	if roundIndex == 0 || roundLeadingZeros >= 64 {
+		return 0
+	}
+
+	// Tickets with more leading zeros than the ones of the round have been
+	// submitted in preceding rounds.
+	return uint64(1) << (63 - roundLeadingZeros)
+}
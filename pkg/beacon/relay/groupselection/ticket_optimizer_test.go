@@ -0,0 +1,252 @@
+package groupselection
+
+import (
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestRoundCoveredValue(t *testing.T) {
+	var tests = map[string]struct {
+		roundIndex           uint64
+		roundLeadingZeros    uint64
+		expectedCoveredValue uint64
+	}{
+		"first round": {
+			roundIndex:           0,
+			roundLeadingZeros:    3,
+			expectedCoveredValue: 0,
+		},
+		"second round": {
+			roundIndex:           1,
+			roundLeadingZeros:    2,
+			expectedCoveredValue: 1 << 61,
+		},
+		"last round": {
+			roundIndex:           3,
+			roundLeadingZeros:    0,
+			expectedCoveredValue: 1 << 63,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			coveredValue := roundCoveredValue(
+				test.roundIndex,
+				test.roundLeadingZeros,
+			)
+
+			if coveredValue != test.expectedCoveredValue {
+				t.Errorf(
+					"unexpected covered value\nexpected: [%v]\nactual:   [%v]",
+					test.expectedCoveredValue,
+					coveredValue,
+				)
+			}
+		})
+	}
+}
+
+func TestSelectionProbability(t *testing.T) {
+	otherTickets := []uint64{0, 100, 200, 300, 400, 500, 600, 700, 800, 900}
+
+	var tests = map[string]struct {
+		groupSize           int
+		stakerTickets       []uint64
+		value               uint64
+		submittedTickets    []uint64
+		lowerRoundTickets   int
+		coveredValue        uint64
+		expectedProbability float64
+	}{
+		"no free slots": {
+			groupSize:           10,
+			value:               1100,
+			submittedTickets:    otherTickets,
+			coveredValue:        1000,
+			expectedProbability: 0,
+		},
+		"no free slots left by lower tickets of the round": {
+			groupSize:           11,
+			value:               1100,
+			submittedTickets:    otherTickets,
+			lowerRoundTickets:   1,
+			coveredValue:        1000,
+			expectedProbability: 0,
+		},
+		"nothing covered": {
+			groupSize:           5,
+			value:               1100,
+			coveredValue:        0,
+			expectedProbability: 1,
+		},
+		"no tickets of other stakers covered": {
+			groupSize:           5,
+			value:               1100,
+			coveredValue:        1000,
+			expectedProbability: 1,
+		},
+		"tickets of other stakers covered": {
+			groupSize:        12,
+			value:            1100,
+			submittedTickets: otherTickets,
+			coveredValue:     1000,
+			// mean = 10 / 1000 * 100 = 1
+			// P = e^-1 * (1 + 1)
+			expectedProbability: 2 * math.Exp(-1),
+		},
+		"tickets of the staker covered": {
+			groupSize:        12,
+			stakerTickets:    []uint64{0, 100, 200, 300, 400},
+			value:            1100,
+			submittedTickets: otherTickets,
+			coveredValue:     1000,
+			// mean = 5 / 1000 * 100 = 0.5
+			// P = e^-0.5 * (1 + 0.5)
+			expectedProbability: 1.5 * math.Exp(-0.5),
+		},
+		"tickets of the staker skipped": {
+			groupSize: 12,
+			// Tickets 200, 300 and 400 of other stakers have been submitted
+			// while the same tickets of the staker have been skipped.
+			stakerTickets:    []uint64{0, 100},
+			value:            1100,
+			submittedTickets: otherTickets,
+			coveredValue:     1000,
+			// mean = 8 / 1000 * 100 = 0.8
+			// P = e^-0.8 * (1 + 0.8)
+			expectedProbability: 1.8 * math.Exp(-0.8),
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			optimizer := newTicketOptimizer(
+				OptimizerConfig{},
+				test.groupSize,
+			)
+			for _, stakerTicket := range test.stakerTickets {
+				optimizer.submittedValues[stakerTicket] = true
+			}
+
+			probability := optimizer.selectionProbability(
+				test.value,
+				test.submittedTickets,
+				test.lowerRoundTickets,
+				test.coveredValue,
+			)
+
+			if math.Abs(probability-test.expectedProbability) > 1e-9 {
+				t.Errorf(
+					"unexpected probability\nexpected: [%v]\nactual:   [%v]",
+					test.expectedProbability,
+					probability,
+				)
+			}
+		})
+	}
+}
+
+func TestOptimizerFilter(t *testing.T) {
+	candidateTickets := []*ticket{
+		newTestTicket(8, 9),
+		newTestTicket(9, 10),
+		newTestTicket(10, 12),
+	}
+
+	var tests = map[string]struct {
+		config                   OptimizerConfig
+		ticketSubmissionCost     *big.Int
+		expectedCandidateTickets []*ticket
+		expectedSkippedTickets   int
+	}{
+		"optimizer disabled": {
+			config:                   OptimizerConfig{},
+			ticketSubmissionCost:     big.NewInt(50),
+			expectedCandidateTickets: candidateTickets,
+			expectedSkippedTickets:   0,
+		},
+		"all tickets worth their cost": {
+			config:                   OptimizerConfig{big.NewInt(10000)},
+			ticketSubmissionCost:     big.NewInt(50),
+			expectedCandidateTickets: candidateTickets,
+			expectedSkippedTickets:   0,
+		},
+		"some tickets not worth their cost": {
+			// P(9) = e^-0.875 * (1 + 0.875 + 0.875^2 / 2) ~ 0.94
+			// P(10) = e^-1.75 * (1 + 1.75) ~ 0.48
+			config:                   OptimizerConfig{big.NewInt(100)},
+			ticketSubmissionCost:     big.NewInt(50),
+			expectedCandidateTickets: candidateTickets[:1],
+			expectedSkippedTickets:   2,
+		},
+		"no tickets worth their cost": {
+			config:                   OptimizerConfig{big.NewInt(100)},
+			ticketSubmissionCost:     big.NewInt(95),
+			expectedCandidateTickets: candidateTickets[:0],
+			expectedSkippedTickets:   3,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			relayChain := &stubGroupInterface{
+				groupSize:            10,
+				ticketSubmissionCost: test.ticketSubmissionCost,
+			}
+
+			// Tickets of other stakers submitted in preceding rounds.
+			for value := uint64(1); value < 8; value++ {
+				chainTicket, err := toChainTicket(newTestTicket(0, value))
+				if err != nil {
+					t.Fatal(err)
+				}
+				relayChain.SubmitTicket(chainTicket)
+			}
+
+			optimizer := newTicketOptimizer(
+				test.config,
+				relayChain.groupSize,
+			)
+
+			// Tickets with 60 leading zeros have values from 8 to 15.
+			filteredTickets := optimizer.filter(
+				relayChain,
+				candidateTickets,
+				1,
+				60,
+			)
+
+			if !reflect.DeepEqual(test.expectedCandidateTickets, filteredTickets) {
+				t.Errorf(
+					"unexpected candidate tickets\nexpected: [%v]\nactual:   [%v]",
+					test.expectedCandidateTickets,
+					filteredTickets,
+				)
+			}
+
+			if optimizer.skippedTickets != test.expectedSkippedTickets {
+				t.Errorf(
+					"unexpected number of skipped tickets\n"+
+						"expected: [%v]\nactual:   [%v]",
+					test.expectedSkippedTickets,
+					optimizer.skippedTickets,
+				)
+			}
+
+			expectedSubmittedTickets := len(test.expectedCandidateTickets)
+			if !test.config.enabled() {
+				expectedSubmittedTickets = 0
+			}
+			if optimizer.submittedTickets != expectedSubmittedTickets {
+				t.Errorf(
+					"unexpected number of submitted tickets\n"+
+						"expected: [%v]\nactual:   [%v]",
+					expectedSubmittedTickets,
+					optimizer.submittedTickets,
+				)
+			}
+		})
+	}
+}
@@ -4,12 +4,16 @@ import (
 	"math/big"
 
 	relaychain "github.com/keep-network/keep-core/pkg/beacon/relay/chain"
+	"github.com/keep-network/keep-core/pkg/beacon/relay/event"
+	"github.com/keep-network/keep-core/pkg/metrics"
 )
 
-// submitTicketsOnChain submits tickets to the chain.
+// submitTicketsOnChain submits tickets to the chain. Tickets successfully
+// submitted are counted in the given metrics.
 func submitTicketsOnChain(
 	tickets []*ticket,
 	relayChain relaychain.GroupSelectionInterface,
+	protocolMetrics *metrics.Protocol,
 ) {
 	for _, ticket := range tickets {
 		chainTicket, err := toChainTicket(ticket)
@@ -21,7 +25,11 @@ func submitTicketsOnChain(
 			continue
 		}
 
-		relayChain.SubmitTicket(chainTicket).OnFailure(
+		relayChain.SubmitTicket(chainTicket).OnSuccess(
+			func(*event.GroupTicketSubmission) {
+				protocolMetrics.TicketsSubmitted.Inc()
+			},
+		).OnFailure(
 			func(err error) {
 				logger.Errorf(
 					"ticket submission failed: [%v]",
@@ -35,7 +35,7 @@ func TestSubmitTicketsOnChain(t *testing.T) {
 		},
 	}
 
-	submitTicketsOnChain(tickets, mockInterface)
+	submitTicketsOnChain(tickets, mockInterface, testProtocolMetrics(t))
 
 	if len(tickets) != len(submittedTickets) {
 		t.Errorf(
@@ -87,6 +87,10 @@ func (mgi *mockGroupInterface) GetSubmittedTickets() ([]uint64, error) {
 	panic("not implemented")
 }
 
+func (mgi *mockGroupInterface) TicketSubmissionCost() (*big.Int, error) {
+	panic("not implemented")
+}
+
 func (mgi *mockGroupInterface) GetSelectedParticipants() ([]chain.StakerAddress, error) {
 	panic("unexpected")
 }
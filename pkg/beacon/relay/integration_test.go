@@ -17,6 +17,7 @@ import (
 
 	"github.com/keep-network/keep-core/pkg/internal/dkgtest"
 	"github.com/keep-network/keep-core/pkg/internal/entrytest"
+	"github.com/keep-network/keep-core/pkg/internal/faultnet"
 	"github.com/keep-network/keep-core/pkg/net"
 )
 
@@ -63,6 +64,66 @@ func TestAllMembersSigning(t *testing.T) {
 	}
 }
 
+// Success: all members of the signing group participate in signing over
+// a network delaying, reordering, and duplicating messages.
+func TestAllMembersSigningOverFaultyNetwork(t *testing.T) {
+	t.Parallel()
+
+	interceptor := func(msg net.TaggedMarshaler) net.TaggedMarshaler {
+		return msg
+	}
+
+	faults := faultnet.Config{
+		Seed:                   1024,
+		Latency:                1,
+		ReorderProbability:     0.2,
+		DuplicationProbability: 0.2,
+	}
+
+	dkgResult, err := dkgtest.RunTestWithFaults(
+		groupSize,
+		honestThreshold,
+		dkgtest.RandomSeed(t),
+		interceptor,
+		faults,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dkgtest.AssertDkgResultPublished(t, dkgResult)
+	dkgtest.AssertSuccessfulSignersCount(t, dkgResult, groupSize)
+	dkgtest.AssertSamePublicKey(t, dkgResult)
+
+	signingResult, err := entrytest.RunTestWithFaults(
+		dkgResult.GetSigners(),
+		honestThreshold,
+		interceptor,
+		faults,
+		previousEntry(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entrytest.AssertEntryPublished(t, signingResult)
+	entrytest.AssertNoSignerFailures(t, signingResult)
+
+	groupPublicKey, err := getFirstGroupPublicKey(dkgResult)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newEntry, err := signingResult.EntryValue()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bls.VerifyG1(groupPublicKey, previousEntryG1(), newEntry) {
+		t.Errorf("threshold signature failed BLS verification")
+	}
+}
+
 // Success: honest threshold of the signing group members participate in
 // signing.
 func TestHonestThresholdMembersSigning(t *testing.T) {
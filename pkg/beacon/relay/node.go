@@ -2,6 +2,7 @@ package relay
 
 import (
 	"bytes"
+	"context"
 	"encoding/hex"
 	"fmt"
 	"math/big"
@@ -15,9 +16,12 @@ import (
 
 	relaychain "github.com/keep-network/keep-core/pkg/beacon/relay/chain"
 	"github.com/keep-network/keep-core/pkg/beacon/relay/dkg"
+	"github.com/keep-network/keep-core/pkg/beacon/relay/gjkr"
 	"github.com/keep-network/keep-core/pkg/beacon/relay/groupselection"
 	"github.com/keep-network/keep-core/pkg/beacon/relay/registry"
 	"github.com/keep-network/keep-core/pkg/chain"
+	"github.com/keep-network/keep-core/pkg/journal"
+	"github.com/keep-network/keep-core/pkg/metrics"
 	"github.com/keep-network/keep-core/pkg/net"
 )
 
@@ -30,11 +34,17 @@ type Node struct {
 	Staker chain.Staker
 
 	// External interactors.
-	netProvider  net.Provider
-	blockCounter chain.BlockCounter
-	chainConfig  *relaychain.Config
+	netProvider    net.Provider
+	sharesExchange *gjkr.SharesExchange
+	blockCounter   chain.BlockCounter
+	chainConfig    *relaychain.Config
 
-	groupRegistry *registry.Groups
+	groupRegistry  *registry.Groups
+	dkgCheckpoints dkgCheckpointStorage
+	journal        *journal.Journal
+	metrics        *metrics.Protocol
+
+	executions executions
 }
 
 // IsInGroup checks if this node is a member of the group which was selected to
@@ -51,7 +61,11 @@ func (n *Node) IsInGroup(groupPublicKey []byte) bool {
 //
 // Indirectly, the completion of the process is signaled by the formation of an
 // on-chain group containing at least one of this node's virtual stakers.
+//
+// DKG executions started by this function are aborted as soon as the provided
+// context is done.
 func (n *Node) JoinGroupIfEligible(
+	ctx context.Context,
 	relayChain relaychain.Interface,
 	signing chain.Signing,
 	groupSelectionResult *groupselection.Result,
@@ -101,49 +115,214 @@ func (n *Node) JoinGroupIfEligible(
 		}
 
 		for _, index := range indexes {
-			// capture player index for goroutine
-			playerIndex := index
-
-			go func() {
-				signer, err := dkg.ExecuteDKG(
-					newEntry,
-					playerIndex,
-					n.chainConfig.GroupSize,
-					n.chainConfig.DishonestThreshold(),
-					membershipValidator,
-					dkgStartBlockHeight,
-					n.blockCounter,
-					relayChain,
-					signing,
-					broadcastChannel,
-				)
-				if err != nil {
-					logger.Errorf("failed to execute dkg: [%v]", err)
-					return
-				}
-
-				// final broadcast channel name for group is the compressed
-				// public key of the group
-				channelName := hex.EncodeToString(
-					signer.GroupPublicKeyBytesCompressed(),
-				)
-
-				err = n.groupRegistry.RegisterGroup(signer, channelName)
-				if err != nil {
-					logger.Errorf("failed to register a group: [%v]", err)
-				}
-
-				logger.Infof(
-					"[member:%v] ready to operate in the group",
-					signer.MemberID(),
-				)
-			}()
+			n.startDKG(
+				ctx,
+				relayChain,
+				signing,
+				broadcastChannel,
+				membershipValidator,
+				&dkgCheckpoint{
+					Seed:             newEntry,
+					Index:            index,
+					SelectedStakers:  groupSelectionResult.SelectedStakers,
+					StartBlockHeight: dkgStartBlockHeight,
+				},
+			)
 		}
 	}
 
 	return
 }
 
+// ResumeDKGIfEligible enables a client to rejoin the ongoing DKG executions
+// after it was crashed or restarted. Executions are resumed from the last
+// checkpoint persisted before the restart. Resumed DKG executions are aborted
+// as soon as the provided context is done.
+func (n *Node) ResumeDKGIfEligible(
+	ctx context.Context,
+	relayChain relayChain.Interface,
+	signing chain.Signing,
+) {
+	checkpoints, errors := n.dkgCheckpoints.readAll()
+
+	// Both channels have to be drained at the same time as we don't know in
+	// what order the storage writes to them.
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		for err := range errors {
+			logger.Errorf("could not load DKG checkpoint: [%v]", err)
+		}
+		wg.Done()
+	}()
+
+	go func() {
+		for checkpoint := range checkpoints {
+			n.resumeDKG(ctx, relayChain, signing, checkpoint)
+		}
+		wg.Done()
+	}()
+
+	wg.Wait()
+}
+
+func (n *Node) resumeDKG(
+	ctx context.Context,
+	relayChain relayChain.Interface,
+	signing chain.Signing,
+	checkpoint *dkgCheckpoint,
+) {
+	logger.Infof(
+		"[member:%v] resuming DKG for seed [0x%x] started at block [%v]",
+		checkpoint.Index+1,
+		checkpoint.Seed,
+		checkpoint.StartBlockHeight,
+	)
+
+	broadcastChannel, err := n.netProvider.BroadcastChannelFor(
+		checkpoint.Seed.Text(16),
+	)
+	if err != nil {
+		logger.Errorf("failed to get broadcast channel: [%v]", err)
+		return
+	}
+
+	membershipValidator := group.NewStakersMembershipValidator(
+		checkpoint.SelectedStakers,
+		signing,
+	)
+
+	err = broadcastChannel.SetFilter(membershipValidator.IsInGroup)
+	if err != nil {
+		logger.Errorf(
+			"could not set filter for channel [%v]: [%v]",
+			broadcastChannel.Name(),
+			err,
+		)
+	}
+
+	n.startDKG(
+		ctx,
+		relayChain,
+		signing,
+		broadcastChannel,
+		membershipValidator,
+		checkpoint,
+	)
+}
+
+// startDKG registers the DKG execution for the member described by the given
+// checkpoint and executes it in a separate goroutine. The execution is
+// registered before the function returns so that there is no moment in which
+// the node has already completed the group selection but has not yet
+// registered the DKG resulting from it.
+func (n *Node) startDKG(
+	ctx context.Context,
+	relayChain relayChain.Interface,
+	signing chain.Signing,
+	broadcastChannel net.BroadcastChannel,
+	membershipValidator group.MembershipValidator,
+	checkpoint *dkgCheckpoint,
+) {
+	done := n.executions.start(&Execution{
+		Protocol:    ProtocolDKG,
+		ID:          checkpoint.Seed.Text(16),
+		MemberIndex: group.MemberIndex(checkpoint.Index + 1),
+		StartBlock:  checkpoint.StartBlockHeight,
+	})
+
+	go func() {
+		defer done()
+
+		n.executeDKG(
+			ctx,
+			relayChain,
+			signing,
+			broadcastChannel,
+			membershipValidator,
+			checkpoint,
+		)
+	}()
+}
+
+// executeDKG executes or resumes DKG for the member described by the given
+// checkpoint and registers the resulting group membership. The execution
+// progress is persisted as it goes so that it can be resumed after a restart.
+// The persisted progress is archived once the execution completes. It is not
+// archived if the execution has been aborted because the context is done,
+// so that it can be resumed on the next start.
+func (n *Node) executeDKG(
+	ctx context.Context,
+	relayChain relayChain.Interface,
+	signing chain.Signing,
+	broadcastChannel net.BroadcastChannel,
+	membershipValidator group.MembershipValidator,
+	checkpoint *dkgCheckpoint,
+) {
+	onCheckpoint := func(progress *dkg.Checkpoint) {
+		checkpoint.Progress = progress
+		if err := n.dkgCheckpoints.save(checkpoint); err != nil {
+			logger.Errorf(
+				"[member:%v] could not save DKG checkpoint: [%v]",
+				checkpoint.Index+1,
+				err,
+			)
+		}
+	}
+
+	signer, err := dkg.ExecuteDKG(
+		ctx,
+		checkpoint.Seed,
+		checkpoint.Index,
+		n.chainConfig.GroupSize,
+		n.chainConfig.DishonestThreshold(),
+		membershipValidator,
+		checkpoint.StartBlockHeight,
+		n.blockCounter,
+		relayChain,
+		signing,
+		broadcastChannel,
+		n.sharesExchange,
+		n.journal,
+		n.metrics,
+		checkpoint.Progress,
+		onCheckpoint,
+	)
+
+	if ctx.Err() == nil {
+		err := n.dkgCheckpoints.archive(checkpoint.Seed, checkpoint.Index)
+		if err != nil {
+			logger.Errorf(
+				"[member:%v] could not archive DKG checkpoint: [%v]",
+				checkpoint.Index+1,
+				err,
+			)
+		}
+	}
+
+	if err != nil {
+		logger.Errorf("failed to execute dkg: [%v]", err)
+		return
+	}
+
+	// final broadcast channel name for group is the compressed
+	// public key of the group
+	channelName := hex.EncodeToString(
+		signer.GroupPublicKeyBytesCompressed(),
+	)
+
+	err = n.groupRegistry.RegisterGroup(signer, channelName)
+	if err != nil {
+		logger.Errorf("failed to register a group: [%v]", err)
+	}
+
+	logger.Infof(
+		"[member:%v] ready to operate in the group",
+		signer.MemberID(),
+	)
+}
+
 // ForwardSignatureShares enables the ability to forward signature shares
 // messages to other nodes even if this node is not a part of the group which
 // signs the relay entry.
@@ -160,6 +339,7 @@ func (n *Node) ForwardSignatureShares(groupPublicKeyBytes []byte) {
 // ResumeSigningIfEligible enables a client to rejoin the ongoing signing process
 // after it was crashed or restarted and if it belongs to the signing group.
 func (n *Node) ResumeSigningIfEligible(
+	ctx context.Context,
 	relayChain relayChain.Interface,
 	signing chain.Signing,
 ) {
@@ -203,6 +383,7 @@ func (n *Node) ResumeSigningIfEligible(
 			groupPublicKey,
 		)
 		n.GenerateRelayEntry(
+			ctx,
 			previousEntry,
 			relayChain,
 			signing,
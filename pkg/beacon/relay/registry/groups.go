@@ -2,16 +2,24 @@ package registry
 
 import (
 	"bytes"
+	"context"
 	"encoding/hex"
 	"fmt"
 	"sync"
 
 	relaychain "github.com/keep-network/keep-core/pkg/beacon/relay/chain"
 	"github.com/keep-network/keep-core/pkg/beacon/relay/dkg"
+	"github.com/keep-network/keep-core/pkg/chain"
+	"github.com/keep-network/keep-core/pkg/metrics"
 
 	"github.com/keep-network/keep-common/pkg/persistence"
 )
 
+// staleGroupsCheckInterval is the number of blocks after which groups whose
+// stale state could not be determined from their registration block are
+// checked against the chain again.
+const staleGroupsCheckInterval = uint64(100)
+
 // Groups represents a collection of Keep groups in which the given
 // client is a member.
 type Groups struct {
@@ -20,7 +28,13 @@ type Groups struct {
 	// key is group public key in uncompressed form
 	myGroups map[string][]*Membership
 
-	relayChain relaychain.GroupRegistrationInterface
+	relayChain  relaychain.GroupRegistrationInterface
+	chainConfig *relaychain.Config
+	metrics     *metrics.Protocol
+
+	// key is group public key in uncompressed form
+	statuses     map[string]*GroupStatus
+	currentBlock uint64
 
 	storage storage
 }
@@ -31,16 +45,49 @@ type Membership struct {
 	ChannelName string
 }
 
-// NewGroupRegistry returns an empty GroupRegistry.
+// GroupStatus describes the lifecycle of a group the client is a member of.
+// Blocks which are not known yet are zero.
+type GroupStatus struct {
+	// RegistrationBlock is the block at which the group has been registered
+	// on-chain.
+	RegistrationBlock uint64
+	// ExpirationBlock is the block after which the group expires and is no
+	// longer selected to new relay entries.
+	ExpirationBlock uint64
+	// StaleBlock is the block after which the group becomes stale and it is
+	// archived.
+	StaleBlock uint64
+	// LastSigningBlock is the start block of the last relay entry signing
+	// completed by members of the group hosted by the client since the client
+	// started.
+	LastSigningBlock uint64
+	// BlocksUntilExpiration is the number of blocks left until the group
+	// expires as of the last block seen by the registry. It is negative if the
+	// group has already expired.
+	BlocksUntilExpiration int64
+
+	// nextCheckBlock is the block at which the registry checks whether the
+	// group is stale or, if the registration block is not known, tries to
+	// determine it.
+	nextCheckBlock uint64
+}
+
+// NewGroupRegistry returns an empty GroupRegistry. Group lifecycle metrics
+// are updated in the given metrics of the operator owning the registry.
 func NewGroupRegistry(
 	relayChain relaychain.GroupRegistrationInterface,
+	chainConfig *relaychain.Config,
 	persistence persistence.Handle,
+	protocolMetrics *metrics.Protocol,
 ) *Groups {
 	return &Groups{
-		myGroups:   make(map[string][]*Membership),
-		relayChain: relayChain,
-		storage:    newStorage(persistence),
-		mutex:      sync.Mutex{},
+		myGroups:    make(map[string][]*Membership),
+		relayChain:  relayChain,
+		chainConfig: chainConfig,
+		metrics:     protocolMetrics,
+		statuses:    make(map[string]*GroupStatus),
+		storage:     newStorage(persistence),
+		mutex:       sync.Mutex{},
 	}
 }
 
@@ -66,10 +113,66 @@ func (g *Groups) RegisterGroup(
 	}
 
 	g.myGroups[groupPublicKey] = append(g.myGroups[groupPublicKey], membership)
+	g.status(groupPublicKey)
 
 	return nil
 }
 
+// RecordSigning records that members of the group with the given public key
+// hosted by the client completed the relay entry signing started at the
+// given block.
+func (g *Groups) RecordSigning(groupPublicKey []byte, startBlock uint64) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	publicKey := groupKeyToString(groupPublicKey)
+	if _, ok := g.myGroups[publicKey]; !ok {
+		return
+	}
+
+	status := g.status(publicKey)
+	if startBlock > status.LastSigningBlock {
+		status.LastSigningBlock = startBlock
+		g.metrics.GroupLastSigningBlock.Set(
+			float64(startBlock),
+			groupLabel(publicKey),
+		)
+	}
+}
+
+// GroupStatuses returns lifecycle statuses of all groups this client is
+// a member of. Statuses are keyed the same way as groups returned from
+// GetGroups.
+func (g *Groups) GroupStatuses() map[string]GroupStatus {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	statuses := make(map[string]GroupStatus, len(g.myGroups))
+	for publicKey := range g.myGroups {
+		status := *g.status(publicKey)
+		if status.RegistrationBlock != 0 {
+			status.BlocksUntilExpiration =
+				int64(status.ExpirationBlock) - int64(g.currentBlock)
+		}
+		statuses[publicKey] = status
+	}
+
+	return statuses
+}
+
+// status returns the status of the group with the given public key, creating
+// it if the group has no status yet. Has to be called with the registry lock
+// held.
+func (g *Groups) status(groupPublicKey string) *GroupStatus {
+	status, ok := g.statuses[groupPublicKey]
+	if !ok {
+		status = &GroupStatus{}
+		g.statuses[groupPublicKey] = status
+	}
+
+	return status
+}
+
 // GetGroup gets a group by a groupPublicKey
 func (g *Groups) GetGroup(groupPublicKey []byte) []*Membership {
 	g.mutex.Lock()
@@ -78,6 +181,20 @@ func (g *Groups) GetGroup(groupPublicKey []byte) []*Membership {
 	return g.myGroups[groupKeyToString(groupPublicKey)]
 }
 
+// GetGroups returns all groups this client is a member of. Groups are keyed
+// by the hexadecimal representation of the uncompressed group public key.
+func (g *Groups) GetGroups() map[string][]*Membership {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	groups := make(map[string][]*Membership, len(g.myGroups))
+	for groupPublicKey, memberships := range g.myGroups {
+		groups[groupPublicKey] = append([]*Membership{}, memberships...)
+	}
+
+	return groups
+}
+
 // UnregisterStaleGroups lookup for groups that have been marked as stale
 // on-chain. A stale group is a group that has expired and a certain time passed
 // after the group expiration. This guarantees the group will not be selected to
@@ -114,33 +231,232 @@ func (g *Groups) UnregisterStaleGroups(latestGroupPublicKey []byte) {
 			}
 
 			if isStaleGroup {
-				if len(memberships) == 0 {
-					logger.Errorf(
-						"inconsistent state; group with public key [%s] has no members",
-						publicKey,
-					)
-					continue
-				}
-
-				compressedPublicKey := memberships[0].Signer.GroupPublicKeyBytesCompressed()
-				err = g.storage.archive(compressedPublicKey)
-				if err != nil {
-					logger.Errorf("failed to archive group with compressed public key [%s]: [%v]",
-						hex.EncodeToString(compressedPublicKey),
-						err,
-					)
-					continue
-				}
-
-				logger.Infof(
-					"archived group with compressed public key [%s]",
-					hex.EncodeToString(compressedPublicKey),
-				)
+				g.archiveGroup(publicKey, memberships)
+			}
+		}
+	}
+}
+
+// MonitorGroups archives groups as soon as they become stale, tracking groups
+// with every new block until the provided context is done. Registration
+// blocks of groups are determined from the chain to learn when the groups
+// expire and become stale, so the chain is asked whether a group is stale only
+// once it is expected to be. Groups whose registration block could not be
+// determined are checked every staleGroupsCheckInterval blocks.
+func (g *Groups) MonitorGroups(
+	ctx context.Context,
+	blockCounter chain.BlockCounter,
+) {
+	blocks := blockCounter.WatchBlocks(ctx)
+
+	go func() {
+		for block := range blocks {
+			g.updateGroups(block)
+		}
+	}()
+}
+
+// groupCheck is the check of a single group against the chain made when
+// the registry is updated with a new block. Checks are made with the registry
+// lock released and their outcomes are applied to the registry afterwards.
+type groupCheck struct {
+	publicKey      string
+	publicKeyBytes []byte
+
+	// registrationBlock is the registration block of the group; zero if it
+	// is not known. registrationDetermined is set if it has been determined
+	// by the check.
+	registrationBlock      uint64
+	registrationDetermined bool
+
+	nextCheckBlock uint64
+	isStale        bool
+}
+
+// updateGroups updates statuses of groups with the given current block and
+// archives groups which became stale. Groups due to be checked against the
+// chain are collected with the registry lock held, the chain is asked with
+// the lock released, and the outcomes are applied with the lock held again.
+func (g *Groups) updateGroups(currentBlock uint64) {
+	checks := g.collectGroupChecks(currentBlock)
+
+	for _, check := range checks {
+		g.checkGroup(check, currentBlock)
+	}
+
+	g.applyGroupChecks(checks)
+}
+
+// collectGroupChecks updates group metrics with the current block and returns
+// checks of groups due to be checked against the chain at that block.
+func (g *Groups) collectGroupChecks(currentBlock uint64) []*groupCheck {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.currentBlock = currentBlock
+
+	var checks []*groupCheck
+	for publicKey := range g.myGroups {
+		status := g.status(publicKey)
+
+		if status.RegistrationBlock != 0 {
+			g.metrics.GroupBlocksUntilExpiration.Set(
+				float64(int64(status.ExpirationBlock)-int64(currentBlock)),
+				groupLabel(publicKey),
+			)
+		}
+
+		if currentBlock < status.nextCheckBlock {
+			continue
+		}
+
+		publicKeyBytes, err := groupKeyFromString(publicKey)
+		if err != nil {
+			logger.Errorf(
+				"error occurred while decoding public key into bytes: [%v]",
+				err,
+			)
+			continue
+		}
 
-				delete(g.myGroups, publicKey)
+		checks = append(checks, &groupCheck{
+			publicKey:         publicKey,
+			publicKeyBytes:    publicKeyBytes,
+			registrationBlock: status.RegistrationBlock,
+			nextCheckBlock:    status.nextCheckBlock,
+		})
+	}
+
+	return checks
+}
+
+// checkGroup determines the registration block of the group, if not known
+// yet, and whether the group is stale, if it is expected to be. Has to be
+// called with the registry lock released.
+func (g *Groups) checkGroup(check *groupCheck, currentBlock uint64) {
+	if check.registrationBlock == 0 {
+		registrationBlock, err := g.relayChain.GetGroupRegistrationBlock(
+			check.publicKeyBytes,
+		)
+		if err != nil {
+			logger.Warningf(
+				"could not determine registration block "+
+					"of group with public key [%s]: [%v]",
+				check.publicKey,
+				err,
+			)
+
+			// The registration of a new group may not be seen yet.
+			// Give it some time before asking the chain whether the
+			// group is stale as the chain may not know the group yet.
+			if check.nextCheckBlock == 0 {
+				check.nextCheckBlock = currentBlock + staleGroupsCheckInterval
+				return
+			}
+		} else {
+			check.registrationBlock = registrationBlock
+			check.registrationDetermined = true
+
+			staleBlock := g.staleBlock(registrationBlock)
+			if currentBlock <= staleBlock {
+				check.nextCheckBlock = staleBlock + 1
+				return
 			}
 		}
 	}
+
+	check.nextCheckBlock = currentBlock + staleGroupsCheckInterval
+
+	isStaleGroup, err := g.relayChain.IsStaleGroup(check.publicKeyBytes)
+	if err != nil {
+		logger.Errorf(
+			"failed to check if stale for group with public key [%s]: [%v]",
+			check.publicKey,
+			err,
+		)
+		return
+	}
+
+	check.isStale = isStaleGroup
+}
+
+// applyGroupChecks updates statuses of checked groups and archives the stale
+// ones. Groups removed from the registry while they were checked are skipped.
+func (g *Groups) applyGroupChecks(checks []*groupCheck) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	for _, check := range checks {
+		memberships, ok := g.myGroups[check.publicKey]
+		if !ok {
+			continue
+		}
+
+		status := g.status(check.publicKey)
+		status.nextCheckBlock = check.nextCheckBlock
+
+		if check.registrationDetermined && status.RegistrationBlock == 0 {
+			status.RegistrationBlock = check.registrationBlock
+			status.ExpirationBlock =
+				check.registrationBlock + g.chainConfig.GroupActiveTime
+			status.StaleBlock = g.staleBlock(check.registrationBlock)
+
+			logger.Infof(
+				"group with public key [%s] registered at block [%v] "+
+					"expires at block [%v]",
+				check.publicKey,
+				status.RegistrationBlock,
+				status.ExpirationBlock,
+			)
+		}
+
+		if check.isStale {
+			g.archiveGroup(check.publicKey, memberships)
+		}
+	}
+}
+
+// staleBlock returns the block after which the group registered at the
+// given block becomes stale.
+func (g *Groups) staleBlock(registrationBlock uint64) uint64 {
+	return registrationBlock +
+		g.chainConfig.GroupActiveTime +
+		g.chainConfig.RelayEntryTimeout
+}
+
+// archiveGroup archives memberships of the group with the given public key
+// in the underlying storage and removes the group from the registry. Has to
+// be called with the registry lock held.
+func (g *Groups) archiveGroup(publicKey string, memberships []*Membership) {
+	if len(memberships) == 0 {
+		logger.Errorf(
+			"inconsistent state; group with public key [%s] has no members",
+			publicKey,
+		)
+		return
+	}
+
+	compressedPublicKey := memberships[0].Signer.GroupPublicKeyBytesCompressed()
+	err := g.storage.archive(compressedPublicKey)
+	if err != nil {
+		logger.Errorf("failed to archive group with compressed public key [%s]: [%v]",
+			hex.EncodeToString(compressedPublicKey),
+			err,
+		)
+		return
+	}
+
+	logger.Infof(
+		"archived group with compressed public key [%s]",
+		hex.EncodeToString(compressedPublicKey),
+	)
+
+	delete(g.myGroups, publicKey)
+	delete(g.statuses, publicKey)
+
+	g.metrics.GroupBlocksUntilExpiration.Delete(groupLabel(publicKey))
+	g.metrics.GroupLastSigningBlock.Delete(groupLabel(publicKey))
+	g.metrics.GroupsArchived.Inc()
 }
 
 // LoadExistingGroups iterates over all stored memberships on disk and loads them
@@ -195,6 +511,16 @@ func (g *Groups) printMemberships() {
 	}
 }
 
+// groupLabel shortens the group public key so that it can be used as
+// a metric label.
+func groupLabel(groupPublicKey string) string {
+	if len(groupPublicKey) > 16 {
+		return groupPublicKey[:16]
+	}
+
+	return groupPublicKey
+}
+
 func groupKeyToString(groupKey []byte) string {
 	return hex.EncodeToString(groupKey)
 }
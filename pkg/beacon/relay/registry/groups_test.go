@@ -3,9 +3,11 @@ package registry
 import (
 	"bytes"
 	"encoding/hex"
+	"fmt"
 	"math/big"
 	"reflect"
 	"testing"
+	"time"
 
 	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
 	"github.com/keep-network/keep-common/pkg/persistence"
@@ -14,6 +16,7 @@ import (
 	"github.com/keep-network/keep-core/pkg/beacon/relay/event"
 	"github.com/keep-network/keep-core/pkg/beacon/relay/group"
 	chainLocal "github.com/keep-network/keep-core/pkg/chain/local"
+	"github.com/keep-network/keep-core/pkg/metrics"
 	"github.com/keep-network/keep-core/pkg/subscription"
 )
 
@@ -54,7 +57,12 @@ var (
 func TestRegisterGroup(t *testing.T) {
 	chain := chainLocal.Connect(5, 3, big.NewInt(200)).ThresholdRelay()
 
-	gr := NewGroupRegistry(chain, persistenceMock)
+	gr := NewGroupRegistry(
+		chain,
+		chain.GetConfig(),
+		persistenceMock,
+		testProtocolMetrics(t),
+	)
 
 	gr.RegisterGroup(signer1, channelName1)
 
@@ -77,7 +85,12 @@ func TestRegisterGroup(t *testing.T) {
 
 func TestLoadGroup(t *testing.T) {
 	chain := chainLocal.Connect(5, 3, big.NewInt(200)).ThresholdRelay()
-	gr := NewGroupRegistry(chain, persistenceMock)
+	gr := NewGroupRegistry(
+		chain,
+		chain.GetConfig(),
+		persistenceMock,
+		testProtocolMetrics(t),
+	)
 
 	if len(gr.myGroups) != 0 {
 		t.Fatalf(
@@ -122,7 +135,12 @@ func TestUnregisterStaleGroups(t *testing.T) {
 		groupsCheckedIfStale: make(map[string]bool),
 	}
 
-	gr := NewGroupRegistry(mockChain, persistenceMock)
+	gr := NewGroupRegistry(
+		mockChain,
+		&chain.Config{},
+		persistenceMock,
+		testProtocolMetrics(t),
+	)
 
 	gr.RegisterGroup(signer1, channelName1)
 	gr.RegisterGroup(signer2, channelName1)
@@ -158,7 +176,12 @@ func TestUnregisterStaleGroupsSkipLastGroupCheck(t *testing.T) {
 		groupsCheckedIfStale: make(map[string]bool),
 	}
 
-	gr := NewGroupRegistry(mockChain, persistenceMock)
+	gr := NewGroupRegistry(
+		mockChain,
+		&chain.Config{},
+		persistenceMock,
+		testProtocolMetrics(t),
+	)
 
 	gr.RegisterGroup(signer1, channelName1)
 	gr.RegisterGroup(signer2, channelName1)
@@ -182,9 +205,188 @@ func TestUnregisterStaleGroupsSkipLastGroupCheck(t *testing.T) {
 	}
 }
 
+func TestMonitorGroupsArchivesStaleGroups(t *testing.T) {
+	mockChain := &mockGroupRegistrationInterface{
+		groupsToRemove:       [][]byte{},
+		groupsCheckedIfStale: make(map[string]bool),
+		registrationBlocks: map[string]uint64{
+			groupKeyToString(signer1.GroupPublicKeyBytes()): 10,
+		},
+	}
+	persistence := &persistenceHandleMock{}
+
+	gr := NewGroupRegistry(
+		mockChain,
+		&chain.Config{GroupActiveTime: 20, RelayEntryTimeout: 5},
+		persistence,
+		testProtocolMetrics(t),
+	)
+
+	gr.RegisterGroup(signer1, channelName1)
+	gr.RegisterGroup(signer2, channelName1)
+
+	mockChain.markAsStale(signer1.GroupPublicKeyBytes())
+	mockChain.markAsStale(signer2.GroupPublicKeyBytes())
+
+	group1PublicKeyString := groupKeyToString(signer1.GroupPublicKeyBytes())
+	group2PublicKeyString := groupKeyToString(signer2.GroupPublicKeyBytes())
+
+	// The registration block of the second group is not known so it is
+	// checked only after the check interval.
+	gr.updateGroups(30)
+
+	if mockChain.groupsCheckedIfStale[group1PublicKeyString] {
+		t.Errorf("IsStaleGroup() was not expected to be called for the first group")
+	}
+	if mockChain.groupsCheckedIfStale[group2PublicKeyString] {
+		t.Errorf("IsStaleGroup() was not expected to be called for the second group")
+	}
+
+	expectedStatus := GroupStatus{
+		RegistrationBlock:     10,
+		ExpirationBlock:       30,
+		StaleBlock:            35,
+		BlocksUntilExpiration: 0,
+		nextCheckBlock:        36,
+	}
+	status := gr.GroupStatuses()[group1PublicKeyString]
+	if status != expectedStatus {
+		t.Errorf(
+			"unexpected status\nexpected: [%+v]\nactual:   [%+v]",
+			expectedStatus,
+			status,
+		)
+	}
+
+	gr.updateGroups(36)
+
+	if gr.GetGroup(signer1.GroupPublicKeyBytes()) != nil {
+		t.Errorf("first group was expected to be archived")
+	}
+	if gr.GetGroup(signer2.GroupPublicKeyBytes()) == nil {
+		t.Errorf("second group was not expected to be archived yet")
+	}
+
+	gr.updateGroups(30 + staleGroupsCheckInterval)
+
+	if gr.GetGroup(signer2.GroupPublicKeyBytes()) != nil {
+		t.Errorf("second group was expected to be archived")
+	}
+
+	expectedArchivedGroups := []string{
+		hex.EncodeToString(signer1.GroupPublicKeyBytesCompressed()),
+		hex.EncodeToString(signer2.GroupPublicKeyBytesCompressed()),
+	}
+	if !reflect.DeepEqual(expectedArchivedGroups, persistence.archivedGroups) {
+		t.Errorf(
+			"unexpected archived groups\nexpected: [%v]\nactual:   [%v]",
+			expectedArchivedGroups,
+			persistence.archivedGroups,
+		)
+	}
+}
+
+func TestMonitorGroupsQueriesChainWithoutLock(t *testing.T) {
+	mockChain := &mockGroupRegistrationInterface{
+		groupsToRemove:       [][]byte{},
+		groupsCheckedIfStale: make(map[string]bool),
+		registrationBlocks: map[string]uint64{
+			groupKeyToString(signer1.GroupPublicKeyBytes()): 10,
+		},
+	}
+
+	gr := NewGroupRegistry(
+		mockChain,
+		&chain.Config{GroupActiveTime: 20, RelayEntryTimeout: 5},
+		&persistenceHandleMock{},
+		testProtocolMetrics(t),
+	)
+
+	gr.RegisterGroup(signer1, channelName1)
+
+	mockChain.markAsStale(signer1.GroupPublicKeyBytes())
+
+	// The registry is used by other goroutines while the chain is asked
+	// about groups; those must not wait for the chain to respond.
+	chainCalls := 0
+	mockChain.onChainCall = func() {
+		chainCalls++
+
+		done := make(chan struct{})
+		go func() {
+			gr.GetGroups()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("registry locked while asking the chain")
+		}
+	}
+
+	gr.updateGroups(30)
+	gr.updateGroups(36)
+
+	if chainCalls != 2 {
+		t.Errorf(
+			"unexpected number of chain calls\nexpected: [%v]\nactual:   [%v]",
+			2,
+			chainCalls,
+		)
+	}
+	if gr.GetGroup(signer1.GroupPublicKeyBytes()) != nil {
+		t.Errorf("group was expected to be archived")
+	}
+}
+
+func TestRecordSigning(t *testing.T) {
+	mockChain := &mockGroupRegistrationInterface{
+		groupsCheckedIfStale: make(map[string]bool),
+	}
+
+	gr := NewGroupRegistry(
+		mockChain,
+		&chain.Config{},
+		&persistenceHandleMock{},
+		testProtocolMetrics(t),
+	)
+
+	gr.RegisterGroup(signer1, channelName1)
+
+	gr.RecordSigning(signer1.GroupPublicKeyBytes(), 120)
+	gr.RecordSigning(signer1.GroupPublicKeyBytes(), 100)
+	// not a member of the group; should be ignored
+	gr.RecordSigning(signer2.GroupPublicKeyBytes(), 130)
+
+	statuses := gr.GroupStatuses()
+
+	if len(statuses) != 1 {
+		t.Fatalf(
+			"unexpected number of statuses\nexpected: [%v]\nactual:   [%v]",
+			1,
+			len(statuses),
+		)
+	}
+
+	status := statuses[groupKeyToString(signer1.GroupPublicKeyBytes())]
+	if status.LastSigningBlock != 120 {
+		t.Errorf(
+			"unexpected last signing block\nexpected: [%v]\nactual:   [%v]",
+			120,
+			status.LastSigningBlock,
+		)
+	}
+}
+
 type mockGroupRegistrationInterface struct {
 	groupsToRemove       [][]byte
 	groupsCheckedIfStale map[string]bool
+	registrationBlocks   map[string]uint64
+
+	// onChainCall, if set, is called whenever the registry asks the chain
+	// about a group.
+	onChainCall func()
 }
 
 func (mgri *mockGroupRegistrationInterface) markAsStale(publicKey []byte) {
@@ -198,6 +400,10 @@ func (mgri *mockGroupRegistrationInterface) OnGroupRegistered(
 }
 
 func (mgri *mockGroupRegistrationInterface) IsStaleGroup(groupPublicKey []byte) (bool, error) {
+	if mgri.onChainCall != nil {
+		mgri.onChainCall()
+	}
+
 	mgri.groupsCheckedIfStale[groupKeyToString(groupPublicKey)] = true
 	for _, groupToRemove := range mgri.groupsToRemove {
 		if bytes.Compare(groupToRemove, groupPublicKey) == 0 {
@@ -207,6 +413,21 @@ func (mgri *mockGroupRegistrationInterface) IsStaleGroup(groupPublicKey []byte)
 	return false, nil
 }
 
+func (mgri *mockGroupRegistrationInterface) GetGroupRegistrationBlock(
+	groupPublicKey []byte,
+) (uint64, error) {
+	if mgri.onChainCall != nil {
+		mgri.onChainCall()
+	}
+
+	registrationBlock, ok := mgri.registrationBlocks[groupKeyToString(groupPublicKey)]
+	if !ok {
+		return 0, fmt.Errorf("group not found")
+	}
+
+	return registrationBlock, nil
+}
+
 func (mgri *mockGroupRegistrationInterface) GetGroupMembers(
 	groupPublicKey []byte,
 ) ([]chain.StakerAddress, error) {
@@ -243,12 +464,14 @@ func (phm *persistenceHandleMock) ReadAll() (<-chan persistence.DataDescriptor,
 		ChannelName: channelName2,
 	}).Marshal()
 
-	outputData := make(chan persistence.DataDescriptor, 3)
+	outputData := make(chan persistence.DataDescriptor, 4)
 	outputErrors := make(chan error)
 
-	outputData <- &testDataDescriptor{"1", "dir", membershipBytes1}
-	outputData <- &testDataDescriptor{"2", "dir", membershipBytes2}
-	outputData <- &testDataDescriptor{"3", "dir", membershipBytes3}
+	outputData <- &testDataDescriptor{"membership_1", "dir", membershipBytes1}
+	outputData <- &testDataDescriptor{"membership_2", "dir", membershipBytes2}
+	outputData <- &testDataDescriptor{"membership_3", "dir", membershipBytes3}
+	// not a membership; should be skipped when loading groups
+	outputData <- &testDataDescriptor{"checkpoint", "dkg_dir", []byte("{}")}
 
 	close(outputData)
 	close(outputErrors)
@@ -279,3 +502,12 @@ func (tdd *testDataDescriptor) Directory() string {
 func (tdd *testDataDescriptor) Content() ([]byte, error) {
 	return tdd.content, nil
 }
+
+func testProtocolMetrics(t *testing.T) *metrics.Protocol {
+	protocolMetrics, err := metrics.NewProtocol(metrics.NewRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return protocolMetrics.ForOperator("test")
+}
@@ -2,6 +2,7 @@ package registry
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/keep-network/keep-common/pkg/persistence"
@@ -9,6 +10,8 @@ import (
 	"encoding/hex"
 )
 
+const membershipFilePrefix = "membership_"
+
 type storage interface {
 	save(membership *Membership) error
 	readAll() (<-chan *Membership, <-chan error)
@@ -33,7 +36,7 @@ func (ps *persistentStorage) save(membership *Membership) error {
 
 	hexGroupPublicKey := hex.EncodeToString(membership.Signer.GroupPublicKeyBytesCompressed())
 
-	return ps.handle.Save(membershipBytes, hexGroupPublicKey, "/membership_"+fmt.Sprint(membership.Signer.MemberID()))
+	return ps.handle.Save(membershipBytes, hexGroupPublicKey, "/"+membershipFilePrefix+fmt.Sprint(membership.Signer.MemberID()))
 }
 
 func (ps *persistentStorage) archive(groupPublicKeyCompressed []byte) error {
@@ -79,6 +82,12 @@ func (ps *persistentStorage) readAll() (<-chan *Membership, <-chan error) {
 	// error to an output errors channel.
 	go func() {
 		for descriptor := range inputData {
+			// The same persistence handle is used to store other data, such
+			// as DKG checkpoints. Skip everything which is not a membership.
+			if !strings.HasPrefix(descriptor.Name(), membershipFilePrefix) {
+				continue
+			}
+
 			content, err := descriptor.Content()
 			if err != nil {
 				outputErrors <- fmt.Errorf(
@@ -1,15 +1,22 @@
 package relay
 
 import (
+	"context"
+	"time"
+
 	"github.com/ipfs/go-log"
+	"github.com/keep-network/keep-common/pkg/persistence"
 	"github.com/keep-network/keep-core/pkg/beacon/relay/group"
 
 	relayChain "github.com/keep-network/keep-core/pkg/beacon/relay/chain"
 	"github.com/keep-network/keep-core/pkg/beacon/relay/entry"
 	"github.com/keep-network/keep-core/pkg/beacon/relay/event"
+	"github.com/keep-network/keep-core/pkg/beacon/relay/gjkr"
 
 	"github.com/keep-network/keep-core/pkg/beacon/relay/registry"
 	"github.com/keep-network/keep-core/pkg/chain"
+	"github.com/keep-network/keep-core/pkg/journal"
+	"github.com/keep-network/keep-core/pkg/metrics"
 	"github.com/keep-network/keep-core/pkg/net"
 )
 
@@ -18,20 +25,31 @@ var logger = log.Logger("keep-relay")
 const maxGroupSize = 255
 
 // NewNode returns an empty Node with no group, zero group count, and a nil last
-// seen entry, tied to the given net.Provider.
+// seen entry, tied to the given net.Provider. The given persistence handle is
+// used to store checkpoints of DKG executions in progress. Peer shares of DKG
+// executions are delivered over unicast channels of the net.Provider. DKG and
+// relay entry signing executions are recorded in the given journal and
+// counted in the given metrics of the staker's operator.
 func NewNode(
 	staker chain.Staker,
 	netProvider net.Provider,
 	blockCounter chain.BlockCounter,
 	chainConfig *relayChain.Config,
 	groupRegistry *registry.Groups,
+	persistence persistence.Handle,
+	eventJournal *journal.Journal,
+	protocolMetrics *metrics.Protocol,
 ) Node {
 	return Node{
-		Staker:        staker,
-		netProvider:   netProvider,
-		blockCounter:  blockCounter,
-		chainConfig:   chainConfig,
-		groupRegistry: groupRegistry,
+		Staker:         staker,
+		netProvider:    netProvider,
+		sharesExchange: gjkr.NewSharesExchange(netProvider),
+		blockCounter:   blockCounter,
+		chainConfig:    chainConfig,
+		groupRegistry:  groupRegistry,
+		dkgCheckpoints: newDKGCheckpointStorage(persistence),
+		journal:        eventJournal,
+		metrics:        protocolMetrics,
 	}
 }
 
@@ -39,13 +57,17 @@ func NewNode(
 // When a processing group which is supposed to deliver a relay entry does not
 // fulfill its work, then this Node notifies the chain about it. In the case of
 // delivering a relay entry by a processing group, this Node does nothing.
+// Monitoring stops as soon as the provided context is done.
 func (n *Node) MonitorRelayEntry(
+	ctx context.Context,
 	relayChain relayChain.Interface,
 	relayRequestBlockNumber uint64,
 	chainConfig *relayChain.Config,
 ) {
 	logger.Infof("monitoring chain for a new relay entry")
 
+	monitoringStartTime := time.Now()
+
 	timeoutWaiterChannel, err := n.blockCounter.BlockHeightWaiter(relayRequestBlockNumber + chainConfig.RelayEntryTimeout)
 	if err != nil {
 		logger.Errorf("waiter for a relay entry timeout block failed: [%v]", err)
@@ -71,13 +93,27 @@ func (n *Node) MonitorRelayEntry(
 			err = relayChain.ReportRelayEntryTimeout()
 			if err != nil {
 				logger.Errorf("could not report a relay entry timeout: [%v]", err)
+				return
 			}
+			n.metrics.RelayEntryTimeoutsReported.Inc()
 			return
 		case entry := <-onEntrySubmittedChannel:
 			logger.Infof(
 				"relay entry was submitted by the selected group on time at block [%v]",
 				entry.BlockNumber,
 			)
+			n.metrics.RelayEntryDurationSeconds.Observe(
+				time.Since(monitoringStartTime).Seconds(),
+			)
+			if entry.BlockNumber >= relayRequestBlockNumber {
+				n.metrics.RelayEntryDurationBlocks.Observe(
+					float64(entry.BlockNumber - relayRequestBlockNumber),
+				)
+			}
+			return
+		case <-ctx.Done():
+			subscription.Unsubscribe()
+			logger.Infof("stopped monitoring chain for a new relay entry")
 			return
 		}
 	}
@@ -89,8 +125,10 @@ func (n *Node) MonitorRelayEntry(
 // upon successfully completing it, submits the signature as a new relay entry.
 // Note that this function returns immediately after determining whether the
 // node is or is not a member of the requested group, and signature creation
-// and submission is performed in a background goroutine.
+// and submission is performed in a background goroutine which is aborted as
+// soon as the provided context is done.
 func (n *Node) GenerateRelayEntry(
+	ctx context.Context,
 	previousEntry []byte,
 	relayChain relayChain.Interface,
 	signing chain.Signing,
@@ -133,7 +171,16 @@ func (n *Node) GenerateRelayEntry(
 
 	for _, member := range memberships {
 		go func(member *registry.Membership) {
-			err = entry.SignAndSubmit(
+			done := n.executions.start(&Execution{
+				Protocol:    ProtocolSigning,
+				ID:          member.ChannelName,
+				MemberIndex: member.Signer.MemberID(),
+				StartBlock:  startBlockHeight,
+			})
+			defer done()
+
+			err := entry.SignAndSubmit(
+				ctx,
 				n.blockCounter,
 				channel,
 				relayChain,
@@ -141,6 +188,8 @@ func (n *Node) GenerateRelayEntry(
 				n.chainConfig.HonestThreshold,
 				member.Signer,
 				startBlockHeight,
+				n.journal,
+				n.metrics,
 			)
 			if err != nil {
 				logger.Errorf(
@@ -149,6 +198,8 @@ func (n *Node) GenerateRelayEntry(
 				)
 				return
 			}
+
+			n.groupRegistry.RecordSigning(groupPublicKey, startBlockHeight)
 		}(member)
 	}
 }
@@ -1,12 +1,14 @@
 package relay
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 	"testing"
 
 	relaychain "github.com/keep-network/keep-core/pkg/beacon/relay/chain"
 	chainLocal "github.com/keep-network/keep-core/pkg/chain/local"
+	"github.com/keep-network/keep-core/pkg/metrics"
 )
 
 var address = "0x65ea55c1f10491038425725dc00dffeab2a1e28a"
@@ -21,6 +23,7 @@ func TestMonitorRelayEntryOnChain_EntrySubmitted(t *testing.T) {
 
 	node := &Node{
 		blockCounter: blockCounter,
+		metrics:      testProtocolMetrics(t),
 	}
 
 	relayChain := chain.ThresholdRelay()
@@ -33,6 +36,7 @@ func TestMonitorRelayEntryOnChain_EntrySubmitted(t *testing.T) {
 	}
 
 	go node.MonitorRelayEntry(
+		context.Background(),
 		relayChain,
 		startBlockHeight,
 		chainConfig,
@@ -79,6 +83,7 @@ func TestMonitorRelayEntryOnChain_EntryNotSubmitted(t *testing.T) {
 
 	node := &Node{
 		blockCounter: blockCounter,
+		metrics:      testProtocolMetrics(t),
 	}
 
 	relayChain := chain.ThresholdRelay()
@@ -91,6 +96,7 @@ func TestMonitorRelayEntryOnChain_EntryNotSubmitted(t *testing.T) {
 	}
 
 	go node.MonitorRelayEntry(
+		context.Background(),
 		relayChain,
 		startBlockHeight,
 		chainConfig,
@@ -121,3 +127,59 @@ func TestMonitorRelayEntryOnChain_EntryNotSubmitted(t *testing.T) {
 		)
 	}
 }
+
+func TestMonitorRelayEntryOnChain_Cancelled(t *testing.T) {
+	chain := chainLocal.Connect(5, 3, big.NewInt(200))
+	blockCounter, err := chain.BlockCounter()
+	if err != nil {
+		fmt.Printf("failed to setup a block counter: [%v]", err)
+	}
+
+	node := &Node{
+		blockCounter: blockCounter,
+		metrics:      testProtocolMetrics(t),
+	}
+
+	relayChain := chain.ThresholdRelay()
+	chainConfig := &relaychain.Config{
+		RelayEntryTimeout: uint64(relayEntryTimeout),
+	}
+	startBlockHeight, err := blockCounter.CurrentBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancelCtx := context.WithCancel(context.Background())
+
+	go node.MonitorRelayEntry(
+		ctx,
+		relayChain,
+		startBlockHeight,
+		chainConfig,
+	)
+
+	cancelCtx()
+
+	// we want to exceed the relay entry timeout to make sure the timeout
+	// is not reported once monitoring has been cancelled.
+	blockCounter.WaitForBlockHeight(startBlockHeight + relayEntryTimeout + 5)
+
+	timeoutsReport := chain.GetRelayEntryTimeoutReports()
+	numberOfReports := len(timeoutsReport)
+
+	if numberOfReports != 0 {
+		t.Fatalf(
+			"expected 0 relay entry timeout reports; has: [%v]",
+			numberOfReports,
+		)
+	}
+}
+
+func testProtocolMetrics(t *testing.T) *metrics.Protocol {
+	protocolMetrics, err := metrics.NewProtocol(metrics.NewRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return protocolMetrics.ForOperator("test")
+}
@@ -0,0 +1,126 @@
+package state
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/keep-network/keep-core/pkg/net"
+)
+
+// Checkpoint captures the progress of the state machine execution. It allows
+// to resume the execution after a restart by replaying all the states
+// completed before the checkpoint was taken, along with all the messages
+// received by them.
+type Checkpoint struct {
+	// CompletedStates is the number of states completed before the checkpoint
+	// was taken.
+	CompletedStates int
+	// EndBlockHeight is the block at which the last completed state ended.
+	EndBlockHeight uint64
+	// Finished is true if the last completed state was the final state of
+	// the execution.
+	Finished bool
+	// Messages contains all messages received by the completed states.
+	Messages []*RecordedMessage
+}
+
+// RecordedMessage is a network message received by the state machine, stored
+// in a form allowing to deliver it again when the execution is resumed.
+type RecordedMessage struct {
+	// StateIndex is the index of the state which received the message,
+	// starting from 0 for the initial state.
+	StateIndex      int
+	Type            string
+	SenderPublicKey []byte
+	Payload         []byte
+}
+
+func (c *Checkpoint) copy() *Checkpoint {
+	return &Checkpoint{
+		CompletedStates: c.CompletedStates,
+		EndBlockHeight:  c.EndBlockHeight,
+		Finished:        c.Finished,
+		Messages:        append([]*RecordedMessage{}, c.Messages...),
+	}
+}
+
+// replayedMessage is a message delivered to the state again when the execution
+// is resumed from a checkpoint.
+type replayedMessage struct {
+	messageType     string
+	senderPublicKey []byte
+	payload         interface{}
+}
+
+func (rm *replayedMessage) TransportSenderID() net.TransportIdentifier {
+	return nil
+}
+
+func (rm *replayedMessage) SenderPublicKey() []byte {
+	return rm.senderPublicKey
+}
+
+func (rm *replayedMessage) Payload() interface{} {
+	return rm.payload
+}
+
+func (rm *replayedMessage) Type() string {
+	return rm.messageType
+}
+
+func (rm *replayedMessage) Seqno() uint64 {
+	return 0
+}
+
+// ReplayChannel is a broadcast channel which can be muted while the state
+// machine replays states completed before a checkpoint was taken. States of
+// a machine which is supposed to be resumed should be created with this
+// channel, so that messages they already sent before the restart are not sent
+// again during the replay.
+type ReplayChannel struct {
+	net.BroadcastChannel
+
+	replaying int32
+}
+
+// NewReplayChannel wraps the given broadcast channel with a channel which can
+// be muted during the replay.
+func NewReplayChannel(channel net.BroadcastChannel) *ReplayChannel {
+	return &ReplayChannel{BroadcastChannel: channel}
+}
+
+// Send sends the message to the underlying broadcast channel unless the state
+// machine is replaying completed states.
+func (rc *ReplayChannel) Send(
+	ctx context.Context,
+	message net.TaggedMarshaler,
+) error {
+	if rc.Replaying() {
+		return nil
+	}
+
+	return rc.BroadcastChannel.Send(ctx, message)
+}
+
+// ReportMisbehavior reports the sender to the underlying broadcast channel
+// if it supports reporting misbehavior.
+func (rc *ReplayChannel) ReportMisbehavior(sender net.TransportIdentifier) {
+	if reporter, ok := rc.BroadcastChannel.(net.MisbehaviorReporter); ok {
+		reporter.ReportMisbehavior(sender)
+	}
+}
+
+// Replaying returns true if the state machine is replaying states completed
+// before the checkpoint was taken. States should not interact with other peers
+// in any other way while replaying, as they already did it before the restart.
+func (rc *ReplayChannel) Replaying() bool {
+	return atomic.LoadInt32(&rc.replaying) == 1
+}
+
+func (rc *ReplayChannel) setReplaying(replaying bool) {
+	if replaying {
+		atomic.StoreInt32(&rc.replaying, 1)
+	} else {
+		atomic.StoreInt32(&rc.replaying, 0)
+	}
+}
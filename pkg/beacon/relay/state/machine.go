@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/keep-network/keep-core/pkg/chain"
+	"github.com/keep-network/keep-core/pkg/journal"
 	"github.com/keep-network/keep-core/pkg/net"
 )
 
@@ -21,33 +22,208 @@ const receiveBuffer = 128
 type Machine struct {
 	channel      net.BroadcastChannel
 	blockCounter chain.BlockCounter
+	journal      *journal.Journal
 	initialState State // first state from which execution starts
+
+	checkpointHandler     func(*Checkpoint)
+	stateCompletedHandler func(State)
 }
 
 // NewMachine returns a new state machine. It requires a broadcast channel and
 // an initialization function for the channel to be able to perform interactions.
+// State transitions are recorded in the given journal.
 func NewMachine(
 	channel net.BroadcastChannel,
 	blockCounter chain.BlockCounter,
+	journal *journal.Journal,
 	initialState State,
 ) *Machine {
 	return &Machine{
 		channel:      channel,
 		blockCounter: blockCounter,
+		journal:      journal,
 		initialState: initialState,
 	}
 }
 
+// OnCheckpoint registers a handler called each time a state completes, with
+// the checkpoint allowing to resume the execution from that moment. Messages
+// received by the machine are recorded only if the handler is registered. The
+// handler is called synchronously and the machine does not transition to the
+// next state before the handler returns.
+func (m *Machine) OnCheckpoint(handler func(*Checkpoint)) {
+	m.checkpointHandler = handler
+}
+
+// OnStateCompleted registers a handler called each time a state completes,
+// with the completed state. The handler is called synchronously, after the
+// state has been left, and the machine does not transition to the next state
+// before the handler returns. States replayed when the execution is resumed
+// from a checkpoint are reported as well, once their recorded messages are
+// replayed, so that the handler observes the same sequence of completed states
+// as in an uninterrupted execution.
+func (m *Machine) OnStateCompleted(handler func(State)) {
+	m.stateCompletedHandler = handler
+}
+
 // Execute state machine starting with initial state up to finalization. It
-// requires the broadcast channel to be pre-initialized.
-func (m *Machine) Execute(startBlockHeight uint64) (State, uint64, error) {
+// requires the broadcast channel to be pre-initialized. The execution is
+// aborted with an error as soon as the provided context is done.
+func (m *Machine) Execute(
+	parentCtx context.Context,
+	startBlockHeight uint64,
+) (State, uint64, error) {
+	return m.execute(
+		parentCtx,
+		m.initialState,
+		startBlockHeight,
+		&Checkpoint{EndBlockHeight: startBlockHeight},
+		false,
+	)
+}
+
+// Resume resumes the state machine execution from the given checkpoint. First,
+// all the states completed before the checkpoint was taken are replayed with
+// the recorded messages. The machine must have been created with
+// a ReplayChannel used by all the states so that no messages are sent during
+// the replay. Unmarshalers are used to recover recorded messages. Once the
+// replay completes, the execution continues from the first state not completed
+// before the checkpoint. If the window of the first state exchanging messages
+// is already closed, the execution is aborted with an error, as the member
+// could not catch up with the rest of the group.
+func (m *Machine) Resume(
+	parentCtx context.Context,
+	checkpoint *Checkpoint,
+	unmarshalers ...func() net.TaggedUnmarshaler,
+) (State, uint64, error) {
+	replayChannel, ok := m.channel.(*ReplayChannel)
+	if !ok {
+		return nil, 0, fmt.Errorf(
+			"state machine channel does not support the replay",
+		)
+	}
+
+	unmarshalersByType := make(map[string]func() net.TaggedUnmarshaler)
+	for _, unmarshaler := range unmarshalers {
+		unmarshalersByType[unmarshaler().Type()] = unmarshaler
+	}
+
+	currentState := m.initialState
+
+	logger.Infof(
+		"[member:%v,channel:%s] replaying [%v] completed states",
+		currentState.MemberIndex(),
+		m.channel.Name()[:5],
+		checkpoint.CompletedStates,
+	)
+
+	replayCtx, cancelReplayCtx := context.WithCancel(parentCtx)
+	defer cancelReplayCtx()
+
+	replayChannel.setReplaying(true)
+	defer replayChannel.setReplaying(false)
+
+	for stateIndex := 0; stateIndex < checkpoint.CompletedStates; stateIndex++ {
+		err := currentState.Initiate(replayCtx)
+		if err != nil {
+			return nil, 0, fmt.Errorf(
+				"failed to replay state [%T]: [%v]",
+				currentState,
+				err,
+			)
+		}
+
+		for _, recorded := range checkpoint.Messages {
+			if recorded.StateIndex != stateIndex {
+				continue
+			}
+
+			message, err := recoverMessage(recorded, unmarshalersByType)
+			if err != nil {
+				return nil, 0, fmt.Errorf(
+					"failed to replay message for state [%T]: [%v]",
+					currentState,
+					err,
+				)
+			}
+
+			err = currentState.Receive(message)
+			if err != nil {
+				logger.Errorf(
+					"[member:%v,channel:%s,state:%T] failed to replay a message: [%v]",
+					currentState.MemberIndex(),
+					m.channel.Name()[:5],
+					currentState,
+					err,
+				)
+			}
+		}
+
+		nextState := currentState.Next()
+
+		if m.stateCompletedHandler != nil {
+			m.stateCompletedHandler(currentState)
+		}
+
+		if nextState == nil {
+			return currentState, checkpoint.EndBlockHeight, nil
+		}
+
+		currentState = nextState
+	}
+
+	replayChannel.setReplaying(false)
+
+	return m.execute(
+		parentCtx,
+		currentState,
+		checkpoint.EndBlockHeight,
+		checkpoint.copy(),
+		true,
+	)
+}
+
+func recoverMessage(
+	recorded *RecordedMessage,
+	unmarshalers map[string]func() net.TaggedUnmarshaler,
+) (net.Message, error) {
+	unmarshaler, ok := unmarshalers[recorded.Type]
+	if !ok {
+		return nil, fmt.Errorf(
+			"no unmarshaler for message type [%v]",
+			recorded.Type,
+		)
+	}
+
+	payload := unmarshaler()
+	if err := payload.Unmarshal(recorded.Payload); err != nil {
+		return nil, fmt.Errorf(
+			"could not unmarshal message of type [%v]: [%v]",
+			recorded.Type,
+			err,
+		)
+	}
+
+	return &replayedMessage{
+		messageType:     recorded.Type,
+		senderPublicKey: recorded.SenderPublicKey,
+		payload:         payload,
+	}, nil
+}
+
+func (m *Machine) execute(
+	parentCtx context.Context,
+	currentState State,
+	startBlockHeight uint64,
+	progress *Checkpoint,
+	resumed bool,
+) (State, uint64, error) {
 	recvChan := make(chan net.Message, receiveBuffer)
 	handler := func(msg net.Message) {
 		recvChan <- msg
 	}
 
-	currentState := m.initialState
-	ctx, cancelCtx := context.WithCancel(context.Background())
+	ctx, cancelCtx := context.WithCancel(parentCtx)
 	m.channel.Recv(ctx, handler)
 
 	logger.Infof(
@@ -56,13 +232,32 @@ func (m *Machine) Execute(startBlockHeight uint64) (State, uint64, error) {
 		m.channel.Name()[:5],
 		startBlockHeight,
 	)
-	err := m.blockCounter.WaitForBlockHeight(startBlockHeight)
+	startBlockWaiter, err := m.blockCounter.BlockHeightWaiter(startBlockHeight)
 	if err != nil {
+		cancelCtx()
 		return nil, 0, fmt.Errorf("failed to wait for the execution start block")
 	}
 
+	select {
+	case <-startBlockWaiter:
+	case <-parentCtx.Done():
+		cancelCtx()
+		return nil, 0, fmt.Errorf(
+			"execution cancelled before the start block: [%v]",
+			parentCtx.Err(),
+		)
+	}
+
 	lastStateEndBlockHeight := startBlockHeight
 
+	if resumed {
+		resumed, err = m.checkResumedOnTime(currentState, lastStateEndBlockHeight)
+		if err != nil {
+			cancelCtx()
+			return nil, 0, err
+		}
+	}
+
 	blockWaiter, err := stateTransition(
 		ctx,
 		currentState,
@@ -74,10 +269,15 @@ func (m *Machine) Execute(startBlockHeight uint64) (State, uint64, error) {
 		cancelCtx()
 		return nil, 0, err
 	}
+	m.journalTransition(currentState, lastStateEndBlockHeight)
 
 	for {
 		select {
 		case msg := <-recvChan:
+			if m.checkpointHandler != nil {
+				m.record(progress, msg)
+			}
+
 			err := currentState.Receive(msg)
 			if err != nil {
 				logger.Errorf(
@@ -92,6 +292,18 @@ func (m *Machine) Execute(startBlockHeight uint64) (State, uint64, error) {
 		case lastStateEndBlockHeight := <-blockWaiter:
 			cancelCtx()
 			nextState := currentState.Next()
+
+			if m.stateCompletedHandler != nil {
+				m.stateCompletedHandler(currentState)
+			}
+
+			if m.checkpointHandler != nil {
+				progress.CompletedStates++
+				progress.EndBlockHeight = lastStateEndBlockHeight
+				progress.Finished = nextState == nil
+				m.checkpointHandler(progress.copy())
+			}
+
 			if nextState == nil {
 				logger.Infof(
 					"[member:%v,channel:%s,state:%T] reached final state at block: [%v]",
@@ -104,9 +316,20 @@ func (m *Machine) Execute(startBlockHeight uint64) (State, uint64, error) {
 			}
 
 			currentState = nextState
-			ctx, cancelCtx = context.WithCancel(context.Background())
+			ctx, cancelCtx = context.WithCancel(parentCtx)
 			m.channel.Recv(ctx, handler)
 
+			if resumed {
+				resumed, err = m.checkResumedOnTime(
+					currentState,
+					lastStateEndBlockHeight,
+				)
+				if err != nil {
+					cancelCtx()
+					return nil, 0, err
+				}
+			}
+
 			blockWaiter, err = stateTransition(
 				ctx,
 				currentState,
@@ -118,12 +341,124 @@ func (m *Machine) Execute(startBlockHeight uint64) (State, uint64, error) {
 				cancelCtx()
 				return nil, 0, err
 			}
+			m.journalTransition(currentState, lastStateEndBlockHeight)
 
 			continue
+
+		case <-parentCtx.Done():
+			cancelCtx()
+			logger.Warningf(
+				"[member:%v,channel:%s,state:%T] execution cancelled",
+				currentState.MemberIndex(),
+				m.channel.Name()[:5],
+				currentState,
+			)
+			return nil, 0, fmt.Errorf(
+				"execution cancelled in state [%T]: [%v]",
+				currentState,
+				parentCtx.Err(),
+			)
 		}
 	}
 }
 
+// journalTransition records the transition to the given state in the journal.
+// The state is initiated after the delay following the end of the previous
+// state. The state machine executes DKG and the broadcast channel used for
+// DKG is named after the group selection seed. Transitions to states replayed
+// when the execution is resumed are already in the journal and are not
+// recorded again.
+func (m *Machine) journalTransition(
+	currentState State,
+	lastStateEndBlockHeight uint64,
+) {
+	if !m.journal.Enabled() {
+		return
+	}
+
+	m.journal.Record(&journal.Entry{
+		Type:        journal.StateTransition,
+		BlockNumber: lastStateEndBlockHeight + currentState.DelayBlocks(),
+		Seed:        m.channel.Name(),
+		MemberIndex: uint8(currentState.MemberIndex()),
+		Phase:       fmt.Sprintf("%T", currentState),
+	})
+}
+
+// record adds the received message to the execution progress so that it can
+// be replayed when the execution is resumed from a checkpoint.
+func (m *Machine) record(progress *Checkpoint, msg net.Message) {
+	marshaler, ok := msg.Payload().(net.TaggedMarshaler)
+	if !ok {
+		logger.Warningf(
+			"could not record message of type [%v]; payload is not marshalable",
+			msg.Type(),
+		)
+		return
+	}
+
+	payload, err := marshaler.Marshal()
+	if err != nil {
+		logger.Warningf(
+			"could not record message of type [%v]: [%v]",
+			msg.Type(),
+			err,
+		)
+		return
+	}
+
+	progress.Messages = append(progress.Messages, &RecordedMessage{
+		StateIndex:      progress.CompletedStates,
+		Type:            marshaler.Type(),
+		SenderPublicKey: msg.SenderPublicKey(),
+		Payload:         payload,
+	})
+}
+
+// checkResumedOnTime checks whether the execution resumed from a checkpoint
+// can still catch up with the rest of the group, that is, whether the window
+// of the given state is still open. States which do not exchange any messages
+// can be always executed. Returns true if the check should be performed also
+// for the next state, which is the case when the current state does not
+// exchange any messages.
+func (m *Machine) checkResumedOnTime(
+	currentState State,
+	lastStateEndBlockHeight uint64,
+) (bool, error) {
+	if currentState.ActiveBlocks() == SilentStateActiveBlocks {
+		return true, nil
+	}
+
+	currentBlock, err := m.blockCounter.CurrentBlock()
+	if err != nil {
+		return false, fmt.Errorf("could not get the current block: [%v]", err)
+	}
+
+	stateEndBlockHeight := lastStateEndBlockHeight +
+		currentState.DelayBlocks() +
+		currentState.ActiveBlocks()
+
+	if currentBlock >= stateEndBlockHeight {
+		return false, fmt.Errorf(
+			"could not resume the execution; window of state [%T] "+
+				"closed at block [%v] and the current block is [%v]",
+			currentState,
+			stateEndBlockHeight,
+			currentBlock,
+		)
+	}
+
+	logger.Infof(
+		"[member:%v,channel:%s,state:%T] resuming the execution at block [%v]",
+		currentState.MemberIndex(),
+		m.channel.Name()[:5],
+		currentState,
+		currentBlock,
+	)
+
+	return false, nil
+}
+
 func stateTransition(
 	ctx context.Context,
 	currentState State,
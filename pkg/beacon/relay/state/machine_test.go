@@ -54,13 +54,36 @@ func TestExecute(t *testing.T) {
 		channel:     channel,
 	}
 
-	stateMachine := NewMachine(channel, blockCounter, initialState)
+	stateMachine := NewMachine(channel, blockCounter, nil, initialState)
+
+	var completedStates []string
+	stateMachine.OnStateCompleted(func(completedState State) {
+		completedStates = append(
+			completedStates,
+			fmt.Sprintf("%T", completedState),
+		)
+	})
 
-	finalState, endBlockHeight, err := stateMachine.Execute(1)
+	finalState, endBlockHeight, err := stateMachine.Execute(context.Background(), 1)
 	if err != nil {
 		t.Errorf("unexpected error [%v]", err)
 	}
 
+	expectedCompletedStates := []string{
+		"state.testState1",
+		"*state.testState2",
+		"*state.testState3",
+		"*state.testState4",
+		"*state.testState5",
+	}
+	if !reflect.DeepEqual(expectedCompletedStates, completedStates) {
+		t.Errorf(
+			"unexpected completed states\nexpected: %v\nactual:   %v\n",
+			expectedCompletedStates,
+			completedStates,
+		)
+	}
+
 	if _, ok := finalState.(*testState5); !ok {
 		t.Errorf("state is not final [%v]", finalState)
 	}
@@ -93,6 +116,316 @@ func TestExecute(t *testing.T) {
 	}
 }
 
+func TestExecute_Cancelled(t *testing.T) {
+	testLog = make(map[uint64][]string)
+
+	localChain := chainLocal.Connect(10, 5, big.NewInt(200))
+	blockCounter, _ = localChain.BlockCounter()
+	provider := netLocal.Connect()
+	channel, err := provider.BroadcastChannelFor("cancellation_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	channel.SetUnmarshaler(func() net.TaggedUnmarshaler {
+		return &TestMessage{}
+	})
+
+	initialState := testState1{
+		memberIndex: group.MemberIndex(1),
+		channel:     channel,
+	}
+
+	stateMachine := NewMachine(channel, blockCounter, nil, initialState)
+
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	go func() {
+		blockCounter.WaitForBlockHeight(4)
+		cancelCtx()
+	}()
+
+	finalState, _, err := stateMachine.Execute(ctx, 1)
+	if err == nil {
+		t.Fatal("expected execution error")
+	}
+
+	if finalState != nil {
+		t.Errorf("unexpected final state [%v]", finalState)
+	}
+
+	for block, entries := range testLog {
+		if block > 4 {
+			t.Errorf(
+				"unexpected state activity after cancellation at block [%v]: [%v]",
+				block,
+				entries,
+			)
+		}
+	}
+}
+
+func TestResume(t *testing.T) {
+	testLog = make(map[uint64][]string)
+
+	localChain := chainLocal.Connect(10, 5, big.NewInt(200))
+	blockCounter, _ = localChain.BlockCounter()
+	provider := netLocal.Connect()
+	channel, err := provider.BroadcastChannelFor("checkpoint_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func(blockCounter chain.BlockCounter) {
+		blockCounter.WaitForBlockHeight(1)
+		ctx, cancel := context.WithCancel(context.Background())
+		channel.Send(ctx, &TestMessage{"message_1"})
+		cancel()
+
+		blockCounter.WaitForBlockHeight(4)
+		ctx, cancel = context.WithCancel(context.Background())
+		channel.Send(ctx, &TestMessage{"message_2"})
+		cancel()
+	}(blockCounter)
+
+	channel.SetUnmarshaler(func() net.TaggedUnmarshaler {
+		return &TestMessage{}
+	})
+
+	replayChannel := NewReplayChannel(channel)
+
+	stateMachine := NewMachine(
+		replayChannel,
+		blockCounter,
+		nil,
+		testState1{
+			memberIndex: group.MemberIndex(1),
+			channel:     replayChannel,
+		},
+	)
+
+	var checkpoints []*Checkpoint
+	stateMachine.OnCheckpoint(func(checkpoint *Checkpoint) {
+		checkpoints = append(checkpoints, checkpoint)
+	})
+
+	_, _, err = stateMachine.Execute(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error [%v]", err)
+	}
+
+	if len(checkpoints) != 5 {
+		t.Fatalf("unexpected number of checkpoints [%v]", len(checkpoints))
+	}
+
+	if !checkpoints[4].Finished {
+		t.Errorf("last checkpoint should be marked as finished")
+	}
+
+	// Checkpoint taken after testState1 and testState2 completed.
+	checkpoint := checkpoints[1]
+	if checkpoint.CompletedStates != 2 {
+		t.Fatalf("unexpected completed states [%v]", checkpoint.CompletedStates)
+	}
+	if checkpoint.EndBlockHeight != 5 {
+		t.Fatalf("unexpected end block [%v]", checkpoint.EndBlockHeight)
+	}
+
+	// Resume the execution on a fresh chain with the block counter starting
+	// from zero again so that the window of the next state is still open.
+	testLog = make(map[uint64][]string)
+
+	localChain = chainLocal.Connect(10, 5, big.NewInt(200))
+	blockCounter, _ = localChain.BlockCounter()
+	channel, err = provider.BroadcastChannelFor("checkpoint_resume_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func(blockCounter chain.BlockCounter) {
+		blockCounter.WaitForBlockHeight(7)
+		ctx, cancel := context.WithCancel(context.Background())
+		channel.Send(ctx, &TestMessage{"message_3"})
+		cancel()
+	}(blockCounter)
+
+	channel.SetUnmarshaler(func() net.TaggedUnmarshaler {
+		return &TestMessage{}
+	})
+
+	replayChannel = NewReplayChannel(channel)
+
+	stateMachine = NewMachine(
+		replayChannel,
+		blockCounter,
+		nil,
+		testState1{
+			memberIndex: group.MemberIndex(1),
+			channel:     replayChannel,
+		},
+	)
+
+	var completedStates []string
+	stateMachine.OnStateCompleted(func(completedState State) {
+		completedStates = append(
+			completedStates,
+			fmt.Sprintf("%T", completedState),
+		)
+	})
+
+	finalState, endBlockHeight, err := stateMachine.Resume(
+		context.Background(),
+		checkpoint,
+		func() net.TaggedUnmarshaler { return &TestMessage{} },
+	)
+	if err != nil {
+		t.Fatalf("unexpected error [%v]", err)
+	}
+
+	// Replayed states are reported as completed before the live ones.
+	expectedCompletedStates := []string{
+		"state.testState1",
+		"*state.testState2",
+		"*state.testState3",
+		"*state.testState4",
+		"*state.testState5",
+	}
+	if !reflect.DeepEqual(expectedCompletedStates, completedStates) {
+		t.Errorf(
+			"unexpected completed states\nexpected: %v\nactual:   %v\n",
+			expectedCompletedStates,
+			completedStates,
+		)
+	}
+
+	if _, ok := finalState.(*testState5); !ok {
+		t.Errorf("state is not final [%v]", finalState)
+	}
+
+	if endBlockHeight != 8 {
+		t.Errorf("unexpected end block [%v]", endBlockHeight)
+	}
+
+	replayedTestLog := make([]string, 0)
+	liveTestLog := make(map[uint64][]string)
+	for block, entries := range testLog {
+		if block < 5 {
+			replayedTestLog = append(replayedTestLog, entries...)
+		} else {
+			liveTestLog[block] = entries
+		}
+	}
+
+	expectedReplayedTestLog := []string{
+		"1-state.testState1-initiate",
+		"1-state.testState1-receive-message_1",
+		"1-state.testState2-initiate",
+		"1-state.testState2-receive-message_2",
+	}
+	if !reflect.DeepEqual(expectedReplayedTestLog, replayedTestLog) {
+		t.Errorf(
+			"\nexpected: %v\nactual:   %v\n",
+			expectedReplayedTestLog,
+			replayedTestLog,
+		)
+	}
+
+	expectedLiveTestLog := map[uint64][]string{
+		6: []string{
+			"1-state.testState3-initiate",
+			"1-state.testState4-initiate",
+		},
+		7: []string{
+			"1-state.testState4-receive-message_3",
+		},
+		8: []string{
+			"1-state.testState5-initiate",
+		},
+	}
+	if !reflect.DeepEqual(expectedLiveTestLog, liveTestLog) {
+		t.Errorf(
+			"\nexpected: %v\nactual:   %v\n",
+			expectedLiveTestLog,
+			liveTestLog,
+		)
+	}
+}
+
+func TestResume_WindowClosed(t *testing.T) {
+	testLog = make(map[uint64][]string)
+
+	localChain := chainLocal.Connect(10, 5, big.NewInt(200))
+	blockCounter, _ = localChain.BlockCounter()
+	provider := netLocal.Connect()
+	channel, err := provider.BroadcastChannelFor("checkpoint_late_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replayChannel := NewReplayChannel(channel)
+
+	stateMachine := NewMachine(
+		replayChannel,
+		blockCounter,
+		nil,
+		testState1{
+			memberIndex: group.MemberIndex(1),
+			channel:     replayChannel,
+		},
+	)
+
+	// testState3 is silent so the window of testState4 is checked; testState3
+	// ends at block 5 + 1 and the window of testState4 closes at block 6 + 2.
+	err = blockCounter.WaitForBlockHeight(9)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err = stateMachine.Resume(
+		context.Background(),
+		&Checkpoint{CompletedStates: 2, EndBlockHeight: 5},
+		func() net.TaggedUnmarshaler { return &TestMessage{} },
+	)
+	if err == nil {
+		t.Fatal("expected execution error")
+	}
+}
+
+func TestResume_ChannelNotReplayable(t *testing.T) {
+	localChain := chainLocal.Connect(10, 5, big.NewInt(200))
+	blockCounter, _ = localChain.BlockCounter()
+	provider := netLocal.Connect()
+	channel, err := provider.BroadcastChannelFor("checkpoint_channel_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stateMachine := NewMachine(
+		channel,
+		blockCounter,
+		nil,
+		testState1{
+			memberIndex: group.MemberIndex(1),
+			channel:     channel,
+		},
+	)
+
+	_, _, err = stateMachine.Resume(
+		context.Background(),
+		&Checkpoint{CompletedStates: 2, EndBlockHeight: 5},
+	)
+
+	expectedError := fmt.Errorf(
+		"state machine channel does not support the replay",
+	)
+	if !reflect.DeepEqual(expectedError, err) {
+		t.Errorf(
+			"unexpected error\nexpected: %v\nactual:   %v\n",
+			expectedError,
+			err,
+		)
+	}
+}
+
 func addToTestLog(testState State, functionName string) {
 	currentBlock, _ := blockCounter.CurrentBlock()
 	testLog[currentBlock] = append(
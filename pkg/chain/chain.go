@@ -35,6 +35,15 @@ type BlockCounter interface {
 	WatchBlocks(ctx context.Context) <-chan uint64
 }
 
+// BlockHashes is an interface that provides hashes of blocks of the chain
+// currently considered canonical. A block which hash has changed since it was
+// last read has been replaced by a chain reorganization.
+type BlockHashes interface {
+	// BlockHash returns the hash of the block with the given number. It
+	// returns an error if the block has not been mined yet.
+	BlockHash(blockNumber uint64) ([]byte, error)
+}
+
 // StakeMonitor is an interface that provides ability to check and monitor
 // the stake for the provided address.
 type StakeMonitor interface {
@@ -91,6 +100,7 @@ type Signing interface {
 // operator functionality needed for Keep network interactions.
 type Handle interface {
 	BlockCounter() (BlockCounter, error)
+	BlockHashes() (BlockHashes, error)
 	StakeMonitor() (StakeMonitor, error)
 	ThresholdRelay() relaychain.Interface
 	Signing() Signing
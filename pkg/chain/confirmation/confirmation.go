@@ -0,0 +1,307 @@
+// Package confirmation protects the client from acting on chain events which
+// are removed from the chain by a chain reorganization. Events are delivered
+// either once they are confirmed by the configured number of blocks, or as
+// soon as they are seen and retracted if their block is removed before they
+// are confirmed.
+package confirmation
+
+import (
+	"bytes"
+	"context"
+	"sync"
+
+	"github.com/ipfs/go-log"
+
+	"github.com/keep-network/keep-core/pkg/chain"
+	"github.com/keep-network/keep-core/pkg/journal"
+)
+
+var logger = log.Logger("keep-confirmation")
+
+// Config stores configuration of chain event confirmations.
+type Config struct {
+	// Depth is the number of blocks which have to be mined on top of the
+	// block of an event for the event to be confirmed. Zero disables
+	// confirmations and events are delivered as soon as they are seen.
+	Depth int
+
+	// DeliverEarly makes events delivered as soon as they are seen instead
+	// of once they are confirmed. Events whose block is removed by a chain
+	// reorganization before they are confirmed are retracted.
+	DeliverEarly bool
+}
+
+// Confirmer delivers chain events to their handlers according to the
+// confirmation config.
+type Confirmer struct {
+	config       Config
+	blockCounter chain.BlockCounter
+	blockHashes  chain.BlockHashes
+	journal      *journal.Journal
+
+	pendingMutex sync.Mutex
+	pending      map[pendingEventID][]*pendingEvent
+}
+
+// pendingEventID identifies events delivered but not confirmed yet by the name
+// of the event and the hash of the block the event has been emitted in.
+type pendingEventID struct {
+	eventName string
+	blockHash string
+}
+
+// pendingEvent is an event delivered but not confirmed yet.
+type pendingEvent struct {
+	retract func()
+}
+
+// NewConfirmer creates a confirmer with the given config, waiting for blocks
+// with the given block counter and detecting chain reorganizations by
+// comparing the hash of the block of an event with the hash of the block with
+// the same number at the time the event is confirmed. Chain reorganizations
+// are recorded in the given journal.
+func NewConfirmer(
+	config Config,
+	blockCounter chain.BlockCounter,
+	blockHashes chain.BlockHashes,
+	journal *journal.Journal,
+) *Confirmer {
+	return &Confirmer{
+		config:       config,
+		blockCounter: blockCounter,
+		blockHashes:  blockHashes,
+		journal:      journal,
+		pending:      make(map[pendingEventID][]*pendingEvent),
+	}
+}
+
+// Deliver passes the event emitted in the block with the given number and
+// hash to the handler. The handler is called with a context derived from the
+// provided one. If the chain does not provide hashes of blocks of events, nil
+// hash should be passed and the hash is read when the event is delivered.
+//
+// If the event is delivered early, the handler is called synchronously and
+// the context passed to it is cancelled if the event gets retracted, which
+// aborts all work started by the handler. Otherwise, the handler is called in
+// a separate goroutine once the event is confirmed and it is not called at
+// all if the block of the event is removed from the chain.
+//
+// If the state of the chain could not be read, the event is considered
+// confirmed.
+func (c *Confirmer) Deliver(
+	ctx context.Context,
+	eventName string,
+	blockNumber uint64,
+	blockHash []byte,
+	handler func(ctx context.Context),
+) {
+	if c.config.Depth <= 0 {
+		handler(ctx)
+		return
+	}
+
+	if blockHash == nil {
+		var err error
+		blockHash, err = c.blockHashes.BlockHash(blockNumber)
+		if err != nil {
+			logger.Warningf(
+				"could not read hash of block [%v] of [%v] event; "+
+					"removal of the block will not be detected: [%v]",
+				blockNumber,
+				eventName,
+				err,
+			)
+		}
+	}
+
+	// The context of a confirmed event is released along with the
+	// provided context.
+	eventCtx, cancelEvent := context.WithCancel(ctx)
+
+	done := c.addPending(eventName, blockHash, cancelEvent)
+
+	if !c.config.DeliverEarly {
+		go func() {
+			defer done()
+
+			if c.waitForConfirmation(eventCtx, eventName, blockNumber, blockHash) {
+				handler(ctx)
+			}
+		}()
+		return
+	}
+
+	handler(eventCtx)
+
+	go func() {
+		defer done()
+
+		if !c.waitForConfirmation(eventCtx, eventName, blockNumber, blockHash) {
+			cancelEvent()
+		}
+	}()
+}
+
+// Retract retracts the event emitted in the block with the given number and
+// hash reported as removed from the chain by a chain reorganization. If the
+// event has not been confirmed yet, it is not delivered or, if it has been
+// delivered early, the context passed to its handler is cancelled. Events
+// removed after they have been confirmed are not retracted.
+func (c *Confirmer) Retract(
+	eventName string,
+	blockNumber uint64,
+	blockHash []byte,
+) {
+	if c.config.Depth <= 0 {
+		logger.Warningf(
+			"[%v] event from block [%v] has been removed by a chain "+
+				"reorganization; confirmations are disabled so the "+
+				"event is not retracted",
+			eventName,
+			blockNumber,
+		)
+		return
+	}
+
+	id := pendingEventID{eventName, string(blockHash)}
+
+	c.pendingMutex.Lock()
+	pending := c.pending[id]
+	delete(c.pending, id)
+	c.pendingMutex.Unlock()
+
+	if len(pending) == 0 {
+		logger.Warningf(
+			"[%v] event from block [%v] removed by a chain reorganization "+
+				"is not pending confirmation",
+			eventName,
+			blockNumber,
+		)
+		return
+	}
+
+	c.reportRemoval(eventName, blockNumber)
+
+	for _, event := range pending {
+		event.retract()
+	}
+}
+
+// addPending registers the event as delivered but not confirmed yet. Returns
+// the function which has to be called once the event is confirmed or dropped.
+func (c *Confirmer) addPending(
+	eventName string,
+	blockHash []byte,
+	retract func(),
+) func() {
+	if blockHash == nil {
+		return func() {}
+	}
+
+	id := pendingEventID{eventName, string(blockHash)}
+	event := &pendingEvent{retract}
+
+	c.pendingMutex.Lock()
+	c.pending[id] = append(c.pending[id], event)
+	c.pendingMutex.Unlock()
+
+	return func() {
+		c.pendingMutex.Lock()
+		defer c.pendingMutex.Unlock()
+
+		remaining := c.pending[id][:0]
+		for _, pending := range c.pending[id] {
+			if pending != event {
+				remaining = append(remaining, pending)
+			}
+		}
+
+		if len(remaining) == 0 {
+			delete(c.pending, id)
+		} else {
+			c.pending[id] = remaining
+		}
+	}
+}
+
+// waitForConfirmation waits until the block of the event is confirmed by the
+// configured number of blocks. Returns true if the block is still in the
+// chain at that time and false if it has been removed or the context is done
+// before the event is confirmed.
+func (c *Confirmer) waitForConfirmation(
+	ctx context.Context,
+	eventName string,
+	blockNumber uint64,
+	blockHash []byte,
+) bool {
+	confirmationBlock := blockNumber + uint64(c.config.Depth)
+
+	waiter, err := c.blockCounter.BlockHeightWaiter(confirmationBlock)
+	if err != nil {
+		logger.Warningf(
+			"could not wait for confirmation of [%v] event from block [%v]: "+
+				"[%v]; considering the event confirmed",
+			eventName,
+			blockNumber,
+			err,
+		)
+		return true
+	}
+
+	select {
+	case <-waiter:
+	case <-ctx.Done():
+		return false
+	}
+
+	if blockHash == nil {
+		return true
+	}
+
+	currentBlockHash, err := c.blockHashes.BlockHash(blockNumber)
+	if err != nil {
+		logger.Warningf(
+			"could not confirm [%v] event from block [%v]: [%v]; "+
+				"considering the event confirmed",
+			eventName,
+			blockNumber,
+			err,
+		)
+		return true
+	}
+
+	if bytes.Equal(currentBlockHash, blockHash) {
+		return true
+	}
+
+	c.reportRemoval(eventName, blockNumber)
+
+	return false
+}
+
+func (c *Confirmer) reportRemoval(eventName string, blockNumber uint64) {
+	if c.config.DeliverEarly {
+		logger.Warningf(
+			"block [%v] has been removed by a chain reorganization; "+
+				"retracting [%v] event",
+			blockNumber,
+			eventName,
+		)
+	} else {
+		logger.Warningf(
+			"block [%v] has been removed by a chain reorganization; "+
+				"dropping [%v] event",
+			blockNumber,
+			eventName,
+		)
+	}
+
+	c.journal.Record(&journal.Entry{
+		Type:        journal.ChainReorganization,
+		BlockNumber: blockNumber,
+		Details: map[string]interface{}{
+			"event":     eventName,
+			"retracted": c.config.DeliverEarly,
+		},
+	})
+}
@@ -0,0 +1,256 @@
+package confirmation
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/keep-network/keep-core/pkg/chain/local"
+)
+
+func TestDeliverWithoutConfirmations(t *testing.T) {
+	_, confirmer := newTestConfirmer(t, Config{})
+
+	delivered := false
+	confirmer.Deliver(
+		context.Background(),
+		"Test",
+		0,
+		nil,
+		func(ctx context.Context) { delivered = true },
+	)
+
+	if !delivered {
+		t.Errorf("event has not been delivered")
+	}
+}
+
+func TestDeliverConfirmedEvent(t *testing.T) {
+	localChain, confirmer := newTestConfirmer(t, Config{Depth: 2})
+	blockCounter, _ := localChain.BlockCounter()
+
+	eventBlock, _ := blockCounter.CurrentBlock()
+
+	deliveryBlock := make(chan uint64, 1)
+	confirmer.Deliver(
+		context.Background(),
+		"Test",
+		eventBlock,
+		nil,
+		func(ctx context.Context) {
+			currentBlock, _ := blockCounter.CurrentBlock()
+			deliveryBlock <- currentBlock
+		},
+	)
+
+	select {
+	case block := <-deliveryBlock:
+		if block < eventBlock+2 {
+			t.Errorf(
+				"event delivered before confirmation\n"+
+					"expected block: [>= %v]\nactual block:   [%v]",
+				eventBlock+2,
+				block,
+			)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("event has not been delivered")
+	}
+}
+
+func TestDropRemovedEvent(t *testing.T) {
+	localChain, confirmer := newTestConfirmer(t, Config{Depth: 1})
+	blockCounter, _ := localChain.BlockCounter()
+
+	eventBlock, _ := blockCounter.CurrentBlock()
+
+	delivered := make(chan struct{}, 1)
+	confirmer.Deliver(
+		context.Background(),
+		"Test",
+		eventBlock,
+		nil,
+		func(ctx context.Context) { delivered <- struct{}{} },
+	)
+
+	localChain.SimulateReorg(2)
+
+	if err := blockCounter.WaitForBlockHeight(eventBlock + 2); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-delivered:
+		t.Errorf("removed event has been delivered")
+	default:
+	}
+}
+
+func TestRetractRemovedEvent(t *testing.T) {
+	localChain, confirmer := newTestConfirmer(
+		t,
+		Config{Depth: 1, DeliverEarly: true},
+	)
+	blockCounter, _ := localChain.BlockCounter()
+
+	eventBlock, _ := blockCounter.CurrentBlock()
+
+	var eventCtx context.Context
+	confirmer.Deliver(
+		context.Background(),
+		"Test",
+		eventBlock,
+		nil,
+		func(ctx context.Context) { eventCtx = ctx },
+	)
+
+	if eventCtx == nil {
+		t.Fatalf("event has not been delivered early")
+	}
+
+	localChain.SimulateReorg(2)
+
+	select {
+	case <-eventCtx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("removed event has not been retracted")
+	}
+}
+
+func TestDoNotRetractConfirmedEvent(t *testing.T) {
+	localChain, confirmer := newTestConfirmer(
+		t,
+		Config{Depth: 1, DeliverEarly: true},
+	)
+	blockCounter, _ := localChain.BlockCounter()
+
+	eventBlock, _ := blockCounter.CurrentBlock()
+
+	var eventCtx context.Context
+	confirmer.Deliver(
+		context.Background(),
+		"Test",
+		eventBlock,
+		nil,
+		func(ctx context.Context) { eventCtx = ctx },
+	)
+
+	if err := blockCounter.WaitForBlockHeight(eventBlock + 2); err != nil {
+		t.Fatal(err)
+	}
+
+	// Blocks removed after the confirmation do not retract the event.
+	localChain.SimulateReorg(3)
+
+	if eventCtx.Err() != nil {
+		t.Errorf("confirmed event has been retracted")
+	}
+}
+
+func TestDropEventFromReplacedBlock(t *testing.T) {
+	localChain, confirmer := newTestConfirmer(t, Config{Depth: 1})
+	blockCounter, _ := localChain.BlockCounter()
+
+	eventBlock, _ := blockCounter.CurrentBlock()
+
+	// The event has been emitted in a block replaced by a chain
+	// reorganization before the event has been delivered.
+	delivered := make(chan struct{}, 1)
+	confirmer.Deliver(
+		context.Background(),
+		"Test",
+		eventBlock,
+		[]byte{0x01, 0x02},
+		func(ctx context.Context) { delivered <- struct{}{} },
+	)
+
+	if err := blockCounter.WaitForBlockHeight(eventBlock + 2); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-delivered:
+		t.Errorf("event from replaced block has been delivered")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestRetractEventReportedAsRemoved(t *testing.T) {
+	localChain, confirmer := newTestConfirmer(
+		t,
+		Config{Depth: 5, DeliverEarly: true},
+	)
+	blockCounter, _ := localChain.BlockCounter()
+	blockHashes, _ := localChain.BlockHashes()
+
+	eventBlock, _ := blockCounter.CurrentBlock()
+	eventBlockHash, _ := blockHashes.BlockHash(eventBlock)
+
+	var eventCtx context.Context
+	confirmer.Deliver(
+		context.Background(),
+		"Test",
+		eventBlock,
+		eventBlockHash,
+		func(ctx context.Context) { eventCtx = ctx },
+	)
+
+	confirmer.Retract("Other", eventBlock, eventBlockHash)
+
+	if eventCtx.Err() != nil {
+		t.Fatalf("event has been retracted by removal of another event")
+	}
+
+	confirmer.Retract("Test", eventBlock, eventBlockHash)
+
+	if eventCtx.Err() == nil {
+		t.Errorf("removed event has not been retracted")
+	}
+}
+
+func TestDropEventReportedAsRemoved(t *testing.T) {
+	localChain, confirmer := newTestConfirmer(t, Config{Depth: 2})
+	blockCounter, _ := localChain.BlockCounter()
+	blockHashes, _ := localChain.BlockHashes()
+
+	eventBlock, _ := blockCounter.CurrentBlock()
+	eventBlockHash, _ := blockHashes.BlockHash(eventBlock)
+
+	delivered := make(chan struct{}, 1)
+	confirmer.Deliver(
+		context.Background(),
+		"Test",
+		eventBlock,
+		eventBlockHash,
+		func(ctx context.Context) { delivered <- struct{}{} },
+	)
+
+	confirmer.Retract("Test", eventBlock, eventBlockHash)
+
+	if err := blockCounter.WaitForBlockHeight(eventBlock + 3); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-delivered:
+		t.Errorf("removed event has been delivered")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func newTestConfirmer(t *testing.T, config Config) (local.Chain, *Confirmer) {
+	localChain := local.Connect(5, 3, big.NewInt(200))
+
+	blockCounter, err := localChain.BlockCounter()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blockHashes, err := localChain.BlockHashes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return localChain, NewConfirmer(config, blockCounter, blockHashes, nil)
+}
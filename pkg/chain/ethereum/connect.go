@@ -7,20 +7,19 @@ import (
 	"sync"
 	"time"
 
-	"github.com/keep-network/keep-common/pkg/rate"
-
 	"github.com/keep-network/keep-common/pkg/chain/ethlike"
 
 	relaychain "github.com/keep-network/keep-core/pkg/beacon/relay/chain"
 
-	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/keep-network/keep-common/pkg/chain/ethereum"
 	"github.com/keep-network/keep-common/pkg/chain/ethereum/ethutil"
+	"github.com/keep-network/keep-common/pkg/persistence"
 	"github.com/keep-network/keep-core/pkg/chain"
+	"github.com/keep-network/keep-core/pkg/chain/ethereum/signer"
 	"github.com/keep-network/keep-core/pkg/chain/gen/contract"
+	"github.com/keep-network/keep-core/pkg/metrics"
 )
 
 var (
@@ -38,16 +37,59 @@ var (
 	DefaultMaxGasPrice = big.NewInt(500000000000) // 500 Gwei
 )
 
+// blockHashTimeout is the timeout of the request for the hash of a block.
+const blockHashTimeout = 30 * time.Second
+
+// groupActiveTime is the duration in blocks after which a group expires. The
+// operator contract does not expose it so the value has to be kept in sync
+// with the one set in the contract: 14 days equivalent in 15s blocks.
+const groupActiveTime = uint64(86400*14) / 15
+
 type ethereumChain struct {
 	config                           ethereum.Config
 	client                           ethutil.HostChainClient
-	clientRPC                        *rpc.Client
-	clientWS                         *rpc.Client
 	keepRandomBeaconOperatorContract *contract.KeepRandomBeaconOperator
 	stakingContract                  *contract.TokenStaking
-	accountKey                       *keystore.Key
+	signer                           signer.Signer
 	blockCounter                     *ethlike.BlockCounter
+	nonceManager                     *ethlike.NonceManager
 	chainConfig                      *relaychain.Config
+	gasPolicies                      GasPolicies
+
+	// metrics are updated with transactions submitted by the chain. They
+	// are nil if transactions are not measured.
+	metrics *metrics.Ethereum
+
+	// outbox persists transactions submitted as part of the protocol until
+	// they are mined. It is nil if transactions are not persisted.
+	outbox *transactionOutbox
+
+	// ticketWindow is the ticket submission window of the last group
+	// selection, determined for tickets priced with the deadline strategy.
+	// It is guarded by ticketWindowMutex.
+	ticketWindowMutex *sync.Mutex
+	ticketWindow      submissionWindow
+
+	// transactionCosts records costs of transactions submitted as part of
+	// the protocol once they are mined. It is nil if transactions are not
+	// measured.
+	transactionCosts *transactionCosts
+
+	// subscriptionEstablished returns the channel closed once the next
+	// subscription is established with the Ethereum client. It is used to
+	// backfill events missed while event subscriptions were down.
+	subscriptionEstablished func() <-chan struct{}
+
+	// liveEventsDelay is the number of blocks behind live events and blocks
+	// up to which tracked events are considered processed.
+	liveEventsDelay uint64
+
+	// contractsMutex guards contract handles and transaction submitters
+	// which are replaced when the chain is reconfigured. They should be
+	// obtained with keepRandomBeaconOperator, tokenStaking and
+	// transactionSubmitter functions.
+	contractsMutex        *sync.RWMutex
+	transactionSubmitters map[string]*transactionSubmitter
 
 	// transactionMutex allows interested parties to forcibly serialize
 	// transaction submission.
@@ -64,6 +106,25 @@ type ethereumChain struct {
 	transactionMutex *sync.Mutex
 }
 
+// transactionSubmitter submits transactions of a single type. Each type of
+// transactions has its own operator contract handle so that resubmissions
+// of transactions not mined in time are capped by the gas policy of that
+// type.
+type transactionSubmitter struct {
+	operatorContract  *contract.KeepRandomBeaconOperator
+	pricer            *gasPricer
+	miningWaiter      *ethlike.MiningWaiter
+	transactorOptions *bind.TransactOpts
+}
+
+// transactionTypes lists types of transactions with their own gas policies.
+var transactionTypes = []string{
+	submitTicketTransaction,
+	submitDKGResultTransaction,
+	submitRelayEntryTransaction,
+	reportRelayEntryTimeoutTransaction,
+}
+
 type ethereumUtilityChain struct {
 	ethereumChain
 
@@ -74,7 +135,7 @@ func connect(
 	ctx context.Context,
 	config ethereum.Config,
 ) (*ethereumChain, error) {
-	client, clientWS, clientRPC, err := ethutil.ConnectClients(config.URL, config.URLRPC)
+	client, err := connectFailoverClient(ctx, config.URL, FailoverConfig{}, nil)
 	if err != nil {
 		return nil, fmt.Errorf(
 			"error connecting to Ethereum server: %s [%v]",
@@ -83,48 +144,150 @@ func connect(
 		)
 	}
 
-	return connectWithClient(ctx, config, client, clientWS, clientRPC)
+	operatorSigner, err := localSigner(config.Account)
+	if err != nil {
+		return nil, err
+	}
+
+	return connectWithClient(
+		ctx,
+		config,
+		operatorSigner,
+		client,
+	)
+}
+
+// localSigner creates a signer for the account using the key decrypted from
+// the account's key file.
+func localSigner(account ethlike.Account) (*signer.Local, error) {
+	key, err := ethutil.DecryptKeyFile(
+		account.KeyFile,
+		account.KeyFilePassword,
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to read KeyFile: %s: [%v]",
+			account.KeyFile,
+			err,
+		)
+	}
+
+	return signer.NewLocal(key), nil
 }
 
 func connectWithClient(
 	ctx context.Context,
 	config ethereum.Config,
-	client *ethclient.Client,
-	clientWS *rpc.Client,
-	clientRPC *rpc.Client,
+	operatorSigner signer.Signer,
+	client *failoverClient,
 ) (*ethereumChain, error) {
-	ec := &ethereumChain{
-		config:           config,
-		client:           addClientWrappers(config, client),
-		clientRPC:        clientRPC,
-		clientWS:         clientWS,
-		transactionMutex: &sync.Mutex{},
-	}
+	wrappedClient := addClientWrappers(config, client)
 
-	blockCounter, err := ethutil.NewBlockCounter(ec.client)
+	blockCounter, err := ethutil.NewBlockCounter(wrappedClient)
 	if err != nil {
 		return nil, fmt.Errorf(
 			"failed to create Ethereum blockcounter: [%v]",
 			err,
 		)
 	}
-	ec.blockCounter = blockCounter
 
-	if ec.accountKey == nil {
-		key, err := ethutil.DecryptKeyFile(
-			config.Account.KeyFile,
-			config.Account.KeyFilePassword,
+	return connectAccount(
+		ctx,
+		config,
+		GasPolicies{},
+		nil,
+		nil,
+		operatorSigner,
+		wrappedClient,
+		blockCounter,
+		client.subscriptionEstablished,
+		client.config.liveEventsDelay(),
+	)
+}
+
+// connectAccount creates a handle to the chain interface for the account of
+// the given signer using the already established connection to the Ethereum
+// network. Each account has its own nonce manager and serializes its own
+// transaction submission, while the client and the block counter may be
+// shared between multiple accounts. Transactions submitted as part of the
+// protocol are priced according to the provided gas policies and counted in
+// the provided metrics, if any. If the outbox persistence handle is provided,
+// these transactions are persisted until they are mined and transactions
+// persisted before are replayed.
+func connectAccount(
+	ctx context.Context,
+	config ethereum.Config,
+	gasPolicies GasPolicies,
+	ethereumMetrics *metrics.Ethereum,
+	outboxPersistence persistence.Handle,
+	operatorSigner signer.Signer,
+	client ethutil.HostChainClient,
+	blockCounter *ethlike.BlockCounter,
+	subscriptionEstablished func() <-chan struct{},
+	liveEventsDelay uint64,
+) (*ethereumChain, error) {
+	ec := &ethereumChain{
+		config:                  config,
+		client:                  client,
+		signer:                  operatorSigner,
+		blockCounter:            blockCounter,
+		nonceManager:            ethutil.NewNonceManager(client, operatorSigner.Address()),
+		gasPolicies:             gasPolicies,
+		metrics:                 ethereumMetrics,
+		subscriptionEstablished: subscriptionEstablished,
+		liveEventsDelay:         liveEventsDelay,
+		ticketWindowMutex:       &sync.Mutex{},
+		contractsMutex:          &sync.RWMutex{},
+		transactionMutex:        &sync.Mutex{},
+	}
+
+	if outboxPersistence != nil {
+		outbox, err := newTransactionOutbox(
+			outboxPersistence,
+			operatorSigner.Address(),
 		)
 		if err != nil {
-			return nil, fmt.Errorf(
-				"failed to read KeyFile: %s: [%v]",
-				config.Account.KeyFile,
-				err,
-			)
+			return nil, fmt.Errorf("could not create outbox: [%v]", err)
 		}
-		ec.accountKey = key
+		ec.outbox = outbox
+	}
+
+	if ethereumMetrics != nil {
+		ec.transactionCosts = newTransactionCosts(
+			client,
+			ethereumMetrics,
+			operatorSigner.Address().Hex(),
+		)
+		ec.startTransactionCosts(ctx)
+	}
+
+	if err := ec.attachContracts(config); err != nil {
+		return nil, err
 	}
 
+	chainConfig, err := fetchChainConfig(ec)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch chain config: [%v]", err)
+	}
+	ec.chainConfig = chainConfig
+
+	if ec.outbox != nil {
+		ec.startOutbox(ctx)
+	}
+
+	ec.initializeBalanceMonitoring(ctx)
+
+	return ec, nil
+}
+
+// attachContracts creates contract handles of the chain submitting
+// transactions with the mining check interval and the max gas price from the
+// provided config. Previously attached contract handles are replaced. Handles
+// of all contracts share the nonce manager and the transaction mutex of the
+// chain so that replacing them does not affect transaction ordering. Protocol
+// transactions are submitted with their own handles of the operator contract,
+// capped by their gas policies.
+func (ec *ethereumChain) attachContracts(config ethereum.Config) error {
 	checkInterval := DefaultMiningCheckInterval
 	maxGasPrice := DefaultMaxGasPrice
 	if config.MiningCheckInterval != 0 {
@@ -144,85 +307,123 @@ func connectWithClient(
 
 	address, err := addressForContract(config, "KeepRandomBeaconOperator")
 	if err != nil {
-		return nil, fmt.Errorf("error resolving KeepRandomBeaconOperator contract: [%v]", err)
+		return fmt.Errorf("error resolving KeepRandomBeaconOperator contract: [%v]", err)
 	}
 
-	nonceManager := ethutil.NewNonceManager(
-		ec.client,
-		ec.accountKey.Address,
-	)
+	transactorOptions := signer.TransactorOptions(ec.signer)
 
 	keepRandomBeaconOperatorContract, err :=
-		contract.NewKeepRandomBeaconOperator(
+		contract.NewKeepRandomBeaconOperatorWithTransactor(
 			*address,
-			ec.accountKey,
+			transactorOptions,
 			ec.client,
-			nonceManager,
+			ec.nonceManager,
 			miningWaiter,
-			blockCounter,
+			ec.blockCounter,
 			ec.transactionMutex,
 		)
 	if err != nil {
-		return nil, fmt.Errorf("error attaching to KeepRandomBeaconOperator contract: [%v]", err)
+		return fmt.Errorf("error attaching to KeepRandomBeaconOperator contract: [%v]", err)
+	}
+
+	transactionSubmitters := make(map[string]*transactionSubmitter)
+	for _, transactionType := range transactionTypes {
+		pricer := ec.gasPricer(transactionType, maxGasPrice)
+		submitterMiningWaiter := ethutil.NewMiningWaiter(
+			ec.client,
+			checkInterval,
+			pricer.maxGasPrice,
+		)
+
+		submitterOptions := pricer.submitterTransactorOptions(
+			transactorOptions,
+		)
+		var submitterBackend ethutil.HostChainClient = ec.client
+		if ec.outbox != nil {
+			submitterOptions = ec.outboxTransactorOptions(
+				transactionType,
+				submitterOptions,
+			)
+			submitterBackend = &outboxBackend{ec.client, ec.outbox}
+		}
+
+		operatorContract, err :=
+			contract.NewKeepRandomBeaconOperatorWithTransactor(
+				*address,
+				submitterOptions,
+				submitterBackend,
+				ec.nonceManager,
+				submitterMiningWaiter,
+				ec.blockCounter,
+				ec.transactionMutex,
+			)
+		if err != nil {
+			return fmt.Errorf(
+				"error attaching to KeepRandomBeaconOperator contract "+
+					"for [%v] transactions: [%v]",
+				transactionType,
+				err,
+			)
+		}
+
+		transactionSubmitters[transactionType] = &transactionSubmitter{
+			operatorContract:  operatorContract,
+			pricer:            pricer,
+			miningWaiter:      submitterMiningWaiter,
+			transactorOptions: submitterOptions,
+		}
 	}
-	ec.keepRandomBeaconOperatorContract = keepRandomBeaconOperatorContract
 
 	address, err = addressForContract(config, "TokenStaking")
 	if err != nil {
-		return nil, fmt.Errorf("error resolving TokenStaking contract: [%v]", err)
+		return fmt.Errorf("error resolving TokenStaking contract: [%v]", err)
 	}
 
 	stakingContract, err :=
-		contract.NewTokenStaking(
+		contract.NewTokenStakingWithTransactor(
 			*address,
-			ec.accountKey,
+			transactorOptions,
 			ec.client,
-			nonceManager,
+			ec.nonceManager,
 			miningWaiter,
-			blockCounter,
+			ec.blockCounter,
 			ec.transactionMutex,
 		)
 	if err != nil {
-		return nil, fmt.Errorf("error attaching to TokenStaking contract: [%v]", err)
+		return fmt.Errorf("error attaching to TokenStaking contract: [%v]", err)
 	}
+
+	ec.contractsMutex.Lock()
+	defer ec.contractsMutex.Unlock()
+
+	ec.keepRandomBeaconOperatorContract = keepRandomBeaconOperatorContract
 	ec.stakingContract = stakingContract
+	ec.transactionSubmitters = transactionSubmitters
 
-	chainConfig, err := fetchChainConfig(ec)
-	if err != nil {
-		return nil, fmt.Errorf("could not fetch chain config: [%v]", err)
-	}
-	ec.chainConfig = chainConfig
+	return nil
+}
 
-	ec.initializeBalanceMonitoring(ctx)
+func (ec *ethereumChain) keepRandomBeaconOperator() *contract.KeepRandomBeaconOperator {
+	ec.contractsMutex.RLock()
+	defer ec.contractsMutex.RUnlock()
 
-	return ec, nil
+	return ec.keepRandomBeaconOperatorContract
 }
 
-func addClientWrappers(
-	config ethereum.Config,
-	backend ethutil.HostChainClient,
-) ethutil.HostChainClient {
-	loggingBackend := ethutil.WrapCallLogging(logger, backend)
-
-	if config.RequestsPerSecondLimit > 0 || config.ConcurrencyLimit > 0 {
-		logger.Infof(
-			"enabled ethereum client request rate limiter; "+
-				"rps limit [%v]; "+
-				"concurrency limit [%v]",
-			config.RequestsPerSecondLimit,
-			config.ConcurrencyLimit,
-		)
+func (ec *ethereumChain) tokenStaking() *contract.TokenStaking {
+	ec.contractsMutex.RLock()
+	defer ec.contractsMutex.RUnlock()
 
-		return ethutil.WrapRateLimiting(
-			loggingBackend,
-			&rate.LimiterConfig{
-				RequestsPerSecondLimit: config.RequestsPerSecondLimit,
-				ConcurrencyLimit:       config.ConcurrencyLimit,
-			},
-		)
-	}
+	return ec.stakingContract
+}
 
-	return loggingBackend
+func (ec *ethereumChain) transactionSubmitter(
+	transactionType string,
+) *transactionSubmitter {
+	ec.contractsMutex.RLock()
+	defer ec.contractsMutex.RUnlock()
+
+	return ec.transactionSubmitters[transactionType]
 }
 
 // ConnectUtility makes the network connection to the Ethereum network and
@@ -231,7 +432,12 @@ func addClientWrappers(
 // the configuration will need to reference a websocket, "ws://", or local IPC
 // connection.
 func ConnectUtility(config ethereum.Config) (chain.Utility, error) {
-	client, clientWS, clientRPC, err := ethutil.ConnectClients(config.URL, config.URLRPC)
+	client, err := connectFailoverClient(
+		context.Background(),
+		config.URL,
+		FailoverConfig{},
+		nil,
+	)
 	if err != nil {
 		return nil, fmt.Errorf(
 			"error connecting to Ethereum server: %s [%v]",
@@ -240,12 +446,16 @@ func ConnectUtility(config ethereum.Config) (chain.Utility, error) {
 		)
 	}
 
+	operatorSigner, err := localSigner(config.Account)
+	if err != nil {
+		return nil, err
+	}
+
 	base, err := connectWithClient(
 		context.Background(),
 		config,
+		operatorSigner,
 		client,
-		clientWS,
-		clientRPC,
 	)
 	if err != nil {
 		return nil, err
@@ -281,13 +491,13 @@ func ConnectUtility(config ethereum.Config) (chain.Utility, error) {
 
 	nonceManager := ethutil.NewNonceManager(
 		client,
-		base.accountKey.Address,
+		base.signer.Address(),
 	)
 
 	keepRandomBeaconServiceContract, err :=
-		contract.NewKeepRandomBeaconService(
+		contract.NewKeepRandomBeaconServiceWithTransactor(
 			*address,
-			base.accountKey,
+			signer.TransactorOptions(base.signer),
 			base.client,
 			nonceManager,
 			miningWaiter,
@@ -315,6 +525,142 @@ func Connect(
 	return connect(ctx, config)
 }
 
+// ConnectOperators makes the network connection to the Ethereum network and
+// returns standard handles to the chain interface for the operator of the
+// given signer and for all the given additional accounts, in this order.
+// All handles share the same connection to the Ethereum network and the same
+// block counter but sign and submit transactions using their own accounts.
+// Keys of additional accounts are decrypted from their key files. The returned
+// reconfigurer applies config changes to all the handles.
+//
+// The connection fails over between the Ethereum node from the config and
+// the additional nodes from the failover config, depending on their health.
+// Transactions submitted as part of the protocol are priced according to the
+// provided gas policies. Endpoints and transactions are measured with the
+// provided metrics. If the outbox persistence handle is provided, these
+// transactions are persisted until they are mined and transactions persisted
+// before are reconciled with the state of the chain and replayed if they are
+// still needed, before the handles are returned.
+func ConnectOperators(
+	ctx context.Context,
+	config ethereum.Config,
+	failoverConfig FailoverConfig,
+	gasPolicies GasPolicies,
+	ethereumMetrics *metrics.Ethereum,
+	outboxPersistence persistence.Handle,
+	operatorSigner signer.Signer,
+	additionalAccounts []ethlike.Account,
+) ([]chain.Handle, *Reconfigurer, error) {
+	client, err := connectFailoverClient(
+		ctx,
+		config.URL,
+		failoverConfig,
+		ethereumMetrics,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf(
+			"error connecting to Ethereum server: %s [%v]",
+			config.URL,
+			err,
+		)
+	}
+
+	wrappedClient := addClientWrappers(config, client)
+
+	blockCounter, err := ethutil.NewBlockCounter(wrappedClient)
+	if err != nil {
+		return nil, nil, fmt.Errorf(
+			"failed to create Ethereum blockcounter: [%v]",
+			err,
+		)
+	}
+
+	primary, err := connectAccount(
+		ctx,
+		config,
+		gasPolicies,
+		ethereumMetrics,
+		outboxPersistence,
+		operatorSigner,
+		wrappedClient,
+		blockCounter,
+		client.subscriptionEstablished,
+		failoverConfig.liveEventsDelay(),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reconfigurer := &Reconfigurer{
+		client: wrappedClient,
+		chains: []*ethereumChain{primary},
+	}
+
+	handles := []chain.Handle{primary}
+	for _, account := range additionalAccounts {
+		accountSigner, err := localSigner(account)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		handle, err := connectAccount(
+			ctx,
+			config,
+			gasPolicies,
+			ethereumMetrics,
+			outboxPersistence,
+			accountSigner,
+			wrappedClient,
+			blockCounter,
+			client.subscriptionEstablished,
+			failoverConfig.liveEventsDelay(),
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"could not connect operator [%v]: [%v]",
+				account.KeyFile,
+				err,
+			)
+		}
+
+		handles = append(handles, handle)
+		reconfigurer.chains = append(reconfigurer.chains, handle)
+	}
+
+	return handles, reconfigurer, nil
+}
+
+// Reconfigurer applies changes of the Ethereum config to chain handles sharing
+// the same connection to the Ethereum network, without reconnecting.
+type Reconfigurer struct {
+	client *reconfigurableClient
+	chains []*ethereumChain
+}
+
+// Reconfigure applies the request rate limits, the mining check interval and
+// the max gas price from the provided config. Other values of the config are
+// ignored. Transactions submitted before the reconfiguration are resubmitted
+// with the max gas price they were submitted with.
+func (r *Reconfigurer) Reconfigure(config ethereum.Config) error {
+	r.client.reconfigure(config)
+
+	for _, chain := range r.chains {
+		chainConfig := chain.config
+		chainConfig.MiningCheckInterval = config.MiningCheckInterval
+		chainConfig.MaxGasPrice = config.MaxGasPrice
+
+		if err := chain.attachContracts(chainConfig); err != nil {
+			return fmt.Errorf(
+				"could not reconfigure operator [%v]: [%v]",
+				chain.Address().Hex(),
+				err,
+			)
+		}
+	}
+
+	return nil
+}
+
 func addressForContract(config ethereum.Config, contractName string) (*common.Address, error) {
 	addressString, exists := config.ContractAddresses[contractName]
 	if !exists {
@@ -341,21 +687,46 @@ func (ec *ethereumChain) BlockCounter() (chain.BlockCounter, error) {
 	return ec.blockCounter, nil
 }
 
+// BlockHashes returns hashes of blocks as seen by the Ethereum client.
+func (ec *ethereumChain) BlockHashes() (chain.BlockHashes, error) {
+	return ec, nil
+}
+
+// BlockHash returns the hash of the block with the given number.
+func (ec *ethereumChain) BlockHash(blockNumber uint64) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), blockHashTimeout)
+	defer cancel()
+
+	header, err := ec.client.HeaderByNumber(
+		ctx,
+		new(big.Int).SetUint64(blockNumber),
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"could not get header of block [%v]: [%v]",
+			blockNumber,
+			err,
+		)
+	}
+
+	return header.Hash().Bytes(), nil
+}
+
 func fetchChainConfig(ec *ethereumChain) (*relaychain.Config, error) {
 	logger.Infof("fetching relay chain config")
 
-	groupSize, err := ec.keepRandomBeaconOperatorContract.GroupSize()
+	groupSize, err := ec.keepRandomBeaconOperator().GroupSize()
 	if err != nil {
 		return nil, fmt.Errorf("error calling GroupSize: [%v]", err)
 	}
 
-	threshold, err := ec.keepRandomBeaconOperatorContract.GroupThreshold()
+	threshold, err := ec.keepRandomBeaconOperator().GroupThreshold()
 	if err != nil {
 		return nil, fmt.Errorf("error calling GroupThreshold: [%v]", err)
 	}
 
 	ticketSubmissionTimeout, err :=
-		ec.keepRandomBeaconOperatorContract.TicketSubmissionTimeout()
+		ec.keepRandomBeaconOperator().TicketSubmissionTimeout()
 	if err != nil {
 		return nil, fmt.Errorf(
 			"error calling TicketSubmissionTimeout: [%v]",
@@ -363,7 +734,7 @@ func fetchChainConfig(ec *ethereumChain) (*relaychain.Config, error) {
 		)
 	}
 
-	resultPublicationBlockStep, err := ec.keepRandomBeaconOperatorContract.ResultPublicationBlockStep()
+	resultPublicationBlockStep, err := ec.keepRandomBeaconOperator().ResultPublicationBlockStep()
 	if err != nil {
 		return nil, fmt.Errorf(
 			"error calling ResultPublicationBlockStep: [%v]",
@@ -371,7 +742,7 @@ func fetchChainConfig(ec *ethereumChain) (*relaychain.Config, error) {
 		)
 	}
 
-	relayEntryTimeout, err := ec.keepRandomBeaconOperatorContract.RelayEntryTimeout()
+	relayEntryTimeout, err := ec.keepRandomBeaconOperator().RelayEntryTimeout()
 	if err != nil {
 		return nil, fmt.Errorf("error calling RelayEntryTimeout: [%v]", err)
 	}
@@ -382,5 +753,6 @@ func fetchChainConfig(ec *ethereumChain) (*relaychain.Config, error) {
 		TicketSubmissionTimeout:    ticketSubmissionTimeout.Uint64(),
 		ResultPublicationBlockStep: resultPublicationBlockStep.Uint64(),
 		RelayEntryTimeout:          relayEntryTimeout.Uint64(),
+		GroupActiveTime:            groupActiveTime,
 	}, nil
 }
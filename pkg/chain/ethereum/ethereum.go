@@ -1,6 +1,7 @@
 package ethereum
 
 import (
+	"bytes"
 	"fmt"
 	"math/big"
 	"time"
@@ -13,6 +14,8 @@ import (
 	relayChain "github.com/keep-network/keep-core/pkg/beacon/relay/chain"
 	"github.com/keep-network/keep-core/pkg/beacon/relay/event"
 	"github.com/keep-network/keep-core/pkg/chain"
+	"github.com/keep-network/keep-core/pkg/chain/ethereum/signer"
+	"github.com/keep-network/keep-core/pkg/chain/gen/abi"
 	"github.com/keep-network/keep-core/pkg/gen/async"
 	"github.com/keep-network/keep-core/pkg/operator"
 	"github.com/keep-network/keep-core/pkg/subscription"
@@ -20,17 +23,37 @@ import (
 
 var logger = log.Logger("keep-chain-ethereum")
 
+// submitTicketGasLimit is the gas limit of the ticket submission transaction.
+const submitTicketGasLimit = 250000
+
+// submitTicketGasUsed is the average gas used by the ticket submission
+// transaction, as measured on mainnet. It is much lower than the gas limit
+// which has to cover the most expensive ticket submissions.
+const submitTicketGasUsed = 140000
+
 // ThresholdRelay converts from ethereumChain to beacon.ChainInterface.
 func (ec *ethereumChain) ThresholdRelay() relayChain.Interface {
 	return ec
 }
 
+// GetKeys returns the operator key pair. If the operator key is held by
+// an external signing service, only the public key is returned.
 func (ec *ethereumChain) GetKeys() (*operator.PrivateKey, *operator.PublicKey) {
-	return operator.ChainKeyToOperatorKey(ec.accountKey)
+	if localSigner, ok := ec.signer.(*signer.Local); ok {
+		return operator.ChainKeyToOperatorKey(localSigner.Key())
+	}
+
+	publicKey, err := operator.Unmarshal(ec.signer.PublicKey())
+	if err != nil {
+		logger.Errorf("could not unmarshal operator public key: [%v]", err)
+		return nil, nil
+	}
+
+	return nil, publicKey
 }
 
 func (ec *ethereumChain) Signing() chain.Signing {
-	return ethutil.NewSigner(ec.accountKey.PrivateKey)
+	return ec.signer
 }
 
 func (ec *ethereumChain) GetConfig() *relayChain.Config {
@@ -38,14 +61,14 @@ func (ec *ethereumChain) GetConfig() *relayChain.Config {
 }
 
 func (ec *ethereumChain) MinimumStake() (*big.Int, error) {
-	return ec.stakingContract.MinimumStake()
+	return ec.tokenStaking().MinimumStake()
 }
 
 // HasMinimumStake returns true if the specified address is staked.  False will
 // be returned if not staked.  If err != nil then it was not possible to determine
 // if the address is staked or not.
 func (ec *ethereumChain) HasMinimumStake(address common.Address) (bool, error) {
-	return ec.keepRandomBeaconOperatorContract.HasMinimumStake(address)
+	return ec.keepRandomBeaconOperator().HasMinimumStake(address)
 }
 
 func (ec *ethereumChain) SubmitTicket(ticket *relayChain.Ticket) *async.EventGroupTicketSubmissionPromise {
@@ -64,14 +87,28 @@ func (ec *ethereumChain) SubmitTicket(ticket *relayChain.Ticket) *async.EventGro
 
 	ticketBytes := ec.packTicket(ticket)
 
-	_, err := ec.keepRandomBeaconOperatorContract.SubmitTicket(
+	submitter := ec.transactionSubmitter(submitTicketTransaction)
+
+	window := ec.ticketSubmissionWindow()
+
+	transactionOptions, err := submitter.pricer.transactionOptions(
+		window,
+		submitTicketGasLimit,
+	)
+	if err != nil {
+		failPromise(err)
+		return submittedTicketPromise
+	}
+
+	transaction, err := submitter.operatorContract.SubmitTicket(
 		ticketBytes,
-		ethutil.TransactionOptions{
-			GasLimit: 250000,
-		},
+		transactionOptions,
 	)
 	if err != nil {
 		failPromise(err)
+	} else {
+		submitter.pricer.recordSubmission(transaction, window)
+		ec.recordDeadline(transaction, window)
 	}
 
 	// TODO: fulfill when submitted
@@ -92,13 +129,44 @@ func (ec *ethereumChain) packTicket(ticket *relayChain.Ticket) [32]uint8 {
 }
 
 func (ec *ethereumChain) GetSubmittedTickets() ([]uint64, error) {
-	return ec.keepRandomBeaconOperatorContract.SubmittedTickets()
+	return ec.keepRandomBeaconOperator().SubmittedTickets()
+}
+
+// TicketSubmissionCost returns the expected cost of the ticket submission
+// transaction, that is the average gas used by the transaction at the gas
+// price the ticket would be submitted with now.
+func (ec *ethereumChain) TicketSubmissionCost() (*big.Int, error) {
+	submitter := ec.transactionSubmitter(submitTicketTransaction)
+
+	transactionOptions, err := submitter.pricer.transactionOptions(
+		ec.ticketSubmissionWindow(),
+		submitTicketGasLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	gasPrice := transactionOptions.GasPrice
+	if gasPrice == nil {
+		gasPrice, err = submitter.pricer.suggestedGasPrice()
+		if err != nil {
+			return nil, fmt.Errorf(
+				"could not get suggested gas price: [%v]",
+				err,
+			)
+		}
+	}
+
+	return new(big.Int).Mul(
+		gasPrice,
+		new(big.Int).SetUint64(submitTicketGasUsed),
+	), nil
 }
 
 func (ec *ethereumChain) GetSelectedParticipants() ([]relayChain.StakerAddress, error) {
 	var stakerAddresses []relayChain.StakerAddress
 	fetchParticipants := func() error {
-		participants, err := ec.keepRandomBeaconOperatorContract.SelectedParticipants()
+		participants, err := ec.keepRandomBeaconOperator().SelectedParticipants()
 		if err != nil {
 			return err
 		}
@@ -190,22 +258,47 @@ func (ec *ethereumChain) SubmitRelayEntry(
 		}
 	}()
 
-	gasEstimate, err := ec.keepRandomBeaconOperatorContract.RelayEntryGasEstimate(entry)
+	// The relay entry may have been submitted before the restart of the
+	// client and replayed from the outbox. The promise is fulfilled once the
+	// replayed transaction is mined.
+	if ec.hasPendingRelayEntry(entry) {
+		logger.Infof("relay entry is already pending; not submitting it again")
+		return relayEntryPromise
+	}
+
+	gasEstimate, err := ec.keepRandomBeaconOperator().RelayEntryGasEstimate(entry)
 	if err != nil {
 		logger.Errorf("failed to estimate gas [%v]", err)
 	}
 
 	gasEstimateWithMargin := float64(gasEstimate) * float64(1.2) // 20% more than original
-	_, err = ec.keepRandomBeaconOperatorContract.RelayEntry(
+
+	submitter := ec.transactionSubmitter(submitRelayEntryTransaction)
+
+	window := ec.relayEntrySubmissionWindow()
+
+	transactionOptions, err := submitter.pricer.transactionOptions(
+		window,
+		uint64(gasEstimateWithMargin),
+	)
+	if err != nil {
+		subscription.Unsubscribe()
+		close(generatedEntry)
+		failPromise(err)
+		return relayEntryPromise
+	}
+
+	transaction, err := submitter.operatorContract.RelayEntry(
 		entry,
-		ethutil.TransactionOptions{
-			GasLimit: uint64(gasEstimateWithMargin),
-		},
+		transactionOptions,
 	)
 	if err != nil {
 		subscription.Unsubscribe()
 		close(generatedEntry)
 		failPromise(err)
+	} else {
+		submitter.pricer.recordSubmission(transaction, window)
+		ec.recordDeadline(transaction, window)
 	}
 
 	return relayEntryPromise
@@ -214,15 +307,47 @@ func (ec *ethereumChain) SubmitRelayEntry(
 func (ec *ethereumChain) OnRelayEntrySubmitted(
 	handle func(entry *event.EntrySubmitted),
 ) subscription.EventSubscription {
-	onEvent := func(blockNumber uint64) {
-		handle(&event.EntrySubmitted{
-			BlockNumber: blockNumber,
-		})
+	toTrackedEvent := func(
+		submitted *abi.KeepRandomBeaconOperatorRelayEntrySubmitted,
+	) trackedEvent {
+		return trackedEvent{submitted.Raw, func() {
+			handle(&event.EntrySubmitted{
+				BlockNumber: submitted.Raw.BlockNumber,
+			})
+		}}
 	}
 
-	subscription := ec.keepRandomBeaconOperatorContract.RelayEntrySubmitted(
-		nil,
-	).OnEvent(onEvent)
+	sink := make(chan *abi.KeepRandomBeaconOperatorRelayEntrySubmitted)
+	tracker, subscription := ec.trackEvents(
+		"RelayEntrySubmitted",
+		ec.keepRandomBeaconOperator().RelayEntrySubmitted(nil).Pipe(sink),
+		func(fromBlock, toBlock uint64) ([]trackedEvent, error) {
+			events, err := ec.keepRandomBeaconOperator().PastRelayEntrySubmittedEvents(
+				fromBlock,
+				&toBlock,
+			)
+			if err != nil {
+				return nil, err
+			}
+
+			tracked := make([]trackedEvent, len(events))
+			for i, submitted := range events {
+				tracked[i] = toTrackedEvent(submitted)
+			}
+			return tracked, nil
+		},
+	)
+
+	go func() {
+		for {
+			select {
+			case submitted := <-sink:
+				tracker.process(toTrackedEvent(submitted))
+			case <-tracker.done:
+				return
+			}
+		}
+	}()
 
 	return subscription
 }
@@ -230,21 +355,51 @@ func (ec *ethereumChain) OnRelayEntrySubmitted(
 func (ec *ethereumChain) OnRelayEntryRequested(
 	handle func(request *event.Request),
 ) subscription.EventSubscription {
-	onEvent := func(
-		previousEntry []byte,
-		groupPublicKey []byte,
-		blockNumber uint64,
-	) {
-		handle(&event.Request{
-			PreviousEntry:  previousEntry,
-			GroupPublicKey: groupPublicKey,
-			BlockNumber:    blockNumber,
-		})
-	}
-
-	subscription := ec.keepRandomBeaconOperatorContract.RelayEntryRequested(
-		nil,
-	).OnEvent(onEvent)
+	toTrackedEvent := func(
+		requested *abi.KeepRandomBeaconOperatorRelayEntryRequested,
+	) trackedEvent {
+		return trackedEvent{requested.Raw, func() {
+			handle(&event.Request{
+				PreviousEntry:  requested.PreviousEntry,
+				GroupPublicKey: requested.GroupPublicKey,
+				BlockNumber:    requested.Raw.BlockNumber,
+				BlockHash:      requested.Raw.BlockHash.Bytes(),
+				Removed:        requested.Raw.Removed,
+			})
+		}}
+	}
+
+	sink := make(chan *abi.KeepRandomBeaconOperatorRelayEntryRequested)
+	tracker, subscription := ec.trackEvents(
+		"RelayEntryRequested",
+		ec.keepRandomBeaconOperator().RelayEntryRequested(nil).Pipe(sink),
+		func(fromBlock, toBlock uint64) ([]trackedEvent, error) {
+			events, err := ec.keepRandomBeaconOperator().PastRelayEntryRequestedEvents(
+				fromBlock,
+				&toBlock,
+			)
+			if err != nil {
+				return nil, err
+			}
+
+			tracked := make([]trackedEvent, len(events))
+			for i, requested := range events {
+				tracked[i] = toTrackedEvent(requested)
+			}
+			return tracked, nil
+		},
+	)
+
+	go func() {
+		for {
+			select {
+			case requested := <-sink:
+				tracker.process(toTrackedEvent(requested))
+			case <-tracker.done:
+				return
+			}
+		}
+	}()
 
 	return subscription
 }
@@ -252,19 +407,50 @@ func (ec *ethereumChain) OnRelayEntryRequested(
 func (ec *ethereumChain) OnGroupSelectionStarted(
 	handle func(groupSelectionStart *event.GroupSelectionStart),
 ) subscription.EventSubscription {
-	onEvent := func(
-		newEntry *big.Int,
-		blockNumber uint64,
-	) {
-		handle(&event.GroupSelectionStart{
-			NewEntry:    newEntry,
-			BlockNumber: blockNumber,
-		})
+	toTrackedEvent := func(
+		started *abi.KeepRandomBeaconOperatorGroupSelectionStarted,
+	) trackedEvent {
+		return trackedEvent{started.Raw, func() {
+			handle(&event.GroupSelectionStart{
+				NewEntry:    started.NewEntry,
+				BlockNumber: started.Raw.BlockNumber,
+				BlockHash:   started.Raw.BlockHash.Bytes(),
+				Removed:     started.Raw.Removed,
+			})
+		}}
 	}
 
-	subscription := ec.keepRandomBeaconOperatorContract.GroupSelectionStarted(
-		nil,
-	).OnEvent(onEvent)
+	sink := make(chan *abi.KeepRandomBeaconOperatorGroupSelectionStarted)
+	tracker, subscription := ec.trackEvents(
+		"GroupSelectionStarted",
+		ec.keepRandomBeaconOperator().GroupSelectionStarted(nil).Pipe(sink),
+		func(fromBlock, toBlock uint64) ([]trackedEvent, error) {
+			events, err := ec.keepRandomBeaconOperator().PastGroupSelectionStartedEvents(
+				fromBlock,
+				&toBlock,
+			)
+			if err != nil {
+				return nil, err
+			}
+
+			tracked := make([]trackedEvent, len(events))
+			for i, started := range events {
+				tracked[i] = toTrackedEvent(started)
+			}
+			return tracked, nil
+		},
+	)
+
+	go func() {
+		for {
+			select {
+			case started := <-sink:
+				tracker.process(toTrackedEvent(started))
+			case <-tracker.done:
+				return
+			}
+		}
+	}()
 
 	return subscription
 }
@@ -272,38 +458,67 @@ func (ec *ethereumChain) OnGroupSelectionStarted(
 func (ec *ethereumChain) OnGroupRegistered(
 	handle func(groupRegistration *event.GroupRegistration),
 ) subscription.EventSubscription {
-	onEvent := func(
-		memberIndex *big.Int,
-		groupPublicKey []byte,
-		misbehaved []byte,
-		blockNumber uint64,
-	) {
-		handle(&event.GroupRegistration{
-			GroupPublicKey: groupPublicKey,
-			BlockNumber:    blockNumber,
-		})
-	}
-
-	subscription := ec.keepRandomBeaconOperatorContract.DkgResultSubmittedEvent(
-		nil,
-	).OnEvent(onEvent)
-
-	return subscription
+	return ec.onDKGResultSubmittedEvent(
+		"GroupRegistered",
+		func(submitted *abi.KeepRandomBeaconOperatorDkgResultSubmittedEvent) {
+			handle(&event.GroupRegistration{
+				GroupPublicKey: submitted.GroupPubKey,
+				BlockNumber:    submitted.Raw.BlockNumber,
+			})
+		},
+	)
 }
 
 func (ec *ethereumChain) IsGroupRegistered(groupPublicKey []byte) (bool, error) {
-	return ec.keepRandomBeaconOperatorContract.IsGroupRegistered(groupPublicKey)
+	return ec.keepRandomBeaconOperator().IsGroupRegistered(groupPublicKey)
 }
 
 func (ec *ethereumChain) IsStaleGroup(groupPublicKey []byte) (bool, error) {
-	return ec.keepRandomBeaconOperatorContract.IsStaleGroup(groupPublicKey)
+	return ec.keepRandomBeaconOperator().IsStaleGroup(groupPublicKey)
+}
+
+// GetGroupRegistrationBlock looks for the registration of the group with the
+// given public key in blocks in which groups that are not stale yet could
+// have been registered.
+func (ec *ethereumChain) GetGroupRegistrationBlock(
+	groupPublicKey []byte,
+) (uint64, error) {
+	currentBlock, err := ec.blockCounter.CurrentBlock()
+	if err != nil {
+		return 0, fmt.Errorf("could not get current block: [%v]", err)
+	}
+
+	lookback := ec.chainConfig.GroupActiveTime + ec.chainConfig.RelayEntryTimeout
+	startBlock := uint64(0)
+	if currentBlock > lookback {
+		startBlock = currentBlock - lookback
+	}
+
+	events, err := ec.keepRandomBeaconOperator().PastOnGroupRegisteredEvents(
+		startBlock,
+		nil,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, event := range events {
+		if bytes.Equal(event.GroupPubKey, groupPublicKey) {
+			return event.Raw.BlockNumber, nil
+		}
+	}
+
+	return 0, fmt.Errorf(
+		"group registration not found since block [%v]",
+		startBlock,
+	)
 }
 
 func (ec *ethereumChain) GetGroupMembers(groupPublicKey []byte) (
 	[]relayChain.StakerAddress,
 	error,
 ) {
-	members, err := ec.keepRandomBeaconOperatorContract.GetGroupMembers(
+	members, err := ec.keepRandomBeaconOperator().GetGroupMembers(
 		groupPublicKey,
 	)
 	if err != nil {
@@ -321,55 +536,111 @@ func (ec *ethereumChain) GetGroupMembers(groupPublicKey []byte) (
 func (ec *ethereumChain) OnDKGResultSubmitted(
 	handler func(dkgResultPublication *event.DKGResultSubmission),
 ) subscription.EventSubscription {
-	onEvent := func(
-		memberIndex *big.Int,
-		groupPublicKey []byte,
-		misbehaved []byte,
-		blockNumber uint64,
-	) {
-		handler(&event.DKGResultSubmission{
-			MemberIndex:    uint32(memberIndex.Uint64()),
-			GroupPublicKey: groupPublicKey,
-			Misbehaved:     misbehaved,
-			BlockNumber:    blockNumber,
-		})
-	}
-
-	subscription := ec.keepRandomBeaconOperatorContract.DkgResultSubmittedEvent(
-		nil,
-	).OnEvent(onEvent)
+	return ec.onDKGResultSubmittedEvent(
+		"DKGResultSubmitted",
+		func(submitted *abi.KeepRandomBeaconOperatorDkgResultSubmittedEvent) {
+			handler(&event.DKGResultSubmission{
+				MemberIndex:    uint32(submitted.MemberIndex.Uint64()),
+				GroupPublicKey: submitted.GroupPubKey,
+				Misbehaved:     submitted.Misbehaved,
+				BlockNumber:    submitted.Raw.BlockNumber,
+			})
+		},
+	)
+}
+
+// onDKGResultSubmittedEvent subscribes to DkgResultSubmittedEvent events
+// which both OnGroupRegistered and OnDKGResultSubmitted are based on.
+func (ec *ethereumChain) onDKGResultSubmittedEvent(
+	trackedName string,
+	handle func(submitted *abi.KeepRandomBeaconOperatorDkgResultSubmittedEvent),
+) subscription.EventSubscription {
+	toTrackedEvent := func(
+		submitted *abi.KeepRandomBeaconOperatorDkgResultSubmittedEvent,
+	) trackedEvent {
+		return trackedEvent{submitted.Raw, func() { handle(submitted) }}
+	}
+
+	sink := make(chan *abi.KeepRandomBeaconOperatorDkgResultSubmittedEvent)
+	tracker, subscription := ec.trackEvents(
+		trackedName,
+		ec.keepRandomBeaconOperator().DkgResultSubmittedEvent(nil).Pipe(sink),
+		func(fromBlock, toBlock uint64) ([]trackedEvent, error) {
+			events, err := ec.keepRandomBeaconOperator().PastDkgResultSubmittedEventEvents(
+				fromBlock,
+				&toBlock,
+			)
+			if err != nil {
+				return nil, err
+			}
+
+			tracked := make([]trackedEvent, len(events))
+			for i, submitted := range events {
+				tracked[i] = toTrackedEvent(submitted)
+			}
+			return tracked, nil
+		},
+	)
+
+	go func() {
+		for {
+			select {
+			case submitted := <-sink:
+				tracker.process(toTrackedEvent(submitted))
+			case <-tracker.done:
+				return
+			}
+		}
+	}()
 
 	return subscription
 }
 
 func (ec *ethereumChain) ReportRelayEntryTimeout() error {
-	_, err := ec.keepRandomBeaconOperatorContract.ReportRelayEntryTimeout()
+	submitter := ec.transactionSubmitter(reportRelayEntryTimeoutTransaction)
+
+	// The timeout can be reported by anyone at any time once the relay
+	// entry timed out so the transaction has no deadline.
+	transactionOptions, err := submitter.pricer.transactionOptions(
+		submissionWindow{},
+		0,
+	)
 	if err != nil {
 		return err
 	}
 
+	transaction, err := submitter.operatorContract.ReportRelayEntryTimeout(
+		transactionOptions,
+	)
+	if err != nil {
+		return err
+	}
+
+	submitter.pricer.recordSubmission(transaction, submissionWindow{})
+	ec.recordDeadline(transaction, submissionWindow{})
+
 	return nil
 }
 
 func (ec *ethereumChain) IsEntryInProgress() (bool, error) {
-	return ec.keepRandomBeaconOperatorContract.IsEntryInProgress()
+	return ec.keepRandomBeaconOperator().IsEntryInProgress()
 }
 
 func (ec *ethereumChain) CurrentRequestStartBlock() (*big.Int, error) {
-	return ec.keepRandomBeaconOperatorContract.CurrentRequestStartBlock()
+	return ec.keepRandomBeaconOperator().CurrentRequestStartBlock()
 }
 
 func (ec *ethereumChain) CurrentRequestPreviousEntry() ([]byte, error) {
-	return ec.keepRandomBeaconOperatorContract.CurrentRequestPreviousEntry()
+	return ec.keepRandomBeaconOperator().CurrentRequestPreviousEntry()
 }
 
 func (ec *ethereumChain) CurrentRequestGroupPublicKey() ([]byte, error) {
-	currentRequestGroupIndex, err := ec.keepRandomBeaconOperatorContract.CurrentRequestGroupIndex()
+	currentRequestGroupIndex, err := ec.keepRandomBeaconOperator().CurrentRequestGroupIndex()
 	if err != nil {
 		return nil, err
 	}
 
-	return ec.keepRandomBeaconOperatorContract.GetGroupPublicKey(currentRequestGroupIndex)
+	return ec.keepRandomBeaconOperator().GetGroupPublicKey(currentRequestGroupIndex)
 }
 
 func (ec *ethereumChain) SubmitDKGResult(
@@ -432,16 +703,44 @@ func (ec *ethereumChain) SubmitDKGResult(
 		return resultPublicationPromise
 	}
 
-	if _, err = ec.keepRandomBeaconOperatorContract.SubmitDkgResult(
+	// The result may have been submitted before the restart of the client
+	// and replayed from the outbox. The promise is fulfilled once the
+	// replayed transaction is mined.
+	if ec.hasPendingDKGResult(result.GroupPublicKey) {
+		logger.Infof("DKG result is already pending; not submitting it again")
+		return resultPublicationPromise
+	}
+
+	submitter := ec.transactionSubmitter(submitDKGResultTransaction)
+
+	window := ec.dkgResultSubmissionWindow(participantIndex)
+
+	transactionOptions, err := submitter.pricer.transactionOptions(
+		window,
+		0,
+	)
+	if err != nil {
+		subscription.Unsubscribe()
+		close(publishedResult)
+		failPromise(err)
+		return resultPublicationPromise
+	}
+
+	transaction, err := submitter.operatorContract.SubmitDkgResult(
 		big.NewInt(int64(participantIndex)),
 		result.GroupPublicKey,
 		result.Misbehaved,
 		signaturesOnChainFormat,
 		membersIndicesOnChainFormat,
-	); err != nil {
+		transactionOptions,
+	)
+	if err != nil {
 		subscription.Unsubscribe()
 		close(publishedResult)
 		failPromise(err)
+	} else {
+		submitter.pricer.recordSubmission(transaction, window)
+		ec.recordDeadline(transaction, window)
 	}
 
 	return resultPublicationPromise
@@ -490,5 +789,5 @@ func (ec *ethereumChain) CalculateDKGResultHash(
 }
 
 func (ec *ethereumChain) Address() common.Address {
-	return ec.accountKey.Address
+	return ec.signer.Address()
 }
@@ -0,0 +1,357 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/keep-network/keep-core/pkg/subscription"
+)
+
+const (
+	// backfillChunkSize is the maximum number of blocks past events are
+	// fetched for with a single request during the backfill.
+	backfillChunkSize = 1000
+
+	// deduplicationBlocks is the number of blocks before the last processed
+	// block for which identifiers of processed events are kept. It has to
+	// cover the range of past blocks the subscription monitoring of generated
+	// contracts pulls events for.
+	deduplicationBlocks = 1000
+
+	// backfillTimeout is the timeout of the request for the current block
+	// made before the backfill.
+	backfillTimeout = 30 * time.Second
+
+	// backfillRetryDelay is the initial delay before a failed backfill is
+	// retried. The delay doubles with each subsequent failure up to
+	// maxBackfillRetryDelay.
+	backfillRetryDelay    = 5 * time.Second
+	maxBackfillRetryDelay = 5 * time.Minute
+)
+
+// trackedEvent is a single event received by the event subscription along
+// with the function handling it.
+type trackedEvent struct {
+	raw    types.Log
+	handle func()
+}
+
+// eventID uniquely identifies an event in the chain.
+type eventID struct {
+	blockNumber uint64
+	logIndex    uint
+}
+
+// eventTracker tracks events handled by an event subscription along with the
+// last block for which all events have been handled. Events received live
+// and events fetched during the backfill are handled only once. The last
+// processed block advances with the backfill as well as with live events and
+// blocks, as long as no backfill is pending. Live events and blocks advance it
+// only up to the live events delay before them, so that events of blocks which
+// could still be reorganized are fetched again by the backfill.
+type eventTracker struct {
+	eventName       string
+	liveEventsDelay uint64
+	pastEvents      func(fromBlock, toBlock uint64) ([]trackedEvent, error)
+	done            chan struct{}
+
+	backfillRetryDelay    time.Duration
+	maxBackfillRetryDelay time.Duration
+
+	mutex              sync.Mutex
+	lastProcessedBlock uint64
+	processed          map[eventID]bool
+	// established is closed once a subscription is established with the
+	// Ethereum client again, that is, once events might have been missed
+	// and a backfill is pending.
+	established <-chan struct{}
+}
+
+// newEventTracker creates a tracker of events with all events before the
+// given start block considered processed. If the start block is unknown,
+// zero should be passed and events before the block current at the time of
+// the first backfill are considered processed. Live events and blocks advance
+// the last processed block up to the given delay before them.
+func newEventTracker(
+	eventName string,
+	startBlock uint64,
+	liveEventsDelay uint64,
+	pastEvents func(fromBlock, toBlock uint64) ([]trackedEvent, error),
+) *eventTracker {
+	return &eventTracker{
+		eventName:             eventName,
+		liveEventsDelay:       liveEventsDelay,
+		pastEvents:            pastEvents,
+		done:                  make(chan struct{}),
+		backfillRetryDelay:    backfillRetryDelay,
+		maxBackfillRetryDelay: maxBackfillRetryDelay,
+		lastProcessedBlock:    startBlock,
+		processed:             make(map[eventID]bool),
+	}
+}
+
+// process handles the event unless it has been already handled. Events
+// removed by a chain reorganization are handled only if they have been handled
+// before, so that their handlers can retract them, and they can be handled
+// again if they are included in the chain again.
+func (et *eventTracker) process(event trackedEvent) {
+	if !et.markProcessed(event.raw) {
+		return
+	}
+
+	event.handle()
+
+	if !event.raw.Removed {
+		et.advanceLive(event.raw.BlockNumber)
+	}
+}
+
+// awaitBackfill sets the channel closed once events might have been missed
+// and the next backfill is pending.
+func (et *eventTracker) awaitBackfill(established <-chan struct{}) {
+	et.mutex.Lock()
+	defer et.mutex.Unlock()
+
+	et.established = established
+}
+
+// advanceLive advances the last processed block with the given block of
+// a live event or a new block observed by the client. The last processed
+// block is not advanced while a backfill is pending so that missed events
+// are fetched by the backfill.
+func (et *eventTracker) advanceLive(block uint64) {
+	et.mutex.Lock()
+	established := et.established
+	et.mutex.Unlock()
+
+	if established != nil {
+		select {
+		case <-established:
+			return
+		default:
+		}
+	}
+
+	if block <= et.liveEventsDelay {
+		return
+	}
+
+	et.advance(block - et.liveEventsDelay)
+}
+
+func (et *eventTracker) markProcessed(raw types.Log) bool {
+	et.mutex.Lock()
+	defer et.mutex.Unlock()
+
+	id := eventID{raw.BlockNumber, raw.Index}
+	if raw.Removed {
+		if !et.processed[id] {
+			return false
+		}
+
+		delete(et.processed, id)
+		return true
+	}
+
+	if et.processed[id] {
+		return false
+	}
+
+	et.processed[id] = true
+	return true
+}
+
+// backfill fetches events emitted since the last processed block until the
+// current block and handles all of them which have not been handled yet.
+func (et *eventTracker) backfill(currentBlock uint64) error {
+	et.mutex.Lock()
+	fromBlock := et.lastProcessedBlock
+	et.mutex.Unlock()
+
+	if fromBlock == 0 {
+		et.advance(currentBlock)
+		return nil
+	}
+
+	// Events of the last processed block are fetched again as the block
+	// might have been processed only partially.
+	for fromBlock <= currentBlock {
+		toBlock := fromBlock + backfillChunkSize - 1
+		if toBlock > currentBlock {
+			toBlock = currentBlock
+		}
+
+		events, err := et.pastEvents(fromBlock, toBlock)
+		if err != nil {
+			return fmt.Errorf(
+				"could not fetch past [%v] events from blocks [%v-%v]: [%v]",
+				et.eventName,
+				fromBlock,
+				toBlock,
+				err,
+			)
+		}
+
+		sort.SliceStable(events, func(i, j int) bool {
+			if events[i].raw.BlockNumber != events[j].raw.BlockNumber {
+				return events[i].raw.BlockNumber < events[j].raw.BlockNumber
+			}
+			return events[i].raw.Index < events[j].raw.Index
+		})
+
+		for _, event := range events {
+			if et.markProcessed(event.raw) {
+				logger.Warningf(
+					"backfilled missed [%v] event from block [%v]",
+					et.eventName,
+					event.raw.BlockNumber,
+				)
+				event.handle()
+			}
+		}
+
+		et.advance(toBlock)
+		fromBlock = toBlock + 1
+	}
+
+	return nil
+}
+
+// advance moves the last processed block forward and forgets events too old
+// to be received again.
+func (et *eventTracker) advance(block uint64) {
+	et.mutex.Lock()
+	defer et.mutex.Unlock()
+
+	if block <= et.lastProcessedBlock {
+		return
+	}
+	et.lastProcessedBlock = block
+
+	if block < deduplicationBlocks {
+		return
+	}
+	for id := range et.processed {
+		if id.blockNumber < block-deduplicationBlocks {
+			delete(et.processed, id)
+		}
+	}
+}
+
+// trackEvents creates a tracker of the given event and backfills missed
+// events each time a subscription is established with the Ethereum client,
+// that is, after the connection of any event subscription has been restored.
+// The tracker stops once the returned subscription is unsubscribed.
+func (ec *ethereumChain) trackEvents(
+	eventName string,
+	live subscription.EventSubscription,
+	pastEvents func(fromBlock, toBlock uint64) ([]trackedEvent, error),
+) (*eventTracker, subscription.EventSubscription) {
+	startBlock, err := ec.blockCounter.CurrentBlock()
+	if err != nil {
+		logger.Warningf(
+			"could not determine start block of [%v] events: [%v]",
+			eventName,
+			err,
+		)
+	}
+
+	tracker := newEventTracker(
+		eventName,
+		startBlock,
+		ec.liveEventsDelay,
+		pastEvents,
+	)
+
+	established := ec.subscriptionEstablished()
+	tracker.awaitBackfill(established)
+
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	blocks := ec.blockCounter.WatchBlocks(ctx)
+
+	go func() {
+		defer cancelCtx()
+
+		tracker.run(
+			established,
+			ec.subscriptionEstablished,
+			func() error { return ec.backfill(tracker) },
+			blocks,
+		)
+	}()
+
+	return tracker, subscription.NewEventSubscription(func() {
+		live.Unsubscribe()
+		close(tracker.done)
+	})
+}
+
+// run backfills missed events each time a subscription is established and
+// advances the last processed block with new blocks until the tracker is
+// done. The given channel is the one the tracker currently awaits the backfill
+// with. A failed backfill is retried with an increasing delay until it
+// succeeds; until then, the last processed block is not advanced.
+func (et *eventTracker) run(
+	established <-chan struct{},
+	subscriptionEstablished func() <-chan struct{},
+	backfill func() error,
+	blocks <-chan uint64,
+) {
+	var retry <-chan time.Time
+	retryDelay := et.backfillRetryDelay
+
+	runBackfill := func() {
+		if err := backfill(); err != nil {
+			logger.Errorf(
+				"could not backfill [%v] events; retrying in [%v]: [%v]",
+				et.eventName,
+				retryDelay,
+				err,
+			)
+
+			retry = time.After(retryDelay)
+			retryDelay *= 2
+			if retryDelay > et.maxBackfillRetryDelay {
+				retryDelay = et.maxBackfillRetryDelay
+			}
+			return
+		}
+
+		retry = nil
+		retryDelay = et.backfillRetryDelay
+		et.awaitBackfill(established)
+	}
+
+	for {
+		select {
+		case <-established:
+			// If another subscription is established before the backfill
+			// completes, the new channel is already closed and the backfill
+			// is run again.
+			established = subscriptionEstablished()
+			runBackfill()
+		case <-retry:
+			runBackfill()
+		case block := <-blocks:
+			et.advanceLive(block)
+		case <-et.done:
+			return
+		}
+	}
+}
+
+func (ec *ethereumChain) backfill(tracker *eventTracker) error {
+	ctx, cancel := context.WithTimeout(context.Background(), backfillTimeout)
+	defer cancel()
+
+	header, err := ec.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not get current block: [%v]", err)
+	}
+
+	return tracker.backfill(header.Number.Uint64())
+}
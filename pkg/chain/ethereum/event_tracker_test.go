@@ -0,0 +1,328 @@
+package ethereum
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const testLiveEventsDelay = 5
+
+func TestEventTrackerProcessesEventOnce(t *testing.T) {
+	tracker := newEventTracker("Test", 100, testLiveEventsDelay, nil)
+
+	var handled []uint
+	for _, logIndex := range []uint{1, 2, 1} {
+		logIndex := logIndex
+		tracker.process(trackedEvent{
+			raw:    types.Log{BlockNumber: 101, Index: logIndex},
+			handle: func() { handled = append(handled, logIndex) },
+		})
+	}
+
+	expectedHandled := []uint{1, 2}
+	if !reflect.DeepEqual(expectedHandled, handled) {
+		t.Errorf(
+			"unexpected handled events\nexpected: [%v]\nactual:   [%v]",
+			expectedHandled,
+			handled,
+		)
+	}
+}
+
+func TestEventTrackerProcessesRemovedEvents(t *testing.T) {
+	tracker := newEventTracker("Test", 100, testLiveEventsDelay, nil)
+
+	var handled []string
+	for _, raw := range []types.Log{
+		{BlockNumber: 101, Index: 2, Removed: true},
+		{BlockNumber: 101, Index: 1},
+		{BlockNumber: 101, Index: 1, Removed: true},
+		{BlockNumber: 101, Index: 1, Removed: true},
+		{BlockNumber: 101, Index: 1},
+	} {
+		raw := raw
+		tracker.process(trackedEvent{
+			raw: raw,
+			handle: func() {
+				handled = append(
+					handled,
+					fmt.Sprintf("%v:%v", raw.Index, raw.Removed),
+				)
+			},
+		})
+	}
+
+	// Removals of events not handled before are ignored and removed events
+	// are handled again once they are back in the chain.
+	expectedHandled := []string{"1:false", "1:true", "1:false"}
+	if !reflect.DeepEqual(expectedHandled, handled) {
+		t.Errorf(
+			"unexpected handled events\nexpected: [%v]\nactual:   [%v]",
+			expectedHandled,
+			handled,
+		)
+	}
+}
+
+func TestEventTrackerBackfillsMissedEvents(t *testing.T) {
+	var handled []uint64
+	var requestedRanges [][2]uint64
+
+	chainEvents := []types.Log{
+		{BlockNumber: 100, Index: 0},
+		{BlockNumber: 101, Index: 3},
+		{BlockNumber: 900, Index: 0},
+		{BlockNumber: 1500, Index: 2},
+		{BlockNumber: 1500, Index: 1},
+	}
+
+	tracker := newEventTracker(
+		"Test",
+		100,
+		testLiveEventsDelay,
+		func(fromBlock, toBlock uint64) ([]trackedEvent, error) {
+			requestedRanges = append(requestedRanges, [2]uint64{fromBlock, toBlock})
+
+			var events []trackedEvent
+			for _, raw := range chainEvents {
+				raw := raw
+				if raw.BlockNumber >= fromBlock && raw.BlockNumber <= toBlock {
+					events = append(events, trackedEvent{
+						raw: raw,
+						handle: func() {
+							handled = append(handled, raw.BlockNumber*10+uint64(raw.Index))
+						},
+					})
+				}
+			}
+			return events, nil
+		},
+	)
+
+	// received live before the connection has been lost
+	tracker.process(trackedEvent{
+		raw:    chainEvents[0],
+		handle: func() { handled = append(handled, 1000) },
+	})
+
+	if err := tracker.backfill(1600); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedHandled := []uint64{1000, 1013, 9000, 15001, 15002}
+	if !reflect.DeepEqual(expectedHandled, handled) {
+		t.Errorf(
+			"unexpected handled events\nexpected: [%v]\nactual:   [%v]",
+			expectedHandled,
+			handled,
+		)
+	}
+
+	expectedRanges := [][2]uint64{{100, 1099}, {1100, 1600}}
+	if !reflect.DeepEqual(expectedRanges, requestedRanges) {
+		t.Errorf(
+			"unexpected requested ranges\nexpected: [%v]\nactual:   [%v]",
+			expectedRanges,
+			requestedRanges,
+		)
+	}
+
+	if tracker.lastProcessedBlock != 1600 {
+		t.Errorf(
+			"unexpected last processed block\nexpected: [%v]\nactual:   [%v]",
+			1600,
+			tracker.lastProcessedBlock,
+		)
+	}
+}
+
+func TestEventTrackerBackfillWithUnknownStartBlock(t *testing.T) {
+	tracker := newEventTracker(
+		"Test",
+		0,
+		testLiveEventsDelay,
+		func(fromBlock, toBlock uint64) ([]trackedEvent, error) {
+			t.Fatalf("unexpected request for past events")
+			return nil, nil
+		},
+	)
+
+	if err := tracker.backfill(1600); err != nil {
+		t.Fatal(err)
+	}
+
+	if tracker.lastProcessedBlock != 1600 {
+		t.Errorf(
+			"unexpected last processed block\nexpected: [%v]\nactual:   [%v]",
+			1600,
+			tracker.lastProcessedBlock,
+		)
+	}
+}
+
+func TestEventTrackerFailedBackfill(t *testing.T) {
+	tracker := newEventTracker(
+		"Test",
+		100,
+		testLiveEventsDelay,
+		func(fromBlock, toBlock uint64) ([]trackedEvent, error) {
+			return nil, fmt.Errorf("connection refused")
+		},
+	)
+
+	err := tracker.backfill(200)
+
+	expectedError := "could not fetch past [Test] events from blocks [100-200]: " +
+		"[connection refused]"
+	if err == nil || err.Error() != expectedError {
+		t.Fatalf(
+			"unexpected error\nexpected: [%v]\nactual:   [%v]",
+			expectedError,
+			err,
+		)
+	}
+
+	if tracker.lastProcessedBlock != 100 {
+		t.Errorf(
+			"unexpected last processed block\nexpected: [%v]\nactual:   [%v]",
+			100,
+			tracker.lastProcessedBlock,
+		)
+	}
+}
+
+func TestEventTrackerRetriesFailedBackfill(t *testing.T) {
+	attempts := make(chan int, 10)
+	attempt := 0
+
+	tracker := newEventTracker(
+		"Test",
+		100,
+		testLiveEventsDelay,
+		func(fromBlock, toBlock uint64) ([]trackedEvent, error) {
+			return nil, nil
+		},
+	)
+	tracker.backfillRetryDelay = 10 * time.Millisecond
+	tracker.maxBackfillRetryDelay = 20 * time.Millisecond
+	defer close(tracker.done)
+
+	established := make(chan struct{})
+	tracker.awaitBackfill(established)
+
+	blocks := make(chan uint64)
+
+	go tracker.run(
+		established,
+		func() <-chan struct{} { return make(chan struct{}) },
+		func() error {
+			attempt++
+			attempts <- attempt
+			if attempt < 3 {
+				return fmt.Errorf("connection refused")
+			}
+			return tracker.backfill(200)
+		},
+		blocks,
+	)
+
+	close(established)
+
+	for expected := 1; expected <= 3; expected++ {
+		select {
+		case actual := <-attempts:
+			if actual != expected {
+				t.Fatalf(
+					"unexpected backfill attempt\nexpected: [%v]\nactual:   [%v]",
+					expected,
+					actual,
+				)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("backfill attempt [%v] has not been made", expected)
+		}
+	}
+
+	// Once the backfill succeeded, the last processed block advances with
+	// new blocks again.
+	blocks <- 300
+	// Blocks are handled one by one so the first block has been handled
+	// once the next one is received.
+	blocks <- 300
+
+	tracker.mutex.Lock()
+	lastProcessedBlock := tracker.lastProcessedBlock
+	tracker.mutex.Unlock()
+
+	if lastProcessedBlock != 300-testLiveEventsDelay {
+		t.Errorf(
+			"unexpected last processed block\nexpected: [%v]\nactual:   [%v]",
+			300-testLiveEventsDelay,
+			lastProcessedBlock,
+		)
+	}
+}
+
+func TestEventTrackerAdvancesWithLiveEventsAndBlocks(t *testing.T) {
+	tracker := newEventTracker("Test", 100, testLiveEventsDelay, nil)
+	tracker.awaitBackfill(make(chan struct{}))
+
+	tracker.process(trackedEvent{
+		raw:    types.Log{BlockNumber: 101, Index: 0},
+		handle: func() {},
+	})
+	tracker.process(trackedEvent{
+		raw:    types.Log{BlockNumber: 200, Index: 0},
+		handle: func() {},
+	})
+
+	assertLastProcessedBlock(t, tracker, 200-testLiveEventsDelay)
+
+	tracker.advanceLive(101 + deduplicationBlocks + testLiveEventsDelay + 1)
+
+	assertLastProcessedBlock(t, tracker, 101+deduplicationBlocks+1)
+
+	// the event from block 101 is too old to be received again
+	expectedProcessed := map[eventID]bool{{200, 0}: true}
+	if !reflect.DeepEqual(expectedProcessed, tracker.processed) {
+		t.Errorf(
+			"unexpected processed events\nexpected: [%v]\nactual:   [%v]",
+			expectedProcessed,
+			tracker.processed,
+		)
+	}
+}
+
+func TestEventTrackerDoesNotAdvanceWithPendingBackfill(t *testing.T) {
+	tracker := newEventTracker("Test", 100, testLiveEventsDelay, nil)
+
+	established := make(chan struct{})
+	tracker.awaitBackfill(established)
+	close(established)
+
+	tracker.process(trackedEvent{
+		raw:    types.Log{BlockNumber: 200, Index: 0},
+		handle: func() {},
+	})
+	tracker.advanceLive(300)
+
+	assertLastProcessedBlock(t, tracker, 100)
+}
+
+func assertLastProcessedBlock(
+	t *testing.T,
+	tracker *eventTracker,
+	expectedBlock uint64,
+) {
+	if tracker.lastProcessedBlock != expectedBlock {
+		t.Errorf(
+			"unexpected last processed block\nexpected: [%v]\nactual:   [%v]",
+			expectedBlock,
+			tracker.lastProcessedBlock,
+		)
+	}
+}
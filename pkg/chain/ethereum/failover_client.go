@@ -0,0 +1,855 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/url"
+	"sync"
+	"time"
+
+	hostchain "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/keep-network/keep-common/pkg/chain/ethereum/ethutil"
+	"github.com/keep-network/keep-core/pkg/metrics"
+)
+
+var (
+	// DefaultProbeInterval is the default interval in which health of all
+	// Ethereum endpoints is probed.
+	DefaultProbeInterval = 15 * time.Second
+
+	// DefaultMaxBlockLag is the default maximum number of blocks an Ethereum
+	// endpoint may lag behind the most advanced endpoint to be considered
+	// healthy.
+	DefaultMaxBlockLag = 3
+
+	// DefaultMaxErrorRate is the default maximum percentage of requests which
+	// may fail because of an Ethereum endpoint between two subsequent health
+	// probes for the endpoint to be considered healthy.
+	DefaultMaxErrorRate = 25
+
+	// DefaultMaxLatency is the default maximum latency of the health probe
+	// of an Ethereum endpoint for the endpoint to be considered healthy.
+	DefaultMaxLatency = 5 * time.Second
+
+	// DefaultLiveEventsDelay is the default number of blocks live events may
+	// be delivered behind the block in which they have been emitted or behind
+	// the latest block observed by the client.
+	DefaultLiveEventsDelay = 12
+)
+
+// minErrorRateRequests is the minimum number of requests sent to an endpoint
+// between two subsequent health probes for the error rate of the endpoint to
+// be taken into account. It prevents a single failure from rendering an
+// otherwise idle endpoint unhealthy.
+const minErrorRateRequests = 10
+
+// FailoverConfig stores configuration of additional Ethereum endpoints the
+// client fails over to when the endpoint in use becomes unhealthy.
+type FailoverConfig struct {
+	// Endpoints lists websocket URLs of additional Ethereum nodes in order
+	// of preference. The node configured in the Ethereum section is always
+	// preferred over them.
+	Endpoints []string
+	// ProbeInterval is the interval in seconds in which health of all
+	// endpoints is probed.
+	ProbeInterval int
+	// MaxBlockLag is the maximum number of blocks an endpoint may lag behind
+	// the most advanced endpoint to be considered healthy.
+	MaxBlockLag int
+	// MaxErrorRate is the maximum percentage of requests which may fail
+	// because of an endpoint between two subsequent health probes for the
+	// endpoint to be considered healthy.
+	MaxErrorRate int
+	// MaxLatency is the maximum latency in milliseconds of the health probe
+	// of an endpoint for the endpoint to be considered healthy.
+	MaxLatency int
+	// LiveEventsDelay is the number of blocks behind the latest live event or
+	// block up to which events are considered processed and are not fetched
+	// again after the connection is restored. It has to be at least the event
+	// confirmation depth so that events reorganized out of the chain and
+	// included again are fetched.
+	LiveEventsDelay int
+}
+
+func (fc FailoverConfig) probeInterval() time.Duration {
+	if fc.ProbeInterval > 0 {
+		return time.Duration(fc.ProbeInterval) * time.Second
+	}
+	return DefaultProbeInterval
+}
+
+func (fc FailoverConfig) maxBlockLag() uint64 {
+	if fc.MaxBlockLag > 0 {
+		return uint64(fc.MaxBlockLag)
+	}
+	return uint64(DefaultMaxBlockLag)
+}
+
+func (fc FailoverConfig) maxErrorRate() float64 {
+	if fc.MaxErrorRate > 0 {
+		return float64(fc.MaxErrorRate) / 100
+	}
+	return float64(DefaultMaxErrorRate) / 100
+}
+
+func (fc FailoverConfig) liveEventsDelay() uint64 {
+	if fc.LiveEventsDelay > 0 {
+		return uint64(fc.LiveEventsDelay)
+	}
+	return uint64(DefaultLiveEventsDelay)
+}
+
+func (fc FailoverConfig) maxLatency() time.Duration {
+	if fc.MaxLatency > 0 {
+		return time.Duration(fc.MaxLatency) * time.Millisecond
+	}
+	return DefaultMaxLatency
+}
+
+// endpoint is a single Ethereum node the failover client may use along with
+// the health of the node observed by the client.
+type endpoint struct {
+	url     string
+	name    string
+	dial    func(url string) (ethutil.HostChainClient, error)
+	metrics *metrics.Ethereum
+
+	mutex       sync.Mutex
+	client      ethutil.HostChainClient
+	blockNumber uint64
+	latency     time.Duration
+	failure     error
+	requests    int
+	failures    int
+	healthy     bool
+}
+
+func dialEndpoint(url string) (ethutil.HostChainClient, error) {
+	return ethclient.Dial(url)
+}
+
+// connectedClient returns the client of the endpoint, dialing the endpoint if
+// it has not been connected yet.
+func (e *endpoint) connectedClient() (ethutil.HostChainClient, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.client == nil {
+		client, err := e.dial(e.url)
+		if err != nil {
+			return nil, fmt.Errorf("could not connect: [%v]", err)
+		}
+		e.client = client
+	}
+
+	return e.client, nil
+}
+
+// record counts the request to the endpoint completed with the given error.
+// Returns true if the request failed because of the endpoint. Failures only
+// add up to the error rate of the endpoint; health of the endpoint is decided
+// when the endpoint is probed.
+func (e *endpoint) record(err error) bool {
+	failed := isEndpointFailure(err)
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.requests++
+	if e.metrics != nil {
+		e.metrics.EndpointRequests.Inc(e.name)
+	}
+
+	if failed {
+		e.failures++
+		if e.metrics != nil {
+			e.metrics.EndpointErrors.Inc(e.name)
+		}
+	}
+
+	return failed
+}
+
+func (e *endpoint) isHealthy() bool {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	return e.healthy
+}
+
+// isEndpointFailure determines whether the request failed because of the
+// endpoint. Errors returned by the Ethereum node itself, like reverted calls,
+// and requests cancelled by the caller or exceeding the caller's deadline are
+// not failures of the endpoint.
+func isEndpointFailure(err error) bool {
+	if err == nil ||
+		err == hostchain.NotFound ||
+		err == context.Canceled ||
+		err == context.DeadlineExceeded {
+		return false
+	}
+
+	if _, ok := err.(rpc.Error); ok {
+		return false
+	}
+
+	return true
+}
+
+// failoverClient is a host chain client sending requests to one of multiple
+// Ethereum endpoints. Health of all endpoints is probed periodically and
+// requests are sent to the most preferred healthy endpoint. Requests which
+// failed because of the endpoint are retried on another endpoint, if there
+// is a healthy one. Subscriptions made with an endpoint which is no longer in
+// use are terminated with an error so that subscribers can resubscribe.
+type failoverClient struct {
+	config    FailoverConfig
+	endpoints []*endpoint
+	metrics   *metrics.Ethereum
+
+	mutex       sync.RWMutex
+	active      *endpoint
+	switched    chan struct{}
+	established chan struct{}
+}
+
+// connectFailoverClient creates a failover client of the given primary
+// endpoint and the additional endpoints from the config. Endpoints which
+// could not be connected are dialed again on subsequent health probes. An
+// error is returned only if none of the endpoints could be connected.
+// Endpoints are measured with the given metrics unless they are nil.
+func connectFailoverClient(
+	ctx context.Context,
+	primaryURL string,
+	config FailoverConfig,
+	ethereumMetrics *metrics.Ethereum,
+) (*failoverClient, error) {
+	client := newFailoverClient(
+		config,
+		append([]string{primaryURL}, config.Endpoints...),
+		dialEndpoint,
+		ethereumMetrics,
+	)
+
+	client.probe()
+	if _, err := client.activeEndpoint().connectedClient(); err != nil {
+		return nil, fmt.Errorf(
+			"could not connect any of the Ethereum endpoints: [%v]",
+			err,
+		)
+	}
+
+	go client.probeLoop(ctx)
+
+	return client, nil
+}
+
+func newFailoverClient(
+	config FailoverConfig,
+	urls []string,
+	dial func(url string) (ethutil.HostChainClient, error),
+	ethereumMetrics *metrics.Ethereum,
+) *failoverClient {
+	names := make(map[string]int)
+
+	endpoints := make([]*endpoint, len(urls))
+	for i, rawURL := range urls {
+		name := endpointName(rawURL)
+		if count := names[name]; count > 0 {
+			names[name]++
+			name = fmt.Sprintf("%v#%v", name, count+1)
+		} else {
+			names[name] = 1
+		}
+
+		endpoints[i] = &endpoint{
+			url:     rawURL,
+			name:    name,
+			dial:    dial,
+			metrics: ethereumMetrics,
+			healthy: true,
+		}
+	}
+
+	if ethereumMetrics != nil {
+		ethereumMetrics.EndpointActive.Set(1, endpoints[0].name)
+	}
+
+	return &failoverClient{
+		config:      config,
+		endpoints:   endpoints,
+		metrics:     ethereumMetrics,
+		active:      endpoints[0],
+		switched:    make(chan struct{}),
+		established: make(chan struct{}),
+	}
+}
+
+// endpointName returns the host of the endpoint URL so that endpoints can be
+// identified in logs and metrics without exposing credentials which may be
+// part of the URL path.
+func endpointName(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return "unknown"
+	}
+
+	return parsed.Host
+}
+
+// current returns the endpoint in use along with the channel closed once
+// another endpoint is taken into use.
+func (fc *failoverClient) current() (*endpoint, <-chan struct{}) {
+	fc.mutex.RLock()
+	defer fc.mutex.RUnlock()
+
+	return fc.active, fc.switched
+}
+
+// subscriptionEstablished returns the channel closed once the next
+// subscription is established with any of the endpoints. Subscribers can use
+// it to learn about subscriptions restored after a connection failure.
+func (fc *failoverClient) subscriptionEstablished() <-chan struct{} {
+	fc.mutex.RLock()
+	defer fc.mutex.RUnlock()
+
+	return fc.established
+}
+
+func (fc *failoverClient) notifySubscriptionEstablished() {
+	fc.mutex.Lock()
+	defer fc.mutex.Unlock()
+
+	close(fc.established)
+	fc.established = make(chan struct{})
+}
+
+func (fc *failoverClient) activeEndpoint() *endpoint {
+	active, _ := fc.current()
+	return active
+}
+
+func (fc *failoverClient) probeLoop(ctx context.Context) {
+	ticker := time.NewTicker(fc.config.probeInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			fc.probe()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// probe checks health of all endpoints and takes the most preferred healthy
+// endpoint into use. An endpoint is healthy if it responds to the probe
+// within the max latency, does not lag behind the most advanced endpoint more
+// than the max block lag and requests sent to it since the last probe did not
+// fail more often than the max error rate allows.
+func (fc *failoverClient) probe() {
+	maxLatency := fc.config.maxLatency()
+
+	var wait sync.WaitGroup
+	for _, e := range fc.endpoints {
+		wait.Add(1)
+		go func(e *endpoint) {
+			defer wait.Done()
+
+			blockNumber, latency, err := probeEndpoint(e, maxLatency)
+
+			e.mutex.Lock()
+			defer e.mutex.Unlock()
+
+			e.failure = err
+			e.latency = latency
+			if err == nil {
+				e.blockNumber = blockNumber
+			}
+		}(e)
+	}
+	wait.Wait()
+
+	var bestBlockNumber uint64
+	for _, e := range fc.endpoints {
+		e.mutex.Lock()
+		if e.failure == nil && e.blockNumber > bestBlockNumber {
+			bestBlockNumber = e.blockNumber
+		}
+		e.mutex.Unlock()
+	}
+
+	for _, e := range fc.endpoints {
+		fc.evaluate(e, bestBlockNumber)
+	}
+
+	fc.failover()
+}
+
+func probeEndpoint(
+	e *endpoint,
+	maxLatency time.Duration,
+) (uint64, time.Duration, error) {
+	client, err := e.connectedClient()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), maxLatency)
+	defer cancel()
+
+	start := time.Now()
+	header, err := client.HeaderByNumber(ctx, nil)
+	latency := time.Since(start)
+	if err != nil {
+		return 0, latency, fmt.Errorf("probe failed: [%v]", err)
+	}
+
+	return header.Number.Uint64(), latency, nil
+}
+
+// evaluate determines health of the endpoint after it has been probed and
+// resets the error rate of the endpoint.
+func (fc *failoverClient) evaluate(e *endpoint, bestBlockNumber uint64) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	blockLag := bestBlockNumber - e.blockNumber
+	if e.blockNumber > bestBlockNumber {
+		blockLag = 0
+	}
+
+	errorRate := float64(0)
+	if e.requests >= minErrorRateRequests {
+		errorRate = float64(e.failures) / float64(e.requests)
+	}
+
+	switch {
+	case e.failure != nil:
+		e.healthy = false
+		logger.Warningf("ethereum endpoint [%v] is unhealthy: [%v]", e.name, e.failure)
+	case blockLag > fc.config.maxBlockLag():
+		e.healthy = false
+		logger.Warningf(
+			"ethereum endpoint [%v] is unhealthy: lags [%v] blocks behind",
+			e.name,
+			blockLag,
+		)
+	case errorRate > fc.config.maxErrorRate():
+		e.healthy = false
+		logger.Warningf(
+			"ethereum endpoint [%v] is unhealthy: [%v] of [%v] requests failed",
+			e.name,
+			e.failures,
+			e.requests,
+		)
+	case e.latency > fc.config.maxLatency():
+		e.healthy = false
+		logger.Warningf(
+			"ethereum endpoint [%v] is unhealthy: probe latency [%v]",
+			e.name,
+			e.latency,
+		)
+	default:
+		e.healthy = true
+	}
+
+	e.requests = 0
+	e.failures = 0
+
+	if e.metrics == nil {
+		return
+	}
+
+	healthy := float64(0)
+	if e.healthy {
+		healthy = 1
+	}
+	e.metrics.EndpointHealthy.Set(healthy, e.name)
+	e.metrics.EndpointBlockLag.Set(float64(blockLag), e.name)
+	e.metrics.EndpointLatencySeconds.Set(e.latency.Seconds(), e.name)
+}
+
+// failover takes the most preferred healthy endpoint into use. If none of
+// the endpoints is healthy, the endpoint in use is kept.
+func (fc *failoverClient) failover() {
+	for _, e := range fc.endpoints {
+		if e.isHealthy() {
+			fc.use(e)
+			return
+		}
+	}
+}
+
+func (fc *failoverClient) use(e *endpoint) {
+	fc.mutex.Lock()
+	defer fc.mutex.Unlock()
+
+	if fc.active == e {
+		return
+	}
+
+	logger.Warningf(
+		"failing over from ethereum endpoint [%v] to [%v]",
+		fc.active.name,
+		e.name,
+	)
+
+	if fc.metrics != nil {
+		fc.metrics.EndpointActive.Set(0, fc.active.name)
+		fc.metrics.EndpointActive.Set(1, e.name)
+		fc.metrics.EndpointFailovers.Inc(e.name)
+	}
+
+	fc.active = e
+
+	close(fc.switched)
+	fc.switched = make(chan struct{})
+}
+
+// call sends the request to the endpoint in use. If the request fails because
+// of the endpoint, the request is retried on other healthy endpoints in order
+// of preference. The endpoint in use is changed only by health probes.
+func (fc *failoverClient) call(
+	request func(client ethutil.HostChainClient) error,
+) error {
+	_, err := fc.callEndpoint(request)
+	return err
+}
+
+// callEndpoint works like call but additionally returns the endpoint which
+// completed the request.
+func (fc *failoverClient) callEndpoint(
+	request func(client ethutil.HostChainClient) error,
+) (*endpoint, error) {
+	var err error
+	for _, e := range fc.requestEndpoints() {
+		client, clientErr := e.connectedClient()
+		if clientErr != nil {
+			err = clientErr
+		} else {
+			err = request(client)
+		}
+
+		if !e.record(err) {
+			return e, err
+		}
+
+		logger.Warningf(
+			"request to ethereum endpoint [%v] failed: [%v]",
+			e.name,
+			err,
+		)
+	}
+
+	return nil, err
+}
+
+// requestEndpoints returns the endpoint in use followed by other healthy
+// endpoints in order of preference.
+func (fc *failoverClient) requestEndpoints() []*endpoint {
+	active := fc.activeEndpoint()
+
+	endpoints := []*endpoint{active}
+	for _, e := range fc.endpoints {
+		if e != active && e.isHealthy() {
+			endpoints = append(endpoints, e)
+		}
+	}
+
+	return endpoints
+}
+
+// subscribe makes the subscription with the endpoint in use. The returned
+// subscription is terminated with an error once the endpoint fails or
+// another endpoint is taken into use.
+func (fc *failoverClient) subscribe(
+	subscribe func(client ethutil.HostChainClient) (hostchain.Subscription, error),
+) (hostchain.Subscription, error) {
+	_, switched := fc.current()
+
+	var subscription hostchain.Subscription
+	e, err := fc.callEndpoint(func(client ethutil.HostChainClient) error {
+		var err error
+		subscription, err = subscribe(client)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The endpoint may have been switched while subscribing.
+	if active, activeSwitched := fc.current(); active == e {
+		switched = activeSwitched
+	}
+
+	fc.notifySubscriptionEstablished()
+
+	return event.NewSubscription(func(unsubscribed <-chan struct{}) error {
+		defer subscription.Unsubscribe()
+
+		select {
+		case err := <-subscription.Err():
+			e.record(err)
+			return err
+		case <-switched:
+			return fmt.Errorf(
+				"ethereum endpoint [%v] is no longer in use",
+				e.name,
+			)
+		case <-unsubscribed:
+			return nil
+		}
+	}), nil
+}
+
+func (fc *failoverClient) CodeAt(
+	ctx context.Context,
+	contract common.Address,
+	blockNumber *big.Int,
+) ([]byte, error) {
+	var result []byte
+	err := fc.call(func(client ethutil.HostChainClient) error {
+		var err error
+		result, err = client.CodeAt(ctx, contract, blockNumber)
+		return err
+	})
+	return result, err
+}
+
+func (fc *failoverClient) CallContract(
+	ctx context.Context,
+	call hostchain.CallMsg,
+	blockNumber *big.Int,
+) ([]byte, error) {
+	var result []byte
+	err := fc.call(func(client ethutil.HostChainClient) error {
+		var err error
+		result, err = client.CallContract(ctx, call, blockNumber)
+		return err
+	})
+	return result, err
+}
+
+func (fc *failoverClient) PendingCodeAt(
+	ctx context.Context,
+	account common.Address,
+) ([]byte, error) {
+	var result []byte
+	err := fc.call(func(client ethutil.HostChainClient) error {
+		var err error
+		result, err = client.PendingCodeAt(ctx, account)
+		return err
+	})
+	return result, err
+}
+
+func (fc *failoverClient) PendingNonceAt(
+	ctx context.Context,
+	account common.Address,
+) (uint64, error) {
+	var result uint64
+	err := fc.call(func(client ethutil.HostChainClient) error {
+		var err error
+		result, err = client.PendingNonceAt(ctx, account)
+		return err
+	})
+	return result, err
+}
+
+func (fc *failoverClient) SuggestGasPrice(
+	ctx context.Context,
+) (*big.Int, error) {
+	var result *big.Int
+	err := fc.call(func(client ethutil.HostChainClient) error {
+		var err error
+		result, err = client.SuggestGasPrice(ctx)
+		return err
+	})
+	return result, err
+}
+
+func (fc *failoverClient) EstimateGas(
+	ctx context.Context,
+	call hostchain.CallMsg,
+) (uint64, error) {
+	var result uint64
+	err := fc.call(func(client ethutil.HostChainClient) error {
+		var err error
+		result, err = client.EstimateGas(ctx, call)
+		return err
+	})
+	return result, err
+}
+
+func (fc *failoverClient) SendTransaction(
+	ctx context.Context,
+	tx *types.Transaction,
+) error {
+	return fc.call(func(client ethutil.HostChainClient) error {
+		return client.SendTransaction(ctx, tx)
+	})
+}
+
+func (fc *failoverClient) FilterLogs(
+	ctx context.Context,
+	query hostchain.FilterQuery,
+) ([]types.Log, error) {
+	var result []types.Log
+	err := fc.call(func(client ethutil.HostChainClient) error {
+		var err error
+		result, err = client.FilterLogs(ctx, query)
+		return err
+	})
+	return result, err
+}
+
+func (fc *failoverClient) SubscribeFilterLogs(
+	ctx context.Context,
+	query hostchain.FilterQuery,
+	ch chan<- types.Log,
+) (hostchain.Subscription, error) {
+	return fc.subscribe(func(
+		client ethutil.HostChainClient,
+	) (hostchain.Subscription, error) {
+		return client.SubscribeFilterLogs(ctx, query, ch)
+	})
+}
+
+func (fc *failoverClient) BlockByHash(
+	ctx context.Context,
+	hash common.Hash,
+) (*types.Block, error) {
+	var result *types.Block
+	err := fc.call(func(client ethutil.HostChainClient) error {
+		var err error
+		result, err = client.BlockByHash(ctx, hash)
+		return err
+	})
+	return result, err
+}
+
+func (fc *failoverClient) BlockByNumber(
+	ctx context.Context,
+	number *big.Int,
+) (*types.Block, error) {
+	var result *types.Block
+	err := fc.call(func(client ethutil.HostChainClient) error {
+		var err error
+		result, err = client.BlockByNumber(ctx, number)
+		return err
+	})
+	return result, err
+}
+
+func (fc *failoverClient) HeaderByHash(
+	ctx context.Context,
+	hash common.Hash,
+) (*types.Header, error) {
+	var result *types.Header
+	err := fc.call(func(client ethutil.HostChainClient) error {
+		var err error
+		result, err = client.HeaderByHash(ctx, hash)
+		return err
+	})
+	return result, err
+}
+
+func (fc *failoverClient) HeaderByNumber(
+	ctx context.Context,
+	number *big.Int,
+) (*types.Header, error) {
+	var result *types.Header
+	err := fc.call(func(client ethutil.HostChainClient) error {
+		var err error
+		result, err = client.HeaderByNumber(ctx, number)
+		return err
+	})
+	return result, err
+}
+
+func (fc *failoverClient) TransactionCount(
+	ctx context.Context,
+	blockHash common.Hash,
+) (uint, error) {
+	var result uint
+	err := fc.call(func(client ethutil.HostChainClient) error {
+		var err error
+		result, err = client.TransactionCount(ctx, blockHash)
+		return err
+	})
+	return result, err
+}
+
+func (fc *failoverClient) TransactionInBlock(
+	ctx context.Context,
+	blockHash common.Hash,
+	index uint,
+) (*types.Transaction, error) {
+	var result *types.Transaction
+	err := fc.call(func(client ethutil.HostChainClient) error {
+		var err error
+		result, err = client.TransactionInBlock(ctx, blockHash, index)
+		return err
+	})
+	return result, err
+}
+
+func (fc *failoverClient) SubscribeNewHead(
+	ctx context.Context,
+	ch chan<- *types.Header,
+) (hostchain.Subscription, error) {
+	return fc.subscribe(func(
+		client ethutil.HostChainClient,
+	) (hostchain.Subscription, error) {
+		return client.SubscribeNewHead(ctx, ch)
+	})
+}
+
+func (fc *failoverClient) TransactionByHash(
+	ctx context.Context,
+	txHash common.Hash,
+) (*types.Transaction, bool, error) {
+	var result *types.Transaction
+	var isPending bool
+	err := fc.call(func(client ethutil.HostChainClient) error {
+		var err error
+		result, isPending, err = client.TransactionByHash(ctx, txHash)
+		return err
+	})
+	return result, isPending, err
+}
+
+func (fc *failoverClient) TransactionReceipt(
+	ctx context.Context,
+	txHash common.Hash,
+) (*types.Receipt, error) {
+	var result *types.Receipt
+	err := fc.call(func(client ethutil.HostChainClient) error {
+		var err error
+		result, err = client.TransactionReceipt(ctx, txHash)
+		return err
+	})
+	return result, err
+}
+
+func (fc *failoverClient) BalanceAt(
+	ctx context.Context,
+	account common.Address,
+	blockNumber *big.Int,
+) (*big.Int, error) {
+	var result *big.Int
+	err := fc.call(func(client ethutil.HostChainClient) error {
+		var err error
+		result, err = client.BalanceAt(ctx, account, blockNumber)
+		return err
+	})
+	return result, err
+}
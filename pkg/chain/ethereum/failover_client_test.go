@@ -0,0 +1,369 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	hostchain "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/keep-network/keep-common/pkg/chain/ethereum/ethutil"
+)
+
+func TestFailoverClientFailsOverToHealthyEndpoint(t *testing.T) {
+	client, endpoints := newTestFailoverClient(3)
+
+	endpoints[0].setBlockNumber(90)
+	client.probe()
+
+	assertActiveEndpoint(t, client, "node-1")
+
+	// The primary endpoint is preferred as soon as it is healthy again.
+	endpoints[0].setBlockNumber(100)
+	client.probe()
+
+	assertActiveEndpoint(t, client, "node-0")
+}
+
+func TestFailoverClientKeepsEndpointIfNoneIsHealthy(t *testing.T) {
+	client, endpoints := newTestFailoverClient(2)
+
+	endpoints[1].setBlockNumber(80)
+	client.probe()
+	endpoints[0].setBlockNumber(90)
+	endpoints[1].setFailure(fmt.Errorf("connection refused"))
+	client.probe()
+
+	assertActiveEndpoint(t, client, "node-0")
+}
+
+func TestFailoverClientRetriesRequestFailedBecauseOfEndpoint(t *testing.T) {
+	client, endpoints := newTestFailoverClient(2)
+
+	endpoints[0].setFailure(fmt.Errorf("connection reset by peer"))
+
+	balance, err := client.BalanceAt(context.Background(), common.Address{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if balance.Cmp(big.NewInt(1)) != 0 {
+		t.Errorf(
+			"unexpected balance\nexpected: [%v]\nactual:   [%v]",
+			1,
+			balance,
+		)
+	}
+
+	// A single failed request does not render the endpoint unhealthy;
+	// the endpoint is taken out of use only once the probe fails.
+	assertActiveEndpoint(t, client, "node-0")
+
+	client.probe()
+
+	assertActiveEndpoint(t, client, "node-1")
+}
+
+func TestFailoverClientFailsOverOnErrorRate(t *testing.T) {
+	client, endpoints := newTestFailoverClient(2)
+
+	endpoints[0].setBalanceFailure(fmt.Errorf("connection reset by peer"))
+
+	for i := 0; i < minErrorRateRequests; i++ {
+		if _, err := client.BalanceAt(
+			context.Background(),
+			common.Address{},
+			nil,
+		); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	assertActiveEndpoint(t, client, "node-0")
+
+	client.probe()
+
+	assertActiveEndpoint(t, client, "node-1")
+}
+
+func TestFailoverClientIgnoresErrorRateBelowMinimumRequests(t *testing.T) {
+	client, endpoints := newTestFailoverClient(2)
+
+	endpoints[0].setBalanceFailure(fmt.Errorf("connection reset by peer"))
+
+	for i := 0; i < minErrorRateRequests-1; i++ {
+		if _, err := client.BalanceAt(
+			context.Background(),
+			common.Address{},
+			nil,
+		); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	client.probe()
+
+	assertActiveEndpoint(t, client, "node-0")
+}
+
+func TestFailoverClientIgnoresCallerCancellations(t *testing.T) {
+	client, endpoints := newTestFailoverClient(2)
+
+	for _, callerErr := range []error{
+		context.Canceled,
+		context.DeadlineExceeded,
+	} {
+		endpoints[0].setBalanceFailure(callerErr)
+
+		for i := 0; i < minErrorRateRequests; i++ {
+			_, err := client.BalanceAt(context.Background(), common.Address{}, nil)
+			if err != callerErr {
+				t.Errorf(
+					"unexpected error\nexpected: [%v]\nactual:   [%v]",
+					callerErr,
+					err,
+				)
+			}
+		}
+	}
+
+	client.probe()
+
+	assertActiveEndpoint(t, client, "node-0")
+}
+
+func TestFailoverClientDoesNotFailOverOnNodeErrors(t *testing.T) {
+	client, endpoints := newTestFailoverClient(2)
+
+	nodeError := &testNodeError{"execution reverted"}
+	endpoints[0].setFailure(nodeError)
+
+	_, err := client.BalanceAt(context.Background(), common.Address{}, nil)
+	if err != nodeError {
+		t.Errorf(
+			"unexpected error\nexpected: [%v]\nactual:   [%v]",
+			nodeError,
+			err,
+		)
+	}
+
+	assertActiveEndpoint(t, client, "node-0")
+}
+
+func TestFailoverClientTerminatesSubscriptionOnFailover(t *testing.T) {
+	client, endpoints := newTestFailoverClient(2)
+
+	subscription, err := client.SubscribeNewHead(
+		context.Background(),
+		make(chan *types.Header),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	endpoints[0].setBlockNumber(90)
+	client.probe()
+
+	select {
+	case err := <-subscription.Err():
+		if err == nil {
+			t.Errorf("expected subscription error")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("subscription has not been terminated")
+	}
+
+	if !endpoints[0].isUnsubscribed() {
+		t.Errorf("subscription of the endpoint has not been unsubscribed")
+	}
+}
+
+func TestFailoverClientNotifiesAboutEstablishedSubscriptions(t *testing.T) {
+	client, _ := newTestFailoverClient(1)
+
+	established := client.subscriptionEstablished()
+
+	_, err := client.SubscribeNewHead(
+		context.Background(),
+		make(chan *types.Header),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-established:
+	default:
+		t.Errorf("subscription has not been notified")
+	}
+}
+
+func TestEndpointNames(t *testing.T) {
+	client := newFailoverClient(
+		FailoverConfig{},
+		[]string{
+			"wss://mainnet.infura.io/ws/v3/secret-project-id",
+			"wss://mainnet.infura.io/ws/v3/another-project-id",
+			"ws://127.0.0.1:8546",
+		},
+		nil,
+		nil,
+	)
+
+	expectedNames := []string{
+		"mainnet.infura.io",
+		"mainnet.infura.io#2",
+		"127.0.0.1:8546",
+	}
+	for i, endpoint := range client.endpoints {
+		if endpoint.name != expectedNames[i] {
+			t.Errorf(
+				"unexpected name of endpoint [%v]\nexpected: [%v]\nactual:   [%v]",
+				i,
+				expectedNames[i],
+				endpoint.name,
+			)
+		}
+	}
+}
+
+func newTestFailoverClient(
+	endpointsCount int,
+) (*failoverClient, []*testEndpointClient) {
+	urls := make([]string, endpointsCount)
+	endpoints := make(map[string]*testEndpointClient)
+	ordered := make([]*testEndpointClient, endpointsCount)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("ws://node-%v", i)
+		ordered[i] = &testEndpointClient{blockNumber: 100}
+		endpoints[urls[i]] = ordered[i]
+	}
+
+	client := newFailoverClient(
+		FailoverConfig{},
+		urls,
+		func(url string) (ethutil.HostChainClient, error) {
+			return endpoints[url], nil
+		},
+		nil,
+	)
+
+	return client, ordered
+}
+
+func assertActiveEndpoint(t *testing.T, client *failoverClient, name string) {
+	if active := client.activeEndpoint().name; active != name {
+		t.Errorf(
+			"unexpected active endpoint\nexpected: [%v]\nactual:   [%v]",
+			name,
+			active,
+		)
+	}
+}
+
+type testNodeError struct {
+	message string
+}
+
+func (tne *testNodeError) Error() string {
+	return tne.message
+}
+
+func (tne *testNodeError) ErrorCode() int {
+	return -32000
+}
+
+// testEndpointClient is a host chain client of a single endpoint. Only the
+// methods used by tests are implemented.
+type testEndpointClient struct {
+	ethutil.HostChainClient
+
+	mutex          sync.Mutex
+	blockNumber    int64
+	failure        error
+	balanceFailure error
+	unsubscribed   bool
+}
+
+func (tec *testEndpointClient) setBlockNumber(blockNumber int64) {
+	tec.mutex.Lock()
+	defer tec.mutex.Unlock()
+
+	tec.blockNumber = blockNumber
+}
+
+func (tec *testEndpointClient) setFailure(err error) {
+	tec.mutex.Lock()
+	defer tec.mutex.Unlock()
+
+	tec.failure = err
+}
+
+// setBalanceFailure makes balance requests fail with the given error while
+// health probes still succeed.
+func (tec *testEndpointClient) setBalanceFailure(err error) {
+	tec.mutex.Lock()
+	defer tec.mutex.Unlock()
+
+	tec.balanceFailure = err
+}
+
+func (tec *testEndpointClient) isUnsubscribed() bool {
+	tec.mutex.Lock()
+	defer tec.mutex.Unlock()
+
+	return tec.unsubscribed
+}
+
+func (tec *testEndpointClient) HeaderByNumber(
+	ctx context.Context,
+	number *big.Int,
+) (*types.Header, error) {
+	tec.mutex.Lock()
+	defer tec.mutex.Unlock()
+
+	if tec.failure != nil {
+		return nil, tec.failure
+	}
+
+	return &types.Header{Number: big.NewInt(tec.blockNumber)}, nil
+}
+
+func (tec *testEndpointClient) BalanceAt(
+	ctx context.Context,
+	account common.Address,
+	blockNumber *big.Int,
+) (*big.Int, error) {
+	tec.mutex.Lock()
+	defer tec.mutex.Unlock()
+
+	if tec.failure != nil {
+		return nil, tec.failure
+	}
+	if tec.balanceFailure != nil {
+		return nil, tec.balanceFailure
+	}
+
+	return big.NewInt(1), nil
+}
+
+func (tec *testEndpointClient) SubscribeNewHead(
+	ctx context.Context,
+	ch chan<- *types.Header,
+) (hostchain.Subscription, error) {
+	return event.NewSubscription(func(unsubscribed <-chan struct{}) error {
+		<-unsubscribed
+
+		tec.mutex.Lock()
+		defer tec.mutex.Unlock()
+
+		tec.unsubscribed = true
+
+		return nil
+	}), nil
+}
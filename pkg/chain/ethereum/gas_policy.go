@@ -0,0 +1,598 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/keep-network/keep-common/pkg/chain/ethereum"
+	"github.com/keep-network/keep-common/pkg/chain/ethereum/ethutil"
+	relayChain "github.com/keep-network/keep-core/pkg/beacon/relay/chain"
+	"github.com/keep-network/keep-core/pkg/metrics"
+)
+
+// Gas price strategies of gas policies.
+const (
+	// NodeGasPriceStrategy uses the gas price suggested by the Ethereum node.
+	// It is the default strategy.
+	NodeGasPriceStrategy = "node"
+
+	// FixedGasPriceStrategy uses the price configured in the gas policy.
+	FixedGasPriceStrategy = "fixed"
+
+	// OracleGasPriceStrategy uses the gas price ceiling of the operator
+	// contract which follows the value of the GasPriceOracle contract.
+	// Transactions priced above the ceiling are not fully reimbursed.
+	OracleGasPriceStrategy = "oracle"
+
+	// EstimateGasPriceStrategy uses the gas price suggested by the Ethereum
+	// node as the estimate of the base fee and adds the priority fee
+	// configured in the gas policy, in the style of EIP-1559.
+	EstimateGasPriceStrategy = "estimate"
+
+	// DeadlineGasPriceStrategy escalates the gas price from the price
+	// configured in the gas policy at the start of the submission window of
+	// the transaction to the max price of the policy at the deadline block.
+	// Resubmissions of transactions not mined in time follow the escalation.
+	DeadlineGasPriceStrategy = "deadline"
+)
+
+// GasPriceStrategies lists all supported gas price strategies.
+var GasPriceStrategies = []string{
+	NodeGasPriceStrategy,
+	FixedGasPriceStrategy,
+	OracleGasPriceStrategy,
+	EstimateGasPriceStrategy,
+	DeadlineGasPriceStrategy,
+}
+
+// Types of transactions with their own gas policies. They are used as labels
+// of transaction metrics.
+const (
+	submitTicketTransaction            = "submit_ticket"
+	submitDKGResultTransaction         = "submit_dkg_result"
+	submitRelayEntryTransaction        = "submit_relay_entry"
+	reportRelayEntryTimeoutTransaction = "report_relay_entry_timeout"
+)
+
+// gasPriceTimeout is the timeout of requests made to determine the gas price.
+const gasPriceTimeout = 30 * time.Second
+
+// minReplacementBump is the minimum percentage by which the gas price of
+// a replacement transaction has to exceed the gas price of the replaced
+// transaction for Ethereum nodes to accept the replacement.
+const minReplacementBump = 10
+
+// GasPolicy determines the gas price of transactions of a single type.
+type GasPolicy struct {
+	// Strategy is one of GasPriceStrategies. If not set, the node strategy
+	// is used.
+	Strategy string
+
+	// Price is the gas price used by the fixed strategy and the starting
+	// gas price of the deadline strategy.
+	Price *ethereum.Wei
+
+	// PriorityFee is the fee added by the estimate strategy to the gas price
+	// suggested by the Ethereum node.
+	PriorityFee *ethereum.Wei
+
+	// MaxPrice caps the gas price of transactions, including resubmissions
+	// of transactions not mined in time. The cap can not exceed the max gas
+	// price from the Ethereum config.
+	MaxPrice *ethereum.Wei
+}
+
+// GasPolicies configures gas policies of all transactions the client
+// submits as part of the protocol.
+type GasPolicies struct {
+	SubmitTicket            GasPolicy
+	SubmitDKGResult         GasPolicy
+	SubmitRelayEntry        GasPolicy
+	ReportRelayEntryTimeout GasPolicy
+}
+
+func (gp GasPolicies) policy(transactionType string) GasPolicy {
+	switch transactionType {
+	case submitTicketTransaction:
+		return gp.SubmitTicket
+	case submitDKGResultTransaction:
+		return gp.SubmitDKGResult
+	case submitRelayEntryTransaction:
+		return gp.SubmitRelayEntry
+	case reportRelayEntryTimeoutTransaction:
+		return gp.ReportRelayEntryTimeout
+	}
+
+	return GasPolicy{}
+}
+
+// submissionWindow is the range of blocks in which a transaction is expected
+// to be mined. Zero deadline block means the transaction has no deadline.
+type submissionWindow struct {
+	startBlock    uint64
+	deadlineBlock uint64
+}
+
+// deadlineTransaction is a transaction submitted with the deadline strategy,
+// along with its submission window and the gas price of its latest version.
+type deadlineTransaction struct {
+	window   submissionWindow
+	gasPrice *big.Int
+}
+
+// gasPricer determines gas prices of transactions of a single type according
+// to the gas policy of that type.
+type gasPricer struct {
+	transactionType string
+	policy          GasPolicy
+	maxGasPrice     *big.Int
+
+	suggestedGasPrice func() (*big.Int, error)
+	oracleGasPrice    func() (*big.Int, error)
+	currentBlock      func() (uint64, error)
+
+	// metrics are updated with submitted transactions, labeled with the
+	// address of the operator submitting them. Transactions are not measured
+	// if metrics are nil.
+	metrics  *metrics.Ethereum
+	operator string
+
+	// deadlineTransactions holds transactions submitted with the deadline
+	// strategy, by their nonces, until their deadline passes. Resubmissions
+	// of these transactions are priced for the block they are made in.
+	deadlineTransactionsMutex sync.Mutex
+	deadlineTransactions      map[uint64]*deadlineTransaction
+
+	// costs records costs of submitted transactions once they are mined.
+	// Costs are not recorded if it is nil.
+	costs *transactionCosts
+}
+
+// newGasPricer creates a pricer of transactions of the given type capping
+// gas prices at the lower of the max gas price of the policy and the given
+// max gas price of the chain.
+func newGasPricer(
+	transactionType string,
+	policy GasPolicy,
+	chainMaxGasPrice *big.Int,
+	suggestedGasPrice func() (*big.Int, error),
+	oracleGasPrice func() (*big.Int, error),
+	currentBlock func() (uint64, error),
+) *gasPricer {
+	maxGasPrice := chainMaxGasPrice
+	if policy.MaxPrice != nil && policy.MaxPrice.Int.Cmp(maxGasPrice) < 0 {
+		maxGasPrice = policy.MaxPrice.Int
+	}
+
+	return &gasPricer{
+		transactionType:   transactionType,
+		policy:            policy,
+		maxGasPrice:       maxGasPrice,
+		suggestedGasPrice: suggestedGasPrice,
+		oracleGasPrice:    oracleGasPrice,
+		currentBlock:      currentBlock,
+
+		deadlineTransactions: make(map[uint64]*deadlineTransaction),
+	}
+}
+
+// transactionOptions returns options of a transaction with the given gas
+// limit submitted in the given window. If the gas price could not be
+// determined using the strategy of the policy, the gas price suggested by
+// the Ethereum node is used instead. The gas price is always capped.
+func (gp *gasPricer) transactionOptions(
+	window submissionWindow,
+	gasLimit uint64,
+) (ethutil.TransactionOptions, error) {
+	gasPrice, err := gp.gasPrice(window)
+	if err != nil {
+		logger.Warningf(
+			"could not determine gas price of [%v] transaction: [%v]; "+
+				"using gas price suggested by the node",
+			gp.transactionType,
+			err,
+		)
+
+		gasPrice, err = gp.suggestedGasPrice()
+		if err != nil {
+			return ethutil.TransactionOptions{}, fmt.Errorf(
+				"could not get suggested gas price: [%v]",
+				err,
+			)
+		}
+	}
+
+	if gasPrice != nil && gasPrice.Cmp(gp.maxGasPrice) > 0 {
+		logger.Infof(
+			"capping gas price [%v] wei of [%v] transaction at [%v] wei",
+			gasPrice,
+			gp.transactionType,
+			gp.maxGasPrice,
+		)
+		gasPrice = gp.maxGasPrice
+	}
+
+	return ethutil.TransactionOptions{
+		GasLimit: gasLimit,
+		GasPrice: gasPrice,
+	}, nil
+}
+
+// gasPrice returns the gas price of a transaction submitted in the given
+// window, not capped yet. Nil is returned if the gas price should be left to
+// the Ethereum node and there is no need to cap it.
+func (gp *gasPricer) gasPrice(window submissionWindow) (*big.Int, error) {
+	switch gp.policy.Strategy {
+	case "", NodeGasPriceStrategy:
+		if gp.policy.MaxPrice == nil {
+			return nil, nil
+		}
+		return gp.suggestedGasPrice()
+	case FixedGasPriceStrategy:
+		return gp.policy.Price.Int, nil
+	case OracleGasPriceStrategy:
+		return gp.oracleGasPrice()
+	case EstimateGasPriceStrategy:
+		baseFee, err := gp.suggestedGasPrice()
+		if err != nil {
+			return nil, err
+		}
+		if gp.policy.PriorityFee == nil {
+			return baseFee, nil
+		}
+		return new(big.Int).Add(baseFee, gp.policy.PriorityFee.Int), nil
+	case DeadlineGasPriceStrategy:
+		return gp.deadlineGasPrice(window)
+	}
+
+	return nil, fmt.Errorf("unknown gas price strategy [%v]", gp.policy.Strategy)
+}
+
+// deadlineGasPrice interpolates the gas price linearly between the starting
+// price at the start of the submission window and the max price at its
+// deadline block. Transactions without a deadline get the starting price.
+func (gp *gasPricer) deadlineGasPrice(window submissionWindow) (*big.Int, error) {
+	startPrice := gp.policy.Price.Int
+
+	if window.deadlineBlock <= window.startBlock ||
+		startPrice.Cmp(gp.maxGasPrice) >= 0 {
+		return startPrice, nil
+	}
+
+	currentBlock, err := gp.currentBlock()
+	if err != nil {
+		return nil, err
+	}
+
+	if currentBlock <= window.startBlock {
+		return startPrice, nil
+	}
+	if currentBlock >= window.deadlineBlock {
+		return gp.maxGasPrice, nil
+	}
+
+	escalation := new(big.Int).Sub(gp.maxGasPrice, startPrice)
+	escalation.Mul(
+		escalation,
+		new(big.Int).SetUint64(currentBlock-window.startBlock),
+	)
+	escalation.Div(
+		escalation,
+		new(big.Int).SetUint64(window.deadlineBlock-window.startBlock),
+	)
+
+	return escalation.Add(escalation, startPrice), nil
+}
+
+// resubmissionGasPrice returns the gas price of the resubmission of the
+// transaction with the given nonce submitted with the deadline strategy. The
+// resubmission follows the deadline gas price for the current block but is
+// priced at least the minimum replacement bump above the previous version of
+// the transaction so that Ethereum nodes accept it. The gas price is always
+// capped. False is returned if the transaction is not known to the pricer.
+func (gp *gasPricer) resubmissionGasPrice(nonce uint64) (*big.Int, bool) {
+	gp.deadlineTransactionsMutex.Lock()
+	submitted, ok := gp.deadlineTransactions[nonce]
+	gp.deadlineTransactionsMutex.Unlock()
+
+	if !ok {
+		return nil, false
+	}
+
+	gasPrice, err := gp.deadlineGasPrice(submitted.window)
+	if err != nil {
+		logger.Warningf(
+			"could not determine gas price of [%v] transaction "+
+				"resubmission: [%v]",
+			gp.transactionType,
+			err,
+		)
+		return nil, false
+	}
+
+	minGasPrice := new(big.Int).Div(
+		new(big.Int).Mul(
+			submitted.gasPrice,
+			big.NewInt(100+minReplacementBump),
+		),
+		big.NewInt(100),
+	)
+	if gasPrice.Cmp(minGasPrice) < 0 {
+		gasPrice = minGasPrice
+	}
+	if gasPrice.Cmp(gp.maxGasPrice) > 0 {
+		gasPrice = gp.maxGasPrice
+	}
+
+	gp.deadlineTransactionsMutex.Lock()
+	submitted.gasPrice = gasPrice
+	gp.deadlineTransactionsMutex.Unlock()
+
+	return gasPrice, true
+}
+
+// submitterTransactorOptions returns transactor options of the submitter of
+// transactions of the pricer's type. Resubmissions of transactions submitted
+// with the deadline strategy are priced at the deadline gas price for the
+// current block, instead of the gas price bumped by the mining waiter. All
+// signed versions of transactions are tracked so that the cost of the version
+// which gets mined is recorded.
+func (gp *gasPricer) submitterTransactorOptions(
+	transactorOptions *bind.TransactOpts,
+) *bind.TransactOpts {
+	signTransaction := transactorOptions.Signer
+
+	submitterOptions := *transactorOptions
+	submitterOptions.Signer = func(
+		txSigner types.Signer,
+		address common.Address,
+		transaction *types.Transaction,
+	) (*types.Transaction, error) {
+		gasPrice, ok := gp.resubmissionGasPrice(transaction.Nonce())
+		if ok && gasPrice.Cmp(transaction.GasPrice()) != 0 {
+			logger.Infof(
+				"resubmitting [%v] transaction with nonce [%v] with "+
+					"deadline gas price [%v] wei instead of [%v] wei",
+				gp.transactionType,
+				transaction.Nonce(),
+				gasPrice,
+				transaction.GasPrice(),
+			)
+
+			transaction = types.NewTransaction(
+				transaction.Nonce(),
+				*transaction.To(),
+				transaction.Value(),
+				transaction.Gas(),
+				gasPrice,
+				transaction.Data(),
+			)
+		}
+
+		signedTransaction, err := signTransaction(txSigner, address, transaction)
+		if err != nil {
+			return nil, err
+		}
+
+		if gp.costs != nil {
+			gp.costs.track(gp.transactionType, signedTransaction)
+		}
+
+		return signedTransaction, nil
+	}
+
+	return &submitterOptions
+}
+
+// trackDeadline remembers the transaction submitted with the deadline
+// strategy in the given window so that its resubmissions are priced for the
+// block they are made in. Transactions whose deadline passed are forgotten.
+func (gp *gasPricer) trackDeadline(
+	transaction *types.Transaction,
+	window submissionWindow,
+) {
+	if gp.policy.Strategy != DeadlineGasPriceStrategy ||
+		window.deadlineBlock <= window.startBlock {
+		return
+	}
+
+	currentBlock, err := gp.currentBlock()
+	if err != nil {
+		logger.Warningf("could not get current block: [%v]", err)
+		return
+	}
+
+	gp.deadlineTransactionsMutex.Lock()
+	defer gp.deadlineTransactionsMutex.Unlock()
+
+	for nonce, submitted := range gp.deadlineTransactions {
+		if submitted.window.deadlineBlock < currentBlock {
+			delete(gp.deadlineTransactions, nonce)
+		}
+	}
+
+	gp.deadlineTransactions[transaction.Nonce()] = &deadlineTransaction{
+		window:   window,
+		gasPrice: transaction.GasPrice(),
+	}
+}
+
+// recordSubmission updates transaction metrics with the transaction
+// submitted in the given window and tracks the transaction for its
+// resubmissions. The cost of the transaction is recorded once it is mined.
+func (gp *gasPricer) recordSubmission(
+	transaction *types.Transaction,
+	window submissionWindow,
+) {
+	gp.trackDeadline(transaction, window)
+
+	if gp.metrics == nil {
+		return
+	}
+
+	gasPriceGwei := weiToGwei(transaction.GasPrice())
+
+	gp.metrics.Transactions.Inc(gp.operator, gp.transactionType)
+	gp.metrics.TransactionGasPriceGwei.Set(
+		gasPriceGwei,
+		gp.operator,
+		gp.transactionType,
+	)
+}
+
+func weiToGwei(wei *big.Int) float64 {
+	gwei, _ := new(big.Float).Quo(
+		new(big.Float).SetInt(wei),
+		big.NewFloat(1e9),
+	).Float64()
+
+	return gwei
+}
+
+// gasPricer creates a pricer of transactions of the given type according to
+// the gas policies of the chain.
+func (ec *ethereumChain) gasPricer(
+	transactionType string,
+	chainMaxGasPrice *big.Int,
+) *gasPricer {
+	pricer := newGasPricer(
+		transactionType,
+		ec.gasPolicies.policy(transactionType),
+		chainMaxGasPrice,
+		func() (*big.Int, error) {
+			ctx, cancel := context.WithTimeout(
+				context.Background(),
+				gasPriceTimeout,
+			)
+			defer cancel()
+
+			return ec.client.SuggestGasPrice(ctx)
+		},
+		func() (*big.Int, error) {
+			return ec.keepRandomBeaconOperator().GasPriceCeiling()
+		},
+		ec.blockCounter.CurrentBlock,
+	)
+	pricer.metrics = ec.metrics
+	pricer.operator = ec.signer.Address().Hex()
+	pricer.costs = ec.transactionCosts
+
+	return pricer
+}
+
+// ticketSubmissionWindow returns the ticket submission window of the group
+// selection in progress. The window is determined only for the deadline
+// strategy; tickets priced with other strategies do not need it and the
+// window has no deadline. The window is determined once per group selection.
+// If there is no group selection in progress or it could not be determined,
+// the window has no deadline.
+func (ec *ethereumChain) ticketSubmissionWindow() submissionWindow {
+	policy := ec.gasPolicies.policy(submitTicketTransaction)
+	if policy.Strategy != DeadlineGasPriceStrategy {
+		return submissionWindow{}
+	}
+
+	currentBlock, err := ec.blockCounter.CurrentBlock()
+	if err != nil {
+		logger.Warningf("could not get current block: [%v]", err)
+		return submissionWindow{}
+	}
+
+	ec.ticketWindowMutex.Lock()
+	defer ec.ticketWindowMutex.Unlock()
+
+	// The next group selection can not start before the ticket submission
+	// of the current one ends.
+	cached := ec.ticketWindow
+	if cached.deadlineBlock != 0 &&
+		currentBlock >= cached.startBlock &&
+		currentBlock <= cached.deadlineBlock {
+		return cached
+	}
+
+	timeout := ec.chainConfig.TicketSubmissionTimeout
+	fromBlock := uint64(0)
+	if currentBlock > timeout {
+		fromBlock = currentBlock - timeout
+	}
+
+	events, err := ec.keepRandomBeaconOperator().PastGroupSelectionStartedEvents(
+		fromBlock,
+		nil,
+	)
+	if err != nil {
+		logger.Warningf(
+			"could not determine ticket submission window: [%v]",
+			err,
+		)
+		return submissionWindow{}
+	}
+	if len(events) == 0 {
+		return submissionWindow{}
+	}
+
+	startBlock := events[len(events)-1].Raw.BlockNumber
+
+	ec.ticketWindow = submissionWindow{
+		startBlock:    startBlock,
+		deadlineBlock: startBlock + timeout,
+	}
+
+	return ec.ticketWindow
+}
+
+// dkgResultSubmissionWindow returns the DKG result submission window as seen
+// by the member with the given index. Members become eligible to submit the
+// result one after another, every result publication block step, and the
+// member submits the result as soon as it becomes eligible. The window ends
+// when the last member becomes ineligible.
+func (ec *ethereumChain) dkgResultSubmissionWindow(
+	memberIndex relayChain.GroupMemberIndex,
+) submissionWindow {
+	currentBlock, err := ec.blockCounter.CurrentBlock()
+	if err != nil {
+		logger.Warningf("could not get current block: [%v]", err)
+		return submissionWindow{}
+	}
+
+	blockStep := ec.chainConfig.ResultPublicationBlockStep
+	eligibilityDelay := uint64(0)
+	if memberIndex > 0 {
+		eligibilityDelay = (uint64(memberIndex) - 1) * blockStep
+	}
+	if eligibilityDelay > currentBlock {
+		return submissionWindow{}
+	}
+
+	startBlock := currentBlock - eligibilityDelay
+
+	return submissionWindow{
+		startBlock:    startBlock,
+		deadlineBlock: startBlock + uint64(ec.chainConfig.GroupSize)*blockStep,
+	}
+}
+
+// relayEntrySubmissionWindow returns the submission window of the relay
+// entry for the current relay request. If it could not be determined, the
+// window has no deadline.
+func (ec *ethereumChain) relayEntrySubmissionWindow() submissionWindow {
+	startBlock, err := ec.keepRandomBeaconOperator().CurrentRequestStartBlock()
+	if err != nil {
+		logger.Warningf(
+			"could not determine relay entry submission window: [%v]",
+			err,
+		)
+		return submissionWindow{}
+	}
+
+	return submissionWindow{
+		startBlock:    startBlock.Uint64(),
+		deadlineBlock: startBlock.Uint64() + ec.chainConfig.RelayEntryTimeout,
+	}
+}
@@ -0,0 +1,271 @@
+package ethereum
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/keep-network/keep-common/pkg/chain/ethereum"
+	"github.com/keep-network/keep-common/pkg/chain/ethlike"
+)
+
+func TestGasPricerTransactionOptions(t *testing.T) {
+	var tests = map[string]struct {
+		policy            GasPolicy
+		window            submissionWindow
+		currentBlock      uint64
+		suggestedGasPrice *big.Int
+		oracleGasPrice    *big.Int
+		expectedGasPrice  *big.Int
+	}{
+		"node strategy": {
+			policy:            GasPolicy{},
+			suggestedGasPrice: big.NewInt(30),
+			expectedGasPrice:  nil,
+		},
+		"node strategy with max price": {
+			policy:            GasPolicy{MaxPrice: wei(20)},
+			suggestedGasPrice: big.NewInt(30),
+			expectedGasPrice:  big.NewInt(20),
+		},
+		"fixed strategy": {
+			policy: GasPolicy{
+				Strategy: FixedGasPriceStrategy,
+				Price:    wei(40),
+			},
+			expectedGasPrice: big.NewInt(40),
+		},
+		"fixed strategy capped by chain max gas price": {
+			policy: GasPolicy{
+				Strategy: FixedGasPriceStrategy,
+				Price:    wei(150),
+			},
+			expectedGasPrice: big.NewInt(100),
+		},
+		"oracle strategy": {
+			policy:           GasPolicy{Strategy: OracleGasPriceStrategy},
+			oracleGasPrice:   big.NewInt(45),
+			expectedGasPrice: big.NewInt(45),
+		},
+		"estimate strategy": {
+			policy: GasPolicy{
+				Strategy:    EstimateGasPriceStrategy,
+				PriorityFee: wei(5),
+			},
+			suggestedGasPrice: big.NewInt(30),
+			expectedGasPrice:  big.NewInt(35),
+		},
+		"deadline strategy at window start": {
+			policy: GasPolicy{
+				Strategy: DeadlineGasPriceStrategy,
+				Price:    wei(20),
+				MaxPrice: wei(60),
+			},
+			window:           submissionWindow{startBlock: 10, deadlineBlock: 20},
+			currentBlock:     10,
+			expectedGasPrice: big.NewInt(20),
+		},
+		"deadline strategy in window": {
+			policy: GasPolicy{
+				Strategy: DeadlineGasPriceStrategy,
+				Price:    wei(20),
+				MaxPrice: wei(60),
+			},
+			window:           submissionWindow{startBlock: 10, deadlineBlock: 20},
+			currentBlock:     15,
+			expectedGasPrice: big.NewInt(40),
+		},
+		"deadline strategy after deadline": {
+			policy: GasPolicy{
+				Strategy: DeadlineGasPriceStrategy,
+				Price:    wei(20),
+				MaxPrice: wei(60),
+			},
+			window:           submissionWindow{startBlock: 10, deadlineBlock: 20},
+			currentBlock:     25,
+			expectedGasPrice: big.NewInt(60),
+		},
+		"deadline strategy without deadline": {
+			policy: GasPolicy{
+				Strategy: DeadlineGasPriceStrategy,
+				Price:    wei(20),
+				MaxPrice: wei(60),
+			},
+			currentBlock:     15,
+			expectedGasPrice: big.NewInt(20),
+		},
+		"fallback to node strategy": {
+			policy:            GasPolicy{Strategy: OracleGasPriceStrategy},
+			suggestedGasPrice: big.NewInt(30),
+			expectedGasPrice:  big.NewInt(30),
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			pricer := newGasPricer(
+				submitTicketTransaction,
+				test.policy,
+				big.NewInt(100),
+				func() (*big.Int, error) {
+					return test.suggestedGasPrice, nil
+				},
+				func() (*big.Int, error) {
+					if test.oracleGasPrice == nil {
+						return nil, fmt.Errorf("oracle not available")
+					}
+					return test.oracleGasPrice, nil
+				},
+				func() (uint64, error) {
+					return test.currentBlock, nil
+				},
+			)
+
+			options, err := pricer.transactionOptions(test.window, 1000)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if options.GasLimit != 1000 {
+				t.Errorf(
+					"unexpected gas limit\nexpected: [%v]\nactual:   [%v]",
+					1000,
+					options.GasLimit,
+				)
+			}
+
+			if fmt.Sprint(options.GasPrice) != fmt.Sprint(test.expectedGasPrice) {
+				t.Errorf(
+					"unexpected gas price\nexpected: [%v]\nactual:   [%v]",
+					test.expectedGasPrice,
+					options.GasPrice,
+				)
+			}
+		})
+	}
+}
+
+func TestGasPricerFailsWithoutSuggestedGasPrice(t *testing.T) {
+	pricer := newGasPricer(
+		submitRelayEntryTransaction,
+		GasPolicy{Strategy: EstimateGasPriceStrategy},
+		big.NewInt(100),
+		func() (*big.Int, error) {
+			return nil, fmt.Errorf("node not available")
+		},
+		nil,
+		nil,
+	)
+
+	if _, err := pricer.transactionOptions(submissionWindow{}, 1000); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestGasPricerResubmissionGasPrice(t *testing.T) {
+	currentBlock := uint64(12)
+
+	pricer := newGasPricer(
+		submitTicketTransaction,
+		GasPolicy{
+			Strategy: DeadlineGasPriceStrategy,
+			Price:    wei(20),
+			MaxPrice: wei(60),
+		},
+		big.NewInt(100),
+		nil,
+		nil,
+		func() (uint64, error) {
+			return currentBlock, nil
+		},
+	)
+
+	window := submissionWindow{startBlock: 10, deadlineBlock: 20}
+	pricer.recordSubmission(
+		types.NewTransaction(1, common.Address{}, nil, 1000, big.NewInt(28), nil),
+		window,
+	)
+
+	if _, ok := pricer.resubmissionGasPrice(2); ok {
+		t.Errorf("unexpected gas price of unknown transaction")
+	}
+
+	var tests = []struct {
+		currentBlock     uint64
+		expectedGasPrice *big.Int
+	}{
+		// the deadline gas price does not exceed the previous gas price
+		// enough for the resubmission to be accepted
+		{currentBlock: 12, expectedGasPrice: big.NewInt(30)},
+		// the deadline gas price for the current block
+		{currentBlock: 16, expectedGasPrice: big.NewInt(44)},
+		// the max gas price of the policy after the deadline
+		{currentBlock: 22, expectedGasPrice: big.NewInt(60)},
+	}
+
+	for _, test := range tests {
+		currentBlock = test.currentBlock
+
+		gasPrice, ok := pricer.resubmissionGasPrice(1)
+		if !ok {
+			t.Fatalf("transaction is not known at block [%v]", currentBlock)
+		}
+
+		if gasPrice.Cmp(test.expectedGasPrice) != 0 {
+			t.Errorf(
+				"unexpected gas price at block [%v]\n"+
+					"expected: [%v]\nactual:   [%v]",
+				currentBlock,
+				test.expectedGasPrice,
+				gasPrice,
+			)
+		}
+	}
+}
+
+func TestGasPricerMaxGasPrice(t *testing.T) {
+	var tests = map[string]struct {
+		policyMaxPrice      *ethereum.Wei
+		expectedMaxGasPrice *big.Int
+	}{
+		"no policy max price": {
+			policyMaxPrice:      nil,
+			expectedMaxGasPrice: big.NewInt(100),
+		},
+		"policy max price below chain max gas price": {
+			policyMaxPrice:      wei(60),
+			expectedMaxGasPrice: big.NewInt(60),
+		},
+		"policy max price above chain max gas price": {
+			policyMaxPrice:      wei(160),
+			expectedMaxGasPrice: big.NewInt(100),
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			pricer := newGasPricer(
+				submitDKGResultTransaction,
+				GasPolicy{MaxPrice: test.policyMaxPrice},
+				big.NewInt(100),
+				nil,
+				nil,
+				nil,
+			)
+
+			if pricer.maxGasPrice.Cmp(test.expectedMaxGasPrice) != 0 {
+				t.Errorf(
+					"unexpected max gas price\nexpected: [%v]\nactual:   [%v]",
+					test.expectedMaxGasPrice,
+					pricer.maxGasPrice,
+				)
+			}
+		})
+	}
+}
+
+func wei(value int64) *ethereum.Wei {
+	return &ethereum.Wei{Token: ethlike.Token{Int: big.NewInt(value)}}
+}
@@ -0,0 +1,783 @@
+package ethereum
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+
+	hostchainabi "github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/keep-network/keep-common/pkg/chain/ethereum/ethutil"
+	"github.com/keep-network/keep-common/pkg/chain/ethlike"
+	"github.com/keep-network/keep-common/pkg/persistence"
+	"github.com/keep-network/keep-core/pkg/chain/gen/abi"
+)
+
+const (
+	outboxDirectoryPrefix = "outbox_"
+	outboxFileName        = "transaction"
+)
+
+// Statuses of outbox transactions.
+const (
+	// pendingTransaction is waiting to be mined.
+	pendingTransaction = "pending"
+
+	// minedTransaction has been mined; either the original transaction or
+	// one of its replacements.
+	minedTransaction = "mined"
+
+	// droppedTransaction has been dropped by the Ethereum network; its nonce
+	// has been used by another transaction.
+	droppedTransaction = "dropped"
+
+	// obsoleteTransaction is no longer needed by the protocol, for example
+	// because the relay entry has been already submitted by another member.
+	obsoleteTransaction = "obsolete"
+
+	// expiredTransaction has not been mined before its deadline block.
+	expiredTransaction = "expired"
+)
+
+// outboxTransaction is an outbound transaction persisted in the outbox. All
+// versions of the transaction share the same nonce; a new version is
+// recorded whenever the transaction is replaced with a higher gas price.
+type outboxTransaction struct {
+	Type  string
+	Nonce uint64
+
+	// Hashes lists hashes of all versions of the transaction, the current
+	// one being the last.
+	Hashes []common.Hash
+
+	// Payload is the RLP-encoded current version of the signed transaction.
+	Payload []byte
+
+	// SubmittedBlock is the block at which the transaction has been signed.
+	SubmittedBlock uint64
+
+	// DeadlineBlock is the end of the submission window of the transaction.
+	// Zero deadline block means the transaction has no deadline or the
+	// process stopped before it has been recorded.
+	DeadlineBlock uint64
+
+	Status string
+}
+
+func (ot *outboxTransaction) transaction() (*types.Transaction, error) {
+	transaction := &types.Transaction{}
+	if err := rlp.DecodeBytes(ot.Payload, transaction); err != nil {
+		return nil, fmt.Errorf(
+			"could not decode transaction with nonce [%v]: [%v]",
+			ot.Nonce,
+			err,
+		)
+	}
+
+	return transaction, nil
+}
+
+// transactionOutbox persists outbound transactions of a single account from
+// the moment they are signed until they are mined or no longer needed.
+type transactionOutbox struct {
+	handle      persistence.Handle
+	account     common.Address
+	contractABI *hostchainabi.ABI
+
+	mutex        sync.Mutex
+	transactions map[uint64]*outboxTransaction
+}
+
+func newTransactionOutbox(
+	handle persistence.Handle,
+	account common.Address,
+) (*transactionOutbox, error) {
+	contractABI, err := hostchainabi.JSON(
+		strings.NewReader(abi.KeepRandomBeaconOperatorABI),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to instantiate ABI: [%v]", err)
+	}
+
+	return &transactionOutbox{
+		handle:       handle,
+		account:      account,
+		contractABI:  &contractABI,
+		transactions: make(map[uint64]*outboxTransaction),
+	}, nil
+}
+
+func (to *transactionOutbox) directoryPrefix() string {
+	return fmt.Sprintf(
+		"%v%v_",
+		outboxDirectoryPrefix,
+		strings.ToLower(to.account.Hex()[2:]),
+	)
+}
+
+func (to *transactionOutbox) directory(nonce uint64) string {
+	return fmt.Sprintf("%v%v", to.directoryPrefix(), nonce)
+}
+
+// load reads pending transactions of the account persisted in the outbox.
+func (to *transactionOutbox) load() {
+	to.mutex.Lock()
+	defer to.mutex.Unlock()
+
+	dataChannel, errorChannel := to.handle.ReadAll()
+
+	// The same as for group memberships, data and errors channels are read
+	// by two goroutines as we don't know in what order producers write to
+	// them.
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		for descriptor := range dataChannel {
+			// The same persistence handle is used to store other data, such
+			// as group memberships and transactions of other accounts. Skip
+			// all of them.
+			if !strings.HasPrefix(descriptor.Directory(), to.directoryPrefix()) {
+				continue
+			}
+
+			content, err := descriptor.Content()
+			if err != nil {
+				logger.Errorf(
+					"could not read outbox transaction from directory [%v]: [%v]",
+					descriptor.Directory(),
+					err,
+				)
+				continue
+			}
+
+			transaction := &outboxTransaction{}
+			if err := json.Unmarshal(content, transaction); err != nil {
+				logger.Errorf(
+					"could not unmarshal outbox transaction from directory [%v]: [%v]",
+					descriptor.Directory(),
+					err,
+				)
+				continue
+			}
+
+			if transaction.Status == pendingTransaction {
+				to.transactions[transaction.Nonce] = transaction
+			}
+		}
+
+		wg.Done()
+	}()
+
+	go func() {
+		for err := range errorChannel {
+			logger.Errorf("could not load outbox transaction: [%v]", err)
+		}
+
+		wg.Done()
+	}()
+
+	wg.Wait()
+}
+
+// record persists the signed transaction of the given type. If a transaction
+// with the same nonce is already pending, the signed transaction is recorded
+// as its new version.
+func (to *transactionOutbox) record(
+	transactionType string,
+	transaction *types.Transaction,
+	currentBlock uint64,
+) error {
+	to.mutex.Lock()
+	defer to.mutex.Unlock()
+
+	payload, err := rlp.EncodeToBytes(transaction)
+	if err != nil {
+		return fmt.Errorf("could not encode transaction: [%v]", err)
+	}
+
+	recorded, ok := to.transactions[transaction.Nonce()]
+	if !ok || recorded.Type != transactionType {
+		recorded = &outboxTransaction{
+			Type:           transactionType,
+			Nonce:          transaction.Nonce(),
+			SubmittedBlock: currentBlock,
+			Status:         pendingTransaction,
+		}
+	}
+
+	recorded.Hashes = append(recorded.Hashes, transaction.Hash())
+	recorded.Payload = payload
+
+	if err := to.save(recorded); err != nil {
+		return err
+	}
+
+	to.transactions[recorded.Nonce] = recorded
+
+	return nil
+}
+
+// setDeadline sets the deadline block of the pending transaction with the
+// given nonce.
+func (to *transactionOutbox) setDeadline(nonce uint64, deadlineBlock uint64) {
+	to.mutex.Lock()
+	defer to.mutex.Unlock()
+
+	recorded, ok := to.transactions[nonce]
+	if !ok {
+		return
+	}
+
+	recorded.DeadlineBlock = deadlineBlock
+
+	if err := to.save(recorded); err != nil {
+		logger.Warningf(
+			"could not save deadline of transaction with nonce [%v]: [%v]",
+			nonce,
+			err,
+		)
+	}
+}
+
+// complete persists the final status of the pending transaction with the
+// given nonce and archives it.
+func (to *transactionOutbox) complete(nonce uint64, status string) {
+	to.mutex.Lock()
+	defer to.mutex.Unlock()
+
+	recorded, ok := to.transactions[nonce]
+	if !ok {
+		return
+	}
+
+	delete(to.transactions, nonce)
+
+	logger.Infof(
+		"[%v] transaction with nonce [%v] and hash [%v] is %v",
+		recorded.Type,
+		nonce,
+		recorded.Hashes[len(recorded.Hashes)-1].Hex(),
+		status,
+	)
+
+	recorded.Status = status
+	if err := to.save(recorded); err != nil {
+		logger.Warningf(
+			"could not save status of transaction with nonce [%v]: [%v]",
+			nonce,
+			err,
+		)
+		return
+	}
+
+	if err := to.handle.Archive(to.directory(nonce)); err != nil {
+		logger.Warningf(
+			"could not archive transaction with nonce [%v]: [%v]",
+			nonce,
+			err,
+		)
+	}
+}
+
+// discard completes the pending transaction as dropped if the given
+// transaction, which has been rejected by the Ethereum network, is its only
+// version. A failed replacement is not discarded as the previous version of
+// the transaction is still waiting to be mined.
+func (to *transactionOutbox) discard(transaction *types.Transaction) {
+	to.mutex.Lock()
+	recorded, ok := to.transactions[transaction.Nonce()]
+	isOnlyVersion := ok &&
+		len(recorded.Hashes) == 1 &&
+		recorded.Hashes[0] == transaction.Hash()
+	to.mutex.Unlock()
+
+	if isOnlyVersion {
+		to.complete(transaction.Nonce(), droppedTransaction)
+	}
+}
+
+// pending returns copies of pending transactions ordered by their nonces.
+func (to *transactionOutbox) pending() []*outboxTransaction {
+	to.mutex.Lock()
+	defer to.mutex.Unlock()
+
+	pending := make([]*outboxTransaction, 0, len(to.transactions))
+	for _, transaction := range to.transactions {
+		copied := *transaction
+		pending = append(pending, &copied)
+	}
+
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].Nonce < pending[j].Nonce
+	})
+
+	return pending
+}
+
+// parameters returns parameters of the operator contract call made by the
+// transaction.
+func (to *transactionOutbox) parameters(
+	transaction *types.Transaction,
+) ([]interface{}, error) {
+	data := transaction.Data()
+	if len(data) < 4 {
+		return nil, fmt.Errorf("transaction does not call a contract method")
+	}
+
+	method, err := to.contractABI.MethodById(data[:4])
+	if err != nil {
+		return nil, err
+	}
+
+	return method.Inputs.UnpackValues(data[4:])
+}
+
+func (to *transactionOutbox) save(transaction *outboxTransaction) error {
+	content, err := json.Marshal(transaction)
+	if err != nil {
+		return fmt.Errorf("could not marshal outbox transaction: [%v]", err)
+	}
+
+	return to.handle.Save(
+		content,
+		to.directory(transaction.Nonce),
+		outboxFileName,
+	)
+}
+
+// outboxTransactorOptions returns transactor options recording transactions
+// of the given type in the outbox as soon as they are signed, before they are
+// sent to the Ethereum network. Transactions which could not be recorded are
+// not sent. Transactions rejected by the Ethereum network are discarded by
+// the outboxBackend.
+func (ec *ethereumChain) outboxTransactorOptions(
+	transactionType string,
+	transactorOptions *bind.TransactOpts,
+) *bind.TransactOpts {
+	signTransaction := transactorOptions.Signer
+
+	outboxOptions := *transactorOptions
+	outboxOptions.Signer = func(
+		txSigner types.Signer,
+		address common.Address,
+		transaction *types.Transaction,
+	) (*types.Transaction, error) {
+		signedTransaction, err := signTransaction(txSigner, address, transaction)
+		if err != nil {
+			return nil, err
+		}
+
+		currentBlock, err := ec.blockCounter.CurrentBlock()
+		if err != nil {
+			return nil, fmt.Errorf("could not get current block: [%v]", err)
+		}
+
+		err = ec.outbox.record(transactionType, signedTransaction, currentBlock)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"could not record transaction in outbox: [%v]",
+				err,
+			)
+		}
+
+		return signedTransaction, nil
+	}
+
+	return &outboxOptions
+}
+
+// definitiveRejections lists fragments of errors returned by Ethereum nodes
+// rejecting transactions which are never going to be accepted in their
+// current form.
+var definitiveRejections = []string{
+	"nonce too low",
+	"replacement transaction underpriced",
+	"insufficient funds",
+}
+
+// isDefinitiveRejection returns true if the error returned when sending
+// a transaction means the transaction has been rejected by the Ethereum node
+// for good. Other errors, such as connection errors and timeouts, leave it
+// unknown if the transaction reached the Ethereum network.
+func isDefinitiveRejection(err error) bool {
+	message := strings.ToLower(err.Error())
+	for _, rejection := range definitiveRejections {
+		if strings.Contains(message, rejection) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// outboxBackend discards transactions recorded in the outbox when they have
+// been definitively rejected by the Ethereum network so that they do not
+// block submitting them again. Transactions which could not be sent because
+// of other errors might have reached the Ethereum network and are kept in
+// the outbox until they are mined or their nonce is used.
+type outboxBackend struct {
+	ethutil.HostChainClient
+
+	outbox *transactionOutbox
+}
+
+func (ob *outboxBackend) SendTransaction(
+	ctx context.Context,
+	transaction *types.Transaction,
+) error {
+	err := ob.HostChainClient.SendTransaction(ctx, transaction)
+	if err != nil && isDefinitiveRejection(err) {
+		ob.outbox.discard(transaction)
+	}
+
+	return err
+}
+
+// recordDeadline records the deadline block of the submitted transaction in
+// the outbox.
+func (ec *ethereumChain) recordDeadline(
+	transaction *types.Transaction,
+	window submissionWindow,
+) {
+	if ec.outbox == nil {
+		return
+	}
+
+	ec.outbox.setDeadline(transaction.Nonce(), window.deadlineBlock)
+}
+
+// isAlreadyPending returns true if a transaction of the given type, calling
+// the operator contract with parameters satisfying the given predicate, is
+// waiting in the outbox to be mined. It prevents submitting again
+// transactions replayed after a restart.
+func (ec *ethereumChain) isAlreadyPending(
+	transactionType string,
+	matches func(parameters []interface{}) bool,
+) bool {
+	if ec.outbox == nil {
+		return false
+	}
+
+	for _, recorded := range ec.outbox.pending() {
+		if recorded.Type != transactionType {
+			continue
+		}
+
+		transaction, err := recorded.transaction()
+		if err != nil {
+			logger.Warningf("could not read outbox transaction: [%v]", err)
+			continue
+		}
+
+		parameters, err := ec.outbox.parameters(transaction)
+		if err != nil {
+			logger.Warningf(
+				"could not read parameters of outbox transaction: [%v]",
+				err,
+			)
+			continue
+		}
+
+		if matches(parameters) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// startOutbox loads transactions pending in the outbox, replays them and
+// starts monitoring them until they are mined. Transactions no longer needed
+// by the protocol are not replayed.
+func (ec *ethereumChain) startOutbox(ctx context.Context) {
+	ec.outbox.load()
+
+	ec.completeMinedTransactions(ctx)
+
+	for _, recorded := range ec.outbox.pending() {
+		ec.replayTransaction(ctx, recorded)
+	}
+
+	go func() {
+		blocks := ec.blockCounter.WatchBlocks(ctx)
+		for range blocks {
+			ec.completeMinedTransactions(ctx)
+		}
+	}()
+}
+
+// replayTransaction reconciles the pending transaction with the state of
+// the chain and rebroadcasts it if it is still needed. The rebroadcast
+// transaction is replaced with a higher gas price if it is not mined in time,
+// the same as any other submitted transaction.
+func (ec *ethereumChain) replayTransaction(
+	ctx context.Context,
+	recorded *outboxTransaction,
+) {
+	transaction, err := recorded.transaction()
+	if err != nil {
+		logger.Errorf("could not replay outbox transaction: [%v]", err)
+		return
+	}
+
+	status, err := ec.reconcileTransaction(recorded, transaction)
+	if err != nil {
+		logger.Warningf(
+			"could not reconcile [%v] transaction with nonce [%v]: [%v]; "+
+				"replaying the transaction",
+			recorded.Type,
+			recorded.Nonce,
+			err,
+		)
+	}
+	if status != pendingTransaction {
+		ec.outbox.complete(recorded.Nonce, status)
+		return
+	}
+
+	logger.Infof(
+		"replaying [%v] transaction with nonce [%v] and hash [%v]",
+		recorded.Type,
+		recorded.Nonce,
+		transaction.Hash().Hex(),
+	)
+
+	if err := ec.client.SendTransaction(ctx, transaction); err != nil {
+		// The transaction may still wait in the transaction pool of the
+		// Ethereum node. Otherwise, the transaction is going to be
+		// completed once its nonce is used.
+		logger.Warningf(
+			"could not rebroadcast [%v] transaction with nonce [%v]: [%v]",
+			recorded.Type,
+			recorded.Nonce,
+			err,
+		)
+	}
+
+	submitter := ec.transactionSubmitter(recorded.Type)
+	if submitter == nil {
+		return
+	}
+
+	go submitter.miningWaiter.ForceMining(
+		&ethlike.Transaction{
+			Hash:     ethlike.Hash(transaction.Hash()),
+			GasPrice: transaction.GasPrice(),
+		},
+		func(gasPrice *big.Int) (*ethlike.Transaction, error) {
+			replacement, err := ec.replaceTransaction(
+				ctx,
+				submitter.transactorOptions,
+				transaction,
+				gasPrice,
+			)
+			if err != nil {
+				return nil, err
+			}
+
+			return &ethlike.Transaction{
+				Hash:     ethlike.Hash(replacement.Hash()),
+				GasPrice: replacement.GasPrice(),
+			}, nil
+		},
+	)
+}
+
+// reconcileTransaction determines if the pending transaction is still needed
+// by the protocol. It returns the pending status if the transaction should
+// be replayed or the status the transaction should be completed with
+// otherwise.
+func (ec *ethereumChain) reconcileTransaction(
+	recorded *outboxTransaction,
+	transaction *types.Transaction,
+) (string, error) {
+	if recorded.DeadlineBlock != 0 {
+		currentBlock, err := ec.blockCounter.CurrentBlock()
+		if err != nil {
+			return pendingTransaction, err
+		}
+
+		if currentBlock > recorded.DeadlineBlock {
+			return expiredTransaction, nil
+		}
+	}
+
+	operatorContract := ec.keepRandomBeaconOperator()
+
+	switch recorded.Type {
+	case submitTicketTransaction:
+		isGroupSelectionPossible, err :=
+			operatorContract.IsGroupSelectionPossible()
+		if err != nil {
+			return pendingTransaction, err
+		}
+		if isGroupSelectionPossible {
+			return obsoleteTransaction, nil
+		}
+	case submitDKGResultTransaction:
+		parameters, err := ec.outbox.parameters(transaction)
+		if err != nil {
+			return pendingTransaction, err
+		}
+
+		groupPublicKey, ok := parameters[1].([]byte)
+		if !ok {
+			return pendingTransaction, fmt.Errorf("unexpected group public key")
+		}
+
+		isGroupRegistered, err := ec.IsGroupRegistered(groupPublicKey)
+		if err != nil {
+			return pendingTransaction, err
+		}
+		if isGroupRegistered {
+			return obsoleteTransaction, nil
+		}
+	case submitRelayEntryTransaction, reportRelayEntryTimeoutTransaction:
+		isEntryInProgress, err := ec.IsEntryInProgress()
+		if err != nil {
+			return pendingTransaction, err
+		}
+		if !isEntryInProgress {
+			return obsoleteTransaction, nil
+		}
+
+		// The transaction has been submitted for a previous relay request.
+		requestStartBlock, err := ec.CurrentRequestStartBlock()
+		if err != nil {
+			return pendingTransaction, err
+		}
+		if requestStartBlock.Uint64() > recorded.SubmittedBlock {
+			return obsoleteTransaction, nil
+		}
+	}
+
+	return pendingTransaction, nil
+}
+
+// replaceTransaction signs the transaction again with the given gas price and
+// sends it to replace the original one.
+func (ec *ethereumChain) replaceTransaction(
+	ctx context.Context,
+	transactorOptions *bind.TransactOpts,
+	transaction *types.Transaction,
+	gasPrice *big.Int,
+) (*types.Transaction, error) {
+	var txSigner types.Signer = types.HomesteadSigner{}
+	if transaction.Protected() {
+		txSigner = types.NewEIP155Signer(transaction.ChainId())
+	}
+
+	replacement, err := transactorOptions.Signer(
+		txSigner,
+		transactorOptions.From,
+		types.NewTransaction(
+			transaction.Nonce(),
+			*transaction.To(),
+			transaction.Value(),
+			transaction.Gas(),
+			gasPrice,
+			transaction.Data(),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ec.client.SendTransaction(ctx, replacement); err != nil {
+		return nil, err
+	}
+
+	logger.Infof(
+		"replaced transaction with nonce [%v] with transaction [%v]",
+		replacement.Nonce(),
+		replacement.Hash().Hex(),
+	)
+
+	return replacement, nil
+}
+
+// completeMinedTransactions completes pending transactions which have been
+// mined or whose nonces have been used by other transactions of the account.
+func (ec *ethereumChain) completeMinedTransactions(ctx context.Context) {
+	pending := ec.outbox.pending()
+	if len(pending) == 0 {
+		return
+	}
+
+	// The pending nonce has to be read before transactions are looked up.
+	// A transaction recorded but not sent yet has a nonce equal to the
+	// pending nonce and it is not completed.
+	pendingNonce, err := ec.client.PendingNonceAt(ctx, ec.Address())
+	if err != nil {
+		logger.Warningf("could not get pending nonce: [%v]", err)
+		return
+	}
+
+	for _, recorded := range pending {
+		if recorded.Nonce >= pendingNonce {
+			continue
+		}
+
+		if status := ec.transactionStatus(ctx, recorded); status != pendingTransaction {
+			ec.outbox.complete(recorded.Nonce, status)
+		}
+	}
+}
+
+// transactionStatus returns the mined status if any version of the
+// transaction has been mined, the pending status if any version of the
+// transaction is known to the Ethereum node, and the dropped status
+// otherwise.
+func (ec *ethereumChain) transactionStatus(
+	ctx context.Context,
+	recorded *outboxTransaction,
+) string {
+	for _, hash := range recorded.Hashes {
+		receipt, err := ec.client.TransactionReceipt(ctx, hash)
+		if err == nil && receipt != nil {
+			return minedTransaction
+		}
+	}
+
+	for _, hash := range recorded.Hashes {
+		_, isPending, err := ec.client.TransactionByHash(ctx, hash)
+		if err == nil && isPending {
+			return pendingTransaction
+		}
+	}
+
+	return droppedTransaction
+}
+
+// hasPendingDKGResult returns true if the DKG result of the group with the
+// given public key is waiting in the outbox to be mined.
+func (ec *ethereumChain) hasPendingDKGResult(groupPublicKey []byte) bool {
+	return ec.isAlreadyPending(
+		submitDKGResultTransaction,
+		func(parameters []interface{}) bool {
+			pendingKey, ok := parameters[1].([]byte)
+			return ok && bytes.Equal(pendingKey, groupPublicKey)
+		},
+	)
+}
+
+// hasPendingRelayEntry returns true if the given relay entry is waiting in
+// the outbox to be mined.
+func (ec *ethereumChain) hasPendingRelayEntry(entry []byte) bool {
+	return ec.isAlreadyPending(
+		submitRelayEntryTransaction,
+		func(parameters []interface{}) bool {
+			pendingEntry, ok := parameters[0].([]byte)
+			return ok && bytes.Equal(pendingEntry, entry)
+		},
+	)
+}
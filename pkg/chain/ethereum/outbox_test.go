@@ -0,0 +1,430 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/keep-network/keep-common/pkg/chain/ethereum/ethutil"
+	"github.com/keep-network/keep-common/pkg/persistence"
+)
+
+func TestOutboxReloadsPendingTransactions(t *testing.T) {
+	handle := newTestPersistenceHandle()
+	outbox := newTestOutbox(t, handle)
+
+	entryTransaction := newTestTransaction(t, outbox, 3, 20, "relayEntry", []byte{0x01})
+	ticketTransaction := newTestTransaction(t, outbox, 4, 20, "submitTicket", [32]uint8{})
+
+	if err := outbox.record(submitRelayEntryTransaction, entryTransaction, 100); err != nil {
+		t.Fatal(err)
+	}
+	if err := outbox.record(submitTicketTransaction, ticketTransaction, 101); err != nil {
+		t.Fatal(err)
+	}
+	outbox.setDeadline(3, 120)
+	outbox.complete(4, minedTransaction)
+
+	reloaded := newTestOutbox(t, handle)
+	reloaded.load()
+
+	pending := reloaded.pending()
+	if len(pending) != 1 {
+		t.Fatalf(
+			"unexpected number of pending transactions\nexpected: [%v]\nactual:   [%v]",
+			1,
+			len(pending),
+		)
+	}
+
+	expected := &outboxTransaction{
+		Type:           submitRelayEntryTransaction,
+		Nonce:          3,
+		Hashes:         []common.Hash{entryTransaction.Hash()},
+		Payload:        pending[0].Payload,
+		SubmittedBlock: 100,
+		DeadlineBlock:  120,
+		Status:         pendingTransaction,
+	}
+	if !reflect.DeepEqual(expected, pending[0]) {
+		t.Errorf(
+			"unexpected pending transaction\nexpected: [%+v]\nactual:   [%+v]",
+			expected,
+			pending[0],
+		)
+	}
+
+	transaction, err := pending[0].transaction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if transaction.Hash() != entryTransaction.Hash() {
+		t.Errorf(
+			"unexpected transaction\nexpected: [%v]\nactual:   [%v]",
+			entryTransaction.Hash().Hex(),
+			transaction.Hash().Hex(),
+		)
+	}
+}
+
+func TestOutboxRecordsReplacements(t *testing.T) {
+	outbox := newTestOutbox(t, newTestPersistenceHandle())
+
+	original := newTestTransaction(t, outbox, 7, 20, "relayEntry", []byte{0x01})
+	replacement := newTestTransaction(t, outbox, 7, 24, "relayEntry", []byte{0x01})
+
+	if err := outbox.record(submitRelayEntryTransaction, original, 100); err != nil {
+		t.Fatal(err)
+	}
+	if err := outbox.record(submitRelayEntryTransaction, replacement, 105); err != nil {
+		t.Fatal(err)
+	}
+
+	pending := outbox.pending()
+	if len(pending) != 1 {
+		t.Fatalf(
+			"unexpected number of pending transactions\nexpected: [%v]\nactual:   [%v]",
+			1,
+			len(pending),
+		)
+	}
+
+	expectedHashes := []common.Hash{original.Hash(), replacement.Hash()}
+	if !reflect.DeepEqual(expectedHashes, pending[0].Hashes) {
+		t.Errorf(
+			"unexpected hashes\nexpected: [%v]\nactual:   [%v]",
+			expectedHashes,
+			pending[0].Hashes,
+		)
+	}
+
+	if pending[0].SubmittedBlock != 100 {
+		t.Errorf(
+			"unexpected submitted block\nexpected: [%v]\nactual:   [%v]",
+			100,
+			pending[0].SubmittedBlock,
+		)
+	}
+
+	transaction, err := pending[0].transaction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if transaction.GasPrice().Cmp(big.NewInt(24)) != 0 {
+		t.Errorf(
+			"unexpected gas price\nexpected: [%v]\nactual:   [%v]",
+			24,
+			transaction.GasPrice(),
+		)
+	}
+}
+
+func TestOutboxSkipsTransactionsOfOtherAccounts(t *testing.T) {
+	handle := newTestPersistenceHandle()
+	outbox := newTestOutbox(t, handle)
+
+	transaction := newTestTransaction(t, outbox, 1, 20, "relayEntry", []byte{0x01})
+	if err := outbox.record(submitRelayEntryTransaction, transaction, 100); err != nil {
+		t.Fatal(err)
+	}
+
+	otherOutbox, err := newTransactionOutbox(
+		handle,
+		common.HexToAddress("0x6299496199d99941193Fdd2d717ef585F431eA05"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherOutbox.load()
+
+	if pending := otherOutbox.pending(); len(pending) != 0 {
+		t.Errorf("unexpected pending transactions: [%v]", pending)
+	}
+}
+
+func TestHasPendingRelayEntry(t *testing.T) {
+	outbox := newTestOutbox(t, newTestPersistenceHandle())
+	ec := &ethereumChain{outbox: outbox}
+
+	transaction := newTestTransaction(t, outbox, 1, 20, "relayEntry", []byte{0x01})
+	if err := outbox.record(submitRelayEntryTransaction, transaction, 100); err != nil {
+		t.Fatal(err)
+	}
+
+	if !ec.hasPendingRelayEntry([]byte{0x01}) {
+		t.Errorf("relay entry should be pending")
+	}
+	if ec.hasPendingRelayEntry([]byte{0x02}) {
+		t.Errorf("other relay entry should not be pending")
+	}
+
+	outbox.complete(1, minedTransaction)
+
+	if ec.hasPendingRelayEntry([]byte{0x01}) {
+		t.Errorf("mined relay entry should not be pending")
+	}
+}
+
+func TestOutboxDiscardsRejectedTransactions(t *testing.T) {
+	var tests = map[string]struct {
+		sendError       error
+		expectedPending bool
+	}{
+		"nonce too low": {
+			sendError:       fmt.Errorf("nonce too low"),
+			expectedPending: false,
+		},
+		"replacement underpriced": {
+			sendError:       fmt.Errorf("replacement transaction underpriced"),
+			expectedPending: false,
+		},
+		"insufficient funds": {
+			sendError: fmt.Errorf(
+				"insufficient funds for gas * price + value",
+			),
+			expectedPending: false,
+		},
+		"connection error": {
+			sendError:       fmt.Errorf("connection refused"),
+			expectedPending: true,
+		},
+		"timeout": {
+			sendError:       context.DeadlineExceeded,
+			expectedPending: true,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			outbox := newTestOutbox(t, newTestPersistenceHandle())
+			ec := &ethereumChain{outbox: outbox}
+			backend := &outboxBackend{
+				&testFailingClient{err: test.sendError},
+				outbox,
+			}
+
+			transaction := newTestTransaction(
+				t,
+				outbox,
+				1,
+				20,
+				"relayEntry",
+				[]byte{0x01},
+			)
+			err := outbox.record(submitRelayEntryTransaction, transaction, 100)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = backend.SendTransaction(context.Background(), transaction)
+			if err == nil {
+				t.Fatal("expected send error")
+			}
+
+			isPending := ec.hasPendingRelayEntry([]byte{0x01})
+			if isPending != test.expectedPending {
+				t.Errorf(
+					"unexpected pending relay entry\n"+
+						"expected: [%v]\nactual:   [%v]",
+					test.expectedPending,
+					isPending,
+				)
+			}
+		})
+	}
+}
+
+func TestOutboxKeepsTransactionsWithFailedReplacement(t *testing.T) {
+	outbox := newTestOutbox(t, newTestPersistenceHandle())
+	backend := &outboxBackend{
+		&testFailingClient{
+			err: fmt.Errorf("replacement transaction underpriced"),
+		},
+		outbox,
+	}
+
+	original := newTestTransaction(t, outbox, 7, 20, "relayEntry", []byte{0x01})
+	replacement := newTestTransaction(t, outbox, 7, 24, "relayEntry", []byte{0x01})
+
+	if err := outbox.record(submitRelayEntryTransaction, original, 100); err != nil {
+		t.Fatal(err)
+	}
+	if err := outbox.record(submitRelayEntryTransaction, replacement, 105); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := backend.SendTransaction(context.Background(), replacement); err == nil {
+		t.Fatal("expected send error")
+	}
+
+	if pending := outbox.pending(); len(pending) != 1 {
+		t.Errorf(
+			"unexpected number of pending transactions\nexpected: [%v]\nactual:   [%v]",
+			1,
+			len(pending),
+		)
+	}
+}
+
+func TestHasPendingRelayEntryWithoutOutbox(t *testing.T) {
+	ec := &ethereumChain{}
+
+	if ec.hasPendingRelayEntry([]byte{0x01}) {
+		t.Errorf("relay entry should not be pending")
+	}
+}
+
+var testOutboxKey, _ = crypto.GenerateKey()
+
+func newTestOutbox(
+	t *testing.T,
+	handle persistence.Handle,
+) *transactionOutbox {
+	outbox, err := newTransactionOutbox(
+		handle,
+		crypto.PubkeyToAddress(testOutboxKey.PublicKey),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return outbox
+}
+
+func newTestTransaction(
+	t *testing.T,
+	outbox *transactionOutbox,
+	nonce uint64,
+	gasPrice int64,
+	method string,
+	parameters ...interface{},
+) *types.Transaction {
+	data, err := outbox.contractABI.Pack(method, parameters...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transaction, err := types.SignTx(
+		types.NewTransaction(
+			nonce,
+			common.HexToAddress("0x5D5B1f4fb5EB4E8Ea17C4F4B9ec4f4E3aF0aB2c1"),
+			big.NewInt(0),
+			100000,
+			big.NewInt(gasPrice),
+			data,
+		),
+		types.HomesteadSigner{},
+		testOutboxKey,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return transaction
+}
+
+// testFailingClient fails to send any transaction with the given error.
+type testFailingClient struct {
+	ethutil.HostChainClient
+
+	err error
+}
+
+func (tfc *testFailingClient) SendTransaction(
+	ctx context.Context,
+	transaction *types.Transaction,
+) error {
+	return tfc.err
+}
+
+// testPersistenceHandle keeps data in memory. Data is returned in the order
+// its directories have been created.
+type testPersistenceHandle struct {
+	mutex sync.Mutex
+	dirs  []string
+	data  map[string]map[string][]byte
+}
+
+func newTestPersistenceHandle() *testPersistenceHandle {
+	return &testPersistenceHandle{
+		data: make(map[string]map[string][]byte),
+	}
+}
+
+func (tph *testPersistenceHandle) Save(data []byte, dir, name string) error {
+	tph.mutex.Lock()
+	defer tph.mutex.Unlock()
+
+	if _, ok := tph.data[dir]; !ok {
+		tph.dirs = append(tph.dirs, dir)
+		tph.data[dir] = make(map[string][]byte)
+	}
+	tph.data[dir][name] = data
+
+	return nil
+}
+
+func (tph *testPersistenceHandle) Snapshot(data []byte, dir, name string) error {
+	return tph.Save(data, dir, name)
+}
+
+func (tph *testPersistenceHandle) ReadAll() (
+	<-chan persistence.DataDescriptor,
+	<-chan error,
+) {
+	tph.mutex.Lock()
+	defer tph.mutex.Unlock()
+
+	dataChannel := make(chan persistence.DataDescriptor, len(tph.dirs))
+	errorChannel := make(chan error)
+
+	for _, dir := range tph.dirs {
+		for name, content := range tph.data[dir] {
+			dataChannel <- &testDataDescriptor{name, dir, content}
+		}
+	}
+
+	close(dataChannel)
+	close(errorChannel)
+
+	return dataChannel, errorChannel
+}
+
+func (tph *testPersistenceHandle) Archive(dir string) error {
+	tph.mutex.Lock()
+	defer tph.mutex.Unlock()
+
+	delete(tph.data, dir)
+	for i, existing := range tph.dirs {
+		if existing == dir {
+			tph.dirs = append(tph.dirs[:i], tph.dirs[i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+type testDataDescriptor struct {
+	name      string
+	directory string
+	content   []byte
+}
+
+func (tdd *testDataDescriptor) Name() string {
+	return tdd.name
+}
+
+func (tdd *testDataDescriptor) Directory() string {
+	return tdd.directory
+}
+
+func (tdd *testDataDescriptor) Content() ([]byte, error) {
+	return tdd.content, nil
+}
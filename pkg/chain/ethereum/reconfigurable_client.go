@@ -0,0 +1,209 @@
+package ethereum
+
+import (
+	"context"
+	"math/big"
+	"sync"
+
+	hostchain "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/keep-network/keep-common/pkg/chain/ethereum"
+	"github.com/keep-network/keep-common/pkg/chain/ethereum/ethutil"
+	"github.com/keep-network/keep-common/pkg/rate"
+)
+
+// reconfigurableClient is a host chain client whose request rate limits can
+// be changed while the client is in use. Each request is delegated to the
+// client wrapper built from the most recent config. Requests in progress
+// complete with the limits they were started with.
+type reconfigurableClient struct {
+	loggingBackend ethutil.HostChainClient
+
+	mutex   sync.RWMutex
+	current ethutil.HostChainClient
+}
+
+func addClientWrappers(
+	config ethereum.Config,
+	backend ethutil.HostChainClient,
+) *reconfigurableClient {
+	client := &reconfigurableClient{
+		loggingBackend: ethutil.WrapCallLogging(logger, backend),
+	}
+	client.reconfigure(config)
+
+	return client
+}
+
+// reconfigure applies the request rate limits from the provided config.
+func (rc *reconfigurableClient) reconfigure(config ethereum.Config) {
+	current := rc.loggingBackend
+
+	if config.RequestsPerSecondLimit > 0 || config.ConcurrencyLimit > 0 {
+		logger.Infof(
+			"enabled ethereum client request rate limiter; "+
+				"rps limit [%v]; "+
+				"concurrency limit [%v]",
+			config.RequestsPerSecondLimit,
+			config.ConcurrencyLimit,
+		)
+
+		current = ethutil.WrapRateLimiting(
+			rc.loggingBackend,
+			&rate.LimiterConfig{
+				RequestsPerSecondLimit: config.RequestsPerSecondLimit,
+				ConcurrencyLimit:       config.ConcurrencyLimit,
+			},
+		)
+	}
+
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+
+	rc.current = current
+}
+
+func (rc *reconfigurableClient) client() ethutil.HostChainClient {
+	rc.mutex.RLock()
+	defer rc.mutex.RUnlock()
+
+	return rc.current
+}
+
+func (rc *reconfigurableClient) CodeAt(
+	ctx context.Context,
+	contract common.Address,
+	blockNumber *big.Int,
+) ([]byte, error) {
+	return rc.client().CodeAt(ctx, contract, blockNumber)
+}
+
+func (rc *reconfigurableClient) CallContract(
+	ctx context.Context,
+	call hostchain.CallMsg,
+	blockNumber *big.Int,
+) ([]byte, error) {
+	return rc.client().CallContract(ctx, call, blockNumber)
+}
+
+func (rc *reconfigurableClient) PendingCodeAt(
+	ctx context.Context,
+	account common.Address,
+) ([]byte, error) {
+	return rc.client().PendingCodeAt(ctx, account)
+}
+
+func (rc *reconfigurableClient) PendingNonceAt(
+	ctx context.Context,
+	account common.Address,
+) (uint64, error) {
+	return rc.client().PendingNonceAt(ctx, account)
+}
+
+func (rc *reconfigurableClient) SuggestGasPrice(
+	ctx context.Context,
+) (*big.Int, error) {
+	return rc.client().SuggestGasPrice(ctx)
+}
+
+func (rc *reconfigurableClient) EstimateGas(
+	ctx context.Context,
+	call hostchain.CallMsg,
+) (uint64, error) {
+	return rc.client().EstimateGas(ctx, call)
+}
+
+func (rc *reconfigurableClient) SendTransaction(
+	ctx context.Context,
+	tx *types.Transaction,
+) error {
+	return rc.client().SendTransaction(ctx, tx)
+}
+
+func (rc *reconfigurableClient) FilterLogs(
+	ctx context.Context,
+	query hostchain.FilterQuery,
+) ([]types.Log, error) {
+	return rc.client().FilterLogs(ctx, query)
+}
+
+func (rc *reconfigurableClient) SubscribeFilterLogs(
+	ctx context.Context,
+	query hostchain.FilterQuery,
+	ch chan<- types.Log,
+) (hostchain.Subscription, error) {
+	return rc.client().SubscribeFilterLogs(ctx, query, ch)
+}
+
+func (rc *reconfigurableClient) BlockByHash(
+	ctx context.Context,
+	hash common.Hash,
+) (*types.Block, error) {
+	return rc.client().BlockByHash(ctx, hash)
+}
+
+func (rc *reconfigurableClient) BlockByNumber(
+	ctx context.Context,
+	number *big.Int,
+) (*types.Block, error) {
+	return rc.client().BlockByNumber(ctx, number)
+}
+
+func (rc *reconfigurableClient) HeaderByHash(
+	ctx context.Context,
+	hash common.Hash,
+) (*types.Header, error) {
+	return rc.client().HeaderByHash(ctx, hash)
+}
+
+func (rc *reconfigurableClient) HeaderByNumber(
+	ctx context.Context,
+	number *big.Int,
+) (*types.Header, error) {
+	return rc.client().HeaderByNumber(ctx, number)
+}
+
+func (rc *reconfigurableClient) TransactionCount(
+	ctx context.Context,
+	blockHash common.Hash,
+) (uint, error) {
+	return rc.client().TransactionCount(ctx, blockHash)
+}
+
+func (rc *reconfigurableClient) TransactionInBlock(
+	ctx context.Context,
+	blockHash common.Hash,
+	index uint,
+) (*types.Transaction, error) {
+	return rc.client().TransactionInBlock(ctx, blockHash, index)
+}
+
+func (rc *reconfigurableClient) SubscribeNewHead(
+	ctx context.Context,
+	ch chan<- *types.Header,
+) (hostchain.Subscription, error) {
+	return rc.client().SubscribeNewHead(ctx, ch)
+}
+
+func (rc *reconfigurableClient) TransactionByHash(
+	ctx context.Context,
+	txHash common.Hash,
+) (*types.Transaction, bool, error) {
+	return rc.client().TransactionByHash(ctx, txHash)
+}
+
+func (rc *reconfigurableClient) TransactionReceipt(
+	ctx context.Context,
+	txHash common.Hash,
+) (*types.Receipt, error) {
+	return rc.client().TransactionReceipt(ctx, txHash)
+}
+
+func (rc *reconfigurableClient) BalanceAt(
+	ctx context.Context,
+	account common.Address,
+	blockNumber *big.Int,
+) (*big.Int, error) {
+	return rc.client().BalanceAt(ctx, account, blockNumber)
+}
@@ -0,0 +1,43 @@
+package signer
+
+import (
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/keep-network/keep-common/pkg/chain/ethereum/ethutil"
+)
+
+// Local is a signer using the operator key held by the client process,
+// usually decrypted from a key file.
+type Local struct {
+	*ethutil.EthereumSigner
+
+	key *keystore.Key
+}
+
+// NewLocal creates a new signer for the provided operator key.
+func NewLocal(key *keystore.Key) *Local {
+	return &Local{
+		EthereumSigner: ethutil.NewSigner(key.PrivateKey),
+		key:            key,
+	}
+}
+
+// Address returns the address of the operator.
+func (l *Local) Address() common.Address {
+	return l.key.Address
+}
+
+// Key returns the operator key the signer has been created with.
+func (l *Local) Key() *keystore.Key {
+	return l.key
+}
+
+// SignTransaction signs the provided transaction with the operator key using
+// the signature scheme of the provided transaction signer.
+func (l *Local) SignTransaction(
+	txSigner types.Signer,
+	tx *types.Transaction,
+) (*types.Transaction, error) {
+	return types.SignTx(tx, txSigner, l.key.PrivateKey)
+}
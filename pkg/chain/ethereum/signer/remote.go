@@ -0,0 +1,324 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/keep-network/keep-common/pkg/chain/ethereum/ethutil"
+)
+
+// requestTimeout is the maximum time the remote signer waits for a response
+// of the signing service.
+const requestTimeout = 10 * time.Second
+
+// publicKeyProbe is the message signed by the signing service when the remote
+// signer connects to it. The operator's public key is recovered from the
+// signature.
+var publicKeyProbe = []byte("keep remote signer public key probe")
+
+// transactionArgs are the arguments of the eth_signTransaction call.
+type transactionArgs struct {
+	From     common.Address  `json:"from"`
+	To       *common.Address `json:"to"`
+	Gas      hexutil.Uint64  `json:"gas"`
+	GasPrice *hexutil.Big    `json:"gasPrice"`
+	Value    *hexutil.Big    `json:"value"`
+	Nonce    hexutil.Uint64  `json:"nonce"`
+	Data     hexutil.Bytes   `json:"data"`
+}
+
+// Remote is a signer delegating all signing operations to an external signing
+// service exposing the Web3Signer-compatible JSON-RPC API, that is
+// `eth_accounts`, `eth_sign` and `eth_signTransaction` methods. The operator
+// key never enters the client process.
+type Remote struct {
+	// EthereumSigner is created only with the operator's public key and is
+	// used for signature verification and address conversions. Signing is
+	// always delegated to the signing service.
+	*ethutil.EthereumSigner
+
+	client  *rpc.Client
+	address common.Address
+}
+
+// ConnectRemote connects to the signing service under the provided URL and
+// returns a signer for the provided operator address. Both HTTP and local IPC
+// socket URLs are supported. The signing service must hold the key of the
+// operator.
+func ConnectRemote(
+	ctx context.Context,
+	url string,
+	address common.Address,
+) (*Remote, error) {
+	client, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"could not connect to signing service [%v]: [%v]",
+			url,
+			err,
+		)
+	}
+
+	remote := &Remote{
+		client:  client,
+		address: address,
+	}
+
+	publicKey, err := remote.recoverPublicKey()
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	remote.EthereumSigner = ethutil.NewSigner(
+		&ecdsa.PrivateKey{PublicKey: *publicKey},
+	)
+
+	return remote, nil
+}
+
+// recoverPublicKey makes sure the signing service holds the key of the
+// operator and recovers the operator's public key from a probe signature.
+func (r *Remote) recoverPublicKey() (*ecdsa.PublicKey, error) {
+	var serviceAccounts []common.Address
+	if err := r.call(&serviceAccounts, "eth_accounts"); err != nil {
+		return nil, fmt.Errorf("could not list signing service accounts: [%v]", err)
+	}
+
+	hasAccount := false
+	for _, account := range serviceAccounts {
+		if account == r.address {
+			hasAccount = true
+			break
+		}
+	}
+	if !hasAccount {
+		return nil, fmt.Errorf(
+			"signing service does not hold the key of operator [%v]",
+			r.address.Hex(),
+		)
+	}
+
+	signature, err := r.sign(publicKeyProbe)
+	if err != nil {
+		return nil, err
+	}
+
+	// Recovery expects the recovery id, V, in {0, 1}.
+	recoverable := append([]byte{}, signature...)
+	recoverable[ethutil.SignatureSize-1] -= 27
+
+	publicKey, err := crypto.SigToPub(
+		accounts.TextHash(publicKeyProbe),
+		recoverable,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not recover operator public key: [%v]", err)
+	}
+
+	if recovered := crypto.PubkeyToAddress(*publicKey); recovered != r.address {
+		return nil, fmt.Errorf(
+			"signing service signed with key of [%v] instead of operator [%v]",
+			recovered.Hex(),
+			r.address.Hex(),
+		)
+	}
+
+	return publicKey, nil
+}
+
+// Address returns the address of the operator.
+func (r *Remote) Address() common.Address {
+	return r.address
+}
+
+// Sign requests the signing service to sign the provided message with the
+// operator key. The returned signature is in the Ethereum-specific format and
+// it is verified before being returned.
+func (r *Remote) Sign(message []byte) ([]byte, error) {
+	signature, err := r.sign(message)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := r.Verify(message, signature)
+	if err != nil {
+		return nil, fmt.Errorf("could not verify signing service signature: [%v]", err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("signing service returned invalid signature")
+	}
+
+	return signature, nil
+}
+
+func (r *Remote) sign(message []byte) ([]byte, error) {
+	var signature hexutil.Bytes
+	if err := r.call(
+		&signature,
+		"eth_sign",
+		r.address,
+		hexutil.Bytes(message),
+	); err != nil {
+		return nil, fmt.Errorf("could not sign message: [%v]", err)
+	}
+
+	if len(signature) != ethutil.SignatureSize {
+		return nil, fmt.Errorf(
+			"signature should have [%v] bytes; has: [%v]",
+			ethutil.SignatureSize,
+			len(signature),
+		)
+	}
+
+	// Some signing services return V in {0, 1}, the on-chain signature
+	// validation code accepts only V in {27, 28}.
+	if signature[ethutil.SignatureSize-1] < 27 {
+		signature[ethutil.SignatureSize-1] += 27
+	}
+
+	return signature, nil
+}
+
+// SignTransaction requests the signing service to sign the provided
+// transaction with the operator key. The signing service applies its own
+// signature scheme, so the signed transaction is checked against the provided
+// one and against the chain and replay protection of the provided transaction
+// signer before it is returned.
+func (r *Remote) SignTransaction(
+	txSigner types.Signer,
+	tx *types.Transaction,
+) (*types.Transaction, error) {
+	var result json.RawMessage
+	if err := r.call(
+		&result,
+		"eth_signTransaction",
+		transactionArgs{
+			From:     r.address,
+			To:       tx.To(),
+			Gas:      hexutil.Uint64(tx.Gas()),
+			GasPrice: (*hexutil.Big)(tx.GasPrice()),
+			Value:    (*hexutil.Big)(tx.Value()),
+			Nonce:    hexutil.Uint64(tx.Nonce()),
+			Data:     tx.Data(),
+		},
+	); err != nil {
+		return nil, fmt.Errorf("could not sign transaction: [%v]", err)
+	}
+
+	rawTransaction, err := decodeSignedTransaction(result)
+	if err != nil {
+		return nil, err
+	}
+
+	signedTx := new(types.Transaction)
+	if err := rlp.DecodeBytes(rawTransaction, signedTx); err != nil {
+		return nil, fmt.Errorf("could not decode signed transaction: [%v]", err)
+	}
+
+	if err := r.checkSignedTransaction(txSigner, tx, signedTx); err != nil {
+		return nil, err
+	}
+
+	return signedTx, nil
+}
+
+// decodeSignedTransaction extracts the raw signed transaction from the
+// eth_signTransaction result. Web3Signer returns the raw transaction directly
+// while other signing services wrap it in an object along with the decoded
+// transaction.
+func decodeSignedTransaction(result json.RawMessage) ([]byte, error) {
+	var rawTransaction hexutil.Bytes
+	if err := json.Unmarshal(result, &rawTransaction); err == nil {
+		return rawTransaction, nil
+	}
+
+	var wrapped struct {
+		Raw hexutil.Bytes `json:"raw"`
+	}
+	if err := json.Unmarshal(result, &wrapped); err != nil {
+		return nil, fmt.Errorf("unexpected signed transaction format: [%v]", err)
+	}
+
+	return wrapped.Raw, nil
+}
+
+func (r *Remote) checkSignedTransaction(
+	txSigner types.Signer,
+	tx *types.Transaction,
+	signedTx *types.Transaction,
+) error {
+	// The signing service must sign the transaction for the chain the
+	// transaction is going to be submitted to. Transactions without replay
+	// protection are accepted only if the requested signer does not provide
+	// it either.
+	if signedTx.Protected() {
+		if !types.NewEIP155Signer(signedTx.ChainId()).Equal(txSigner) {
+			return fmt.Errorf(
+				"signing service signed transaction for unexpected chain [%v]",
+				signedTx.ChainId(),
+			)
+		}
+	} else if !(types.HomesteadSigner{}).Equal(txSigner) {
+		return fmt.Errorf(
+			"signing service signed transaction without replay protection",
+		)
+	}
+
+	sender, err := types.Sender(txSigner, signedTx)
+	if err != nil {
+		return fmt.Errorf("could not recover signed transaction sender: [%v]", err)
+	}
+	if sender != r.address {
+		return fmt.Errorf(
+			"signing service signed transaction as [%v] instead of operator [%v]",
+			sender.Hex(),
+			r.address.Hex(),
+		)
+	}
+
+	if signedTx.Nonce() != tx.Nonce() ||
+		signedTx.Gas() != tx.Gas() ||
+		signedTx.GasPrice().Cmp(tx.GasPrice()) != 0 ||
+		signedTx.Value().Cmp(tx.Value()) != 0 ||
+		!equalRecipients(signedTx.To(), tx.To()) ||
+		!bytes.Equal(signedTx.Data(), tx.Data()) {
+		return fmt.Errorf("signing service altered the signed transaction")
+	}
+
+	return nil
+}
+
+func equalRecipients(a, b *common.Address) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func (r *Remote) call(
+	result interface{},
+	method string,
+	args ...interface{},
+) error {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	return r.client.CallContext(ctx, result, method, args...)
+}
+
+// Close closes the connection to the signing service.
+func (r *Remote) Close() {
+	r.client.Close()
+}
@@ -0,0 +1,244 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pborman/uuid"
+)
+
+var chainID = big.NewInt(1101)
+
+func TestRemoteSignerOverHTTP(t *testing.T) {
+	key := generateKey(t)
+
+	server, err := NewStandInServer(key, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	remote, err := ConnectRemote(context.Background(), httpServer.URL, key.Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer remote.Close()
+
+	assertSignerMatchesKey(t, remote, key)
+}
+
+func TestRemoteSignerOverIPC(t *testing.T) {
+	key := generateKey(t)
+
+	server, err := NewStandInServer(key, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	socketDir, err := ioutil.TempDir("", "signer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(socketDir)
+
+	socketPath := filepath.Join(socketDir, "signer.ipc")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.ServeListener(listener)
+
+	remote, err := ConnectRemote(context.Background(), socketPath, key.Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer remote.Close()
+
+	assertSignerMatchesKey(t, remote, key)
+}
+
+func TestRemoteSignerUnknownOperator(t *testing.T) {
+	key := generateKey(t)
+
+	server, err := NewStandInServer(key, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	otherAddress := generateKey(t).Address
+
+	_, err = ConnectRemote(context.Background(), httpServer.URL, otherAddress)
+	if err == nil {
+		t.Fatal("expected error for operator without key in signing service")
+	}
+}
+
+func TestRemoteSignerUnexpectedChain(t *testing.T) {
+	key := generateKey(t)
+
+	tx := types.NewTransaction(
+		7,
+		common.HexToAddress("0x65ea55c1f10491038425725dc00dffeab2a1e28a"),
+		big.NewInt(10),
+		21000,
+		big.NewInt(3000000000),
+		nil,
+	)
+
+	var tests = map[string]struct {
+		serviceSigner types.Signer
+		txSigner      types.Signer
+		expectedError string
+	}{
+		"other chain": {
+			serviceSigner: types.NewEIP155Signer(chainID),
+			txSigner:      types.NewEIP155Signer(big.NewInt(1)),
+			expectedError: "signing service signed transaction for " +
+				"unexpected chain [1101]",
+		},
+		"no replay protection": {
+			serviceSigner: types.HomesteadSigner{},
+			txSigner:      types.NewEIP155Signer(chainID),
+			expectedError: "signing service signed transaction without " +
+				"replay protection",
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			server := rpc.NewServer()
+			defer server.Stop()
+
+			err := server.RegisterName("eth", &standInService{
+				key:      key,
+				txSigner: test.serviceSigner,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			httpServer := httptest.NewServer(server)
+			defer httpServer.Close()
+
+			remote, err := ConnectRemote(
+				context.Background(),
+				httpServer.URL,
+				key.Address,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer remote.Close()
+
+			_, err = remote.SignTransaction(test.txSigner, tx)
+			if err == nil || err.Error() != test.expectedError {
+				t.Errorf(
+					"unexpected error\nexpected: [%v]\nactual:   [%v]",
+					test.expectedError,
+					err,
+				)
+			}
+		})
+	}
+}
+
+func assertSignerMatchesKey(t *testing.T, remote *Remote, key *keystore.Key) {
+	local := NewLocal(key)
+
+	if remote.Address() != local.Address() {
+		t.Errorf(
+			"unexpected address\nexpected: [%v]\nactual:   [%v]",
+			local.Address().Hex(),
+			remote.Address().Hex(),
+		)
+	}
+
+	if !bytes.Equal(remote.PublicKey(), local.PublicKey()) {
+		t.Errorf(
+			"unexpected public key\nexpected: [%x]\nactual:   [%x]",
+			local.PublicKey(),
+			remote.PublicKey(),
+		)
+	}
+
+	message := []byte("he who controls the spice")
+	signature, err := remote.Sign(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ok, err := local.Verify(message, signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Errorf("remote signature not valid for operator key")
+	}
+
+	recipient := common.HexToAddress("0x65ea55c1f10491038425725dc00dffeab2a1e28a")
+	tx := types.NewTransaction(
+		7,
+		recipient,
+		big.NewInt(10),
+		21000,
+		big.NewInt(3000000000),
+		[]byte{0x01, 0x02},
+	)
+
+	signedTx, err := TransactorOptions(remote).Signer(
+		types.NewEIP155Signer(chainID),
+		remote.Address(),
+		tx,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sender, err := types.Sender(types.NewEIP155Signer(chainID), signedTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sender != key.Address {
+		t.Errorf(
+			"unexpected transaction sender\nexpected: [%v]\nactual:   [%v]",
+			key.Address.Hex(),
+			sender.Hex(),
+		)
+	}
+	if signedTx.Nonce() != tx.Nonce() || !bytes.Equal(signedTx.Data(), tx.Data()) {
+		t.Errorf("signed transaction does not match the requested one")
+	}
+}
+
+func generateKey(t *testing.T) *keystore.Key {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &keystore.Key{
+		Id:         uuid.NewRandom(),
+		Address:    crypto.PubkeyToAddress(privateKey.PublicKey),
+		PrivateKey: privateKey,
+	}
+}
@@ -0,0 +1,53 @@
+// Package signer provides signers producing signatures and signed
+// transactions on behalf of the operator. The operator key may be held either
+// by the client process or by an external signing service, in which case only
+// signing requests leave the process and the key itself never enters it.
+package signer
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/keep-network/keep-core/pkg/chain"
+)
+
+// Signer signs messages and transactions on behalf of the operator.
+type Signer interface {
+	chain.Signing
+
+	// Address returns the address of the operator.
+	Address() common.Address
+
+	// SignTransaction signs the provided transaction on behalf of the
+	// operator. The transaction signer determines the signature scheme;
+	// signers backed by an external signing service may apply the scheme
+	// configured in that service instead.
+	SignTransaction(
+		txSigner types.Signer,
+		tx *types.Transaction,
+	) (*types.Transaction, error)
+}
+
+// TransactorOptions creates contract binding transactor options which sign
+// all transactions with the provided signer.
+func TransactorOptions(signer Signer) *bind.TransactOpts {
+	return &bind.TransactOpts{
+		From: signer.Address(),
+		Signer: func(
+			txSigner types.Signer,
+			address common.Address,
+			tx *types.Transaction,
+		) (*types.Transaction, error) {
+			if address != signer.Address() {
+				return nil, fmt.Errorf(
+					"not authorized to sign transactions of [%v]",
+					address.Hex(),
+				)
+			}
+
+			return signer.SignTransaction(txSigner, tx)
+		},
+	}
+}
@@ -0,0 +1,95 @@
+package signer
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/keep-network/keep-common/pkg/chain/ethereum/ethutil"
+)
+
+// NewStandInServer creates a JSON-RPC server standing in for an external
+// signing service. The server holds the provided key and exposes the subset of
+// the Web3Signer-compatible API used by the remote signer. Transactions are
+// signed for the provided chain ID. The server can be exposed over HTTP or
+// over a local IPC socket. It is meant to be used in tests and development
+// environments only.
+func NewStandInServer(key *keystore.Key, chainID *big.Int) (*rpc.Server, error) {
+	server := rpc.NewServer()
+
+	if err := server.RegisterName("eth", &standInService{
+		key:      key,
+		txSigner: types.NewEIP155Signer(chainID),
+	}); err != nil {
+		return nil, fmt.Errorf("could not register signing service: [%v]", err)
+	}
+
+	return server, nil
+}
+
+type standInService struct {
+	key      *keystore.Key
+	txSigner types.Signer
+}
+
+func (s *standInService) Accounts() []common.Address {
+	return []common.Address{s.key.Address}
+}
+
+func (s *standInService) Sign(
+	address common.Address,
+	data hexutil.Bytes,
+) (hexutil.Bytes, error) {
+	if err := s.checkAddress(address); err != nil {
+		return nil, err
+	}
+
+	return ethutil.NewSigner(s.key.PrivateKey).Sign(data)
+}
+
+func (s *standInService) SignTransaction(
+	args transactionArgs,
+) (hexutil.Bytes, error) {
+	if err := s.checkAddress(args.From); err != nil {
+		return nil, err
+	}
+
+	var tx *types.Transaction
+	if args.To == nil {
+		tx = types.NewContractCreation(
+			uint64(args.Nonce),
+			(*big.Int)(args.Value),
+			uint64(args.Gas),
+			(*big.Int)(args.GasPrice),
+			args.Data,
+		)
+	} else {
+		tx = types.NewTransaction(
+			uint64(args.Nonce),
+			*args.To,
+			(*big.Int)(args.Value),
+			uint64(args.Gas),
+			(*big.Int)(args.GasPrice),
+			args.Data,
+		)
+	}
+
+	signedTx, err := types.SignTx(tx, s.txSigner, s.key.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return rlp.EncodeToBytes(signedTx)
+}
+
+func (s *standInService) checkAddress(address common.Address) error {
+	if address != s.key.Address {
+		return fmt.Errorf("unknown account [%v]", address.Hex())
+	}
+	return nil
+}
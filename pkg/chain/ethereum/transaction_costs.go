@@ -0,0 +1,135 @@
+package ethereum
+
+import (
+	"context"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/keep-network/keep-common/pkg/chain/ethereum/ethutil"
+	"github.com/keep-network/keep-core/pkg/metrics"
+)
+
+// forgottenNonceLag is the number of nonces by which a transaction has to
+// fall behind a mined transaction of the same account for its versions to be
+// forgotten when none of them has been seen mined.
+const forgottenNonceLag = 100
+
+// transactionVersion is a signed version of a submitted transaction.
+type transactionVersion struct {
+	transactionType string
+	hash            common.Hash
+	gasPrice        *big.Int
+}
+
+// transactionCosts records costs of transactions submitted by a single
+// account as part of the protocol. Signed versions of the transactions are
+// tracked until any of them is mined; the cost of the transaction is the gas
+// used by the mined version multiplied by its gas price.
+type transactionCosts struct {
+	client   ethutil.HostChainClient
+	metrics  *metrics.Ethereum
+	operator string
+
+	mutex    sync.Mutex
+	versions map[uint64][]*transactionVersion
+}
+
+func newTransactionCosts(
+	client ethutil.HostChainClient,
+	ethereumMetrics *metrics.Ethereum,
+	operator string,
+) *transactionCosts {
+	return &transactionCosts{
+		client:   client,
+		metrics:  ethereumMetrics,
+		operator: operator,
+		versions: make(map[uint64][]*transactionVersion),
+	}
+}
+
+// track remembers the signed version of the transaction of the given type.
+func (tc *transactionCosts) track(
+	transactionType string,
+	transaction *types.Transaction,
+) {
+	tc.mutex.Lock()
+	defer tc.mutex.Unlock()
+
+	tc.versions[transaction.Nonce()] = append(
+		tc.versions[transaction.Nonce()],
+		&transactionVersion{
+			transactionType: transactionType,
+			hash:            transaction.Hash(),
+			gasPrice:        transaction.GasPrice(),
+		},
+	)
+}
+
+// recordMined looks up receipts of all tracked transactions and records the
+// cost of the mined ones. Transactions with nonces far below the nonce of
+// a mined transaction are forgotten as they are not going to be mined.
+func (tc *transactionCosts) recordMined(ctx context.Context) {
+	tc.mutex.Lock()
+	pending := make(map[uint64][]*transactionVersion, len(tc.versions))
+	for nonce, versions := range tc.versions {
+		pending[nonce] = versions
+	}
+	tc.mutex.Unlock()
+
+	for nonce, versions := range pending {
+		for _, version := range versions {
+			receipt, err := tc.client.TransactionReceipt(ctx, version.hash)
+			if err != nil || receipt == nil {
+				continue
+			}
+
+			tc.record(version, receipt)
+			tc.forget(nonce)
+			break
+		}
+	}
+}
+
+func (tc *transactionCosts) record(
+	version *transactionVersion,
+	receipt *types.Receipt,
+) {
+	costGwei := weiToGwei(
+		new(big.Int).Mul(
+			version.gasPrice,
+			new(big.Int).SetUint64(receipt.GasUsed),
+		),
+	)
+
+	tc.metrics.TransactionCostGwei.Add(
+		costGwei,
+		tc.operator,
+		version.transactionType,
+	)
+}
+
+// forget stops tracking the transaction with the given nonce, which has been
+// mined, and transactions with nonces too far below it.
+func (tc *transactionCosts) forget(minedNonce uint64) {
+	tc.mutex.Lock()
+	defer tc.mutex.Unlock()
+
+	for nonce := range tc.versions {
+		if nonce == minedNonce || nonce+forgottenNonceLag < minedNonce {
+			delete(tc.versions, nonce)
+		}
+	}
+}
+
+// startTransactionCosts starts recording costs of transactions mined in new
+// blocks until the context is done.
+func (ec *ethereumChain) startTransactionCosts(ctx context.Context) {
+	go func() {
+		blocks := ec.blockCounter.WatchBlocks(ctx)
+		for range blocks {
+			ec.transactionCosts.recordMined(ctx)
+		}
+	}()
+}
@@ -0,0 +1,120 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/keep-network/keep-common/pkg/chain/ethereum/ethutil"
+	"github.com/keep-network/keep-core/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestTransactionCostsRecordMinedVersion(t *testing.T) {
+	registry := metrics.NewRegistry()
+
+	ethereumMetrics, err := metrics.NewEthereum(registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	original := newCostTestTransaction(1, 2e9)
+	replacement := newCostTestTransaction(1, 3e9)
+	dropped := newCostTestTransaction(2, 2e9)
+
+	client := &testReceiptClient{
+		receipts: map[common.Hash]*types.Receipt{},
+	}
+
+	costs := newTransactionCosts(client, ethereumMetrics, "0xAAAA")
+	costs.track(submitTicketTransaction, original)
+	costs.track(submitTicketTransaction, replacement)
+	costs.track(submitTicketTransaction, dropped)
+
+	costs.recordMined(context.Background())
+
+	// no transaction has been mined yet
+	err = testutil.GatherAndCompare(
+		registry,
+		strings.NewReader(""),
+		"ethereum_transaction_cost_gwei_total",
+	)
+	if err != nil {
+		t.Errorf("unexpected transaction cost: [%v]", err)
+	}
+
+	client.receipts[replacement.Hash()] = &types.Receipt{GasUsed: 50000}
+
+	costs.recordMined(context.Background())
+	costs.recordMined(context.Background())
+
+	// 50000 gas used at 3 gwei; the cost is recorded only once
+	assertTransactionCost(t, registry, 150000)
+
+	if _, ok := costs.versions[1]; ok {
+		t.Errorf("mined transaction is still tracked")
+	}
+	if _, ok := costs.versions[2]; !ok {
+		t.Errorf("pending transaction is not tracked")
+	}
+}
+
+func newCostTestTransaction(nonce uint64, gasPrice int64) *types.Transaction {
+	return types.NewTransaction(
+		nonce,
+		common.Address{},
+		big.NewInt(0),
+		100000,
+		big.NewInt(gasPrice),
+		nil,
+	)
+}
+
+func assertTransactionCost(
+	t *testing.T,
+	registry *metrics.Registry,
+	expectedCostGwei float64,
+) {
+	t.Helper()
+
+	expected := fmt.Sprintf(
+		"# HELP ethereum_transaction_cost_gwei_total "+
+			"Cost of mined transactions in gwei.\n"+
+			"# TYPE ethereum_transaction_cost_gwei_total counter\n"+
+			"ethereum_transaction_cost_gwei_total"+
+			"{operator=\"0xAAAA\",type=\"submit_ticket\"} %v\n",
+		expectedCostGwei,
+	)
+
+	err := testutil.GatherAndCompare(
+		registry,
+		strings.NewReader(expected),
+		"ethereum_transaction_cost_gwei_total",
+	)
+	if err != nil {
+		t.Errorf("unexpected transaction cost: [%v]", err)
+	}
+}
+
+// testReceiptClient returns receipts of mined transactions.
+type testReceiptClient struct {
+	ethutil.HostChainClient
+
+	receipts map[common.Hash]*types.Receipt
+}
+
+func (trc *testReceiptClient) TransactionReceipt(
+	ctx context.Context,
+	hash common.Hash,
+) (*types.Receipt, error) {
+	receipt, ok := trc.receipts[hash]
+	if !ok {
+		return nil, fmt.Errorf("not found")
+	}
+
+	return receipt, nil
+}
@@ -0,0 +1,154 @@
+package contract
+
+// This file is not generated. Generated contract constructors require the
+// account key to be held by the client process. Constructors defined here
+// accept transactor options instead so that transactions can be signed with
+// a key held outside of the process.
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	hostchainabi "github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+
+	chainutil "github.com/keep-network/keep-common/pkg/chain/ethereum/ethutil"
+	"github.com/keep-network/keep-common/pkg/chain/ethlike"
+	"github.com/keep-network/keep-core/pkg/chain/gen/abi"
+)
+
+// NewKeepRandomBeaconOperatorWithTransactor creates a KeepRandomBeaconOperator
+// contract handle submitting transactions with the provided transactor
+// options.
+func NewKeepRandomBeaconOperatorWithTransactor(
+	contractAddress common.Address,
+	transactorOptions *bind.TransactOpts,
+	backend bind.ContractBackend,
+	nonceManager *ethlike.NonceManager,
+	miningWaiter *ethlike.MiningWaiter,
+	blockCounter *ethlike.BlockCounter,
+	transactionMutex *sync.Mutex,
+) (*KeepRandomBeaconOperator, error) {
+	contract, err := abi.NewKeepRandomBeaconOperator(
+		contractAddress,
+		backend,
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to instantiate contract at address: %s [%v]",
+			contractAddress.String(),
+			err,
+		)
+	}
+
+	contractABI, err := hostchainabi.JSON(strings.NewReader(abi.KeepRandomBeaconOperatorABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to instantiate ABI: [%v]", err)
+	}
+
+	return &KeepRandomBeaconOperator{
+		contract:          contract,
+		contractAddress:   contractAddress,
+		contractABI:       &contractABI,
+		caller:            backend,
+		transactor:        backend,
+		callerOptions:     &bind.CallOpts{From: transactorOptions.From},
+		transactorOptions: transactorOptions,
+		errorResolver:     chainutil.NewErrorResolver(backend, &contractABI, &contractAddress),
+		nonceManager:      nonceManager,
+		miningWaiter:      miningWaiter,
+		blockCounter:      blockCounter,
+		transactionMutex:  transactionMutex,
+	}, nil
+}
+
+// NewKeepRandomBeaconServiceWithTransactor creates a KeepRandomBeaconService
+// contract handle submitting transactions with the provided transactor
+// options.
+func NewKeepRandomBeaconServiceWithTransactor(
+	contractAddress common.Address,
+	transactorOptions *bind.TransactOpts,
+	backend bind.ContractBackend,
+	nonceManager *ethlike.NonceManager,
+	miningWaiter *ethlike.MiningWaiter,
+	blockCounter *ethlike.BlockCounter,
+	transactionMutex *sync.Mutex,
+) (*KeepRandomBeaconService, error) {
+	contract, err := abi.NewKeepRandomBeaconServiceImplV1(
+		contractAddress,
+		backend,
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to instantiate contract at address: %s [%v]",
+			contractAddress.String(),
+			err,
+		)
+	}
+
+	contractABI, err := hostchainabi.JSON(strings.NewReader(abi.KeepRandomBeaconServiceImplV1ABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to instantiate ABI: [%v]", err)
+	}
+
+	return &KeepRandomBeaconService{
+		contract:          contract,
+		contractAddress:   contractAddress,
+		contractABI:       &contractABI,
+		caller:            backend,
+		transactor:        backend,
+		callerOptions:     &bind.CallOpts{From: transactorOptions.From},
+		transactorOptions: transactorOptions,
+		errorResolver:     chainutil.NewErrorResolver(backend, &contractABI, &contractAddress),
+		nonceManager:      nonceManager,
+		miningWaiter:      miningWaiter,
+		blockCounter:      blockCounter,
+		transactionMutex:  transactionMutex,
+	}, nil
+}
+
+// NewTokenStakingWithTransactor creates a TokenStaking contract handle
+// submitting transactions with the provided transactor options.
+func NewTokenStakingWithTransactor(
+	contractAddress common.Address,
+	transactorOptions *bind.TransactOpts,
+	backend bind.ContractBackend,
+	nonceManager *ethlike.NonceManager,
+	miningWaiter *ethlike.MiningWaiter,
+	blockCounter *ethlike.BlockCounter,
+	transactionMutex *sync.Mutex,
+) (*TokenStaking, error) {
+	contract, err := abi.NewTokenStaking(
+		contractAddress,
+		backend,
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to instantiate contract at address: %s [%v]",
+			contractAddress.String(),
+			err,
+		)
+	}
+
+	contractABI, err := hostchainabi.JSON(strings.NewReader(abi.TokenStakingABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to instantiate ABI: [%v]", err)
+	}
+
+	return &TokenStaking{
+		contract:          contract,
+		contractAddress:   contractAddress,
+		contractABI:       &contractABI,
+		caller:            backend,
+		transactor:        backend,
+		callerOptions:     &bind.CallOpts{From: transactorOptions.From},
+		transactorOptions: transactorOptions,
+		errorResolver:     chainutil.NewErrorResolver(backend, &contractABI, &contractAddress),
+		nonceManager:      nonceManager,
+		miningWaiter:      miningWaiter,
+		blockCounter:      blockCounter,
+		transactionMutex:  transactionMutex,
+	}, nil
+}
@@ -2,6 +2,8 @@ package local
 
 import (
 	"context"
+	"fmt"
+	"math/rand"
 	"sync"
 	"time"
 
@@ -11,6 +13,7 @@ import (
 type localBlockCounter struct {
 	structMutex sync.Mutex
 	blockHeight uint64
+	blockHashes map[uint64][]byte
 	waiters     map[uint64][]chan uint64
 	watchers    []*watcher
 }
@@ -86,6 +89,37 @@ func (lbc *localBlockCounter) WatchBlocks(ctx context.Context) <-chan uint64 {
 	return watcher.channel
 }
 
+func (lbc *localBlockCounter) BlockHash(blockNumber uint64) ([]byte, error) {
+	lbc.structMutex.Lock()
+	defer lbc.structMutex.Unlock()
+
+	hash, ok := lbc.blockHashes[blockNumber]
+	if !ok {
+		return nil, fmt.Errorf("block [%v] has not been mined yet", blockNumber)
+	}
+
+	return hash, nil
+}
+
+// reorganize replaces the given number of the most recent blocks with new
+// blocks of the same numbers but different hashes.
+func (lbc *localBlockCounter) reorganize(depth uint64) {
+	lbc.structMutex.Lock()
+	defer lbc.structMutex.Unlock()
+
+	for i := uint64(0); i < depth && i <= lbc.blockHeight; i++ {
+		lbc.blockHashes[lbc.blockHeight-i] = randomBlockHash()
+	}
+}
+
+func randomBlockHash() []byte {
+	hash := make([]byte, 32)
+	// #nosec G404 (insecure random number source (rand))
+	// Local chain implementation doesn't require secure randomness.
+	rand.Read(hash)
+	return hash
+}
+
 // count is an internal function that counts up time to simulate the generation
 // of blocks.
 func (lbc *localBlockCounter) count() {
@@ -95,6 +129,7 @@ func (lbc *localBlockCounter) count() {
 		lbc.structMutex.Lock()
 		lbc.blockHeight++
 		height := lbc.blockHeight
+		lbc.blockHashes[height] = randomBlockHash()
 		waiters, exists := lbc.waiters[height]
 		delete(lbc.waiters, height)
 		lbc.structMutex.Unlock()
@@ -128,9 +163,17 @@ func (lbc *localBlockCounter) count() {
 // designed to simply increase block height at a set time interval in the
 // background.
 func BlockCounter() (chain.BlockCounter, error) {
-	counter := localBlockCounter{blockHeight: 0, waiters: make(map[uint64][]chan uint64)}
+	return newBlockCounter(), nil
+}
+
+func newBlockCounter() *localBlockCounter {
+	counter := &localBlockCounter{
+		blockHeight: 0,
+		blockHashes: map[uint64][]byte{0: randomBlockHash()},
+		waiters:     make(map[uint64][]chan uint64),
+	}
 
 	go counter.count()
 
-	return &counter, nil
+	return counter
 }
@@ -47,6 +47,11 @@ type Chain interface {
 	// GetRelayEntryTimeoutReports returns an array of blocks which denote at what
 	// block a relay entry timeout occured.
 	GetRelayEntryTimeoutReports() []uint64
+
+	// SimulateReorg simulates a chain reorganization replacing the given
+	// number of the most recent blocks with new blocks. Events emitted in the
+	// replaced blocks are not emitted again.
+	SimulateReorg(depth uint64)
 }
 
 type localGroup struct {
@@ -72,7 +77,7 @@ type localChain struct {
 
 	simulatedHeight uint64
 	stakeMonitor    chain.StakeMonitor
-	blockCounter    chain.BlockCounter
+	blockCounter    *localBlockCounter
 
 	tickets      []*relaychain.Ticket
 	ticketsMutex sync.Mutex
@@ -89,6 +94,14 @@ func (c *localChain) BlockCounter() (chain.BlockCounter, error) {
 	return c.blockCounter, nil
 }
 
+func (c *localChain) BlockHashes() (chain.BlockHashes, error) {
+	return c.blockCounter, nil
+}
+
+func (c *localChain) SimulateReorg(depth uint64) {
+	c.blockCounter.reorganize(depth)
+}
+
 func (c *localChain) StakeMonitor() (chain.StakeMonitor, error) {
 	return c.stakeMonitor, nil
 }
@@ -141,6 +154,12 @@ func (c *localChain) GetSubmittedTickets() ([]uint64, error) {
 	return tickets, nil
 }
 
+// TicketSubmissionCost returns zero as tickets are submitted to the local
+// chain for free.
+func (c *localChain) TicketSubmissionCost() (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+
 func (c *localChain) GetSelectedParticipants() ([]relaychain.StakerAddress, error) {
 	c.ticketsMutex.Lock()
 	defer c.ticketsMutex.Unlock()
@@ -306,7 +325,7 @@ func ConnectWithKey(
 	minimumStake *big.Int,
 	operatorKey *ecdsa.PrivateKey,
 ) Chain {
-	bc, _ := BlockCounter()
+	bc := newBlockCounter()
 
 	currentBlock, _ := bc.CurrentBlock()
 	group := localGroup{
@@ -323,6 +342,7 @@ func ConnectWithKey(
 			TicketSubmissionTimeout:    6,
 			ResultPublicationBlockStep: resultPublicationBlockStep,
 			RelayEntryTimeout:          resultPublicationBlockStep * uint64(groupSize),
+			GroupActiveTime:            groupActiveTime,
 		},
 		relayEntryHandlers:       make(map[int]func(request *event.EntrySubmitted)),
 		relayRequestHandlers:     make(map[int]func(request *event.Request)),
@@ -371,6 +391,21 @@ func (c *localChain) IsStaleGroup(groupPublicKey []byte) (bool, error) {
 	return true, nil
 }
 
+func (c *localChain) GetGroupRegistrationBlock(
+	groupPublicKey []byte,
+) (uint64, error) {
+	c.handlerMutex.Lock()
+	defer c.handlerMutex.Unlock()
+
+	for _, group := range c.groups {
+		if bytes.Equal(group.groupPublicKey, groupPublicKey) {
+			return group.registrationBlockHeight, nil
+		}
+	}
+
+	return 0, fmt.Errorf("group not found")
+}
+
 func (c *localChain) GetGroupMembers(groupPublicKey []byte) (
 	[]relaychain.StakerAddress,
 	error,
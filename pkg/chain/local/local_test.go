@@ -393,6 +393,61 @@ func TestWatchBlocks(t *testing.T) {
 	}
 }
 
+func TestSimulateReorg(t *testing.T) {
+	c := Connect(10, 4, big.NewInt(100))
+	blockCounter, err := c.BlockCounter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	blockHashes, err := c.BlockHashes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = blockCounter.WaitForBlockHeight(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Block 1 is the most recent one until the next block is mined.
+	hashBefore, err := blockHashes.BlockHash(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	genesisHashBefore, err := blockHashes.BlockHash(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.SimulateReorg(1)
+
+	hashAfter, err := blockHashes.BlockHash(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	genesisHashAfter, err := blockHashes.BlockHash(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if reflect.DeepEqual(hashBefore, hashAfter) {
+		t.Errorf("hash of the replaced block has not changed")
+	}
+	if !reflect.DeepEqual(genesisHashBefore, genesisHashAfter) {
+		t.Errorf("hash of the block before the reorganization has changed")
+	}
+
+	_, err = blockHashes.BlockHash(100)
+	expectedError := fmt.Errorf("block [100] has not been mined yet")
+	if !reflect.DeepEqual(expectedError, err) {
+		t.Errorf(
+			"unexpected error\nexpected: [%v]\nactual:   [%v]",
+			expectedError,
+			err,
+		)
+	}
+}
+
 func TestWatchBlocksNonBlocking(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 1100*time.Millisecond)
 	defer cancel()
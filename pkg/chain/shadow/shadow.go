@@ -0,0 +1,148 @@
+// Package shadow provides a chain handle decorator running the client in the
+// shadow mode. In the shadow mode, the client follows the chain and takes
+// part in all protocols exactly as it would normally do, but it never submits
+// any transaction. Instead, transactions the client would submit are logged
+// and recorded in the journal.
+package shadow
+
+import (
+	"encoding/hex"
+	"errors"
+
+	"github.com/ipfs/go-log"
+
+	relaychain "github.com/keep-network/keep-core/pkg/beacon/relay/chain"
+	"github.com/keep-network/keep-core/pkg/chain"
+	"github.com/keep-network/keep-core/pkg/gen/async"
+	"github.com/keep-network/keep-core/pkg/journal"
+)
+
+var logger = log.Logger("keep-shadow")
+
+// ErrNotSubmitted is returned in place of the result of every transaction
+// the client would submit if it was not running in the shadow mode.
+var ErrNotSubmitted = errors.New("transaction not submitted in shadow mode")
+
+// Kinds of transactions not submitted in the shadow mode.
+const (
+	submitTicketTransaction            = "submit_ticket"
+	submitDKGResultTransaction         = "submit_dkg_result"
+	submitRelayEntryTransaction        = "submit_relay_entry"
+	reportRelayEntryTimeoutTransaction = "report_relay_entry_timeout"
+)
+
+type shadowHandle struct {
+	chain.Handle
+
+	journal *journal.Journal
+}
+
+// Wrap decorates the given chain handle so that the relay chain it provides
+// never submits transactions. All other chain interactions are delegated to
+// the given handle. Transactions not submitted are recorded in the given
+// journal.
+func Wrap(handle chain.Handle, journal *journal.Journal) chain.Handle {
+	return &shadowHandle{handle, journal}
+}
+
+func (sh *shadowHandle) ThresholdRelay() relaychain.Interface {
+	return &shadowRelayChain{sh.Handle.ThresholdRelay(), sh.journal}
+}
+
+type shadowRelayChain struct {
+	relaychain.Interface
+
+	journal *journal.Journal
+}
+
+func (src *shadowRelayChain) SubmitTicket(
+	ticket *relaychain.Ticket,
+) *async.EventGroupTicketSubmissionPromise {
+	details := map[string]interface{}{
+		"value": hex.EncodeToString(ticket.Value[:]),
+	}
+	if ticket.Proof != nil {
+		details["virtual_staker_index"] = ticket.Proof.VirtualStakerIndex
+	}
+
+	src.record(submitTicketTransaction, "", details)
+
+	promise := &async.EventGroupTicketSubmissionPromise{}
+	failPromise(promise.Fail(ErrNotSubmitted))
+	return promise
+}
+
+func (src *shadowRelayChain) SubmitDKGResult(
+	participantIndex relaychain.GroupMemberIndex,
+	dkgResult *relaychain.DKGResult,
+	signatures map[relaychain.GroupMemberIndex][]byte,
+) *async.EventDKGResultSubmissionPromise {
+	misbehaved := make([]int, len(dkgResult.Misbehaved))
+	for i, memberIndex := range dkgResult.Misbehaved {
+		misbehaved[i] = int(memberIndex)
+	}
+
+	src.record(
+		submitDKGResultTransaction,
+		hex.EncodeToString(dkgResult.GroupPublicKey),
+		map[string]interface{}{
+			"submitter":  participantIndex,
+			"misbehaved": misbehaved,
+			"signatures": len(signatures),
+		},
+	)
+
+	promise := &async.EventDKGResultSubmissionPromise{}
+	failPromise(promise.Fail(ErrNotSubmitted))
+	return promise
+}
+
+func (src *shadowRelayChain) SubmitRelayEntry(
+	entry []byte,
+) *async.EventEntrySubmittedPromise {
+	src.record(
+		submitRelayEntryTransaction,
+		"",
+		map[string]interface{}{
+			"entry": hex.EncodeToString(entry),
+		},
+	)
+
+	promise := &async.EventEntrySubmittedPromise{}
+	failPromise(promise.Fail(ErrNotSubmitted))
+	return promise
+}
+
+func (src *shadowRelayChain) ReportRelayEntryTimeout() error {
+	src.record(reportRelayEntryTimeoutTransaction, "", nil)
+	return ErrNotSubmitted
+}
+
+func (src *shadowRelayChain) record(
+	transaction string,
+	groupPublicKey string,
+	details map[string]interface{},
+) {
+	logger.Infof(
+		"shadow mode; not submitting [%v] transaction with details [%v]",
+		transaction,
+		details,
+	)
+
+	if details == nil {
+		details = make(map[string]interface{})
+	}
+	details["transaction"] = transaction
+
+	src.journal.Record(&journal.Entry{
+		Type:           journal.ShadowTransaction,
+		GroupPublicKey: groupPublicKey,
+		Details:        details,
+	})
+}
+
+func failPromise(err error) {
+	if err != nil {
+		logger.Errorf("could not fail the transaction promise: [%v]", err)
+	}
+}
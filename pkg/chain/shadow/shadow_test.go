@@ -0,0 +1,130 @@
+package shadow
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	relaychain "github.com/keep-network/keep-core/pkg/beacon/relay/chain"
+	"github.com/keep-network/keep-core/pkg/beacon/relay/event"
+	"github.com/keep-network/keep-core/pkg/chain/local"
+	"github.com/keep-network/keep-core/pkg/journal"
+)
+
+func TestTransactionsNotSubmitted(t *testing.T) {
+	dataDir, err := ioutil.TempDir("", "shadow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dataDir)
+
+	eventJournal, err := journal.Open(dataDir, journal.Config{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	localChain := local.Connect(5, 3, big.NewInt(200))
+	relayChain := Wrap(localChain, eventJournal).ThresholdRelay()
+
+	submittedEntries := make(chan *event.EntrySubmitted, 1)
+	subscription := localChain.ThresholdRelay().OnRelayEntrySubmitted(
+		func(entry *event.EntrySubmitted) {
+			submittedEntries <- entry
+		},
+	)
+	defer subscription.Unsubscribe()
+
+	entryErrors := make(chan error, 1)
+	relayChain.SubmitRelayEntry(big.NewInt(100).Bytes()).OnComplete(
+		func(entry *event.EntrySubmitted, err error) {
+			entryErrors <- err
+		},
+	)
+
+	ticketErrors := make(chan error, 1)
+	relayChain.SubmitTicket(&relaychain.Ticket{
+		Value: [8]byte{0x01},
+		Proof: &relaychain.TicketProof{
+			StakerValue:        big.NewInt(1),
+			VirtualStakerIndex: big.NewInt(2),
+		},
+	}).OnFailure(func(err error) {
+		ticketErrors <- err
+	})
+
+	dkgResultErrors := make(chan error, 1)
+	relayChain.SubmitDKGResult(
+		1,
+		&relaychain.DKGResult{
+			GroupPublicKey: []byte{0x0a},
+			Misbehaved:     []byte{0x02},
+		},
+		map[relaychain.GroupMemberIndex][]byte{1: []byte{0x0b}},
+	).OnFailure(func(err error) {
+		dkgResultErrors <- err
+	})
+
+	for name, errors := range map[string]chan error{
+		"relay entry": entryErrors,
+		"ticket":      ticketErrors,
+		"DKG result":  dkgResultErrors,
+	} {
+		select {
+		case err := <-errors:
+			if err != ErrNotSubmitted {
+				t.Errorf(
+					"unexpected %v submission error\nexpected: [%v]\nactual:   [%v]",
+					name,
+					ErrNotSubmitted,
+					err,
+				)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%v submission promise not completed", name)
+		}
+	}
+
+	if err := relayChain.ReportRelayEntryTimeout(); err != ErrNotSubmitted {
+		t.Errorf(
+			"unexpected relay entry timeout error\nexpected: [%v]\nactual:   [%v]",
+			ErrNotSubmitted,
+			err,
+		)
+	}
+
+	select {
+	case entry := <-submittedEntries:
+		t.Errorf("unexpected relay entry submitted: [%v]", entry)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if err := eventJournal.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := journal.Read(dataDir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transactions := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.Type != journal.ShadowTransaction {
+			t.Errorf("unexpected journal entry type [%v]", entry.Type)
+		}
+		transactions[entry.Details["transaction"].(string)] = true
+	}
+
+	for _, transaction := range []string{
+		submitTicketTransaction,
+		submitDKGResultTransaction,
+		submitRelayEntryTransaction,
+		reportRelayEntryTimeoutTransaction,
+	} {
+		if !transactions[transaction] {
+			t.Errorf("transaction [%v] not recorded in the journal", transaction)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package standin
+
+import (
+	"context"
+	"sync"
+)
+
+// blockCounter tracks blocks of the stand-in as observed by the client. Block
+// heights are pushed by the client polling the stand-in.
+type blockCounter struct {
+	mutex       sync.Mutex
+	blockHeight uint64
+	waiters     map[uint64][]chan uint64
+	watchers    []*watcher
+}
+
+type watcher struct {
+	ctx     context.Context
+	channel chan uint64
+}
+
+func newBlockCounter(blockHeight uint64) *blockCounter {
+	return &blockCounter{
+		blockHeight: blockHeight,
+		waiters:     make(map[uint64][]chan uint64),
+	}
+}
+
+func (bc *blockCounter) WaitForBlockHeight(blockNumber uint64) error {
+	waiter, err := bc.BlockHeightWaiter(blockNumber)
+	if err != nil {
+		return err
+	}
+	<-waiter
+	return nil
+}
+
+func (bc *blockCounter) BlockHeightWaiter(
+	blockNumber uint64,
+) (<-chan uint64, error) {
+	newWaiter := make(chan uint64)
+
+	bc.mutex.Lock()
+	defer bc.mutex.Unlock()
+
+	if blockNumber <= bc.blockHeight {
+		go func() { newWaiter <- blockNumber }()
+	} else {
+		bc.waiters[blockNumber] = append(bc.waiters[blockNumber], newWaiter)
+	}
+
+	return newWaiter, nil
+}
+
+func (bc *blockCounter) CurrentBlock() (uint64, error) {
+	bc.mutex.Lock()
+	defer bc.mutex.Unlock()
+
+	return bc.blockHeight, nil
+}
+
+func (bc *blockCounter) WatchBlocks(ctx context.Context) <-chan uint64 {
+	watcher := &watcher{
+		ctx:     ctx,
+		channel: make(chan uint64, 1),
+	}
+
+	bc.mutex.Lock()
+	bc.watchers = append(bc.watchers, watcher)
+	bc.mutex.Unlock()
+
+	return watcher.channel
+}
+
+// update advances the block counter to the given block height, releasing
+// waiters and notifying watchers of every block mined since the last update.
+func (bc *blockCounter) update(blockHeight uint64) {
+	bc.mutex.Lock()
+	previousHeight := bc.blockHeight
+	if blockHeight <= previousHeight {
+		bc.mutex.Unlock()
+		return
+	}
+	bc.blockHeight = blockHeight
+	bc.mutex.Unlock()
+
+	for height := previousHeight + 1; height <= blockHeight; height++ {
+		bc.mutex.Lock()
+		waiters := bc.waiters[height]
+		delete(bc.waiters, height)
+
+		activeWatchers := bc.watchers[:0]
+		for _, watcher := range bc.watchers {
+			if watcher.ctx.Err() != nil {
+				close(watcher.channel)
+				continue
+			}
+			activeWatchers = append(activeWatchers, watcher)
+		}
+		bc.watchers = activeWatchers
+
+		watchers := make([]*watcher, len(activeWatchers))
+		copy(watchers, activeWatchers)
+		bc.mutex.Unlock()
+
+		for _, waiter := range waiters {
+			go func(waiter chan uint64, height uint64) {
+				waiter <- height
+			}(waiter, height)
+		}
+
+		for _, watcher := range watchers {
+			select {
+			case watcher.channel <- height:
+			default: // the watcher is too slow; the block is dropped
+			}
+		}
+	}
+}
@@ -0,0 +1,598 @@
+package standin
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	relaychain "github.com/keep-network/keep-core/pkg/beacon/relay/chain"
+	"github.com/keep-network/keep-core/pkg/beacon/relay/event"
+	"github.com/keep-network/keep-core/pkg/chain"
+	"github.com/keep-network/keep-core/pkg/chain/ethereum/signer"
+	"github.com/keep-network/keep-core/pkg/gen/async"
+	"github.com/keep-network/keep-core/pkg/operator"
+	"github.com/keep-network/keep-core/pkg/subscription"
+)
+
+// pollInterval is the interval at which clients poll the stand-in for new
+// blocks and events.
+const pollInterval = 100 * time.Millisecond
+
+type client struct {
+	rpcClient    *rpc.Client
+	signer       signer.Signer
+	relayConfig  *relaychain.Config
+	blockCounter *blockCounter
+
+	handlersMutex sync.Mutex
+	handlers      map[int]*eventHandler
+	nextHandlerID int
+}
+
+type eventHandler struct {
+	eventType string
+	handle    func(record *eventRecord)
+}
+
+// Connect connects to the chain stand-in served at the given URL on behalf of
+// the operator using the provided signer. Blocks and events of the stand-in
+// are polled until the provided context is done. Handlers registered for
+// stand-in events are notified only about events emitted after the
+// connection has been established.
+func Connect(
+	ctx context.Context,
+	url string,
+	operatorSigner signer.Signer,
+) (chain.Utility, error) {
+	rpcClient, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to the stand-in: [%v]", err)
+	}
+
+	c := &client{
+		rpcClient: rpcClient,
+		signer:    operatorSigner,
+		handlers:  make(map[int]*eventHandler),
+	}
+
+	relayConfig := &relaychain.Config{}
+	if err := c.call(relayConfig, "relayConfig"); err != nil {
+		rpcClient.Close()
+		return nil, fmt.Errorf("could not get relay config: [%v]", err)
+	}
+	c.relayConfig = relayConfig
+
+	var blockHeight uint64
+	if err := c.call(&blockHeight, "currentBlock"); err != nil {
+		rpcClient.Close()
+		return nil, fmt.Errorf("could not get current block: [%v]", err)
+	}
+	c.blockCounter = newBlockCounter(blockHeight)
+
+	// Requesting events past the end of the event log returns no events but
+	// the index of the next event emitted.
+	page := &eventsPage{}
+	if err := c.call(page, "events", uint64(math.MaxUint64)); err != nil {
+		rpcClient.Close()
+		return nil, fmt.Errorf("could not get events: [%v]", err)
+	}
+
+	go c.poll(ctx, page.Next)
+
+	return c, nil
+}
+
+func (c *client) call(result interface{}, method string, args ...interface{}) error {
+	return c.rpcClient.Call(result, serviceName+"_"+method, args...)
+}
+
+// poll polls the stand-in for new events and blocks and dispatches them to
+// registered handlers and the block counter. Events are polled before blocks
+// so that the block counter has reached the block of an event by the time the
+// event is dispatched.
+func (c *client) poll(ctx context.Context, nextEvent uint64) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	defer c.rpcClient.Close()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+
+		page := &eventsPage{}
+		if err := c.call(page, "events", nextEvent); err != nil {
+			logger.Warningf("could not poll stand-in events: [%v]", err)
+			continue
+		}
+
+		var blockHeight uint64
+		if err := c.call(&blockHeight, "currentBlock"); err != nil {
+			logger.Warningf("could not poll stand-in blocks: [%v]", err)
+			continue
+		}
+
+		c.blockCounter.update(blockHeight)
+
+		for _, record := range page.Events {
+			c.dispatch(record)
+		}
+		nextEvent = page.Next
+	}
+}
+
+func (c *client) dispatch(record *eventRecord) {
+	c.handlersMutex.Lock()
+	defer c.handlersMutex.Unlock()
+
+	for _, handler := range c.handlers {
+		if handler.eventType == record.Type {
+			go handler.handle(record)
+		}
+	}
+}
+
+func (c *client) subscribe(
+	eventType string,
+	handle func(record *eventRecord),
+) subscription.EventSubscription {
+	c.handlersMutex.Lock()
+	defer c.handlersMutex.Unlock()
+
+	handlerID := c.nextHandlerID
+	c.nextHandlerID++
+	c.handlers[handlerID] = &eventHandler{eventType, handle}
+
+	return subscription.NewEventSubscription(func() {
+		c.handlersMutex.Lock()
+		defer c.handlersMutex.Unlock()
+
+		delete(c.handlers, handlerID)
+	})
+}
+
+func (c *client) BlockCounter() (chain.BlockCounter, error) {
+	return c.blockCounter, nil
+}
+
+func (c *client) BlockHashes() (chain.BlockHashes, error) {
+	return c, nil
+}
+
+func (c *client) BlockHash(blockNumber uint64) ([]byte, error) {
+	var hash hexutil.Bytes
+	if err := c.call(&hash, "blockHash", blockNumber); err != nil {
+		return nil, err
+	}
+
+	return hash, nil
+}
+
+func (c *client) StakeMonitor() (chain.StakeMonitor, error) {
+	return &stakeMonitor{c}, nil
+}
+
+func (c *client) ThresholdRelay() relaychain.Interface {
+	return c
+}
+
+func (c *client) Signing() chain.Signing {
+	return c.signer
+}
+
+// GetKeys returns the operator key pair. If the operator key is held by
+// an external signing service, only the public key is returned.
+func (c *client) GetKeys() (*operator.PrivateKey, *operator.PublicKey) {
+	if localSigner, ok := c.signer.(*signer.Local); ok {
+		return operator.ChainKeyToOperatorKey(localSigner.Key())
+	}
+
+	publicKey, err := operator.Unmarshal(c.signer.PublicKey())
+	if err != nil {
+		logger.Errorf("could not unmarshal operator public key: [%v]", err)
+		return nil, nil
+	}
+
+	return nil, publicKey
+}
+
+func (c *client) GetConfig() *relaychain.Config {
+	return c.relayConfig
+}
+
+func (c *client) MinimumStake() (*big.Int, error) {
+	var minimumStake hexutil.Big
+	if err := c.call(&minimumStake, "minimumStake"); err != nil {
+		return nil, err
+	}
+
+	return minimumStake.ToInt(), nil
+}
+
+func (c *client) Genesis() error {
+	return c.call(nil, "genesis")
+}
+
+// RequestRelayEntry requests a new relay entry and returns a promise
+// fulfilled with the first relay entry submitted after the request.
+func (c *client) RequestRelayEntry() *async.EventEntryGeneratedPromise {
+	promise := &async.EventEntryGeneratedPromise{}
+
+	var entrySubscription subscription.EventSubscription
+	entrySubscription = c.subscribe(
+		relayEntrySubmittedEvent,
+		func(record *eventRecord) {
+			entrySubscription.Unsubscribe()
+
+			// The promise may have been fulfilled already if more entries
+			// have been submitted in the meantime.
+			_ = promise.Fulfill(&event.EntryGenerated{
+				Value:       record.Seed.ToInt(),
+				BlockNumber: record.BlockNumber,
+			})
+		},
+	)
+
+	var requestBlock uint64
+	if err := c.call(&requestBlock, "requestRelayEntry"); err != nil {
+		entrySubscription.Unsubscribe()
+		if failErr := promise.Fail(err); failErr != nil {
+			logger.Errorf("failed to fail promise: [%v]", failErr)
+		}
+		return promise
+	}
+
+	logger.Infof("relay entry requested at block [%v]", requestBlock)
+
+	return promise
+}
+
+func (c *client) OnGroupSelectionStarted(
+	handler func(groupSelectionStart *event.GroupSelectionStart),
+) subscription.EventSubscription {
+	return c.subscribe(
+		groupSelectionStartedEvent,
+		func(record *eventRecord) {
+			handler(&event.GroupSelectionStart{
+				NewEntry:    record.Seed.ToInt(),
+				BlockNumber: record.BlockNumber,
+			})
+		},
+	)
+}
+
+func (c *client) SubmitTicket(
+	ticket *relaychain.Ticket,
+) *async.EventGroupTicketSubmissionPromise {
+	promise := &async.EventGroupTicketSubmissionPromise{}
+
+	var blockNumber uint64
+	err := c.call(&blockNumber, "submitTicket", &ticketArgs{
+		Value:              ticket.Value[:],
+		StakerValue:        bigToHex(ticket.Proof.StakerValue),
+		VirtualStakerIndex: bigToHex(ticket.Proof.VirtualStakerIndex),
+	})
+	if err != nil {
+		if failErr := promise.Fail(err); failErr != nil {
+			logger.Errorf("failed to fail promise: [%v]", failErr)
+		}
+		return promise
+	}
+
+	err = promise.Fulfill(&event.GroupTicketSubmission{
+		TicketValue: new(big.Int).SetBytes(ticket.Value[:]),
+		BlockNumber: blockNumber,
+	})
+	if err != nil {
+		logger.Errorf("failed to fulfill promise: [%v]", err)
+	}
+
+	return promise
+}
+
+func (c *client) GetSubmittedTickets() ([]uint64, error) {
+	var tickets []uint64
+	if err := c.call(&tickets, "submittedTickets"); err != nil {
+		return nil, err
+	}
+
+	return tickets, nil
+}
+
+// TicketSubmissionCost returns zero as tickets are submitted to the stand-in
+// for free.
+func (c *client) TicketSubmissionCost() (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+
+func (c *client) GetSelectedParticipants() ([]relaychain.StakerAddress, error) {
+	var participants []common.Address
+	if err := c.call(&participants, "selectedParticipants"); err != nil {
+		return nil, err
+	}
+
+	return toStakerAddresses(participants), nil
+}
+
+func toStakerAddresses(addresses []common.Address) []relaychain.StakerAddress {
+	stakerAddresses := make([]relaychain.StakerAddress, len(addresses))
+	for i, address := range addresses {
+		stakerAddresses[i] = address.Bytes()
+	}
+
+	return stakerAddresses
+}
+
+func (c *client) OnGroupRegistered(
+	handler func(groupRegistration *event.GroupRegistration),
+) subscription.EventSubscription {
+	return c.subscribe(
+		dkgResultSubmittedEvent,
+		func(record *eventRecord) {
+			handler(&event.GroupRegistration{
+				GroupPublicKey: record.GroupPublicKey,
+				BlockNumber:    record.BlockNumber,
+			})
+		},
+	)
+}
+
+func (c *client) IsStaleGroup(groupPublicKey []byte) (bool, error) {
+	var isStale bool
+	err := c.call(&isStale, "isStaleGroup", hexutil.Bytes(groupPublicKey))
+	return isStale, err
+}
+
+func (c *client) GetGroupRegistrationBlock(
+	groupPublicKey []byte,
+) (uint64, error) {
+	var registrationBlock uint64
+	err := c.call(
+		&registrationBlock,
+		"groupRegistrationBlock",
+		hexutil.Bytes(groupPublicKey),
+	)
+	return registrationBlock, err
+}
+
+func (c *client) GetGroupMembers(
+	groupPublicKey []byte,
+) ([]relaychain.StakerAddress, error) {
+	var members []common.Address
+	err := c.call(&members, "groupMembers", hexutil.Bytes(groupPublicKey))
+	if err != nil {
+		return nil, err
+	}
+
+	return toStakerAddresses(members), nil
+}
+
+func (c *client) SubmitRelayEntry(entry []byte) *async.EventEntrySubmittedPromise {
+	promise := &async.EventEntrySubmittedPromise{}
+
+	var blockNumber uint64
+	err := c.call(&blockNumber, "submitRelayEntry", hexutil.Bytes(entry))
+	if err != nil {
+		if failErr := promise.Fail(err); failErr != nil {
+			logger.Errorf("failed to fail promise: [%v]", failErr)
+		}
+		return promise
+	}
+
+	err = promise.Fulfill(&event.EntrySubmitted{BlockNumber: blockNumber})
+	if err != nil {
+		logger.Errorf("failed to fulfill promise: [%v]", err)
+	}
+
+	return promise
+}
+
+func (c *client) OnRelayEntrySubmitted(
+	handler func(entry *event.EntrySubmitted),
+) subscription.EventSubscription {
+	return c.subscribe(
+		relayEntrySubmittedEvent,
+		func(record *eventRecord) {
+			handler(&event.EntrySubmitted{BlockNumber: record.BlockNumber})
+		},
+	)
+}
+
+func (c *client) OnRelayEntryRequested(
+	handler func(request *event.Request),
+) subscription.EventSubscription {
+	return c.subscribe(
+		relayEntryRequestedEvent,
+		func(record *eventRecord) {
+			handler(&event.Request{
+				PreviousEntry:  record.PreviousEntry,
+				GroupPublicKey: record.GroupPublicKey,
+				BlockNumber:    record.BlockNumber,
+			})
+		},
+	)
+}
+
+func (c *client) ReportRelayEntryTimeout() error {
+	return c.call(nil, "reportRelayEntryTimeout")
+}
+
+func (c *client) currentRequest() (*requestState, error) {
+	request := &requestState{}
+	if err := c.call(request, "currentRequest"); err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+func (c *client) IsEntryInProgress() (bool, error) {
+	request, err := c.currentRequest()
+	if err != nil {
+		return false, err
+	}
+
+	return request.InProgress, nil
+}
+
+func (c *client) CurrentRequestStartBlock() (*big.Int, error) {
+	request, err := c.currentRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetUint64(request.StartBlock), nil
+}
+
+func (c *client) CurrentRequestPreviousEntry() ([]byte, error) {
+	request, err := c.currentRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	return request.PreviousEntry, nil
+}
+
+func (c *client) CurrentRequestGroupPublicKey() ([]byte, error) {
+	request, err := c.currentRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	return request.GroupPublicKey, nil
+}
+
+func (c *client) SubmitDKGResult(
+	participantIndex relaychain.GroupMemberIndex,
+	dkgResult *relaychain.DKGResult,
+	signatures map[relaychain.GroupMemberIndex][]byte,
+) *async.EventDKGResultSubmissionPromise {
+	promise := &async.EventDKGResultSubmissionPromise{}
+
+	signaturesHex := make(map[uint8]hexutil.Bytes, len(signatures))
+	for memberIndex, signature := range signatures {
+		signaturesHex[memberIndex] = signature
+	}
+
+	var blockNumber uint64
+	err := c.call(
+		&blockNumber,
+		"submitDKGResult",
+		participantIndex,
+		&dkgResultArgs{
+			GroupPublicKey: dkgResult.GroupPublicKey,
+			Misbehaved:     dkgResult.Misbehaved,
+		},
+		signaturesHex,
+	)
+	if err != nil {
+		if failErr := promise.Fail(err); failErr != nil {
+			logger.Errorf("failed to fail promise: [%v]", failErr)
+		}
+		return promise
+	}
+
+	err = promise.Fulfill(&event.DKGResultSubmission{
+		MemberIndex:    uint32(participantIndex),
+		GroupPublicKey: dkgResult.GroupPublicKey,
+		Misbehaved:     dkgResult.Misbehaved,
+		BlockNumber:    blockNumber,
+	})
+	if err != nil {
+		logger.Errorf("failed to fulfill promise: [%v]", err)
+	}
+
+	return promise
+}
+
+func (c *client) OnDKGResultSubmitted(
+	handler func(event *event.DKGResultSubmission),
+) subscription.EventSubscription {
+	return c.subscribe(
+		dkgResultSubmittedEvent,
+		func(record *eventRecord) {
+			handler(&event.DKGResultSubmission{
+				MemberIndex:    record.MemberIndex,
+				GroupPublicKey: record.GroupPublicKey,
+				Misbehaved:     record.Misbehaved,
+				BlockNumber:    record.BlockNumber,
+			})
+		},
+	)
+}
+
+func (c *client) IsGroupRegistered(groupPublicKey []byte) (bool, error) {
+	var isRegistered bool
+	err := c.call(&isRegistered, "isGroupRegistered", hexutil.Bytes(groupPublicKey))
+	return isRegistered, err
+}
+
+// CalculateDKGResultHash calculates the hash of the DKG result the same way
+// as the stand-in does.
+func (c *client) CalculateDKGResultHash(
+	dkgResult *relaychain.DKGResult,
+) (relaychain.DKGResultHash, error) {
+	return relaychain.DKGResultHashFromBytes(
+		dkgResultHash(dkgResult.GroupPublicKey, dkgResult.Misbehaved),
+	)
+}
+
+type stakeMonitor struct {
+	client *client
+}
+
+func (sm *stakeMonitor) HasMinimumStake(address string) (bool, error) {
+	staker, err := sm.StakerFor(address)
+	if err != nil {
+		return false, err
+	}
+
+	stake, err := staker.Stake()
+	if err != nil {
+		return false, err
+	}
+
+	minimumStake, err := sm.client.MinimumStake()
+	if err != nil {
+		return false, err
+	}
+
+	return stake.Cmp(minimumStake) >= 0, nil
+}
+
+func (sm *stakeMonitor) StakerFor(address string) (chain.Staker, error) {
+	if !common.IsHexAddress(address) {
+		return nil, fmt.Errorf("not a valid ethereum address: %v", address)
+	}
+
+	return &staker{
+		address: common.HexToAddress(address),
+		client:  sm.client,
+	}, nil
+}
+
+type staker struct {
+	address common.Address
+	client  *client
+}
+
+func (s *staker) Address() relaychain.StakerAddress {
+	return s.address.Bytes()
+}
+
+func (s *staker) Stake() (*big.Int, error) {
+	var stake hexutil.Big
+	if err := s.client.call(&stake, "stakeOf", s.address); err != nil {
+		return nil, err
+	}
+
+	return stake.ToInt(), nil
+}
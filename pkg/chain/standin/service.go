@@ -0,0 +1,179 @@
+package standin
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	relaychain "github.com/keep-network/keep-core/pkg/beacon/relay/chain"
+)
+
+// Types of events emitted by the stand-in.
+const (
+	groupSelectionStartedEvent = "groupSelectionStarted"
+	dkgResultSubmittedEvent    = "dkgResultSubmitted"
+	relayEntryRequestedEvent   = "relayEntryRequested"
+	relayEntrySubmittedEvent   = "relayEntrySubmitted"
+)
+
+// eventRecord is an event emitted by the stand-in. Fields which are set depend
+// on the type of the event. The seed is the group selection seed for group
+// selection start events and the entry value for relay entry submission
+// events.
+type eventRecord struct {
+	Type           string        `json:"type"`
+	BlockNumber    uint64        `json:"blockNumber"`
+	Seed           *hexutil.Big  `json:"seed,omitempty"`
+	MemberIndex    uint32        `json:"memberIndex,omitempty"`
+	GroupPublicKey hexutil.Bytes `json:"groupPublicKey,omitempty"`
+	Misbehaved     hexutil.Bytes `json:"misbehaved,omitempty"`
+	PreviousEntry  hexutil.Bytes `json:"previousEntry,omitempty"`
+}
+
+// eventsPage is a list of events along with the index of the next event to be
+// emitted, from which the next page should be requested.
+type eventsPage struct {
+	Events []*eventRecord `json:"events"`
+	Next   uint64         `json:"next"`
+}
+
+type ticketArgs struct {
+	Value              hexutil.Bytes `json:"value"`
+	StakerValue        *hexutil.Big  `json:"stakerValue"`
+	VirtualStakerIndex *hexutil.Big  `json:"virtualStakerIndex"`
+}
+
+type dkgResultArgs struct {
+	GroupPublicKey hexutil.Bytes `json:"groupPublicKey"`
+	Misbehaved     hexutil.Bytes `json:"misbehaved"`
+}
+
+// requestState describes the relay request in progress. The start block is
+// zero if there is no request in progress.
+type requestState struct {
+	InProgress     bool          `json:"inProgress"`
+	StartBlock     uint64        `json:"startBlock"`
+	PreviousEntry  hexutil.Bytes `json:"previousEntry"`
+	GroupPublicKey hexutil.Bytes `json:"groupPublicKey"`
+}
+
+func bigToHex(value *big.Int) *hexutil.Big {
+	return (*hexutil.Big)(value)
+}
+
+// service exposes the stand-in to clients over JSON-RPC.
+type service struct {
+	chain *standInChain
+}
+
+func (s *service) RelayConfig() relaychain.Config {
+	return s.chain.config.Relay
+}
+
+func (s *service) MinimumStake() *hexutil.Big {
+	return bigToHex(s.chain.config.MinimumStake)
+}
+
+func (s *service) CurrentBlock() uint64 {
+	return s.chain.currentBlock()
+}
+
+func (s *service) BlockHash(blockNumber uint64) (hexutil.Bytes, error) {
+	return s.chain.blockHash(blockNumber)
+}
+
+func (s *service) StakeOf(address common.Address) *hexutil.Big {
+	return bigToHex(s.chain.stakeOf(address))
+}
+
+func (s *service) Events(from uint64) *eventsPage {
+	events, next := s.chain.eventsFrom(from)
+	return &eventsPage{Events: events, Next: next}
+}
+
+func (s *service) Genesis() error {
+	return s.chain.genesis()
+}
+
+func (s *service) SubmitTicket(args ticketArgs) (uint64, error) {
+	var value [8]byte
+	copy(value[:], args.Value)
+
+	return s.chain.submitTicket(
+		value,
+		(*big.Int)(args.StakerValue),
+		(*big.Int)(args.VirtualStakerIndex),
+	)
+}
+
+func (s *service) SubmittedTickets() []uint64 {
+	return s.chain.submittedTickets()
+}
+
+func (s *service) SelectedParticipants() ([]common.Address, error) {
+	return s.chain.selectedParticipants()
+}
+
+func (s *service) SubmitDKGResult(
+	submitterIndex uint8,
+	result dkgResultArgs,
+	signatures map[uint8]hexutil.Bytes,
+) (uint64, error) {
+	signaturesBytes := make(map[uint8][]byte, len(signatures))
+	for memberIndex, signature := range signatures {
+		signaturesBytes[memberIndex] = signature
+	}
+
+	return s.chain.submitDKGResult(
+		submitterIndex,
+		result.GroupPublicKey,
+		result.Misbehaved,
+		signaturesBytes,
+	)
+}
+
+func (s *service) IsGroupRegistered(groupPublicKey hexutil.Bytes) bool {
+	return s.chain.isGroupRegistered(groupPublicKey)
+}
+
+func (s *service) IsStaleGroup(groupPublicKey hexutil.Bytes) bool {
+	return s.chain.isStaleGroup(groupPublicKey)
+}
+
+func (s *service) GroupRegistrationBlock(
+	groupPublicKey hexutil.Bytes,
+) (uint64, error) {
+	return s.chain.groupRegistrationBlock(groupPublicKey)
+}
+
+func (s *service) GroupMembers(
+	groupPublicKey hexutil.Bytes,
+) ([]common.Address, error) {
+	return s.chain.groupMembers(groupPublicKey)
+}
+
+func (s *service) RequestRelayEntry() (uint64, error) {
+	return s.chain.requestRelayEntry()
+}
+
+func (s *service) CurrentRequest() *requestState {
+	request := s.chain.currentRequest()
+	if request == nil {
+		return &requestState{}
+	}
+
+	return &requestState{
+		InProgress:     true,
+		StartBlock:     request.startBlock,
+		PreviousEntry:  request.previousEntry,
+		GroupPublicKey: request.groupPublicKey,
+	}
+}
+
+func (s *service) SubmitRelayEntry(entry hexutil.Bytes) (uint64, error) {
+	return s.chain.submitRelayEntry(entry)
+}
+
+func (s *service) ReportRelayEntryTimeout() error {
+	return s.chain.reportRelayEntryTimeout()
+}
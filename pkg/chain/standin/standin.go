@@ -0,0 +1,923 @@
+// Package standin provides a chain stand-in simulating the random beacon
+// operator contract in a single process and exposing it over JSON-RPC so that
+// multiple clients running in separate processes can share it. The stand-in
+// mines blocks at a fixed interval, keeps stakes of operators, runs the
+// ticket-based group selection, registers groups based on DKG results
+// supported by signatures of group members, and serves relay entry requests
+// verifying submitted entries against the group public key. It is meant to be
+// used in tests and development environments only.
+package standin
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ipfs/go-log"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/keep-network/keep-core/pkg/altbn128"
+	relaychain "github.com/keep-network/keep-core/pkg/beacon/relay/chain"
+	"github.com/keep-network/keep-core/pkg/bls"
+	"github.com/keep-network/keep-core/pkg/internal/byteutils"
+)
+
+var logger = log.Logger("keep-chain-standin")
+
+// serviceName is the namespace of JSON-RPC methods exposed by the stand-in.
+const serviceName = "standin"
+
+// genesisEntry is the relay entry used as the previous entry of the first
+// relay request. Its value is the seed of the genesis group selection.
+var genesisEntry = altbn128.G1HashToPoint(
+	[]byte("keep random beacon stand-in genesis"),
+).Marshal()
+
+// Config stores configuration of the chain stand-in.
+type Config struct {
+	// Relay is the configuration of the threshold relay the stand-in
+	// provides to clients.
+	Relay relaychain.Config
+
+	// DKGResultSubmissionTimeout is the number of blocks after the end of
+	// the ticket submission within which the DKG result has to be submitted.
+	// Once the timeout passes, the group selection is abandoned and a new
+	// one may be started.
+	DKGResultSubmissionTimeout uint64
+
+	// MinimumStake is the minimum stake an operator needs to take part in
+	// the group selection. Each multiple of the minimum stake is a virtual
+	// staker which can submit one ticket.
+	MinimumStake *big.Int
+
+	// BlockTime is the interval at which the stand-in mines blocks.
+	BlockTime time.Duration
+}
+
+// DefaultConfig returns the stand-in configuration for groups of the given
+// size and honest threshold, with timeouts long enough for clients running
+// on a single machine to complete group selection, DKG, and relay entry
+// signing.
+func DefaultConfig(groupSize int, honestThreshold int) Config {
+	resultPublicationBlockStep := uint64(3)
+
+	return Config{
+		Relay: relaychain.Config{
+			GroupSize:                  groupSize,
+			HonestThreshold:            honestThreshold,
+			TicketSubmissionTimeout:    24,
+			ResultPublicationBlockStep: resultPublicationBlockStep,
+			RelayEntryTimeout:          24 + resultPublicationBlockStep*uint64(groupSize),
+			GroupActiveTime:            1000,
+		},
+		DKGResultSubmissionTimeout: 100 + resultPublicationBlockStep*uint64(groupSize),
+		MinimumStake:               big.NewInt(1000),
+		BlockTime:                  500 * time.Millisecond,
+	}
+}
+
+// Group is a group registered in the stand-in.
+type Group struct {
+	PublicKey         []byte
+	Members           []common.Address
+	Misbehaved        []byte
+	RegistrationBlock uint64
+}
+
+// Entry is a relay entry submitted to the stand-in.
+type Entry struct {
+	Value          []byte
+	PreviousEntry  []byte
+	GroupPublicKey []byte
+	BlockNumber    uint64
+}
+
+// Server serves the chain stand-in over JSON-RPC. It can be exposed over HTTP
+// as it implements http.Handler. The state of the stand-in can be inspected
+// and driven directly through the server by the process hosting it.
+type Server struct {
+	rpcServer *rpc.Server
+	chain     *standInChain
+	stop      chan struct{}
+	stopOnce  sync.Once
+}
+
+// NewServer creates the chain stand-in with the given configuration and
+// starts mining its blocks.
+func NewServer(config Config) (*Server, error) {
+	if config.BlockTime <= 0 {
+		return nil, fmt.Errorf("block time must be positive")
+	}
+	if config.MinimumStake == nil || config.MinimumStake.Sign() <= 0 {
+		return nil, fmt.Errorf("minimum stake must be positive")
+	}
+
+	chain := newStandInChain(config)
+
+	rpcServer := rpc.NewServer()
+	if err := rpcServer.RegisterName(
+		serviceName,
+		&service{chain: chain},
+	); err != nil {
+		return nil, fmt.Errorf("could not register stand-in service: [%v]", err)
+	}
+
+	server := &Server{
+		rpcServer: rpcServer,
+		chain:     chain,
+		stop:      make(chan struct{}),
+	}
+
+	go server.mine()
+
+	return server, nil
+}
+
+// ServeHTTP serves JSON-RPC requests of the stand-in clients.
+func (s *Server) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	s.rpcServer.ServeHTTP(writer, request)
+}
+
+// Stop stops mining blocks and serving requests.
+func (s *Server) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+		s.rpcServer.Stop()
+	})
+}
+
+func (s *Server) mine() {
+	ticker := time.NewTicker(s.chain.config.BlockTime)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.chain.mineBlock()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// Stake sets the stake of the operator with the given address.
+func (s *Server) Stake(address common.Address, amount *big.Int) {
+	s.chain.stake(address, amount)
+}
+
+// Genesis starts the first group selection. It fails if a group has already
+// been registered or a group selection is in progress.
+func (s *Server) Genesis() error {
+	return s.chain.genesis()
+}
+
+// RequestRelayEntry requests a new relay entry from one of the active groups
+// and returns the number of the block at which the request has been made.
+func (s *Server) RequestRelayEntry() (uint64, error) {
+	return s.chain.requestRelayEntry()
+}
+
+// CurrentBlock returns the number of the most recently mined block.
+func (s *Server) CurrentBlock() uint64 {
+	return s.chain.currentBlock()
+}
+
+// Groups returns all groups registered so far, in the order of their
+// registration.
+func (s *Server) Groups() []*Group {
+	return s.chain.registeredGroups()
+}
+
+// Entries returns all relay entries submitted so far, in the order of their
+// submission.
+func (s *Server) Entries() []*Entry {
+	return s.chain.submittedEntries()
+}
+
+// TimeoutReports returns numbers of blocks at which relay entry timeouts have
+// been reported.
+func (s *Server) TimeoutReports() []uint64 {
+	return s.chain.relayEntryTimeoutReports()
+}
+
+type ticket struct {
+	value  [8]byte
+	staker common.Address
+}
+
+type groupSelection struct {
+	seed       *big.Int
+	startBlock uint64
+	tickets    []*ticket
+}
+
+type relayRequest struct {
+	previousEntry  []byte
+	groupPublicKey []byte
+	startBlock     uint64
+}
+
+// standInChain holds the state of the simulated operator contract. All
+// operations on the state are performed under the mutex so that they are
+// atomic, as transactions are.
+type standInChain struct {
+	config Config
+
+	mutex sync.Mutex
+
+	blockHeight uint64
+	blockHashes map[uint64][]byte
+
+	stakes map[common.Address]*big.Int
+
+	groupSelection *groupSelection
+	groups         []*Group
+
+	lastEntry      []byte
+	request        *relayRequest
+	entries        []*Entry
+	timeoutReports []uint64
+
+	events []*eventRecord
+}
+
+func newStandInChain(config Config) *standInChain {
+	return &standInChain{
+		config:      config,
+		blockHashes: map[uint64][]byte{0: randomBlockHash()},
+		stakes:      make(map[common.Address]*big.Int),
+		lastEntry:   genesisEntry,
+	}
+}
+
+func randomBlockHash() []byte {
+	hash := make([]byte, 32)
+	if _, err := rand.Read(hash); err != nil {
+		logger.Errorf("could not generate block hash: [%v]", err)
+	}
+	return hash
+}
+
+func (sic *standInChain) mineBlock() {
+	sic.mutex.Lock()
+	defer sic.mutex.Unlock()
+
+	sic.blockHeight++
+	sic.blockHashes[sic.blockHeight] = randomBlockHash()
+}
+
+func (sic *standInChain) currentBlock() uint64 {
+	sic.mutex.Lock()
+	defer sic.mutex.Unlock()
+
+	return sic.blockHeight
+}
+
+func (sic *standInChain) blockHash(blockNumber uint64) ([]byte, error) {
+	sic.mutex.Lock()
+	defer sic.mutex.Unlock()
+
+	hash, ok := sic.blockHashes[blockNumber]
+	if !ok {
+		return nil, fmt.Errorf("block [%v] has not been mined yet", blockNumber)
+	}
+
+	return hash, nil
+}
+
+func (sic *standInChain) emit(record *eventRecord) {
+	record.BlockNumber = sic.blockHeight
+	sic.events = append(sic.events, record)
+}
+
+// eventsFrom returns events emitted since the event with the given index,
+// inclusive, along with the index of the next event to be emitted.
+func (sic *standInChain) eventsFrom(index uint64) ([]*eventRecord, uint64) {
+	sic.mutex.Lock()
+	defer sic.mutex.Unlock()
+
+	next := uint64(len(sic.events))
+	if index >= next {
+		return []*eventRecord{}, next
+	}
+
+	events := make([]*eventRecord, next-index)
+	copy(events, sic.events[index:])
+
+	return events, next
+}
+
+func (sic *standInChain) stake(address common.Address, amount *big.Int) {
+	sic.mutex.Lock()
+	defer sic.mutex.Unlock()
+
+	sic.stakes[address] = new(big.Int).Set(amount)
+}
+
+func (sic *standInChain) stakeOf(address common.Address) *big.Int {
+	sic.mutex.Lock()
+	defer sic.mutex.Unlock()
+
+	return sic.stakeOfLocked(address)
+}
+
+func (sic *standInChain) stakeOfLocked(address common.Address) *big.Int {
+	stake, ok := sic.stakes[address]
+	if !ok {
+		return big.NewInt(0)
+	}
+
+	return new(big.Int).Set(stake)
+}
+
+func (sic *standInChain) genesis() error {
+	sic.mutex.Lock()
+	defer sic.mutex.Unlock()
+
+	if len(sic.groups) > 0 {
+		return fmt.Errorf("genesis already performed")
+	}
+	if sic.isGroupSelectionInProgressLocked() {
+		return fmt.Errorf("group selection in progress")
+	}
+
+	sic.startGroupSelectionLocked(entryValue(genesisEntry))
+
+	return nil
+}
+
+func (sic *standInChain) isGroupSelectionInProgressLocked() bool {
+	if sic.groupSelection == nil {
+		return false
+	}
+
+	deadline := sic.groupSelection.startBlock +
+		sic.config.Relay.TicketSubmissionTimeout +
+		sic.config.DKGResultSubmissionTimeout
+
+	return sic.blockHeight <= deadline
+}
+
+func (sic *standInChain) startGroupSelectionLocked(seed *big.Int) {
+	sic.groupSelection = &groupSelection{
+		seed:       seed,
+		startBlock: sic.blockHeight,
+	}
+
+	logger.Infof(
+		"group selection started with seed [0x%x] at block [%v]",
+		seed,
+		sic.blockHeight,
+	)
+
+	sic.emit(&eventRecord{
+		Type: groupSelectionStartedEvent,
+		Seed: bigToHex(seed),
+	})
+}
+
+func (sic *standInChain) isTicketSubmissionOverLocked() bool {
+	return sic.blockHeight >= sic.groupSelection.startBlock+
+		sic.config.Relay.TicketSubmissionTimeout
+}
+
+func (sic *standInChain) submitTicket(
+	value [8]byte,
+	stakerValue *big.Int,
+	virtualStakerIndex *big.Int,
+) (uint64, error) {
+	sic.mutex.Lock()
+	defer sic.mutex.Unlock()
+
+	if !sic.isGroupSelectionInProgressLocked() {
+		return 0, fmt.Errorf("group selection not in progress")
+	}
+	if sic.isTicketSubmissionOverLocked() {
+		return 0, fmt.Errorf("ticket submission is over")
+	}
+
+	staker := common.BigToAddress(stakerValue)
+	virtualStakersCount := new(big.Int).Div(
+		sic.stakeOfLocked(staker),
+		sic.config.MinimumStake,
+	)
+	if virtualStakerIndex.Sign() <= 0 ||
+		virtualStakerIndex.Cmp(virtualStakersCount) > 0 {
+		return 0, fmt.Errorf(
+			"invalid virtual staker index [%v] for staker [%v] with [%v] "+
+				"virtual stakers",
+			virtualStakerIndex,
+			staker.Hex(),
+			virtualStakersCount,
+		)
+	}
+
+	expectedValue, err := ticketValue(
+		sic.groupSelection.seed,
+		staker,
+		virtualStakerIndex,
+	)
+	if err != nil {
+		return 0, err
+	}
+	if value != expectedValue {
+		return 0, fmt.Errorf("invalid ticket value [0x%x]", value)
+	}
+
+	for _, submitted := range sic.groupSelection.tickets {
+		if submitted.value == value {
+			return 0, fmt.Errorf("ticket [0x%x] already submitted", value)
+		}
+	}
+
+	sic.groupSelection.tickets = append(
+		sic.groupSelection.tickets,
+		&ticket{value: value, staker: staker},
+	)
+
+	return sic.blockHeight, nil
+}
+
+// ticketValue calculates the value of the ticket the same way as the operator
+// contract does, as the first 8 bytes of the hash of the group selection seed,
+// the staker address, and the virtual staker index, each padded to 32 bytes.
+func ticketValue(
+	seed *big.Int,
+	staker common.Address,
+	virtualStakerIndex *big.Int,
+) ([8]byte, error) {
+	var value [8]byte
+
+	var combined []byte
+	for _, part := range [][]byte{
+		seed.Bytes(),
+		staker.Bytes(),
+		virtualStakerIndex.Bytes(),
+	} {
+		padded, err := byteutils.LeftPadTo32Bytes(part)
+		if err != nil {
+			return value, fmt.Errorf("could not pad ticket input: [%v]", err)
+		}
+		combined = append(combined, padded...)
+	}
+
+	copy(value[:], crypto.Keccak256(combined)[:8])
+
+	return value, nil
+}
+
+func (sic *standInChain) submittedTickets() []uint64 {
+	sic.mutex.Lock()
+	defer sic.mutex.Unlock()
+
+	tickets := make([]uint64, 0)
+	if sic.groupSelection == nil {
+		return tickets
+	}
+
+	for _, ticket := range sic.groupSelection.tickets {
+		tickets = append(tickets, binary.BigEndian.Uint64(ticket.value[:]))
+	}
+
+	return tickets
+}
+
+func (sic *standInChain) selectedParticipants() ([]common.Address, error) {
+	sic.mutex.Lock()
+	defer sic.mutex.Unlock()
+
+	return sic.selectedParticipantsLocked()
+}
+
+// selectedParticipantsLocked returns stakers of the tickets with the lowest
+// values, one for each member of the group.
+func (sic *standInChain) selectedParticipantsLocked() ([]common.Address, error) {
+	if sic.groupSelection == nil {
+		return nil, fmt.Errorf("no group selection performed")
+	}
+	if !sic.isTicketSubmissionOverLocked() {
+		return nil, fmt.Errorf("ticket submission in progress")
+	}
+
+	tickets := make([]*ticket, len(sic.groupSelection.tickets))
+	copy(tickets, sic.groupSelection.tickets)
+
+	groupSize := sic.config.Relay.GroupSize
+	if len(tickets) < groupSize {
+		return nil, fmt.Errorf(
+			"not enough tickets submitted; has [%v], required [%v]",
+			len(tickets),
+			groupSize,
+		)
+	}
+
+	sort.Slice(tickets, func(i, j int) bool {
+		return bytes.Compare(tickets[i].value[:], tickets[j].value[:]) < 0
+	})
+
+	participants := make([]common.Address, groupSize)
+	for i := range participants {
+		participants[i] = tickets[i].staker
+	}
+
+	return participants, nil
+}
+
+// dkgResultHash calculates the hash of the DKG result the same way as the
+// operator contract does.
+func dkgResultHash(groupPublicKey []byte, misbehaved []byte) []byte {
+	return crypto.Keccak256(groupPublicKey, misbehaved)
+}
+
+func (sic *standInChain) submitDKGResult(
+	submitterIndex uint8,
+	groupPublicKey []byte,
+	misbehaved []byte,
+	signatures map[uint8][]byte,
+) (uint64, error) {
+	sic.mutex.Lock()
+	defer sic.mutex.Unlock()
+
+	if !sic.isGroupSelectionInProgressLocked() {
+		return 0, fmt.Errorf("group selection not in progress")
+	}
+
+	members, err := sic.selectedParticipantsLocked()
+	if err != nil {
+		return 0, err
+	}
+
+	if submitterIndex < 1 || int(submitterIndex) > len(members) {
+		return 0, fmt.Errorf("invalid submitter index [%v]", submitterIndex)
+	}
+	if len(groupPublicKey) == 0 {
+		return 0, fmt.Errorf("missing group public key")
+	}
+	for _, memberIndex := range misbehaved {
+		if memberIndex < 1 || int(memberIndex) > len(members) {
+			return 0, fmt.Errorf(
+				"invalid misbehaved member index [%v]",
+				memberIndex,
+			)
+		}
+	}
+
+	relayConfig := sic.config.Relay
+	signatureThreshold := relayConfig.HonestThreshold +
+		(relayConfig.GroupSize-relayConfig.HonestThreshold)/2
+	if len(signatures) < signatureThreshold {
+		return 0, fmt.Errorf(
+			"too few signatures; has [%v], required [%v]",
+			len(signatures),
+			signatureThreshold,
+		)
+	}
+
+	hash := dkgResultHash(groupPublicKey, misbehaved)
+	for memberIndex, signature := range signatures {
+		if memberIndex < 1 || int(memberIndex) > len(members) {
+			return 0, fmt.Errorf(
+				"signature of invalid member index [%v]",
+				memberIndex,
+			)
+		}
+
+		signer, err := recoverSigner(hash, signature)
+		if err != nil {
+			return 0, fmt.Errorf(
+				"invalid signature of member [%v]: [%v]",
+				memberIndex,
+				err,
+			)
+		}
+		if signer != members[memberIndex-1] {
+			return 0, fmt.Errorf(
+				"signature of member [%v] not signed by the member",
+				memberIndex,
+			)
+		}
+	}
+
+	sic.groups = append(sic.groups, &Group{
+		PublicKey:         groupPublicKey,
+		Members:           members,
+		Misbehaved:        misbehaved,
+		RegistrationBlock: sic.blockHeight,
+	})
+	sic.groupSelection = nil
+
+	logger.Infof(
+		"group [0x%x] registered at block [%v] with misbehaved members [%v]",
+		groupPublicKey,
+		sic.blockHeight,
+		misbehaved,
+	)
+
+	sic.emit(&eventRecord{
+		Type:           dkgResultSubmittedEvent,
+		MemberIndex:    uint32(submitterIndex),
+		GroupPublicKey: groupPublicKey,
+		Misbehaved:     misbehaved,
+	})
+
+	return sic.blockHeight, nil
+}
+
+// recoverSigner recovers the address of the signer of the given hash from
+// the signature in the Ethereum signed message format.
+func recoverSigner(hash []byte, signature []byte) (common.Address, error) {
+	if len(signature) != 65 {
+		return common.Address{}, fmt.Errorf(
+			"signature should have 65 bytes; has: [%v]",
+			len(signature),
+		)
+	}
+
+	// Signatures conform to the on-chain validation code accepting the
+	// recovery identifier equal to 27 or 28.
+	recoverable := make([]byte, len(signature))
+	copy(recoverable, signature)
+	if recoverable[64] >= 27 {
+		recoverable[64] -= 27
+	}
+
+	prefixedHash := crypto.Keccak256(
+		[]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%v", len(hash))),
+		hash,
+	)
+
+	publicKey, err := crypto.SigToPub(prefixedHash, recoverable)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	return crypto.PubkeyToAddress(*publicKey), nil
+}
+
+func (sic *standInChain) findGroupLocked(groupPublicKey []byte) *Group {
+	for _, group := range sic.groups {
+		if bytes.Equal(group.PublicKey, groupPublicKey) {
+			return group
+		}
+	}
+	return nil
+}
+
+func (sic *standInChain) isGroupRegistered(groupPublicKey []byte) bool {
+	sic.mutex.Lock()
+	defer sic.mutex.Unlock()
+
+	return sic.findGroupLocked(groupPublicKey) != nil
+}
+
+func (sic *standInChain) isExpiredLocked(group *Group) bool {
+	return sic.blockHeight > group.RegistrationBlock+sic.config.Relay.GroupActiveTime
+}
+
+func (sic *standInChain) isStaleGroup(groupPublicKey []byte) bool {
+	sic.mutex.Lock()
+	defer sic.mutex.Unlock()
+
+	group := sic.findGroupLocked(groupPublicKey)
+	if group == nil {
+		return true
+	}
+
+	return sic.blockHeight > group.RegistrationBlock+
+		sic.config.Relay.GroupActiveTime+
+		sic.config.Relay.RelayEntryTimeout
+}
+
+func (sic *standInChain) groupRegistrationBlock(
+	groupPublicKey []byte,
+) (uint64, error) {
+	sic.mutex.Lock()
+	defer sic.mutex.Unlock()
+
+	group := sic.findGroupLocked(groupPublicKey)
+	if group == nil {
+		return 0, fmt.Errorf("group not found")
+	}
+
+	return group.RegistrationBlock, nil
+}
+
+func (sic *standInChain) groupMembers(
+	groupPublicKey []byte,
+) ([]common.Address, error) {
+	sic.mutex.Lock()
+	defer sic.mutex.Unlock()
+
+	group := sic.findGroupLocked(groupPublicKey)
+	if group == nil {
+		return nil, fmt.Errorf("group not found")
+	}
+
+	return group.Members, nil
+}
+
+func (sic *standInChain) registeredGroups() []*Group {
+	sic.mutex.Lock()
+	defer sic.mutex.Unlock()
+
+	groups := make([]*Group, len(sic.groups))
+	for i, group := range sic.groups {
+		groupCopy := *group
+		groups[i] = &groupCopy
+	}
+
+	return groups
+}
+
+func (sic *standInChain) isEntryTimedOutLocked() bool {
+	return sic.blockHeight > sic.request.startBlock+
+		sic.config.Relay.RelayEntryTimeout
+}
+
+// requestRelayEntry selects one of the active groups based on the value of
+// the last relay entry and requests a new relay entry from it.
+func (sic *standInChain) requestRelayEntry() (uint64, error) {
+	sic.mutex.Lock()
+	defer sic.mutex.Unlock()
+
+	if sic.request != nil && !sic.isEntryTimedOutLocked() {
+		return 0, fmt.Errorf("relay entry in progress")
+	}
+
+	activeGroups := make([]*Group, 0)
+	for _, group := range sic.groups {
+		if !sic.isExpiredLocked(group) {
+			activeGroups = append(activeGroups, group)
+		}
+	}
+	if len(activeGroups) == 0 {
+		return 0, fmt.Errorf("no active groups")
+	}
+
+	selectedIndex := new(big.Int).Mod(
+		entryValue(sic.lastEntry),
+		big.NewInt(int64(len(activeGroups))),
+	).Int64()
+	selectedGroup := activeGroups[selectedIndex]
+
+	sic.request = &relayRequest{
+		previousEntry:  sic.lastEntry,
+		groupPublicKey: selectedGroup.PublicKey,
+		startBlock:     sic.blockHeight,
+	}
+
+	sic.emit(&eventRecord{
+		Type:           relayEntryRequestedEvent,
+		PreviousEntry:  sic.request.previousEntry,
+		GroupPublicKey: sic.request.groupPublicKey,
+	})
+
+	return sic.blockHeight, nil
+}
+
+// currentRequest returns the relay request in progress or nil if there is no
+// request in progress.
+func (sic *standInChain) currentRequest() *relayRequest {
+	sic.mutex.Lock()
+	defer sic.mutex.Unlock()
+
+	if sic.request == nil {
+		return nil
+	}
+
+	request := *sic.request
+	return &request
+}
+
+func (sic *standInChain) submitRelayEntry(entry []byte) (uint64, error) {
+	sic.mutex.Lock()
+	defer sic.mutex.Unlock()
+
+	if sic.request == nil {
+		return 0, fmt.Errorf("no relay entry in progress")
+	}
+	if sic.isEntryTimedOutLocked() {
+		return 0, fmt.Errorf("relay entry timed out")
+	}
+
+	if err := verifyEntry(
+		entry,
+		sic.request.previousEntry,
+		sic.request.groupPublicKey,
+	); err != nil {
+		return 0, err
+	}
+
+	sic.entries = append(sic.entries, &Entry{
+		Value:          entry,
+		PreviousEntry:  sic.request.previousEntry,
+		GroupPublicKey: sic.request.groupPublicKey,
+		BlockNumber:    sic.blockHeight,
+	})
+	sic.lastEntry = entry
+	sic.request = nil
+
+	value := entryValue(entry)
+
+	logger.Infof(
+		"relay entry [0x%x] submitted at block [%v]",
+		value,
+		sic.blockHeight,
+	)
+
+	sic.emit(&eventRecord{
+		Type: relayEntrySubmittedEvent,
+		Seed: bigToHex(value),
+	})
+
+	// Each new relay entry triggers a new group selection unless one is in
+	// progress already.
+	if !sic.isGroupSelectionInProgressLocked() {
+		sic.startGroupSelectionLocked(value)
+	}
+
+	return sic.blockHeight, nil
+}
+
+// verifyEntry checks if the entry is a valid BLS signature of the previous
+// entry made with the group private key.
+func verifyEntry(entry []byte, previousEntry []byte, groupPublicKey []byte) error {
+	signature := new(bn256.G1)
+	if _, err := signature.Unmarshal(entry); err != nil {
+		return fmt.Errorf("could not unmarshal relay entry: [%v]", err)
+	}
+
+	message := new(bn256.G1)
+	if _, err := message.Unmarshal(previousEntry); err != nil {
+		return fmt.Errorf("could not unmarshal previous entry: [%v]", err)
+	}
+
+	publicKey := new(bn256.G2)
+	if _, err := publicKey.Unmarshal(groupPublicKey); err != nil {
+		return fmt.Errorf("could not unmarshal group public key: [%v]", err)
+	}
+
+	if !bls.VerifyG1(publicKey, message, signature) {
+		return fmt.Errorf("invalid relay entry")
+	}
+
+	return nil
+}
+
+// entryValue returns the value of the relay entry as seen by relay
+// consumers; it is also the seed of the group selection started with the
+// entry.
+func entryValue(entry []byte) *big.Int {
+	return new(big.Int).SetBytes(crypto.Keccak256(entry))
+}
+
+func (sic *standInChain) reportRelayEntryTimeout() error {
+	sic.mutex.Lock()
+	defer sic.mutex.Unlock()
+
+	if sic.request == nil {
+		return fmt.Errorf("no relay entry in progress")
+	}
+	if !sic.isEntryTimedOutLocked() {
+		return fmt.Errorf("relay entry did not time out yet")
+	}
+
+	sic.request = nil
+	sic.timeoutReports = append(sic.timeoutReports, sic.blockHeight)
+
+	logger.Warningf("relay entry timeout reported at block [%v]", sic.blockHeight)
+
+	return nil
+}
+
+func (sic *standInChain) submittedEntries() []*Entry {
+	sic.mutex.Lock()
+	defer sic.mutex.Unlock()
+
+	entries := make([]*Entry, len(sic.entries))
+	for i, entry := range sic.entries {
+		entryCopy := *entry
+		entries[i] = &entryCopy
+	}
+
+	return entries
+}
+
+func (sic *standInChain) relayEntryTimeoutReports() []uint64 {
+	sic.mutex.Lock()
+	defer sic.mutex.Unlock()
+
+	reports := make([]uint64, len(sic.timeoutReports))
+	copy(reports, sic.timeoutReports)
+
+	return reports
+}
@@ -0,0 +1,381 @@
+package standin
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+	relaychain "github.com/keep-network/keep-core/pkg/beacon/relay/chain"
+	"github.com/keep-network/keep-core/pkg/beacon/relay/event"
+	"github.com/keep-network/keep-core/pkg/bls"
+	"github.com/keep-network/keep-core/pkg/chain/ethereum/signer"
+	"github.com/keep-network/keep-core/pkg/gen/async"
+	"github.com/pborman/uuid"
+)
+
+func TestGroupSelectionAndRelayEntry(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	config := Config{
+		Relay: relaychain.Config{
+			GroupSize:                  3,
+			HonestThreshold:            2,
+			TicketSubmissionTimeout:    10,
+			ResultPublicationBlockStep: 1,
+			RelayEntryTimeout:          100,
+			GroupActiveTime:            100,
+		},
+		DKGResultSubmissionTimeout: 100,
+		MinimumStake:               big.NewInt(1000),
+		BlockTime:                  20 * time.Millisecond,
+	}
+
+	server, err := NewServer(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	signers := make([]*signer.Local, config.Relay.GroupSize)
+	for i := range signers {
+		signers[i] = signer.NewLocal(generateKey(t))
+		server.Stake(signers[i].Address(), config.MinimumStake)
+	}
+
+	utility, err := Connect(ctx, httpServer.URL, signers[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	relay := utility.ThresholdRelay()
+	blockCounter, err := utility.BlockCounter()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	groupSelectionStarted := make(chan *event.GroupSelectionStart, 1)
+	relay.OnGroupSelectionStarted(func(start *event.GroupSelectionStart) {
+		groupSelectionStarted <- start
+	})
+
+	if err := utility.Genesis(); err != nil {
+		t.Fatal(err)
+	}
+
+	var seed *big.Int
+	var selectionStartBlock uint64
+	select {
+	case start := <-groupSelectionStarted:
+		seed = start.NewEntry
+		selectionStartBlock = start.BlockNumber
+	case <-ctx.Done():
+		t.Fatal("group selection not started")
+	}
+
+	if err := utility.Genesis(); err == nil {
+		t.Errorf("expected genesis to fail with group selection in progress")
+	}
+
+	invalidTicket := &relaychain.Ticket{
+		Value: [8]byte{1},
+		Proof: &relaychain.TicketProof{
+			StakerValue:        signers[0].Address().Hash().Big(),
+			VirtualStakerIndex: big.NewInt(1),
+		},
+	}
+	if _, err := awaitTicket(relay.SubmitTicket(invalidTicket)); err == nil {
+		t.Errorf("expected ticket with invalid value to be rejected")
+	}
+
+	for _, operator := range signers {
+		virtualStakerIndex := big.NewInt(1)
+		value, err := ticketValue(seed, operator.Address(), virtualStakerIndex)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ticket := &relaychain.Ticket{
+			Value: value,
+			Proof: &relaychain.TicketProof{
+				StakerValue:        operator.Address().Hash().Big(),
+				VirtualStakerIndex: virtualStakerIndex,
+			},
+		}
+		if _, err := awaitTicket(relay.SubmitTicket(ticket)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tickets, err := relay.GetSubmittedTickets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tickets) != len(signers) {
+		t.Errorf(
+			"unexpected number of tickets\nexpected: [%v]\nactual:   [%v]",
+			len(signers),
+			len(tickets),
+		)
+	}
+
+	err = blockCounter.WaitForBlockHeight(
+		selectionStartBlock + config.Relay.TicketSubmissionTimeout,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	participants, err := relay.GetSelectedParticipants()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	groupPrivateKey, err := rand.Int(rand.Reader, bn256.Order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	groupPublicKey := new(bn256.G2).ScalarBaseMult(groupPrivateKey)
+
+	dkgResult := &relaychain.DKGResult{
+		GroupPublicKey: groupPublicKey.Marshal(),
+		Misbehaved:     []byte{},
+	}
+	dkgResultHash, err := relay.CalculateDKGResultHash(dkgResult)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signatures := make(map[relaychain.GroupMemberIndex][]byte)
+	for i, participant := range participants {
+		for _, operator := range signers {
+			if !bytes.Equal(participant, operator.Address().Bytes()) {
+				continue
+			}
+			signature, err := operator.Sign(dkgResultHash[:])
+			if err != nil {
+				t.Fatal(err)
+			}
+			signatures[relaychain.GroupMemberIndex(i+1)] = signature
+		}
+	}
+
+	groupRegistered := make(chan *event.GroupRegistration, 1)
+	relay.OnGroupRegistered(func(registration *event.GroupRegistration) {
+		groupRegistered <- registration
+	})
+
+	tooFewSignatures := map[relaychain.GroupMemberIndex][]byte{
+		1: signatures[1],
+	}
+	_, err = awaitDKGResult(relay.SubmitDKGResult(1, dkgResult, tooFewSignatures))
+	if err == nil {
+		t.Errorf("expected DKG result with too few signatures to be rejected")
+	}
+
+	_, err = awaitDKGResult(relay.SubmitDKGResult(1, dkgResult, signatures))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case registration := <-groupRegistered:
+		if !bytes.Equal(registration.GroupPublicKey, dkgResult.GroupPublicKey) {
+			t.Errorf("unexpected group public key registered")
+		}
+	case <-ctx.Done():
+		t.Fatal("group not registered")
+	}
+
+	isRegistered, err := relay.IsGroupRegistered(dkgResult.GroupPublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isRegistered {
+		t.Errorf("expected group to be registered")
+	}
+
+	requested := make(chan *event.Request, 1)
+	relay.OnRelayEntryRequested(func(request *event.Request) {
+		requested <- request
+	})
+
+	entryPromise := utility.RequestRelayEntry()
+
+	var request *event.Request
+	select {
+	case request = <-requested:
+	case <-ctx.Done():
+		t.Fatal("relay entry not requested")
+	}
+
+	startBlock, err := relay.CurrentRequestStartBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if startBlock.Uint64() != request.BlockNumber {
+		t.Errorf(
+			"unexpected request start block\nexpected: [%v]\nactual:   [%v]",
+			request.BlockNumber,
+			startBlock,
+		)
+	}
+
+	invalidEntry := bls.Sign(groupPrivateKey, []byte("invalid")).Marshal()
+	if _, err := awaitEntry(relay.SubmitRelayEntry(invalidEntry)); err == nil {
+		t.Errorf("expected invalid relay entry to be rejected")
+	}
+
+	previousEntry := new(bn256.G1)
+	if _, err := previousEntry.Unmarshal(request.PreviousEntry); err != nil {
+		t.Fatal(err)
+	}
+	entry := bls.SignG1(groupPrivateKey, previousEntry).Marshal()
+	if _, err := awaitEntry(relay.SubmitRelayEntry(entry)); err != nil {
+		t.Fatal(err)
+	}
+
+	generated := make(chan *event.EntryGenerated, 1)
+	entryPromise.OnSuccess(func(entryGenerated *event.EntryGenerated) {
+		generated <- entryGenerated
+	})
+
+	select {
+	case entryGenerated := <-generated:
+		if entryGenerated.Value.Cmp(entryValue(entry)) != 0 {
+			t.Errorf(
+				"unexpected entry value\nexpected: [%v]\nactual:   [%v]",
+				entryValue(entry),
+				entryGenerated.Value,
+			)
+		}
+	case <-ctx.Done():
+		t.Fatal("relay entry not generated")
+	}
+
+	entries := server.Entries()
+	if len(entries) != 1 || !bytes.Equal(entries[0].Value, entry) {
+		t.Errorf("expected the submitted relay entry to be recorded")
+	}
+}
+
+func TestHasMinimumStake(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config := DefaultConfig(3, 2)
+	server, err := NewServer(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	staked := signer.NewLocal(generateKey(t))
+	server.Stake(staked.Address(), config.MinimumStake)
+	notStaked := signer.NewLocal(generateKey(t))
+
+	utility, err := Connect(ctx, httpServer.URL, staked)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stakeMonitor, err := utility.StakeMonitor()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = map[string]struct {
+		address       string
+		expectedStake bool
+	}{
+		"staked operator": {
+			address:       staked.Address().Hex(),
+			expectedStake: true,
+		},
+		"operator without stake": {
+			address:       notStaked.Address().Hex(),
+			expectedStake: false,
+		},
+	}
+
+	for testName, test := range tests {
+		testName, test := testName, test
+		t.Run(testName, func(t *testing.T) {
+			hasMinimumStake, err := stakeMonitor.HasMinimumStake(test.address)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hasMinimumStake != test.expectedStake {
+				t.Errorf(
+					"unexpected minimum stake\nexpected: [%v]\nactual:   [%v]",
+					test.expectedStake,
+					hasMinimumStake,
+				)
+			}
+		})
+	}
+
+	if _, err := stakeMonitor.HasMinimumStake("not an address"); err == nil {
+		t.Errorf("expected invalid address to be rejected")
+	}
+}
+
+func awaitTicket(
+	promise *async.EventGroupTicketSubmissionPromise,
+) (*event.GroupTicketSubmission, error) {
+	result := make(chan error, 1)
+	var submission *event.GroupTicketSubmission
+	promise.OnComplete(func(value *event.GroupTicketSubmission, err error) {
+		submission = value
+		result <- err
+	})
+	return submission, <-result
+}
+
+func awaitDKGResult(
+	promise *async.EventDKGResultSubmissionPromise,
+) (*event.DKGResultSubmission, error) {
+	result := make(chan error, 1)
+	var submission *event.DKGResultSubmission
+	promise.OnComplete(func(value *event.DKGResultSubmission, err error) {
+		submission = value
+		result <- err
+	})
+	return submission, <-result
+}
+
+func awaitEntry(
+	promise *async.EventEntrySubmittedPromise,
+) (*event.EntrySubmitted, error) {
+	result := make(chan error, 1)
+	var submission *event.EntrySubmitted
+	promise.OnComplete(func(value *event.EntrySubmitted, err error) {
+		submission = value
+		result <- err
+	})
+	return submission, <-result
+}
+
+func generateKey(t *testing.T) *keystore.Key {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &keystore.Key{
+		Id:         uuid.NewRandom(),
+		Address:    crypto.PubkeyToAddress(privateKey.PublicKey),
+		PrivateKey: privateKey,
+	}
+}
@@ -2,11 +2,12 @@ package diagnostics
 
 import (
 	"encoding/json"
+	"sort"
 
 	"github.com/ipfs/go-log"
 	"github.com/keep-network/keep-common/pkg/diagnostics"
 	"github.com/keep-network/keep-core/pkg/net"
-	"github.com/keep-network/keep-core/pkg/net/key"
+	"github.com/keep-network/keep-core/pkg/operator"
 )
 
 var logger = log.Logger("keep-diagnostics")
@@ -37,15 +38,15 @@ func RegisterConnectedPeersSource(
 		peersList := make([]map[string]interface{}, len(connectedPeers))
 		for i := 0; i < len(connectedPeers); i++ {
 			peer := connectedPeers[i]
-			peerPublicKey, err := connectionManager.GetPeerPublicKey(peer)
+			peerPublicKey, err := connectionManager.GetPeerOperatorPublicKey(peer)
 			if err != nil {
-				logger.Error("error on getting peer public key: [%v]", err)
+				logger.Error("error on getting peer operator public key: [%v]", err)
 				continue
 			}
 
 			peersList[i] = map[string]interface{}{
 				"network_id":       peer,
-				"ethereum_address": key.NetworkPubKeyToChainAddress(peerPublicKey),
+				"ethereum_address": operator.PubkeyToAddress(*peerPublicKey).String(),
 			}
 		}
 
@@ -69,15 +70,15 @@ func RegisterClientInfoSource(
 		connectionManager := netProvider.ConnectionManager()
 
 		clientID := netProvider.ID().String()
-		clientPublicKey, err := connectionManager.GetPeerPublicKey(clientID)
+		clientPublicKey, err := connectionManager.GetPeerOperatorPublicKey(clientID)
 		if err != nil {
-			logger.Error("error on getting client public key: [%v]", err)
+			logger.Error("error on getting client operator public key: [%v]", err)
 			return ""
 		}
 
 		clientInfo := map[string]interface{}{
 			"network_id":       clientID,
-			"ethereum_address": key.NetworkPubKeyToChainAddress(clientPublicKey),
+			"ethereum_address": operator.PubkeyToAddress(*clientPublicKey).String(),
 		}
 
 		bytes, err := json.Marshal(clientInfo)
@@ -89,3 +90,48 @@ func RegisterClientInfoSource(
 		return string(bytes)
 	})
 }
+
+// RegisterPeerReputationSource registers the diagnostics source providing
+// reputation scores of peers penalized for misbehavior. The source is not
+// registered if the network provider does not keep reputation of peers.
+func RegisterPeerReputationSource(
+	registry *diagnostics.DiagnosticsRegistry,
+	netProvider net.Provider,
+) {
+	reputationKeeper, ok := netProvider.ConnectionManager().(net.ReputationKeeper)
+	if !ok {
+		return
+	}
+
+	registry.RegisterSource("peer_reputation", func() string {
+		peerScores := reputationKeeper.PeerScores()
+
+		connectedPeers := make(map[string]bool)
+		for _, peer := range netProvider.ConnectionManager().ConnectedPeers() {
+			connectedPeers[peer] = true
+		}
+
+		peers := make([]string, 0, len(peerScores))
+		for peer := range peerScores {
+			peers = append(peers, peer)
+		}
+		sort.Strings(peers)
+
+		peersList := make([]map[string]interface{}, len(peers))
+		for i, peer := range peers {
+			peersList[i] = map[string]interface{}{
+				"network_id":       peer,
+				"reputation_score": peerScores[peer],
+				"connected":        connectedPeers[peer],
+			}
+		}
+
+		bytes, err := json.Marshal(peersList)
+		if err != nil {
+			logger.Error("error on serializing peer reputation to JSON: [%v]", err)
+			return ""
+		}
+
+		return string(bytes)
+	})
+}
@@ -37,7 +37,10 @@ const (
 var errNoMinimumStake = fmt.Errorf("remote peer has no minimum stake")
 
 // MinimumStakePolicy is a net.Firewall rule making sure the remote peer
-// has a minimum stake of KEEP.
+// has a minimum stake of KEEP. The stake is resolved for the remote peer's
+// operator; if the remote peer uses a network key delegated by the operator,
+// the network layer validates the delegation and passes the operator key to
+// the policy.
 func MinimumStakePolicy(stakeMonitor chain.StakeMonitor) net.Firewall {
 	return &minimumStakePolicy{
 		stakeMonitor:        stakeMonitor,
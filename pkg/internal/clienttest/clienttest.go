@@ -0,0 +1,544 @@
+// Package clienttest provides a multi-process integration test harness. It
+// launches keep-client processes on localhost ports, each running the real
+// libp2p network and connected to a chain stand-in shared by all of them. The
+// stand-in is served by the process running the harness so the harness can
+// drive genesis and relay entry requests and inspect their outcomes.
+package clienttest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/keep-network/keep-core/pkg/chain/standin"
+	"github.com/keep-network/keep-core/pkg/net/key"
+	"github.com/keep-network/keep-core/pkg/operator"
+	peer "github.com/libp2p/go-libp2p-core/peer"
+	"github.com/pborman/uuid"
+)
+
+// BinaryEnvVariable is the name of the environment variable pointing to
+// the keep-client binary used by the harness. If it is not set, the binary is
+// built from the sources of the module the harness is a part of.
+const BinaryEnvVariable = "KEEP_CLIENT_BINARY"
+
+// keyFilePassword is the password of operator key files of all clients.
+const keyFilePassword = "password"
+
+// readinessCheckInterval is the interval in which the harness checks whether
+// clients have started and connected to each other.
+const readinessCheckInterval = 500 * time.Millisecond
+
+var configTemplate = template.Must(template.New("config").Parse(`
+[ethereum.account]
+	KeyFile = "{{.KeyFile}}"
+
+[LibP2P]
+	Port = {{.Port}}
+	Peers = [{{range $i, $peer := .Peers}}{{if $i}}, {{end}}"{{$peer}}"{{end}}]
+
+[Storage]
+	DataDir = "{{.DataDir}}"
+
+[Diagnostics]
+	Port = {{.DiagnosticsPort}}
+
+[StandIn]
+	URL = "{{.StandInURL}}"
+`))
+
+// Config stores configuration of the harness.
+type Config struct {
+	// Clients is the number of client processes launched.
+	Clients int
+
+	// StandIn is the configuration of the chain stand-in shared by clients.
+	StandIn standin.Config
+
+	// Stake is the stake of each client operator. If not set, each operator
+	// has the minimum stake.
+	Stake *big.Int
+}
+
+// Network is a set of client processes connected to each other and sharing
+// the same chain stand-in.
+type Network struct {
+	config  Config
+	dir     string
+	server  *standin.Server
+	http    *http.Server
+	clients []*Client
+
+	stopOnce sync.Once
+}
+
+// Client is a single client process of the network.
+type Client struct {
+	// Address is the address of the client operator.
+	Address string
+	// LogFile is the path to the file with the output of the client.
+	LogFile string
+
+	multiaddress    string
+	diagnosticsPort int
+	command         *exec.Cmd
+	exited          chan error
+}
+
+// Start serves the chain stand-in, launches all clients of the network and
+// waits until they have connected to each other. The network should be
+// stopped with Stop once it is no longer needed. If Start fails, all clients
+// are stopped but their files are kept for inspection.
+func Start(ctx context.Context, config Config) (*Network, error) {
+	if config.Clients < 1 {
+		return nil, fmt.Errorf("at least one client is required")
+	}
+	if config.Stake == nil {
+		config.Stake = config.StandIn.MinimumStake
+	}
+
+	dir, err := ioutil.TempDir("", "clienttest")
+	if err != nil {
+		return nil, fmt.Errorf("could not create network directory: [%v]", err)
+	}
+
+	server, err := standin.NewServer(config.StandIn)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("could not create the chain stand-in: [%v]", err)
+	}
+
+	network := &Network{
+		config: config,
+		dir:    dir,
+		server: server,
+	}
+
+	standInURL, err := network.serveStandIn()
+	if err != nil {
+		network.Stop()
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	binary, err := clientBinary(dir)
+	if err != nil {
+		network.Stop()
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	var bootstrapPeer string
+	for i := 0; i < config.Clients; i++ {
+		client, err := network.startClient(i, binary, standInURL, bootstrapPeer)
+		if err != nil {
+			network.Stop()
+			return nil, fmt.Errorf("could not start client [%v]: [%v]", i, err)
+		}
+
+		if bootstrapPeer == "" {
+			bootstrapPeer = client.multiaddress
+		}
+	}
+
+	if err := network.waitUntilConnected(ctx); err != nil {
+		network.Stop()
+		return nil, err
+	}
+
+	return network, nil
+}
+
+func (n *Network) serveStandIn() (string, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", fmt.Errorf("could not listen for stand-in requests: [%v]", err)
+	}
+
+	n.http = &http.Server{Handler: n.server}
+	go func() {
+		if err := n.http.Serve(listener); err != http.ErrServerClosed {
+			fmt.Fprintf(os.Stderr, "stand-in server failed: [%v]\n", err)
+		}
+	}()
+
+	return "http://" + listener.Addr().String(), nil
+}
+
+// clientBinary returns the path to the keep-client binary, building it into
+// the provided directory if the binary is not set in the environment.
+func clientBinary(dir string) (string, error) {
+	if binary := os.Getenv(BinaryEnvVariable); binary != "" {
+		return binary, nil
+	}
+
+	binary := filepath.Join(dir, "keep-client")
+	build := exec.Command(
+		"go",
+		"build",
+		"-o",
+		binary,
+		"github.com/keep-network/keep-core",
+	)
+	if output, err := build.CombinedOutput(); err != nil {
+		return "", fmt.Errorf(
+			"could not build the client: [%v]\n%s",
+			err,
+			output,
+		)
+	}
+
+	return binary, nil
+}
+
+func (n *Network) startClient(
+	index int,
+	binary string,
+	standInURL string,
+	bootstrapPeer string,
+) (*Client, error) {
+	clientDir := filepath.Join(n.dir, fmt.Sprintf("client-%v", index))
+	dataDir := filepath.Join(clientDir, "data")
+	if err := os.MkdirAll(dataDir, 0700); err != nil {
+		return nil, err
+	}
+
+	operatorKey, keyFile, err := createOperatorKey(clientDir)
+	if err != nil {
+		return nil, err
+	}
+
+	networkPrivateKey, _ := key.OperatorKeyToNetworkKey(
+		operator.ChainKeyToOperatorKey(operatorKey),
+	)
+	peerID, err := peer.IDFromPublicKey(networkPrivateKey.GetPublic())
+	if err != nil {
+		return nil, err
+	}
+
+	ports, err := freePorts(2)
+	if err != nil {
+		return nil, err
+	}
+
+	var peers []string
+	if bootstrapPeer != "" {
+		peers = append(peers, bootstrapPeer)
+	}
+
+	configFile := filepath.Join(clientDir, "config.toml")
+	if err := writeConfig(configFile, map[string]interface{}{
+		"KeyFile":         keyFile,
+		"Port":            ports[0],
+		"Peers":           peers,
+		"DataDir":         dataDir,
+		"DiagnosticsPort": ports[1],
+		"StandInURL":      standInURL,
+	}); err != nil {
+		return nil, err
+	}
+
+	n.server.Stake(operatorKey.Address, n.config.Stake)
+
+	logFile := filepath.Join(clientDir, "client.log")
+	output, err := os.Create(logFile)
+	if err != nil {
+		return nil, err
+	}
+
+	command := exec.Command(binary, "--config", configFile, "start")
+	command.Env = append(
+		os.Environ(),
+		"KEEP_ETHEREUM_PASSWORD="+keyFilePassword,
+	)
+	command.Stdout = output
+	command.Stderr = output
+
+	if err := command.Start(); err != nil {
+		output.Close()
+		return nil, err
+	}
+
+	client := &Client{
+		Address: operatorKey.Address.Hex(),
+		LogFile: logFile,
+		multiaddress: fmt.Sprintf(
+			"/ip4/127.0.0.1/tcp/%v/ipfs/%v",
+			ports[0],
+			peerID.Pretty(),
+		),
+		diagnosticsPort: ports[1],
+		command:         command,
+		exited:          make(chan error, 1),
+	}
+	go func() {
+		client.exited <- command.Wait()
+		output.Close()
+	}()
+
+	n.clients = append(n.clients, client)
+
+	return client, nil
+}
+
+func createOperatorKey(dir string) (*keystore.Key, string, error) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	operatorKey := &keystore.Key{
+		Id:         uuid.NewRandom(),
+		Address:    crypto.PubkeyToAddress(privateKey.PublicKey),
+		PrivateKey: privateKey,
+	}
+
+	keyJSON, err := keystore.EncryptKey(
+		operatorKey,
+		keyFilePassword,
+		keystore.LightScryptN,
+		keystore.LightScryptP,
+	)
+	if err != nil {
+		return nil, "", err
+	}
+
+	keyFile := filepath.Join(dir, "operator-key")
+	if err := ioutil.WriteFile(keyFile, keyJSON, 0600); err != nil {
+		return nil, "", err
+	}
+
+	return operatorKey, keyFile, nil
+}
+
+func writeConfig(path string, values map[string]interface{}) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return configTemplate.Execute(file, values)
+}
+
+// freePorts returns the given number of distinct localhost ports which are
+// free at the moment of the call.
+func freePorts(count int) ([]int, error) {
+	ports := make([]int, count)
+	for i := range ports {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return nil, err
+		}
+		defer listener.Close()
+
+		ports[i] = listener.Addr().(*net.TCPAddr).Port
+	}
+
+	return ports, nil
+}
+
+// waitUntilConnected waits until every client has started and is connected
+// to at least one peer, and the bootstrap client is connected to all the
+// other clients.
+func (n *Network) waitUntilConnected(ctx context.Context) error {
+	ticker := time.NewTicker(readinessCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		connected := true
+		for i, client := range n.clients {
+			select {
+			case err := <-client.exited:
+				return fmt.Errorf(
+					"client [%v] exited: [%v]; see [%v]",
+					i,
+					err,
+					client.LogFile,
+				)
+			default:
+			}
+
+			expectedPeers := 1
+			if i == 0 {
+				expectedPeers = len(n.clients) - 1
+			}
+
+			peers, err := client.connectedPeers()
+			if err != nil || peers < expectedPeers {
+				connected = false
+			}
+		}
+
+		if connected {
+			return nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return fmt.Errorf("clients not connected: [%v]", ctx.Err())
+		}
+	}
+}
+
+// connectedPeers returns the number of peers the client is connected to, as
+// reported by its diagnostics endpoint. The endpoint is available once the
+// client has fully started.
+func (c *Client) connectedPeers() (int, error) {
+	response, err := http.Get(
+		fmt.Sprintf("http://127.0.0.1:%v/diagnostics", c.diagnosticsPort),
+	)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	diagnostics := struct {
+		ConnectedPeers []interface{} `json:"connected_peers"`
+	}{}
+	if err := json.NewDecoder(response.Body).Decode(&diagnostics); err != nil {
+		return 0, err
+	}
+
+	return len(diagnostics.ConnectedPeers), nil
+}
+
+// Clients returns all clients of the network.
+func (n *Network) Clients() []*Client {
+	return n.clients
+}
+
+// StandIn returns the chain stand-in shared by all clients.
+func (n *Network) StandIn() *standin.Server {
+	return n.server
+}
+
+// Genesis starts the first group selection.
+func (n *Network) Genesis() error {
+	return n.server.Genesis()
+}
+
+// WaitForGroup waits until the group with the given number, counting from
+// zero in the order of registration, is registered.
+func (n *Network) WaitForGroup(
+	ctx context.Context,
+	groupNumber int,
+) (*standin.Group, error) {
+	var group *standin.Group
+	err := n.waitFor(ctx, "group registration", func() bool {
+		groups := n.server.Groups()
+		if len(groups) > groupNumber {
+			group = groups[groupNumber]
+			return true
+		}
+		return false
+	})
+
+	return group, err
+}
+
+// RequestRelayEntry requests a new relay entry and waits until the entry
+// is submitted.
+func (n *Network) RequestRelayEntry(ctx context.Context) (*standin.Entry, error) {
+	entriesBefore := len(n.server.Entries())
+
+	if _, err := n.server.RequestRelayEntry(); err != nil {
+		return nil, fmt.Errorf("could not request relay entry: [%v]", err)
+	}
+
+	var entry *standin.Entry
+	err := n.waitFor(ctx, "relay entry", func() bool {
+		entries := n.server.Entries()
+		if len(entries) > entriesBefore {
+			entry = entries[entriesBefore]
+			return true
+		}
+		return false
+	})
+
+	return entry, err
+}
+
+// waitFor waits until the condition is met, checking it at every block of
+// the stand-in. Waiting fails if any client exits before the condition is
+// met.
+func (n *Network) waitFor(
+	ctx context.Context,
+	description string,
+	condition func() bool,
+) error {
+	ticker := time.NewTicker(n.config.StandIn.BlockTime)
+	defer ticker.Stop()
+
+	for !condition() {
+		for i, client := range n.clients {
+			select {
+			case err := <-client.exited:
+				return fmt.Errorf(
+					"client [%v] exited while waiting for %v: [%v]; see [%v]",
+					i,
+					description,
+					err,
+					client.LogFile,
+				)
+			default:
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return fmt.Errorf(
+				"%v not completed at block [%v]: [%v]; see logs in [%v]",
+				description,
+				n.server.CurrentBlock(),
+				ctx.Err(),
+				n.dir,
+			)
+		}
+	}
+
+	return nil
+}
+
+// Stop kills all clients and stops the chain stand-in. Clients keep
+// submitting relay entries and running group selections until they are
+// stopped. Logs, configs, and data of clients are kept in the network
+// directory; they should be removed with RemoveFiles once no longer needed.
+func (n *Network) Stop() {
+	n.stopOnce.Do(func() {
+		for _, client := range n.clients {
+			if err := client.command.Process.Kill(); err != nil {
+				fmt.Fprintf(os.Stderr, "could not kill client: [%v]\n", err)
+			}
+		}
+
+		if n.http != nil {
+			n.http.Close()
+		}
+		n.server.Stop()
+	})
+}
+
+// Dir returns the directory with logs, configs, and data of all clients.
+func (n *Network) Dir() string {
+	return n.dir
+}
+
+// RemoveFiles removes the network directory.
+func (n *Network) RemoveFiles() error {
+	return os.RemoveAll(n.dir)
+}
@@ -0,0 +1,86 @@
+//+build integration
+
+package clienttest
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+	"github.com/keep-network/keep-core/pkg/bls"
+	"github.com/keep-network/keep-core/pkg/chain/standin"
+)
+
+func TestGenesisGroupAndRelayEntry(t *testing.T) {
+	groupSize := 5
+	honestThreshold := 3
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	network, err := Start(ctx, Config{
+		Clients: groupSize,
+		StandIn: standin.DefaultConfig(groupSize, honestThreshold),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		network.Stop()
+		if t.Failed() {
+			t.Logf("client logs kept in [%v]", network.Dir())
+			return
+		}
+		network.RemoveFiles()
+	}()
+
+	if err := network.Genesis(); err != nil {
+		t.Fatal(err)
+	}
+
+	group, err := network.WaitForGroup(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(group.Misbehaved) != 0 {
+		t.Errorf("unexpected misbehaved members: [%v]", group.Misbehaved)
+	}
+
+	members := make(map[string]bool)
+	for _, member := range group.Members {
+		members[member.Hex()] = true
+	}
+	for _, client := range network.Clients() {
+		if !members[client.Address] {
+			t.Errorf("client [%v] is not a group member", client.Address)
+		}
+	}
+
+	entry, err := network.RequestRelayEntry(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(entry.GroupPublicKey, group.PublicKey) {
+		t.Errorf("relay entry not signed by the registered group")
+	}
+
+	signature := new(bn256.G1)
+	if _, err := signature.Unmarshal(entry.Value); err != nil {
+		t.Fatal(err)
+	}
+	previousEntry := new(bn256.G1)
+	if _, err := previousEntry.Unmarshal(entry.PreviousEntry); err != nil {
+		t.Fatal(err)
+	}
+	groupPublicKey := new(bn256.G2)
+	if _, err := groupPublicKey.Unmarshal(group.PublicKey); err != nil {
+		t.Fatal(err)
+	}
+	if !bls.VerifyG1(groupPublicKey, previousEntry, signature) {
+		t.Errorf("invalid relay entry signature")
+	}
+}
@@ -19,7 +19,10 @@ import (
 	"github.com/keep-network/keep-core/pkg/beacon/relay/gjkr"
 	"github.com/keep-network/keep-core/pkg/beacon/relay/group"
 	chainLocal "github.com/keep-network/keep-core/pkg/chain/local"
+	"github.com/keep-network/keep-core/pkg/internal/faultnet"
 	"github.com/keep-network/keep-core/pkg/internal/interception"
+	"github.com/keep-network/keep-core/pkg/metrics"
+	"github.com/keep-network/keep-core/pkg/net"
 	"github.com/keep-network/keep-core/pkg/net/key"
 	netLocal "github.com/keep-network/keep-core/pkg/net/local"
 	"github.com/keep-network/keep-core/pkg/operator"
@@ -62,6 +65,27 @@ func RunTest(
 	honestThreshold int,
 	seed *big.Int,
 	rules interception.Rules,
+) (*Result, error) {
+	return RunTestWithFaults(
+		groupSize,
+		honestThreshold,
+		seed,
+		rules,
+		faultnet.Config{},
+	)
+}
+
+// RunTestWithFaults executes the full DKG roundrip test just like RunTest
+// but additionally injects the provided network faults into the delivery of
+// broadcast messages between group members. Latencies and partitions are
+// measured in blocks of the local chain which starts from block 0 when the
+// test starts.
+func RunTestWithFaults(
+	groupSize int,
+	honestThreshold int,
+	seed *big.Int,
+	rules interception.Rules,
+	faults faultnet.Config,
 ) (*Result, error) {
 	privateKey, publicKey, err := operator.GenerateKeyPair()
 	if err != nil {
@@ -70,11 +94,6 @@ func RunTest(
 
 	_, networkPublicKey := key.OperatorKeyToNetworkKey(privateKey, publicKey)
 
-	network := interception.NewNetwork(
-		netLocal.ConnectWithKey(networkPublicKey),
-		rules,
-	)
-
 	chain := chainLocal.ConnectWithKey(
 		groupSize,
 		honestThreshold,
@@ -82,6 +101,17 @@ func RunTest(
 		privateKey,
 	)
 
+	blockCounter, err := chain.BlockCounter()
+	if err != nil {
+		return nil, err
+	}
+
+	network := faultnet.NewNetwork(
+		netLocal.ConnectWithKey(networkPublicKey),
+		blockCounter,
+		faults,
+	)
+
 	address := chain.Signing().PublicKeyBytesToAddress(
 		key.Marshal(networkPublicKey),
 	)
@@ -91,13 +121,14 @@ func RunTest(
 		selectedStakers[i] = address
 	}
 
-	return executeDKG(seed, chain, network, selectedStakers)
+	return executeDKG(seed, chain, network, rules, selectedStakers)
 }
 
 func executeDKG(
 	seed *big.Int,
 	chain chainLocal.Chain,
-	network interception.Network,
+	network *faultnet.Network,
+	rules interception.Rules,
 	selectedStakers []relaychain.StakerAddress,
 ) (*Result, error) {
 	relayConfig := chain.ThresholdRelay().GetConfig()
@@ -107,9 +138,22 @@ func executeDKG(
 		return nil, err
 	}
 
-	broadcastChannel, err := network.BroadcastChannelFor(fmt.Sprintf("dkg-test-%v", seed))
-	if err != nil {
-		return nil, err
+	// Each member uses its own view of the network so that faults can be
+	// injected on links between members.
+	broadcastChannels := make([]net.BroadcastChannel, relayConfig.GroupSize)
+	for i := range broadcastChannels {
+		broadcastChannel, err := interception.NewNetwork(
+			network.Provider(group.MemberIndex(i+1)),
+			rules,
+		).BroadcastChannelFor(fmt.Sprintf("dkg-test-%v", seed))
+		if err != nil {
+			return nil, err
+		}
+
+		gjkr.RegisterUnmarshallers(broadcastChannel)
+		dkgResult.RegisterUnmarshallers(broadcastChannel)
+
+		broadcastChannels[i] = broadcastChannel
 	}
 
 	resultSubmissionChan := make(chan *event.DKGResultSubmission)
@@ -136,18 +180,21 @@ func executeDKG(
 	// make sure all members are up.
 	startBlockHeight := currentBlockHeight + 3
 
-	gjkr.RegisterUnmarshallers(broadcastChannel)
-	dkgResult.RegisterUnmarshallers(broadcastChannel)
-
 	membershipValidator := group.NewStakersMembershipValidator(
 		selectedStakers,
 		chain.Signing(),
 	)
 
+	protocolMetrics, err := metrics.NewProtocol(metrics.NewRegistry())
+	if err != nil {
+		return nil, err
+	}
+
 	for i := 0; i < relayConfig.GroupSize; i++ {
 		i := i // capture for goroutine
 		go func() {
 			signer, err := dkg.ExecuteDKG(
+				context.Background(),
 				seed,
 				uint8(i),
 				relayConfig.GroupSize,
@@ -157,7 +204,12 @@ func executeDKG(
 				blockCounter,
 				chain.ThresholdRelay(),
 				chain.Signing(),
-				broadcastChannel,
+				broadcastChannels[i],
+				nil,
+				nil,
+				protocolMetrics.ForOperator("test"),
+				nil,
+				nil,
 			)
 			if signer != nil {
 				signersMutex.Lock()
@@ -12,7 +12,9 @@ import (
 	"time"
 
 	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+	"github.com/keep-network/keep-core/pkg/internal/faultnet"
 	"github.com/keep-network/keep-core/pkg/internal/interception"
+	"github.com/keep-network/keep-core/pkg/net"
 	"github.com/keep-network/keep-core/pkg/net/key"
 	"github.com/keep-network/keep-core/pkg/operator"
 
@@ -21,6 +23,7 @@ import (
 	"github.com/keep-network/keep-core/pkg/beacon/relay/event"
 
 	chainLocal "github.com/keep-network/keep-core/pkg/chain/local"
+	"github.com/keep-network/keep-core/pkg/metrics"
 	netLocal "github.com/keep-network/keep-core/pkg/net/local"
 )
 
@@ -62,6 +65,27 @@ func RunTest(
 	threshold int,
 	rules interception.Rules,
 	previousEntry []byte,
+) (*Result, error) {
+	return RunTestWithFaults(
+		signers,
+		threshold,
+		rules,
+		faultnet.Config{},
+		previousEntry,
+	)
+}
+
+// RunTestWithFaults executes the full relay entry signing roundtrip test just
+// like RunTest but additionally injects the provided network faults into the
+// delivery of broadcast messages between signers. Latencies and partitions
+// are measured in blocks of the local chain which starts from block 0 when
+// the test starts.
+func RunTestWithFaults(
+	signers []*dkg.ThresholdSigner,
+	threshold int,
+	rules interception.Rules,
+	faults faultnet.Config,
+	previousEntry []byte,
 ) (*Result, error) {
 	privateKey, publicKey, err := operator.GenerateKeyPair()
 	if err != nil {
@@ -70,21 +94,28 @@ func RunTest(
 
 	_, networkPublicKey := key.OperatorKeyToNetworkKey(privateKey, publicKey)
 
-	network := interception.NewNetwork(
+	chain := chainLocal.ConnectWithKey(len(signers), threshold, minimumStake, privateKey)
+
+	blockCounter, err := chain.BlockCounter()
+	if err != nil {
+		return nil, err
+	}
+
+	network := faultnet.NewNetwork(
 		netLocal.ConnectWithKey(networkPublicKey),
-		rules,
+		blockCounter,
+		faults,
 	)
 
-	chain := chainLocal.ConnectWithKey(len(signers), threshold, minimumStake, privateKey)
-
-	return executeSigning(signers, threshold, chain, network, previousEntry)
+	return executeSigning(signers, threshold, chain, network, rules, previousEntry)
 }
 
 func executeSigning(
 	signers []*dkg.ThresholdSigner,
 	threshold int,
 	chain chainLocal.Chain,
-	network interception.Network,
+	network *faultnet.Network,
+	rules interception.Rules,
 	previousEntry []byte,
 ) (*Result, error) {
 	blockCounter, err := chain.BlockCounter()
@@ -99,11 +130,22 @@ func executeSigning(
 	if err != nil {
 		return nil, err
 	}
-	broadcastChannel, err := network.BroadcastChannelFor(
-		fmt.Sprintf("entry-test-%v", randomSelector),
-	)
-	if err != nil {
-		return nil, err
+
+	// Each signer uses its own view of the network so that faults can be
+	// injected on links between signers.
+	broadcastChannels := make([]net.BroadcastChannel, len(signers))
+	for i, signer := range signers {
+		broadcastChannel, err := interception.NewNetwork(
+			network.Provider(signer.MemberID()),
+			rules,
+		).BroadcastChannelFor(fmt.Sprintf("entry-test-%v", randomSelector))
+		if err != nil {
+			return nil, err
+		}
+
+		entry.RegisterUnmarshallers(broadcastChannel)
+
+		broadcastChannels[i] = broadcastChannel
 	}
 
 	entrySubmissionChan := make(chan *event.EntrySubmitted)
@@ -128,11 +170,15 @@ func executeSigning(
 	// make sure all signers are ready
 	startBlockHeight := currentBlockHeight + 3
 
-	entry.RegisterUnmarshallers(broadcastChannel)
+	protocolMetrics, err := metrics.NewProtocol(metrics.NewRegistry())
+	if err != nil {
+		return nil, err
+	}
 
-	for _, signer := range signers {
-		go func(signer *dkg.ThresholdSigner) {
+	for i, signer := range signers {
+		go func(signer *dkg.ThresholdSigner, broadcastChannel net.BroadcastChannel) {
 			err := entry.SignAndSubmit(
+				context.Background(),
 				blockCounter,
 				broadcastChannel,
 				chain.ThresholdRelay(),
@@ -140,6 +186,8 @@ func executeSigning(
 				threshold,
 				signer,
 				startBlockHeight,
+				nil,
+				protocolMetrics.ForOperator("test"),
 			)
 			if err != nil {
 				fmt.Printf("[signer:%v %v] failed with: [%v]\n", signer.MemberID(), previousEntry, err)
@@ -148,7 +196,7 @@ func executeSigning(
 				signerFailuresMutex.Unlock()
 			}
 			wg.Done()
-		}(signer)
+		}(signer, broadcastChannels[i])
 	}
 	wg.Wait()
 
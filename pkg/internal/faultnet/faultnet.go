@@ -0,0 +1,354 @@
+// Package faultnet provides a network provider wrapper injecting faults into
+// the delivery of broadcast messages so that protocols can be tested against
+// an unreliable network. Messages can be delayed by a number of blocks,
+// reordered, duplicated, lost, and group members can be partitioned from each
+// other. All random faults are decided based on the seed provided in the
+// configuration so that a faulty execution can be reproduced deterministically.
+package faultnet
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"sync"
+
+	"github.com/keep-network/keep-core/pkg/beacon/relay/group"
+	"github.com/keep-network/keep-core/pkg/chain"
+	"github.com/keep-network/keep-core/pkg/net"
+)
+
+// Link is a one-way network connection between two group members.
+type Link struct {
+	Sender   group.MemberIndex
+	Receiver group.MemberIndex
+}
+
+// Partition isolates the given group members from all other group members
+// between the given block heights of the chain. Messages sent between members
+// inside and outside the partition are lost while the partition is active.
+// Partition is active from FromBlock, inclusive, to ToBlock, exclusive.
+// Partition with ToBlock equal to zero is never healed.
+type Partition struct {
+	Members   []group.MemberIndex
+	FromBlock uint64
+	ToBlock   uint64
+}
+
+func (p *Partition) isActive(blockHeight uint64) bool {
+	return blockHeight >= p.FromBlock &&
+		(p.ToBlock == 0 || blockHeight < p.ToBlock)
+}
+
+func (p *Partition) separates(link Link) bool {
+	return p.contains(link.Sender) != p.contains(link.Receiver)
+}
+
+func (p *Partition) contains(memberIndex group.MemberIndex) bool {
+	for _, member := range p.Members {
+		if member == memberIndex {
+			return true
+		}
+	}
+	return false
+}
+
+// Config defines faults injected by the network. The zero value of Config
+// injects no faults.
+type Config struct {
+	// Seed of the pseudo-random source deciding about message loss,
+	// duplication, and reordering. Executions with the same seed observe
+	// the same faults.
+	Seed int64
+
+	// Latency is the number of blocks by which every message is delayed.
+	Latency uint64
+	// LinkLatencies overrides Latency for the given links.
+	LinkLatencies map[Link]uint64
+
+	// Partitions lists partitions applied to the group.
+	Partitions []Partition
+
+	// LossProbability is the probability of a message being lost.
+	LossProbability float64
+	// DuplicationProbability is the probability of a message being delivered
+	// twice.
+	DuplicationProbability float64
+	// ReorderProbability is the probability of a message being delayed by one
+	// additional block so that messages sent after it are delivered first.
+	ReorderProbability float64
+}
+
+// Network injects faults defined by its configuration into the delivery of
+// broadcast messages of the wrapped provider. Each group member should use
+// its own view of the network returned from Provider so that faults can be
+// applied to the links between members.
+//
+// Faults are applied to messages which payload is a group.ProtocolMessage.
+// Other messages and messages sent by members to themselves are delivered
+// unaffected. Unicast channels of the wrapped provider are not affected.
+type Network struct {
+	provider     net.Provider
+	blockCounter chain.BlockCounter
+	config       Config
+}
+
+// NewNetwork creates a new fault-injecting network on top of the provided
+// network provider. Latencies and partitions are measured in blocks of the
+// provided block counter.
+func NewNetwork(
+	provider net.Provider,
+	blockCounter chain.BlockCounter,
+	config Config,
+) *Network {
+	return &Network{
+		provider:     provider,
+		blockCounter: blockCounter,
+		config:       config,
+	}
+}
+
+// Provider returns the view of the network for the given group member.
+// Messages received from broadcast channels of the returned provider are
+// subject to faults injected on links from their senders to the member.
+func (n *Network) Provider(memberIndex group.MemberIndex) net.Provider {
+	return &provider{
+		Provider:    n.provider,
+		network:     n,
+		memberIndex: memberIndex,
+	}
+}
+
+// fault describes what happens to a single message sent over a link.
+type fault struct {
+	lost       bool
+	duplicated bool
+	delay      uint64
+}
+
+// fault decides about the fault of the message of the given type sent over
+// the link. The occurrence is the number of messages of the same type sent
+// over the link before. The decision depends only on the seed, the link, the
+// message type, its occurrence, and the block height at which the message is
+// received so it does not depend on the order in which messages are handled
+// by concurrently executing members.
+func (n *Network) fault(
+	link Link,
+	messageType string,
+	occurrence uint64,
+	blockHeight uint64,
+) fault {
+	for _, partition := range n.config.Partitions {
+		if partition.isActive(blockHeight) && partition.separates(link) {
+			return fault{lost: true}
+		}
+	}
+
+	latency, ok := n.config.LinkLatencies[link]
+	if !ok {
+		latency = n.config.Latency
+	}
+
+	random := rand.New(rand.NewSource(
+		n.faultSeed(link, messageType, occurrence),
+	))
+
+	// All values are drawn unconditionally so that changing one probability
+	// does not change decisions made based on the other ones.
+	lost := random.Float64() < n.config.LossProbability
+	duplicated := random.Float64() < n.config.DuplicationProbability
+	reordered := random.Float64() < n.config.ReorderProbability
+
+	if reordered {
+		latency++
+	}
+
+	return fault{
+		lost:       lost,
+		duplicated: duplicated,
+		delay:      latency,
+	}
+}
+
+func (n *Network) faultSeed(
+	link Link,
+	messageType string,
+	occurrence uint64,
+) int64 {
+	hash := fnv.New64a()
+
+	buffer := make([]byte, 8)
+	binary.BigEndian.PutUint64(buffer, uint64(n.config.Seed))
+	hash.Write(buffer)
+	binary.BigEndian.PutUint64(buffer, uint64(link.Sender))
+	hash.Write(buffer)
+	binary.BigEndian.PutUint64(buffer, uint64(link.Receiver))
+	hash.Write(buffer)
+	binary.BigEndian.PutUint64(buffer, occurrence)
+	hash.Write(buffer)
+	hash.Write([]byte(messageType))
+
+	return int64(hash.Sum64())
+}
+
+type provider struct {
+	net.Provider
+
+	network     *Network
+	memberIndex group.MemberIndex
+}
+
+func (p *provider) BroadcastChannelFor(name string) (net.BroadcastChannel, error) {
+	delegate, err := p.Provider.BroadcastChannelFor(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &channel{
+		BroadcastChannel: delegate,
+		network:          p.network,
+		memberIndex:      p.memberIndex,
+	}, nil
+}
+
+type channel struct {
+	net.BroadcastChannel
+
+	network     *Network
+	memberIndex group.MemberIndex
+}
+
+func (c *channel) Recv(ctx context.Context, handler func(m net.Message)) {
+	receiver := &receiver{
+		ctx:         ctx,
+		handler:     handler,
+		network:     c.network,
+		memberIndex: c.memberIndex,
+		occurrences: make(map[occurrenceKey]uint64),
+	}
+
+	go receiver.deliverDelayed()
+
+	c.BroadcastChannel.Recv(ctx, receiver.receive)
+}
+
+type occurrenceKey struct {
+	sender      group.MemberIndex
+	messageType string
+}
+
+type delayedMessage struct {
+	message     net.Message
+	deliveryAt  uint64
+	copiesCount int
+}
+
+// receiver applies faults to messages received by the handler of a group
+// member.
+type receiver struct {
+	ctx         context.Context
+	handler     func(m net.Message)
+	network     *Network
+	memberIndex group.MemberIndex
+
+	mutex       sync.Mutex
+	occurrences map[occurrenceKey]uint64
+	delayed     []*delayedMessage
+}
+
+func (r *receiver) receive(message net.Message) {
+	protocolMessage, ok := message.Payload().(group.ProtocolMessage)
+	if !ok || protocolMessage.SenderID() == r.memberIndex {
+		r.handler(message)
+		return
+	}
+
+	blockHeight, err := r.network.blockCounter.CurrentBlock()
+	if err != nil {
+		r.handler(message)
+		return
+	}
+
+	link := Link{Sender: protocolMessage.SenderID(), Receiver: r.memberIndex}
+	messageType := fmt.Sprintf("%T", protocolMessage)
+
+	r.mutex.Lock()
+	key := occurrenceKey{link.Sender, messageType}
+	occurrence := r.occurrences[key]
+	r.occurrences[key]++
+	r.mutex.Unlock()
+
+	fault := r.network.fault(link, messageType, occurrence, blockHeight)
+	if fault.lost {
+		return
+	}
+
+	copiesCount := 1
+	if fault.duplicated {
+		copiesCount = 2
+	}
+
+	if fault.delay == 0 {
+		for i := 0; i < copiesCount; i++ {
+			r.handler(message)
+		}
+		return
+	}
+
+	r.mutex.Lock()
+	r.delayed = append(r.delayed, &delayedMessage{
+		message:     message,
+		deliveryAt:  blockHeight + fault.delay,
+		copiesCount: copiesCount,
+	})
+	r.mutex.Unlock()
+}
+
+// deliverDelayed delivers delayed messages once the block at which they
+// should be delivered is mined. Messages due at the same block are delivered
+// in the order in which they have been received.
+func (r *receiver) deliverDelayed() {
+	blocks := r.network.blockCounter.WatchBlocks(r.ctx)
+
+	for {
+		select {
+		case blockHeight, ok := <-blocks:
+			if !ok {
+				return
+			}
+
+			for _, delayed := range r.dueMessages(blockHeight) {
+				for i := 0; i < delayed.copiesCount; i++ {
+					if r.ctx.Err() != nil {
+						return
+					}
+					r.handler(delayed.message)
+				}
+			}
+		case <-r.ctx.Done():
+			return
+		}
+	}
+}
+
+func (r *receiver) dueMessages(blockHeight uint64) []*delayedMessage {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	sort.SliceStable(r.delayed, func(i, j int) bool {
+		return r.delayed[i].deliveryAt < r.delayed[j].deliveryAt
+	})
+
+	dueCount := 0
+	for dueCount < len(r.delayed) &&
+		r.delayed[dueCount].deliveryAt <= blockHeight {
+		dueCount++
+	}
+
+	due := r.delayed[:dueCount]
+	r.delayed = r.delayed[dueCount:]
+
+	return due
+}
@@ -0,0 +1,316 @@
+package faultnet
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/keep-network/keep-core/pkg/beacon/relay/group"
+	chainLocal "github.com/keep-network/keep-core/pkg/chain/local"
+	"github.com/keep-network/keep-core/pkg/net"
+	netLocal "github.com/keep-network/keep-core/pkg/net/local"
+)
+
+func TestFaultDeterministic(t *testing.T) {
+	config := Config{
+		Seed:                   42,
+		LossProbability:        0.3,
+		DuplicationProbability: 0.3,
+		ReorderProbability:     0.3,
+	}
+
+	network1 := NewNetwork(nil, nil, config)
+	network2 := NewNetwork(nil, nil, config)
+
+	config.Seed = 43
+	network3 := NewNetwork(nil, nil, config)
+
+	link := Link{Sender: 1, Receiver: 2}
+
+	var faults1, faults2, faults3 []fault
+	for occurrence := uint64(0); occurrence < 100; occurrence++ {
+		faults1 = append(faults1, network1.fault(link, "message", occurrence, 0))
+		faults2 = append(faults2, network2.fault(link, "message", occurrence, 0))
+		faults3 = append(faults3, network3.fault(link, "message", occurrence, 0))
+	}
+
+	if !reflect.DeepEqual(faults1, faults2) {
+		t.Errorf("expected the same faults for the same seed")
+	}
+	if reflect.DeepEqual(faults1, faults3) {
+		t.Errorf("expected different faults for different seeds")
+	}
+}
+
+func TestFault(t *testing.T) {
+	link := Link{Sender: 1, Receiver: 2}
+
+	var tests = map[string]struct {
+		config        Config
+		blockHeight   uint64
+		expectedFault fault
+	}{
+		"no faults": {
+			config:        Config{},
+			expectedFault: fault{},
+		},
+		"latency": {
+			config:        Config{Latency: 2},
+			expectedFault: fault{delay: 2},
+		},
+		"link latency": {
+			config: Config{
+				Latency:       2,
+				LinkLatencies: map[Link]uint64{link: 3},
+			},
+			expectedFault: fault{delay: 3},
+		},
+		"latency of another link": {
+			config: Config{
+				Latency:       2,
+				LinkLatencies: map[Link]uint64{{2, 1}: 3},
+			},
+			expectedFault: fault{delay: 2},
+		},
+		"loss": {
+			config:        Config{LossProbability: 1},
+			expectedFault: fault{lost: true},
+		},
+		"duplication": {
+			config:        Config{DuplicationProbability: 1},
+			expectedFault: fault{duplicated: true},
+		},
+		"reordering": {
+			config:        Config{Latency: 1, ReorderProbability: 1},
+			expectedFault: fault{delay: 2},
+		},
+		"active partition": {
+			config: Config{
+				Partitions: []Partition{{Members: []group.MemberIndex{1}}},
+			},
+			expectedFault: fault{lost: true},
+		},
+		"partition not separating the link": {
+			config: Config{
+				Partitions: []Partition{{Members: []group.MemberIndex{1, 2}}},
+			},
+			expectedFault: fault{},
+		},
+		"partition not active yet": {
+			config: Config{
+				Partitions: []Partition{{
+					Members:   []group.MemberIndex{2},
+					FromBlock: 5,
+				}},
+			},
+			blockHeight:   4,
+			expectedFault: fault{},
+		},
+		"healed partition": {
+			config: Config{
+				Partitions: []Partition{{
+					Members:   []group.MemberIndex{2},
+					FromBlock: 5,
+					ToBlock:   10,
+				}},
+			},
+			blockHeight:   10,
+			expectedFault: fault{},
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			network := NewNetwork(nil, nil, test.config)
+
+			actualFault := network.fault(link, "message", 0, test.blockHeight)
+			if actualFault != test.expectedFault {
+				t.Errorf(
+					"unexpected fault\nexpected: [%+v]\nactual:   [%+v]",
+					test.expectedFault,
+					actualFault,
+				)
+			}
+		})
+	}
+}
+
+func TestDeliverMessages(t *testing.T) {
+	var tests = map[string]struct {
+		config           Config
+		expectedMessages []string
+	}{
+		"no faults": {
+			config:           Config{},
+			expectedMessages: []string{"1", "2"},
+		},
+		"latency": {
+			config:           Config{Latency: 1},
+			expectedMessages: []string{"1", "2"},
+		},
+		"loss": {
+			config:           Config{LossProbability: 1},
+			expectedMessages: []string{},
+		},
+		"duplication": {
+			config:           Config{DuplicationProbability: 1},
+			expectedMessages: []string{"1", "1", "2", "2"},
+		},
+		"partition": {
+			config: Config{
+				Partitions: []Partition{{Members: []group.MemberIndex{2}}},
+			},
+			expectedMessages: []string{},
+		},
+	}
+
+	for testName, test := range tests {
+		testName, test := testName, test
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			blockCounter, err := chainLocal.BlockCounter()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			network := NewNetwork(netLocal.Connect(), blockCounter, test.config)
+			channelName := fmt.Sprintf("faultnet-test-%v", testName)
+
+			senderChannel := newTestChannel(t, network, 1, channelName)
+			receiverChannel := newTestChannel(t, network, 2, channelName)
+
+			ctx, cancel := context.WithTimeout(
+				context.Background(),
+				2*time.Second,
+			)
+			defer cancel()
+
+			var receivedMutex sync.Mutex
+			received := make([]string, 0)
+			receiverChannel.Recv(ctx, func(message net.Message) {
+				receivedMutex.Lock()
+				defer receivedMutex.Unlock()
+				received = append(
+					received,
+					message.Payload().(*testMessage).content,
+				)
+			})
+
+			for _, content := range []string{"1", "2"} {
+				err := senderChannel.Send(
+					ctx,
+					&testMessage{senderID: 1, content: content},
+				)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			<-ctx.Done()
+
+			receivedMutex.Lock()
+			defer receivedMutex.Unlock()
+			if !reflect.DeepEqual(test.expectedMessages, received) {
+				t.Errorf(
+					"unexpected messages\nexpected: [%v]\nactual:   [%v]",
+					test.expectedMessages,
+					received,
+				)
+			}
+		})
+	}
+}
+
+func TestDeliverDelayedMessagesAfterLatency(t *testing.T) {
+	blockCounter, err := chainLocal.BlockCounter()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	latency := uint64(2)
+	network := NewNetwork(
+		netLocal.Connect(),
+		blockCounter,
+		Config{Latency: latency},
+	)
+
+	senderChannel := newTestChannel(t, network, 1, "faultnet-test-latency")
+	receiverChannel := newTestChannel(t, network, 2, "faultnet-test-latency")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	deliveryHeight := make(chan uint64)
+	receiverChannel.Recv(ctx, func(message net.Message) {
+		height, _ := blockCounter.CurrentBlock()
+		deliveryHeight <- height
+	})
+
+	sendHeight, err := blockCounter.CurrentBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = senderChannel.Send(ctx, &testMessage{senderID: 1, content: "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case height := <-deliveryHeight:
+		if height < sendHeight+latency {
+			t.Errorf(
+				"message delivered too early\nexpected: [>=%v]\nactual:   [%v]",
+				sendHeight+latency,
+				height,
+			)
+		}
+	case <-ctx.Done():
+		t.Fatal("expected message not delivered")
+	}
+}
+
+func newTestChannel(
+	t *testing.T,
+	network *Network,
+	memberIndex group.MemberIndex,
+	name string,
+) net.BroadcastChannel {
+	channel, err := network.Provider(memberIndex).BroadcastChannelFor(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	channel.SetUnmarshaler(func() net.TaggedUnmarshaler {
+		return &testMessage{}
+	})
+
+	return channel
+}
+
+type testMessage struct {
+	senderID group.MemberIndex
+	content  string
+}
+
+func (tm *testMessage) SenderID() group.MemberIndex {
+	return tm.senderID
+}
+
+func (tm *testMessage) Type() string {
+	return "faultnet/test_message"
+}
+
+func (tm *testMessage) Marshal() ([]byte, error) {
+	return append([]byte{byte(tm.senderID)}, []byte(tm.content)...), nil
+}
+
+func (tm *testMessage) Unmarshal(bytes []byte) error {
+	tm.senderID = group.MemberIndex(bytes[0])
+	tm.content = string(bytes[1:])
+	return nil
+}
@@ -0,0 +1,544 @@
+// Package journal provides an append-only, structured journal of all protocol
+// executions the client takes part in. The journal is stored in the data
+// directory as a file with one JSON-encoded entry per line and is meant to be
+// used for post-mortems on missed relay entries and for slashing disputes.
+//
+// The journal is opened once by the client and the handle is passed to all
+// components recording entries. Components working on behalf of one of the
+// operators hosted by the client record entries through the operator's view
+// of the journal so that each entry identifies the operator it belongs to.
+// Entries recorded with a nil handle are discarded.
+//
+// The journal file is rotated once it grows over the configured size and only
+// the configured number of rotated files is retained.
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ipfs/go-log"
+
+	"github.com/keep-network/keep-core/pkg/chain"
+)
+
+var logger = log.Logger("keep-journal")
+
+// FileName is the name of the journal file in the data directory. Rotated
+// journal files have the number of the rotation appended, starting from 1 for
+// the most recent one, e.g. journal.jsonl.1.
+const FileName = "journal.jsonl"
+
+const (
+	// DefaultMaxFileSize is the default size in megabytes the journal file
+	// can grow to before it is rotated.
+	DefaultMaxFileSize = 100
+	// DefaultMaxFiles is the default number of rotated journal files retained
+	// next to the current journal file.
+	DefaultMaxFiles = 5
+)
+
+// recordedRetentionBlocks is the number of blocks for which keys of entries
+// with a phase are kept to skip recording the entries again. It is far longer
+// than any protocol execution whose phases can be replayed, e.g. a DKG
+// resumed from a checkpoint, lasts.
+const recordedRetentionBlocks = 5760
+
+// Config stores configuration of the journal file rotation.
+type Config struct {
+	// MaxFileSize is the size in megabytes the journal file can grow to
+	// before it is rotated. If not set, DefaultMaxFileSize is used.
+	MaxFileSize int
+	// MaxFiles is the number of rotated journal files retained next to the
+	// current journal file; older files are removed. If not set,
+	// DefaultMaxFiles is used.
+	MaxFiles int
+}
+
+// Types of journal entries.
+const (
+	GroupSelectionStarted  = "group_selection_started"
+	TicketSubmitted        = "ticket_submitted"
+	StateTransition        = "state_transition"
+	Accusation             = "accusation"
+	DKGResultVote          = "dkg_result_vote"
+	RelayEntryRequested    = "relay_entry_requested"
+	SignatureShareReceived = "signature_share_received"
+	SignatureShareRejected = "signature_share_rejected"
+	RelayEntrySubmitted    = "relay_entry_submitted"
+	ShadowTransaction      = "shadow_transaction"
+	ChainReorganization    = "chain_reorganization"
+)
+
+// Entry is a single record of the journal.
+type Entry struct {
+	Time time.Time `json:"time"`
+	Type string    `json:"type"`
+	// Operator is the address of the operator the entry is recorded for. It
+	// is set for entries recorded through an operator's view of the journal.
+	Operator string `json:"operator,omitempty"`
+	// BlockNumber is the block at which the recorded event happened. If not
+	// set when the entry is recorded, the current block is used.
+	BlockNumber uint64 `json:"block_number"`
+	// Seed is the hexadecimal representation of the group selection seed,
+	// without the 0x prefix. It is set for entries related to group selection
+	// and DKG.
+	Seed string `json:"seed,omitempty"`
+	// GroupPublicKey is the hexadecimal representation of the group public
+	// key, as registered on-chain, without the 0x prefix. It is set for
+	// entries related to relay entry signing and, once known, to DKG.
+	GroupPublicKey string `json:"group_public_key,omitempty"`
+	// MemberIndex is the index of the group member the entry is recorded
+	// for, if applicable. It is the index of the client's member for most
+	// entries and the index of the accusing or voting member for accusations
+	// and DKG result votes.
+	MemberIndex uint8 `json:"member_index,omitempty"`
+	// Phase is the protocol phase the entry is recorded for, if applicable.
+	// Entries with a phase are recorded at most once per execution, phase
+	// and member, so recording them again, e.g. when states of a DKG resumed
+	// from a checkpoint are replayed, has no effect.
+	Phase string `json:"phase,omitempty"`
+	// Details carries additional, entry type-specific information.
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// Journal is an append-only journal stored in a file.
+type Journal struct {
+	storage  *storage
+	operator string
+}
+
+// storage is the journal file shared by all views of the journal.
+type storage struct {
+	mutex        sync.Mutex
+	path         string
+	file         *os.File
+	size         int64
+	maxFileSize  int64
+	maxFiles     int
+	blockCounter chain.BlockCounter
+
+	// recorded holds keys of entries with a phase recorded in the last
+	// recordedRetentionBlocks blocks, along with their block numbers.
+	recorded map[string]uint64
+	// lastBlock is the highest block number of the recorded entries.
+	lastBlock uint64
+}
+
+// Open opens the journal stored in the given data directory, creating it if
+// it does not exist yet. The journal file is rotated according to the given
+// config. The block counter is used to determine the block number of entries
+// recorded without one.
+func Open(
+	dataDir string,
+	config Config,
+	blockCounter chain.BlockCounter,
+) (*Journal, error) {
+	maxFileSize := config.MaxFileSize
+	if maxFileSize == 0 {
+		maxFileSize = DefaultMaxFileSize
+	}
+	maxFiles := config.MaxFiles
+	if maxFiles == 0 {
+		maxFiles = DefaultMaxFiles
+	}
+
+	s := &storage{
+		path:         filepath.Join(dataDir, FileName),
+		maxFileSize:  int64(maxFileSize) * 1024 * 1024,
+		maxFiles:     maxFiles,
+		blockCounter: blockCounter,
+		recorded:     make(map[string]uint64),
+	}
+
+	paths, err := journalFiles(dataDir)
+	if err != nil {
+		logger.Warningf(
+			"could not list existing journal files; entries may be "+
+				"recorded again: [%v]",
+			err,
+		)
+	}
+	for _, path := range paths {
+		err := scanEntries(path, func(entry *Entry) {
+			if entry.Phase != "" {
+				s.remember(entry)
+			}
+		})
+		if err != nil {
+			logger.Warningf(
+				"could not read existing journal entries; entries may be "+
+					"recorded again: [%v]",
+				err,
+			)
+		}
+	}
+	s.forgetOld()
+
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+
+	return &Journal{storage: s}, nil
+}
+
+// open opens the current journal file for appending.
+func (s *storage) open() error {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("could not open journal file [%v]: [%v]", s.path, err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("could not stat journal file [%v]: [%v]", s.path, err)
+	}
+
+	s.file = file
+	s.size = info.Size()
+
+	return nil
+}
+
+// rotate closes the current journal file, shifts the numbers of rotated
+// files, removing the oldest one if there are too many of them, and opens
+// a new journal file.
+func (s *storage) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return fmt.Errorf("could not close journal file: [%v]", err)
+	}
+	s.file = nil
+
+	for number := s.maxFiles; number > 0; number-- {
+		err := os.Rename(rotatedPath(s.path, number-1), rotatedPath(s.path, number))
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("could not rotate journal file: [%v]", err)
+		}
+	}
+
+	return s.open()
+}
+
+// remember stores the key of the entry with a phase so that the entry is not
+// recorded again.
+func (s *storage) remember(entry *Entry) {
+	s.recorded[entry.key()] = entry.BlockNumber
+	if entry.BlockNumber > s.lastBlock {
+		s.lastBlock = entry.BlockNumber
+	}
+}
+
+// forgetOld removes keys of entries recorded more than recordedRetentionBlocks
+// blocks before the most recent entry.
+func (s *storage) forgetOld() {
+	if s.lastBlock <= recordedRetentionBlocks {
+		return
+	}
+
+	threshold := s.lastBlock - recordedRetentionBlocks
+	for key, blockNumber := range s.recorded {
+		if blockNumber < threshold {
+			delete(s.recorded, key)
+		}
+	}
+}
+
+// ForOperator returns a view of the journal recording entries on behalf of
+// the operator with the given address. The view shares the journal file with
+// the journal it has been created from. A view of a nil journal is nil.
+func (j *Journal) ForOperator(operator string) *Journal {
+	if j == nil {
+		return nil
+	}
+
+	return &Journal{
+		storage:  j.storage,
+		operator: operator,
+	}
+}
+
+// Enabled returns true if the journal records entries. It allows to skip
+// the preparation of entries which are not going to be recorded anyway.
+func (j *Journal) Enabled() bool {
+	return j != nil
+}
+
+// Record appends the given entry to the journal. If the time of the entry
+// is not set, the current time is used. If the journal is an operator's view,
+// the entry is recorded for that operator. Entries with a phase already recorded
+// for the same execution and member are skipped. Errors are logged and do not
+// interrupt the caller. Entries recorded with a nil journal are discarded.
+func (j *Journal) Record(entry *Entry) {
+	if j == nil {
+		return
+	}
+
+	if entry.Operator == "" {
+		entry.Operator = j.operator
+	}
+
+	j.storage.record(entry)
+}
+
+func (s *storage) record(entry *Entry) {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now().UTC()
+	}
+
+	if entry.BlockNumber == 0 && s.blockCounter != nil {
+		currentBlock, err := s.blockCounter.CurrentBlock()
+		if err != nil {
+			logger.Warningf(
+				"could not determine block of [%v] journal entry: [%v]",
+				entry.Type,
+				err,
+			)
+		} else {
+			entry.BlockNumber = currentBlock
+		}
+	}
+
+	entryBytes, err := json.Marshal(entry)
+	if err != nil {
+		logger.Errorf("could not marshal [%v] journal entry: [%v]", entry.Type, err)
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.file == nil {
+		return
+	}
+
+	if entry.Phase != "" {
+		if _, ok := s.recorded[entry.key()]; ok {
+			return
+		}
+	}
+
+	written, err := s.file.Write(append(entryBytes, '\n'))
+	s.size += int64(written)
+	if err != nil {
+		logger.Errorf("could not write [%v] journal entry: [%v]", entry.Type, err)
+		return
+	}
+
+	if entry.Phase != "" {
+		previousLastBlock := s.lastBlock
+		s.remember(entry)
+		// Keys are pruned at most once per retention period so that
+		// recording an entry does not iterate over all keys every time.
+		if s.lastBlock/recordedRetentionBlocks !=
+			previousLastBlock/recordedRetentionBlocks {
+			s.forgetOld()
+		}
+	}
+
+	if s.size >= s.maxFileSize {
+		if err := s.rotate(); err != nil {
+			logger.Errorf("could not rotate journal file: [%v]", err)
+		}
+	}
+}
+
+// key identifies the operator, execution, phase and member the entry is
+// recorded for.
+func (e *Entry) key() string {
+	return fmt.Sprintf(
+		"%v/%v/%v/%v/%v/%v",
+		strings.ToLower(e.Operator),
+		e.Type,
+		NormalizeSeed(e.Seed),
+		normalizeHex(e.GroupPublicKey),
+		e.Phase,
+		e.MemberIndex,
+	)
+}
+
+// Close flushes and closes the journal along with all its operator views.
+// Entries recorded after the journal is closed are discarded.
+func (j *Journal) Close() error {
+	s := j.storage
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+
+	if err := s.file.Sync(); err != nil {
+		return err
+	}
+
+	err := s.file.Close()
+	s.file = nil
+
+	return err
+}
+
+// Filter selects journal entries. Empty filter fields match all entries.
+type Filter struct {
+	Operator       string
+	Seed           string
+	GroupPublicKey string
+}
+
+// Matches checks whether the given entry is selected by the filter.
+func (f *Filter) Matches(entry *Entry) bool {
+	if f.Operator != "" &&
+		normalizeHex(entry.Operator) != normalizeHex(f.Operator) {
+		return false
+	}
+
+	if f.Seed != "" && NormalizeSeed(entry.Seed) != NormalizeSeed(f.Seed) {
+		return false
+	}
+
+	if f.GroupPublicKey != "" &&
+		normalizeHex(entry.GroupPublicKey) != normalizeHex(f.GroupPublicKey) {
+		return false
+	}
+
+	return true
+}
+
+// Read reads all entries of the journal stored in the given data directory,
+// including the retained rotated journal files, which are selected by the
+// filter, in the order they were recorded.
+func Read(dataDir string, filter *Filter) ([]*Entry, error) {
+	paths, err := journalFiles(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf(
+			"could not find journal file in [%v]",
+			dataDir,
+		)
+	}
+
+	entries := make([]*Entry, 0)
+	for _, path := range paths {
+		err := scanEntries(path, func(entry *Entry) {
+			if filter == nil || filter.Matches(entry) {
+				entries = append(entries, entry)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return entries, nil
+}
+
+// journalFiles returns paths of all journal files existing in the given data
+// directory, from the oldest rotated file to the current journal file.
+func journalFiles(dataDir string) ([]string, error) {
+	path := filepath.Join(dataDir, FileName)
+
+	rotated, err := filepath.Glob(path + ".*")
+	if err != nil {
+		return nil, fmt.Errorf("could not list rotated journal files: [%v]", err)
+	}
+
+	numbers := make([]int, 0, len(rotated))
+	for _, rotatedFile := range rotated {
+		number, err := strconv.Atoi(strings.TrimPrefix(rotatedFile, path+"."))
+		if err != nil || number <= 0 {
+			continue
+		}
+		numbers = append(numbers, number)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(numbers)))
+
+	paths := make([]string, 0, len(numbers)+1)
+	for _, number := range numbers {
+		paths = append(paths, rotatedPath(path, number))
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
+
+// rotatedPath returns the path of the journal file rotated the given number
+// of times; zero is the current journal file.
+func rotatedPath(path string, number int) string {
+	if number == 0 {
+		return path
+	}
+
+	return fmt.Sprintf("%v.%v", path, number)
+}
+
+// scanEntries reads the journal file line by line and passes each entry to
+// the handler, so that the whole journal does not have to be kept in memory.
+func scanEntries(path string, handle func(*Entry)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("could not open journal file [%v]: [%v]", path, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+
+		entry := &Entry{}
+		if err := json.Unmarshal(scanner.Bytes(), entry); err != nil {
+			return fmt.Errorf(
+				"could not unmarshal journal entry in line [%v]: [%v]",
+				line,
+				err,
+			)
+		}
+
+		handle(entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("could not read journal file: [%v]", err)
+	}
+
+	return nil
+}
+
+// SeedString returns the representation of the group selection seed used in
+// journal entries.
+func SeedString(seed *big.Int) string {
+	return seed.Text(16)
+}
+
+// NormalizeSeed brings the hexadecimal seed representation to the form used
+// in journal entries so that seeds with a 0x prefix or leading zeros can be
+// compared.
+func NormalizeSeed(seed string) string {
+	value, ok := new(big.Int).SetString(normalizeHex(seed), 16)
+	if !ok {
+		return normalizeHex(seed)
+	}
+
+	return SeedString(value)
+}
+
+func normalizeHex(value string) string {
+	return strings.TrimPrefix(strings.ToLower(value), "0x")
+}
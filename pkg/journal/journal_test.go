@@ -0,0 +1,387 @@
+package journal
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestRecordAndRead(t *testing.T) {
+	dataDir, err := ioutil.TempDir("", "journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dataDir)
+
+	journal, err := Open(dataDir, Config{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	journal.Record(&Entry{
+		Type:        GroupSelectionStarted,
+		BlockNumber: 10,
+		Seed:        SeedString(big.NewInt(0xabc)),
+	})
+	journal.Record(&Entry{
+		Type:        StateTransition,
+		BlockNumber: 20,
+		Seed:        SeedString(big.NewInt(0xabc)),
+		MemberIndex: 3,
+		Phase:       "*gjkr.ephemeralKeyPairGenerationState",
+	})
+	journal.Record(&Entry{
+		Type:           RelayEntryRequested,
+		BlockNumber:    30,
+		GroupPublicKey: "01ff",
+	})
+
+	if err := journal.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// entries recorded after the journal is closed are discarded
+	journal.Record(&Entry{Type: RelayEntrySubmitted, BlockNumber: 40})
+
+	var tests = map[string]struct {
+		filter         *Filter
+		expectedBlocks []uint64
+	}{
+		"no filter": {
+			filter:         nil,
+			expectedBlocks: []uint64{10, 20, 30},
+		},
+		"empty filter": {
+			filter:         &Filter{},
+			expectedBlocks: []uint64{10, 20, 30},
+		},
+		"seed filter": {
+			filter:         &Filter{Seed: "0x0ABC"},
+			expectedBlocks: []uint64{10, 20},
+		},
+		"group public key filter": {
+			filter:         &Filter{GroupPublicKey: "0x01FF"},
+			expectedBlocks: []uint64{30},
+		},
+		"no matching entries": {
+			filter:         &Filter{Seed: "def"},
+			expectedBlocks: []uint64{},
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			entries, err := Read(dataDir, test.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			blocks := make([]uint64, len(entries))
+			for i, entry := range entries {
+				blocks[i] = entry.BlockNumber
+
+				if entry.Time.IsZero() {
+					t.Errorf("time of entry [%v] is not set", i)
+				}
+			}
+
+			if !reflect.DeepEqual(test.expectedBlocks, blocks) {
+				t.Errorf(
+					"unexpected entries\nexpected: [%v]\nactual:   [%v]",
+					test.expectedBlocks,
+					blocks,
+				)
+			}
+		})
+	}
+
+	entries, err := Read(dataDir, &Filter{Seed: "abc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedPhase := "*gjkr.ephemeralKeyPairGenerationState"
+	if entries[1].Phase != expectedPhase {
+		t.Errorf(
+			"unexpected phase\nexpected: [%v]\nactual:   [%v]",
+			expectedPhase,
+			entries[1].Phase,
+		)
+	}
+	if entries[1].MemberIndex != 3 {
+		t.Errorf(
+			"unexpected member index\nexpected: [%v]\nactual:   [%v]",
+			3,
+			entries[1].MemberIndex,
+		)
+	}
+}
+
+func TestRecordPhaseOnce(t *testing.T) {
+	dataDir, err := ioutil.TempDir("", "journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dataDir)
+
+	accusation := func(accuser uint8, kind string) *Entry {
+		return &Entry{
+			Type:        Accusation,
+			BlockNumber: 10,
+			Seed:        "abc",
+			MemberIndex: accuser,
+			Phase:       kind,
+		}
+	}
+
+	journal, err := Open(dataDir, Config{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	journal.Record(accusation(1, "secret_shares"))
+	journal.Record(accusation(1, "secret_shares"))
+	journal.Record(accusation(2, "secret_shares"))
+	journal.Record(accusation(1, "public_key_share_points"))
+	journal.Record(&Entry{Type: RelayEntryRequested, BlockNumber: 20})
+	journal.Record(&Entry{Type: RelayEntryRequested, BlockNumber: 20})
+
+	if err := journal.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// entries recorded before the journal has been reopened are known
+	journal, err = Open(dataDir, Config{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	journal.Record(accusation(2, "secret_shares"))
+	journal.Record(&Entry{
+		Type:        Accusation,
+		BlockNumber: 10,
+		Seed:        "0x0ABC",
+		MemberIndex: 1,
+		Phase:       "public_key_share_points",
+	})
+	journal.Record(accusation(3, "secret_shares"))
+
+	if err := journal.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := Read(dataDir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var recorded []string
+	for _, entry := range entries {
+		recorded = append(
+			recorded,
+			fmt.Sprintf("%v:%v:%v", entry.Type, entry.Phase, entry.MemberIndex),
+		)
+	}
+
+	expected := []string{
+		"accusation:secret_shares:1",
+		"accusation:secret_shares:2",
+		"accusation:public_key_share_points:1",
+		"relay_entry_requested::0",
+		"relay_entry_requested::0",
+		"accusation:secret_shares:3",
+	}
+	if !reflect.DeepEqual(expected, recorded) {
+		t.Errorf(
+			"unexpected entries\nexpected: [%v]\nactual:   [%v]",
+			expected,
+			recorded,
+		)
+	}
+}
+
+func TestOperatorView(t *testing.T) {
+	dataDir, err := ioutil.TempDir("", "journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dataDir)
+
+	journal, err := Open(dataDir, Config{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	operator1 := journal.ForOperator("0xAAAA")
+	operator2 := journal.ForOperator("0xBBBB")
+
+	transition := func() *Entry {
+		return &Entry{
+			Type:        StateTransition,
+			BlockNumber: 10,
+			Seed:        "abc",
+			MemberIndex: 1,
+			Phase:       "*gjkr.ephemeralKeyPairGenerationState",
+		}
+	}
+
+	operator1.Record(transition())
+	operator1.Record(transition())
+	operator2.Record(transition())
+	journal.Record(&Entry{Type: RelayEntryRequested, BlockNumber: 20})
+
+	// closing the journal closes all its views
+	if err := journal.Close(); err != nil {
+		t.Fatal(err)
+	}
+	operator1.Record(&Entry{Type: RelayEntrySubmitted, BlockNumber: 30})
+
+	entries, err := Read(dataDir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var recorded []string
+	for _, entry := range entries {
+		recorded = append(
+			recorded,
+			fmt.Sprintf("%v:%v", entry.Operator, entry.Type),
+		)
+	}
+
+	expected := []string{
+		"0xAAAA:state_transition",
+		"0xBBBB:state_transition",
+		":relay_entry_requested",
+	}
+	if !reflect.DeepEqual(expected, recorded) {
+		t.Errorf(
+			"unexpected entries\nexpected: [%v]\nactual:   [%v]",
+			expected,
+			recorded,
+		)
+	}
+
+	entries, err = Read(dataDir, &Filter{Operator: "0xbbbb"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Operator != "0xBBBB" {
+		t.Errorf("unexpected entries of the operator: [%v]", entries)
+	}
+}
+
+func TestNilJournal(t *testing.T) {
+	var journal *Journal
+
+	if journal.Enabled() {
+		t.Errorf("expected nil journal to be disabled")
+	}
+
+	if journal.ForOperator("0xAAAA") != nil {
+		t.Errorf("expected view of nil journal to be nil")
+	}
+
+	// does not panic
+	journal.Record(&Entry{Type: RelayEntryRequested})
+}
+
+func TestRotation(t *testing.T) {
+	dataDir, err := ioutil.TempDir("", "journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dataDir)
+
+	journal, err := Open(dataDir, Config{MaxFiles: 2}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// rotate after every entry
+	journal.storage.maxFileSize = 1
+
+	for block := uint64(1); block <= 5; block++ {
+		journal.Record(&Entry{Type: RelayEntryRequested, BlockNumber: block})
+	}
+
+	if err := journal.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	paths, err := journalFiles(dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the current journal file is empty after the last rotation
+	if len(paths) != 3 {
+		t.Errorf("unexpected journal files: [%v]", paths)
+	}
+
+	entries, err := Read(dataDir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blocks := make([]uint64, len(entries))
+	for i, entry := range entries {
+		blocks[i] = entry.BlockNumber
+	}
+
+	expectedBlocks := []uint64{4, 5}
+	if !reflect.DeepEqual(expectedBlocks, blocks) {
+		t.Errorf(
+			"unexpected entries\nexpected: [%v]\nactual:   [%v]",
+			expectedBlocks,
+			blocks,
+		)
+	}
+}
+
+func TestRecordedKeysRetention(t *testing.T) {
+	dataDir, err := ioutil.TempDir("", "journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dataDir)
+
+	transition := func(seed string, block uint64) *Entry {
+		return &Entry{
+			Type:        StateTransition,
+			BlockNumber: block,
+			Seed:        seed,
+			MemberIndex: 1,
+			Phase:       "*gjkr.ephemeralKeyPairGenerationState",
+		}
+	}
+
+	journal, err := Open(dataDir, Config{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	journal.Record(transition("abc", 10))
+	journal.Record(transition("def", recordedRetentionBlocks+20))
+
+	if err := journal.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	journal, err = Open(dataDir, Config{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := journal.storage.recorded[transition("abc", 10).key()]; ok {
+		t.Errorf("expected key of the old entry to be forgotten")
+	}
+	if _, ok := journal.storage.recorded[transition("def", 0).key()]; !ok {
+		t.Errorf("expected key of the recent entry to be remembered")
+	}
+
+	if err := journal.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,117 @@
+package metrics
+
+import "fmt"
+
+// Ethereum holds metrics of Ethereum endpoints the client is connected to,
+// partitioned by the endpoint name, and of transactions the client submits,
+// partitioned by the submitting operator and the transaction type.
+type Ethereum struct {
+	// EndpointRequests counts requests sent to Ethereum endpoints.
+	EndpointRequests *Counter
+
+	// EndpointErrors counts requests which failed because of the
+	// endpoint, not because they were rejected by the Ethereum node.
+	EndpointErrors *Counter
+
+	// EndpointFailovers counts failovers by the endpoint the client
+	// failed over to.
+	EndpointFailovers *Counter
+
+	// EndpointBlockLag observes the number of blocks the endpoint
+	// lags behind the most advanced endpoint.
+	EndpointBlockLag *LabeledGauge
+
+	// EndpointLatencySeconds observes the latency of the last health
+	// probe of the endpoint.
+	EndpointLatencySeconds *LabeledGauge
+
+	// EndpointHealthy observes the endpoint health; 1 if the
+	// endpoint is healthy, 0 otherwise.
+	EndpointHealthy *LabeledGauge
+
+	// EndpointActive observes which endpoint is in use; 1 if the
+	// endpoint is in use, 0 otherwise.
+	EndpointActive *LabeledGauge
+
+	// Transactions counts submitted transactions.
+	Transactions *Counter
+
+	// TransactionGasPriceGwei observes the gas price of the last
+	// submitted transaction.
+	TransactionGasPriceGwei *LabeledGauge
+
+	// TransactionCostGwei counts the cost of mined transactions, that is
+	// the gas used by them multiplied by their gas price.
+	TransactionCostGwei *Counter
+}
+
+// NewEthereum creates metrics of Ethereum endpoints and transactions and
+// registers them in the given registry.
+func NewEthereum(registry *Registry) (*Ethereum, error) {
+	builder := &metricsBuilder{registry: registry}
+
+	ethereumMetrics := &Ethereum{
+		EndpointRequests: builder.counter(
+			"ethereum_endpoint_requests_total",
+			"Number of requests sent to the Ethereum endpoint.",
+			"endpoint",
+		),
+		EndpointErrors: builder.counter(
+			"ethereum_endpoint_errors_total",
+			"Number of requests failed because of the Ethereum endpoint.",
+			"endpoint",
+		),
+		EndpointFailovers: builder.counter(
+			"ethereum_endpoint_failovers_total",
+			"Number of failovers to the Ethereum endpoint.",
+			"endpoint",
+		),
+		EndpointBlockLag: builder.labeledGauge(
+			"ethereum_endpoint_block_lag",
+			"Number of blocks the Ethereum endpoint lags behind the most advanced one.",
+			"endpoint",
+		),
+		EndpointLatencySeconds: builder.labeledGauge(
+			"ethereum_endpoint_latency_seconds",
+			"Latency of the last health probe of the Ethereum endpoint.",
+			"endpoint",
+		),
+		EndpointHealthy: builder.labeledGauge(
+			"ethereum_endpoint_healthy",
+			"Ethereum endpoint health; 1 if healthy, 0 otherwise.",
+			"endpoint",
+		),
+		EndpointActive: builder.labeledGauge(
+			"ethereum_endpoint_active",
+			"Ethereum endpoint usage; 1 if in use, 0 otherwise.",
+			"endpoint",
+		),
+		Transactions: builder.counter(
+			"ethereum_transactions_total",
+			"Number of submitted transactions.",
+			"operator",
+			"type",
+		),
+		TransactionGasPriceGwei: builder.labeledGauge(
+			"ethereum_transaction_gas_price_gwei",
+			"Gas price of the last submitted transaction in gwei.",
+			"operator",
+			"type",
+		),
+		TransactionCostGwei: builder.counter(
+			"ethereum_transaction_cost_gwei_total",
+			"Cost of mined transactions in gwei.",
+			"operator",
+			"type",
+		),
+	}
+
+	if builder.err != nil {
+		return nil, fmt.Errorf(
+			"could not create Ethereum metrics: [%v]",
+			builder.err,
+		)
+	}
+
+	return ethereumMetrics, nil
+}
@@ -5,7 +5,6 @@ import (
 	"time"
 
 	"github.com/ipfs/go-log"
-	"github.com/keep-network/keep-common/pkg/metrics"
 	"github.com/keep-network/keep-core/pkg/chain"
 	"github.com/keep-network/keep-core/pkg/net"
 )
@@ -21,28 +20,28 @@ const (
 	DefaultEthereumMetricsTick = 10 * time.Minute
 )
 
-// Initialize set up the metrics registry and enables metrics server.
+// Initialize enables the metrics server exposing metrics of the given
+// registry, if the metrics port is configured.
 func Initialize(
+	registry *Registry,
 	port int,
-) (*metrics.Registry, bool) {
+) bool {
 	if port == 0 {
-		return nil, false
+		return false
 	}
 
-	registry := metrics.NewRegistry()
-
 	registry.EnableServer(port)
 
-	return registry, true
+	return true
 }
 
 // ObserveConnectedPeersCount triggers an observation process of the
 // connected_peers_count metric.
 func ObserveConnectedPeersCount(
 	ctx context.Context,
-	registry *metrics.Registry,
+	registry *Registry,
 	netProvider net.Provider,
-	tick time.Duration,
+	tick *Tick,
 ) {
 	input := func() float64 {
 		connectedPeers := netProvider.ConnectionManager().ConnectedPeers()
@@ -52,25 +51,27 @@ func ObserveConnectedPeersCount(
 	observe(
 		ctx,
 		"connected_peers_count",
+		"Number of connected peers.",
 		input,
 		registry,
-		validateTick(tick, DefaultNetworkMetricsTick),
+		tick,
 	)
 }
 
 // ObserveConnectedBootstrapCount triggers an observation process of the
-// connected_bootstrap_count metric.
+// connected_bootstrap_count metric. The provided function returns addresses of
+// the bootstrap peers currently configured.
 func ObserveConnectedBootstrapCount(
 	ctx context.Context,
-	registry *metrics.Registry,
+	registry *Registry,
 	netProvider net.Provider,
-	bootstraps []string,
-	tick time.Duration,
+	bootstraps func() []string,
+	tick *Tick,
 ) {
 	input := func() float64 {
 		currentCount := 0
 
-		for _, address := range bootstraps {
+		for _, address := range bootstraps() {
 			if netProvider.ConnectionManager().IsConnected(address) {
 				currentCount++
 			}
@@ -82,9 +83,10 @@ func ObserveConnectedBootstrapCount(
 	observe(
 		ctx,
 		"connected_bootstrap_count",
+		"Number of connected bootstrap peers.",
 		input,
 		registry,
-		validateTick(tick, DefaultNetworkMetricsTick),
+		tick,
 	)
 }
 
@@ -92,10 +94,10 @@ func ObserveConnectedBootstrapCount(
 // eth_connectivity metric.
 func ObserveEthConnectivity(
 	ctx context.Context,
-	registry *metrics.Registry,
+	registry *Registry,
 	stakeMonitor chain.StakeMonitor,
 	address string,
-	tick time.Duration,
+	tick *Tick,
 ) {
 	input := func() float64 {
 		_, err := stakeMonitor.HasMinimumStake(address)
@@ -110,20 +112,45 @@ func ObserveEthConnectivity(
 	observe(
 		ctx,
 		"eth_connectivity",
+		"Ethereum client connectivity; 1 if connected, 0 otherwise.",
+		input,
+		registry,
+		tick,
+	)
+}
+
+// ObserveActiveGroupsCount triggers an observation process of the
+// active_groups_count metric. The provided source returns the number of
+// groups the client is a member of.
+func ObserveActiveGroupsCount(
+	ctx context.Context,
+	registry *Registry,
+	groupsCount func() int,
+	tick *Tick,
+) {
+	input := func() float64 {
+		return float64(groupsCount())
+	}
+
+	observe(
+		ctx,
+		"active_groups_count",
+		"Number of active groups the client is a member of.",
 		input,
 		registry,
-		validateTick(tick, DefaultEthereumMetricsTick),
+		tick,
 	)
 }
 
 func observe(
 	ctx context.Context,
 	name string,
-	input metrics.ObserverInput,
-	registry *metrics.Registry,
-	tick time.Duration,
+	help string,
+	input func() float64,
+	registry *Registry,
+	tick *Tick,
 ) {
-	observer, err := registry.NewGaugeObserver(name, input)
+	observer, err := registry.NewGaugeObserver(name, help, input)
 	if err != nil {
 		logger.Warningf("could not create gauge observer [%v]", name)
 		return
@@ -132,10 +159,46 @@ func observe(
 	observer.Observe(ctx, tick)
 }
 
-func validateTick(tick time.Duration, defaultTick time.Duration) time.Duration {
-	if tick > 0 {
-		return tick
-	}
+// metricsBuilder creates metrics in the registry and keeps the first error
+// encountered so that a collection of metrics can be created at once.
+type metricsBuilder struct {
+	registry *Registry
+	err      error
+}
+
+func (mb *metricsBuilder) counter(
+	name string,
+	help string,
+	labelNames ...string,
+) *Counter {
+	counter, err := mb.registry.NewCounter(name, help, labelNames...)
+	mb.keep(err)
+	return counter
+}
+
+func (mb *metricsBuilder) labeledGauge(
+	name string,
+	help string,
+	labelNames ...string,
+) *LabeledGauge {
+	gauge, err := mb.registry.NewLabeledGauge(name, help, labelNames...)
+	mb.keep(err)
+	return gauge
+}
+
+func (mb *metricsBuilder) histogram(
+	name string,
+	help string,
+	buckets []float64,
+	labelNames ...string,
+) *Histogram {
+	histogram, err := mb.registry.NewHistogram(name, help, buckets, labelNames...)
+	mb.keep(err)
+	return histogram
+}
 
-	return defaultTick
+func (mb *metricsBuilder) keep(err error) {
+	if err != nil && mb.err == nil {
+		mb.err = err
+	}
 }
@@ -0,0 +1,52 @@
+package metrics
+
+import "fmt"
+
+// Network holds metrics of messages the client exchanges through broadcast
+// channels, partitioned by the message type.
+type Network struct {
+	// BroadcastBytesSent counts bytes of broadcast message envelopes
+	// published by the client, including retransmissions.
+	BroadcastBytesSent *Counter
+
+	// BroadcastBytesReceived counts bytes of broadcast message envelopes
+	// of known types received from other peers.
+	BroadcastBytesReceived *Counter
+
+	// BroadcastMessagesCompressed counts broadcast messages published with
+	// compressed payloads.
+	BroadcastMessagesCompressed *Counter
+}
+
+// NewNetwork creates metrics of broadcast channels and registers them in the
+// given registry.
+func NewNetwork(registry *Registry) (*Network, error) {
+	builder := &metricsBuilder{registry: registry}
+
+	networkMetrics := &Network{
+		BroadcastBytesSent: builder.counter(
+			"network_broadcast_bytes_sent_total",
+			"Number of bytes of broadcast messages sent, including retransmissions.",
+			"type",
+		),
+		BroadcastBytesReceived: builder.counter(
+			"network_broadcast_bytes_received_total",
+			"Number of bytes of broadcast messages received from other peers.",
+			"type",
+		),
+		BroadcastMessagesCompressed: builder.counter(
+			"network_broadcast_messages_compressed_total",
+			"Number of broadcast messages sent with compressed payloads.",
+			"type",
+		),
+	}
+
+	if builder.err != nil {
+		return nil, fmt.Errorf(
+			"could not create network metrics: [%v]",
+			builder.err,
+		)
+	}
+
+	return networkMetrics, nil
+}
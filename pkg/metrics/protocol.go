@@ -0,0 +1,254 @@
+package metrics
+
+import "fmt"
+
+// Outcomes of group selections.
+const (
+	GroupSelectionSelected    = "selected"
+	GroupSelectionNotSelected = "not_selected"
+)
+
+// Reasons of DKG execution failures.
+const (
+	DKGFailureAborted           = "aborted"
+	DKGFailureKeyGeneration     = "key_generation"
+	DKGFailureResultPublication = "result_publication"
+)
+
+// Kinds of misbehaviour detected during DKG.
+const (
+	MisbehaviourDisqualified = "disqualified"
+	MisbehaviourInactive     = "inactive"
+)
+
+// Protocol holds metrics of the random beacon protocols, partitioned by the
+// operator on behalf of which the protocols are executed. The protocol
+// implementations update metrics of their operator obtained with ForOperator.
+type Protocol struct {
+	// TicketsGenerated counts tickets generated by the client's stakers
+	// in all group selections.
+	TicketsGenerated *Counter
+
+	// TicketsSubmitted counts tickets successfully submitted on-chain
+	// in all group selections.
+	TicketsSubmitted *Counter
+
+	// TicketsSkipped counts candidate tickets the ticket submission
+	// optimizer decided not to submit because their expected reward did not
+	// cover their submission cost.
+	TicketsSkipped *Counter
+
+	// TicketsExpectedRewardGwei accumulates the expected reward of tickets
+	// submitted with the ticket submission optimizer enabled.
+	TicketsExpectedRewardGwei *Counter
+
+	// TicketsExpectedCostGwei accumulates the expected cost of tickets
+	// submitted with the ticket submission optimizer enabled.
+	TicketsExpectedCostGwei *Counter
+
+	// GroupSelections counts completed group selections by their outcome
+	// for the client's staker.
+	GroupSelections *Counter
+
+	// DKGStarted counts DKG executions started or resumed by the client's
+	// members.
+	DKGStarted *Counter
+
+	// DKGSucceeded counts DKG executions which produced a group member
+	// able to operate in the group.
+	DKGSucceeded *Counter
+
+	// DKGFailed counts failed DKG executions by the failure reason.
+	DKGFailed *Counter
+
+	// DKGMisbehavingMembers counts group members found to be misbehaving,
+	// by the GJKR phase in which the misbehaviour has been detected and
+	// by the kind of misbehaviour.
+	DKGMisbehavingMembers *Counter
+
+	// SignatureSharesReceived counts signature share messages received from
+	// other group members during relay entry signing.
+	SignatureSharesReceived *Counter
+
+	// SignatureSharesAccepted counts received signature shares which turned
+	// out to be valid.
+	SignatureSharesAccepted *Counter
+
+	// SignatureSharesRejected counts received signature shares which turned
+	// out to be invalid.
+	SignatureSharesRejected *Counter
+
+	// RelayEntryDurationSeconds observes the time from the moment the client
+	// confirmed a relay request until the relay entry is submitted on-chain.
+	RelayEntryDurationSeconds *Histogram
+
+	// RelayEntryDurationBlocks observes the number of blocks from a relay
+	// request until the relay entry is submitted on-chain.
+	RelayEntryDurationBlocks *Histogram
+
+	// GroupBlocksUntilExpiration tracks the number of blocks left until each
+	// group the client is a member of expires, by the group public key
+	// prefix. It is negative for expired groups not archived yet.
+	GroupBlocksUntilExpiration *LabeledGauge
+
+	// GroupLastSigningBlock tracks the start block of the last relay entry
+	// signing completed by the client's members of each group, by the group
+	// public key prefix.
+	GroupLastSigningBlock *LabeledGauge
+
+	// GroupsArchived counts stale groups archived by the registry.
+	GroupsArchived *Counter
+
+	// RelayEntryTimeoutsReported counts relay entry timeouts reported
+	// on-chain by the client.
+	RelayEntryTimeoutsReported *Counter
+}
+
+// NewProtocol creates metrics of the random beacon protocols and registers
+// them in the given registry.
+func NewProtocol(registry *Registry) (*Protocol, error) {
+	builder := &metricsBuilder{registry: registry}
+
+	protocol := &Protocol{
+		TicketsGenerated: builder.counter(
+			"group_selection_tickets_generated_total",
+			"Number of tickets generated in group selections.",
+			"operator",
+		),
+		TicketsSubmitted: builder.counter(
+			"group_selection_tickets_submitted_total",
+			"Number of tickets submitted on-chain in group selections.",
+			"operator",
+		),
+		TicketsSkipped: builder.counter(
+			"group_selection_tickets_skipped_total",
+			"Number of candidate tickets skipped by the ticket optimizer.",
+			"operator",
+		),
+		TicketsExpectedRewardGwei: builder.counter(
+			"group_selection_tickets_expected_reward_gwei_total",
+			"Expected reward in gwei of tickets submitted in group selections.",
+			"operator",
+		),
+		TicketsExpectedCostGwei: builder.counter(
+			"group_selection_tickets_expected_cost_gwei_total",
+			"Expected cost in gwei of tickets submitted in group selections.",
+			"operator",
+		),
+		GroupSelections: builder.counter(
+			"group_selections_total",
+			"Number of completed group selections by outcome.",
+			"operator",
+			"outcome",
+		),
+		DKGStarted: builder.counter(
+			"dkg_executions_started_total",
+			"Number of DKG executions started.",
+			"operator",
+		),
+		DKGSucceeded: builder.counter(
+			"dkg_executions_succeeded_total",
+			"Number of DKG executions completed successfully.",
+			"operator",
+		),
+		DKGFailed: builder.counter(
+			"dkg_executions_failed_total",
+			"Number of failed DKG executions by reason.",
+			"operator",
+			"reason",
+		),
+		DKGMisbehavingMembers: builder.counter(
+			"dkg_misbehaving_members_total",
+			"Number of misbehaving members detected by GJKR phase and kind.",
+			"operator",
+			"phase",
+			"kind",
+		),
+		SignatureSharesReceived: builder.counter(
+			"relay_signature_shares_received_total",
+			"Number of signature shares received.",
+			"operator",
+		),
+		SignatureSharesAccepted: builder.counter(
+			"relay_signature_shares_accepted_total",
+			"Number of valid signature shares received.",
+			"operator",
+		),
+		SignatureSharesRejected: builder.counter(
+			"relay_signature_shares_rejected_total",
+			"Number of invalid signature shares received.",
+			"operator",
+		),
+		RelayEntryDurationSeconds: builder.histogram(
+			"relay_entry_duration_seconds",
+			"Time from a confirmed relay request until the relay entry submission.",
+			[]float64{15, 30, 60, 120, 300, 600, 1200, 2400},
+			"operator",
+		),
+		RelayEntryDurationBlocks: builder.histogram(
+			"relay_entry_duration_blocks",
+			"Blocks from a relay request until the relay entry submission.",
+			[]float64{1, 2, 3, 5, 10, 20, 50, 100},
+			"operator",
+		),
+		GroupBlocksUntilExpiration: builder.labeledGauge(
+			"registry_group_blocks_until_expiration",
+			"Blocks left until the group expires by group.",
+			"operator",
+			"group",
+		),
+		GroupLastSigningBlock: builder.labeledGauge(
+			"registry_group_last_signing_block",
+			"Start block of the last relay entry signing by group.",
+			"operator",
+			"group",
+		),
+		GroupsArchived: builder.counter(
+			"registry_groups_archived_total",
+			"Number of stale groups archived.",
+			"operator",
+		),
+		RelayEntryTimeoutsReported: builder.counter(
+			"relay_entry_timeouts_reported_total",
+			"Number of relay entry timeouts reported on-chain.",
+			"operator",
+		),
+	}
+
+	if builder.err != nil {
+		return nil, fmt.Errorf(
+			"could not create protocol metrics: [%v]",
+			builder.err,
+		)
+	}
+
+	return protocol, nil
+}
+
+// ForOperator returns a view of the metrics of the random beacon protocols
+// executed on behalf of the operator with the given address. The view shares
+// values with the metrics it has been created from; all its updates are
+// attributed to the operator.
+func (p *Protocol) ForOperator(operator string) *Protocol {
+	return &Protocol{
+		TicketsGenerated:           p.TicketsGenerated.With(operator),
+		TicketsSubmitted:           p.TicketsSubmitted.With(operator),
+		TicketsSkipped:             p.TicketsSkipped.With(operator),
+		TicketsExpectedRewardGwei:  p.TicketsExpectedRewardGwei.With(operator),
+		TicketsExpectedCostGwei:    p.TicketsExpectedCostGwei.With(operator),
+		GroupSelections:            p.GroupSelections.With(operator),
+		DKGStarted:                 p.DKGStarted.With(operator),
+		DKGSucceeded:               p.DKGSucceeded.With(operator),
+		DKGFailed:                  p.DKGFailed.With(operator),
+		DKGMisbehavingMembers:      p.DKGMisbehavingMembers.With(operator),
+		SignatureSharesReceived:    p.SignatureSharesReceived.With(operator),
+		SignatureSharesAccepted:    p.SignatureSharesAccepted.With(operator),
+		SignatureSharesRejected:    p.SignatureSharesRejected.With(operator),
+		RelayEntryDurationSeconds:  p.RelayEntryDurationSeconds.With(operator),
+		RelayEntryDurationBlocks:   p.RelayEntryDurationBlocks.With(operator),
+		GroupBlocksUntilExpiration: p.GroupBlocksUntilExpiration.With(operator),
+		GroupLastSigningBlock:      p.GroupLastSigningBlock.With(operator),
+		GroupsArchived:             p.GroupsArchived.With(operator),
+		RelayEntryTimeoutsReported: p.RelayEntryTimeoutsReported.With(operator),
+	}
+}
@@ -0,0 +1,484 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Registry holds all metrics of the client and exposes them through the
+// metrics server in the Prometheus text-based exposition format. It is backed
+// by the Prometheus client registry and follows the API of the keep-common
+// metrics registry, creating and registering metrics in one step. It extends
+// that API with counters, histograms and gauges partitioned by labels, and
+// with observation ticks which can be changed at runtime. All metrics of the
+// client are created through the registry passed to their users explicitly.
+type Registry struct {
+	registry *prometheus.Registry
+}
+
+// NewRegistry creates a new metrics registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		registry: prometheus.NewRegistry(),
+	}
+}
+
+// Gather collects all registered metrics, the same as they are exposed
+// through the metrics server.
+func (r *Registry) Gather() ([]*dto.MetricFamily, error) {
+	return r.registry.Gather()
+}
+
+// EnableServer enables the metrics server on the given port. Data will
+// be exposed on `/metrics` path.
+func (r *Registry) EnableServer(port int) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(
+		r.registry,
+		promhttp.HandlerOpts{ErrorLog: promhttpLogger{}},
+	))
+
+	server := &http.Server{Addr: ":" + strconv.Itoa(port), Handler: mux}
+
+	go func() {
+		if err := server.ListenAndServe(); err != http.ErrServerClosed {
+			logger.Errorf("metrics server error: [%v]", err)
+		}
+	}()
+}
+
+// promhttpLogger logs errors of the metrics server handler.
+type promhttpLogger struct{}
+
+func (promhttpLogger) Println(v ...interface{}) {
+	logger.Error(v...)
+}
+
+// register adds the metric collector to the registry. In case a metric with
+// the same name already exists, an error is returned.
+func (r *Registry) register(
+	metricName string,
+	collector prometheus.Collector,
+) error {
+	if err := r.registry.Register(collector); err != nil {
+		return fmt.Errorf(
+			"could not register metric [%v]: [%v]",
+			metricName,
+			err,
+		)
+	}
+
+	return nil
+}
+
+// NewGauge creates and registers a new gauge metric which will be exposed
+// through the metrics server. In case a metric already exists, an error
+// will be returned.
+func (r *Registry) NewGauge(gaugeName, help string) (*Gauge, error) {
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: gaugeName,
+		Help: help,
+	})
+
+	if err := r.register(gaugeName, gauge); err != nil {
+		return nil, err
+	}
+
+	return &Gauge{gauge: gauge}, nil
+}
+
+// NewGaugeObserver creates and registers a gauge just like `NewGauge` method
+// and wraps it with a ready to use observer of the provided input. This allows
+// to easily create self-refreshing metrics.
+func (r *Registry) NewGaugeObserver(
+	gaugeName string,
+	help string,
+	input func() float64,
+) (*Observer, error) {
+	gauge, err := r.NewGauge(gaugeName, help)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Observer{
+		input:  input,
+		output: gauge,
+	}, nil
+}
+
+// Observer represents a definition of a cyclic gauge observation process.
+type Observer struct {
+	input  func() float64
+	output *Gauge
+}
+
+// Observe triggers a cyclic metric observation process which stops as soon
+// as the provided context is done. Changes of the tick duration are applied
+// immediately.
+func (o *Observer) Observe(ctx context.Context, tick *Tick) {
+	go func() {
+		o.output.Set(o.input()) // execute the first check immediately
+
+		for {
+			duration, changed := tick.current()
+			timer := time.NewTimer(duration)
+
+			select {
+			case <-timer.C:
+				o.output.Set(o.input())
+			case <-changed:
+				timer.Stop()
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Tick is the duration between subsequent observations of a metric. The tick
+// can be shared by multiple observers and changed while they are running.
+type Tick struct {
+	defaultDuration time.Duration
+
+	mutex    sync.Mutex
+	duration time.Duration
+	changed  chan struct{}
+}
+
+// NewTick creates a new observation tick of the given duration. If the
+// duration is not positive, the default duration is used instead.
+func NewTick(duration time.Duration, defaultDuration time.Duration) *Tick {
+	tick := &Tick{
+		defaultDuration: defaultDuration,
+		changed:         make(chan struct{}),
+	}
+	tick.Set(duration)
+
+	return tick
+}
+
+// Set changes the duration of the tick. If the duration is not positive, the
+// default duration is used instead. Observers using the tick start waiting
+// for the new duration immediately.
+func (t *Tick) Set(duration time.Duration) {
+	if duration <= 0 {
+		duration = t.defaultDuration
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if duration == t.duration {
+		return
+	}
+
+	t.duration = duration
+
+	close(t.changed)
+	t.changed = make(chan struct{})
+}
+
+// Duration returns the current duration of the tick.
+func (t *Tick) Duration() time.Duration {
+	duration, _ := t.current()
+	return duration
+}
+
+// current returns the current duration of the tick along with the channel
+// closed once the duration changes.
+func (t *Tick) current() (time.Duration, <-chan struct{}) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.duration, t.changed
+}
+
+// Gauge is a metric type that represents a single numerical value that can
+// arbitrarily go up and down.
+type Gauge struct {
+	gauge prometheus.Gauge
+}
+
+// Set allows setting the gauge to an arbitrary value.
+func (g *Gauge) Set(value float64) {
+	g.gauge.Set(value)
+}
+
+// NewCounter creates and registers a new counter with the given name,
+// description and names of labels partitioning the counter. In case a metric
+// already exists, an error will be returned.
+func (r *Registry) NewCounter(
+	counterName string,
+	help string,
+	labelNames ...string,
+) (*Counter, error) {
+	vec := prometheus.NewCounterVec(
+		prometheus.CounterOpts{Name: counterName, Help: help},
+		labelNames,
+	)
+
+	if err := r.register(counterName, vec); err != nil {
+		return nil, err
+	}
+
+	// Metrics without labels are exposed from the start, just like metrics
+	// created without a vector.
+	if len(labelNames) == 0 {
+		vec.WithLabelValues()
+	}
+
+	return &Counter{
+		metricName: counterName,
+		labelNames: labelNames,
+		vec:        vec,
+	}, nil
+}
+
+// NewLabeledGauge creates and registers a new gauge with the given name,
+// description and names of labels partitioning the gauge. In case a metric
+// already exists, an error will be returned.
+func (r *Registry) NewLabeledGauge(
+	gaugeName string,
+	help string,
+	labelNames ...string,
+) (*LabeledGauge, error) {
+	vec := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{Name: gaugeName, Help: help},
+		labelNames,
+	)
+
+	if err := r.register(gaugeName, vec); err != nil {
+		return nil, err
+	}
+
+	return &LabeledGauge{
+		metricName: gaugeName,
+		labelNames: labelNames,
+		vec:        vec,
+	}, nil
+}
+
+// NewHistogram creates and registers a new histogram with the given name,
+// description, upper bounds of buckets and names of labels partitioning the
+// histogram. In case a metric already exists, an error will be returned.
+func (r *Registry) NewHistogram(
+	histogramName string,
+	help string,
+	buckets []float64,
+	labelNames ...string,
+) (*Histogram, error) {
+	sortedBuckets := append([]float64{}, buckets...)
+	sort.Float64s(sortedBuckets)
+
+	vec := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    histogramName,
+			Help:    help,
+			Buckets: sortedBuckets,
+		},
+		labelNames,
+	)
+
+	if err := r.register(histogramName, vec); err != nil {
+		return nil, err
+	}
+
+	if len(labelNames) == 0 {
+		vec.WithLabelValues()
+	}
+
+	return &Histogram{
+		metricName: histogramName,
+		labelNames: labelNames,
+		vec:        vec,
+	}, nil
+}
+
+// Counter is a metric type that represents a cumulative value that only
+// increases. Counter can be partitioned by labels; values of all labels have
+// to be provided each time the counter is increased, unless they have been
+// fixed with With.
+type Counter struct {
+	metricName string
+	labelNames []string
+
+	// fixedValues are values of the leading labels fixed with With.
+	fixedValues []string
+	vec         *prometheus.CounterVec
+}
+
+// With returns a view of the counter with values of the leading labels
+// fixed to the given ones. The view shares values with the counter, so it
+// does not have to be registered separately.
+func (c *Counter) With(labelValues ...string) *Counter {
+	return &Counter{
+		metricName:  c.metricName,
+		labelNames:  c.labelNames,
+		fixedValues: appendValues(c.fixedValues, labelValues),
+		vec:         c.vec,
+	}
+}
+
+// Inc increases the counter by one for the given label values.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add increases the counter by the given non-negative value for the given
+// label values.
+func (c *Counter) Add(value float64, labelValues ...string) {
+	if value < 0 {
+		logger.Warningf(
+			"counter [%v] can not be decreased by [%v]",
+			c.metricName,
+			value,
+		)
+		return
+	}
+
+	values, ok := labelValuesOf(
+		"counter",
+		c.metricName,
+		c.labelNames,
+		c.fixedValues,
+		labelValues,
+	)
+	if !ok {
+		return
+	}
+
+	c.vec.WithLabelValues(values...).Add(value)
+}
+
+// LabeledGauge is a gauge partitioned by labels; values of all labels have to
+// be provided each time the gauge is set, unless they have been fixed with
+// With.
+type LabeledGauge struct {
+	metricName string
+	labelNames []string
+
+	// fixedValues are values of the leading labels fixed with With.
+	fixedValues []string
+	vec         *prometheus.GaugeVec
+}
+
+// With returns a view of the gauge with values of the leading labels fixed
+// to the given ones. The view shares values with the gauge, so it does not
+// have to be registered separately.
+func (lg *LabeledGauge) With(labelValues ...string) *LabeledGauge {
+	return &LabeledGauge{
+		metricName:  lg.metricName,
+		labelNames:  lg.labelNames,
+		fixedValues: appendValues(lg.fixedValues, labelValues),
+		vec:         lg.vec,
+	}
+}
+
+// Set sets the gauge to an arbitrary value for the given label values.
+func (lg *LabeledGauge) Set(value float64, labelValues ...string) {
+	values, ok := labelValuesOf(
+		"gauge",
+		lg.metricName,
+		lg.labelNames,
+		lg.fixedValues,
+		labelValues,
+	)
+	if !ok {
+		return
+	}
+
+	lg.vec.WithLabelValues(values...).Set(value)
+}
+
+// Delete removes the gauge value for the given label values so that it is no
+// longer exposed.
+func (lg *LabeledGauge) Delete(labelValues ...string) {
+	values := appendValues(lg.fixedValues, labelValues)
+	if len(values) != len(lg.labelNames) {
+		return
+	}
+
+	lg.vec.DeleteLabelValues(values...)
+}
+
+// Histogram is a metric type that samples observations and counts them in
+// configurable buckets. It also provides the sum of all observed values.
+// Histogram can be partitioned by labels; values of all labels have to be
+// provided with each observation, unless they have been fixed with With.
+type Histogram struct {
+	metricName string
+	labelNames []string
+
+	// fixedValues are values of the leading labels fixed with With.
+	fixedValues []string
+	vec         *prometheus.HistogramVec
+}
+
+// With returns a view of the histogram with values of the leading labels
+// fixed to the given ones. The view shares observations with the histogram,
+// so it does not have to be registered separately.
+func (h *Histogram) With(labelValues ...string) *Histogram {
+	return &Histogram{
+		metricName:  h.metricName,
+		labelNames:  h.labelNames,
+		fixedValues: appendValues(h.fixedValues, labelValues),
+		vec:         h.vec,
+	}
+}
+
+// Observe adds a single observation to the histogram for the given label
+// values.
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	values, ok := labelValuesOf(
+		"histogram",
+		h.metricName,
+		h.labelNames,
+		h.fixedValues,
+		labelValues,
+	)
+	if !ok {
+		return
+	}
+
+	h.vec.WithLabelValues(values...).Observe(value)
+}
+
+// labelValuesOf returns the fixed label values of the metric followed by the
+// given ones. The Prometheus client panics on a mismatched number of label
+// values so it is checked upfront; false is returned and a warning is logged
+// if the number of values does not match the number of label names.
+func labelValuesOf(
+	metricType string,
+	metricName string,
+	labelNames []string,
+	fixedValues []string,
+	labelValues []string,
+) ([]string, bool) {
+	values := appendValues(fixedValues, labelValues)
+	if len(values) != len(labelNames) {
+		logger.Warningf(
+			"%v [%v] expects [%v] label values, has [%v]",
+			metricType,
+			metricName,
+			len(labelNames),
+			len(values),
+		)
+		return nil, false
+	}
+
+	return values, true
+}
+
+func appendValues(values []string, more []string) []string {
+	return append(append([]string{}, values...), more...)
+}
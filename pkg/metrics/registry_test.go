@@ -0,0 +1,291 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCounterExpose(t *testing.T) {
+	counter, err := NewRegistry().NewCounter(
+		"failures_total",
+		"Number of failures.",
+		"reason",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counter.Inc("timeout")
+	counter.Inc("aborted")
+	counter.Add(2, "timeout")
+
+	// mismatched label values and negative values are ignored
+	counter.Inc()
+	counter.Add(-1, "timeout")
+
+	expected := "# HELP failures_total Number of failures.\n" +
+		"# TYPE failures_total counter\n" +
+		"failures_total{reason=\"aborted\"} 1\n" +
+		"failures_total{reason=\"timeout\"} 3\n"
+
+	assertExposition(t, counter.vec, expected)
+}
+
+func TestLabeledGaugeExpose(t *testing.T) {
+	gauge, err := NewRegistry().NewLabeledGauge("block_lag", "Block lag.", "endpoint")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gauge.Set(4, "node-2")
+	gauge.Set(1, "node-1")
+	gauge.Set(0, "node-2")
+
+	// mismatched label values are ignored
+	gauge.Set(7)
+
+	expected := "# HELP block_lag Block lag.\n" +
+		"# TYPE block_lag gauge\n" +
+		"block_lag{endpoint=\"node-1\"} 1\n" +
+		"block_lag{endpoint=\"node-2\"} 0\n"
+
+	assertExposition(t, gauge.vec, expected)
+}
+
+func TestLabeledGaugeDelete(t *testing.T) {
+	gauge, err := NewRegistry().NewLabeledGauge("block_lag", "Block lag.", "endpoint")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gauge.Set(4, "node-2")
+	gauge.Set(1, "node-1")
+	gauge.Delete("node-2")
+
+	expected := "# HELP block_lag Block lag.\n" +
+		"# TYPE block_lag gauge\n" +
+		"block_lag{endpoint=\"node-1\"} 1\n"
+
+	assertExposition(t, gauge.vec, expected)
+}
+
+func TestHistogramExpose(t *testing.T) {
+	histogram, err := NewRegistry().NewHistogram(
+		"duration_blocks",
+		"Duration in blocks.",
+		[]float64{5, 1},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	histogram.Observe(1)
+	histogram.Observe(3)
+	histogram.Observe(8)
+
+	expected := "# HELP duration_blocks Duration in blocks.\n" +
+		"# TYPE duration_blocks histogram\n" +
+		"duration_blocks_bucket{le=\"1\"} 1\n" +
+		"duration_blocks_bucket{le=\"5\"} 2\n" +
+		"duration_blocks_bucket{le=\"+Inf\"} 3\n" +
+		"duration_blocks_sum 12\n" +
+		"duration_blocks_count 3\n"
+
+	assertExposition(t, histogram.vec, expected)
+}
+
+func TestCounterWith(t *testing.T) {
+	counter, err := NewRegistry().NewCounter(
+		"failures_total",
+		"Number of failures.",
+		"operator",
+		"reason",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counter.With("0xAAAA").Inc("timeout")
+	counter.With("0xBBBB").Add(2, "timeout")
+	counter.With("0xAAAA", "aborted").Inc()
+	counter.Inc("0xAAAA", "timeout")
+
+	// mismatched label values are ignored
+	counter.With("0xAAAA").Inc()
+
+	expected := "# HELP failures_total Number of failures.\n" +
+		"# TYPE failures_total counter\n" +
+		"failures_total{operator=\"0xAAAA\",reason=\"aborted\"} 1\n" +
+		"failures_total{operator=\"0xAAAA\",reason=\"timeout\"} 2\n" +
+		"failures_total{operator=\"0xBBBB\",reason=\"timeout\"} 2\n"
+
+	assertExposition(t, counter.vec, expected)
+}
+
+func TestLabeledHistogramExpose(t *testing.T) {
+	histogram, err := NewRegistry().NewHistogram(
+		"duration_blocks",
+		"Duration in blocks.",
+		[]float64{5},
+		"operator",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	histogram.With("0xBBBB").Observe(8)
+	histogram.With("0xAAAA").Observe(3)
+	histogram.Observe(1, "0xAAAA")
+
+	// mismatched label values are ignored
+	histogram.Observe(1)
+
+	expected := "# HELP duration_blocks Duration in blocks.\n" +
+		"# TYPE duration_blocks histogram\n" +
+		"duration_blocks_bucket{operator=\"0xAAAA\",le=\"5\"} 2\n" +
+		"duration_blocks_bucket{operator=\"0xAAAA\",le=\"+Inf\"} 2\n" +
+		"duration_blocks_sum{operator=\"0xAAAA\"} 4\n" +
+		"duration_blocks_count{operator=\"0xAAAA\"} 2\n" +
+		"duration_blocks_bucket{operator=\"0xBBBB\",le=\"5\"} 0\n" +
+		"duration_blocks_bucket{operator=\"0xBBBB\",le=\"+Inf\"} 1\n" +
+		"duration_blocks_sum{operator=\"0xBBBB\"} 8\n" +
+		"duration_blocks_count{operator=\"0xBBBB\"} 1\n"
+
+	assertExposition(t, histogram.vec, expected)
+}
+
+func TestRegistryExposeMetrics(t *testing.T) {
+	registry := NewRegistry()
+
+	gauge, err := registry.NewGauge("peers_count", "Number of peers.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gauge.Set(7)
+
+	counter, err := registry.NewCounter("entries_total", "Number of entries.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	counter.Inc()
+
+	if _, err := registry.NewGauge("peers_count", "Duplicate."); err == nil {
+		t.Errorf("expected error on duplicate metric name")
+	}
+
+	expected := "# HELP entries_total Number of entries.\n" +
+		"# TYPE entries_total counter\n" +
+		"entries_total 1\n" +
+		"# HELP peers_count Number of peers.\n" +
+		"# TYPE peers_count gauge\n" +
+		"peers_count 7\n"
+
+	err = testutil.GatherAndCompare(
+		registry.registry,
+		strings.NewReader(expected),
+	)
+	if err != nil {
+		t.Errorf("unexpected exposition: [%v]", err)
+	}
+}
+
+func TestProtocolForOperator(t *testing.T) {
+	registry := NewRegistry()
+
+	protocolMetrics, err := NewProtocol(registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	protocolMetrics.ForOperator("0xAAAA").DKGStarted.Inc()
+	protocolMetrics.ForOperator("0xBBBB").DKGStarted.Inc()
+	protocolMetrics.ForOperator("0xAAAA").DKGFailed.Inc(DKGFailureAborted)
+
+	value := testutil.ToFloat64(
+		protocolMetrics.DKGStarted.vec.WithLabelValues("0xAAAA"),
+	)
+	if value != 1 {
+		t.Errorf(
+			"unexpected value\nexpected: [%v]\nactual:   [%v]",
+			1,
+			value,
+		)
+	}
+
+	expected := "# HELP dkg_executions_failed_total " +
+		"Number of failed DKG executions by reason.\n" +
+		"# TYPE dkg_executions_failed_total counter\n" +
+		"dkg_executions_failed_total{operator=\"0xAAAA\",reason=\"aborted\"} 1\n"
+	assertExposition(t, protocolMetrics.DKGFailed.vec, expected)
+
+	if _, err := NewProtocol(registry); err == nil {
+		t.Errorf("expected error on metrics registered twice")
+	}
+}
+
+func TestTickSet(t *testing.T) {
+	tick := NewTick(0, time.Minute)
+	if tick.Duration() != time.Minute {
+		t.Errorf("unexpected default duration: [%v]", tick.Duration())
+	}
+
+	tick.Set(time.Second)
+	if tick.Duration() != time.Second {
+		t.Errorf("unexpected duration: [%v]", tick.Duration())
+	}
+
+	tick.Set(-1)
+	if tick.Duration() != time.Minute {
+		t.Errorf("unexpected duration: [%v]", tick.Duration())
+	}
+}
+
+func TestObserverAppliesTickChange(t *testing.T) {
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	defer cancelCtx()
+
+	observations := make(chan struct{}, 10)
+	observer, err := NewRegistry().NewGaugeObserver(
+		"observations",
+		"Number of observations.",
+		func() float64 {
+			observations <- struct{}{}
+			return 1
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tick := NewTick(time.Hour, time.Hour)
+	observer.Observe(ctx, tick)
+
+	// the first observation is executed immediately
+	<-observations
+
+	tick.Set(10 * time.Millisecond)
+
+	select {
+	case <-observations:
+	case <-time.After(5 * time.Second):
+		t.Fatal("tick change has not been applied")
+	}
+}
+
+func assertExposition(
+	t *testing.T,
+	collector prometheus.Collector,
+	expected string,
+) {
+	t.Helper()
+
+	err := testutil.CollectAndCompare(collector, strings.NewReader(expected))
+	if err != nil {
+		t.Errorf("unexpected exposition: [%v]", err)
+	}
+}
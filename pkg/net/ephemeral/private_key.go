@@ -54,6 +54,16 @@ func UnmarshalPrivateKey(bytes []byte) *PrivateKey {
 	return (*PrivateKey)(priv)
 }
 
+// UnmarshalKeyPair turns a slice of bytes representing a private key into
+// a `KeyPair`.
+func UnmarshalKeyPair(bytes []byte) *KeyPair {
+	priv, pub := btcec.PrivKeyFromBytes(curve(), bytes)
+	return &KeyPair{
+		(*PrivateKey)(priv),
+		(*PublicKey)(pub),
+	}
+}
+
 // UnmarshalPublicKey turns a slice of bytes into a `PublicKey`.
 func UnmarshalPublicKey(bytes []byte) (*PublicKey, error) {
 	pubKey, err := btcec.ParsePubKey(bytes, curve())
@@ -37,6 +37,19 @@ func TestMarshalUnmarshalPrivateKey(t *testing.T) {
 	}
 }
 
+func TestUnmarshalKeyPair(t *testing.T) {
+	keyPair, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unmarshalled := UnmarshalKeyPair(keyPair.PrivateKey.Marshal())
+
+	if !reflect.DeepEqual(unmarshalled, keyPair) {
+		t.Fatal("unmarshalled key pair does not match the original one")
+	}
+}
+
 func TestIsKeyMatching(t *testing.T) {
 	keyPair1, err := GenerateKeyPair()
 	if err != nil {
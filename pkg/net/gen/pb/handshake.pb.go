@@ -100,6 +100,9 @@ type Act1Message struct {
 	Nonce []byte `protobuf:"bytes,1,opt,name=nonce,proto3" json:"nonce,omitempty"`
 	// the identifier of the protocol the initiator is executing
 	Protocol string `protobuf:"bytes,2,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	// marshalled delegation of the initiator's network key by the operator;
+	// empty if the network key is the operator key
+	Delegation []byte `protobuf:"bytes,3,opt,name=delegation,proto3" json:"delegation,omitempty"`
 }
 
 func (m *Act1Message) Reset()      { *m = Act1Message{} }
@@ -148,6 +151,13 @@ func (m *Act1Message) GetProtocol() string {
 	return ""
 }
 
+func (m *Act1Message) GetDelegation() []byte {
+	if m != nil {
+		return m.Delegation
+	}
+	return nil
+}
+
 // Act2Message is sent in the second handshake act by the responder to the
 // initiator. It contains randomly generated `nonce2`, an 8-byte unsigned
 // integer and `challenge` which is a result of SHA256 on the concatenated
@@ -161,6 +171,9 @@ type Act2Message struct {
 	Challenge []byte `protobuf:"bytes,2,opt,name=challenge,proto3" json:"challenge,omitempty"`
 	// the identifier of the protocol the responder is executing
 	Protocol string `protobuf:"bytes,3,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	// marshalled delegation of the responder's network key by the operator;
+	// empty if the network key is the operator key
+	Delegation []byte `protobuf:"bytes,4,opt,name=delegation,proto3" json:"delegation,omitempty"`
 }
 
 func (m *Act2Message) Reset()      { *m = Act2Message{} }
@@ -216,6 +229,13 @@ func (m *Act2Message) GetProtocol() string {
 	return ""
 }
 
+func (m *Act2Message) GetDelegation() []byte {
+	if m != nil {
+		return m.Delegation
+	}
+	return nil
+}
+
 // Act1Message is sent in the first handshake act by the initiator to the
 // responder. It contains randomly generated `nonce1`, an 8-byte (64-bit)
 // unsigned integer.
@@ -275,24 +295,25 @@ func init() {
 func init() { proto.RegisterFile("pb/handshake.proto", fileDescriptor_73dffe19bde0f856) }
 
 var fileDescriptor_73dffe19bde0f856 = []byte{
-	// 262 bytes of a gzipped FileDescriptorProto
+	// 281 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x12, 0x2a, 0x48, 0xd2, 0xcf,
 	0x48, 0xcc, 0x4b, 0x29, 0xce, 0x48, 0xcc, 0x4e, 0xd5, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62,
 	0xce, 0x4b, 0x2d, 0x51, 0x4a, 0xe6, 0x12, 0xf4, 0x80, 0x89, 0xbb, 0xe6, 0x95, 0xa5, 0xe6, 0xe4,
 	0x17, 0xa4, 0x0a, 0x49, 0x70, 0xb1, 0xe7, 0xa6, 0x16, 0x17, 0x27, 0xa6, 0xa7, 0x4a, 0x30, 0x2a,
 	0x30, 0x6a, 0xf0, 0x04, 0xc1, 0xb8, 0x42, 0x32, 0x5c, 0x9c, 0xc5, 0x99, 0xe9, 0x79, 0x89, 0x25,
 	0xa5, 0x45, 0xa9, 0x12, 0x4c, 0x60, 0x39, 0x84, 0x80, 0x90, 0x18, 0x17, 0x5b, 0x41, 0x6a, 0x6a,
-	0x91, 0xa7, 0x8b, 0x04, 0x33, 0x58, 0x0a, 0xca, 0x53, 0xb2, 0xe7, 0xe2, 0x76, 0x4c, 0x2e, 0x31,
+	0x91, 0xa7, 0x8b, 0x04, 0x33, 0x58, 0x0a, 0xca, 0x53, 0x8a, 0xe7, 0xe2, 0x76, 0x4c, 0x2e, 0x31,
 	0xf4, 0x85, 0x1a, 0x22, 0xc2, 0xc5, 0x9a, 0x97, 0x9f, 0x97, 0x0c, 0x33, 0x1c, 0xc2, 0x11, 0x92,
-	0xe2, 0xe2, 0x00, 0xbb, 0x2b, 0x39, 0x3f, 0x07, 0x6c, 0x32, 0x67, 0x10, 0x9c, 0xaf, 0x14, 0x0b,
-	0x36, 0xc0, 0x08, 0xbf, 0x01, 0x32, 0x5c, 0x9c, 0xc9, 0x19, 0x89, 0x39, 0x39, 0xa9, 0x79, 0xe9,
-	0x70, 0xb7, 0xc1, 0x05, 0x50, 0x8c, 0x67, 0x46, 0x33, 0x5e, 0x1b, 0x6c, 0xbc, 0xb1, 0x2f, 0xc2,
-	0x93, 0x08, 0x83, 0x18, 0xd1, 0x0c, 0x72, 0xb2, 0xb8, 0xf0, 0x50, 0x8e, 0xe1, 0xc6, 0x43, 0x39,
-	0x86, 0x0f, 0x0f, 0xe5, 0x18, 0x1b, 0x1e, 0xc9, 0x31, 0xae, 0x78, 0x24, 0xc7, 0x78, 0xe2, 0x91,
-	0x1c, 0xe3, 0x85, 0x47, 0x72, 0x8c, 0x0f, 0x1e, 0xc9, 0x31, 0xbe, 0x78, 0x24, 0xc7, 0xf0, 0xe1,
-	0x91, 0x1c, 0xe3, 0x84, 0xc7, 0x72, 0x0c, 0x17, 0x1e, 0xcb, 0x31, 0xdc, 0x78, 0x2c, 0xc7, 0x10,
-	0xc5, 0x54, 0x90, 0x94, 0xc4, 0x06, 0xb6, 0xd0, 0x18, 0x10, 0x00, 0x00, 0xff, 0xff, 0xa1, 0x59,
-	0x1f, 0x3f, 0x8d, 0x01, 0x00, 0x00,
+	0xe2, 0xe2, 0x00, 0xbb, 0x2b, 0x39, 0x3f, 0x07, 0x6c, 0x32, 0x67, 0x10, 0x9c, 0x2f, 0x24, 0xc7,
+	0xc5, 0x95, 0x92, 0x9a, 0x93, 0x9a, 0x9e, 0x58, 0x92, 0x99, 0x9f, 0x07, 0x35, 0x1c, 0x49, 0x44,
+	0xa9, 0x16, 0x6c, 0x81, 0x11, 0x7e, 0x0b, 0x64, 0xb8, 0x38, 0x93, 0x33, 0x12, 0x73, 0x72, 0x52,
+	0xf3, 0xd2, 0xe1, 0x6e, 0x87, 0x0b, 0xa0, 0x58, 0xcf, 0x8c, 0xd7, 0x7a, 0x16, 0x0c, 0xeb, 0xb5,
+	0xc1, 0xd6, 0x1b, 0xfb, 0x22, 0x02, 0x09, 0x61, 0x11, 0x23, 0x9a, 0x45, 0x4e, 0x16, 0x17, 0x1e,
+	0xca, 0x31, 0xdc, 0x78, 0x28, 0xc7, 0xf0, 0xe1, 0xa1, 0x1c, 0x63, 0xc3, 0x23, 0x39, 0xc6, 0x15,
+	0x8f, 0xe4, 0x18, 0x4f, 0x3c, 0x92, 0x63, 0xbc, 0xf0, 0x48, 0x8e, 0xf1, 0xc1, 0x23, 0x39, 0xc6,
+	0x17, 0x8f, 0xe4, 0x18, 0x3e, 0x3c, 0x92, 0x63, 0x9c, 0xf0, 0x58, 0x8e, 0xe1, 0xc2, 0x63, 0x39,
+	0x86, 0x1b, 0x8f, 0xe5, 0x18, 0xa2, 0x98, 0x0a, 0x92, 0x92, 0xd8, 0xc0, 0x0e, 0x32, 0x06, 0x0c,
+	0x00, 0x1d, 0x0f, 0xcf, 0x18, 0xcd, 0x01, 0x00, 0x00,
 }
 
 func (this *HandshakeEnvelope) Equal(that interface{}) bool {
@@ -350,6 +371,9 @@ func (this *Act1Message) Equal(that interface{}) bool {
 	if this.Protocol != that1.Protocol {
 		return false
 	}
+	if !bytes.Equal(this.Delegation, that1.Delegation) {
+		return false
+	}
 	return true
 }
 func (this *Act2Message) Equal(that interface{}) bool {
@@ -380,6 +404,9 @@ func (this *Act2Message) Equal(that interface{}) bool {
 	if this.Protocol != that1.Protocol {
 		return false
 	}
+	if !bytes.Equal(this.Delegation, that1.Delegation) {
+		return false
+	}
 	return true
 }
 func (this *Act3Message) Equal(that interface{}) bool {
@@ -422,10 +449,11 @@ func (this *Act1Message) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 6)
+	s := make([]string, 0, 7)
 	s = append(s, "&pb.Act1Message{")
 	s = append(s, "Nonce: "+fmt.Sprintf("%#v", this.Nonce)+",\n")
 	s = append(s, "Protocol: "+fmt.Sprintf("%#v", this.Protocol)+",\n")
+	s = append(s, "Delegation: "+fmt.Sprintf("%#v", this.Delegation)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -433,11 +461,12 @@ func (this *Act2Message) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 7)
+	s := make([]string, 0, 8)
 	s = append(s, "&pb.Act2Message{")
 	s = append(s, "Nonce: "+fmt.Sprintf("%#v", this.Nonce)+",\n")
 	s = append(s, "Challenge: "+fmt.Sprintf("%#v", this.Challenge)+",\n")
 	s = append(s, "Protocol: "+fmt.Sprintf("%#v", this.Protocol)+",\n")
+	s = append(s, "Delegation: "+fmt.Sprintf("%#v", this.Delegation)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -523,6 +552,13 @@ func (m *Act1Message) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.Delegation) > 0 {
+		i -= len(m.Delegation)
+		copy(dAtA[i:], m.Delegation)
+		i = encodeVarintHandshake(dAtA, i, uint64(len(m.Delegation)))
+		i--
+		dAtA[i] = 0x1a
+	}
 	if len(m.Protocol) > 0 {
 		i -= len(m.Protocol)
 		copy(dAtA[i:], m.Protocol)
@@ -560,6 +596,13 @@ func (m *Act2Message) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.Delegation) > 0 {
+		i -= len(m.Delegation)
+		copy(dAtA[i:], m.Delegation)
+		i = encodeVarintHandshake(dAtA, i, uint64(len(m.Delegation)))
+		i--
+		dAtA[i] = 0x22
+	}
 	if len(m.Protocol) > 0 {
 		i -= len(m.Protocol)
 		copy(dAtA[i:], m.Protocol)
@@ -660,6 +703,10 @@ func (m *Act1Message) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovHandshake(uint64(l))
 	}
+	l = len(m.Delegation)
+	if l > 0 {
+		n += 1 + l + sovHandshake(uint64(l))
+	}
 	return n
 }
 
@@ -681,6 +728,10 @@ func (m *Act2Message) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovHandshake(uint64(l))
 	}
+	l = len(m.Delegation)
+	if l > 0 {
+		n += 1 + l + sovHandshake(uint64(l))
+	}
 	return n
 }
 
@@ -722,6 +773,7 @@ func (this *Act1Message) String() string {
 	s := strings.Join([]string{`&Act1Message{`,
 		`Nonce:` + fmt.Sprintf("%v", this.Nonce) + `,`,
 		`Protocol:` + fmt.Sprintf("%v", this.Protocol) + `,`,
+		`Delegation:` + fmt.Sprintf("%v", this.Delegation) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -734,6 +786,7 @@ func (this *Act2Message) String() string {
 		`Nonce:` + fmt.Sprintf("%v", this.Nonce) + `,`,
 		`Challenge:` + fmt.Sprintf("%v", this.Challenge) + `,`,
 		`Protocol:` + fmt.Sprintf("%v", this.Protocol) + `,`,
+		`Delegation:` + fmt.Sprintf("%v", this.Delegation) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -1006,6 +1059,40 @@ func (m *Act1Message) Unmarshal(dAtA []byte) error {
 			}
 			m.Protocol = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Delegation", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowHandshake
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthHandshake
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthHandshake
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Delegation = append(m.Delegation[:0], dAtA[iNdEx:postIndex]...)
+			if m.Delegation == nil {
+				m.Delegation = []byte{}
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipHandshake(dAtA[iNdEx:])
@@ -1159,6 +1246,40 @@ func (m *Act2Message) Unmarshal(dAtA []byte) error {
 			}
 			m.Protocol = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Delegation", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowHandshake
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthHandshake
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthHandshake
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Delegation = append(m.Delegation[:0], dAtA[iNdEx:postIndex]...)
+			if m.Delegation == nil {
+				m.Delegation = []byte{}
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipHandshake(dAtA[iNdEx:])
@@ -37,6 +37,12 @@ type BroadcastNetworkMessage struct {
 	// Sequence number of the message. Retransmissions have the same sequence
 	// number as the original message.
 	SequenceNumber uint64 `protobuf:"varint,4,opt,name=sequenceNumber,proto3" json:"sequenceNumber,omitempty"`
+	// Version of the envelope. Clients not aware of envelope versions leave
+	// it unset. Envelopes of version 1 may carry compressed payloads.
+	Version uint32 `protobuf:"varint,5,opt,name=version,proto3" json:"version,omitempty"`
+	// True if the payload is compressed with DEFLATE. Can be set only if the
+	// envelope version supports compression.
+	Compressed bool `protobuf:"varint,6,opt,name=compressed,proto3" json:"compressed,omitempty"`
 }
 
 func (m *BroadcastNetworkMessage) Reset()      { *m = BroadcastNetworkMessage{} }
@@ -99,6 +105,20 @@ func (m *BroadcastNetworkMessage) GetSequenceNumber() uint64 {
 	return 0
 }
 
+func (m *BroadcastNetworkMessage) GetVersion() uint32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *BroadcastNetworkMessage) GetCompressed() bool {
+	if m != nil {
+		return m.Compressed
+	}
+	return false
+}
+
 // UnicastNetworkMessage represents a network message used by unicast
 // channels.
 type UnicastNetworkMessage struct {
@@ -174,6 +194,9 @@ func (m *UnicastNetworkMessage) GetSignature() []byte {
 
 type Identity struct {
 	PubKey []byte `protobuf:"bytes,1,opt,name=pub_key,json=pubKey,proto3" json:"pub_key,omitempty"`
+	// Marshaled NetworkKeyDelegation of the network key. Empty if the network
+	// key is the operator key.
+	Delegation []byte `protobuf:"bytes,2,opt,name=delegation,proto3" json:"delegation,omitempty"`
 }
 
 func (m *Identity) Reset()      { *m = Identity{} }
@@ -215,33 +238,135 @@ func (m *Identity) GetPubKey() []byte {
 	return nil
 }
 
+func (m *Identity) GetDelegation() []byte {
+	if m != nil {
+		return m.Delegation
+	}
+	return nil
+}
+
+// NetworkKeyDelegation authorizes a network key to represent an operator in
+// the network. It is signed with the operator key.
+type NetworkKeyDelegation struct {
+	// The PublicKey of the delegated network key.
+	NetworkPublicKey []byte `protobuf:"bytes,1,opt,name=networkPublicKey,proto3" json:"networkPublicKey,omitempty"`
+	// The PublicKey of the operator delegating to the network key.
+	OperatorPublicKey []byte `protobuf:"bytes,2,opt,name=operatorPublicKey,proto3" json:"operatorPublicKey,omitempty"`
+	// Operator's signature over the network public key, the sequence number
+	// and the expiration time.
+	Signature []byte `protobuf:"bytes,3,opt,name=signature,proto3" json:"signature,omitempty"`
+	// Sequence number of the delegation. A delegation with a higher sequence
+	// number issued by the same operator invalidates delegations with lower
+	// sequence numbers.
+	Sequence uint64 `protobuf:"varint,4,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	// Time after which the delegation is no longer valid, in seconds since
+	// the Unix epoch.
+	ExpiresAt uint64 `protobuf:"varint,5,opt,name=expiresAt,proto3" json:"expiresAt,omitempty"`
+}
+
+func (m *NetworkKeyDelegation) Reset()      { *m = NetworkKeyDelegation{} }
+func (*NetworkKeyDelegation) ProtoMessage() {}
+func (*NetworkKeyDelegation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8447775385e7eb85, []int{3}
+}
+func (m *NetworkKeyDelegation) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *NetworkKeyDelegation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_NetworkKeyDelegation.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *NetworkKeyDelegation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_NetworkKeyDelegation.Merge(m, src)
+}
+func (m *NetworkKeyDelegation) XXX_Size() int {
+	return m.Size()
+}
+func (m *NetworkKeyDelegation) XXX_DiscardUnknown() {
+	xxx_messageInfo_NetworkKeyDelegation.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_NetworkKeyDelegation proto.InternalMessageInfo
+
+func (m *NetworkKeyDelegation) GetNetworkPublicKey() []byte {
+	if m != nil {
+		return m.NetworkPublicKey
+	}
+	return nil
+}
+
+func (m *NetworkKeyDelegation) GetOperatorPublicKey() []byte {
+	if m != nil {
+		return m.OperatorPublicKey
+	}
+	return nil
+}
+
+func (m *NetworkKeyDelegation) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+func (m *NetworkKeyDelegation) GetSequence() uint64 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+func (m *NetworkKeyDelegation) GetExpiresAt() uint64 {
+	if m != nil {
+		return m.ExpiresAt
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*BroadcastNetworkMessage)(nil), "net.BroadcastNetworkMessage")
 	proto.RegisterType((*UnicastNetworkMessage)(nil), "net.UnicastNetworkMessage")
 	proto.RegisterType((*Identity)(nil), "net.Identity")
+	proto.RegisterType((*NetworkKeyDelegation)(nil), "net.NetworkKeyDelegation")
 }
 
 func init() { proto.RegisterFile("pb/message.proto", fileDescriptor_8447775385e7eb85) }
 
 var fileDescriptor_8447775385e7eb85 = []byte{
-	// 272 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x90, 0xb1, 0x4a, 0x03, 0x41,
-	0x10, 0x86, 0x77, 0x92, 0x90, 0xe8, 0x12, 0x44, 0x16, 0x34, 0x57, 0xc8, 0x10, 0x4e, 0x90, 0x54,
-	0x5a, 0xd8, 0x58, 0xa7, 0x13, 0x31, 0xc5, 0x81, 0x8d, 0x8d, 0xec, 0xe6, 0x86, 0x70, 0xc4, 0xec,
-	0xad, 0xbb, 0x7b, 0xc8, 0x61, 0x63, 0x67, 0xeb, 0x63, 0xf8, 0x28, 0x96, 0x57, 0xa6, 0xf4, 0xf6,
-	0x1a, 0xcb, 0x3c, 0x82, 0x70, 0x1a, 0x04, 0x7b, 0xbb, 0xf9, 0xbf, 0x19, 0x98, 0x8f, 0x9f, 0xef,
-	0x1b, 0x75, 0xb6, 0x22, 0xe7, 0xe4, 0x82, 0x4e, 0x8d, 0xcd, 0x7d, 0x2e, 0xba, 0x9a, 0x7c, 0xfc,
-	0x02, 0x7c, 0x34, 0xb5, 0xb9, 0x4c, 0xe7, 0xd2, 0xf9, 0x19, 0xf9, 0xc7, 0xdc, 0x2e, 0xaf, 0xbf,
-	0xcf, 0xc4, 0x21, 0xef, 0x3b, 0xd2, 0x29, 0xd9, 0x08, 0xc6, 0x30, 0x19, 0x26, 0x3f, 0x49, 0x44,
-	0x7c, 0x60, 0x64, 0x79, 0x9f, 0xcb, 0x34, 0xea, 0xb4, 0x8b, 0x6d, 0x14, 0x82, 0xf7, 0x7c, 0x69,
-	0x28, 0xea, 0xb6, 0xb8, 0x9d, 0xc5, 0x09, 0xdf, 0x73, 0xf4, 0x50, 0x90, 0x9e, 0xd3, 0xac, 0x58,
-	0x29, 0xb2, 0x51, 0x6f, 0x0c, 0x93, 0x5e, 0xf2, 0x87, 0xc6, 0x4f, 0xfc, 0xe0, 0x46, 0x67, 0xff,
-	0xa6, 0x71, 0xc4, 0x77, 0x5d, 0xb6, 0xd0, 0xd2, 0x17, 0x96, 0x5a, 0x83, 0x61, 0xf2, 0x0b, 0xe2,
-	0x63, 0xbe, 0x73, 0x99, 0x92, 0xf6, 0x99, 0x2f, 0xc5, 0x88, 0x0f, 0x4c, 0xa1, 0xee, 0x96, 0x54,
-	0x6e, 0x1f, 0x9a, 0x42, 0x5d, 0x51, 0x39, 0xbd, 0xa8, 0x6a, 0x64, 0xeb, 0x1a, 0xd9, 0xa6, 0x46,
-	0x78, 0x0e, 0x08, 0x6f, 0x01, 0xe1, 0x3d, 0x20, 0x54, 0x01, 0xe1, 0x23, 0x20, 0x7c, 0x06, 0x64,
-	0x9b, 0x80, 0xf0, 0xda, 0x20, 0xab, 0x1a, 0x64, 0xeb, 0x06, 0xd9, 0x6d, 0xc7, 0x28, 0xd5, 0x6f,
-	0x1b, 0x3f, 0xff, 0x0a, 0x00, 0x00, 0xff, 0xff, 0x67, 0x49, 0x47, 0x0c, 0x85, 0x01, 0x00, 0x00,
+	// 391 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x92, 0xb1, 0xee, 0xd3, 0x30,
+	0x10, 0xc6, 0xe3, 0x7f, 0x43, 0xff, 0xe5, 0x54, 0x50, 0xb1, 0x80, 0x46, 0x08, 0x59, 0x51, 0x06,
+	0x14, 0x21, 0x04, 0x03, 0x0b, 0x2b, 0x85, 0x05, 0x55, 0x54, 0x28, 0x12, 0x0b, 0x0b, 0x72, 0x92,
+	0x53, 0x15, 0xb5, 0xb5, 0x8d, 0xed, 0x00, 0x11, 0x0b, 0x8f, 0xc0, 0x63, 0xf0, 0x1a, 0x6c, 0x1d,
+	0x3b, 0x76, 0xa4, 0xe9, 0xc2, 0xd8, 0x47, 0x40, 0x49, 0x9a, 0xb6, 0xb4, 0x33, 0x5b, 0xbe, 0xdf,
+	0x5d, 0xee, 0xee, 0xd3, 0x67, 0x18, 0xa8, 0xf8, 0xd9, 0x02, 0x8d, 0xe1, 0x53, 0x7c, 0xaa, 0xb4,
+	0xb4, 0x92, 0x76, 0x04, 0xda, 0xe0, 0x17, 0x81, 0xe1, 0x48, 0x4b, 0x9e, 0x26, 0xdc, 0xd8, 0x09,
+	0xda, 0x2f, 0x52, 0xcf, 0xde, 0x36, 0x6d, 0xf4, 0x3e, 0x74, 0x0d, 0x8a, 0x14, 0xb5, 0x47, 0x7c,
+	0x12, 0xf6, 0xa3, 0xbd, 0xa2, 0x1e, 0x5c, 0x2b, 0x5e, 0xcc, 0x25, 0x4f, 0xbd, 0xab, 0xba, 0xd0,
+	0x4a, 0x4a, 0xc1, 0xb5, 0x85, 0x42, 0xaf, 0x53, 0xe3, 0xfa, 0x9b, 0x3e, 0x82, 0xdb, 0x06, 0x3f,
+	0xe5, 0x28, 0x12, 0x9c, 0xe4, 0x8b, 0x18, 0xb5, 0xe7, 0xfa, 0x24, 0x74, 0xa3, 0x33, 0x5a, 0x4d,
+	0xfd, 0x8c, 0xda, 0x64, 0x52, 0x78, 0x37, 0x7c, 0x12, 0xde, 0x8a, 0x5a, 0x49, 0x19, 0x40, 0x22,
+	0x17, 0x4a, 0xa3, 0x31, 0x98, 0x7a, 0x5d, 0x9f, 0x84, 0xbd, 0xe8, 0x84, 0x04, 0xdf, 0xe0, 0xde,
+	0x7b, 0x91, 0xfd, 0x37, 0x03, 0x0f, 0xe1, 0xa6, 0xc9, 0xa6, 0x82, 0xdb, 0x5c, 0x63, 0x7d, 0x7b,
+	0x3f, 0x3a, 0x82, 0xe0, 0x15, 0xf4, 0xde, 0xa4, 0x28, 0x6c, 0x66, 0x0b, 0x3a, 0x84, 0x6b, 0x95,
+	0xc7, 0x1f, 0x67, 0x58, 0xb4, 0x0b, 0x55, 0x1e, 0x8f, 0xb1, 0xa8, 0x1c, 0xa4, 0x38, 0xc7, 0x29,
+	0xb7, 0x95, 0xbd, 0x66, 0xe7, 0x09, 0x09, 0x96, 0x04, 0xee, 0xee, 0x6f, 0x1f, 0x63, 0xf1, 0xfa,
+	0x50, 0xa0, 0x8f, 0x61, 0x20, 0x1a, 0xfe, 0x2e, 0x8f, 0xe7, 0x59, 0x32, 0x3e, 0x8c, 0xbe, 0xe0,
+	0xf4, 0x09, 0xdc, 0x91, 0x0a, 0x35, 0xb7, 0x52, 0x1f, 0x9b, 0x9b, 0x5d, 0x97, 0x85, 0x7f, 0x5d,
+	0x75, 0xce, 0x5c, 0xd1, 0x07, 0xd0, 0x6b, 0xe3, 0xd9, 0xc7, 0x75, 0xd0, 0xd5, 0x9f, 0xf8, 0x55,
+	0x65, 0x1a, 0xcd, 0x4b, 0x5b, 0x47, 0xe5, 0x46, 0x47, 0x30, 0x7a, 0xb1, 0xda, 0x30, 0x67, 0xbd,
+	0x61, 0xce, 0x6e, 0xc3, 0xc8, 0xf7, 0x92, 0x91, 0x9f, 0x25, 0x23, 0xcb, 0x92, 0x91, 0x55, 0xc9,
+	0xc8, 0xef, 0x92, 0x91, 0x3f, 0x25, 0x73, 0x76, 0x25, 0x23, 0x3f, 0xb6, 0xcc, 0x59, 0x6d, 0x99,
+	0xb3, 0xde, 0x32, 0xe7, 0xc3, 0x95, 0x8a, 0xe3, 0x6e, 0xfd, 0x2c, 0x9f, 0xff, 0x1d, 0x00, 0x58,
+	0xdc, 0x0c, 0xa6, 0xaa, 0x02, 0x00, 0x00,
 }
 
 func (this *BroadcastNetworkMessage) Equal(that interface{}) bool {
@@ -275,6 +400,12 @@ func (this *BroadcastNetworkMessage) Equal(that interface{}) bool {
 	if this.SequenceNumber != that1.SequenceNumber {
 		return false
 	}
+	if this.Version != that1.Version {
+		return false
+	}
+	if this.Compressed != that1.Compressed {
+		return false
+	}
 	return true
 }
 func (this *UnicastNetworkMessage) Equal(that interface{}) bool {
@@ -332,18 +463,59 @@ func (this *Identity) Equal(that interface{}) bool {
 	if !bytes.Equal(this.PubKey, that1.PubKey) {
 		return false
 	}
+	if !bytes.Equal(this.Delegation, that1.Delegation) {
+		return false
+	}
+	return true
+}
+func (this *NetworkKeyDelegation) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*NetworkKeyDelegation)
+	if !ok {
+		that2, ok := that.(NetworkKeyDelegation)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if !bytes.Equal(this.NetworkPublicKey, that1.NetworkPublicKey) {
+		return false
+	}
+	if !bytes.Equal(this.OperatorPublicKey, that1.OperatorPublicKey) {
+		return false
+	}
+	if !bytes.Equal(this.Signature, that1.Signature) {
+		return false
+	}
+	if this.Sequence != that1.Sequence {
+		return false
+	}
+	if this.ExpiresAt != that1.ExpiresAt {
+		return false
+	}
 	return true
 }
 func (this *BroadcastNetworkMessage) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 8)
+	s := make([]string, 0, 10)
 	s = append(s, "&pb.BroadcastNetworkMessage{")
 	s = append(s, "Sender: "+fmt.Sprintf("%#v", this.Sender)+",\n")
 	s = append(s, "Payload: "+fmt.Sprintf("%#v", this.Payload)+",\n")
 	s = append(s, "Type: "+fmt.Sprintf("%#v", this.Type)+",\n")
 	s = append(s, "SequenceNumber: "+fmt.Sprintf("%#v", this.SequenceNumber)+",\n")
+	s = append(s, "Version: "+fmt.Sprintf("%#v", this.Version)+",\n")
+	s = append(s, "Compressed: "+fmt.Sprintf("%#v", this.Compressed)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -364,9 +536,24 @@ func (this *Identity) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 5)
+	s := make([]string, 0, 6)
 	s = append(s, "&pb.Identity{")
 	s = append(s, "PubKey: "+fmt.Sprintf("%#v", this.PubKey)+",\n")
+	s = append(s, "Delegation: "+fmt.Sprintf("%#v", this.Delegation)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *NetworkKeyDelegation) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 9)
+	s = append(s, "&pb.NetworkKeyDelegation{")
+	s = append(s, "NetworkPublicKey: "+fmt.Sprintf("%#v", this.NetworkPublicKey)+",\n")
+	s = append(s, "OperatorPublicKey: "+fmt.Sprintf("%#v", this.OperatorPublicKey)+",\n")
+	s = append(s, "Signature: "+fmt.Sprintf("%#v", this.Signature)+",\n")
+	s = append(s, "Sequence: "+fmt.Sprintf("%#v", this.Sequence)+",\n")
+	s = append(s, "ExpiresAt: "+fmt.Sprintf("%#v", this.ExpiresAt)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -398,6 +585,21 @@ func (m *BroadcastNetworkMessage) MarshalToSizedBuffer(dAtA []byte) (int, error)
 	_ = i
 	var l int
 	_ = l
+	if m.Compressed {
+		i--
+		if m.Compressed {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x30
+	}
+	if m.Version != 0 {
+		i = encodeVarintMessage(dAtA, i, uint64(m.Version))
+		i--
+		dAtA[i] = 0x28
+	}
 	if m.SequenceNumber != 0 {
 		i = encodeVarintMessage(dAtA, i, uint64(m.SequenceNumber))
 		i--
@@ -498,6 +700,13 @@ func (m *Identity) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.Delegation) > 0 {
+		i -= len(m.Delegation)
+		copy(dAtA[i:], m.Delegation)
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.Delegation)))
+		i--
+		dAtA[i] = 0x12
+	}
 	if len(m.PubKey) > 0 {
 		i -= len(m.PubKey)
 		copy(dAtA[i:], m.PubKey)
@@ -508,6 +717,60 @@ func (m *Identity) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *NetworkKeyDelegation) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *NetworkKeyDelegation) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *NetworkKeyDelegation) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.ExpiresAt != 0 {
+		i = encodeVarintMessage(dAtA, i, uint64(m.ExpiresAt))
+		i--
+		dAtA[i] = 0x28
+	}
+	if m.Sequence != 0 {
+		i = encodeVarintMessage(dAtA, i, uint64(m.Sequence))
+		i--
+		dAtA[i] = 0x20
+	}
+	if len(m.Signature) > 0 {
+		i -= len(m.Signature)
+		copy(dAtA[i:], m.Signature)
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.Signature)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.OperatorPublicKey) > 0 {
+		i -= len(m.OperatorPublicKey)
+		copy(dAtA[i:], m.OperatorPublicKey)
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.OperatorPublicKey)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.NetworkPublicKey) > 0 {
+		i -= len(m.NetworkPublicKey)
+		copy(dAtA[i:], m.NetworkPublicKey)
+		i = encodeVarintMessage(dAtA, i, uint64(len(m.NetworkPublicKey)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
 func encodeVarintMessage(dAtA []byte, offset int, v uint64) int {
 	offset -= sovMessage(v)
 	base := offset
@@ -540,6 +803,12 @@ func (m *BroadcastNetworkMessage) Size() (n int) {
 	if m.SequenceNumber != 0 {
 		n += 1 + sovMessage(uint64(m.SequenceNumber))
 	}
+	if m.Version != 0 {
+		n += 1 + sovMessage(uint64(m.Version))
+	}
+	if m.Compressed {
+		n += 2
+	}
 	return n
 }
 
@@ -578,6 +847,37 @@ func (m *Identity) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovMessage(uint64(l))
 	}
+	l = len(m.Delegation)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	return n
+}
+
+func (m *NetworkKeyDelegation) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.NetworkPublicKey)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	l = len(m.OperatorPublicKey)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	l = len(m.Signature)
+	if l > 0 {
+		n += 1 + l + sovMessage(uint64(l))
+	}
+	if m.Sequence != 0 {
+		n += 1 + sovMessage(uint64(m.Sequence))
+	}
+	if m.ExpiresAt != 0 {
+		n += 1 + sovMessage(uint64(m.ExpiresAt))
+	}
 	return n
 }
 
@@ -596,6 +896,8 @@ func (this *BroadcastNetworkMessage) String() string {
 		`Payload:` + fmt.Sprintf("%v", this.Payload) + `,`,
 		`Type:` + fmt.Sprintf("%v", this.Type) + `,`,
 		`SequenceNumber:` + fmt.Sprintf("%v", this.SequenceNumber) + `,`,
+		`Version:` + fmt.Sprintf("%v", this.Version) + `,`,
+		`Compressed:` + fmt.Sprintf("%v", this.Compressed) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -619,6 +921,21 @@ func (this *Identity) String() string {
 	}
 	s := strings.Join([]string{`&Identity{`,
 		`PubKey:` + fmt.Sprintf("%v", this.PubKey) + `,`,
+		`Delegation:` + fmt.Sprintf("%v", this.Delegation) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *NetworkKeyDelegation) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&NetworkKeyDelegation{`,
+		`NetworkPublicKey:` + fmt.Sprintf("%v", this.NetworkPublicKey) + `,`,
+		`OperatorPublicKey:` + fmt.Sprintf("%v", this.OperatorPublicKey) + `,`,
+		`Signature:` + fmt.Sprintf("%v", this.Signature) + `,`,
+		`Sequence:` + fmt.Sprintf("%v", this.Sequence) + `,`,
+		`ExpiresAt:` + fmt.Sprintf("%v", this.ExpiresAt) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -781,6 +1098,45 @@ func (m *BroadcastNetworkMessage) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Version", wireType)
+			}
+			m.Version = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Version |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Compressed", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Compressed = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMessage(dAtA[iNdEx:])
@@ -1057,6 +1413,233 @@ func (m *Identity) Unmarshal(dAtA []byte) error {
 				m.PubKey = []byte{}
 			}
 			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Delegation", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessage
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Delegation = append(m.Delegation[:0], dAtA[iNdEx:postIndex]...)
+			if m.Delegation == nil {
+				m.Delegation = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMessage(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthMessage
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthMessage
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *NetworkKeyDelegation) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMessage
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: NetworkKeyDelegation: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: NetworkKeyDelegation: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NetworkPublicKey", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessage
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.NetworkPublicKey = append(m.NetworkPublicKey[:0], dAtA[iNdEx:postIndex]...)
+			if m.NetworkPublicKey == nil {
+				m.NetworkPublicKey = []byte{}
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field OperatorPublicKey", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessage
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.OperatorPublicKey = append(m.OperatorPublicKey[:0], dAtA[iNdEx:postIndex]...)
+			if m.OperatorPublicKey == nil {
+				m.OperatorPublicKey = []byte{}
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Signature", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthMessage
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMessage
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Signature = append(m.Signature[:0], dAtA[iNdEx:postIndex]...)
+			if m.Signature == nil {
+				m.Signature = []byte{}
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Sequence", wireType)
+			}
+			m.Sequence = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Sequence |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ExpiresAt", wireType)
+			}
+			m.ExpiresAt = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMessage
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ExpiresAt |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMessage(dAtA[iNdEx:])
@@ -0,0 +1,168 @@
+package key
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/keep-network/keep-common/pkg/chain/ethereum/ethutil"
+	"github.com/keep-network/keep-core/pkg/net/gen/pb"
+	"github.com/keep-network/keep-core/pkg/operator"
+)
+
+// delegationPrefix is prepended to the network public key, the sequence number
+// and the expiration time before the delegation is signed with the operator
+// key so that the delegation signature cannot be confused with a signature
+// over any other message.
+var delegationPrefix = []byte("keep network key delegation:")
+
+// Delegation authorizes a network key to represent an operator in the network.
+// It lets the operator keep its key outside of the client process, for example
+// in a remote signing service, while the client authenticates to other peers
+// and signs network messages with a separate network key. Peers resolve the
+// operator, and thus the stake, through the delegation.
+//
+// The delegation is valid until it expires, so a network key stolen from
+// a compromised host can not represent the operator forever. Each delegation
+// issued by the operator has a sequence number higher than the previous one,
+// which lets peers reject delegations replaced by the operator before they
+// expire.
+type Delegation struct {
+	networkPublicKey  *NetworkPublic
+	operatorPublicKey *operator.PublicKey
+	sequence          uint64
+	expiresAt         uint64
+	signature         []byte
+}
+
+// SignDelegation creates a delegation of the provided network public key
+// signed by the operator, with the given sequence number and valid until the
+// given expiration time. The sign function should sign the message with the
+// operator key and return the signature in the Ethereum-specific format.
+func SignDelegation(
+	networkPublicKey *NetworkPublic,
+	operatorPublicKey *operator.PublicKey,
+	sequence uint64,
+	expiresAt time.Time,
+	sign func(message []byte) ([]byte, error),
+) (*Delegation, error) {
+	delegation := &Delegation{
+		networkPublicKey:  networkPublicKey,
+		operatorPublicKey: operatorPublicKey,
+		sequence:          sequence,
+		expiresAt:         uint64(expiresAt.Unix()),
+	}
+
+	signature, err := sign(delegation.message())
+	if err != nil {
+		return nil, fmt.Errorf("could not sign network key delegation: [%v]", err)
+	}
+	delegation.signature = signature
+
+	if err := delegation.Verify(networkPublicKey); err != nil {
+		return nil, err
+	}
+
+	return delegation, nil
+}
+
+// Verify checks whether the delegation has been issued for the provided
+// network public key, whether it is correctly signed with the operator key
+// and whether it has not expired yet.
+func (d *Delegation) Verify(networkPublicKey *NetworkPublic) error {
+	if !bytes.Equal(Marshal(d.networkPublicKey), Marshal(networkPublicKey)) {
+		return fmt.Errorf("delegation issued for another network key")
+	}
+
+	verifier := ethutil.NewSigner(&ecdsa.PrivateKey{PublicKey: *d.operatorPublicKey})
+	ok, err := verifier.Verify(d.message(), d.signature)
+	if err != nil {
+		return fmt.Errorf("could not verify delegation signature: [%v]", err)
+	}
+	if !ok {
+		return fmt.Errorf("invalid delegation signature")
+	}
+
+	if time.Now().After(d.ExpiresAt()) {
+		return fmt.Errorf("delegation expired at [%v]", d.ExpiresAt())
+	}
+
+	return nil
+}
+
+// NetworkPublicKey returns the delegated network public key.
+func (d *Delegation) NetworkPublicKey() *NetworkPublic {
+	return d.networkPublicKey
+}
+
+// OperatorPublicKey returns the public key of the delegating operator.
+func (d *Delegation) OperatorPublicKey() *operator.PublicKey {
+	return d.operatorPublicKey
+}
+
+// Sequence returns the sequence number of the delegation.
+func (d *Delegation) Sequence() uint64 {
+	return d.sequence
+}
+
+// ExpiresAt returns the time after which the delegation is no longer valid.
+func (d *Delegation) ExpiresAt() time.Time {
+	return time.Unix(int64(d.expiresAt), 0)
+}
+
+// Marshal converts the delegation to a byte array suitable for network
+// communication and storage.
+func (d *Delegation) Marshal() ([]byte, error) {
+	return (&pb.NetworkKeyDelegation{
+		NetworkPublicKey:  Marshal(d.networkPublicKey),
+		OperatorPublicKey: operator.Marshal(d.operatorPublicKey),
+		Signature:         d.signature,
+		Sequence:          d.sequence,
+		ExpiresAt:         d.expiresAt,
+	}).Marshal()
+}
+
+// UnmarshalDelegation converts a byte array produced by Marshal to a
+// Delegation. The returned delegation is not verified.
+func UnmarshalDelegation(bytes []byte) (*Delegation, error) {
+	pbDelegation := pb.NetworkKeyDelegation{}
+	if err := pbDelegation.Unmarshal(bytes); err != nil {
+		return nil, err
+	}
+
+	networkPublicKey, err := btcec.ParsePubKey(
+		pbDelegation.NetworkPublicKey,
+		btcec.S256(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse network public key: [%v]", err)
+	}
+
+	operatorPublicKey, err := operator.Unmarshal(pbDelegation.OperatorPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse operator public key: [%v]", err)
+	}
+
+	return &Delegation{
+		networkPublicKey:  (*NetworkPublic)(networkPublicKey),
+		operatorPublicKey: operatorPublicKey,
+		sequence:          pbDelegation.Sequence,
+		expiresAt:         pbDelegation.ExpiresAt,
+		signature:         pbDelegation.Signature,
+	}, nil
+}
+
+func (d *Delegation) message() []byte {
+	message := append([]byte{}, delegationPrefix...)
+	message = append(message, Marshal(d.networkPublicKey)...)
+
+	var sequence, expiresAt [8]byte
+	binary.BigEndian.PutUint64(sequence[:], d.sequence)
+	binary.BigEndian.PutUint64(expiresAt[:], d.expiresAt)
+
+	message = append(message, sequence[:]...)
+	return append(message, expiresAt[:]...)
+}
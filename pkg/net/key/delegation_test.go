@@ -0,0 +1,150 @@
+package key
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/keep-network/keep-common/pkg/chain/ethereum/ethutil"
+	"github.com/keep-network/keep-core/pkg/operator"
+)
+
+func TestDelegationRoundTrip(t *testing.T) {
+	delegation, networkPublicKey, _ := newTestDelegation(t)
+
+	bytes, err := delegation.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unmarshaled, err := UnmarshalDelegation(bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(delegation, unmarshaled) {
+		t.Fatalf("unexpected content of unmarshaled delegation")
+	}
+
+	if err := unmarshaled.Verify(networkPublicKey); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDelegationVerifyOtherNetworkKey(t *testing.T) {
+	delegation, _, _ := newTestDelegation(t)
+
+	_, otherNetworkPublicKey, err := GenerateStaticNetworkKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = delegation.Verify(otherNetworkPublicKey)
+
+	expectedError := "delegation issued for another network key"
+	if err == nil || err.Error() != expectedError {
+		t.Fatalf(
+			"unexpected error\nexpected: [%v]\nactual:   [%v]",
+			expectedError,
+			err,
+		)
+	}
+}
+
+func TestDelegationVerifyOtherOperator(t *testing.T) {
+	delegation, networkPublicKey, _ := newTestDelegation(t)
+
+	_, otherOperatorPublicKey, err := operator.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	delegation.operatorPublicKey = otherOperatorPublicKey
+
+	err = delegation.Verify(networkPublicKey)
+
+	expectedError := "invalid delegation signature"
+	if err == nil || err.Error() != expectedError {
+		t.Fatalf(
+			"unexpected error\nexpected: [%v]\nactual:   [%v]",
+			expectedError,
+			err,
+		)
+	}
+}
+
+func TestDelegationVerifyOtherSequence(t *testing.T) {
+	delegation, networkPublicKey, _ := newTestDelegation(t)
+
+	delegation.sequence++
+
+	err := delegation.Verify(networkPublicKey)
+
+	expectedError := "invalid delegation signature"
+	if err == nil || err.Error() != expectedError {
+		t.Fatalf(
+			"unexpected error\nexpected: [%v]\nactual:   [%v]",
+			expectedError,
+			err,
+		)
+	}
+}
+
+func TestDelegationVerifyExpired(t *testing.T) {
+	operatorPrivateKey, operatorPublicKey, err := operator.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, networkPublicKey, err := GenerateStaticNetworkKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expiresAt := time.Now().Add(-time.Minute).Truncate(time.Second)
+
+	_, err = SignDelegation(
+		networkPublicKey,
+		operatorPublicKey,
+		1,
+		expiresAt,
+		ethutil.NewSigner(operatorPrivateKey).Sign,
+	)
+
+	expectedError := "delegation expired at [" + expiresAt.String() + "]"
+	if err == nil || err.Error() != expectedError {
+		t.Fatalf(
+			"unexpected error\nexpected: [%v]\nactual:   [%v]",
+			expectedError,
+			err,
+		)
+	}
+}
+
+func newTestDelegation(t *testing.T) (
+	*Delegation,
+	*NetworkPublic,
+	*operator.PublicKey,
+) {
+	operatorPrivateKey, operatorPublicKey, err := operator.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, networkPublicKey, err := GenerateStaticNetworkKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	delegation, err := SignDelegation(
+		networkPublicKey,
+		operatorPublicKey,
+		3,
+		time.Now().Add(time.Hour),
+		ethutil.NewSigner(operatorPrivateKey).Sign,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return delegation, networkPublicKey, operatorPublicKey
+}
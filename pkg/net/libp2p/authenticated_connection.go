@@ -9,6 +9,7 @@ import (
 	"github.com/keep-network/keep-core/pkg/net/gen/pb"
 	"github.com/keep-network/keep-core/pkg/net/key"
 	"github.com/keep-network/keep-core/pkg/net/security/handshake"
+	"github.com/keep-network/keep-core/pkg/operator"
 	libp2pcrypto "github.com/libp2p/go-libp2p-core/crypto"
 	peer "github.com/libp2p/go-libp2p-core/peer"
 
@@ -26,9 +27,19 @@ type authenticatedConnection struct {
 
 	localPeerID         peer.ID
 	localPeerPrivateKey libp2pcrypto.PrivKey
+	// localDelegation is the marshaled delegation of the local network key
+	// or nil if the local network key is the operator key.
+	localDelegation []byte
 
 	remotePeerID        peer.ID
 	remotePeerPublicKey libp2pcrypto.PubKey
+	// remoteOperatorPublicKey is resolved during the handshake from the
+	// remote peer's delegation or, if the remote peer sent no delegation,
+	// from its network key.
+	remoteOperatorPublicKey *operator.PublicKey
+	// remoteDelegation is the verified delegation received from the remote
+	// peer during the handshake or nil if the remote peer sent none.
+	remoteDelegation *key.Delegation
 
 	firewall keepNet.Firewall
 
@@ -45,6 +56,7 @@ func newAuthenticatedInboundConnection(
 	unauthenticatedConn net.Conn,
 	localPeerID peer.ID,
 	privateKey libp2pcrypto.PrivKey,
+	delegation []byte,
 	firewall keepNet.Firewall,
 	protocol string,
 ) (*authenticatedConnection, error) {
@@ -52,6 +64,7 @@ func newAuthenticatedInboundConnection(
 		Conn:                unauthenticatedConn,
 		localPeerID:         localPeerID,
 		localPeerPrivateKey: privateKey,
+		localDelegation:     delegation,
 		firewall:            firewall,
 		protocol:            protocol,
 	}
@@ -86,6 +99,7 @@ func newAuthenticatedOutboundConnection(
 	unauthenticatedConn net.Conn,
 	localPeerID peer.ID,
 	privateKey libp2pcrypto.PrivKey,
+	delegation []byte,
 	remotePeerID peer.ID,
 	firewall keepNet.Firewall,
 	protocol string,
@@ -102,6 +116,7 @@ func newAuthenticatedOutboundConnection(
 		Conn:                unauthenticatedConn,
 		localPeerID:         localPeerID,
 		localPeerPrivateKey: privateKey,
+		localDelegation:     delegation,
 		remotePeerID:        remotePeerID,
 		remotePeerPublicKey: remotePublicKey,
 		firewall:            firewall,
@@ -127,13 +142,38 @@ func newAuthenticatedOutboundConnection(
 	return ac, nil
 }
 
+// checkFirewallRules validates the remote operator against the firewall. The
+// remote operator is resolved through the remote peer's delegation, so the
+// firewall rules are checked against the operator's stake even if the remote
+// peer uses a separate network key.
 func (ac *authenticatedConnection) checkFirewallRules() error {
+	return ac.firewall.Validate(ac.remoteOperatorPublicKey)
+}
+
+// resolveRemoteOperator verifies the delegation received from the remote peer
+// during the handshake and sets the remote operator's public key. If the
+// remote peer sent no delegation, its network key is the operator key.
+func (ac *authenticatedConnection) resolveRemoteOperator(
+	delegationBytes []byte,
+) error {
 	networkKey, ok := ac.remotePeerPublicKey.(*key.NetworkPublic)
 	if !ok {
 		return fmt.Errorf("unexpected type of remote peer's public key")
 	}
 
-	return ac.firewall.Validate(key.NetworkKeyToECDSAKey(networkKey))
+	delegation, err := verifiedDelegation(delegationBytes, networkKey)
+	if err != nil {
+		return fmt.Errorf("invalid remote peer's delegation: [%v]", err)
+	}
+
+	if delegation != nil {
+		ac.remoteOperatorPublicKey = delegation.OperatorPublicKey()
+		ac.remoteDelegation = delegation
+	} else {
+		ac.remoteOperatorPublicKey = key.NetworkKeyToECDSAKey(networkKey)
+	}
+
+	return nil
 }
 
 func (ac *authenticatedConnection) runHandshakeAsInitiator() error {
@@ -146,7 +186,10 @@ func (ac *authenticatedConnection) runHandshakeAsInitiator() error {
 	// Act 1
 	//
 
-	initiatorAct1, err := handshake.InitiateHandshake(ac.protocol)
+	initiatorAct1, err := handshake.InitiateHandshake(
+		ac.protocol,
+		ac.localDelegation,
+	)
 	if err != nil {
 		return err
 	}
@@ -176,6 +219,10 @@ func (ac *authenticatedConnection) runHandshakeAsInitiator() error {
 		return err
 	}
 
+	if err := ac.resolveRemoteOperator(act2Message.Delegation()); err != nil {
+		return err
+	}
+
 	//
 	// Act 3
 	//
@@ -287,11 +334,19 @@ func (ac *authenticatedConnection) runHandshakeAsResponder() error {
 		return err
 	}
 
-	responderAct2, err := handshake.AnswerHandshake(act1Message, ac.protocol)
+	responderAct2, err := handshake.AnswerHandshake(
+		act1Message,
+		ac.protocol,
+		ac.localDelegation,
+	)
 	if err != nil {
 		return err
 	}
 
+	if err := ac.resolveRemoteOperator(act1Message.Delegation()); err != nil {
+		return err
+	}
+
 	//
 	// Act 2
 	//
@@ -481,3 +536,10 @@ func (ac *authenticatedConnection) RemotePeer() peer.ID {
 func (ac *authenticatedConnection) RemotePublicKey() libp2pcrypto.PubKey {
 	return ac.remotePeerPublicKey
 }
+
+// RemoteOperatorPublicKey retrieves the public key of the remote operator.
+// It is the remote public key unless the remote peer delegated its network
+// key.
+func (ac *authenticatedConnection) RemoteOperatorPublicKey() *operator.PublicKey {
+	return ac.remoteOperatorPublicKey
+}
@@ -11,10 +11,12 @@ import (
 	"time"
 
 	protoio "github.com/gogo/protobuf/io"
+	"github.com/keep-network/keep-common/pkg/chain/ethereum/ethutil"
 	keepNet "github.com/keep-network/keep-core/pkg/net"
 	"github.com/keep-network/keep-core/pkg/net/gen/pb"
 	"github.com/keep-network/keep-core/pkg/net/key"
 	"github.com/keep-network/keep-core/pkg/net/security/handshake"
+	"github.com/keep-network/keep-core/pkg/operator"
 	libp2pcrypto "github.com/libp2p/go-libp2p-core/crypto"
 	peer "github.com/libp2p/go-libp2p-core/peer"
 )
@@ -52,6 +54,7 @@ func TestPinnedAndMessageKeyMismatch(t *testing.T) {
 		responderConn,
 		responder.peerID,
 		responder.privKey,
+		nil,
 		firewall,
 		ProtocolBeacon,
 	)
@@ -68,7 +71,7 @@ func maliciousInitiatorHijacksHonestRun(t *testing.T, ac *authenticatedConnectio
 	initiatorConnectionReader := protoio.NewDelimitedReader(ac.Conn, maxFrameSize)
 	initiatorConnectionWriter := protoio.NewDelimitedWriter(ac.Conn)
 
-	initiatorAct1, err := handshake.InitiateHandshake(ProtocolBeacon)
+	initiatorAct1, err := handshake.InitiateHandshake(ProtocolBeacon, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -210,6 +213,66 @@ func TestHandshakeResponderBlockedByFirewallRules(t *testing.T) {
 	}
 }
 
+func TestHandshakeWithDelegation(t *testing.T) {
+	initiator := createTestDelegatedConnectionConfig(t)
+	responder := createTestDelegatedConnectionConfig(t)
+
+	// only operators meet firewall rules, network keys are not staked
+	firewall := newMockFirewall()
+	firewall.updateOperator(initiator.operatorPublicKey, true)
+	firewall.updateOperator(responder.operatorPublicKey, true)
+
+	authnInboundConn, authnOutboundConn, inboundError, outboundError :=
+		connectInitiatorAndResponder(initiator, responder, firewall, t)
+	if inboundError != nil {
+		t.Fatal(inboundError)
+	}
+	if outboundError != nil {
+		t.Fatal(outboundError)
+	}
+
+	if !reflect.DeepEqual(
+		initiator.operatorPublicKey,
+		authnInboundConn.RemoteOperatorPublicKey(),
+	) {
+		t.Errorf("unexpected initiator's operator public key")
+	}
+	if !reflect.DeepEqual(
+		responder.operatorPublicKey,
+		authnOutboundConn.RemoteOperatorPublicKey(),
+	) {
+		t.Errorf("unexpected responder's operator public key")
+	}
+}
+
+func TestHandshakeWithDelegationOfAnotherNetworkKey(t *testing.T) {
+	initiator := createTestDelegatedConnectionConfig(t)
+	responder := createTestConnectionConfig(t)
+
+	// initiator presents a delegation issued for another network key
+	initiator.delegation = createTestDelegatedConnectionConfig(t).delegation
+
+	firewall := newMockFirewall()
+	firewall.updateOperator(initiator.operatorPublicKey, true)
+	firewall.updatePeer(responder.pubKey, true)
+
+	// the initiator observes the connection closed by the responder
+	_, _, _, inboundError :=
+		connectInitiatorAndResponder(initiator, responder, firewall, t)
+
+	expectedInboundError := fmt.Errorf(
+		"connection handshake failed: [invalid remote peer's delegation: " +
+			"[delegation issued for another network key]]",
+	)
+	if !reflect.DeepEqual(expectedInboundError, inboundError) {
+		t.Fatalf(
+			"unexpected inbound connection error\nexpected: %v\nactual: %v",
+			expectedInboundError,
+			inboundError,
+		)
+	}
+}
+
 func connectInitiatorAndResponder(
 	initiator *testConnectionConfig,
 	responder *testConnectionConfig,
@@ -236,6 +299,7 @@ func connectInitiatorAndResponder(
 			initiatorConn,
 			initiatorPeerID,
 			initiatorPrivKey,
+			initiator.delegation,
 			responderPeerID,
 			firewall,
 			ProtocolBeacon,
@@ -247,6 +311,7 @@ func connectInitiatorAndResponder(
 		responderConn,
 		responder.peerID,
 		responder.privKey,
+		responder.delegation,
 		firewall,
 		ProtocolBeacon,
 	)
@@ -260,6 +325,9 @@ type testConnectionConfig struct {
 	privKey *key.NetworkPrivate
 	pubKey  *key.NetworkPublic
 	peerID  peer.ID
+
+	delegation        []byte
+	operatorPublicKey *operator.PublicKey
 }
 
 func createTestConnectionConfig(t *testing.T) *testConnectionConfig {
@@ -272,7 +340,40 @@ func createTestConnectionConfig(t *testing.T) *testConnectionConfig {
 		t.Fatal(err)
 	}
 
-	return &testConnectionConfig{privKey, pubKey, peerID}
+	return &testConnectionConfig{
+		privKey:           privKey,
+		pubKey:            pubKey,
+		peerID:            peerID,
+		operatorPublicKey: key.NetworkKeyToECDSAKey(pubKey),
+	}
+}
+
+func createTestDelegatedConnectionConfig(t *testing.T) *testConnectionConfig {
+	config := createTestConnectionConfig(t)
+
+	operatorPrivateKey, operatorPublicKey, err := operator.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	delegation, err := key.SignDelegation(
+		config.pubKey,
+		operatorPublicKey,
+		1,
+		time.Now().Add(time.Hour),
+		ethutil.NewSigner(operatorPrivateKey).Sign,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config.delegation, err = delegation.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.operatorPublicKey = operatorPublicKey
+
+	return config
 }
 
 // Connect an initiator and responder via a full duplex network connection (reads
@@ -302,6 +403,12 @@ func (mf *mockFirewall) updatePeer(
 	remotePeerPublicKey *key.NetworkPublic,
 	meetsCriteria bool,
 ) {
-	x := key.NetworkKeyToECDSAKey(remotePeerPublicKey).X.Uint64()
-	mf.meetsCriteria[x] = meetsCriteria
+	mf.updateOperator(key.NetworkKeyToECDSAKey(remotePeerPublicKey), meetsCriteria)
+}
+
+func (mf *mockFirewall) updateOperator(
+	operatorPublicKey *ecdsa.PublicKey,
+	meetsCriteria bool,
+) {
+	mf.meetsCriteria[operatorPublicKey.X.Uint64()] = meetsCriteria
 }
@@ -9,12 +9,12 @@ import (
 	"sync/atomic"
 
 	"github.com/gogo/protobuf/proto"
+	"github.com/keep-network/keep-core/pkg/metrics"
 	"github.com/keep-network/keep-core/pkg/net"
 	"github.com/keep-network/keep-core/pkg/net/gen/pb"
 	"github.com/keep-network/keep-core/pkg/net/internal"
-	"github.com/keep-network/keep-core/pkg/net/key"
 	"github.com/keep-network/keep-core/pkg/net/retransmission"
-	crypto "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/keep-network/keep-core/pkg/operator"
 	peer "github.com/libp2p/go-libp2p-core/peer"
 	peerstore "github.com/libp2p/go-libp2p-peerstore"
 	pubsub "github.com/libp2p/go-libp2p-pubsub"
@@ -55,6 +55,15 @@ type channel struct {
 	unmarshalersByType map[string]func() net.TaggedUnmarshaler
 
 	retransmissionTicker *retransmission.Ticker
+
+	rateLimiter      *rateLimiter
+	reputation       *reputation
+	envelopeVersions *envelopeVersions
+	metrics          *metrics.Network
+
+	// Peers which published messages on the channel.
+	publishersMutex sync.Mutex
+	publishers      map[peer.ID]bool
 }
 
 type messageHandler struct {
@@ -71,7 +80,18 @@ func (c *channel) Name() string {
 }
 
 func (c *channel) Send(ctx context.Context, message net.TaggedMarshaler) error {
-	messageProto, err := c.messageProto(message)
+	return c.sendFrom(ctx, c.clientIdentity, message)
+}
+
+// sendFrom publishes the message on behalf of the given identity. The
+// identity must share the network key with the client identity; it may carry
+// the delegation of another operator hosted by the client.
+func (c *channel) sendFrom(
+	ctx context.Context,
+	sender *identity,
+	message net.TaggedMarshaler,
+) error {
+	messageProto, err := c.messageProto(sender, message)
 	if err != nil {
 		return err
 	}
@@ -150,6 +170,7 @@ func (c *channel) SetUnmarshaler(unmarshaler func() net.TaggedUnmarshaler) {
 }
 
 func (c *channel) messageProto(
+	sender *identity,
 	message net.TaggedMarshaler,
 ) (*pb.BroadcastNetworkMessage, error) {
 	payloadBytes, err := message.Marshal()
@@ -157,18 +178,89 @@ func (c *channel) messageProto(
 		return nil, err
 	}
 
-	senderIdentityBytes, err := c.clientIdentity.Marshal()
+	senderIdentityBytes, err := sender.Marshal()
 	if err != nil {
 		return nil, err
 	}
 
+	compressed := false
+	if c.compressionSupported() {
+		payloadBytes, compressed, err = compressPayload(payloadBytes)
+		if err != nil {
+			return nil, fmt.Errorf("could not compress payload: [%v]", err)
+		}
+	}
+
+	if compressed && c.metrics != nil {
+		c.metrics.BroadcastMessagesCompressed.Inc(message.Type())
+	}
+
 	return &pb.BroadcastNetworkMessage{
-		Payload: payloadBytes,
-		Sender:  senderIdentityBytes,
-		Type:    []byte(message.Type()),
+		Payload:    payloadBytes,
+		Sender:     senderIdentityBytes,
+		Type:       []byte(message.Type()),
+		Version:    currentEnvelopeVersion,
+		Compressed: compressed,
 	}, nil
 }
 
+// compressionSupported returns true if all known subscribers of the channel
+// support envelopes with compressed payloads. See subscribersSupportCompression.
+func (c *channel) compressionSupported() bool {
+	c.pubsubMutex.Lock()
+	peers := c.pubsub.ListPeers(c.name)
+	c.pubsubMutex.Unlock()
+
+	return c.subscribersSupportCompression(peers)
+}
+
+// subscribersSupportCompression returns true if all known subscribers of the
+// channel support envelopes with compressed payloads. Known subscribers are
+// the given peers subscribed to the channel and connected with the client
+// directly, whose envelope versions have been negotiated in the handshake,
+// and the peers which published messages on the channel, advertising their
+// envelope version in each message. Members of a group publish on the group
+// channel before any large payload is exchanged, so in practice all members
+// are known by then. If any known subscriber is a legacy client, or there are
+// no known subscribers, payloads are sent uncompressed.
+func (c *channel) subscribersSupportCompression(peers []peer.ID) bool {
+	c.publishersMutex.Lock()
+	for publisher := range c.publishers {
+		peers = append(peers, publisher)
+	}
+	c.publishersMutex.Unlock()
+
+	if len(peers) == 0 {
+		return false
+	}
+
+	for _, peerID := range peers {
+		if c.envelopeVersions.get(peerID) < compressedEnvelopeVersion {
+			return false
+		}
+	}
+
+	return true
+}
+
+// recordPublisher records the peer which published a message on the channel
+// along with the envelope version of the message.
+func (c *channel) recordPublisher(peerID peer.ID, envelopeVersion uint32) {
+	if peerID == c.clientIdentity.id {
+		return
+	}
+
+	c.envelopeVersions.put(peerID, envelopeVersion)
+
+	c.publishersMutex.Lock()
+	defer c.publishersMutex.Unlock()
+
+	if c.publishers == nil {
+		c.publishers = make(map[peer.ID]bool)
+	}
+	c.publishers[peerID] = true
+}
+
 func (c *channel) publishToPubSub(message *pb.BroadcastNetworkMessage) error {
 	messageBytes, err := message.Marshal()
 	if err != nil {
@@ -178,7 +270,18 @@ func (c *channel) publishToPubSub(message *pb.BroadcastNetworkMessage) error {
 	c.pubsubMutex.Lock()
 	defer c.pubsubMutex.Unlock()
 
-	return c.pubsub.Publish(c.name, messageBytes)
+	if err := c.pubsub.Publish(c.name, messageBytes); err != nil {
+		return err
+	}
+
+	if c.metrics != nil {
+		c.metrics.BroadcastBytesSent.Add(
+			float64(len(messageBytes)),
+			string(message.Type),
+		)
+	}
+
+	return nil
 }
 
 func (c *channel) handleMessages(ctx context.Context) {
@@ -206,6 +309,10 @@ func (c *channel) subscriptionWorker(ctx context.Context) {
 				continue
 			}
 
+			if !c.isAdmitted(message.GetFrom()) {
+				continue
+			}
+
 			select {
 			case c.incomingMessageQueue <- message:
 			default:
@@ -215,6 +322,63 @@ func (c *channel) subscriptionWorker(ctx context.Context) {
 	}
 }
 
+// isAdmitted determines if the message from the given peer should be
+// processed. Messages from peers with reputation at or below the threshold
+// are dropped, as well as messages exceeding the rate limit of the peer.
+// Messages published by the client itself are always admitted.
+func (c *channel) isAdmitted(sender peer.ID) bool {
+	if sender == c.clientIdentity.id {
+		return true
+	}
+
+	if c.reputation.isBanned(sender) {
+		logger.Debugf(
+			"dropping message from peer [%v] with low reputation",
+			sender,
+		)
+		return false
+	}
+
+	if !c.rateLimiter.allow(sender) {
+		logger.Debugf(
+			"dropping message from peer [%v] exceeding the rate limit "+
+				"of channel [%v]",
+			sender,
+			c.name,
+		)
+		c.penalize(sender, rateLimitExceeded)
+		return false
+	}
+
+	return true
+}
+
+// penalize lowers the reputation of the given peer unless it is the client
+// itself.
+func (c *channel) penalize(peerID peer.ID, offense misbehavior) {
+	if peerID == c.clientIdentity.id {
+		return
+	}
+
+	c.reputation.penalize(peerID, offense)
+}
+
+// ReportMisbehavior lowers the reputation of the peer which sent a message
+// rejected by the protocol layer.
+func (c *channel) ReportMisbehavior(sender net.TransportIdentifier) {
+	peerID, err := peer.IDB58Decode(sender.String())
+	if err != nil {
+		logger.Warningf(
+			"could not decode reported peer [%v]: [%v]",
+			sender,
+			err,
+		)
+		return
+	}
+
+	c.penalize(peerID, protocolViolation)
+}
+
 func (c *channel) incomingMessageWorker(ctx context.Context) {
 	for {
 		select {
@@ -231,6 +395,7 @@ func (c *channel) incomingMessageWorker(ctx context.Context) {
 func (c *channel) processPubsubMessage(pubsubMessage *pubsub.Message) error {
 	var messageProto pb.BroadcastNetworkMessage
 	if err := proto.Unmarshal(pubsubMessage.Data, &messageProto); err != nil {
+		c.penalize(pubsubMessage.GetFrom(), malformedMessage)
 		return err
 	}
 
@@ -245,16 +410,32 @@ func (c *channel) processContainerMessage(
 	// from our map of unmarshallers.
 	unmarshaled, err := c.getUnmarshalingContainerByType(string(message.Type))
 	if err != nil {
+		c.penalize(proposedSender, unknownMessageType)
+		return err
+	}
+
+	if proposedSender != c.clientIdentity.id && c.metrics != nil {
+		c.metrics.BroadcastBytesReceived.Add(
+			float64(message.Size()),
+			string(message.Type),
+		)
+	}
+
+	payload, err := c.messagePayload(message)
+	if err != nil {
+		c.penalize(proposedSender, malformedMessage)
 		return err
 	}
 
-	if err := unmarshaled.Unmarshal(message.GetPayload()); err != nil {
+	if err := unmarshaled.Unmarshal(payload); err != nil {
+		c.penalize(proposedSender, malformedMessage)
 		return err
 	}
 
 	// Construct an identifier from the sender.
 	senderIdentifier := &identity{}
 	if err := senderIdentifier.Unmarshal(message.Sender); err != nil {
+		c.penalize(proposedSender, invalidSender)
 		return err
 	}
 
@@ -262,6 +443,7 @@ func (c *channel) processContainerMessage(
 	//     Test that the proposed sender (outer layer) matches the
 	//     sender identifier we grab from the message (inner layer).
 	if proposedSender != senderIdentifier.id {
+		c.penalize(proposedSender, invalidSender)
 		return fmt.Errorf(
 			"outer layer sender [%v] does not match inner layer sender [%v]",
 			proposedSender,
@@ -269,20 +451,22 @@ func (c *channel) processContainerMessage(
 		)
 	}
 
-	networkKey := key.Libp2pKeyToNetworkKey(senderIdentifier.pubKey)
-	if networkKey == nil {
-		return fmt.Errorf(
-			"sender [%v] with key [%v] is not of correct type",
-			senderIdentifier.id,
-			senderIdentifier.pubKey,
-		)
+	c.recordPublisher(senderIdentifier.id, message.Version)
+
+	// The sender public key is the operator key resolved through the
+	// sender's delegation so that the protocol layer can validate the sender
+	// against its on-chain identity.
+	senderPublicKey, err := senderIdentifier.operatorPublicKey()
+	if err != nil {
+		c.penalize(proposedSender, invalidSender)
+		return err
 	}
 
 	netMessage := internal.BasicMessage(
 		senderIdentifier.id,
 		unmarshaled,
 		string(message.Type),
-		key.Marshal(networkKey),
+		operator.Marshal(senderPublicKey),
 		message.SequenceNumber,
 	)
 
@@ -291,6 +475,26 @@ func (c *channel) processContainerMessage(
 	return nil
 }
 
+// messagePayload returns the payload of the message envelope, decompressed
+// if needed. Compressed payloads are accepted only in envelopes of versions
+// supporting compression.
+func (c *channel) messagePayload(
+	message pb.BroadcastNetworkMessage,
+) ([]byte, error) {
+	if !message.Compressed {
+		return message.Payload, nil
+	}
+
+	if message.Version < compressedEnvelopeVersion {
+		return nil, fmt.Errorf(
+			"compressed payload in envelope of version [%v]",
+			message.Version,
+		)
+	}
+
+	return decompressPayload(message.Payload)
+}
+
 func (c *channel) getUnmarshalingContainerByType(messageType string) (net.TaggedUnmarshaler, error) {
 	c.unmarshalersMutex.Lock()
 	defer c.unmarshalersMutex.Unlock()
@@ -341,7 +545,7 @@ func (c *channel) SetFilter(filter net.BroadcastChannelFilter) error {
 
 func createTopicValidator(filter net.BroadcastChannelFilter) pubsub.Validator {
 	return func(_ context.Context, _ peer.ID, message *pubsub.Message) bool {
-		authorPublicKey, err := extractPublicKey(message.GetFrom())
+		authorPublicKey, err := extractOperatorPublicKey(message)
 		if err != nil {
 			logger.Warningf(
 				"could not retrieve message author public key: [%v]",
@@ -353,16 +557,27 @@ func createTopicValidator(filter net.BroadcastChannelFilter) pubsub.Validator {
 	}
 }
 
-func extractPublicKey(peer peer.ID) (*ecdsa.PublicKey, error) {
-	publicKey, err := peer.ExtractPublicKey()
-	if err != nil {
+// extractOperatorPublicKey resolves the public key of the operator who
+// authored the message. The operator is resolved from the sender identity
+// carried by the message, which must belong to the message author.
+func extractOperatorPublicKey(message *pubsub.Message) (*ecdsa.PublicKey, error) {
+	var messageProto pb.BroadcastNetworkMessage
+	if err := proto.Unmarshal(message.Data, &messageProto); err != nil {
+		return nil, err
+	}
+
+	senderIdentifier := &identity{}
+	if err := senderIdentifier.Unmarshal(messageProto.Sender); err != nil {
 		return nil, err
 	}
 
-	secp256k1PublicKey, ok := publicKey.(*crypto.Secp256k1PublicKey)
-	if !ok {
-		return nil, fmt.Errorf("public key is of type other than Secp256k1")
+	if senderIdentifier.id != message.GetFrom() {
+		return nil, fmt.Errorf(
+			"message author [%v] does not match sender [%v]",
+			message.GetFrom(),
+			senderIdentifier.id,
+		)
 	}
 
-	return key.NetworkKeyToECDSAKey(secp256k1PublicKey), nil
+	return senderIdentifier.operatorPublicKey()
 }
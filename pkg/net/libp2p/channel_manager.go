@@ -5,6 +5,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/keep-network/keep-core/pkg/metrics"
 	"github.com/keep-network/keep-core/pkg/net"
 	"github.com/keep-network/keep-core/pkg/net/retransmission"
 	"github.com/libp2p/go-libp2p-core/host"
@@ -32,6 +33,10 @@ type channelManager struct {
 
 	retransmissionTicker *retransmission.Ticker
 
+	reputation       *reputation
+	envelopeVersions *envelopeVersions
+	metrics          *metrics.Network
+
 	forwarderSubscriptionsMutex sync.Mutex
 	forwarderSubscriptions      map[string]*pubsub.Subscription
 }
@@ -41,6 +46,9 @@ func newChannelManager(
 	identity *identity,
 	p2phost host.Host,
 	retransmissionTicker *retransmission.Ticker,
+	reputation *reputation,
+	envelopeVersions *envelopeVersions,
+	networkMetrics *metrics.Network,
 ) (*channelManager, error) {
 	floodsub, err := pubsub.NewFloodSub(
 		ctx,
@@ -61,6 +69,9 @@ func newChannelManager(
 		identity:               identity,
 		ctx:                    ctx,
 		retransmissionTicker:   retransmissionTicker,
+		reputation:             reputation,
+		envelopeVersions:       envelopeVersions,
+		metrics:                networkMetrics,
 		forwarderSubscriptions: make(map[string]*pubsub.Subscription),
 	}, nil
 }
@@ -113,6 +124,10 @@ func (cm *channelManager) newChannel(name string) (*channel, error) {
 		messageHandlers:      make([]*messageHandler, 0),
 		unmarshalersByType:   make(map[string]func() net.TaggedUnmarshaler),
 		retransmissionTicker: cm.retransmissionTicker,
+		rateLimiter:          newRateLimiter(peerMessageRate, peerMessageBurst),
+		reputation:           cm.reputation,
+		envelopeVersions:     cm.envelopeVersions,
+		metrics:              cm.metrics,
 	}
 
 	go channel.handleMessages(cm.ctx)
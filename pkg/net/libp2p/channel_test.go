@@ -13,7 +13,11 @@ import (
 	"time"
 
 	"github.com/btcsuite/btcd/btcec"
+	"github.com/keep-network/keep-common/pkg/chain/ethereum/ethutil"
 	"github.com/keep-network/keep-core/pkg/net"
+	"github.com/keep-network/keep-core/pkg/net/gen/pb"
+	"github.com/keep-network/keep-core/pkg/net/key"
+	"github.com/keep-network/keep-core/pkg/operator"
 	crypto "github.com/libp2p/go-libp2p-core/crypto"
 	peer "github.com/libp2p/go-libp2p-core/peer"
 	pubsub "github.com/libp2p/go-libp2p-pubsub"
@@ -176,7 +180,10 @@ func TestCreateTopicValidator(t *testing.T) {
 	for i, publicKey := range publicKeys {
 		authorID, _ := peer.IDFromPublicKey(publicKey)
 		authorIDBytes, _ := authorID.Marshal()
-		message := &pubsubpb.Message{From: authorIDBytes}
+		message := &pubsubpb.Message{
+			From: authorIDBytes,
+			Data: newTestMessageData(t, &identity{id: authorID, pubKey: publicKey}),
+		}
 
 		actualResult := validator(nil, peer.ID(i), &pubsub.Message{Message: message})
 
@@ -192,6 +199,66 @@ func TestCreateTopicValidator(t *testing.T) {
 	}
 }
 
+func TestCreateTopicValidatorWithDelegation(t *testing.T) {
+	networkPrivateKey, networkPublicKey, err := key.GenerateStaticNetworkKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	operatorPrivateKey, operatorPublicKey, err := operator.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	delegation, err := key.SignDelegation(
+		networkPublicKey,
+		operatorPublicKey,
+		1,
+		time.Now().Add(time.Hour),
+		ethutil.NewSigner(operatorPrivateKey).Sign,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	author, err := createIdentity(networkPrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	author.setDelegation(delegation)
+
+	// only the operator is authorized, the network key is not
+	filter := func(publicKey *ecdsa.PublicKey) bool {
+		return toEncodedBytes(publicKey) == toEncodedBytes(operatorPublicKey)
+	}
+
+	validator := createTopicValidator(filter)
+
+	authorIDBytes, _ := author.id.Marshal()
+	message := &pubsubpb.Message{
+		From: authorIDBytes,
+		Data: newTestMessageData(t, author),
+	}
+
+	if !validator(nil, author.id, &pubsub.Message{Message: message}) {
+		t.Errorf("message of delegated network key should be accepted")
+	}
+}
+
+func newTestMessageData(t *testing.T, sender *identity) []byte {
+	senderBytes, err := sender.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := (&pb.BroadcastNetworkMessage{Sender: senderBytes}).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
 func toEcdsaPublicKey(publicKey crypto.PubKey) *ecdsa.PublicKey {
 	secp256k1PublicKey, _ := publicKey.(*crypto.Secp256k1PublicKey)
 	return (*btcec.PublicKey)(secp256k1PublicKey).ToECDSA()
@@ -0,0 +1,117 @@
+package libp2p
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+const (
+	// plainEnvelopeVersion is the version of broadcast message envelopes
+	// understood by clients which predate envelope versioning. Payloads of
+	// such envelopes are never compressed.
+	plainEnvelopeVersion uint32 = 0
+	// compressedEnvelopeVersion is the version of broadcast message envelopes
+	// which payloads can be compressed.
+	compressedEnvelopeVersion uint32 = 1
+	// currentEnvelopeVersion is the version of envelopes sent by this client.
+	currentEnvelopeVersion = compressedEnvelopeVersion
+
+	// compressionThreshold is the payload size in bytes from which payloads
+	// are compressed. Compressing smaller payloads is not worth the effort.
+	compressionThreshold = 1024
+	// maxDecompressedPayloadSize is the maximum size in bytes of a payload
+	// after decompression. Larger payloads are rejected so that a small
+	// malicious message can not exhaust the memory of the client.
+	maxDecompressedPayloadSize = 8 * 1024 * 1024
+)
+
+// compressPayload compresses the payload with DEFLATE if the payload is not
+// smaller than the compression threshold. The second returned value is true
+// if the payload has been compressed; it is false if the payload has been
+// left intact because it is too small or compression would not make it
+// smaller.
+func compressPayload(payload []byte) ([]byte, bool, error) {
+	if len(payload) < compressionThreshold {
+		return payload, false, nil
+	}
+
+	var buffer bytes.Buffer
+	writer, err := flate.NewWriter(&buffer, flate.DefaultCompression)
+	if err != nil {
+		return nil, false, err
+	}
+	if _, err := writer.Write(payload); err != nil {
+		return nil, false, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, false, err
+	}
+
+	if buffer.Len() >= len(payload) {
+		return payload, false, nil
+	}
+
+	return buffer.Bytes(), true, nil
+}
+
+// decompressPayload decompresses the payload compressed with compressPayload.
+// It fails if the decompressed payload exceeds maxDecompressedPayloadSize.
+func decompressPayload(payload []byte) ([]byte, error) {
+	reader := flate.NewReader(bytes.NewReader(payload))
+	defer reader.Close()
+
+	decompressed, err := ioutil.ReadAll(
+		io.LimitReader(reader, maxDecompressedPayloadSize+1),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not decompress payload: [%v]", err)
+	}
+
+	if len(decompressed) > maxDecompressedPayloadSize {
+		return nil, fmt.Errorf(
+			"decompressed payload exceeds [%v] bytes",
+			maxDecompressedPayloadSize,
+		)
+	}
+
+	return decompressed, nil
+}
+
+// envelopeVersions keeps track of envelope versions supported by peers, as
+// negotiated during the connection handshake. Peers connected with the legacy
+// handshake protocol support only plain envelopes.
+type envelopeVersions struct {
+	mutex    sync.RWMutex
+	versions map[peer.ID]uint32
+}
+
+func newEnvelopeVersions() *envelopeVersions {
+	return &envelopeVersions{versions: make(map[peer.ID]uint32)}
+}
+
+func (ev *envelopeVersions) put(peerID peer.ID, version uint32) {
+	ev.mutex.Lock()
+	defer ev.mutex.Unlock()
+
+	ev.versions[peerID] = version
+}
+
+// get returns the envelope version supported by the peer. Unknown peers are
+// assumed to support only plain envelopes.
+func (ev *envelopeVersions) get(peerID peer.ID) uint32 {
+	ev.mutex.RLock()
+	defer ev.mutex.RUnlock()
+
+	version, ok := ev.versions[peerID]
+	if !ok {
+		return plainEnvelopeVersion
+	}
+
+	return version
+}
@@ -0,0 +1,303 @@
+package libp2p
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/keep-network/keep-core/pkg/net/gen/pb"
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+func TestCompressPayloadRoundTrip(t *testing.T) {
+	payload := bytes.Repeat([]byte("keep random beacon "), 1000)
+
+	compressedPayload, compressed, err := compressPayload(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !compressed {
+		t.Fatal("expected payload to be compressed")
+	}
+	if len(compressedPayload) >= len(payload) {
+		t.Errorf(
+			"compressed payload is not smaller\noriginal:   [%v]\ncompressed: [%v]",
+			len(payload),
+			len(compressedPayload),
+		)
+	}
+
+	decompressedPayload, err := decompressPayload(compressedPayload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(payload, decompressedPayload) {
+		t.Errorf("decompressed payload does not match the original one")
+	}
+}
+
+func TestCompressPayloadLeavesSmallPayloadIntact(t *testing.T) {
+	payload := bytes.Repeat([]byte{0x01}, compressionThreshold-1)
+
+	result, compressed, err := compressPayload(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if compressed {
+		t.Errorf("expected payload not to be compressed")
+	}
+	if !bytes.Equal(payload, result) {
+		t.Errorf("expected payload to be left intact")
+	}
+}
+
+func TestCompressPayloadLeavesIncompressiblePayloadIntact(t *testing.T) {
+	payload := make([]byte, 4*compressionThreshold)
+	if _, err := rand.Read(payload); err != nil {
+		t.Fatal(err)
+	}
+
+	result, compressed, err := compressPayload(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if compressed {
+		t.Errorf("expected payload not to be compressed")
+	}
+	if !bytes.Equal(payload, result) {
+		t.Errorf("expected payload to be left intact")
+	}
+}
+
+func TestDecompressPayloadRejectsOversizedPayload(t *testing.T) {
+	payload := make([]byte, maxDecompressedPayloadSize+1)
+
+	compressedPayload, compressed, err := compressPayload(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !compressed {
+		t.Fatal("expected payload to be compressed")
+	}
+
+	_, err = decompressPayload(compressedPayload)
+	if err == nil {
+		t.Fatal("expected error for oversized payload")
+	}
+}
+
+func TestDecompressPayloadRejectsCorruptedPayload(t *testing.T) {
+	_, err := decompressPayload([]byte{0xff, 0xff, 0xff, 0xff})
+	if err == nil {
+		t.Fatal("expected error for corrupted payload")
+	}
+}
+
+func TestMessagePayload(t *testing.T) {
+	payload := bytes.Repeat([]byte("keep random beacon "), 1000)
+	compressedPayload, _, err := compressPayload(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = map[string]struct {
+		message         pb.BroadcastNetworkMessage
+		expectedPayload []byte
+		expectError     bool
+	}{
+		"plain envelope": {
+			message: pb.BroadcastNetworkMessage{
+				Payload: payload,
+				Version: plainEnvelopeVersion,
+			},
+			expectedPayload: payload,
+		},
+		"uncompressed payload in versioned envelope": {
+			message: pb.BroadcastNetworkMessage{
+				Payload: payload,
+				Version: compressedEnvelopeVersion,
+			},
+			expectedPayload: payload,
+		},
+		"compressed payload in versioned envelope": {
+			message: pb.BroadcastNetworkMessage{
+				Payload:    compressedPayload,
+				Version:    compressedEnvelopeVersion,
+				Compressed: true,
+			},
+			expectedPayload: payload,
+		},
+		"compressed payload in plain envelope": {
+			message: pb.BroadcastNetworkMessage{
+				Payload:    compressedPayload,
+				Version:    plainEnvelopeVersion,
+				Compressed: true,
+			},
+			expectError: true,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			channel := &channel{}
+
+			actualPayload, err := channel.messagePayload(test.message)
+
+			if test.expectError {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(test.expectedPayload, actualPayload) {
+				t.Errorf("unexpected payload")
+			}
+		})
+	}
+}
+
+func TestEnvelopeVersions(t *testing.T) {
+	versions := newEnvelopeVersions()
+
+	legacyPeer := peer.ID("legacy")
+	upgradedPeer := peer.ID("upgraded")
+	unknownPeer := peer.ID("unknown")
+
+	versions.put(legacyPeer, plainEnvelopeVersion)
+	versions.put(upgradedPeer, compressedEnvelopeVersion)
+
+	var tests = map[string]struct {
+		peerID          peer.ID
+		expectedVersion uint32
+	}{
+		"legacy peer":   {legacyPeer, plainEnvelopeVersion},
+		"upgraded peer": {upgradedPeer, compressedEnvelopeVersion},
+		"unknown peer":  {unknownPeer, plainEnvelopeVersion},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			actualVersion := versions.get(test.peerID)
+			if actualVersion != test.expectedVersion {
+				t.Errorf(
+					"unexpected envelope version\nexpected: [%v]\nactual:   [%v]",
+					test.expectedVersion,
+					actualVersion,
+				)
+			}
+		})
+	}
+}
+
+func TestSubscribersSupportCompression(t *testing.T) {
+	legacyPeer := peer.ID("legacy")
+	upgradedPeer := peer.ID("upgraded")
+	unknownPeer := peer.ID("unknown")
+
+	var tests = map[string]struct {
+		directPeers       []peer.ID
+		publishers        map[peer.ID]uint32
+		expectedSupported bool
+	}{
+		"no known subscribers": {
+			expectedSupported: false,
+		},
+		"upgraded direct peer": {
+			directPeers:       []peer.ID{upgradedPeer},
+			expectedSupported: true,
+		},
+		"legacy direct peer": {
+			directPeers:       []peer.ID{upgradedPeer, legacyPeer},
+			expectedSupported: false,
+		},
+		"direct peer with unknown version": {
+			directPeers:       []peer.ID{upgradedPeer, unknownPeer},
+			expectedSupported: false,
+		},
+		"upgraded publisher": {
+			directPeers: []peer.ID{upgradedPeer},
+			publishers: map[peer.ID]uint32{
+				peer.ID("remote"): compressedEnvelopeVersion,
+			},
+			expectedSupported: true,
+		},
+		"legacy publisher not connected directly": {
+			directPeers: []peer.ID{upgradedPeer},
+			publishers: map[peer.ID]uint32{
+				peer.ID("remote"): plainEnvelopeVersion,
+			},
+			expectedSupported: false,
+		},
+		"legacy publisher only": {
+			publishers: map[peer.ID]uint32{
+				peer.ID("remote"): plainEnvelopeVersion,
+			},
+			expectedSupported: false,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			versions := newEnvelopeVersions()
+			versions.put(legacyPeer, plainEnvelopeVersion)
+			versions.put(upgradedPeer, compressedEnvelopeVersion)
+
+			channel := &channel{
+				clientIdentity:   &identity{id: peer.ID("self")},
+				envelopeVersions: versions,
+			}
+			for publisher, version := range test.publishers {
+				channel.recordPublisher(publisher, version)
+			}
+			// Own messages are not taken into account.
+			channel.recordPublisher(peer.ID("self"), plainEnvelopeVersion)
+
+			supported := channel.subscribersSupportCompression(test.directPeers)
+			if supported != test.expectedSupported {
+				t.Errorf(
+					"unexpected compression support\nexpected: [%v]\nactual:   [%v]",
+					test.expectedSupported,
+					supported,
+				)
+			}
+		})
+	}
+}
+
+func TestEnvelopeFieldsMarshalRoundTrip(t *testing.T) {
+	message := &pb.BroadcastNetworkMessage{
+		Sender:         []byte{0x01},
+		Payload:        []byte{0x02},
+		Type:           []byte("type"),
+		SequenceNumber: 3,
+		Version:        compressedEnvelopeVersion,
+		Compressed:     true,
+	}
+
+	messageBytes, err := message.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unmarshaled := &pb.BroadcastNetworkMessage{}
+	if err := unmarshaled.Unmarshal(messageBytes); err != nil {
+		t.Fatal(err)
+	}
+
+	if !message.Equal(unmarshaled) {
+		t.Errorf(
+			"unexpected message\nexpected: [%v]\nactual:   [%v]",
+			message,
+			unmarshaled,
+		)
+	}
+}
@@ -0,0 +1,136 @@
+package libp2p
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/keep-network/keep-core/pkg/net"
+	"github.com/keep-network/keep-core/pkg/net/key"
+)
+
+// hostedOperatorProvider is the network provider of an additional operator
+// hosted by the client. It shares the host, connections and channels with the
+// provider of the host but sends messages on behalf of its own identity, which
+// carries the operator's delegation of the network key of the host.
+type hostedOperatorProvider struct {
+	*provider
+
+	identity *identity
+
+	unicastChannelsMutex sync.Mutex
+	unicastChannels      map[*unicastChannel]*hostedUnicastChannel
+}
+
+func (p *provider) HostOperator(
+	delegation *key.Delegation,
+) (net.Provider, error) {
+	networkPublicKey := key.Libp2pKeyToNetworkKey(p.identity.pubKey)
+	if err := delegation.Verify(networkPublicKey); err != nil {
+		return nil, fmt.Errorf("invalid network key delegation: [%v]", err)
+	}
+
+	identity := &identity{
+		id:      p.identity.id,
+		pubKey:  p.identity.pubKey,
+		privKey: p.identity.privKey,
+	}
+	identity.setDelegation(delegation)
+
+	return &hostedOperatorProvider{
+		provider:        p,
+		identity:        identity,
+		unicastChannels: make(map[*unicastChannel]*hostedUnicastChannel),
+	}, nil
+}
+
+func (hop *hostedOperatorProvider) UnicastChannelWith(
+	peerID net.TransportIdentifier,
+) (net.UnicastChannel, error) {
+	channel, err := hop.unicastChannelManager.getUnicastChannelWithHandshake(
+		peerID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return hop.hostedUnicastChannel(channel), nil
+}
+
+func (hop *hostedOperatorProvider) OnUnicastChannelOpened(
+	handler func(channel net.UnicastChannel),
+) {
+	hop.unicastChannelManager.onChannelOpened(
+		func(channel net.UnicastChannel) {
+			handler(hop.hostedUnicastChannel(channel.(*unicastChannel)))
+		},
+	)
+}
+
+// hostedUnicastChannel returns the view of the unicast channel sending
+// messages on behalf of the operator. The same view is returned for the same
+// channel so that views can be compared by the protocol layer.
+func (hop *hostedOperatorProvider) hostedUnicastChannel(
+	channel *unicastChannel,
+) *hostedUnicastChannel {
+	hop.unicastChannelsMutex.Lock()
+	defer hop.unicastChannelsMutex.Unlock()
+
+	hosted, ok := hop.unicastChannels[channel]
+	if !ok {
+		hosted = &hostedUnicastChannel{channel, hop.identity}
+		hop.unicastChannels[channel] = hosted
+	}
+
+	return hosted
+}
+
+func (hop *hostedOperatorProvider) BroadcastChannelFor(
+	name string,
+) (net.BroadcastChannel, error) {
+	hop.channelManagerMutex.Lock()
+	defer hop.channelManagerMutex.Unlock()
+
+	channel, err := hop.broadcastChannelManager.getChannel(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &hostedBroadcastChannel{channel, hop.identity}, nil
+}
+
+func (hop *hostedOperatorProvider) UpdateDelegation(
+	delegation *key.Delegation,
+) error {
+	return updateDelegation(hop.identity, delegation)
+}
+
+// hostedBroadcastChannel is the view of a broadcast channel of the host
+// sending messages on behalf of a hosted operator. Messages received by the
+// channel are delivered to handlers of all operators, just like they would be
+// if the operators had separate hosts. The channel filter is shared by all
+// operators as well; operators of the same group set the same filter.
+type hostedBroadcastChannel struct {
+	*channel
+
+	sender *identity
+}
+
+func (hbc *hostedBroadcastChannel) Send(
+	ctx context.Context,
+	message net.TaggedMarshaler,
+) error {
+	return hbc.sendFrom(ctx, hbc.sender, message)
+}
+
+// hostedUnicastChannel is the view of a unicast channel of the host sending
+// messages on behalf of a hosted operator.
+type hostedUnicastChannel struct {
+	*unicastChannel
+
+	sender *identity
+}
+
+func (huc *hostedUnicastChannel) Send(message net.TaggedMarshaler) error {
+	return huc.sendFrom(huc.sender, message)
+}
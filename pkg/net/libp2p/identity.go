@@ -2,8 +2,11 @@ package libp2p
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/keep-network/keep-core/pkg/net/gen/pb"
+	"github.com/keep-network/keep-core/pkg/net/key"
+	"github.com/keep-network/keep-core/pkg/operator"
 
 	libp2pcrypto "github.com/libp2p/go-libp2p-core/crypto"
 	peer "github.com/libp2p/go-libp2p-core/peer"
@@ -15,10 +18,17 @@ import (
 //
 // Consumers of the net package require an ID to register with protocol level
 // IDs, as well as a public key for authentication.
+//
+// If the network key is not the operator key, the identity carries the
+// delegation of the network key signed with the operator key. The delegation
+// of the local identity can be renewed while the provider is running.
 type identity struct {
 	id      peer.ID
 	pubKey  libp2pcrypto.PubKey
 	privKey libp2pcrypto.PrivKey
+
+	delegationMutex sync.RWMutex
+	delegation      *key.Delegation
 }
 
 type networkIdentity peer.ID
@@ -32,7 +42,56 @@ func createIdentity(privateKey libp2pcrypto.PrivKey) (*identity, error) {
 		)
 	}
 
-	return &identity{peerID, privateKey.GetPublic(), privateKey}, nil
+	return &identity{
+		id:      peerID,
+		pubKey:  privateKey.GetPublic(),
+		privKey: privateKey,
+	}, nil
+}
+
+func (i *identity) currentDelegation() *key.Delegation {
+	i.delegationMutex.RLock()
+	defer i.delegationMutex.RUnlock()
+
+	return i.delegation
+}
+
+func (i *identity) setDelegation(delegation *key.Delegation) {
+	i.delegationMutex.Lock()
+	defer i.delegationMutex.Unlock()
+
+	i.delegation = delegation
+}
+
+// operatorPublicKey returns the public key of the operator represented by
+// this identity. It is the key of the delegating operator if the identity
+// carries a delegation and the network key itself otherwise.
+func (i *identity) operatorPublicKey() (*operator.PublicKey, error) {
+	if delegation := i.currentDelegation(); delegation != nil {
+		return delegation.OperatorPublicKey(), nil
+	}
+
+	networkKey := key.Libp2pKeyToNetworkKey(i.pubKey)
+	if networkKey == nil {
+		return nil, fmt.Errorf(
+			"identity [%v] with key [%v] is not of correct type",
+			i.id,
+			i.pubKey,
+		)
+	}
+
+	return key.NetworkKeyToECDSAKey(networkKey), nil
+}
+
+// marshaledDelegation returns the marshaled delegation carried by this
+// identity or nil if the identity carries no delegation.
+func (i *identity) marshaledDelegation() ([]byte, error) {
+	delegation := i.currentDelegation()
+	if delegation == nil {
+		return nil, nil
+	}
+
+	return delegation.Marshal()
 }
 
 func (ni networkIdentity) String() string {
@@ -56,7 +115,16 @@ func (i *identity) Marshal() ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-	return (&pb.Identity{PubKey: pubKeyBytes}).Marshal()
+
+	delegationBytes, err := i.marshaledDelegation()
+	if err != nil {
+		return nil, err
+	}
+
+	return (&pb.Identity{
+		PubKey:     pubKeyBytes,
+		Delegation: delegationBytes,
+	}).Marshal()
 }
 
 func (i *identity) Unmarshal(bytes []byte) error {
@@ -82,5 +150,146 @@ func (i *identity) Unmarshal(bytes []byte) error {
 	}
 	i.id = pid
 
+	delegation, err := verifiedDelegation(pbIdentity.Delegation, i.pubKey)
+	if err != nil {
+		return fmt.Errorf("invalid identity delegation: [%v]", err)
+	}
+	i.setDelegation(delegation)
+
+	return nil
+}
+
+// verifiedDelegation unmarshals the delegation and verifies it against the
+// provided network key. If there are no delegation bytes, the network key is
+// the operator key and nil delegation is returned.
+func verifiedDelegation(
+	delegationBytes []byte,
+	publicKey libp2pcrypto.PubKey,
+) (*key.Delegation, error) {
+	if len(delegationBytes) == 0 {
+		return nil, nil
+	}
+
+	networkKey := key.Libp2pKeyToNetworkKey(publicKey)
+	if networkKey == nil {
+		return nil, fmt.Errorf("network key is not of correct type")
+	}
+
+	delegation, err := key.UnmarshalDelegation(delegationBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := delegation.Verify(networkKey); err != nil {
+		return nil, err
+	}
+
+	return delegation, nil
+}
+
+// operatorKeys keeps track of operator public keys of peers authenticated
+// during the connection handshake.
+type operatorKeys struct {
+	mutex sync.RWMutex
+	keys  map[peer.ID]*operator.PublicKey
+}
+
+func newOperatorKeys() *operatorKeys {
+	return &operatorKeys{keys: make(map[peer.ID]*operator.PublicKey)}
+}
+
+func (k *operatorKeys) put(peerID peer.ID, publicKey *operator.PublicKey) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	k.keys[peerID] = publicKey
+}
+
+func (k *operatorKeys) get(peerID peer.ID) (*operator.PublicKey, bool) {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+
+	publicKey, found := k.keys[peerID]
+	return publicKey, found
+}
+
+// delegationSequences keeps track of the highest sequence number of network
+// key delegations seen for each operator during connection handshakes.
+// A delegation with a lower sequence number has been replaced by the operator
+// and is rejected even if it has not expired yet. When a delegation with
+// a higher sequence number is seen for another network key, the peer
+// representing the operator with the replaced network key is disconnected.
+type delegationSequences struct {
+	mutex      sync.Mutex
+	sequences  map[string]delegationSequence
+	disconnect func(peerID peer.ID)
+}
+
+type delegationSequence struct {
+	sequence uint64
+	peerID   peer.ID
+}
+
+func newDelegationSequences() *delegationSequences {
+	return &delegationSequences{
+		sequences: make(map[string]delegationSequence),
+	}
+}
+
+// onReplaced sets the function disconnecting peers whose delegations have
+// been replaced.
+func (ds *delegationSequences) onReplaced(disconnect func(peerID peer.ID)) {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	ds.disconnect = disconnect
+}
+
+// observe checks the delegation of the given peer against the highest
+// sequence number seen for the delegating operator and records the sequence
+// number if it is higher. Peers without delegations are not checked.
+func (ds *delegationSequences) observe(
+	peerID peer.ID,
+	delegation *key.Delegation,
+) error {
+	if delegation == nil {
+		return nil
+	}
+
+	operatorKey := string(operator.Marshal(delegation.OperatorPublicKey()))
+
+	ds.mutex.Lock()
+	latest, seen := ds.sequences[operatorKey]
+	if seen && delegation.Sequence() < latest.sequence {
+		ds.mutex.Unlock()
+		return fmt.Errorf(
+			"delegation with sequence [%v] has been replaced by "+
+				"delegation with sequence [%v]",
+			delegation.Sequence(),
+			latest.sequence,
+		)
+	}
+
+	var replaced peer.ID
+	if !seen || delegation.Sequence() > latest.sequence {
+		ds.sequences[operatorKey] = delegationSequence{
+			sequence: delegation.Sequence(),
+			peerID:   peerID,
+		}
+		if seen && latest.peerID != peerID {
+			replaced = latest.peerID
+		}
+	}
+	disconnect := ds.disconnect
+	ds.mutex.Unlock()
+
+	if replaced != "" && disconnect != nil {
+		logger.Warningf(
+			"disconnecting peer [%v] with replaced network key delegation",
+			replaced,
+		)
+		disconnect(replaced)
+	}
+
 	return nil
 }
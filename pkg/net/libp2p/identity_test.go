@@ -0,0 +1,123 @@
+package libp2p
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keep-network/keep-common/pkg/chain/ethereum/ethutil"
+	"github.com/keep-network/keep-core/pkg/net/key"
+	"github.com/keep-network/keep-core/pkg/operator"
+	peer "github.com/libp2p/go-libp2p-core/peer"
+)
+
+func TestDelegationSequencesRejectReplacedDelegation(t *testing.T) {
+	operatorPrivateKey, _, err := operator.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sequences := newDelegationSequences()
+
+	newPeer, newDelegation := newTestDelegatedPeer(t, operatorPrivateKey, 2)
+	if err := sequences.observe(newPeer, newDelegation); err != nil {
+		t.Fatal(err)
+	}
+
+	oldPeer, oldDelegation := newTestDelegatedPeer(t, operatorPrivateKey, 1)
+	err = sequences.observe(oldPeer, oldDelegation)
+
+	expectedError := "delegation with sequence [1] has been replaced by " +
+		"delegation with sequence [2]"
+	if err == nil || err.Error() != expectedError {
+		t.Fatalf(
+			"unexpected error\nexpected: [%v]\nactual:   [%v]",
+			expectedError,
+			err,
+		)
+	}
+
+	// the peer presenting the latest delegation can reconnect
+	if err := sequences.observe(newPeer, newDelegation); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDelegationSequencesDisconnectReplacedPeer(t *testing.T) {
+	operatorPrivateKey, _, err := operator.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var disconnected []peer.ID
+	sequences := newDelegationSequences()
+	sequences.onReplaced(func(peerID peer.ID) {
+		disconnected = append(disconnected, peerID)
+	})
+
+	oldPeer, oldDelegation := newTestDelegatedPeer(t, operatorPrivateKey, 1)
+	if err := sequences.observe(oldPeer, oldDelegation); err != nil {
+		t.Fatal(err)
+	}
+
+	newPeer, newDelegation := newTestDelegatedPeer(t, operatorPrivateKey, 2)
+	if err := sequences.observe(newPeer, newDelegation); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(disconnected) != 1 || disconnected[0] != oldPeer {
+		t.Errorf(
+			"unexpected disconnected peers\nexpected: [%v]\nactual:   [%v]",
+			[]peer.ID{oldPeer},
+			disconnected,
+		)
+	}
+}
+
+func TestDelegationSequencesOtherOperators(t *testing.T) {
+	sequences := newDelegationSequences()
+
+	for sequence := uint64(3); sequence > 0; sequence-- {
+		operatorPrivateKey, _, err := operator.GenerateKeyPair()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		peerID, delegation := newTestDelegatedPeer(
+			t,
+			operatorPrivateKey,
+			sequence,
+		)
+		if err := sequences.observe(peerID, delegation); err != nil {
+			t.Errorf("unexpected error: [%v]", err)
+		}
+	}
+}
+
+func newTestDelegatedPeer(
+	t *testing.T,
+	operatorPrivateKey *operator.PrivateKey,
+	sequence uint64,
+) (peer.ID, *key.Delegation) {
+	networkPrivateKey, networkPublicKey, err := key.GenerateStaticNetworkKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	peerID, err := peer.IDFromPrivateKey(networkPrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	delegation, err := key.SignDelegation(
+		networkPublicKey,
+		&operatorPrivateKey.PublicKey,
+		sequence,
+		time.Now().Add(time.Hour),
+		ethutil.NewSigner(operatorPrivateKey).Sign,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return peerID, delegation
+}
@@ -1,6 +1,7 @@
 package libp2p
 
 import (
+	"bytes"
 	"context"
 	"crypto/ecdsa"
 	"fmt"
@@ -13,6 +14,7 @@ import (
 	"github.com/keep-network/keep-core/pkg/net/key"
 	"github.com/keep-network/keep-core/pkg/net/retransmission"
 	"github.com/keep-network/keep-core/pkg/net/watchtower"
+	"github.com/keep-network/keep-core/pkg/operator"
 
 	dstore "github.com/ipfs/go-datastore"
 	dssync "github.com/ipfs/go-datastore/sync"
@@ -28,6 +30,7 @@ import (
 	rhost "github.com/libp2p/go-libp2p/p2p/host/routed"
 
 	bootstrap "github.com/keep-network/go-libp2p-bootstrap"
+	"github.com/keep-network/keep-core/pkg/metrics"
 	ma "github.com/multiformats/go-multiaddr"
 )
 
@@ -79,19 +82,93 @@ type Config struct {
 	DisseminationTime  int
 }
 
+// Reconfigurable is implemented by network providers whose configuration can
+// be changed while they are running.
+type Reconfigurable interface {
+	// Reconfigure applies the bootstrap peers, announced addresses and
+	// dissemination time from the provided config. Other values of the
+	// config are ignored. Bootstrap peers are used starting from the next
+	// bootstrap round, announced addresses are announced to peers starting
+	// from the next identification, and the dissemination time applies to
+	// message forwarders started after the reconfiguration.
+	Reconfigure(config Config) error
+}
+
+// OperatorHost is implemented by network providers able to host additional
+// operators on the same network host.
+type OperatorHost interface {
+	// HostOperator returns a network provider acting on behalf of the
+	// operator who issued the provided delegation of the network key of the
+	// host. The returned provider shares the host, its connections and
+	// channels with the host provider but attaches the delegation to all
+	// messages it sends so that peers attribute them to the operator. The
+	// returned provider implements Delegated so that the delegation can be
+	// renewed.
+	HostOperator(delegation *key.Delegation) (net.Provider, error)
+}
+
+// Delegated is implemented by network providers whose network key is
+// delegated by the operator and whose delegation can be renewed while they
+// are running.
+type Delegated interface {
+	// UpdateDelegation replaces the delegation presented to other peers with
+	// the provided one. The delegation has to be issued by the same operator
+	// for the network key of the provider and can not have a lower sequence
+	// number than the current delegation. The new delegation is presented
+	// starting from the next connection handshake and attached to all
+	// messages sent from now on.
+	UpdateDelegation(delegation *key.Delegation) error
+}
+
 type provider struct {
 	channelManagerMutex     sync.Mutex
 	broadcastChannelManager *channelManager
 	unicastChannelManager   *unicastChannelManager
 
-	identity          *identity
-	host              host.Host
-	routing           *dht.IpfsDHT
-	disseminationTime int
+	identity *identity
+	host     host.Host
+	routing  *dht.IpfsDHT
+
+	configMutex        sync.RWMutex
+	bootstrapPeers     []peerstore.PeerInfo
+	announcedAddresses *announcedAddresses
+	disseminationTime  int
 
 	connectionManager *connectionManager
 }
 
+// announcedAddresses holds addresses announced to peers instead of the
+// addresses the host is listening on.
+type announcedAddresses struct {
+	mutex     sync.RWMutex
+	addresses []ma.Multiaddr
+}
+
+func (aa *announcedAddresses) set(addresses []ma.Multiaddr) {
+	aa.mutex.Lock()
+	defer aa.mutex.Unlock()
+
+	aa.addresses = addresses
+}
+
+// replace is the host address factory returning the announced addresses if
+// they are configured or the listen addresses otherwise.
+func (aa *announcedAddresses) replace(addrs []ma.Multiaddr) []ma.Multiaddr {
+	aa.mutex.RLock()
+	defer aa.mutex.RUnlock()
+
+	if len(aa.addresses) == 0 {
+		return addrs
+	}
+
+	logger.Debugf(
+		"replacing default announced addresses [%v] with [%v]",
+		addrs,
+		aa.addresses,
+	)
+	return aa.addresses
+}
+
 func (p *provider) UnicastChannelWith(
 	peerID net.TransportIdentifier,
 ) (net.UnicastChannel, error) {
@@ -118,6 +195,41 @@ func (p *provider) ID() net.TransportIdentifier {
 	return networkIdentity(p.identity.id)
 }
 
+func (p *provider) UpdateDelegation(delegation *key.Delegation) error {
+	return updateDelegation(p.identity, delegation)
+}
+
+// updateDelegation replaces the delegation carried by the given identity
+// with a delegation issued by the same operator.
+func updateDelegation(identity *identity, delegation *key.Delegation) error {
+	networkPublicKey := key.Libp2pKeyToNetworkKey(identity.pubKey)
+	if err := delegation.Verify(networkPublicKey); err != nil {
+		return fmt.Errorf("invalid network key delegation: [%v]", err)
+	}
+
+	current := identity.currentDelegation()
+	if current == nil {
+		return fmt.Errorf("network key is not delegated")
+	}
+	if !bytes.Equal(
+		operator.Marshal(current.OperatorPublicKey()),
+		operator.Marshal(delegation.OperatorPublicKey()),
+	) {
+		return fmt.Errorf("delegation issued by another operator")
+	}
+	if delegation.Sequence() < current.Sequence() {
+		return fmt.Errorf(
+			"delegation sequence [%v] is lower than current sequence [%v]",
+			delegation.Sequence(),
+			current.Sequence(),
+		)
+	}
+
+	identity.setDelegation(delegation)
+
+	return nil
+}
+
 func (p *provider) ConnectionManager() net.ConnectionManager {
 	return p.connectionManager
 }
@@ -131,12 +243,16 @@ func (p *provider) CreateTransportIdentifier(publicKey ecdsa.PublicKey) (
 }
 
 func (p *provider) BroadcastChannelForwarderFor(name string) {
-	if p.disseminationTime == 0 {
+	p.configMutex.RLock()
+	disseminationTime := p.disseminationTime
+	p.configMutex.RUnlock()
+
+	if disseminationTime == 0 {
 		return
 	}
 
 	logger.Infof("starting message forwarder for channel [%v]", name)
-	timeout := time.Duration(p.disseminationTime) * time.Second
+	timeout := time.Duration(disseminationTime) * time.Second
 
 	if err := p.broadcastChannelManager.newForwarder(name, timeout); err != nil {
 		logger.Warningf(
@@ -147,12 +263,87 @@ func (p *provider) BroadcastChannelForwarderFor(name string) {
 	}
 }
 
+func (p *provider) Reconfigure(config Config) error {
+	if err := validateDisseminationTime(config.DisseminationTime); err != nil {
+		return err
+	}
+
+	bootstrapPeers, err := extractMultiAddrFromPeers(config.Peers)
+	if err != nil {
+		return fmt.Errorf("invalid bootstrap peers: [%v]", err)
+	}
+
+	var addresses []ma.Multiaddr
+	for _, address := range config.AnnouncedAddresses {
+		multiaddress, err := ma.NewMultiaddr(address)
+		if err != nil {
+			return fmt.Errorf(
+				"invalid announced address [%v]: [%v]",
+				address,
+				err,
+			)
+		}
+		addresses = append(addresses, multiaddress)
+	}
+
+	p.configMutex.Lock()
+	defer p.configMutex.Unlock()
+
+	p.bootstrapPeers = bootstrapPeers
+	p.announcedAddresses.set(addresses)
+	p.disseminationTime = config.DisseminationTime
+
+	logger.Infof(
+		"reconfigured network provider; bootstrap peers [%v]; "+
+			"announced addresses [%v]; dissemination time [%v]",
+		len(bootstrapPeers),
+		addresses,
+		config.DisseminationTime,
+	)
+
+	return nil
+}
+
+// currentBootstrapPeers returns the bootstrap peers used in the next
+// bootstrap round.
+func (p *provider) currentBootstrapPeers() []peerstore.PeerInfo {
+	p.configMutex.RLock()
+	defer p.configMutex.RUnlock()
+
+	return p.bootstrapPeers
+}
+
+func validateDisseminationTime(disseminationTime int) error {
+	if disseminationTime < 0 || disseminationTime > MaximumDisseminationTime {
+		return fmt.Errorf(
+			"dissemination time mut be in range [0, %v]",
+			MaximumDisseminationTime,
+		)
+	}
+
+	return nil
+}
+
 type connectionManager struct {
 	host.Host
+
+	identity     *identity
+	operatorKeys *operatorKeys
+	reputation   *reputation
 }
 
-func newConnectionManager(ctx context.Context, host host.Host) *connectionManager {
-	connectionManager := &connectionManager{host}
+func newConnectionManager(
+	ctx context.Context,
+	host host.Host,
+	identity *identity,
+	operatorKeys *operatorKeys,
+) *connectionManager {
+	connectionManager := &connectionManager{
+		Host:         host,
+		identity:     identity,
+		operatorKeys: operatorKeys,
+	}
+	connectionManager.reputation = newReputation(connectionManager.DisconnectPeer)
 
 	go connectionManager.monitorConnectedPeers(ctx)
 
@@ -189,6 +380,32 @@ func (cm *connectionManager) GetPeerPublicKey(connectedPeer string) (*key.Networ
 	return key.Libp2pKeyToNetworkKey(peerPublicKey), nil
 }
 
+func (cm *connectionManager) GetPeerOperatorPublicKey(
+	connectedPeer string,
+) (*operator.PublicKey, error) {
+	peerID, err := peer.IDB58Decode(connectedPeer)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to decode peer ID from [%s]: [%v]",
+			connectedPeer,
+			err,
+		)
+	}
+
+	if peerID == cm.identity.id {
+		return cm.identity.operatorPublicKey()
+	}
+
+	if operatorPublicKey, found := cm.operatorKeys.get(peerID); found {
+		return operatorPublicKey, nil
+	}
+
+	return nil, fmt.Errorf(
+		"operator of peer [%s] has not been authenticated",
+		connectedPeer,
+	)
+}
+
 func (cm *connectionManager) DisconnectPeer(peerHash string) {
 	peerID, err := peer.IDB58Decode(peerHash)
 	if err != nil {
@@ -204,6 +421,12 @@ func (cm *connectionManager) DisconnectPeer(peerHash string) {
 	}
 }
 
+// PeerScores returns reputation scores of peers penalized for misbehavior
+// which have not fully recovered yet.
+func (cm *connectionManager) PeerScores() map[string]int {
+	return cm.reputation.peerScores()
+}
+
 func (cm *connectionManager) AddrStrings() []string {
 	multiaddrStrings := make([]string, 0, len(cm.Addrs()))
 	for _, multiaddr := range cm.Addrs() {
@@ -245,6 +468,8 @@ func (cm *connectionManager) monitorConnectedPeers(ctx context.Context) {
 // ConnectOptions allows to set various options used by libp2p.
 type ConnectOptions struct {
 	RoutingTableRefreshPeriod time.Duration
+	Delegation                *key.Delegation
+	Metrics                   *metrics.Network
 }
 
 func defaultConnectOptions() *ConnectOptions {
@@ -272,6 +497,24 @@ func WithRoutingTableRefreshPeriod(period time.Duration) ConnectOption {
 	}
 }
 
+// WithDelegation sets the delegation authorizing the static network key to
+// represent the operator. It should be set if the static network key is not
+// the operator key. The delegation is presented to other peers during the
+// connection handshake and attached to all sent messages.
+func WithDelegation(delegation *key.Delegation) ConnectOption {
+	return func(options *ConnectOptions) {
+		options.Delegation = delegation
+	}
+}
+
+// WithMetrics sets metrics of broadcast channels updated by the provider.
+// If not set, broadcast channels are not measured.
+func WithMetrics(networkMetrics *metrics.Network) ConnectOption {
+	return func(options *ConnectOptions) {
+		options.Metrics = networkMetrics
+	}
+}
+
 // Connect connects to a libp2p network based on the provided config. The
 // connection is managed in part by the passed context, and provides access to
 // the functionality specified in the net.Provider interface.
@@ -287,11 +530,8 @@ func Connect(
 	ticker *retransmission.Ticker,
 	options ...ConnectOption,
 ) (net.Provider, error) {
-	if config.DisseminationTime < 0 || config.DisseminationTime > MaximumDisseminationTime {
-		return nil, fmt.Errorf(
-			"dissemination time mut be in range [0, %v]",
-			MaximumDisseminationTime,
-		)
+	if err := validateDisseminationTime(config.DisseminationTime); err != nil {
+		return nil, err
 	}
 
 	connectOptions := defaultConnectOptions()
@@ -302,13 +542,29 @@ func Connect(
 		return nil, err
 	}
 
+	if delegation := connectOptions.Delegation; delegation != nil {
+		networkPublicKey := key.Libp2pKeyToNetworkKey(staticKey.GetPublic())
+		if err := delegation.Verify(networkPublicKey); err != nil {
+			return nil, fmt.Errorf("invalid network key delegation: [%v]", err)
+		}
+		identity.setDelegation(delegation)
+	}
+
+	operatorKeys := newOperatorKeys()
+	envelopeVersions := newEnvelopeVersions()
+
+	announcedAddresses := &announcedAddresses{}
+	announcedAddresses.set(parseMultiaddresses(config.AnnouncedAddresses))
+
 	host, err := discoverAndListen(
 		ctx,
 		identity,
 		config.Port,
 		protocol,
-		config.AnnouncedAddresses,
+		announcedAddresses,
 		firewall,
+		operatorKeys,
+		envelopeVersions,
 	)
 	if err != nil {
 		return nil, err
@@ -316,11 +572,6 @@ func Connect(
 
 	host.Network().Notify(buildNotifiee())
 
-	broadcastChannelManager, err := newChannelManager(ctx, identity, host, ticker)
-	if err != nil {
-		return nil, err
-	}
-
 	unicastChannelManager := newUnicastChannelManager(ctx, identity, host)
 
 	dhtDatastore := dssync.MutexWrap(dstore.NewMapDatastore())
@@ -338,23 +589,50 @@ func Connect(
 	}
 
 	provider := &provider{
-		broadcastChannelManager: broadcastChannelManager,
-		unicastChannelManager:   unicastChannelManager,
-		identity:                identity,
-		host:                    rhost.Wrap(host, router),
-		routing:                 router,
-		disseminationTime:       config.DisseminationTime,
+		unicastChannelManager: unicastChannelManager,
+		identity:              identity,
+		host:                  rhost.Wrap(host, router),
+		routing:               router,
+		announcedAddresses:    announcedAddresses,
+		disseminationTime:     config.DisseminationTime,
 	}
 
 	if len(config.Peers) == 0 {
 		logger.Infof("bootstrap peers list is empty")
 	}
 
-	if err := provider.bootstrap(ctx, config.Peers); err != nil {
+	provider.bootstrapPeers, err = extractMultiAddrFromPeers(config.Peers)
+	if err != nil {
 		return nil, fmt.Errorf("bootstrap failed: [%v]", err)
 	}
 
-	provider.connectionManager = newConnectionManager(ctx, provider.host)
+	provider.connectionManager = newConnectionManager(
+		ctx,
+		provider.host,
+		identity,
+		operatorKeys,
+	)
+
+	// Broadcast channels share the reputation of peers kept by the
+	// connection manager so that misbehaving peers can be disconnected.
+	provider.broadcastChannelManager, err = newChannelManager(
+		ctx,
+		identity,
+		host,
+		ticker,
+		provider.connectionManager.reputation,
+		envelopeVersions,
+		connectOptions.Metrics,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Pubsub protocols must be registered before connecting to bootstrap
+	// peers; otherwise peers that connect first never open broadcast streams.
+	if err := provider.bootstrap(); err != nil {
+		return nil, fmt.Errorf("bootstrap failed: [%v]", err)
+	}
 
 	// Instantiates and starts the connection management background process.
 	watchtower.NewGuard(
@@ -372,8 +650,10 @@ func discoverAndListen(
 	identity *identity,
 	port int,
 	protocol string,
-	announcedAddresses []string,
+	announcedAddresses *announcedAddresses,
 	firewall net.Firewall,
+	operatorKeys *operatorKeys,
+	envelopeVersions *envelopeVersions,
 ) (host.Host, error) {
 	var err error
 
@@ -383,10 +663,16 @@ func discoverAndListen(
 		return nil, err
 	}
 
+	delegationSequences := newDelegationSequences()
+
 	transport, err := newEncryptedAuthenticatedTransport(
-		identity.privKey,
+		identity,
 		protocol,
 		firewall,
+		operatorKeys,
+		delegationSequences,
+		currentEnvelopeVersion,
+		envelopeVersions,
 	)
 	if err != nil {
 		return nil, fmt.Errorf(
@@ -395,10 +681,29 @@ func discoverAndListen(
 		)
 	}
 
+	legacyTransport, err := newEncryptedAuthenticatedTransport(
+		identity,
+		protocol,
+		firewall,
+		operatorKeys,
+		delegationSequences,
+		plainEnvelopeVersion,
+		envelopeVersions,
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"could not create legacy authenticated transport: [%v]",
+			err,
+		)
+	}
+
 	options := []libp2p.Option{
 		libp2p.ListenAddrs(addrs...),
 		libp2p.Identity(identity.privKey),
-		libp2p.Security(handshakeID, transport),
+		// Security transports are negotiated in the order they are
+		// configured so the compression-capable transport is preferred.
+		libp2p.Security(compressionHandshakeID, transport),
+		libp2p.Security(handshakeID, legacyTransport),
 		libp2p.ConnectionManager(
 			connmgr.NewConnManager(
 				DefaultConnMgrLowWater,
@@ -406,21 +711,27 @@ func discoverAndListen(
 				DefaultConnMgrGracePeriod,
 			),
 		),
+		libp2p.AddrsFactory(announcedAddresses.replace),
+	}
+
+	host, err := libp2p.New(ctx, options...)
+	if err != nil {
+		return nil, err
 	}
 
-	if addresses := parseMultiaddresses(announcedAddresses); len(addresses) > 0 {
-		addressFactory := func(addrs []ma.Multiaddr) []ma.Multiaddr {
-			logger.Debugf(
-				"replacing default announced addresses [%v] with [%v]",
-				addrs,
-				addresses,
+	// Peers still presenting delegations replaced by their operators are
+	// disconnected as soon as a newer delegation is seen.
+	delegationSequences.onReplaced(func(peerID peer.ID) {
+		if err := host.Network().ClosePeer(peerID); err != nil {
+			logger.Warningf(
+				"could not disconnect peer [%v]: [%v]",
+				peerID,
+				err,
 			)
-			return addresses
 		}
-		options = append(options, libp2p.AddrsFactory(addressFactory))
-	}
+	})
 
-	return libp2p.New(ctx, options...)
+	return host, nil
 }
 
 func getListenAddrs(port int) ([]ma.Multiaddr, error) {
@@ -457,20 +768,15 @@ func parseMultiaddresses(addresses []string) []ma.Multiaddr {
 	return multiaddresses
 }
 
-func (p *provider) bootstrap(
-	ctx context.Context,
-	bootstrapPeers []string,
-) error {
-	peerInfos, err := extractMultiAddrFromPeers(bootstrapPeers)
-	if err != nil {
-		return err
-	}
-
-	bootstrapConfig := bootstrap.BootstrapConfigWithPeers(peerInfos)
+func (p *provider) bootstrap() error {
+	// Bootstrap peers are read before each bootstrap round so that they can
+	// be changed while the provider is running.
+	bootstrapConfig := bootstrap.BootstrapConfigWithPeers(nil)
+	bootstrapConfig.BootstrapPeers = p.currentBootstrapPeers
 
 	// TODO: use the io.Closer to shutdown the bootstrapper when we build out
 	// a shutdown process.
-	_, err = bootstrap.Bootstrap(
+	_, err := bootstrap.Bootstrap(
 		p.identity.id,
 		p.host,
 		p.routing,
@@ -1,6 +1,7 @@
 package libp2p
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -8,10 +9,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/keep-network/keep-common/pkg/chain/ethereum/ethutil"
 	"github.com/keep-network/keep-core/pkg/firewall"
 	"github.com/keep-network/keep-core/pkg/net"
 	"github.com/keep-network/keep-core/pkg/net/key"
 	"github.com/keep-network/keep-core/pkg/net/retransmission"
+	"github.com/keep-network/keep-core/pkg/operator"
 )
 
 func TestProviderReturnsType(t *testing.T) {
@@ -152,6 +155,230 @@ func TestSendReceive(t *testing.T) {
 	}
 }
 
+func TestHostedOperatorSendReceive(t *testing.T) {
+	ctx, cancel := newTestContext()
+	defer cancel()
+
+	name := "testchannel"
+
+	networkPrivateKey, networkPublicKey, err := key.GenerateStaticNetworkKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	operatorPrivateKey, operatorPublicKey, err := operator.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	delegation, err := key.SignDelegation(
+		networkPublicKey,
+		operatorPublicKey,
+		1,
+		time.Now().Add(time.Hour),
+		ethutil.NewSigner(operatorPrivateKey).Sign,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider, err := Connect(
+		ctx,
+		generateDeterministicNetworkConfig(),
+		networkPrivateKey,
+		ProtocolBeacon,
+		firewall.Disabled,
+		idleTicker(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hostedProvider, err := provider.(OperatorHost).HostOperator(delegation)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if hostedProvider.ID().String() != provider.ID().String() {
+		t.Errorf(
+			"unexpected hosted operator provider ID\n"+
+				"expected: [%v]\nactual:   [%v]",
+			provider.ID(),
+			hostedProvider.ID(),
+		)
+	}
+
+	hostChannel, err := provider.BroadcastChannelFor(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostChannel.SetUnmarshaler(
+		func() net.TaggedUnmarshaler { return &testMessage{} },
+	)
+
+	recvChan := make(chan net.Message, 1)
+	hostChannel.Recv(ctx, func(msg net.Message) {
+		recvChan <- msg
+	})
+
+	hostedChannel, err := hostedProvider.BroadcastChannelFor(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := hostedChannel.Send(
+		ctx,
+		&testMessage{Payload: "hosted operator"},
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case msg := <-recvChan:
+		if !bytes.Equal(
+			msg.SenderPublicKey(),
+			operator.Marshal(operatorPublicKey),
+		) {
+			t.Errorf("message should be attributed to the hosted operator")
+		}
+	case <-ctx.Done():
+		t.Fatal(ctx.Err())
+	}
+}
+
+func TestHostedOperatorUnicastToOwnPeer(t *testing.T) {
+	ctx, cancel := newTestContext()
+	defer cancel()
+
+	networkPrivateKey, networkPublicKey, err := key.GenerateStaticNetworkKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	operatorPrivateKey, operatorPublicKey, err := operator.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	delegation, err := key.SignDelegation(
+		networkPublicKey,
+		operatorPublicKey,
+		1,
+		time.Now().Add(time.Hour),
+		ethutil.NewSigner(operatorPrivateKey).Sign,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider, err := Connect(
+		ctx,
+		generateDeterministicNetworkConfig(),
+		networkPrivateKey,
+		ProtocolBeacon,
+		firewall.Disabled,
+		idleTicker(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hostedProvider, err := provider.(OperatorHost).HostOperator(delegation)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The host learns about the channel opened by the hosted operator with
+	// their own peer just like it would learn about a channel opened by
+	// a remote peer.
+	recvChan := make(chan net.Message, 1)
+	provider.OnUnicastChannelOpened(func(channel net.UnicastChannel) {
+		channel.SetUnmarshaler(
+			func() net.TaggedUnmarshaler { return &testMessage{} },
+		)
+		channel.Recv(ctx, func(msg net.Message) {
+			recvChan <- msg
+		})
+	})
+
+	hostedChannel, err := hostedProvider.UnicastChannelWith(provider.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := hostedChannel.Send(
+		&testMessage{Payload: "hosted operator"},
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case msg := <-recvChan:
+		if msg.Payload().(*testMessage).Payload != "hosted operator" {
+			t.Errorf(
+				"unexpected payload\nexpected: [%v]\nactual:   [%v]",
+				"hosted operator",
+				msg.Payload().(*testMessage).Payload,
+			)
+		}
+		if !bytes.Equal(
+			msg.SenderPublicKey(),
+			operator.Marshal(operatorPublicKey),
+		) {
+			t.Errorf("message should be attributed to the hosted operator")
+		}
+	case <-ctx.Done():
+		t.Fatal(ctx.Err())
+	}
+}
+
+func TestHostOperatorRejectsDelegationOfAnotherKey(t *testing.T) {
+	ctx, cancel := newTestContext()
+	defer cancel()
+
+	networkPrivateKey, _, err := key.GenerateStaticNetworkKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, otherNetworkPublicKey, err := key.GenerateStaticNetworkKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	operatorPrivateKey, operatorPublicKey, err := operator.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	delegation, err := key.SignDelegation(
+		otherNetworkPublicKey,
+		operatorPublicKey,
+		1,
+		time.Now().Add(time.Hour),
+		ethutil.NewSigner(operatorPrivateKey).Sign,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider, err := Connect(
+		ctx,
+		generateDeterministicNetworkConfig(),
+		networkPrivateKey,
+		ProtocolBeacon,
+		firewall.Disabled,
+		idleTicker(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := provider.(OperatorHost).HostOperator(delegation); err == nil {
+		t.Errorf("delegation of another network key should be rejected")
+	}
+}
+
 func TestProviderSetAnnouncedAddresses(t *testing.T) {
 	ctx, cancel := newTestContext()
 	defer cancel()
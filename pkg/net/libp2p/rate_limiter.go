@@ -0,0 +1,75 @@
+package libp2p
+
+import (
+	"sync"
+	"time"
+
+	peer "github.com/libp2p/go-libp2p-core/peer"
+)
+
+const (
+	// peerMessageRate is the number of messages per second a single peer can
+	// send to a single broadcast channel in the long run.
+	peerMessageRate = 32
+	// peerMessageBurst is the number of messages a single peer can send to
+	// a single broadcast channel at once. It has to accommodate all the
+	// messages sent by members of a peer which hosts the entire group, along
+	// with their retransmissions.
+	peerMessageBurst = 512
+)
+
+// rateLimiter limits the rate of messages received from each peer with
+// a token bucket. Each peer has a separate bucket filled at the configured
+// rate up to the configured burst size. Each message takes one token from
+// the bucket of its sender and is allowed only if there was a token left.
+type rateLimiter struct {
+	rate  float64
+	burst float64
+
+	mutex   sync.Mutex
+	buckets map[peer.ID]*tokenBucket
+
+	now func() time.Time
+}
+
+type tokenBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+func newRateLimiter(rate float64, burst float64) *rateLimiter {
+	return &rateLimiter{
+		rate:    rate,
+		burst:   burst,
+		buckets: make(map[peer.ID]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// allow takes a token from the bucket of the given peer and returns true
+// if the message from the peer should be processed.
+func (rl *rateLimiter) allow(peerID peer.ID) bool {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	now := rl.now()
+
+	bucket, ok := rl.buckets[peerID]
+	if !ok {
+		bucket = &tokenBucket{tokens: rl.burst, updatedAt: now}
+		rl.buckets[peerID] = bucket
+	}
+
+	bucket.tokens += now.Sub(bucket.updatedAt).Seconds() * rl.rate
+	if bucket.tokens > rl.burst {
+		bucket.tokens = rl.burst
+	}
+	bucket.updatedAt = now
+
+	if bucket.tokens < 1 {
+		return false
+	}
+
+	bucket.tokens--
+	return true
+}
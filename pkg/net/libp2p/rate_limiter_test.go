@@ -0,0 +1,56 @@
+package libp2p
+
+import (
+	"testing"
+	"time"
+
+	peer "github.com/libp2p/go-libp2p-core/peer"
+)
+
+func TestRateLimiter(t *testing.T) {
+	rateLimiter := newRateLimiter(2, 5)
+
+	now := time.Now()
+	rateLimiter.now = func() time.Time { return now }
+
+	peer1 := peer.ID("peer-1")
+	peer2 := peer.ID("peer-2")
+
+	for i := 0; i < 5; i++ {
+		if !rateLimiter.allow(peer1) {
+			t.Fatalf("message [%v] within the burst should be allowed", i)
+		}
+	}
+
+	if rateLimiter.allow(peer1) {
+		t.Errorf("message exceeding the burst should not be allowed")
+	}
+
+	if !rateLimiter.allow(peer2) {
+		t.Errorf("message of another peer should be allowed")
+	}
+
+	now = now.Add(time.Second)
+
+	for i := 0; i < 2; i++ {
+		if !rateLimiter.allow(peer1) {
+			t.Fatalf("message [%v] within the rate should be allowed", i)
+		}
+	}
+
+	if rateLimiter.allow(peer1) {
+		t.Errorf("message exceeding the rate should not be allowed")
+	}
+
+	now = now.Add(time.Hour)
+
+	for i := 0; i < 5; i++ {
+		if !rateLimiter.allow(peer1) {
+			t.Fatalf("message [%v] within the burst should be allowed", i)
+		}
+	}
+
+	if rateLimiter.allow(peer1) {
+		t.Errorf("bucket should not be filled above the burst")
+	}
+}
@@ -0,0 +1,188 @@
+package libp2p
+
+import (
+	"sync"
+	"time"
+
+	peer "github.com/libp2p/go-libp2p-core/peer"
+)
+
+const (
+	// MaximumReputationScore is the reputation score of every peer which has
+	// not been penalized for misbehavior.
+	MaximumReputationScore = 100
+	// ReputationThreshold is the reputation score at or below which the peer
+	// is disconnected and messages received from it are dropped until its
+	// score recovers.
+	ReputationThreshold = 0
+
+	// reputationRecovery is the number of reputation points recovered by
+	// the peer in each reputationRecoveryPeriod.
+	reputationRecovery       = 10
+	reputationRecoveryPeriod = time.Minute
+)
+
+// misbehavior is a kind of misbehavior the peer can be penalized for.
+type misbehavior int
+
+const (
+	// malformedMessage is a message which could not be unmarshaled.
+	malformedMessage misbehavior = iota
+	// invalidSender is a message whose sender identity does not match the
+	// author of the message or whose network key delegation signature is
+	// not valid.
+	invalidSender
+	// unknownMessageType is a message of type not registered in the channel.
+	unknownMessageType
+	// rateLimitExceeded is a message exceeding the per-peer message rate
+	// limit of the channel.
+	rateLimitExceeded
+	// protocolViolation is a message rejected by the protocol layer.
+	protocolViolation
+)
+
+// misbehaviorPenalties maps misbehavior kinds to the number of reputation
+// points the peer loses for each of them. Unknown message types are penalized
+// only slightly as they may be a result of a different client version, and
+// exceeding the rate limit is penalized per each dropped message.
+var misbehaviorPenalties = map[misbehavior]int{
+	malformedMessage:   25,
+	invalidSender:      25,
+	unknownMessageType: 2,
+	rateLimitExceeded:  1,
+	protocolViolation:  25,
+}
+
+func (m misbehavior) String() string {
+	switch m {
+	case malformedMessage:
+		return "malformed message"
+	case invalidSender:
+		return "invalid sender"
+	case unknownMessageType:
+		return "unknown message type"
+	case rateLimitExceeded:
+		return "rate limit exceeded"
+	case protocolViolation:
+		return "protocol violation"
+	default:
+		return "unknown misbehavior"
+	}
+}
+
+// reputation keeps reputation scores of peers. Every peer starts with the
+// maximum score and loses points each time it misbehaves. Lost points are
+// recovered over time. Once the score falls to the threshold, the peer is
+// disconnected.
+type reputation struct {
+	mutex  sync.Mutex
+	scores map[peer.ID]*reputationScore
+
+	disconnect func(peerID string)
+	now        func() time.Time
+}
+
+type reputationScore struct {
+	value     int
+	updatedAt time.Time
+}
+
+func newReputation(disconnect func(peerID string)) *reputation {
+	return &reputation{
+		scores:     make(map[peer.ID]*reputationScore),
+		disconnect: disconnect,
+		now:        time.Now,
+	}
+}
+
+// penalize lowers the reputation score of the peer for the given misbehavior
+// and disconnects the peer if its score falls to the threshold.
+func (r *reputation) penalize(peerID peer.ID, offense misbehavior) {
+	r.mutex.Lock()
+
+	score, ok := r.scores[peerID]
+	if !ok {
+		score = &reputationScore{
+			value:     MaximumReputationScore,
+			updatedAt: r.now(),
+		}
+		r.scores[peerID] = score
+	}
+	r.applyRecovery(score)
+
+	previousValue := score.value
+	score.value -= misbehaviorPenalties[offense]
+	currentValue := score.value
+
+	r.mutex.Unlock()
+
+	logger.Debugf(
+		"peer [%v] penalized for [%v]; reputation score [%v]",
+		peerID,
+		offense,
+		currentValue,
+	)
+
+	if previousValue > ReputationThreshold &&
+		currentValue <= ReputationThreshold {
+		logger.Warningf(
+			"disconnecting peer [%v]; reputation score [%v] "+
+				"fell to the threshold after [%v]",
+			peerID,
+			currentValue,
+			offense,
+		)
+		r.disconnect(peerID.String())
+	}
+}
+
+// isBanned returns true if the reputation score of the peer is at or below
+// the threshold.
+func (r *reputation) isBanned(peerID peer.ID) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	score, ok := r.scores[peerID]
+	if !ok {
+		return false
+	}
+	r.applyRecovery(score)
+
+	return score.value <= ReputationThreshold
+}
+
+// peerScores returns the reputation scores of all peers which have not fully
+// recovered yet. Scores of fully recovered peers are forgotten.
+func (r *reputation) peerScores() map[string]int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	scores := make(map[string]int)
+	for peerID, score := range r.scores {
+		r.applyRecovery(score)
+
+		if score.value >= MaximumReputationScore {
+			delete(r.scores, peerID)
+			continue
+		}
+
+		scores[peerID.String()] = score.value
+	}
+
+	return scores
+}
+
+// applyRecovery adds points recovered by the peer since the last update of its
+// score. Has to be called with the reputation lock held.
+func (r *reputation) applyRecovery(score *reputationScore) {
+	periods := r.now().Sub(score.updatedAt) / reputationRecoveryPeriod
+	if periods <= 0 {
+		return
+	}
+
+	score.value += int(periods) * reputationRecovery
+	if score.value > MaximumReputationScore {
+		score.value = MaximumReputationScore
+	}
+	score.updatedAt = score.updatedAt.Add(periods * reputationRecoveryPeriod)
+}
@@ -0,0 +1,110 @@
+package libp2p
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	peer "github.com/libp2p/go-libp2p-core/peer"
+)
+
+func TestReputationPenalize(t *testing.T) {
+	var disconnected []string
+	reputation := newReputation(func(peerID string) {
+		disconnected = append(disconnected, peerID)
+	})
+
+	now := time.Now()
+	reputation.now = func() time.Time { return now }
+
+	misbehavingPeer := peer.ID("misbehaving")
+	otherPeer := peer.ID("other")
+
+	for i := 0; i < 3; i++ {
+		reputation.penalize(misbehavingPeer, invalidSender)
+	}
+	reputation.penalize(otherPeer, unknownMessageType)
+
+	if reputation.isBanned(misbehavingPeer) {
+		t.Errorf("peer should not be banned before reaching the threshold")
+	}
+	if len(disconnected) != 0 {
+		t.Errorf("unexpected disconnected peers: [%v]", disconnected)
+	}
+
+	reputation.penalize(misbehavingPeer, protocolViolation)
+
+	if !reputation.isBanned(misbehavingPeer) {
+		t.Errorf("peer should be banned after reaching the threshold")
+	}
+	if reputation.isBanned(otherPeer) {
+		t.Errorf("other peer should not be banned")
+	}
+
+	expectedDisconnected := []string{misbehavingPeer.String()}
+	if !reflect.DeepEqual(expectedDisconnected, disconnected) {
+		t.Errorf(
+			"unexpected disconnected peers\nexpected: [%v]\nactual:   [%v]",
+			expectedDisconnected,
+			disconnected,
+		)
+	}
+
+	// peer already below the threshold is not disconnected again
+	reputation.penalize(misbehavingPeer, protocolViolation)
+
+	if len(disconnected) != 1 {
+		t.Errorf("peer should be disconnected only once")
+	}
+
+	expectedScores := map[string]int{
+		misbehavingPeer.String(): -25,
+		otherPeer.String():       98,
+	}
+	if !reflect.DeepEqual(expectedScores, reputation.peerScores()) {
+		t.Errorf(
+			"unexpected scores\nexpected: [%v]\nactual:   [%v]",
+			expectedScores,
+			reputation.peerScores(),
+		)
+	}
+}
+
+func TestReputationRecovery(t *testing.T) {
+	reputation := newReputation(func(peerID string) {})
+
+	now := time.Now()
+	reputation.now = func() time.Time { return now }
+
+	misbehavingPeer := peer.ID("misbehaving")
+	otherPeer := peer.ID("other")
+
+	for i := 0; i < 4; i++ {
+		reputation.penalize(misbehavingPeer, malformedMessage)
+	}
+	reputation.penalize(otherPeer, rateLimitExceeded)
+
+	now = now.Add(reputationRecoveryPeriod + time.Second)
+
+	if reputation.isBanned(misbehavingPeer) {
+		t.Errorf("peer should not be banned after recovery")
+	}
+
+	// the other peer fully recovered and is forgotten
+	expectedScores := map[string]int{
+		misbehavingPeer.String(): reputationRecovery,
+	}
+	if !reflect.DeepEqual(expectedScores, reputation.peerScores()) {
+		t.Errorf(
+			"unexpected scores\nexpected: [%v]\nactual:   [%v]",
+			expectedScores,
+			reputation.peerScores(),
+		)
+	}
+
+	now = now.Add(20 * reputationRecoveryPeriod)
+
+	if len(reputation.peerScores()) != 0 {
+		t.Errorf("all peers should fully recover")
+	}
+}
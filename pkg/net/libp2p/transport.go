@@ -2,6 +2,7 @@ package libp2p
 
 import (
 	"context"
+	"fmt"
 	"net"
 
 	secio "github.com/libp2p/go-libp2p-secio"
@@ -12,44 +13,68 @@ import (
 	"github.com/libp2p/go-libp2p-core/sec"
 )
 
-// ID is the multistream-select protocol ID that should be used when identifying
-// this security transport.
-const handshakeID = "/keep/handshake/1.0.0"
+const (
+	// handshakeID is the multistream-select protocol ID of the legacy security
+	// transport. Peers connected with this transport can not handle
+	// compressed broadcast message envelopes.
+	handshakeID = "/keep/handshake/1.0.0"
+	// compressionHandshakeID is the multistream-select protocol ID of the
+	// security transport of clients able to handle compressed broadcast
+	// message envelopes. It is preferred over the legacy transport so that
+	// clients supporting compression negotiate it with each other while they
+	// can still connect to legacy clients.
+	compressionHandshakeID = "/keep/handshake/1.1.0"
+)
 
 // Compile time assertions of custom types
 var _ sec.SecureTransport = (*transport)(nil)
 var _ sec.SecureConn = (*authenticatedConnection)(nil)
 
 // transport constructs an encrypted and authenticated connection for a peer.
+// Operator public keys of authenticated peers are recorded so that they can
+// be resolved by the connection manager, along with the envelope version
+// negotiated with the peer through the transport protocol ID. Peers
+// presenting network key delegations replaced by their operators are
+// rejected.
 type transport struct {
-	localPeerID     peer.ID
-	privateKey      libp2pcrypto.PrivKey
-	protocol        string
-	firewall        keepNet.Firewall
-	encryptionLayer sec.SecureTransport
+	localPeerID         peer.ID
+	privateKey          libp2pcrypto.PrivKey
+	identity            *identity
+	protocol            string
+	firewall            keepNet.Firewall
+	encryptionLayer     sec.SecureTransport
+	operatorKeys        *operatorKeys
+	delegationSequences *delegationSequences
+
+	envelopeVersion  uint32
+	envelopeVersions *envelopeVersions
 }
 
 func newEncryptedAuthenticatedTransport(
-	pk libp2pcrypto.PrivKey,
+	identity *identity,
 	protocol string,
 	firewall keepNet.Firewall,
+	operatorKeys *operatorKeys,
+	delegationSequences *delegationSequences,
+	envelopeVersion uint32,
+	envelopeVersions *envelopeVersions,
 ) (*transport, error) {
-	id, err := peer.IDFromPrivateKey(pk)
-	if err != nil {
-		return nil, err
-	}
-
-	encryptionLayer, err := secio.New(pk)
+	encryptionLayer, err := secio.New(identity.privKey)
 	if err != nil {
 		return nil, err
 	}
 
 	return &transport{
-		localPeerID:     id,
-		privateKey:      pk,
-		firewall:        firewall,
-		encryptionLayer: encryptionLayer,
-		protocol:        protocol,
+		localPeerID:         identity.id,
+		privateKey:          identity.privKey,
+		identity:            identity,
+		firewall:            firewall,
+		encryptionLayer:     encryptionLayer,
+		protocol:            protocol,
+		operatorKeys:        operatorKeys,
+		delegationSequences: delegationSequences,
+		envelopeVersion:     envelopeVersion,
+		envelopeVersions:    envelopeVersions,
 	}, nil
 }
 
@@ -63,13 +88,30 @@ func (t *transport) SecureInbound(
 		return nil, err
 	}
 
-	return newAuthenticatedInboundConnection(
+	// The local delegation is read for each connection as it may have been
+	// renewed since the transport has been created.
+	delegation, err := t.identity.marshaledDelegation()
+	if err != nil {
+		return nil, err
+	}
+
+	authenticatedConnection, err := newAuthenticatedInboundConnection(
 		encryptedConnection,
 		t.localPeerID,
 		t.privateKey,
+		delegation,
 		t.firewall,
 		t.protocol,
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.recordOperator(authenticatedConnection); err != nil {
+		return nil, err
+	}
+
+	return authenticatedConnection, nil
 }
 
 // SecureOutbound secures an outbound connection.
@@ -87,12 +129,49 @@ func (t *transport) SecureOutbound(
 		return nil, err
 	}
 
-	return newAuthenticatedOutboundConnection(
+	delegation, err := t.identity.marshaledDelegation()
+	if err != nil {
+		return nil, err
+	}
+
+	authenticatedConnection, err := newAuthenticatedOutboundConnection(
 		encryptedConnection,
 		t.localPeerID,
 		t.privateKey,
+		delegation,
 		remotePeerID,
 		t.firewall,
 		t.protocol,
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.recordOperator(authenticatedConnection); err != nil {
+		return nil, err
+	}
+
+	return authenticatedConnection, nil
+}
+
+func (t *transport) recordOperator(connection *authenticatedConnection) error {
+	err := t.delegationSequences.observe(
+		connection.RemotePeer(),
+		connection.remoteDelegation,
+	)
+	if err != nil {
+		if closeErr := connection.Close(); closeErr != nil {
+			logger.Debugf("could not close the connection: [%v]", closeErr)
+		}
+
+		return fmt.Errorf("connection handshake failed: [%v]", err)
+	}
+
+	t.operatorKeys.put(
+		connection.RemotePeer(),
+		connection.RemoteOperatorPublicKey(),
+	)
+	t.envelopeVersions.put(connection.RemotePeer(), t.envelopeVersion)
+
+	return nil
 }
@@ -9,7 +9,7 @@ import (
 	"time"
 
 	"github.com/keep-network/keep-core/pkg/net/internal"
-	"github.com/keep-network/keep-core/pkg/net/key"
+	"github.com/keep-network/keep-core/pkg/operator"
 
 	"github.com/libp2p/go-libp2p-core/helpers"
 	"github.com/libp2p/go-libp2p-core/peer"
@@ -48,6 +48,20 @@ type unicastMessageHandler struct {
 }
 
 func (uc *unicastChannel) Send(message net.TaggedMarshaler) error {
+	return uc.sendFrom(uc.clientIdentity, message)
+}
+
+// sendFrom sends the message on behalf of the given identity. The identity
+// must share the network key with the client identity; it may carry the
+// delegation of another operator hosted by the client.
+func (uc *unicastChannel) sendFrom(
+	sender *identity,
+	message net.TaggedMarshaler,
+) error {
+	if uc.isLoopback() {
+		return uc.sendLoopback(sender, message)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
 	defer cancel()
 
@@ -70,12 +84,12 @@ func (uc *unicastChannel) Send(message net.TaggedMarshaler) error {
 
 	select {
 	case stream := <-streamSuccess:
-		messageProto, err := uc.messageProto(message)
+		messageProto, err := uc.messageProto(sender, message)
 		if err != nil {
 			return err
 		}
 
-		err = signMessage(messageProto, uc.clientIdentity.privKey)
+		err = signMessage(messageProto, sender.privKey)
 		if err != nil {
 			return err
 		}
@@ -88,6 +102,39 @@ func (uc *unicastChannel) Send(message net.TaggedMarshaler) error {
 	}
 }
 
+// isLoopback returns true if the channel has been opened with the client's
+// own peer. It happens when group members are operated by the same client,
+// either as virtual stakers of the same operator or by operators hosted by
+// the client.
+func (uc *unicastChannel) isLoopback() bool {
+	return uc.remotePeerID == uc.clientIdentity.id
+}
+
+// sendLoopback processes the message locally, just like it would be processed
+// if received from the remote peer, since the client can not open a stream
+// with its own peer.
+func (uc *unicastChannel) sendLoopback(
+	sender *identity,
+	message net.TaggedMarshaler,
+) error {
+	logger.Debugf(
+		"[%v] dispatching message sent to own peer locally",
+		uc.clientIdentity.id,
+	)
+
+	messageProto, err := uc.messageProto(sender, message)
+	if err != nil {
+		return err
+	}
+
+	err = signMessage(messageProto, sender.privKey)
+	if err != nil {
+		return err
+	}
+
+	return uc.processMessage(messageProto)
+}
+
 func (uc *unicastChannel) send(stream network.Stream, message proto.Message) error {
 	writer := bufio.NewWriter(stream)
 	protoWriter := protoio.NewDelimitedWriter(writer)
@@ -116,6 +163,7 @@ func (uc *unicastChannel) send(stream network.Stream, message proto.Message) err
 }
 
 func (uc *unicastChannel) messageProto(
+	sender *identity,
 	message net.TaggedMarshaler,
 ) (*pb.UnicastNetworkMessage, error) {
 	payloadBytes, err := message.Marshal()
@@ -123,7 +171,7 @@ func (uc *unicastChannel) messageProto(
 		return nil, err
 	}
 
-	senderIdentityBytes, err := uc.clientIdentity.Marshal()
+	senderIdentityBytes, err := sender.Marshal()
 	if err != nil {
 		return nil, err
 	}
@@ -265,20 +313,19 @@ func (uc *unicastChannel) processMessage(message *pb.UnicastNetworkMessage) erro
 		return err
 	}
 
-	networkKey := key.Libp2pKeyToNetworkKey(senderIdentifier.pubKey)
-	if networkKey == nil {
-		return fmt.Errorf(
-			"sender [%v] with key [%v] is not of correct type",
-			senderIdentifier.id,
-			senderIdentifier.pubKey,
-		)
+	// The sender public key is the operator key resolved through the
+	// sender's delegation so that the protocol layer can validate the sender
+	// against its on-chain identity.
+	senderPublicKey, err := senderIdentifier.operatorPublicKey()
+	if err != nil {
+		return err
 	}
 
 	uc.deliver(internal.BasicMessage(
 		senderIdentifier.id,
 		unmarshaled,
 		string(message.Type),
-		key.Marshal(networkKey),
+		operator.Marshal(senderPublicKey),
 		uint64(0),
 	))
 
@@ -27,7 +27,9 @@ type unicastChannelManager struct {
 	channelsMutex sync.Mutex
 	channels      map[net.TransportIdentifier]*unicastChannel
 
-	channelOpenedHandler func(channel net.UnicastChannel)
+	// Each operator hosted by the client registers its own handler.
+	channelOpenedHandlersMutex sync.Mutex
+	channelOpenedHandlers      []func(channel net.UnicastChannel)
 }
 
 func newUnicastChannelManager(
@@ -54,7 +56,10 @@ func newUnicastChannelManager(
 func (ucm *unicastChannelManager) onChannelOpened(
 	handler func(channel net.UnicastChannel),
 ) {
-	ucm.channelOpenedHandler = handler
+	ucm.channelOpenedHandlersMutex.Lock()
+	defer ucm.channelOpenedHandlersMutex.Unlock()
+
+	ucm.channelOpenedHandlers = append(ucm.channelOpenedHandlers, handler)
 }
 
 func (ucm *unicastChannelManager) handleIncomingStream(stream network.Stream) {
@@ -77,26 +82,54 @@ func (ucm *unicastChannelManager) handleIncomingStream(stream network.Stream) {
 		return
 	}
 
-	if !isExistingChannel && ucm.channelOpenedHandler != nil {
-		ucm.channelOpenedHandler(channel)
+	if !isExistingChannel {
+		ucm.notifyChannelOpened(channel)
 	}
 
 	channel.handleStream(stream)
 }
 
+func (ucm *unicastChannelManager) notifyChannelOpened(channel *unicastChannel) {
+	ucm.channelOpenedHandlersMutex.Lock()
+	handlers := make([]func(net.UnicastChannel), len(ucm.channelOpenedHandlers))
+	copy(handlers, ucm.channelOpenedHandlers)
+	ucm.channelOpenedHandlersMutex.Unlock()
+
+	for _, handler := range handlers {
+		handler(channel)
+	}
+}
+
 func (ucm *unicastChannelManager) getUnicastChannelWithHandshake(
 	peerID net.TransportIdentifier,
 ) (
 	*unicastChannel,
 	error,
 ) {
-	err := ucm.trialHandshake(peerID)
+	// Messages sent to the client's own peer are processed locally so
+	// there is no need to check whether the peer is reachable.
+	isOwnPeer := peerID.String() == ucm.identity.id.String()
+
+	if !isOwnPeer {
+		err := ucm.trialHandshake(peerID)
+		if err != nil {
+			return nil, fmt.Errorf("unicast channel handshake error: [%v]", err)
+		}
+	}
+
+	channel, isExistingChannel, err := ucm.getUnicastChannel(peerID)
 	if err != nil {
-		return nil, fmt.Errorf("unicast channel handshake error: [%v]", err)
+		return nil, err
 	}
 
-	channel, _, err := ucm.getUnicastChannel(peerID)
-	return channel, err
+	// There is no incoming stream opening the channel with the client's own
+	// peer, so handlers are notified when the channel is created. This way,
+	// all operators hosted by the client can receive messages sent over it.
+	if isOwnPeer && !isExistingChannel {
+		ucm.notifyChannelOpened(channel)
+	}
+
+	return channel, nil
 }
 
 func (ucm *unicastChannelManager) getUnicastChannel(
@@ -14,13 +14,18 @@ import (
 
 const messageHandlerThrottle = 256
 
+// broadcastCounter is the source of sequence numbers of all local broadcast
+// channels. Channels created with the same network key share the transport
+// identifier so sequence numbers have to be unique across all of them for
+// retransmissions to be filtered correctly.
+var broadcastCounter uint64
+
 type messageHandler struct {
 	ctx     context.Context
 	channel chan net.Message
 }
 
 type localChannel struct {
-	counter              uint64
 	name                 string
 	identifier           net.TransportIdentifier
 	staticKey            *key.NetworkPublic
@@ -32,7 +37,7 @@ type localChannel struct {
 }
 
 func (lc *localChannel) nextSeqno() uint64 {
-	return atomic.AddUint64(&lc.counter, 1)
+	return atomic.AddUint64(&broadcastCounter, 1)
 }
 
 func (lc *localChannel) Name() string {
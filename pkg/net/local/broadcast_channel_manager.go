@@ -31,7 +31,9 @@ func getBroadcastChannel(name string, staticKey *key.NetworkPublic) net.Broadcas
 		broadcastChannels[name] = localChannels
 	}
 
-	identifier := randomLocalIdentifier()
+	// Senders are identified the same way as in unicast channels so that
+	// unicast channels can be opened with senders of broadcast messages.
+	identifier := createLocalIdentifier(staticKey)
 	channel := &localChannel{
 		name:                 name,
 		identifier:           &identifier,
@@ -6,11 +6,13 @@ package local
 import (
 	"context"
 	"crypto/ecdsa"
+	"fmt"
 	"sync"
 
 	"github.com/ipfs/go-log"
 	"github.com/keep-network/keep-core/pkg/net"
 	"github.com/keep-network/keep-core/pkg/net/key"
+	"github.com/keep-network/keep-core/pkg/operator"
 )
 
 var logger = log.Logger("keep-net-local")
@@ -89,7 +91,7 @@ func Connect() Provider {
 // identify network messages.
 func ConnectWithKey(staticKey *key.NetworkPublic) Provider {
 	return &localProvider{
-		id:                    randomLocalIdentifier(),
+		id:                    createLocalIdentifier(staticKey),
 		staticKey:             staticKey,
 		connectionManager:     &localConnectionManager{peers: make(map[string]*key.NetworkPublic)},
 		unicastChannelManager: newUnicastChannelManager(staticKey),
@@ -125,6 +127,23 @@ func (lcm *localConnectionManager) GetPeerPublicKey(
 	return lcm.peers[connectedPeer], nil
 }
 
+// GetPeerOperatorPublicKey returns the network key of the peer. Local
+// providers do not support delegations so the network key of every peer is
+// its operator key.
+func (lcm *localConnectionManager) GetPeerOperatorPublicKey(
+	connectedPeer string,
+) (*operator.PublicKey, error) {
+	lcm.mutex.Lock()
+	defer lcm.mutex.Unlock()
+
+	peerPublicKey, ok := lcm.peers[connectedPeer]
+	if !ok {
+		return nil, fmt.Errorf("unknown peer [%v]", connectedPeer)
+	}
+
+	return key.NetworkKeyToECDSAKey(peerPublicKey), nil
+}
+
 func (lcm *localConnectionManager) DisconnectPeer(connectedPeer string) {
 	lcm.mutex.Lock()
 	defer lcm.mutex.Unlock()
@@ -58,6 +58,7 @@ func (uc *unicastChannel) Send(message net.TaggedMarshaler) error {
 
 	return deliverMessage(
 		uc.senderTransportID,
+		uc.senderStaticKey,
 		uc.receiverTransportID,
 		marshalled,
 		message.Type(),
@@ -86,7 +87,11 @@ func (uc *unicastChannel) SetUnmarshaler(
 	uc.unmarshalersByType[unmarshaler().Type()] = unmarshaler
 }
 
+// receiveMessage handles a message sent by the remote peer of the channel.
+// The message is attributed to the remote peer so that handlers can identify
+// its sender.
 func (uc *unicastChannel) receiveMessage(
+	remoteStaticKey *key.NetworkPublic,
 	messagePayload []byte,
 	messageType string,
 ) error {
@@ -108,10 +113,10 @@ func (uc *unicastChannel) receiveMessage(
 	}
 
 	message := internal.BasicMessage(
-		uc.senderTransportID,
+		uc.receiverTransportID,
 		unmarshaled,
 		messageType,
-		key.Marshal(uc.senderStaticKey),
+		key.Marshal(remoteStaticKey),
 		uc.nextSeqno(),
 	)
 
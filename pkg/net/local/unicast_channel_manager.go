@@ -154,6 +154,7 @@ func (up *unicastChannelManager) notifyNewChannel(channel net.UnicastChannel) {
 
 func deliverMessage(
 	sender net.TransportIdentifier,
+	senderStaticKey *key.NetworkPublic,
 	receiver net.TransportIdentifier,
 	messagePayload []byte,
 	messageType string,
@@ -171,5 +172,5 @@ func deliverMessage(
 		return fmt.Errorf("peer [%v] could not find channel for [%v]", receiver, sender)
 	}
 
-	return channel.receiveMessage(messagePayload, messageType)
+	return channel.receiveMessage(senderStaticKey, messagePayload, messageType)
 }
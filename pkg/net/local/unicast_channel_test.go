@@ -1,6 +1,7 @@
 package local
 
 import (
+	"bytes"
 	"context"
 	"testing"
 	"time"
@@ -136,6 +137,17 @@ func TestSendAndReceive(t *testing.T) {
 			t.Fatal("unexpected message type")
 		}
 
+		if msg.TransportSenderID().String() != remotePeer1ID.String() {
+			t.Fatalf(
+				"unexpected transport sender ID\nactual:   [%v]\nexpected: [%v]",
+				msg.TransportSenderID(),
+				remotePeer1ID,
+			)
+		}
+		if !bytes.Equal(msg.SenderPublicKey(), key.Marshal(peer1StaticKey)) {
+			t.Fatal("unexpected sender public key")
+		}
+
 	case <-peer1Received:
 		t.Fatal("peer 1 should not receive this message")
 	case <-ctx.Done():
@@ -264,6 +276,7 @@ func TestTimedOutHandlerNotReceiveUnicastMessage(t *testing.T) {
 	_, peer1StaticKey, _ := key.GenerateStaticNetworkKey()
 
 	peer2ID := localIdentifier("peer-0x121211")
+	_, peer2StaticKey, _ := key.GenerateStaticNetworkKey()
 
 	unicastChannel := newUnicastChannel(peer1ID, peer1StaticKey, peer2ID)
 	unicastChannel.SetUnmarshaler(func() net.TaggedUnmarshaler {
@@ -288,7 +301,7 @@ func TestTimedOutHandlerNotReceiveUnicastMessage(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	unicastChannel.receiveMessage(marshaled, message.Type())
+	unicastChannel.receiveMessage(peer2StaticKey, marshaled, message.Type())
 
 	select {
 	case <-received:
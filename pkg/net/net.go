@@ -6,6 +6,7 @@ import (
 
 	"github.com/gogo/protobuf/proto"
 	"github.com/keep-network/keep-core/pkg/net/key"
+	"github.com/keep-network/keep-core/pkg/operator"
 )
 
 // TransportIdentifier represents a protocol-level identifier. It is an opaque
@@ -17,6 +18,10 @@ type TransportIdentifier interface {
 // Message represents a message exchanged within the network layer. It carries
 // a sender id for the transport layer and, if available, for the protocol
 // layer. It also carries an unmarshaled payload.
+//
+// The sender public key is the public key of the sender's operator. If the
+// sender uses a network key delegated by the operator, the operator key is
+// resolved through the delegation.
 type Message interface {
 	TransportSenderID() TransportIdentifier
 	SenderPublicKey() []byte
@@ -73,6 +78,10 @@ type Provider interface {
 type ConnectionManager interface {
 	ConnectedPeers() []string
 	GetPeerPublicKey(connectedPeer string) (*key.NetworkPublic, error)
+	// GetPeerOperatorPublicKey returns the public key of the operator of the
+	// connected peer. It is the peer's network key unless the peer uses a
+	// network key delegated by the operator.
+	GetPeerOperatorPublicKey(connectedPeer string) (*operator.PublicKey, error)
 	DisconnectPeer(connectedPeer string)
 
 	// AddrStrings returns all listen addresses of the provider.
@@ -157,6 +166,37 @@ type BroadcastChannel interface {
 // processed or false otherwise.
 type BroadcastChannelFilter func(*ecdsa.PublicKey) bool
 
+// MisbehaviorReporter is implemented by broadcast channels keeping reputation
+// of peers. It allows the protocol layer to report the peer which sent
+// a message rejected by the protocol, e.g. a message from a sender not being
+// a member of the group under the index it claims.
+type MisbehaviorReporter interface {
+	// ReportMisbehavior lowers the reputation of the peer with the given
+	// transport identifier.
+	ReportMisbehavior(sender TransportIdentifier)
+}
+
+// ReportMisbehavior reports the sender of the message as misbehaving if the
+// channel the message has been received from supports reporting misbehavior.
+// Otherwise, it does nothing.
+func ReportMisbehavior(channel BroadcastChannel, message Message) {
+	reporter, ok := channel.(MisbehaviorReporter)
+	if !ok || message.TransportSenderID() == nil {
+		return
+	}
+
+	reporter.ReportMisbehavior(message.TransportSenderID())
+}
+
+// ReputationKeeper is implemented by connection managers keeping reputation
+// scores of peers based on the messages received from them.
+type ReputationKeeper interface {
+	// PeerScores returns the current reputation scores of peers which have
+	// been penalized and have not fully recovered yet, keyed by the peer
+	// identifier. Peers not listed have the maximum score.
+	PeerScores() map[string]int
+}
+
 // Firewall represents a set of rules the remote peer has to conform to so that
 // a connection with that peer can be approved.
 type Firewall interface {
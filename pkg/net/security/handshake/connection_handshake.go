@@ -18,11 +18,11 @@
 //
 // [Act 1]
 // nonce1 = random_nonce()
-// act1Message{nonce1, protocol_id1} ---->
+// act1Message{nonce1, protocol_id1, delegation1} ---->
 //                                       [Act 2]
 //                                       nonce2 = random_nonce()
 //                                       challenge = sha256(nonce1 || nonce2)
-//                                       <---- act2Message{challenge, nonce2, protocol_id2}
+//                                       <---- act2Message{challenge, nonce2, protocol_id2, delegation2}
 // [Act 3]
 // challenge = sha256(nonce1 || nonce2)
 // act3Message{challenge} ---->
@@ -32,6 +32,11 @@
 // initiator and responder in acts one, two, and three of the handshake,
 // respectively.
 //
+// If the peer's network key is not its operator key, delegation1 and
+// delegation2 carry the marshaled delegation of the network key signed with
+// the operator key. The handshake only transports delegations, they are
+// verified against the peer's network key by the transport layer.
+//
 // initiatorAct1, initiatorAct2, and initiatorAct3 represent the state of the
 // initiator in rounds one, two, and three of the handshake, respectively.
 //
@@ -54,24 +59,28 @@ import (
 
 // Act1Message is sent in the first handshake act by the initiator to the
 // responder. It contains randomly generated `nonce1`, an 8-byte (64-bit)
-// unsigned integer as well as the protocol identifier.
+// unsigned integer, the protocol identifier and, optionally, the initiator's
+// network key delegation.
 //
 // act1Message should be signed with initiator's static private key.
 type Act1Message struct {
-	nonce1    uint64
-	protocol1 string
+	nonce1      uint64
+	protocol1   string
+	delegation1 []byte
 }
 
 // Act2Message is sent in the second handshake act by the responder to the
 // initiator. It contains randomly generated `nonce2`, which is an 8-byte
 // unsigned integer, `challenge`, which is the result of SHA256 on the
-// concatenated bytes of `nonce1` and `nonce2`, and the protocol identifier.
+// concatenated bytes of `nonce1` and `nonce2`, the protocol identifier and,
+// optionally, the responder's network key delegation.
 //
 // act2Message should be signed with responder's static private key.
 type Act2Message struct {
-	nonce2    uint64
-	challenge [sha256.Size]byte
-	protocol2 string
+	nonce2      uint64
+	challenge   [sha256.Size]byte
+	protocol2   string
+	delegation2 []byte
 }
 
 // Act3Message is sent in the third handshake act by the initiator to the
@@ -86,26 +95,33 @@ type Act3Message struct {
 // initiatorAct1 represents the state of the initiator in the first act of the
 // handshake protocol.
 type initiatorAct1 struct {
-	nonce1    uint64
-	protocol1 string
+	nonce1      uint64
+	protocol1   string
+	delegation1 []byte
 }
 
 // InitiateHandshake function allows to initiate a handshake by creating
 // and initializing a state machine representing initiator in the first round
-// of the handshake, ready to execute the protocol.
-func InitiateHandshake(protocol string) (*initiatorAct1, error) {
+// of the handshake, ready to execute the protocol. The delegation is the
+// marshaled delegation of the initiator's network key; it should be nil if
+// the network key is the operator key.
+func InitiateHandshake(protocol string, delegation []byte) (*initiatorAct1, error) {
 	nonce1, err := randomNonce()
 	if err != nil {
 		return nil, fmt.Errorf("could not initiate the handshake: [%v]", err)
 	}
 
-	return &initiatorAct1{nonce1, protocol}, nil
+	return &initiatorAct1{nonce1, protocol, delegation}, nil
 }
 
 // Message returns the message sent by initiator to the responder in the first
 // act of the handshake protocol.
 func (ia1 *initiatorAct1) Message() *Act1Message {
-	return &Act1Message{nonce1: ia1.nonce1, protocol1: ia1.protocol1}
+	return &Act1Message{
+		nonce1:      ia1.nonce1,
+		protocol1:   ia1.protocol1,
+		delegation1: ia1.delegation1,
+	}
 }
 
 // Next performs a state transition and returns initiator in a state ready to
@@ -119,7 +135,13 @@ func (ia1 *initiatorAct1) Next() *initiatorAct2 {
 // The returned responder is in a state ready to execute the second act of the
 // handshake protocol.
 // The function also validates if both parties run the same protocol.
-func AnswerHandshake(message *Act1Message, protocol string) (*responderAct2, error) {
+// The delegation is the marshaled delegation of the responder's network key;
+// it should be nil if the network key is the operator key.
+func AnswerHandshake(
+	message *Act1Message,
+	protocol string,
+	delegation []byte,
+) (*responderAct2, error) {
 	if message.protocol1 != protocol {
 		return nil, fmt.Errorf("unsupported protocol: [%v]", message.protocol1)
 	}
@@ -131,7 +153,19 @@ func AnswerHandshake(message *Act1Message, protocol string) (*responderAct2, err
 	}
 	challenge := hashToChallenge(nonce1, nonce2)
 
-	return &responderAct2{nonce2, challenge, protocol}, nil
+	return &responderAct2{nonce2, challenge, protocol, delegation}, nil
+}
+
+// Delegation returns the marshaled network key delegation of the initiator or
+// nil if the initiator's network key is its operator key.
+func (am *Act1Message) Delegation() []byte {
+	return am.delegation1
+}
+
+// Delegation returns the marshaled network key delegation of the responder or
+// nil if the responder's network key is its operator key.
+func (am *Act2Message) Delegation() []byte {
+	return am.delegation2
 }
 
 // initiatorAct2 represents the state of the initiator in the second act of the
@@ -144,18 +178,20 @@ type initiatorAct2 struct {
 // responderAct2 represents the state of the responder in the second act of the
 // handshake protocol.
 type responderAct2 struct {
-	nonce2    uint64
-	challenge [sha256.Size]byte
-	protocol2 string
+	nonce2      uint64
+	challenge   [sha256.Size]byte
+	protocol2   string
+	delegation2 []byte
 }
 
 // Message returns the message sent by responder to the initiator in the second
 // act of the handshake protocol.
 func (ra2 *responderAct2) Message() *Act2Message {
 	return &Act2Message{
-		nonce2:    ra2.nonce2,
-		challenge: ra2.challenge,
-		protocol2: ra2.protocol2,
+		nonce2:      ra2.nonce2,
+		challenge:   ra2.challenge,
+		protocol2:   ra2.protocol2,
+		delegation2: ra2.delegation2,
 	}
 }
 
@@ -13,11 +13,11 @@ const (
 )
 
 func TestInitiateHanshakeWithUniqueNonce(t *testing.T) {
-	initiator1, err := InitiateHandshake(protocol)
+	initiator1, err := InitiateHandshake(protocol, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
-	initiator2, err := InitiateHandshake(protocol)
+	initiator2, err := InitiateHandshake(protocol, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -38,14 +38,14 @@ func TestAnswerHandshakeWithChallenge(t *testing.T) {
 	//
 
 	// initiator station
-	initiator, err := InitiateHandshake(protocol)
+	initiator, err := InitiateHandshake(protocol, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
 	act1Msg := initiator.Message()
 
 	// responder station
-	responder, err := AnswerHandshake(act1Msg, protocol)
+	responder, err := AnswerHandshake(act1Msg, protocol, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -79,7 +79,7 @@ func TestRepeatChallengeToFinalize(t *testing.T) {
 	//
 
 	// responder station
-	act2Msg := &Act2Message{nonce2, expectedChallenge, protocol}
+	act2Msg := &Act2Message{nonce2, expectedChallenge, protocol, nil}
 
 	// initiator station
 	initiatorAct2 := &initiatorAct2{nonce1, protocol}
@@ -112,14 +112,14 @@ func TestFailAct1ForUnexpectedProtocol(t *testing.T) {
 	//
 
 	// initiator station
-	initiator, err := InitiateHandshake(protocol)
+	initiator, err := InitiateHandshake(protocol, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
 	act1Msg := initiator.Message()
 
 	// responder station
-	_, err = AnswerHandshake(act1Msg, protocol2)
+	_, err = AnswerHandshake(act1Msg, protocol2, nil)
 
 	expectedErr := "unsupported protocol: [keep-beacon]"
 	if err.Error() != expectedErr {
@@ -141,7 +141,7 @@ func TestFailAct2ForUnexpectedProtocol(t *testing.T) {
 	//
 
 	// responder station
-	act2Msg := &Act2Message{nonce2, expectedChallenge, protocol2}
+	act2Msg := &Act2Message{nonce2, expectedChallenge, protocol2, nil}
 
 	// initiator station
 	initiatorAct2 := &initiatorAct2{nonce1, protocol}
@@ -167,7 +167,7 @@ func TestFailAct2ForInvalidChallenge(t *testing.T) {
 
 	// responder station
 	invalidChallenge := [32]byte{0xff, 0xfa}
-	act2Msg := &Act2Message{nonce2, invalidChallenge, protocol}
+	act2Msg := &Act2Message{nonce2, invalidChallenge, protocol, nil}
 
 	// initiator station
 	initiatorAct2 := &initiatorAct2{nonce1, protocol}
@@ -214,7 +214,7 @@ func TestFullHandshake(t *testing.T) {
 	//
 
 	// initiator station
-	initiatorAct1, err := InitiateHandshake(protocol)
+	initiatorAct1, err := InitiateHandshake(protocol, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -222,7 +222,7 @@ func TestFullHandshake(t *testing.T) {
 	initiatorAct2 := initiatorAct1.Next()
 
 	// responder station
-	responderAct2, err := AnswerHandshake(act1Message, protocol)
+	responderAct2, err := AnswerHandshake(act1Message, protocol, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -254,3 +254,44 @@ func TestFullHandshake(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestHandshakeTransportsDelegations(t *testing.T) {
+	initiatorDelegation := []byte("initiator delegation")
+	responderDelegation := []byte("responder delegation")
+
+	initiatorAct1, err := InitiateHandshake(protocol, initiatorDelegation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	act1Message := initiatorAct1.Message()
+
+	if !reflect.DeepEqual(initiatorDelegation, act1Message.Delegation()) {
+		t.Errorf(
+			"unexpected initiator's delegation\nexpected: [%s]\nactual:   [%s]",
+			initiatorDelegation,
+			act1Message.Delegation(),
+		)
+	}
+
+	responderAct2, err := AnswerHandshake(
+		act1Message,
+		protocol,
+		responderDelegation,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	act2Message := responderAct2.Message()
+
+	if !reflect.DeepEqual(responderDelegation, act2Message.Delegation()) {
+		t.Errorf(
+			"unexpected responder's delegation\nexpected: [%s]\nactual:   [%s]",
+			responderDelegation,
+			act2Message.Delegation(),
+		)
+	}
+
+	if _, err := initiatorAct1.Next().Next(act2Message); err != nil {
+		t.Fatal(err)
+	}
+}
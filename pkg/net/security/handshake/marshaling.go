@@ -17,7 +17,11 @@ const (
 func (am *Act1Message) Marshal() ([]byte, error) {
 	nonceBytes := make([]byte, nonceByteLength)
 	binary.LittleEndian.PutUint64(nonceBytes, am.nonce1)
-	return (&pb.Act1Message{Nonce: nonceBytes, Protocol: am.protocol1}).Marshal()
+	return (&pb.Act1Message{
+		Nonce:      nonceBytes,
+		Protocol:   am.protocol1,
+		Delegation: am.delegation1,
+	}).Marshal()
 }
 
 // Unmarshal converts a byte array produced by Marshal to a Act1Message.
@@ -36,6 +40,10 @@ func (am *Act1Message) Unmarshal(bytes []byte) error {
 
 	am.protocol1 = pbAct1.Protocol
 
+	if len(pbAct1.Delegation) > 0 {
+		am.delegation1 = pbAct1.Delegation
+	}
+
 	return nil
 }
 
@@ -45,9 +53,10 @@ func (am *Act2Message) Marshal() ([]byte, error) {
 	nonceBytes := make([]byte, nonceByteLength)
 	binary.LittleEndian.PutUint64(nonceBytes, am.nonce2)
 	return (&pb.Act2Message{
-		Nonce:     nonceBytes,
-		Challenge: am.challenge[:],
-		Protocol:  am.protocol2,
+		Nonce:      nonceBytes,
+		Challenge:  am.challenge[:],
+		Protocol:   am.protocol2,
+		Delegation: am.delegation2,
 	}).Marshal()
 }
 
@@ -74,6 +83,10 @@ func (am *Act2Message) Unmarshal(bytes []byte) error {
 
 	am.protocol2 = pbAct2.Protocol
 
+	if len(pbAct2.Delegation) > 0 {
+		am.delegation2 = pbAct2.Delegation
+	}
+
 	return nil
 }
 
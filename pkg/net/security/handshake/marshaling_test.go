@@ -11,8 +11,9 @@ import (
 
 func TestAct1MessageRoundTrip(t *testing.T) {
 	message := &Act1Message{
-		nonce1:    100,
-		protocol1: "keep-beacon",
+		nonce1:      100,
+		protocol1:   "keep-beacon",
+		delegation1: []byte{1, 2, 3},
 	}
 
 	unmarshaler := &Act1Message{}
@@ -60,9 +61,10 @@ func TestAct2MessageRoundTrip(t *testing.T) {
 	}
 
 	message := &Act2Message{
-		nonce2:    100,
-		challenge: challenge,
-		protocol2: "keep-ecdsa",
+		nonce2:      100,
+		challenge:   challenge,
+		protocol2:   "keep-ecdsa",
+		delegation2: []byte{4, 5, 6},
 	}
 
 	unmarshaler := &Act2Message{}
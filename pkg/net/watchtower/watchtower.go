@@ -12,7 +12,6 @@ import (
 	"github.com/ipfs/go-log"
 
 	"github.com/keep-network/keep-core/pkg/net"
-	"github.com/keep-network/keep-core/pkg/net/key"
 )
 
 var logger = log.Logger("keep-net-watchtower")
@@ -126,7 +125,10 @@ func (g *Guard) checkFirewallRules(peer string) {
 }
 
 func (g *Guard) getPeerPublicKey(peer string) (*ecdsa.PublicKey, error) {
-	peerPublicKey, err := g.connectionManager.GetPeerPublicKey(peer)
+	// Firewall rules are checked against the operator so that peers using
+	// a network key delegated by the operator are validated against the
+	// operator's stake.
+	peerPublicKey, err := g.connectionManager.GetPeerOperatorPublicKey(peer)
 	if err != nil {
 		return nil, err
 	}
@@ -136,5 +138,5 @@ func (g *Guard) getPeerPublicKey(peer string) (*ecdsa.PublicKey, error) {
 			"failed to resolve valid public key for peer [%s]", peer,
 		)
 	}
-	return key.NetworkKeyToECDSAKey(peerPublicKey), nil
+	return peerPublicKey, nil
 }